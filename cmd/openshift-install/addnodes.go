@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset/agent/image"
+	"github.com/openshift/installer/pkg/statecrypt"
+)
+
+var addNodesImageOpts struct {
+	kubeconfig string
+}
+
+func newAgentCreateAddNodesImageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-nodes-image",
+		Short: "Generates a bootable image adding the hosts of " + image.NodesConfigFilename + " as workers of an installed cluster",
+		Long: `Generate a bootable image installing the hosts of the ` + image.NodesConfigFilename + ` of
+the assets directory as workers of the installed cluster of the kubeconfig.
+
+The release image, the pull secret, the architecture and the ignition of the
+workers are read from the cluster. Each host is found by the MAC address of
+one of its interfaces, installed with its host name and network config to its
+rootDeviceHints deviceName, which is required since the disk is overwritten
+without confirmation, and reboots to join the cluster. Follow the hosts and
+approve the certificate signing requests of their nodes with agent wait-for
+add-nodes.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			isoFile, err := createAddNodesImage(context.Background(), command.RootOpts.Dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			logrus.Infof("Generated ISO at %s", isoFile)
+		},
+	}
+	cmd.Flags().StringVar(&addNodesImageOpts.kubeconfig, "kubeconfig", "", "kubeconfig of the cluster, auth/kubeconfig of the assets directory when empty")
+	return cmd
+}
+
+func createAddNodesImage(ctx context.Context, directory string) (string, error) {
+	nodesConfig, err := image.LoadNodesConfig(directory)
+	if err != nil {
+		return "", err
+	}
+
+	kubeconfig := addNodesImageOpts.kubeconfig
+	if kubeconfig == "" {
+		kubeconfig = filepath.Join(directory, "auth", "kubeconfig")
+	}
+	data, err := statecrypt.ReadFile(kubeconfig)
+	if err != nil {
+		return "", errors.Wrap(err, "loading kubeconfig")
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		return "", errors.Wrap(err, "loading kubeconfig")
+	}
+
+	cluster, err := image.FetchAddNodesCluster(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	logrus.Infof("Adding %d hosts to the cluster running %s", len(nodesConfig.Hosts), cluster.ReleaseImage)
+	return image.CreateAddNodesImage(directory, cluster, nodesConfig)
+}
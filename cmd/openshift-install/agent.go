@@ -1,9 +1,12 @@
 package main
 
 import (
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift/installer/cmd/openshift-install/agent"
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
 	"github.com/openshift/installer/pkg/asset"
 	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
 	"github.com/openshift/installer/pkg/asset/agent/configimage"
@@ -25,6 +28,13 @@ func newAgentCmd() *cobra.Command {
 
 	agentCmd.AddCommand(newAgentCreateCmd())
 	agentCmd.AddCommand(agent.NewWaitForCmd())
+	agentCmd.AddCommand(agent.NewGatherCmd())
+	agentCmd.AddCommand(agent.NewServeCmd())
+	agentCmd.AddCommand(agent.NewBootHostsCmd())
+	agentCmd.AddCommand(agent.NewWipeHostsCmd())
+	agentCmd.AddCommand(agent.NewHostCmd())
+	agentCmd.AddCommand(agent.NewAbortCmd())
+	agentCmd.AddCommand(agent.NewTroubleshootCmd())
 	agentCmd.AddCommand(newAgentGraphCmd())
 	return agentCmd
 }
@@ -76,7 +86,13 @@ var (
 		command: &cobra.Command{
 			Use:   "config-image",
 			Short: "Generates an ISO containing configuration files only",
-			Args:  cobra.ExactArgs(0),
+			Long: `Generate an ISO containing the configuration of the cluster only, the
+manifests, the secrets and the network configuration of the hosts.
+
+Attached to hosts booting the generic image of agent create unconfigured-image,
+it configures them to install the cluster. The generic image can be flashed on
+the hosts before the configuration of their site is known.`,
+			Args: cobra.ExactArgs(0),
 		},
 		assets: []asset.WritableAsset{
 			&configimage.ConfigImage{},
@@ -99,6 +115,18 @@ var (
 		},
 	}
 
+	agentUnconfiguredImageTarget = target{
+		name: "Agent unconfigured image",
+		command: &cobra.Command{
+			Use:   "unconfigured-image",
+			Short: "Generates a generic bootable image excluding the cluster configuration, configured with the ISO of config-image",
+			Args:  cobra.ExactArgs(0),
+		},
+		assets: []asset.WritableAsset{
+			&image.UnconfiguredImage{},
+		},
+	}
+
 	agentUnconfiguredIgnitionTarget = target{
 		name: "Agent unconfigured ignition",
 		command: &cobra.Command{
@@ -112,9 +140,24 @@ var (
 		},
 	}
 
-	agentTargets = []target{agentConfigTarget, agentManifestsTarget, agentImageTarget, agentPXEFilesTarget, agentConfigImageTarget, agentUnconfiguredIgnitionTarget}
+	agentTargets = []target{agentConfigTarget, agentManifestsTarget, agentImageTarget, agentPXEFilesTarget, agentConfigImageTarget, agentUnconfiguredImageTarget, agentUnconfiguredIgnitionTarget}
 )
 
+var agentCreateOpts struct {
+	interactiveConfig  string
+	minimalISO         bool
+	reverseManifests   bool
+	embedRelease       bool
+	embedImages        []string
+	embedRegistryImage string
+	pxeFormat          string
+	ukiSigningKey      string
+	ukiSigningCert     string
+	ukiRootFSURL       string
+	skipMirrorCheck    bool
+	restAPIAuth        bool
+}
+
 func newAgentCreateCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
@@ -126,10 +169,57 @@ func newAgentCreateCmd() *cobra.Command {
 	}
 
 	for _, t := range agentTargets {
+		run := runTargetCmd(t.assets...)
 		t.command.Args = cobra.ExactArgs(0)
-		t.command.Run = runTargetCmd(t.assets...)
+		t.command.Run = func(cmd *cobra.Command, args []string) {
+			image.InteractiveConfigFile = agentCreateOpts.interactiveConfig
+			image.MinimalISO = agentCreateOpts.minimalISO
+			image.EmbedRelease = agentCreateOpts.embedRelease
+			image.EmbedImages = agentCreateOpts.embedImages
+			image.EmbedRegistryImage = agentCreateOpts.embedRegistryImage
+			image.PXEFilesFormat = agentCreateOpts.pxeFormat
+			image.UKISigningKeyFile = agentCreateOpts.ukiSigningKey
+			image.UKISigningCertFile = agentCreateOpts.ukiSigningCert
+			image.UKIRootFSURL = agentCreateOpts.ukiRootFSURL
+			image.SkipMirrorCheck = agentCreateOpts.skipMirrorCheck
+			image.RestAPIAuthEnabled = agentCreateOpts.restAPIAuth
+			run(cmd, args)
+		}
 		cmd.AddCommand(t.command)
 	}
+	manifestsRun := agentManifestsTarget.command.Run
+	agentManifestsTarget.command.Run = func(cmd *cobra.Command, args []string) {
+		if !agentCreateOpts.reverseManifests {
+			manifestsRun(cmd, args)
+			return
+		}
+		cleanup := command.SetupFileHook(command.RootOpts.Dir)
+		defer cleanup()
+		if err := agentpkg.ConvertClusterManifests(command.RootOpts.Dir); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+	agentManifestsTarget.command.Flags().BoolVar(&agentCreateOpts.reverseManifests, "reverse", false, "convert the AgentClusterInstall, ClusterDeployment, InfraEnv, NMStateConfig and pull secret manifests of the cluster-manifests directory, e.g. rendered for GitOps ZTP, into the install-config.yaml and agent-config.yaml they are generated from. SiteConfig manifests are not converted")
+	cmd.AddCommand(newAgentCreateAddNodesImageCmd())
+	for _, t := range []target{agentImageTarget, agentPXEFilesTarget, agentUnconfiguredImageTarget, agentUnconfiguredIgnitionTarget} {
+		t.command.Flags().StringVar(&agentCreateOpts.interactiveConfig, "interactive-config", "", "YAML file pre-seeding the network configuration of the interactive console of the agent, with the rendezvousIP of agent-config.yaml, and masking the console when unattended")
+	}
+	for _, t := range []target{agentImageTarget, agentPXEFilesTarget} {
+		t.command.Flags().BoolVar(&agentCreateOpts.restAPIAuth, "rest-api-auth", false, "serve the Agent Rest API of the rendezvous host over HTTPS with a generated CA and tokens, written to the image and the asset directory. Requires agent services supporting TLS and the local authentication of assisted-service")
+		t.command.Flags().BoolVar(&agentCreateOpts.skipMirrorCheck, "skip-mirror-check", false, "skip the check that the mirror registries, or the source registries they fall back to, serve the images of the release payload, e.g. when the mirror registries are only reachable from the hosts")
+	}
+	agentImageTarget.command.Flags().BoolVar(&agentCreateOpts.minimalISO, "minimal", false, "create a minimal ISO without the rootfs, written to the boot-artifacts directory to be served at the bootArtifactsBaseURL of agent-config.yaml, which the hosts download it from when they boot")
+	agentImageTarget.command.Flags().BoolVar(&agentCreateOpts.embedRelease, "embed-release", false, "copy the images of the release payload into a release payload ISO written alongside the agent ISO, served by a local registry on the hosts it is attached to so that the installation pulls no image from a remote registry. Requires skopeo")
+	agentImageTarget.command.Flags().StringSliceVar(&agentCreateOpts.embedImages, "embed-images", nil, "comma-separated images, e.g. of the operators, copied into the release payload ISO along with the images of the release payload, with --embed-release")
+	agentImageTarget.command.Flags().StringVar(&agentCreateOpts.embedRegistryImage, "embed-registry-image", "", "image of the registry serving the release payload on the hosts, e.g. ghcr.io/project-zot/zot-linux-amd64@sha256:<digest>, referenced by digest and copied into the release payload ISO for the architecture of the hosts. Required with --embed-release")
+	pxeFlags := agentPXEFilesTarget.command.Flags()
+	pxeFlags.StringVar(&agentCreateOpts.pxeFormat, "format", image.PXEFormatPXE, "format of the PXE files, pxe for the kernel, initrd and rootfs, or uki to also create a Unified Kernel Image for UEFI HTTP boot")
+	pxeFlags.StringVar(&agentCreateOpts.ukiSigningKey, "uki-signing-key", "", "private key signing the Unified Kernel Images for Secure Boot")
+	pxeFlags.StringVar(&agentCreateOpts.ukiSigningCert, "uki-signing-cert", "", "certificate signing the Unified Kernel Images for Secure Boot")
+	pxeFlags.StringVar(&agentCreateOpts.ukiRootFSURL, "uki-rootfs-url", "", "URL the hosts booting the Unified Kernel Images download the rootfs from, %s being replaced by the architecture (default the rootfs at the bootArtifactsBaseURL of agent-config.yaml)")
+	if err := agentPXEFilesTarget.command.RegisterFlagCompletionFunc("format", command.CompleteValues(image.PXEFormatPXE, image.PXEFormatUKI)); err != nil {
+		logrus.Debugf("Failed to register completion for flag format: %v", err)
+	}
 
 	return cmd
 }
@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
+)
+
+// NewAbortCmd creates the command aborting an agent based installation.
+func NewAbortCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "abort",
+		Short: "Abort the installation of the cluster",
+		Long: `Abort the installation of the cluster through the Agent Rest API of the
+rendezvous host.
+
+The installation is cancelled when it has started, stopping the installation
+of the hosts, and is then reset. The hosts are discovered again once they
+boot from the agent ISO, e.g. with agent boot-hosts, after which the
+installation can start again.
+
+The installation cannot be aborted once the rendezvous host has rebooted
+into the cluster.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := agentpkg.AbortInstall(context.Background(), command.RootOpts.Dir); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	addProxyFlag(cmd.Flags())
+	return cmd
+}
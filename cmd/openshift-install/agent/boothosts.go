@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
+)
+
+// NewBootHostsCmd creates the command booting the hosts of an agent based
+// installation on the agent ISO through their BMC.
+func NewBootHostsCmd() *cobra.Command {
+	var isoURL string
+	var noWait bool
+	cmd := &cobra.Command{
+		Use:   "boot-hosts",
+		Short: "Boot the hosts on the agent ISO through their BMC and wait for the installation",
+		Long: `Boot the hosts on the agent ISO through their BMC and wait for the installation.
+
+The ISO at --iso-url, created by agent create image and published on a web
+server reachable from the BMCs, is inserted in the Redfish virtual media of
+each host with a bmc in agent-config.yaml. Each host is then set to boot from
+the virtual media once and powered on, or restarted when it is already on.
+
+Once every host was booted, the command waits until the installation is
+complete, as agent wait-for install-complete does.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			assetDir := command.RootOpts.Dir
+			if err := agentpkg.BootHosts(context.Background(), assetDir, isoURL); err != nil {
+				logrus.Fatal(err)
+			}
+			if noWait {
+				return
+			}
+			waitForInstallComplete(assetDir)
+		},
+	}
+	cmd.Flags().StringVar(&isoURL, "iso-url", "", "URL of the agent ISO, downloaded by the BMCs")
+	cmd.Flags().BoolVar(&noWait, "no-wait", false, "Return once the hosts were booted without waiting for the installation")
+	if err := cmd.MarkFlagRequired("iso-url"); err != nil {
+		logrus.Debugf("Failed to mark flag iso-url as required: %v", err)
+	}
+	return cmd
+}
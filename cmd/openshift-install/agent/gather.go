@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
+)
+
+// NewGatherCmd creates the command gathering the logs of an agent based
+// cluster installation.
+func NewGatherCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "gather",
+		Short: "Gather debugging data for a failing agent based installation",
+		Long: `Gather debugging data for a failing agent based installation.
+
+The host and controller logs and the events are downloaded from the Agent Rest
+API on the rendezvous host, and the output of agent-gather is pulled from the
+rendezvous host over SSH. The logs are gathered as long as either works, e.g.
+when no SSH key was provisioned.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			bundlePath, err := agentpkg.GatherLogs(context.Background(), command.RootOpts.Dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			logrus.Infof("Agent gather logs captured here %q", bundlePath)
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
+)
+
+// NewHostCmd creates the commands acting on the hosts of an agent based
+// installation through the Agent Rest API.
+func NewHostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "host",
+		Short: "Act on the hosts of an agent based installation",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newHostResetCmd())
+	addProxyFlag(cmd.PersistentFlags())
+	return cmd
+}
+
+func newHostResetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset <hostname>",
+		Short: "Retry the installation of the cluster after a host failed",
+		Long: `Retry the installation of the cluster after a host failed.
+
+The host, given by its hostname or its ID in the Agent Rest API of the
+rendezvous host, must have failed. The Agent Rest API cannot reset a single
+host before the cluster is installed, so the installation of the cluster is
+cancelled and reset, and the hosts which started writing to their disk have
+to boot from the agent ISO again to register, e.g. with agent boot-hosts or
+their BMC console. The installation of the cluster is triggered again once
+all the hosts have registered and passed their validations.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			ctx := context.Background()
+			if err := agentpkg.ResetHost(ctx, command.RootOpts.Dir, args[0], command.RootOpts.Timeout); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	return cmd
+}
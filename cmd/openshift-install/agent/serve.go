@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
+)
+
+// NewServeCmd creates the command serving the PXE boot artifacts of an agent
+// based installation over HTTP.
+func NewServeCmd() *cobra.Command {
+	var opts agentpkg.ServeOptions
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the PXE boot artifacts over HTTP",
+		Long: `Serve the PXE boot artifacts over HTTP.
+
+The kernel, initrd, rootfs and iPXE script created by agent create pxe-files in
+the boot-artifacts directory of the assets directory are served until the
+command is interrupted, to PXE boot the hosts without a separate web server.
+The iPXE script is only created when bootArtifactsBaseURL is set in
+agent-config.yaml, which must then be the URL of this server.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if err := agentpkg.ServePXEArtifacts(ctx, command.RootOpts.Dir, opts); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Address, "bind-address", ":8080", "Address to listen on, as [host]:port")
+	cmd.Flags().StringVar(&opts.CertFile, "tls-cert", "", "Path of the TLS certificate to serve over HTTPS, with --tls-key")
+	cmd.Flags().StringVar(&opts.KeyFile, "tls-key", "", "Path of the TLS private key to serve over HTTPS, with --tls-cert")
+	return cmd
+}
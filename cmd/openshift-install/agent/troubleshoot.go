@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
+)
+
+// NewTroubleshootCmd creates the command showing the failed validations of an
+// agent based installation with how to fix them.
+func NewTroubleshootCmd() *cobra.Command {
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:   "troubleshoot",
+		Short: "Show the failed validations of the cluster and of its hosts with how to fix them",
+		Long: `Show the failed validations of the cluster and of its hosts with how to fix them.
+
+The validations are retrieved from the Agent Rest API of the rendezvous
+host. Each failed validation, e.g. ntp-synced or has-min-valid-disks, is
+shown with its message and a remediation, e.g. the firewall ports to open,
+the NTP sources or the root device hints to set in agent-config.yaml.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := agentpkg.Troubleshoot(context.Background(), command.RootOpts.Dir, os.Stdout, asJSON); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Write the failed validations and their remediations as JSON")
+	addProxyFlag(cmd.Flags())
+	return cmd
+}
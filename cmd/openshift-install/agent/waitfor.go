@@ -2,13 +2,19 @@ package agent
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	terminal "golang.org/x/term"
 
 	"github.com/openshift/installer/cmd/openshift-install/command"
 	agentpkg "github.com/openshift/installer/pkg/agent"
+	"github.com/openshift/installer/pkg/asset/agent/image"
 )
 
 const (
@@ -18,6 +24,23 @@ const (
 	exitCodeInstallFailed
 )
 
+var addNodesOpts struct {
+	hostIPs    []string
+	kubeconfig string
+}
+
+// recordEvents records the events of the Agent Rest API to agent-events.jsonl
+// in the assets directory.
+var recordEvents bool
+
+// validationsOutput is the path of the file receiving the validations of the
+// cluster and of its hosts as JSON, or - for the standard output.
+var validationsOutput string
+
+// watch shows a table of the hosts with the progress of their installation
+// on the standard output.
+var watch bool
+
 // NewWaitForCmd create the commands for waiting the completion of the agent based cluster installation.
 func NewWaitForCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -31,9 +54,65 @@ func NewWaitForCmd() *cobra.Command {
 
 	cmd.AddCommand(newWaitForBootstrapCompleteCmd())
 	cmd.AddCommand(newWaitForInstallCompleteCmd())
+	cmd.AddCommand(newWaitForAddNodesCmd())
+	command.AddPollFlags(cmd.PersistentFlags())
 	return cmd
 }
 
+// addClusterWaitFlags adds the flags of the commands waiting on the Agent Rest
+// API of the rendezvous host for the installation of the cluster.
+func addClusterWaitFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&recordEvents, "record-events", false, "Append the events of the cluster and of its hosts to "+agentpkg.EventsFileName+" in the assets directory as they are retrieved, skipping the ones already recorded")
+	addProxyFlag(flags)
+	flags.BoolVar(&watch, "watch", false, "Show a table of the hosts with their role, installation stage (discovering, installing, rebooting, done) and percentage, refreshed from the Agent Rest API until the bootstrap is complete")
+	flags.StringVar(&validationsOutput, "validations-output", "", "Write the validations of the cluster and of each host, e.g. NTP, disk size and connectivity, as lines of JSON to this file whenever they change, or - for the standard output")
+}
+
+// setValidationsOutput sets the output of the validations of the cluster, and
+// returns the function closing it.
+func setValidationsOutput(cluster *agentpkg.Cluster) func() {
+	switch validationsOutput {
+	case "":
+		return func() {}
+	case "-":
+		cluster.ValidationsOutput = os.Stdout
+		return func() {}
+	}
+	file, err := os.OpenFile(validationsOutput, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		logrus.Fatal(errors.Wrap(err, "failed to open the validations output"))
+	}
+	cluster.ValidationsOutput = file
+	return func() {
+		if err := file.Close(); err != nil {
+			logrus.Warn(errors.Wrap(err, "failed to close the validations output"))
+		}
+	}
+}
+
+// addProxyFlag adds the flag of the proxy through which the Agent Rest API of
+// the rendezvous host is reached.
+func addProxyFlag(flags *pflag.FlagSet) {
+	flags.StringVar(&agentpkg.RestAPIProxy, "proxy", "", "URL of the proxy through which the Agent Rest API of the rendezvous host is reached, bypassed for the hosts in NO_PROXY, instead of the proxy of install-config.yaml")
+}
+
+// setWatch shows the progress of the hosts of the cluster on the standard
+// output when --watch is set, redrawing it when it is a terminal.
+func setWatch(cluster *agentpkg.Cluster) {
+	if watch {
+		cluster.WatchHosts(os.Stdout, terminal.IsTerminal(int(os.Stdout.Fd())))
+	}
+}
+
+// waitDeadline returns the deadline of the whole wait set by --timeout, or the
+// zero time when it is not set and each stage waits for its default timeout.
+func waitDeadline() time.Time {
+	if command.RootOpts.Timeout > 0 {
+		return time.Now().Add(command.RootOpts.Timeout)
+	}
+	return time.Time{}
+}
+
 func handleBootstrapError(cluster *agentpkg.Cluster, err error) {
 	logrus.Debug("Printing the event list gathered from the Agent Rest API")
 	cluster.PrintInfraEnvRestAPIEventList()
@@ -42,13 +121,13 @@ func handleBootstrapError(cluster *agentpkg.Cluster, err error) {
 		logrus.Error("Attempted to gather ClusterOperator status after wait failure: ", err2)
 	}
 	logrus.Info("Use the following commands to gather logs from the cluster")
-	logrus.Info("openshift-install gather bootstrap --help")
+	logrus.Info("openshift-install agent gather --help")
 	logrus.Error(errors.Wrap(err, "Bootstrap failed to complete: "))
 	logrus.Exit(exitCodeBootstrapFailed)
 }
 
 func newWaitForBootstrapCompleteCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "bootstrap-complete",
 		Short: "Wait until the cluster bootstrap is complete",
 		Args:  cobra.ExactArgs(0),
@@ -62,21 +141,32 @@ func newWaitForBootstrapCompleteCmd() *cobra.Command {
 				logrus.Fatal("No cluster installation directory found")
 			}
 
+			deadline := waitDeadline()
 			ctx := context.Background()
 			cluster, err := agentpkg.NewCluster(ctx, assetDir)
 			if err != nil {
 				logrus.Exit(exitCodeBootstrapFailed)
 			}
+			closeValidationsOutput := setValidationsOutput(cluster)
+			defer closeValidationsOutput()
+			setWatch(cluster)
+			if recordEvents {
+				if err := cluster.RecordEvents(); err != nil {
+					logrus.Fatal(err)
+				}
+			}
 
-			if err := agentpkg.WaitForBootstrapComplete(cluster); err != nil {
+			if err := agentpkg.WaitForBootstrapComplete(cluster, agentpkg.TimeLeft(deadline)); err != nil {
 				handleBootstrapError(cluster, err)
 			}
 		},
 	}
+	addClusterWaitFlags(cmd.Flags())
+	return cmd
 }
 
 func newWaitForInstallCompleteCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "install-complete",
 		Short: "Wait until the cluster installation is complete",
 		Args:  cobra.ExactArgs(0),
@@ -89,29 +179,101 @@ func newWaitForInstallCompleteCmd() *cobra.Command {
 			if len(assetDir) == 0 {
 				logrus.Fatal("No cluster installation directory found")
 			}
+			waitForInstallComplete(assetDir)
+		},
+	}
+	addClusterWaitFlags(cmd.Flags())
+	return cmd
+}
 
-			ctx := context.Background()
-			cluster, err := agentpkg.NewCluster(ctx, assetDir)
-			if err != nil {
-				logrus.Exit(exitCodeBootstrapFailed)
+// waitForInstallComplete waits until the bootstrap and then the installation
+// of the cluster are complete, exiting with the matching code on failure.
+func waitForInstallComplete(assetDir string) {
+	deadline := waitDeadline()
+	ctx := context.Background()
+	cluster, err := agentpkg.NewCluster(ctx, assetDir)
+	if err != nil {
+		logrus.Exit(exitCodeBootstrapFailed)
+	}
+	closeValidationsOutput := setValidationsOutput(cluster)
+	defer closeValidationsOutput()
+	setWatch(cluster)
+	if recordEvents {
+		if err := cluster.RecordEvents(); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	if err := agentpkg.WaitForBootstrapComplete(cluster, agentpkg.TimeLeft(deadline)); err != nil {
+		handleBootstrapError(cluster, err)
+	}
+
+	if err = agentpkg.WaitForInstallComplete(cluster, agentpkg.TimeLeft(deadline)); err != nil {
+		logrus.Error(err)
+		err2 := cluster.API.OpenShift.LogClusterOperatorConditions()
+		if err2 != nil {
+			logrus.Error("Attempted to gather ClusterOperator status after wait failure: ", err2)
+		}
+		logrus.Error(`Cluster initialization failed because one or more operators are not functioning properly.
+		The cluster should be accessible for troubleshooting as detailed in the documentation linked below,
+		https://docs.openshift.com/container-platform/latest/support/troubleshooting/troubleshooting-installations.html`)
+		logrus.Exit(exitCodeInstallFailed)
+	}
+	cluster.PrintInstallationComplete()
+}
+
+func newWaitForAddNodesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add-nodes",
+		Short: "Wait until the hosts added to an installed cluster have joined it",
+		Long: `Wait until the hosts booted with an agent ISO to be added to an installed
+cluster have joined it, reporting the status of each host as it goes through
+discovery, validation, installation and the approval of the certificate
+signing requests of its node.
+
+Without --node-ips, the hosts of the ` + image.NodesConfigFilename + ` of the assets directory,
+booted with the image of agent create add-nodes-image, are waited for by
+their host names.
+
+The certificate signing requests of the kubelet of each host are approved
+when the client certificate is requested by the node bootstrapper for the
+host name before the node registered, and the serving certificate by the
+node itself for its own addresses.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			assetDir := cmd.Flags().Lookup("dir").Value.String()
+			logrus.Debugf("asset directory: %s", assetDir)
+			if len(assetDir) == 0 {
+				logrus.Fatal("No cluster installation directory found")
 			}
 
-			if err := agentpkg.WaitForBootstrapComplete(cluster); err != nil {
-				handleBootstrapError(cluster, err)
+			var hostnames []string
+			if len(addNodesOpts.hostIPs) == 0 {
+				nodesConfig, err := image.LoadNodesConfig(assetDir)
+				if err != nil {
+					logrus.Fatal(errors.Wrap(err, "no --node-ips given"))
+				}
+				for _, host := range nodesConfig.Hosts {
+					hostnames = append(hostnames, host.Hostname)
+				}
+			}
+			kubeconfig := addNodesOpts.kubeconfig
+			if kubeconfig == "" {
+				kubeconfig = filepath.Join(assetDir, "auth", "kubeconfig")
 			}
 
-			if err = agentpkg.WaitForInstallComplete(cluster); err != nil {
+			ctx := context.Background()
+			if err := agentpkg.WaitForAddNodes(ctx, kubeconfig, addNodesOpts.hostIPs, hostnames, agentpkg.TimeLeft(waitDeadline())); err != nil {
 				logrus.Error(err)
-				err2 := cluster.API.OpenShift.LogClusterOperatorConditions()
-				if err2 != nil {
-					logrus.Error("Attempted to gather ClusterOperator status after wait failure: ", err2)
-				}
-				logrus.Error(`Cluster initialization failed because one or more operators are not functioning properly.
-				The cluster should be accessible for troubleshooting as detailed in the documentation linked below,
-				https://docs.openshift.com/container-platform/latest/support/troubleshooting/troubleshooting-installations.html`)
 				logrus.Exit(exitCodeInstallFailed)
 			}
-			cluster.PrintInstallationComplete()
+			logrus.Info("All hosts have joined the cluster")
 		},
 	}
+	cmd.Flags().StringSliceVar(&addNodesOpts.hostIPs, "node-ips", nil, "comma-separated IPs of the hosts to wait for, the hosts of "+image.NodesConfigFilename+" when empty")
+	cmd.Flags().StringVar(&addNodesOpts.kubeconfig, "kubeconfig", "", "kubeconfig of the cluster, auth/kubeconfig of the assets directory when empty")
+	return cmd
 }
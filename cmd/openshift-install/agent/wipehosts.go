@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	agentpkg "github.com/openshift/installer/pkg/agent"
+)
+
+// NewWipeHostsCmd creates the command erasing the disks of the hosts of an
+// agent based installation through their BMC.
+func NewWipeHostsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wipe-hosts",
+		Short: "Securely erase the disks of the hosts through their BMC and power them off",
+		Long: `Securely erase the disks of the hosts through their BMC and power them off.
+
+The drives of each host with a bmc in agent-config.yaml are erased with the
+Redfish SecureErase action, waiting for the BMC to complete erasing them,
+and the host is then powered off. This removes the stale RAID and partition
+signatures of the cluster, which otherwise break re-installing the hosts.
+
+Agent based installations have no metadata.json, so destroy cluster
+--wipe-disks does not apply to them.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := agentpkg.WipeHosts(context.Background(), command.RootOpts.Dir); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	return cmd
+}
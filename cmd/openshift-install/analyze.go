@@ -1,19 +1,23 @@
 package main
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/gather/diagnosis"
 	"github.com/openshift/installer/pkg/gather/service"
 )
 
 var (
 	analyzeOpts struct {
 		gatherBundle string
+		signatures   string
 	}
 )
 
@@ -23,7 +27,16 @@ func newAnalyzeCmd() *cobra.Command {
 		Short: "Analyze debugging data for a given installation failure",
 		Long: `Analyze debugging data for a given installation failure.
 
-This command helps users to analyze the reasons for an installation that failed while bootstrapping.`,
+This command helps users to analyze the reasons for an installation that failed while bootstrapping.
+It matches the logs of the gather bundle, or of an agent log bundle, with known
+failure signatures, e.g. Ignition fetch failures, etcd quorum loss, image pull
+errors and DNS resolution failures, and prints the probable causes, from the
+most to the least likely, with hints to fix them. The classification of the
+failure is written to failure.json in the assets directory, with a stable code
+for CI systems to aggregate the failures.
+
+The failure signatures are shipped with the installer, and may be replaced
+with a newer signature bundle with --signatures.`,
 		Args: cobra.ExactArgs(0),
 		Run: func(_ *cobra.Command, _ []string) {
 			gatherBundle := analyzeOpts.gatherBundle
@@ -37,15 +50,51 @@ This command helps users to analyze the reasons for an installation that failed
 			if !filepath.IsAbs(gatherBundle) {
 				gatherBundle = filepath.Join(command.RootOpts.Dir, gatherBundle)
 			}
-			if err := service.AnalyzeGatherBundle(gatherBundle); err != nil {
+			// The agent log bundles do not hold the bootstrap services
+			// analyzed.
+			if !strings.HasSuffix(gatherBundle, ".tar.xz") && !strings.HasPrefix(filepath.Base(gatherBundle), "agent-") {
+				if err := service.AnalyzeGatherBundle(gatherBundle); err != nil {
+					logrus.Fatal(err)
+				}
+			}
+			findings, err := diagnoseGatherBundle(gatherBundle)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if err := diagnosis.Print(os.Stdout, findings); err != nil {
 				logrus.Fatal(err)
 			}
 		},
 	}
-	cmd.PersistentFlags().StringVar(&analyzeOpts.gatherBundle, "file", "", "Filename of the bootstrap gather bundle; either absolute or relative to the assets directory")
+	cmd.PersistentFlags().StringVar(&analyzeOpts.signatures, "signatures", "", "Path of a YAML bundle of failure signatures replacing the ones shipped with the installer")
+	cmd.PersistentFlags().StringVar(&analyzeOpts.gatherBundle, "file", "", "Filename of the bootstrap gather bundle or agent log bundle; either absolute or relative to the assets directory")
 	return cmd
 }
 
+// diagnoseGatherBundle diagnoses the gather bundle and writes the failure
+// report to the assets directory.
+func diagnoseGatherBundle(bundlePath string) ([]diagnosis.Finding, error) {
+	signatures := diagnosis.Signatures
+	if analyzeOpts.signatures != "" {
+		var err error
+		signatures, err = diagnosis.LoadSignaturesFile(analyzeOpts.signatures)
+		if err != nil {
+			return nil, err
+		}
+	}
+	findings, err := diagnosis.DiagnoseBundle(bundlePath, signatures)
+	if err != nil {
+		return nil, err
+	}
+	report := diagnosis.NewReport(bundlePath, findings)
+	reportPath, err := diagnosis.WriteReport(command.RootOpts.Dir, report)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("Failure classified as %s in %q", report.Code, reportPath)
+	return findings, nil
+}
+
 func getGatherBundleFromAssetsDirectory() (string, error) {
 	matches, err := filepath.Glob(filepath.Join(command.RootOpts.Dir, "log-bundle-*.tar.gz"))
 	if err != nil {
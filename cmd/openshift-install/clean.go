@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+)
+
+var cleanOpts struct {
+	all bool
+}
+
+// cleanTargets are the targets which can be cleaned, in the order they are
+// generated. Cleaning a target also cleans the targets after it.
+var cleanTargets = []target{installConfigTarget, manifestsTarget, ignitionConfigsTarget, singleNodeIgnitionConfigTarget}
+
+func newCleanCmd() *cobra.Command {
+	names := make([]string, 0, len(cleanTargets))
+	for _, t := range cleanTargets {
+		names = append(names, t.command.Use)
+	}
+
+	cmd := &cobra.Command{
+		Use:   fmt.Sprintf("clean [%s]", strings.Join(names, "|")),
+		Short: "Removes the generated assets of a target from the asset directory",
+		Long: `Removes the assets of the target, and of the targets generated after it,
+from the asset directory and from the installer state, so that they are
+generated again by the next create command.
+
+The assets of the earlier targets are kept. Those which were consumed by a
+later target, e.g. the install-config.yaml consumed when the manifests
+were created, are written back to the asset directory so that they can be
+edited before the assets are generated again.
+
+With --all, every generated asset and the installer state are removed.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if cleanOpts.all {
+				return cobra.NoArgs(cmd, args)
+			}
+			if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+				return err
+			}
+			return cobra.OnlyValidArgs(cmd, args)
+		},
+		ValidArgs: names,
+		Run: func(_ *cobra.Command, args []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if err := runCleanCmd(command.RootOpts.Dir, name); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&cleanOpts.all, "all", false, "remove every generated asset and the installer state")
+	return cmd
+}
+
+// runCleanCmd removes the assets of the named target and of the targets after
+// it, and restores the consumed assets of the targets before it. An empty
+// name removes every asset and the installer state.
+func runCleanCmd(directory string, name string) error {
+	tfstateFiles, err := filepath.Glob(filepath.Join(directory, "*.tfstate"))
+	if err != nil {
+		return errors.Wrap(err, "failed to glob for tfstate files")
+	}
+	if len(tfstateFiles) > 0 {
+		return errors.Errorf("a cluster may have been created from %s, run destroy cluster first", directory)
+	}
+
+	index := 0
+	if name != "" {
+		index = -1
+		for i, t := range cleanTargets {
+			if t.command.Use == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return errors.Errorf("invalid target %q", name)
+		}
+	}
+
+	var restore []asset.WritableAsset
+	for _, t := range cleanTargets[:index] {
+		restore = append(restore, t.assets...)
+	}
+	var remove []asset.WritableAsset
+	for _, t := range cleanTargets[index:] {
+		remove = append(remove, t.assets...)
+	}
+	remove, restore = uniqueAssets(remove, nil), uniqueAssets(restore, remove)
+
+	if err := assetstore.Clean(directory, remove, restore); err != nil {
+		return err
+	}
+
+	if name == "" {
+		store, err := assetstore.NewStore(directory)
+		if err != nil {
+			return errors.Wrap(err, "failed to create asset store")
+		}
+		if err := store.DestroyState(); err != nil {
+			return errors.Wrap(err, "failed to remove state file")
+		}
+	}
+	return nil
+}
+
+// uniqueAssets returns the assets without duplicates, and without the assets
+// of the same type as any of the excluded ones.
+func uniqueAssets(assets []asset.WritableAsset, excluded []asset.WritableAsset) []asset.WritableAsset {
+	seen := map[reflect.Type]bool{}
+	for _, a := range excluded {
+		seen[reflect.TypeOf(a)] = true
+	}
+	unique := make([]asset.WritableAsset, 0, len(assets))
+	for _, a := range assets {
+		if seen[reflect.TypeOf(a)] {
+			continue
+		}
+		seen[reflect.TypeOf(a)] = true
+		unique = append(unique, a)
+	}
+	return unique
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/pkg/asset/installconfig"
+	"github.com/openshift/installer/pkg/plugin"
+	"github.com/openshift/installer/pkg/types"
+)
+
+// regionCompletionTimeout bounds how long region completion may spend
+// querying cloud APIs, so the shell does not hang.
+const regionCompletionTimeout = 10 * time.Second
+
+// Platforms returns the sorted names of the platforms supported by the
+// installer as well as the ones provided by provider plugins.
+func Platforms() []string {
+	platforms := append(append([]string{}, types.PlatformNames...), types.HiddenPlatformNames...)
+	platforms = append(platforms, plugin.List()...)
+	sort.Strings(platforms)
+	return platforms
+}
+
+// CompletePlatforms is a cobra completion function suggesting the platforms
+// returned by Platforms.
+func CompletePlatforms(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return filterPrefix(Platforms(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteRegions returns a cobra completion function suggesting the regions
+// known for the platform given in the platformFlag flag of the command. The
+// regions are only listed with the credentials already configured, the user
+// is never prompted for them.
+func CompleteRegions(platformFlag string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		platform, err := cmd.Flags().GetString(platformFlag)
+		if err != nil || platform == "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), regionCompletionTimeout)
+		defer cancel()
+		regions, err := installconfig.KnownRegions(ctx, platform)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := make([]string, 0, len(regions))
+		for id, description := range regions {
+			if strings.HasPrefix(id, toComplete) {
+				completions = append(completions, id+"\t"+description)
+			}
+		}
+		sort.Strings(completions)
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteValues returns a cobra completion function suggesting the given static values.
+func CompleteValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	var filtered []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
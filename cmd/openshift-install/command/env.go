@@ -0,0 +1,58 @@
+package command
+
+import (
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// EnvPrefix is the prefix of the environment variables which can be used to
+// set any command line flag.
+const EnvPrefix = "OPENSHIFT_INSTALL_"
+
+// EnvVarName returns the name of the environment variable for the flag,
+// e.g. OPENSHIFT_INSTALL_LOG_LEVEL for --log-level.
+func EnvVarName(flag string) string {
+	var name strings.Builder
+	name.WriteString(EnvPrefix)
+	for i, r := range flag {
+		switch {
+		case r == '-' || r == '.':
+			name.WriteRune('_')
+		case unicode.IsUpper(r):
+			if i > 0 {
+				name.WriteRune('_')
+			}
+			name.WriteRune(r)
+		default:
+			name.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return name.String()
+}
+
+// BindEnv sets every flag which was not given on the command line from its
+// environment variable, when that is set. Flags given explicitly always take
+// precedence over the environment.
+func BindEnv(flags *pflag.FlagSet) error {
+	var errs []string
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		value, ok := os.LookupEnv(EnvVarName(f.Name))
+		if !ok {
+			return
+		}
+		if err := flags.Set(f.Name, value); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid value for %s", EnvVarName(f.Name)).Error())
+		}
+	})
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvVarName(t *testing.T) {
+	for flag, expected := range map[string]string{
+		"dir":          "OPENSHIFT_INSTALL_DIR",
+		"log-level":    "OPENSHIFT_INSTALL_LOG_LEVEL",
+		"skipAnalysis": "OPENSHIFT_INSTALL_SKIP_ANALYSIS",
+	} {
+		assert.Equal(t, expected, EnvVarName(flag))
+	}
+}
+
+func TestBindEnv(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	dir := flags.String("dir", ".", "")
+	level := flags.String("log-level", "info", "")
+	quiet := flags.Bool("quiet", false, "")
+	flags.Int("retries", 0, "")
+	assert.NoError(t, flags.Parse([]string{"--log-level=warn"}))
+
+	t.Setenv("OPENSHIFT_INSTALL_DIR", "/tmp/assets")
+	t.Setenv("OPENSHIFT_INSTALL_LOG_LEVEL", "debug")
+	t.Setenv("OPENSHIFT_INSTALL_QUIET", "true")
+	assert.NoError(t, BindEnv(flags))
+
+	assert.Equal(t, "/tmp/assets", *dir)
+	assert.Equal(t, "warn", *level, "flags on the command line take precedence")
+	assert.True(t, *quiet)
+
+	t.Setenv("OPENSHIFT_INSTALL_RETRIES", "many")
+	assert.ErrorContains(t, BindEnv(flags), "invalid value for OPENSHIFT_INSTALL_RETRIES")
+}
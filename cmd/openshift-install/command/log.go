@@ -5,21 +5,114 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/openshift/installer/pkg/metrics/telemetry"
+	timer "github.com/openshift/installer/pkg/metrics/timer"
 	"github.com/openshift/installer/pkg/version"
 )
 
+const (
+	// LogFileName is the name of the install log file written to the assets directory.
+	LogFileName = ".openshift_install.log"
+
+	// logMaxBackups is the number of rotated log files which are kept.
+	logMaxBackups = 3
+)
+
+const (
+	// LogFormatText emits human-readable log lines.
+	LogFormatText = "text"
+	// LogFormatJSON emits newline-delimited JSON log records.
+	LogFormatJSON = "json"
+)
+
 var (
-	// RootOpts holds the log directory and log level configuration.
+	// RootOpts holds the log directory, log file, log level and log format
+	// configuration, as well as the destination for progress events,
+	// telemetry and notifications, the wait timeout override, whether the
+	// user may be prompted for input, where and how the installer state is
+	// stored, whether the assets are generated reproducibly, and the values
+	// of the install config template.
 	RootOpts struct {
-		Dir      string
-		LogLevel string
+		Dir                string
+		CacheDir           string
+		LogFile            string
+		LogMaxSize         int64
+		LogLevel           string
+		LogFormat          string
+		Color              string
+		ProgressFD         string
+		StateURL           string
+		NotifyURL          string
+		StateEncryptionKey string
+		Values             string
+		Timeout            time.Duration
+		NonInteractive     bool
+		Reproducible       bool
+		Concurrent         bool
+		Telemetry          telemetry.Options
 	}
 )
 
+const (
+	// ColorAuto colors the output only when writing to a terminal.
+	ColorAuto = "auto"
+	// ColorAlways always colors the output.
+	ColorAlways = "always"
+	// ColorNever never colors the output.
+	ColorNever = "never"
+)
+
+// jsonFormatter formats entries as JSON records, adding the currently
+// running install phase when one is known.
+type jsonFormatter struct {
+	logrus.JSONFormatter
+}
+
+// NewJSONFormatter returns a formatter which emits one JSON record per line
+// with level, timestamp, phase and message fields.
+func NewJSONFormatter() logrus.Formatter {
+	return &jsonFormatter{
+		JSONFormatter: logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime: "timestamp",
+				logrus.FieldKeyMsg:  "message",
+			},
+		},
+	}
+}
+
+func (f *jsonFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	phase := timer.CurrentStage()
+	if _, ok := entry.Data["phase"]; ok || phase == "" {
+		return f.JSONFormatter.Format(entry)
+	}
+
+	// copy the entry so the phase field does not leak into other hooks.
+	withPhase := *entry
+	withPhase.Data = make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		withPhase.Data[k] = v
+	}
+	withPhase.Data["phase"] = phase
+	return f.JSONFormatter.Format(&withPhase)
+}
+
+// ValidateLogFormat returns an error if the format is not a supported log format.
+func ValidateLogFormat(format string) error {
+	switch format {
+	case LogFormatText, LogFormatJSON:
+		return nil
+	default:
+		return errors.Errorf("unsupported log format %q, must be one of %q or %q", format, LogFormatText, LogFormatJSON)
+	}
+}
+
 type fileHook struct {
 	file      io.Writer
 	formatter logrus.Formatter
@@ -36,6 +129,11 @@ func newFileHook(file io.Writer, level logrus.Level, formatter logrus.Formatter)
 	}
 }
 
+// NewFileHook returns a new FileHook.
+func NewFileHook(file io.Writer, level logrus.Level, formatter logrus.Formatter) *fileHook {
+	return newFileHook(file, level, formatter)
+}
+
 // NewFileHookWithNewlineTruncate returns a new FileHook with truncated new lines.
 func NewFileHookWithNewlineTruncate(file io.Writer, level logrus.Level, formatter logrus.Formatter) *fileHook {
 	f := newFileHook(file, level, formatter)
@@ -82,13 +180,35 @@ func (h *fileHook) Fire(entry *logrus.Entry) error {
 	return nil
 }
 
+// UseColor returns whether output written to the terminal file should be
+// colored according to the color mode.
+func UseColor(mode string, isTerminal bool) (bool, error) {
+	switch mode {
+	case ColorAuto:
+		return isTerminal, nil
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	default:
+		return false, errors.Errorf("unsupported color mode %q, must be one of %q, %q or %q", mode, ColorAuto, ColorAlways, ColorNever)
+	}
+}
+
 // SetupFileHook creates the base log directory and configures logrus options.
+// The log is written to RootOpts.LogFile when set, or to LogFileName in the
+// base directory otherwise, and is rotated once it grows past RootOpts.LogMaxSize
+// megabytes.
 func SetupFileHook(baseDir string) func() {
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
+	path := RootOpts.LogFile
+	if path == "" {
+		path = filepath.Join(baseDir, LogFileName)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		logrus.Fatal(errors.Wrap(err, "failed to create base directory for logs"))
 	}
 
-	logfile, err := os.OpenFile(filepath.Join(baseDir, ".openshift_install.log"), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	logfile, err := openRotatingFile(path, RootOpts.LogMaxSize*1024*1024, logMaxBackups)
 	if err != nil {
 		logrus.Fatal(errors.Wrap(err, "failed to open log file"))
 	}
@@ -97,12 +217,16 @@ func SetupFileHook(baseDir string) func() {
 	for k, v := range logrus.StandardLogger().Hooks {
 		originalHooks[k] = v
 	}
-	logrus.AddHook(newFileHook(logfile, logrus.TraceLevel, &logrus.TextFormatter{
+	var formatter logrus.Formatter = &logrus.TextFormatter{
 		DisableColors:          true,
 		DisableTimestamp:       false,
 		FullTimestamp:          true,
 		DisableLevelTruncation: false,
-	}))
+	}
+	if RootOpts.LogFormat == LogFormatJSON {
+		formatter = NewJSONFormatter()
+	}
+	logrus.AddHook(newFileHook(logfile, logrus.TraceLevel, formatter))
 
 	versionString, err := version.String()
 	if err != nil {
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/pkg/metrics/timer"
+)
+
+func TestJSONFormatterLogSummary(t *testing.T) {
+	logger := logrus.StandardLogger()
+	formatter, out, level := logger.Formatter, logger.Out, logger.GetLevel()
+	var buf bytes.Buffer
+	logger.SetFormatter(NewJSONFormatter())
+	logger.SetOutput(&buf)
+	logger.SetLevel(logrus.DebugLevel)
+
+	timer.StartTimer(timer.TotalTimeElapsed)
+	timer.StartTimer("Cluster Operators Available")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		timer.LogSummary()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		// the logger is not restored, it is locked by the hanging entry.
+		t.Fatal("logging the summary of the timer with the JSON formatter hangs")
+	}
+	defer func() {
+		timer.StopTimer("Cluster Operators Available")
+		logger.SetFormatter(formatter)
+		logger.SetOutput(out)
+		logger.SetLevel(level)
+	}()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &record))
+	assert.Equal(t, "Cluster Operators Available", record["phase"])
+	assert.Contains(t, record["message"], "Time elapsed: ")
+}
+
+func TestUseColor(t *testing.T) {
+	cases := []struct {
+		name        string
+		mode        string
+		isTerminal  bool
+		expected    bool
+		expectedErr string
+	}{
+		{
+			name:       "auto with a terminal",
+			mode:       ColorAuto,
+			isTerminal: true,
+			expected:   true,
+		},
+		{
+			name:     "auto without a terminal",
+			mode:     ColorAuto,
+			expected: false,
+		},
+		{
+			name:     "always without a terminal",
+			mode:     ColorAlways,
+			expected: true,
+		},
+		{
+			name:       "never with a terminal",
+			mode:       ColorNever,
+			isTerminal: true,
+			expected:   false,
+		},
+		{
+			name:        "invalid",
+			mode:        "sometimes",
+			isTerminal:  true,
+			expectedErr: `unsupported color mode "sometimes", must be one of "auto", "always" or "never"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			color, err := UseColor(tc.mode, tc.isTerminal)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, color)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package command
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/installer/pkg/poll"
+)
+
+// AddPollFlags adds the flags setting the policy used to poll the APIs of the
+// cluster and of the agent installer while waiting for install-time events.
+func AddPollFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&poll.Options.Interval, "poll-interval", 0, "interval between two polls of the APIs, replacing the default of each wait (e.g. \"10s\")")
+	flags.DurationVar(&poll.Options.MaxInterval, "poll-max-interval", 0, "maximum interval between two polls when backing off, 0 for no maximum")
+	flags.Float64Var(&poll.Options.BackoffFactor, "poll-backoff-factor", 0, "factor by which the poll interval grows after each unsuccessful poll, 0 or 1 to keep it fixed")
+	flags.IntVar(&poll.Options.ErrorBudget, "api-error-budget", 0, "number of transient API errors in a row, e.g. refused connections, after which the wait fails, 0 for no limit")
+}
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotatingFile is an append-only log file which is rotated once it grows
+// past maxSize bytes. Rotated files are renamed with a numeric suffix, the
+// most recent being <path>.1, and at most maxBackups of them are kept.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p to the file, rotating it first if p would push it past the size limit.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	os.Remove(backupName(f.path, f.maxBackups))
+	for i := f.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backupName(f.path, i), backupName(f.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if f.maxBackups > 0 {
+		if err := os.Rename(f.path, backupName(f.path, 1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close closes the underlying file.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
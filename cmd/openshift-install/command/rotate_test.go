@@ -0,0 +1,54 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".openshift_install.log")
+	f, err := openRotatingFile(path, 10, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+
+	for name, expected := range map[string]string{
+		path:                "fourth\n",
+		backupName(path, 1): "third\n",
+		backupName(path, 2): "second\n",
+	} {
+		data, err := os.ReadFile(name)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, string(data), name)
+		}
+	}
+	_, err = os.Stat(backupName(path, 3))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRotatingFileUnlimited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".openshift_install.log")
+	f, err := openRotatingFile(path, 0, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n"} {
+		_, err := f.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	data, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "first\nsecond\n", string(data))
+	}
+}
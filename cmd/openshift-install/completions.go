@@ -3,7 +3,10 @@ package main
 import (
 	"os"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
 )
 
 var (
@@ -70,3 +73,45 @@ func newCompletionCmd() *cobra.Command {
 
 	return completionCmd
 }
+
+// registerCompletions sets up the dynamic completions for the flags and
+// subcommands of the root command.
+func registerCompletions(rootCmd *cobra.Command) {
+	levels := make([]string, 0, len(logrus.AllLevels))
+	for _, level := range logrus.AllLevels {
+		levels = append(levels, level.String())
+	}
+	for flag, completion := range map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"log-level":  command.CompleteValues(levels...),
+		"log-format": command.CompleteValues(command.LogFormatText, command.LogFormatJSON),
+		"color":      command.CompleteValues(command.ColorAuto, command.ColorAlways, command.ColorNever),
+	} {
+		if err := rootCmd.RegisterFlagCompletionFunc(flag, completion); err != nil {
+			logrus.Debugf("Failed to register completion for flag %s: %v", flag, err)
+		}
+	}
+	for _, flag := range []string{"dir", "cache-dir"} {
+		if err := rootCmd.MarkPersistentFlagDirname(flag); err != nil {
+			logrus.Debugf("Failed to register completion for flag %s: %v", flag, err)
+		}
+	}
+
+	// The create, destroy and wait-for targets take no arguments, so make
+	// sure the shell does not fall back to suggesting file names.
+	for _, name := range []string{"create", "destroy", "wait-for", "agent"} {
+		cmd, _, err := rootCmd.Find([]string{name})
+		if err != nil || cmd == rootCmd {
+			continue
+		}
+		setNoFileCompletions(cmd)
+	}
+}
+
+func setNoFileCompletions(cmd *cobra.Command) {
+	for _, subCmd := range cmd.Commands() {
+		setNoFileCompletions(subCmd)
+	}
+	if !cmd.HasSubCommands() && cmd.ValidArgsFunction == nil {
+		cmd.ValidArgsFunction = cobra.NoFileCompletions
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+)
+
+const installConfigFilename = "install-config.yaml"
+
+var newConfigOpts struct {
+	installconfig.NewConfigOptions
+	pullSecretFile string
+	sshKeyFile     string
+}
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the install config",
+		Long:  "",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newConfigNewCmd())
+	return cmd
+}
+
+func newConfigNewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Generates an install config without prompting",
+		Long: `Generates a complete install-config.yaml in the asset directory from
+the given flags, with the platform defaults filled in.
+
+Unlike "create install-config", no values are asked for interactively, so
+it can be used from scripts. The resulting config can be edited before
+running "create cluster".
+
+Supported platforms: ` + strings.Join(installconfig.NewConfigPlatforms(), ", ") + ".",
+		Example: `  openshift-install config new --platform aws --region us-east-1 \
+      --name mycluster --base-domain example.com --pull-secret-file pull-secret.json`,
+		Args:              cobra.ExactArgs(0),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := runConfigNewCmd(command.RootOpts.Dir); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&newConfigOpts.Platform, "platform", "", "platform the cluster is installed on")
+	flags.StringVar(&newConfigOpts.Region, "region", "", "region the cluster is installed in")
+	flags.StringVar(&newConfigOpts.ClusterName, "name", "", "name of the cluster")
+	flags.StringVar(&newConfigOpts.BaseDomain, "base-domain", "", "base domain of the cluster")
+	flags.StringVar(&newConfigOpts.pullSecretFile, "pull-secret-file", "", "file containing the pull secret")
+	flags.StringVar(&newConfigOpts.sshKeyFile, "ssh-key-file", "", "file containing the public SSH key added to the nodes (optional)")
+	flags.StringVar(&newConfigOpts.GCPProjectID, "gcp-project-id", "", "GCP project the cluster is created in")
+	flags.StringVar(&newConfigOpts.Profile, "profile", "", "built-in profile ("+strings.Join(installconfig.Profiles(), ", ")+") or profile file setting the machine pools and networking (optional)")
+	flags.StringVar(&newConfigOpts.AzureBaseDomainResourceGroupName, "azure-base-domain-resource-group", "", "Azure resource group holding the DNS zone of the base domain")
+	for _, flag := range []string{"platform", "name", "base-domain", "pull-secret-file"} {
+		if err := cmd.MarkFlagRequired(flag); err != nil {
+			logrus.Debugf("Failed to mark flag %s as required: %v", flag, err)
+		}
+	}
+
+	for flag, completion := range map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"platform": command.CompleteValues(installconfig.NewConfigPlatforms()...),
+		"region":   command.CompleteRegions("platform"),
+		"profile":  completeProfiles,
+	} {
+		if err := cmd.RegisterFlagCompletionFunc(flag, completion); err != nil {
+			logrus.Debugf("Failed to register completion for flag %s: %v", flag, err)
+		}
+	}
+	return cmd
+}
+
+// completeProfiles completes the names of the built-in profiles, and files
+// for profile files.
+func completeProfiles(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var profiles []string
+	for _, profile := range installconfig.Profiles() {
+		if strings.HasPrefix(profile, toComplete) {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, cobra.ShellCompDirectiveDefault
+}
+
+func runConfigNewCmd(directory string) error {
+	path := filepath.Join(directory, installConfigFilename)
+	if _, err := os.Stat(path); err == nil {
+		return errors.Errorf("%s already exists, refusing to overwrite it", path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	opts := newConfigOpts.NewConfigOptions
+	pullSecret, err := os.ReadFile(newConfigOpts.pullSecretFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read pull secret")
+	}
+	opts.PullSecret = strings.TrimSpace(string(pullSecret))
+	if newConfigOpts.sshKeyFile != "" {
+		sshKey, err := os.ReadFile(newConfigOpts.sshKeyFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read SSH key")
+		}
+		opts.SSHKey = strings.TrimSpace(string(sshKey))
+	}
+
+	config, err := installconfig.NewConfig(opts)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal install config")
+	}
+
+	if err := os.MkdirAll(directory, 0750); err != nil {
+		return errors.Wrap(err, "failed to create dir")
+	}
+	if err := os.WriteFile(path, data, 0o640); err != nil { //nolint:gosec // same permissions as the other assets
+		return errors.Wrap(err, "failed to write file")
+	}
+	logrus.Infof("Install-Config created in: %s", directory)
+	return nil
+}
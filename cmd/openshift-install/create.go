@@ -4,9 +4,12 @@ import (
 	"context"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/pkg/errors"
@@ -35,13 +38,19 @@ import (
 	"github.com/openshift/installer/pkg/asset"
 	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
 	"github.com/openshift/installer/pkg/asset/cluster"
+	"github.com/openshift/installer/pkg/asset/ignition"
 	"github.com/openshift/installer/pkg/asset/installconfig"
 	"github.com/openshift/installer/pkg/asset/logging"
+	"github.com/openshift/installer/pkg/asset/manifests"
 	assetstore "github.com/openshift/installer/pkg/asset/store"
 	targetassets "github.com/openshift/installer/pkg/asset/targets"
 	destroybootstrap "github.com/openshift/installer/pkg/destroy/bootstrap"
 	"github.com/openshift/installer/pkg/gather/service"
+	"github.com/openshift/installer/pkg/hooks"
+	"github.com/openshift/installer/pkg/metrics/progress"
 	timer "github.com/openshift/installer/pkg/metrics/timer"
+	"github.com/openshift/installer/pkg/poll"
+	"github.com/openshift/installer/pkg/statecrypt"
 	"github.com/openshift/installer/pkg/types/baremetal"
 	"github.com/openshift/installer/pkg/types/vsphere"
 	cov1helpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
@@ -54,12 +63,22 @@ type target struct {
 	assets  []asset.WritableAsset
 }
 
+// Exit codes returned by the installer, so that automation can tell failure
+// classes apart. 0 means success and 1 any failure not listed here.
+//
+//	3 - the install config is invalid or could not be created
+//	4 - infrastructure provisioning failed
+//	5 - bootstrapping did not complete in time
+//	6 - the cluster did not finish initializing in time
+//	7 - cluster operators did not finish rolling out in time
+//	8 - quota or permission preflight checks failed
 const (
 	exitCodeInstallConfigError = iota + 3
 	exitCodeInfrastructureFailed
 	exitCodeBootstrapFailed
 	exitCodeInstallFailed
 	exitCodeOperatorStabilityFailed
+	exitCodePreflightFailed
 
 	// coStabilityThreshold is how long a cluster operator must have Progressing=False
 	// in order to be considered stable. Measured in seconds.
@@ -119,7 +138,15 @@ var (
 			Short: "Create an OpenShift cluster",
 			// FIXME: add longer descriptions for our commands with examples for better UX.
 			// Long:  "",
+			PreRun: func(_ *cobra.Command, _ []string) {
+				if err := validateGatherUpload(); err != nil {
+					logrus.Fatal(err)
+				}
+			},
 			PostRun: func(_ *cobra.Command, _ []string) {
+				if clusterOpts.dryRun {
+					return
+				}
 				ctx := context.Background()
 
 				cleanup := command.SetupFileHook(command.RootOpts.Dir)
@@ -127,14 +154,18 @@ var (
 
 				// FIXME: pulling the kubeconfig and metadata out of the root
 				// directory is a bit cludgy when we already have them in memory.
-				config, err := clientcmd.BuildConfigFromFlags("", filepath.Join(command.RootOpts.Dir, "auth", "kubeconfig"))
+				config, err := loadKubeconfig(command.RootOpts.Dir)
 				if err != nil {
 					logrus.Fatal(errors.Wrap(err, "loading kubeconfig"))
 				}
 
+				// the waits share the deadline of --timeout.
+				waitCtx, cancelWait := waitContext(ctx)
+				defer cancelWait()
+
 				timer.StartTimer("Bootstrap Complete")
-				if err := waitForBootstrapComplete(ctx, config); err != nil {
-					bundlePath, gatherErr := runGatherBootstrapCmd(command.RootOpts.Dir)
+				if err := waitForBootstrapComplete(waitCtx, config); err != nil {
+					bundlePath, analyzable, gatherErr := runGatherBootstrapCmd(command.RootOpts.Dir)
 					if gatherErr != nil {
 						logrus.Error("Attempted to gather debug logs after installation failure: ", gatherErr)
 					}
@@ -144,14 +175,23 @@ var (
 					logrus.Error("Bootstrap failed to complete: ", err.Unwrap())
 					logrus.Error(err.Error())
 					if gatherErr == nil {
-						if err := service.AnalyzeGatherBundle(bundlePath); err != nil {
-							logrus.Error("Attempted to analyze the debug logs after installation failure: ", err)
+						if analyzable {
+							if err := service.AnalyzeGatherBundle(bundlePath); err != nil {
+								logrus.Error("Attempted to analyze the debug logs after installation failure: ", err)
+							}
+						}
+						if _, err := diagnoseGatherBundle(bundlePath); err != nil {
+							logrus.Error("Attempted to classify the failure after installation failure: ", err)
 						}
 						logrus.Infof("Bootstrap gather logs captured here %q", bundlePath)
+						if err := uploadGatherBundle(ctx, bundlePath); err != nil {
+							logrus.Error("Attempted to upload the debug logs after installation failure: ", err)
+						}
 					}
 					logrus.Exit(exitCodeBootstrapFailed)
 				}
 				timer.StopTimer("Bootstrap Complete")
+				progress.Emit(progress.BootstrapComplete, "")
 				timer.StartTimer("Bootstrap Destroy")
 
 				if oi, ok := os.LookupEnv("OPENSHIFT_INSTALL_PRESERVE_BOOTSTRAP"); ok && oi != "" {
@@ -166,14 +206,17 @@ var (
 				}
 				timer.StopTimer("Bootstrap Destroy")
 
-				err = waitForInstallComplete(ctx, config, command.RootOpts.Dir)
+				_, err = waitForInstallComplete(waitCtx, config, command.RootOpts.Dir)
 				if err != nil {
 					if err2 := logClusterOperatorConditions(ctx, config); err2 != nil {
 						logrus.Error("Attempted to gather ClusterOperator status after installation failure: ", err2)
 					}
+					if installCompleteOpts.gatherOnFailure {
+						gatherCluster(ctx, config, command.RootOpts.Dir)
+					}
 					logTroubleshootingLink()
 					logrus.Error(err)
-					logrus.Exit(exitCodeInstallFailed)
+					logrus.Exit(installExitCode(err))
 				}
 				timer.StopTimer(timer.TotalTimeElapsed)
 				timer.LogSummary()
@@ -183,6 +226,18 @@ var (
 	}
 
 	targets = []target{installConfigTarget, manifestsTarget, ignitionConfigsTarget, clusterTarget, singleNodeIgnitionConfigTarget}
+
+	createOpts struct {
+		profile       string
+		trustBundles  []string
+		registryCerts map[string]string
+	}
+
+	clusterOpts struct {
+		quiet  bool
+		dryRun bool
+		resume bool
+	}
 )
 
 // clusterCreateError defines a custom error type that would help identify where the error occurs
@@ -248,6 +303,20 @@ func newCreateCmd() *cobra.Command {
 		t.command.Run = runTargetCmd(t.assets...)
 		cmd.AddCommand(t.command)
 	}
+	cmd.PersistentFlags().StringVar(&createOpts.profile, "profile", "", "built-in profile ("+strings.Join(installconfig.Profiles(), ", ")+") or profile file applied to the install config when it is generated")
+	if err := cmd.RegisterFlagCompletionFunc("profile", completeProfiles); err != nil {
+		logrus.Debugf("Failed to register completion for flag profile: %v", err)
+	}
+	for _, t := range []target{ignitionConfigsTarget, clusterTarget} {
+		t.command.Flags().StringArrayVar(&createOpts.trustBundles, "trust-bundle", nil, "PEM file with certificate authorities trusted by the bootstrap and every node, may be repeated. Kept for the next commands of the asset directory until changed")
+		t.command.Flags().StringToStringVar(&createOpts.registryCerts, "registry-cert", nil, "mirror registry and PEM file with its certificate authorities trusted by the bootstrap and every node, as host[:port]=file, may be repeated. Kept for the next commands of the asset directory until changed")
+	}
+	clusterTarget.command.Flags().BoolVar(&installCompleteOpts.verifyEndpoints, "verify-endpoints", false, "probe the console and the ingress canary over HTTPS, through the cluster proxy if any, before declaring the install complete")
+	clusterTarget.command.Flags().BoolVar(&installCompleteOpts.gatherOnFailure, "gather-on-failure", false, "gather the cluster operators, the warning events and the pod logs of the failing operators through the API into the assets directory when the install fails to complete")
+	clusterTarget.command.Flags().BoolVar(&clusterOpts.quiet, "quiet", false, "only log errors and print the cluster access information to stdout once the install completes")
+	clusterTarget.command.Flags().BoolVar(&clusterOpts.resume, "resume", false, "continue the infrastructure provisioning of a previous failed attempt from the last completed stage")
+	addGatherUploadFlags(clusterTarget.command.Flags())
+	clusterTarget.command.Flags().BoolVar(&clusterOpts.dryRun, "dry-run", false, "render all assets and run the validations and preflight checks, then print a summary of the infrastructure instead of creating it")
 
 	return cmd
 }
@@ -261,14 +330,51 @@ func asFileWriter(a asset.WritableAsset) asset.FileWriter {
 	}
 }
 
+// exitCodeFor returns the code the installer exits with when creating the
+// targets fails with err.
+func exitCodeFor(err error) int {
+	switch {
+	case strings.Contains(err.Error(), asset.InstallConfigError):
+		return exitCodeInstallConfigError
+	case strings.Contains(err.Error(), asset.PreflightCheckError):
+		return exitCodePreflightFailed
+	case strings.Contains(err.Error(), asset.ClusterCreationError):
+		return exitCodeInfrastructureFailed
+	default:
+		return 1
+	}
+}
+
 func runTargetCmd(targets ...asset.WritableAsset) func(cmd *cobra.Command, args []string) {
 	runner := func(directory string) error {
+		installconfig.Profile = createOpts.profile
+		ignition.TrustBundleFiles = createOpts.trustBundles
+		ignition.RegistryCertFiles = createOpts.registryCerts
+
 		assetStore, err := assetstore.NewStore(directory)
 		if err != nil {
 			return errors.Wrap(err, "failed to create asset store")
 		}
 
+		if command.RootOpts.NonInteractive {
+			if err := checkNonInteractive(assetStore, targets); err != nil {
+				return err
+			}
+		}
+
+		assetStore, err = generateManifestsWithHooks(directory, assetStore, targets)
+		if err != nil {
+			return err
+		}
+
 		for _, a := range targets {
+			if _, ok := a.(*cluster.Cluster); ok && clusterOpts.dryRun {
+				if err := dryRunCluster(assetStore, a, targets); err != nil {
+					return err
+				}
+				continue
+			}
+
 			err := assetStore.Fetch(a, targets...)
 			if err != nil {
 				err = errors.Wrapf(err, "failed to fetch %s", a.Name())
@@ -298,18 +404,16 @@ func runTargetCmd(targets ...asset.WritableAsset) func(cmd *cobra.Command, args
 		defer cleanup()
 
 		cluster.InstallDir = command.RootOpts.Dir
+		cluster.Resume = clusterOpts.resume
 
 		err := runner(command.RootOpts.Dir)
 		if err != nil {
-			if strings.Contains(err.Error(), asset.InstallConfigError) {
-				logrus.Error(err)
-				logrus.Exit(exitCodeInstallConfigError)
+			code := exitCodeFor(err)
+			if code == 1 {
+				logrus.Fatal(err)
 			}
-			if strings.Contains(err.Error(), asset.ClusterCreationError) {
-				logrus.Error(err)
-				logrus.Exit(exitCodeInfrastructureFailed)
-			}
-			logrus.Fatal(err)
+			logrus.Error(err)
+			logrus.Exit(code)
 		}
 		switch cmd.Name() {
 		case "cluster", "image", "pxe-files":
@@ -320,22 +424,145 @@ func runTargetCmd(targets ...asset.WritableAsset) func(cmd *cobra.Command, args
 	}
 }
 
-// addRouterCAToClusterCA adds router CA to cluster CA in kubeconfig
-func addRouterCAToClusterCA(ctx context.Context, config *rest.Config, directory string) (err error) {
-	client, err := kubernetes.NewForConfig(config)
+// generateManifestsWithHooks writes the manifests to the install dir, running
+// the pre-manifests and post-manifests hooks around it, when the targets
+// depend on manifests that have not been generated yet and any such hooks
+// exist. The returned store must be used to fetch the targets so that any
+// changes the hooks made to the manifests are picked up.
+func generateManifestsWithHooks(directory string, assetStore asset.Store, targets []asset.WritableAsset) (asset.Store, error) {
+	manifestsAsset := &manifests.Manifests{}
+	if !dependsOn(manifestsAsset, targets) {
+		return assetStore, nil
+	}
+	preHooks, err := hooks.Find(directory, hooks.PreManifests)
+	if err != nil {
+		return nil, err
+	}
+	postHooks, err := hooks.Find(directory, hooks.PostManifests)
 	if err != nil {
-		return errors.Wrap(err, "creating a Kubernetes client")
+		return nil, err
+	}
+	if len(preHooks) == 0 && len(postHooks) == 0 {
+		return assetStore, nil
+	}
+	found, err := assetStore.Load(manifestsAsset)
+	if err != nil {
+		return nil, err
+	}
+	if found != nil {
+		logrus.Debug("Skipping the manifests hooks because the manifests have already been generated")
+		return assetStore, nil
 	}
 
-	// Configmap may not exist. log and accept not-found errors with configmap.
-	caConfigMap, err := client.CoreV1().ConfigMaps("openshift-config-managed").Get(ctx, "default-ingress-cert", metav1.GetOptions{})
+	if err := hooks.Run(directory, hooks.PreManifests); err != nil {
+		return nil, err
+	}
+	for _, a := range targetassets.Manifests {
+		if err := assetStore.Fetch(a, append(targets, targetassets.Manifests...)...); err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch %s", a.Name())
+		}
+		if err := asFileWriter(a).PersistToFile(directory); err != nil {
+			return nil, errors.Wrapf(err, "failed to write asset (%s) to disk", a.Name())
+		}
+	}
+	if err := hooks.Run(directory, hooks.PostManifests); err != nil {
+		return nil, err
+	}
+
+	assetStore, err = assetstore.NewStore(directory)
+	return assetStore, errors.Wrap(err, "failed to create asset store")
+}
+
+// dryRunCluster fetches the dependencies of the cluster asset, which runs all
+// the validations and preflight checks, and prints a summary of the
+// infrastructure the cluster asset would create without generating it.
+func dryRunCluster(assetStore asset.Store, clusterAsset asset.Asset, targets []asset.WritableAsset) error {
+	var installConfig *installconfig.InstallConfig
+	for _, dependency := range clusterAsset.Dependencies() {
+		if err := assetStore.Fetch(dependency, targets...); err != nil {
+			return errors.Wrapf(err, "failed to fetch %s", dependency.Name())
+		}
+		if ic, ok := dependency.(*installconfig.InstallConfig); ok {
+			installConfig = ic
+		}
+	}
+	if installConfig == nil || installConfig.Config == nil {
+		return errors.New("install config is required to summarize the infrastructure")
+	}
+
+	logrus.Info("Dry run complete, no infrastructure was created")
+	summary := cluster.SummarizeInfrastructure(installConfig.Config)
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Platform:\t%s\n", summary.Platform)
+	fmt.Fprintf(w, "Bootstrap machines:\t%d\n", summary.BootstrapMachines)
+	fmt.Fprintf(w, "Control plane machines:\t%d\n", summary.ControlPlaneMachines)
+	fmt.Fprintf(w, "Compute machines:\t%d\n", summary.ComputeMachines)
+	fmt.Fprintf(w, "API load balancers:\t%d\n", summary.LoadBalancers)
+	fmt.Fprintf(w, "API DNS records:\t%d\n", summary.DNSRecords)
+	return w.Flush()
+}
+
+// checkNonInteractive returns an error listing the install-config fields the
+// user would otherwise be prompted for when fetching the targets requires
+// generating an install config from scratch.
+func checkNonInteractive(assetStore asset.Store, targets []asset.WritableAsset) error {
+	installConfig := &installconfig.InstallConfig{}
+	if !dependsOn(installConfig, targets) {
+		return nil
+	}
+	found, err := assetStore.Load(installConfig)
 	if err != nil {
-		return errors.Wrap(err, "fetching default-ingress-cert configmap from openshift-config-managed namespace")
+		return errors.Wrap(err, asset.InstallConfigError)
 	}
+	if found == nil {
+		return errors.Errorf("%s: install-config.yaml not found in %q and prompting is disabled by --non-interactive, missing fields: %s",
+			asset.InstallConfigError, command.RootOpts.Dir, strings.Join(installconfig.PromptedFields, ", "))
+	}
+	return nil
+}
+
+// dependsOn returns true if any of the given assets is, or transitively
+// depends on, an asset of the same type as dependency.
+func dependsOn(dependency asset.Asset, assets []asset.WritableAsset) bool {
+	want := reflect.TypeOf(dependency)
+	visited := map[reflect.Type]bool{}
+	var visit func(a asset.Asset) bool
+	visit = func(a asset.Asset) bool {
+		t := reflect.TypeOf(a)
+		if t == want {
+			return true
+		}
+		if visited[t] {
+			return false
+		}
+		visited[t] = true
+		for _, d := range a.Dependencies() {
+			if visit(d) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, a := range assets {
+		if visit(a) {
+			return true
+		}
+	}
+	return false
+}
 
-	routerCrtBytes := []byte(caConfigMap.Data["ca-bundle.crt"])
+// addRouterCAToClusterCA adds router CA to cluster CA in kubeconfig
+func addRouterCAToClusterCA(ctx context.Context, config *rest.Config, directory string) (err error) {
+	routerCrtBytes, err := routerCABundle(ctx, config)
+	if err != nil {
+		return err
+	}
 	kubeconfig := filepath.Join(directory, "auth", "kubeconfig")
-	kconfig, err := clientcmd.LoadFromFile(kubeconfig)
+	data, err := statecrypt.ReadFile(kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "loading kubeconfig")
+	}
+	kconfig, err := clientcmd.Load(data)
 	if err != nil {
 		return errors.Wrap(err, "loading kubeconfig")
 	}
@@ -360,12 +587,73 @@ func addRouterCAToClusterCA(ctx context.Context, config *rest.Config, directory
 		newCA := append(routerCrtBytes, clusterCABytes...)
 		c.CertificateAuthorityData = newCA
 	}
-	if err := clientcmd.WriteToFile(*kconfig, kubeconfig); err != nil {
+	data, err = clientcmd.Write(*kconfig)
+	if err == nil {
+		data, err = statecrypt.Encrypt(data)
+	}
+	if err == nil {
+		err = os.WriteFile(kubeconfig, data, 0o600)
+	}
+	if err != nil {
 		return errors.Wrap(err, "writing kubeconfig")
 	}
 	return nil
 }
 
+// routerCABundle returns the bundle of the certificate authorities of the
+// default ingress certificate of the cluster.
+func routerCABundle(ctx context.Context, config *rest.Config) ([]byte, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating a Kubernetes client")
+	}
+
+	caConfigMap, err := client.CoreV1().ConfigMaps("openshift-config-managed").Get(ctx, "default-ingress-cert", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching default-ingress-cert configmap from openshift-config-managed namespace")
+	}
+	return []byte(caConfigMap.Data["ca-bundle.crt"]), nil
+}
+
+// loadKubeconfig loads the admin kubeconfig from the assets directory,
+// decrypting it if needed.
+func loadKubeconfig(directory string) (*rest.Config, error) {
+	data, err := statecrypt.ReadFile(filepath.Join(directory, "auth", "kubeconfig"))
+	if err != nil {
+		return nil, err
+	}
+	return clientcmd.RESTConfigFromKubeConfig(data)
+}
+
+// waitContext returns the context of the waits for the cluster, bounded by
+// --timeout when it is set, so that it bounds the whole wait rather than each
+// of its stages.
+func waitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if command.RootOpts.Timeout > 0 {
+		return context.WithDeadline(ctx, time.Now().Add(command.RootOpts.Timeout))
+	}
+	return context.WithCancel(ctx)
+}
+
+// stageTimeout returns the timeout of a wait stage, which is the time left
+// until the deadline of --timeout when it is set, and the default timeout of
+// the stage otherwise.
+func stageTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok && command.RootOpts.Timeout > 0 {
+		return time.Until(deadline)
+	}
+	return timeout
+}
+
+// boundedTimeout returns the timeout of a wait stage, bounded by the time
+// left until the deadline of the context, if any.
+func boundedTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		return time.Until(deadline)
+	}
+	return timeout
+}
+
 func waitForBootstrapComplete(ctx context.Context, config *rest.Config) *clusterCreateError {
 	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -374,7 +662,7 @@ func waitForBootstrapComplete(ctx context.Context, config *rest.Config) *cluster
 
 	discovery := client.Discovery()
 
-	apiTimeout := 20 * time.Minute
+	apiTimeout := stageTimeout(ctx, 20*time.Minute)
 
 	untilTime := time.Now().Add(apiTimeout)
 	timezone, _ := untilTime.Zone()
@@ -396,30 +684,32 @@ func waitForBootstrapComplete(ctx context.Context, config *rest.Config) *cluster
 	}
 
 	var lastErr error
-	wait.Until(func() {
+	err = poll.Until(apiContext, 2*time.Second, func(ctx context.Context) (bool, error) {
 		version, err := discovery.ServerVersion()
 		if err == nil {
 			logrus.Infof("API %s up", version)
 			timer.StopTimer("API")
-			cancel()
-		} else {
-			lastErr = err
-			silenceRemaining--
-			chunks := strings.Split(err.Error(), ":")
-			errorSuffix := chunks[len(chunks)-1]
-			if previousErrorSuffix != errorSuffix {
-				logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
-				previousErrorSuffix = errorSuffix
-				silenceRemaining = logDownsample
-			} else if silenceRemaining == 0 {
-				logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
-				silenceRemaining = logDownsample
-			}
+			return true, nil
 		}
-	}, 2*time.Second, apiContext.Done())
-	err = apiContext.Err()
-	if err != nil && err != context.Canceled {
-		if lastErr != nil {
+		lastErr = err
+		silenceRemaining--
+		chunks := strings.Split(err.Error(), ":")
+		errorSuffix := chunks[len(chunks)-1]
+		if previousErrorSuffix != errorSuffix {
+			logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
+			previousErrorSuffix = errorSuffix
+			silenceRemaining = logDownsample
+		} else if silenceRemaining == 0 {
+			logrus.Debugf("Still waiting for the Kubernetes API: %v", err)
+			silenceRemaining = logDownsample
+		}
+		if poll.IsTransient(err) {
+			return false, err
+		}
+		return false, nil
+	})
+	if err != nil {
+		if lastErr != nil && wait.Interrupted(err) {
 			return newAPIError(lastErr)
 		}
 		return newAPIError(err)
@@ -442,6 +732,7 @@ func waitForBootstrapConfigMap(ctx context.Context, client *kubernetes.Clientset
 			}
 		}
 	}
+	timeout = stageTimeout(ctx, timeout)
 
 	untilTime := time.Now().Add(timeout)
 	timezone, _ := untilTime.Zone()
@@ -498,6 +789,7 @@ func waitForInitializedCluster(ctx context.Context, config *rest.Config) error {
 
 		checkIfAgentCommand(assetStore)
 	}
+	timeout = stageTimeout(ctx, timeout)
 
 	untilTime := time.Now().Add(timeout)
 	timezone, _ := untilTime.Zone()
@@ -561,14 +853,33 @@ func waitForInitializedCluster(ctx context.Context, config *rest.Config) error {
 	return errors.Wrap(err, "failed to initialize the cluster")
 }
 
+// unstableOperatorsError is returned when cluster operators are still
+// progressing after the stability deadline.
+type unstableOperatorsError struct {
+	operators []string
+}
+
+func (e *unstableOperatorsError) Error() string {
+	return fmt.Sprintf("these cluster operators were not stable: [%s]", strings.Join(e.operators, ", "))
+}
+
+// installExitCode returns the exit code for the error of waitForInstallComplete.
+func installExitCode(err error) int {
+	var unstable *unstableOperatorsError
+	if errors.As(err, &unstable) {
+		return exitCodeOperatorStabilityFailed
+	}
+	return exitCodeInstallFailed
+}
+
 // waitForStableOperators ensures that each cluster operator is "stable", i.e. the
 // operator has not been in a progressing state for at least a certain duration,
-// 30 seconds by default. Returns an error if any operator does meet this threshold
-// after a deadline, 30 minutes by default.
+// 30 seconds by default. Returns an unstableOperatorsError if any operator does
+// not meet this threshold after a deadline, 30 minutes by default.
 func waitForStableOperators(ctx context.Context, config *rest.Config) error {
 	timer.StartTimer("Cluster Operators Stable")
 
-	stabilityCheckDuration := 30 * time.Minute
+	stabilityCheckDuration := boundedTimeout(ctx, 30*time.Minute)
 	stabilityContext, cancel := context.WithTimeout(ctx, stabilityCheckDuration)
 	defer cancel()
 
@@ -589,7 +900,7 @@ func waitForStableOperators(ctx context.Context, config *rest.Config) error {
 		return fmt.Errorf("informers never started")
 	}
 
-	waitErr := wait.PollUntilContextCancel(stabilityContext, 1*time.Second, true, waitForAllClusterOperators(clusterOperatorLister))
+	waitErr := poll.Until(stabilityContext, 1*time.Second, waitForAllClusterOperators(clusterOperatorLister))
 	if waitErr != nil {
 		logrus.Errorf("Error checking cluster operator Progressing status: %q", waitErr)
 		stableOperators, unstableOperators, err := currentOperatorStability(clusterOperatorLister)
@@ -597,9 +908,7 @@ func waitForStableOperators(ctx context.Context, config *rest.Config) error {
 			logrus.Errorf("Error checking final cluster operator Progressing status: %q", err)
 		}
 		logrus.Debugf("These cluster operators were stable: [%s]", strings.Join(sets.List(stableOperators), ", "))
-		logrus.Errorf("These cluster operators were not stable: [%s]", strings.Join(sets.List(unstableOperators), ", "))
-
-		logrus.Exit(exitCodeOperatorStabilityFailed)
+		return &unstableOperatorsError{operators: sets.List(unstableOperators)}
 	}
 
 	timer.StopTimer("Cluster Operators Stable")
@@ -620,7 +929,7 @@ func getConsole(ctx context.Context, config *rest.Config) (string, error) {
 		return "", errors.Wrap(err, "creating a route client")
 	}
 
-	consoleRouteTimeout := 2 * time.Minute
+	consoleRouteTimeout := boundedTimeout(ctx, 2*time.Minute)
 	logrus.Infof("Checking to see if there is a route at %s/%s...", consoleNamespace, consoleRouteName)
 	consoleRouteContext, cancel := context.WithTimeout(ctx, consoleRouteTimeout)
 	defer cancel()
@@ -673,38 +982,71 @@ func logComplete(directory, consoleURL string) error {
 	}
 	kubeconfig := filepath.Join(absDir, "auth", "kubeconfig")
 	pwFile := filepath.Join(absDir, "auth", "kubeadmin-password")
-	pw, err := os.ReadFile(pwFile)
+	pw, err := statecrypt.ReadFile(pwFile)
 	if err != nil {
 		return err
 	}
 	logrus.Info("Install complete!")
+	if statecrypt.Enabled() {
+		logrus.Infof("The files in %s are encrypted, decrypt them with 'openshift-install decrypt'", filepath.Dir(kubeconfig))
+	}
 	logrus.Infof("To access the cluster as the system:admin user when using 'oc', run 'export KUBECONFIG=%s'", kubeconfig)
 	if consoleURL != "" {
 		logrus.Infof("Access the OpenShift web-console here: %s", consoleURL)
 		logrus.Infof("Login to the console with user: %q, and password: %q", "kubeadmin", pw)
 	}
+	if clusterOpts.quiet {
+		metadata, err := cluster.LoadMetadata(absDir)
+		if err != nil {
+			return err
+		}
+		return printAccessInfo(os.Stdout, kubeconfig, consoleURL, pwFile, metadata.InfraID)
+	}
 	return nil
 }
 
-func waitForInstallComplete(ctx context.Context, config *rest.Config, directory string) error {
+// printAccessInfo writes the cluster access information to out as KEY=value
+// lines, so wrapper scripts can capture them. CONSOLE_URL is omitted when the
+// cluster has no console.
+func printAccessInfo(out io.Writer, kubeconfig, consoleURL, pwFile, infraID string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "KUBECONFIG=%s\n", kubeconfig)
+	if consoleURL != "" {
+		fmt.Fprintf(&b, "CONSOLE_URL=%s\n", consoleURL)
+	}
+	fmt.Fprintf(&b, "KUBEADMIN_PASSWORD_FILE=%s\n", pwFile)
+	fmt.Fprintf(&b, "INFRA_ID=%s\n", infraID)
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// waitForInstallComplete waits for the cluster to be initialized and its
+// operators to be stable, and returns the console URL, if any.
+func waitForInstallComplete(ctx context.Context, config *rest.Config, directory string) (string, error) {
 	if err := waitForInitializedCluster(ctx, config); err != nil {
-		return err
+		return "", err
 	}
 
 	if err := addRouterCAToClusterCA(ctx, config, command.RootOpts.Dir); err != nil {
-		return err
+		return "", err
 	}
 
 	if err := waitForStableOperators(ctx, config); err != nil {
-		return err
+		return "", err
 	}
 
 	consoleURL, err := getConsole(ctx, config)
 	if err != nil {
 		logrus.Warnf("Cluster does not have a console available: %v", err)
 	}
+	if installCompleteOpts.verifyEndpoints {
+		if err := verifyEndpoints(ctx, config, consoleURL); err != nil {
+			return "", err
+		}
+	}
+	progress.Emit(progress.InstallComplete, "")
 
-	return logComplete(command.RootOpts.Dir, consoleURL)
+	return consoleURL, logComplete(command.RootOpts.Dir, consoleURL)
 }
 
 func logTroubleshootingLink() {
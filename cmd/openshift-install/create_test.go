@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+)
+
+const noneInstallConfig = `apiVersion: v1
+metadata:
+  name: test-cluster
+baseDomain: test-domain
+controlPlane:
+  name: master
+  replicas: 1
+compute:
+- name: worker
+  replicas: 0
+platform:
+  none: {}
+pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
+`
+
+// fakeAsset is a writable asset with the given dependencies, which counts
+// how many times its dependencies are listed.
+type fakeAsset struct {
+	dependencies []asset.Asset
+	listed       int
+}
+
+func (a *fakeAsset) Dependencies() []asset.Asset {
+	a.listed++
+	return a.dependencies
+}
+
+func (a *fakeAsset) Generate(asset.Parents) error {
+	return nil
+}
+
+func (a *fakeAsset) Name() string {
+	return "Fake Asset"
+}
+
+func (a *fakeAsset) Files() []*asset.File {
+	return nil
+}
+
+func (a *fakeAsset) Load(asset.FileFetcher) (bool, error) {
+	return false, nil
+}
+
+// The fake assets below only differ by their type, which is what the asset
+// graph is keyed on.
+type fakeTopAsset struct{ fakeAsset }
+type fakeLeftAsset struct{ fakeAsset }
+type fakeRightAsset struct{ fakeAsset }
+type fakeBottomAsset struct{ fakeAsset }
+
+func TestExitCodeFor(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "install-config error",
+			err:      errors.Wrap(errors.New("invalid platform"), asset.InstallConfigError),
+			expected: exitCodeInstallConfigError,
+		},
+		{
+			name:     "install-config error of a preflight check",
+			err:      errors.Wrap(errors.Wrap(errors.New("invalid platform"), asset.InstallConfigError), asset.PreflightCheckError),
+			expected: exitCodeInstallConfigError,
+		},
+		{
+			name:     "preflight check error",
+			err:      errors.Wrap(errors.New("insufficient quota"), asset.PreflightCheckError),
+			expected: exitCodePreflightFailed,
+		},
+		{
+			name:     "cluster creation error",
+			err:      errors.Wrap(errors.New("terraform apply failed"), asset.ClusterCreationError),
+			expected: exitCodeInfrastructureFailed,
+		},
+		{
+			name:     "other error",
+			err:      errors.New("failed to fetch Metadata"),
+			expected: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, exitCodeFor(tc.err))
+		})
+	}
+}
+
+func TestCheckNonInteractive(t *testing.T) {
+	cases := []struct {
+		name          string
+		target        asset.WritableAsset
+		installConfig string
+		expectedErr   string
+	}{
+		{
+			name:   "target not depending on the install-config",
+			target: &fakeAsset{},
+		},
+		{
+			name:        "install-config not found",
+			target:      &fakeAsset{dependencies: []asset.Asset{&installconfig.InstallConfig{}}},
+			expectedErr: "^" + asset.InstallConfigError + `: install-config.yaml not found in ".*" and prompting is disabled by --non-interactive, missing fields: ` + strings.Join(installconfig.PromptedFields, ", ") + "$",
+		},
+		{
+			name:          "install-config on disk",
+			target:        &fakeAsset{dependencies: []asset.Asset{&installconfig.InstallConfig{}}},
+			installConfig: noneInstallConfig,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.installConfig != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "install-config.yaml"), []byte(tc.installConfig), 0600))
+			}
+			oldDir := command.RootOpts.Dir
+			command.RootOpts.Dir = dir
+			defer func() { command.RootOpts.Dir = oldDir }()
+
+			assetStore, err := assetstore.NewStore(dir)
+			require.NoError(t, err)
+
+			err = checkNonInteractive(assetStore, []asset.WritableAsset{tc.target})
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				assert.Equal(t, exitCodeInstallConfigError, exitCodeFor(err))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDependsOnSharedDependencies(t *testing.T) {
+	bottom := &fakeBottomAsset{}
+	left := &fakeLeftAsset{fakeAsset{dependencies: []asset.Asset{bottom}}}
+	right := &fakeRightAsset{fakeAsset{dependencies: []asset.Asset{bottom}}}
+	top := &fakeTopAsset{fakeAsset{dependencies: []asset.Asset{left, right}}}
+	targets := []asset.WritableAsset{top, left}
+
+	assert.False(t, dependsOn(&installconfig.InstallConfig{}, targets))
+	assert.Equal(t, 1, bottom.listed, "the shared dependency is visited once")
+	assert.Equal(t, 1, left.listed, "the dependency also given as a target is visited once")
+
+	assert.True(t, dependsOn(&fakeBottomAsset{}, targets))
+}
+
+func TestWaitTimeouts(t *testing.T) {
+	cases := []struct {
+		name            string
+		timeout         time.Duration
+		consumed        time.Duration
+		stageDefault    time.Duration
+		expectedStage   time.Duration
+		expectedBounded time.Duration
+	}{
+		{
+			name:            "no timeout",
+			stageDefault:    20 * time.Minute,
+			expectedStage:   20 * time.Minute,
+			expectedBounded: 20 * time.Minute,
+		},
+		{
+			name:            "timeout shorter than the stage default",
+			timeout:         5 * time.Minute,
+			stageDefault:    20 * time.Minute,
+			expectedStage:   5 * time.Minute,
+			expectedBounded: 5 * time.Minute,
+		},
+		{
+			name:            "timeout longer than the stage default",
+			timeout:         2 * time.Hour,
+			stageDefault:    20 * time.Minute,
+			expectedStage:   2 * time.Hour,
+			expectedBounded: 20 * time.Minute,
+		},
+		{
+			name:            "timeout mostly consumed by the earlier stages",
+			timeout:         2 * time.Hour,
+			consumed:        118 * time.Minute,
+			stageDefault:    20 * time.Minute,
+			expectedStage:   2 * time.Minute,
+			expectedBounded: 2 * time.Minute,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldTimeout := command.RootOpts.Timeout
+			command.RootOpts.Timeout = tc.timeout
+			defer func() { command.RootOpts.Timeout = oldTimeout }()
+
+			ctx, cancel := waitContext(context.Background())
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			assert.Equal(t, tc.timeout > 0, ok)
+			if tc.consumed > 0 {
+				// Move the deadline closer, as if the earlier stages had run.
+				var cancelConsumed context.CancelFunc
+				ctx, cancelConsumed = context.WithDeadline(ctx, deadline.Add(-tc.consumed))
+				defer cancelConsumed()
+			}
+
+			assert.InDelta(t, float64(tc.expectedStage), float64(stageTimeout(ctx, tc.stageDefault)), float64(time.Second))
+			assert.InDelta(t, float64(tc.expectedBounded), float64(boundedTimeout(ctx, tc.stageDefault)), float64(time.Second))
+		})
+	}
+}
+
+func TestPrintAccessInfo(t *testing.T) {
+	cases := []struct {
+		name       string
+		consoleURL string
+		expected   string
+	}{
+		{
+			name:       "console",
+			consoleURL: "https://console-openshift-console.apps.test-cluster.example.com",
+			expected: `KUBECONFIG=/assets/auth/kubeconfig
+CONSOLE_URL=https://console-openshift-console.apps.test-cluster.example.com
+KUBEADMIN_PASSWORD_FILE=/assets/auth/kubeadmin-password
+INFRA_ID=test-cluster-x7k2p
+`,
+		},
+		{
+			name: "no console",
+			expected: `KUBECONFIG=/assets/auth/kubeconfig
+KUBEADMIN_PASSWORD_FILE=/assets/auth/kubeadmin-password
+INFRA_ID=test-cluster-x7k2p
+`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			require.NoError(t, printAccessInfo(&out, "/assets/auth/kubeconfig", tc.consoleURL, "/assets/auth/kubeadmin-password", "test-cluster-x7k2p"))
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/agent"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+)
+
+var csrApprovalOpts struct {
+	expectedNodes int
+	nodeNames     []string
+}
+
+func newWaitForCSRApprovalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "csr-approval",
+		Short: "Approve the certificate signing requests of the joining nodes until the cluster has the expected number of nodes",
+		Long: `Approve the certificate signing requests of the kubelets of the nodes joining
+the cluster, until the cluster has the expected number of Ready nodes.
+
+The requests are only approved for the nodes named by --node-names, or by the
+host names of the agent config in the asset directory. The client certificate
+requests must be requested by the node bootstrapper before the node joined,
+and the serving certificate requests by the node itself, for its own names
+and addresses.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			ctx := context.Background()
+
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			nodeNames := expectedNodeNames(command.RootOpts.Dir)
+			nodeNames.Insert(csrApprovalOpts.nodeNames...)
+			if nodeNames.Len() == 0 {
+				logrus.Fatal(errors.New("the names of the joining nodes are required, set --node-names or the host names of the agent config"))
+			}
+
+			if err := agent.WaitForCSRApproval(ctx, filepath.Join(command.RootOpts.Dir, "auth", "kubeconfig"), csrApprovalOpts.expectedNodes, sets.List(nodeNames), command.RootOpts.Timeout); err != nil {
+				logrus.Error(err)
+				logrus.Exit(exitCodeInstallFailed)
+			}
+		},
+	}
+	cmd.Flags().IntVar(&csrApprovalOpts.expectedNodes, "expected-nodes", 0, "number of Ready nodes the cluster has once all the nodes have joined")
+	cmd.Flags().StringSliceVar(&csrApprovalOpts.nodeNames, "node-names", nil, "comma-separated names of the joining nodes, in addition to the host names of the agent config, if any")
+	if err := cmd.MarkFlagRequired("expected-nodes"); err != nil {
+		logrus.Debugf("Failed to mark flag expected-nodes as required: %v", err)
+	}
+	return cmd
+}
+
+// expectedNodeNames returns the host names of the agent config of the asset
+// directory, if any.
+func expectedNodeNames(directory string) sets.Set[string] {
+	names := sets.New[string]()
+	assetStore, err := assetstore.NewStore(directory)
+	if err != nil {
+		return names
+	}
+	agentConfig, err := assetStore.Load(&agentconfig.AgentConfig{})
+	if err != nil || agentConfig == nil || agentConfig.(*agentconfig.AgentConfig).Config == nil {
+		return names
+	}
+	for _, host := range agentConfig.(*agentconfig.AgentConfig).Config.Hosts {
+		if host.Hostname != "" {
+			names.Insert(host.Hostname)
+		}
+	}
+	return names
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/pkg/statecrypt"
+)
+
+func newDecryptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt <file>",
+		Short: "Print a file encrypted with --state-encryption-key",
+		Long: `Prints the plain text of a file encrypted with --state-encryption-key,
+e.g. the admin kubeconfig in auth/kubeconfig, to stdout.`,
+		Example: `  export OPENSHIFT_INSTALL_STATE_ENCRYPTION_KEY=...
+  openshift-install decrypt auth/kubeconfig > kubeconfig`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if !statecrypt.Enabled() {
+				logrus.Fatal(errors.New("--state-encryption-key is required"))
+			}
+			data, err := statecrypt.ReadFile(args[0])
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if _, err := os.Stdout.Write(data); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+}
@@ -1,19 +1,29 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"text/tabwriter"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset/cluster"
 	assetstore "github.com/openshift/installer/pkg/asset/store"
 	"github.com/openshift/installer/pkg/destroy"
 	"github.com/openshift/installer/pkg/destroy/bootstrap"
+	"github.com/openshift/installer/pkg/destroy/providers"
 	quotaasset "github.com/openshift/installer/pkg/destroy/quota"
 	"github.com/openshift/installer/pkg/metrics/timer"
+	"github.com/openshift/installer/pkg/statecrypt"
+	"github.com/openshift/installer/pkg/types"
 
 	_ "github.com/openshift/installer/pkg/destroy/alibabacloud"
 	_ "github.com/openshift/installer/pkg/destroy/aws"
@@ -40,11 +50,32 @@ func newDestroyCmd() *cobra.Command {
 	}
 	cmd.AddCommand(newDestroyBootstrapCmd())
 	cmd.AddCommand(newDestroyClusterCmd())
+	cmd.AddCommand(newDestroyScanCmd())
 	return cmd
 }
 
+type destroyClusterOptions struct {
+	dryRun                bool
+	kubeconfig            string
+	platform              string
+	region                string
+	infraID               string
+	hooksDir              string
+	preserveImageRegistry bool
+	wipeDisks             bool
+	skipPreflight         bool
+	deregister            bool
+	ocmURL                string
+	filter                providers.ResourceFilter
+	rateLimits            providers.RateLimits
+	force                 providers.ForceOptions
+	networkAccount        providers.NetworkAccount
+}
+
+var destroyClusterOpts destroyClusterOptions
+
 func newDestroyClusterCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "cluster",
 		Short: "Destroy an OpenShift cluster",
 		Args:  cobra.ExactArgs(0),
@@ -52,22 +83,178 @@ func newDestroyClusterCmd() *cobra.Command {
 			cleanup := command.SetupFileHook(command.RootOpts.Dir)
 			defer cleanup()
 
-			err := runDestroyCmd(command.RootOpts.Dir, os.Getenv("OPENSHIFT_INSTALL_REPORT_QUOTA_FOOTPRINT") == "true")
+			if err := reconstructMetadata(command.RootOpts.Dir); err != nil {
+				logrus.Fatal(err)
+			}
+
+			if destroyClusterOpts.dryRun {
+				if err := listResourcesToDestroy(command.RootOpts.Dir, destroyClusterOpts, os.Stdout); err != nil {
+					logrus.Fatal(err)
+				}
+				return
+			}
+
+			err := runDestroyCmd(command.RootOpts.Dir, os.Getenv("OPENSHIFT_INSTALL_REPORT_QUOTA_FOOTPRINT") == "true", destroyClusterOpts)
 			if err != nil {
 				logrus.Fatal(err)
 			}
 			logrus.Infof("Uninstallation complete!")
 		},
 	}
+	cmd.Flags().BoolVar(&destroyClusterOpts.dryRun, "dry-run", false, "list the resources which would be deleted, without deleting them")
+	cmd.Flags().StringVar(&destroyClusterOpts.kubeconfig, "kubeconfig", "", "kubeconfig of the running cluster, to reconstruct the lost metadata.json of the cluster from")
+	cmd.Flags().StringVar(&destroyClusterOpts.platform, "platform", "", "platform of the cluster whose metadata.json was lost, with --infra-id: aws or azure")
+	cmd.Flags().StringVar(&destroyClusterOpts.region, "region", "", "region of the cluster whose metadata.json was lost, with --platform")
+	cmd.Flags().StringVar(&destroyClusterOpts.infraID, "infra-id", "", "infra ID of the cluster whose metadata.json was lost, with --platform")
+	cmd.Flags().StringArrayVar(&destroyClusterOpts.filter.ExcludeTags, "exclude-tag", nil, "tag, as key or key=value, of the resources which are not deleted even if they carry the tags of the cluster, e.g. shared hosted zones (may be repeated)")
+	cmd.Flags().BoolVar(&destroyClusterOpts.preserveImageRegistry, "preserve-image-registry", false, "keep the storage of the image registry (S3 or GCS bucket, Swift container, or Azure storage account moved to the <infra ID>-image-registry-rg resource group) to retain or migrate its contents")
+	cmd.Flags().BoolVar(&destroyClusterOpts.wipeDisks, "wipe-disks", false, "on bare metal, securely erase the disks of the hosts in the install config through their Redfish BMCs and power them off, so that stale RAID and partition signatures do not break re-provisioning them")
+	cmd.Flags().BoolVar(&destroyClusterOpts.skipPreflight, "skip-preflight", false, "skip checking, before deleting anything, that the credentials are allowed to delete the resources of the cluster")
+	cmd.Flags().StringVar(&destroyClusterOpts.networkAccount.Role, "network-account-role", "", "on AWS, ARN of the role to assume in the account owning the shared VPC of the cluster, to delete its records in the private hosted zone and remove the shared tags of its subnets there")
+	cmd.Flags().StringVar(&destroyClusterOpts.networkAccount.Profile, "network-account-profile", "", "on AWS, profile of the credentials of the account owning the shared VPC of the cluster, instead of --network-account-role")
+	cmd.Flags().BoolVar(&destroyClusterOpts.deregister, "deregister", false, "once the cluster is destroyed, archive it in the OpenShift Cluster Manager of console.redhat.com, with the cloud.openshift.com token of the pull secret in the install config")
+	cmd.Flags().StringVar(&destroyClusterOpts.ocmURL, "ocm-url", destroy.DefaultOCMURL, "URL of the OpenShift Cluster Manager API used with --deregister")
+	cmd.Flags().StringVar(&destroyClusterOpts.hooksDir, "hooks-dir", "", "directory of executables run, in lexical order, once the cluster is destroyed, with metadata.json on their standard input, to tear down the resources not managed by the installer")
+	cmd.Flags().BoolVar(&destroyClusterOpts.force.Force, "force", false, "give up on the resources which keep failing to be deleted for longer than --resource-timeout, recording them in the destroy report, instead of retrying them until they are deleted")
+	cmd.Flags().DurationVar(&destroyClusterOpts.force.ResourceTimeout, "resource-timeout", 0, "how long a resource can keep failing to be deleted before --force gives up on it (default 10m)")
+	cmd.Flags().IntVar(&destroyClusterOpts.rateLimits.MaxParallel, "max-parallel", 0, "number of resources deleted at the same time (default 1)")
+	cmd.Flags().Float64Var(&destroyClusterOpts.rateLimits.RequestsPerSecond, "api-rate-limit", 0, "maximum number of calls per second to the cloud APIs, unlimited when 0")
+	cmd.Flags().IntVar(&destroyClusterOpts.rateLimits.Burst, "api-burst", 0, "number of calls to the cloud APIs which can exceed --api-rate-limit at once (default 1)")
+	cmd.Flags().StringSliceVar(&destroyClusterOpts.filter.IncludeTypes, "include-type", nil, "comma-separated types of the only resources which are deleted, e.g. \"ec2:instance,elasticloadbalancing\"; the assets directory is kept when filtering, to destroy the rest of the cluster later")
+	return cmd
+}
+
+// reconstructMetadata writes the metadata.json of the cluster, which was
+// lost, to the directory, reconstructed from the running cluster with
+// --kubeconfig or from --platform, --region and --infra-id.
+func reconstructMetadata(directory string) error {
+	opts := destroyClusterOpts
+	fromFlags := opts.platform != "" || opts.region != "" || opts.infraID != ""
+	if opts.kubeconfig == "" && !fromFlags {
+		return nil
+	}
+	if opts.kubeconfig != "" && fromFlags {
+		return errors.New("--kubeconfig cannot be used with --platform, --region and --infra-id")
+	}
+	if _, err := cluster.LoadMetadata(directory); !os.IsNotExist(err) {
+		return errors.Errorf("%s already holds the metadata of a cluster, which is only reconstructed when it was lost", directory)
+	}
+
+	var metadata *types.ClusterMetadata
+	if opts.kubeconfig != "" {
+		data, err := statecrypt.ReadFile(opts.kubeconfig)
+		if err != nil {
+			return errors.Wrap(err, "failed to read the kubeconfig")
+		}
+		config, err := clientcmd.RESTConfigFromKubeConfig(data)
+		if err != nil {
+			return errors.Wrap(err, "failed to load the kubeconfig")
+		}
+		metadata, err = destroy.MetadataFromCluster(context.Background(), config)
+		if err != nil {
+			return errors.Wrap(err, "failed to reconstruct the metadata from the cluster")
+		}
+	} else {
+		var err error
+		metadata, err = destroy.SyntheticMetadata(opts.platform, opts.region, opts.infraID)
+		if err != nil {
+			return errors.Wrap(err, "failed to reconstruct the metadata")
+		}
+	}
+	if err := cluster.SaveMetadata(directory, metadata); err != nil {
+		return err
+	}
+	logrus.Infof("Reconstructed the metadata of cluster %s (infra ID %s)", metadata.ClusterName, metadata.InfraID)
+	return nil
 }
 
-func runDestroyCmd(directory string, reportQuota bool) error {
+// listResourcesToDestroy prints a table of the resources which destroying
+// the cluster would delete.
+func listResourcesToDestroy(directory string, opts destroyClusterOptions, out io.Writer) error {
+	destroyer, err := newDestroyer(directory, opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the resources to destroy")
+	}
+	resources, err := destroy.ListResources(context.Background(), destroyer, directory)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the resources to destroy")
+	}
+	if err := printResources(out, resources); err != nil {
+		return err
+	}
+	logrus.Infof("%d resources would be deleted", len(resources))
+	return nil
+}
+
+// printResources prints a table of the resources, sorted by type and ID.
+func printResources(out io.Writer, resources []providers.Resource) error {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Type != resources[j].Type {
+			return resources[i].Type < resources[j].Type
+		}
+		return resources[i].ID < resources[j].ID
+	})
+
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tID\tREGION")
+	for _, resource := range resources {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", resource.Type, resource.ID, resource.Region)
+	}
+	return tw.Flush()
+}
+
+// newDestroyer returns the Destroyer based on the metadata in the directory,
+// configured with the options.
+func newDestroyer(directory string, opts destroyClusterOptions) (providers.Destroyer, error) {
+	destroyer, err := destroy.NewFiltered(logrus.StandardLogger(), directory, opts.filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := destroy.SetRateLimits(destroyer, directory, opts.rateLimits); err != nil {
+		return nil, err
+	}
+	if err := destroy.SetForceOptions(destroyer, directory, opts.force); err != nil {
+		return nil, err
+	}
+	if err := destroy.SetNetworkAccount(destroyer, directory, opts.networkAccount); err != nil {
+		return nil, err
+	}
+	if opts.preserveImageRegistry {
+		if err := destroy.PreserveImageRegistry(destroyer, directory); err != nil {
+			return nil, err
+		}
+	}
+	if opts.wipeDisks {
+		if err := destroy.WipeHosts(destroyer, directory); err != nil {
+			return nil, err
+		}
+	}
+	return destroyer, nil
+}
+
+func runDestroyCmd(directory string, reportQuota bool, opts destroyClusterOptions) error {
 	timer.StartTimer(timer.TotalTimeElapsed)
-	destroyer, err := destroy.New(logrus.StandardLogger(), directory)
+	destroyer, err := newDestroyer(directory, opts)
+	if err != nil {
+		return errors.Wrap(err, "Failed while preparing to destroy cluster")
+	}
+	report, err := destroy.NewReport(directory)
 	if err != nil {
 		return errors.Wrap(err, "Failed while preparing to destroy cluster")
 	}
+	if !opts.skipPreflight {
+		if err := destroy.Preflight(context.Background(), destroyer); err != nil {
+			return errors.Wrap(err, "Failed while preparing to destroy cluster")
+		}
+	}
+	tracker := providers.NewResourceTracker()
+	if tracked, ok := destroyer.(providers.Tracked); ok {
+		tracked.SetResourceTracker(tracker)
+	}
 	quota, err := destroyer.Run()
+	if err := report.Write(directory, tracker, err); err != nil {
+		logrus.Warn(err)
+	}
 	if err != nil {
 		return errors.Wrap(err, "Failed to destroy cluster")
 	}
@@ -78,6 +265,34 @@ func runDestroyCmd(directory string, reportQuota bool) error {
 		}
 	}
 
+	if !opts.filter.IsEmpty() {
+		// The metadata is still needed to destroy the skipped resources later.
+		logrus.Info("Keeping the assets directory since some resources were skipped")
+		if opts.hooksDir != "" {
+			logrus.Info("Skipping the destroy hooks since some resources were skipped")
+		}
+		if opts.deregister {
+			logrus.Info("Skipping the deregistration since some resources were skipped")
+		}
+		timer.StopTimer(timer.TotalTimeElapsed)
+		timer.LogSummary()
+		return nil
+	}
+
+	if opts.deregister {
+		// The assets are kept when the deregistration fails, to retry it.
+		if err := destroy.Deregister(context.Background(), directory, opts.ocmURL); err != nil {
+			return errors.Wrap(err, "failed to deregister the cluster")
+		}
+	}
+
+	if opts.hooksDir != "" {
+		// The assets are kept when a hook fails, to run the hooks again.
+		if err := destroy.RunHooks(context.Background(), opts.hooksDir, directory); err != nil {
+			return err
+		}
+	}
+
 	store, err := assetstore.NewStore(directory)
 	if err != nil {
 		return errors.Wrap(err, "failed to create asset store")
@@ -119,7 +334,12 @@ func newDestroyBootstrapCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "bootstrap",
 		Short: "Destroy the bootstrap resources",
-		Args:  cobra.ExactArgs(0),
+		Long: `Destroy the bootstrap resources.
+
+For installations with the agent-based installer, which have no bootstrap
+resources, reboot the rendezvous host into its final role if its reboot is
+pending, and remove the artifacts used to boot the hosts.`,
+		Args: cobra.ExactArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
 			cleanup := command.SetupFileHook(command.RootOpts.Dir)
 			defer cleanup()
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
+)
+
+func TestPrintResources(t *testing.T) {
+	resources := []providers.Resource{
+		{Type: "s3", ID: "arn:aws:s3:::test-x2k4f-image-registry-us-east-1-abc"},
+		{Type: "ec2:instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-2", Region: "us-east-1"},
+		{Type: "ec2:instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-1", Region: "us-east-1"},
+		{Type: "route53:hostedzone", ID: "arn:aws:route53:::hostedzone/Z0123"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printResources(&out, resources))
+	expected := `TYPE                ID                                                    REGION
+ec2:instance        arn:aws:ec2:us-east-1:123456789012:instance/i-1       us-east-1
+ec2:instance        arn:aws:ec2:us-east-1:123456789012:instance/i-2       us-east-1
+route53:hostedzone  arn:aws:route53:::hostedzone/Z0123                    
+s3                  arn:aws:s3:::test-x2k4f-image-registry-us-east-1-abc  
+`
+	assert.Equal(t, expected, out.String())
+}
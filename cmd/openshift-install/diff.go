@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+	targetassets "github.com/openshift/installer/pkg/asset/targets"
+)
+
+var diffOpts struct {
+	target string
+}
+
+// diffTargets are the targets which can be compared by the diff command.
+var diffTargets = map[string][]asset.WritableAsset{
+	"manifests":        targetassets.Manifests,
+	"ignition-configs": targetassets.IgnitionConfigs,
+}
+
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the assets on disk with the ones the installer generates",
+		Long: `Regenerates the assets of a target from the install config and the
+installer state in a temporary directory, and prints a unified diff from
+the assets found in the asset directory to the regenerated ones.
+
+This shows the changes made by hand to the manifests or ignition configs.
+Files added to the asset directory by hand are not compared.`,
+		Args:              cobra.ExactArgs(0),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			changed, err := runDiffCmd(command.RootOpts.Dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if !changed {
+				logrus.Info("No differences found")
+			}
+		},
+	}
+	cmd.Flags().StringVar(&diffOpts.target, "target", "manifests", "target to compare (manifests or ignition-configs)")
+	if err := cmd.RegisterFlagCompletionFunc("target", command.CompleteValues("manifests", "ignition-configs")); err != nil {
+		logrus.Debugf("Failed to register completion for flag target: %v", err)
+	}
+	return cmd
+}
+
+func runDiffCmd(directory string) (bool, error) {
+	targets, ok := diffTargets[diffOpts.target]
+	if !ok {
+		return false, errors.Errorf("invalid target %q, must be manifests or ignition-configs", diffOpts.target)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "openshift-install-diff-")
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Leave the targets out of the copied state so that they are regenerated
+	// from their parents instead of being loaded as they were last written.
+	excluded := make([]asset.Asset, 0, len(targets))
+	for _, a := range targets {
+		excluded = append(excluded, a)
+	}
+	if err := assetstore.CopyState(directory, tmpDir, excluded...); err != nil {
+		return false, errors.Wrap(err, "failed to copy the installer state")
+	}
+	if data, err := os.ReadFile(filepath.Join(directory, installConfigFilename)); err == nil {
+		if err := os.WriteFile(filepath.Join(tmpDir, installConfigFilename), data, 0o600); err != nil {
+			return false, err
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	assetStore, err := assetstore.NewStore(tmpDir)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create asset store")
+	}
+	var files []*asset.File
+	for _, a := range targets {
+		if err := assetStore.Fetch(a, targets...); err != nil {
+			return false, errors.Wrapf(err, "failed to fetch %s", a.Name())
+		}
+		files = append(files, a.Files()...)
+	}
+	return asset.DiffFiles(os.Stdout, directory, files)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http/httpproxy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	"github.com/openshift/installer/pkg/poll"
+	"github.com/openshift/library-go/pkg/route/routeapihelpers"
+)
+
+const (
+	canaryNamespace = "openshift-ingress-canary"
+	canaryRouteName = "canary"
+
+	// canaryResponse is the body of the responses of the ingress canary.
+	canaryResponse = "Healthcheck requested"
+)
+
+var installCompleteOpts struct {
+	verifyEndpoints bool
+	gatherOnFailure bool
+}
+
+// verifyEndpoints probes the console and the ingress canary over HTTPS from
+// the host running the installer, through the proxy of the cluster if any,
+// until both respond. This catches the DNS records and load balancers in front
+// of the ingress which are not wired yet, while the cluster reports itself as
+// installed.
+func verifyEndpoints(ctx context.Context, config *rest.Config, consoleURL string) error {
+	client, err := endpointsHTTPClient(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	rc, err := routeclient.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a route client")
+	}
+	canary, err := rc.RouteV1().Routes(canaryNamespace).Get(ctx, canaryRouteName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the route %s/%s", canaryNamespace, canaryRouteName)
+	}
+	canaryURL, _, err := routeapihelpers.IngressURI(canary, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the URL of the route %s/%s", canaryNamespace, canaryRouteName)
+	}
+
+	probes := map[string]func() error{
+		"ingress canary": func() error { return probeEndpoint(ctx, client, canaryURL.String(), canaryResponse) },
+	}
+	if consoleURL != "" {
+		probes["console"] = func() error { return probeEndpoint(ctx, client, consoleURL, "") }
+	}
+
+	timeout := boundedTimeout(ctx, 10*time.Minute)
+	untilTime := time.Now().Add(timeout)
+	timezone, _ := untilTime.Zone()
+	logrus.Infof("Waiting up to %v (until %v %s) for the console and the ingress canary to respond over HTTPS...",
+		timeout, untilTime.Format(time.Kitchen), timezone)
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	failures := map[string]error{}
+	waitErr := poll.Until(probeCtx, 10*time.Second, func(ctx context.Context) (bool, error) {
+		for name, probe := range probes {
+			if isVerified(failures, name) {
+				continue
+			}
+			if err := probe(); err != nil {
+				if previous, ok := failures[name]; !ok || previous == nil || previous.Error() != err.Error() {
+					logrus.Debugf("The %s does not respond yet: %v", name, err)
+				}
+				failures[name] = err
+				continue
+			}
+			logrus.Infof("The %s responds over HTTPS", name)
+			failures[name] = nil
+		}
+		for name := range probes {
+			if err, ok := failures[name]; !ok || err != nil {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if waitErr != nil {
+		var messages []string
+		for name, err := range failures {
+			if err != nil {
+				messages = append(messages, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+		return errors.Errorf("the cluster endpoints do not respond over HTTPS: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// isVerified returns whether the probe already succeeded.
+func isVerified(failures map[string]error, name string) bool {
+	err, ok := failures[name]
+	return ok && err == nil
+}
+
+// endpointsHTTPClient returns an HTTP client trusting the system, the cluster
+// and the default ingress certificate authorities, which goes through the
+// proxy of the cluster if any.
+func endpointsHTTPClient(ctx context.Context, config *rest.Config) (*http.Client, error) {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if len(config.TLSClientConfig.CAData) > 0 {
+		roots.AppendCertsFromPEM(config.TLSClientConfig.CAData)
+	}
+	// The kubeconfig loaded before the installation completed does not
+	// trust the router certificate authority yet.
+	routerCA, err := routerCABundle(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if !roots.AppendCertsFromPEM(routerCA) {
+		return nil, errors.New("ca-bundle.crt from default-ingress-cert configmap not valid PEM format")
+	}
+
+	cc, err := configclient.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating a config client")
+	}
+	proxy, err := cc.ConfigV1().Proxies().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		logrus.Debugf("Failed to get the cluster proxy, probing without proxy: %v", err)
+		proxy = nil
+	}
+	return newEndpointsHTTPClient(roots, proxy), nil
+}
+
+// newEndpointsHTTPClient returns an HTTP client trusting the roots, which goes
+// through the proxy of the status of the cluster proxy, if any.
+func newEndpointsHTTPClient(roots *x509.CertPool, proxy *configv1.Proxy) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	transport.Proxy = nil
+	if proxy != nil && (proxy.Status.HTTPSProxy != "" || proxy.Status.HTTPProxy != "") {
+		proxyFunc := (&httpproxy.Config{
+			HTTPProxy:  proxy.Status.HTTPProxy,
+			HTTPSProxy: proxy.Status.HTTPSProxy,
+			NoProxy:    proxy.Status.NoProxy,
+		}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}
+}
+
+// probeEndpoint gets the URL and checks that it responds with a successful
+// or redirecting status and, if set, with the expected body.
+func probeEndpoint(ctx context.Context, client *http.Client, endpoint string, expectedBody string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("%s responded with %s", endpoint, resp.Status)
+	}
+	if expectedBody != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the response of %s", endpoint)
+		}
+		if !strings.Contains(string(body), expectedBody) {
+			return errors.Errorf("%s responded with %q instead of %q", endpoint, body, expectedBody)
+		}
+	}
+	return nil
+}
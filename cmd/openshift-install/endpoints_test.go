@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestProbeEndpoint(t *testing.T) {
+	cases := []struct {
+		name         string
+		status       int
+		body         string
+		expectedBody string
+		untrusted    bool
+		expectedErr  string
+	}{
+		{
+			name:         "expected body",
+			status:       http.StatusOK,
+			body:         canaryResponse,
+			expectedBody: canaryResponse,
+		},
+		{
+			name:   "any body",
+			status: http.StatusOK,
+			body:   "<html></html>",
+		},
+		{
+			name:         "wrong body",
+			status:       http.StatusOK,
+			body:         "Application is not available",
+			expectedBody: canaryResponse,
+			expectedErr:  `responded with "Application is not available" instead of "Healthcheck requested"$`,
+		},
+		{
+			name:        "not found",
+			status:      http.StatusNotFound,
+			expectedErr: `responded with 404 Not Found$`,
+		},
+		{
+			name:         "server error",
+			status:       http.StatusServiceUnavailable,
+			body:         canaryResponse,
+			expectedBody: canaryResponse,
+			expectedErr:  `responded with 503 Service Unavailable$`,
+		},
+		{
+			name:        "untrusted certificate authority",
+			status:      http.StatusOK,
+			untrusted:   true,
+			expectedErr: `certificate signed by unknown authority`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+
+			roots := x509.NewCertPool()
+			if !tc.untrusted {
+				roots.AddCert(server.Certificate())
+			}
+			client := newEndpointsHTTPClient(roots, nil)
+
+			err := probeEndpoint(context.Background(), client, server.URL, tc.expectedBody)
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewEndpointsHTTPClientProxy(t *testing.T) {
+	cases := []struct {
+		name     string
+		proxy    *configv1.Proxy
+		url      string
+		expected string
+	}{
+		{
+			name: "no cluster proxy",
+			url:  "https://console-openshift-console.apps.example.com",
+		},
+		{
+			name:  "cluster proxy without proxy status",
+			proxy: &configv1.Proxy{Spec: configv1.ProxySpec{HTTPSProxy: "http://spec.example.com:3128"}},
+			url:   "https://console-openshift-console.apps.example.com",
+		},
+		{
+			name: "HTTPS proxy of the status",
+			proxy: &configv1.Proxy{Status: configv1.ProxyStatus{
+				HTTPProxy:  "http://http-proxy.example.com:3128",
+				HTTPSProxy: "http://https-proxy.example.com:3128",
+			}},
+			url:      "https://console-openshift-console.apps.example.com",
+			expected: "http://https-proxy.example.com:3128",
+		},
+		{
+			name: "no proxy of the status",
+			proxy: &configv1.Proxy{Status: configv1.ProxyStatus{
+				HTTPSProxy: "http://https-proxy.example.com:3128",
+				NoProxy:    ".apps.example.com",
+			}},
+			url: "https://console-openshift-console.apps.example.com",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newEndpointsHTTPClient(x509.NewCertPool(), tc.proxy)
+			transport, ok := client.Transport.(*http.Transport)
+			require.True(t, ok)
+			if transport.Proxy == nil {
+				assert.Empty(t, tc.expected)
+				return
+			}
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			proxyURL, err := transport.Proxy(req)
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Nil(t, proxyURL)
+				return
+			}
+			require.NotNil(t, proxyURL)
+			assert.Equal(t, tc.expected, proxyURL.String())
+		})
+	}
+}
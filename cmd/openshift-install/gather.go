@@ -7,13 +7,16 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	gossh "golang.org/x/crypto/ssh"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 
@@ -24,9 +27,15 @@ import (
 	assetstore "github.com/openshift/installer/pkg/asset/store"
 	"github.com/openshift/installer/pkg/asset/tls"
 	serialgather "github.com/openshift/installer/pkg/gather"
+	baremetalgather "github.com/openshift/installer/pkg/gather/baremetal"
+	clustergather "github.com/openshift/installer/pkg/gather/cluster"
+	"github.com/openshift/installer/pkg/gather/controlplane"
+	"github.com/openshift/installer/pkg/gather/selective"
 	"github.com/openshift/installer/pkg/gather/service"
 	"github.com/openshift/installer/pkg/gather/ssh"
+	"github.com/openshift/installer/pkg/gather/upload"
 	platformstages "github.com/openshift/installer/pkg/terraform/stages/platform"
+	"github.com/openshift/installer/pkg/types/baremetal"
 
 	_ "github.com/openshift/installer/pkg/gather/aws"
 	_ "github.com/openshift/installer/pkg/gather/azure"
@@ -47,6 +56,7 @@ to debug the installation failures`,
 		},
 	}
 	cmd.AddCommand(newGatherBootstrapCmd())
+	cmd.AddCommand(newGatherConsoleCmd())
 	return cmd
 }
 
@@ -54,7 +64,14 @@ var gatherBootstrapOpts struct {
 	bootstrap    string
 	masters      []string
 	sshKeys      []string
+	sshProxy     string
 	skipAnalysis bool
+	upload       string
+	uploadCase   string
+	selective    selective.Options
+	maxSize      string
+	parallel     int
+	compression  string
 }
 
 func newGatherBootstrapCmd() *cobra.Command {
@@ -65,47 +82,178 @@ func newGatherBootstrapCmd() *cobra.Command {
 		Run: func(_ *cobra.Command, _ []string) {
 			cleanup := command.SetupFileHook(command.RootOpts.Dir)
 			defer cleanup()
-			bundlePath, err := runGatherBootstrapCmd(command.RootOpts.Dir)
+			if err := validateGatherUpload(); err != nil {
+				logrus.Fatal(err)
+			}
+			if gatherBootstrapOpts.maxSize != "" {
+				maxSize, err := resource.ParseQuantity(gatherBootstrapOpts.maxSize)
+				if err != nil {
+					logrus.Fatal(errors.Wrap(err, "invalid --max-size"))
+				}
+				gatherBootstrapOpts.selective.MaxSize = maxSize.Value()
+			}
+			if _, err := serialgather.ParseCompression(gatherBootstrapOpts.compression); err != nil {
+				logrus.Fatal(errors.Wrap(err, "invalid --compression"))
+			}
+			if err := gatherBootstrapOpts.selective.Validate(); err != nil {
+				logrus.Fatal(err)
+			}
+			bundlePath, analyzable, err := runGatherBootstrapCmd(command.RootOpts.Dir)
 			if err != nil {
 				logrus.Fatal(err)
 			}
 
 			if !gatherBootstrapOpts.skipAnalysis {
-				if err := service.AnalyzeGatherBundle(bundlePath); err != nil {
-					logrus.Fatal(err)
+				if analyzable {
+					if err := service.AnalyzeGatherBundle(bundlePath); err != nil {
+						logrus.Fatal(err)
+					}
+				}
+				if _, err := diagnoseGatherBundle(bundlePath); err != nil {
+					logrus.Error("Attempted to classify the failure: ", err)
 				}
 			}
 
 			logrus.Infof("Bootstrap gather logs captured here %q", bundlePath)
+			if err := uploadGatherBundle(context.Background(), bundlePath); err != nil {
+				logrus.Fatal(err)
+			}
 		},
 	}
 	cmd.PersistentFlags().StringVar(&gatherBootstrapOpts.bootstrap, "bootstrap", "", "Hostname or IP of the bootstrap host")
 	cmd.PersistentFlags().StringArrayVar(&gatherBootstrapOpts.masters, "master", []string{}, "Hostnames or IPs of all control plane hosts")
 	cmd.PersistentFlags().StringArrayVar(&gatherBootstrapOpts.sshKeys, "key", []string{}, "Path to SSH private keys that should be used for authentication. If no key was provided, SSH private keys from user's environment will be used")
+	cmd.PersistentFlags().StringVar(&gatherBootstrapOpts.sshProxy, "ssh-proxy", "", "Bastion host, as [user@]host[:port], to jump through to reach the bootstrap host of private clusters, authenticating with the same keys")
 	cmd.PersistentFlags().BoolVar(&gatherBootstrapOpts.skipAnalysis, "skipAnalysis", false, "Skip analysis of the gathered data")
+	cmd.PersistentFlags().StringArrayVar(&gatherBootstrapOpts.selective.Units, "unit", nil, "Only gather the journal of the systemd unit, may be repeated (e.g. bootkube.service)")
+	cmd.PersistentFlags().StringVar(&gatherBootstrapOpts.selective.Priority, "journal-priority", "", "Only gather the journal entries of this priority or more severe, by name (e.g. warning) or number")
+	cmd.PersistentFlags().StringArrayVar(&gatherBootstrapOpts.selective.Paths, "path", nil, "Only gather the files matching the absolute glob, may be repeated (e.g. /var/log/bootstrap-control-plane/*)")
+	cmd.PersistentFlags().StringVar(&gatherBootstrapOpts.maxSize, "max-size", "", "Cap the size of the gathered data before compression, truncating what does not fit (e.g. 50Mi)")
+	cmd.PersistentFlags().IntVar(&gatherBootstrapOpts.parallel, "parallel", 4, "Number of control plane hosts gathered from concurrently")
+	cmd.PersistentFlags().StringVar(&gatherBootstrapOpts.compression, "compression", string(serialgather.GzipCompression), "Compression of the log bundle, gzip or zstd (faster, requires the zstd command)")
+	addGatherUploadFlags(cmd.PersistentFlags())
+	compressions := make([]string, 0, len(serialgather.Compressions))
+	for _, compression := range serialgather.Compressions {
+		compressions = append(compressions, string(compression))
+	}
+	if err := cmd.RegisterFlagCompletionFunc("compression", command.CompleteValues(compressions...)); err != nil {
+		logrus.Debugf("Failed to register completion for flag compression: %v", err)
+	}
 	return cmd
 }
 
-func runGatherBootstrapCmd(directory string) (string, error) {
+func newGatherConsoleCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "console",
+		Short: "Gather the consoles of the cluster machines from the cloud APIs, without SSH",
+		Long: `Gather the serial console output, the console screenshots and the instance
+status of the cluster machines from the APIs of the cloud platform.
+
+Unlike the bootstrap gather, this does not need SSH access to the machines, and
+diagnoses the machines which do not boot far enough to be reached, e.g. kernel
+panics and boot loops.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+			bundlePath, err := gatherConsoles(command.RootOpts.Dir)
+			if err != nil {
+				logrus.Fatal(err)
+			}
+			if _, err := diagnoseGatherBundle(bundlePath); err != nil {
+				logrus.Error("Attempted to classify the failure: ", err)
+			}
+			logrus.Infof("Console gather logs captured here %q", bundlePath)
+		},
+	}
+}
+
+// gatherConsoles gathers the consoles of the cluster machines through the
+// APIs of the cloud platform, and returns the path of the bundle.
+func gatherConsoles(directory string) (string, error) {
+	bundlePath, err := filepath.Abs(filepath.Join(directory, fmt.Sprintf("console-log-bundle-%s.tar.gz", time.Now().Format("20060102150405"))))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to stat log file")
+	}
+	consoleGather, err := serialgather.New(logrus.StandardLogger(), bundlePath, "", nil, directory)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to gather the consoles")
+	}
+	logrus.Info("Pulling VM console logs, screenshots and status")
+	if err := consoleGather.Run(); err != nil {
+		return "", errors.Wrap(err, "failed to gather the consoles")
+	}
+	if _, err := os.Stat(bundlePath); err != nil {
+		return "", errors.Wrap(err, "no console was gathered")
+	}
+	return bundlePath, nil
+}
+
+// supportTokenEnv is the environment variable holding the offline token of
+// the Red Hat API used to attach gather bundles to support cases.
+const supportTokenEnv = "OPENSHIFT_INSTALL_SUPPORT_TOKEN"
+
+// addGatherUploadFlags adds the flags uploading the gather bundle, shared by
+// gather bootstrap and the gather of a failed create cluster.
+func addGatherUploadFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&gatherBootstrapOpts.upload, "upload", "", "Upload the gather bundle under the S3 location, as s3://<bucket>/<prefix>")
+	flags.StringVar(&gatherBootstrapOpts.uploadCase, "upload-case", "", "Attach the gather bundle to the Red Hat support case with this number, authenticating with the offline token of the Red Hat API in "+supportTokenEnv)
+}
+
+// validateGatherUpload checks the flags uploading the gather bundle before
+// anything is gathered.
+func validateGatherUpload() error {
+	if gatherBootstrapOpts.uploadCase != "" && os.Getenv(supportTokenEnv) == "" {
+		return errors.Errorf("--upload-case requires the offline token of the Red Hat API in %s", supportTokenEnv)
+	}
+	return nil
+}
+
+// uploadGatherBundle uploads the gather bundle to the locations requested by
+// the flags, logging the checksum of the bundle to check the uploads against.
+func uploadGatherBundle(ctx context.Context, bundlePath string) error {
+	if gatherBootstrapOpts.upload != "" {
+		logrus.Infof("Uploading the gather bundle to %s", gatherBootstrapOpts.upload)
+		location, checksum, err := upload.ToS3(ctx, bundlePath, gatherBootstrapOpts.upload)
+		if err != nil {
+			return errors.Wrap(err, "failed to upload the gather bundle")
+		}
+		logrus.Infof("Gather bundle uploaded to %s with SHA-256 checksum %s", location, checksum)
+	}
+	if gatherBootstrapOpts.uploadCase != "" {
+		logrus.Infof("Attaching the gather bundle to support case %s", gatherBootstrapOpts.uploadCase)
+		checksum, err := upload.ToSupportCase(ctx, bundlePath, gatherBootstrapOpts.uploadCase, os.Getenv(supportTokenEnv))
+		if err != nil {
+			return errors.Wrap(err, "failed to upload the gather bundle")
+		}
+		logrus.Infof("Gather bundle attached to support case %s with SHA-256 checksum %s", gatherBootstrapOpts.uploadCase, checksum)
+	}
+	return nil
+}
+
+// runGatherBootstrapCmd returns the path of the log bundle, and whether it
+// holds the logs of the bootstrap machine to analyze, which it does not when
+// the bare metal hosts were gathered from through their BMCs instead.
+func runGatherBootstrapCmd(directory string) (string, bool, error) {
 	assetStore, err := assetstore.NewStore(directory)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create asset store")
+		return "", false, errors.Wrap(err, "failed to create asset store")
 	}
 	// add the default bootstrap key pair to the sshKeys list
 	bootstrapSSHKeyPair := &tls.BootstrapSSHKeyPair{}
 	if err := assetStore.Fetch(bootstrapSSHKeyPair); err != nil {
-		return "", errors.Wrapf(err, "failed to fetch %s", bootstrapSSHKeyPair.Name())
+		return "", false, errors.Wrapf(err, "failed to fetch %s", bootstrapSSHKeyPair.Name())
 	}
 	tmpfile, err := os.CreateTemp("", "bootstrap-ssh")
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 	defer os.Remove(tmpfile.Name())
 	if _, err := tmpfile.Write(bootstrapSSHKeyPair.Private()); err != nil {
-		return "", err
+		return "", false, err
 	}
 	if err := tmpfile.Close(); err != nil {
-		return "", err
+		return "", false, err
 	}
 	gatherBootstrapOpts.sshKeys = append(gatherBootstrapOpts.sshKeys, tmpfile.Name())
 
@@ -115,7 +263,7 @@ func runGatherBootstrapCmd(directory string) (string, error) {
 	if bootstrap == "" && len(masters) == 0 {
 		config := &installconfig.InstallConfig{}
 		if err := assetStore.Fetch(config); err != nil {
-			return "", errors.Wrapf(err, "failed to fetch %s", config.Name())
+			return "", false, errors.Wrapf(err, "failed to fetch %s", config.Name())
 		}
 
 		for _, stage := range platformstages.StagesForPlatform(config.Config.Platform.Name()) {
@@ -137,19 +285,19 @@ func runGatherBootstrapCmd(directory string) (string, error) {
 	}
 
 	if bootstrap == "" {
-		return "", errors.New("must provide bootstrap host address")
+		return "", false, errors.New("must provide bootstrap host address")
 	}
 
 	return gatherBootstrap(bootstrap, port, masters, directory)
 }
 
-func gatherBootstrap(bootstrap string, port int, masters []string, directory string) (string, error) {
+func gatherBootstrap(bootstrap string, port int, masters []string, directory string) (string, bool, error) {
 	gatherID := time.Now().Format("20060102150405")
 
 	serialLogBundle := filepath.Join(directory, fmt.Sprintf("serial-log-bundle-%s.tar.gz", gatherID))
 	serialLogBundlePath, err := filepath.Abs(serialLogBundle)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to stat log file")
+		return "", false, errors.Wrap(err, "failed to stat log file")
 	}
 
 	consoleGather, err := serialgather.New(logrus.StandardLogger(), serialLogBundlePath, bootstrap, masters, directory)
@@ -163,36 +311,165 @@ func gatherBootstrap(bootstrap string, port int, masters []string, directory str
 	}
 
 	logrus.Info("Pulling debug logs from the bootstrap machine")
-	client, err := ssh.NewClient("core", net.JoinHostPort(bootstrap, strconv.Itoa(port)), gatherBootstrapOpts.sshKeys)
+	client, err := ssh.NewClientThroughProxy("core", net.JoinHostPort(bootstrap, strconv.Itoa(port)), gatherBootstrapOpts.sshKeys, gatherBootstrapOpts.sshProxy)
 	if err != nil {
 		if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ETIMEDOUT) {
-			return "", errors.Wrap(err, "failed to connect to the bootstrap machine")
+			err = errors.Wrap(err, "failed to connect to the bootstrap machine")
+		} else {
+			err = errors.Wrap(err, "failed to create SSH client")
 		}
-		return "", errors.Wrap(err, "failed to create SSH client")
+		return gatherBootstrapFromBMCs(gatherID, serialLogBundlePath, directory, err)
 	}
 
-	if err := ssh.Run(client, fmt.Sprintf("/usr/local/bin/installer-gather.sh --id %s %s", gatherID, strings.Join(masters, " "))); err != nil {
-		return "", errors.Wrap(err, "failed to run remote command")
+	if !gatherBootstrapOpts.selective.IsEmpty() {
+		return gatherBootstrapSelectively(client, net.JoinHostPort(bootstrap, strconv.Itoa(port)), masters, gatherID, serialLogBundlePath, directory)
 	}
 
-	file := filepath.Join(directory, fmt.Sprintf("cluster-log-bundle-%s.tar.gz", gatherID))
-	if err := ssh.PullFileTo(client, fmt.Sprintf("/home/core/log-bundle-%s.tar.gz", gatherID), file); err != nil {
-		return "", errors.Wrap(err, "failed to pull log file from remote")
+	clusterLogBundlePath, err := filepath.Abs(filepath.Join(directory, fmt.Sprintf("cluster-log-bundle-%s.tar.gz", gatherID)))
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to stat log file")
+	}
+	controlPlaneLogBundlePath, err := filepath.Abs(filepath.Join(directory, fmt.Sprintf("control-plane-log-bundle-%s.tar.gz", gatherID)))
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to stat log file")
 	}
 
-	clusterLogBundlePath, err := filepath.Abs(file)
+	// The control plane hosts are gathered concurrently from the installer,
+	// while installer-gather.sh gathers the bootstrap host.
+	var wg sync.WaitGroup
+	if len(masters) > 0 {
+		proxy := gatherBootstrapOpts.sshProxy
+		if proxy == "" {
+			proxy = "core@" + net.JoinHostPort(bootstrap, strconv.Itoa(port))
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := controlplane.Gather(client, masters, proxy, gatherBootstrapOpts.sshKeys, gatherID, gatherBootstrapOpts.parallel, controlPlaneLogBundlePath); err != nil {
+				logrus.Warnf("Failed to gather the control plane hosts: %v", err)
+			}
+		}()
+	}
+	err = ssh.Run(client, fmt.Sprintf("/usr/local/bin/installer-gather.sh --id %s", gatherID))
+	if err == nil {
+		err = errors.Wrap(ssh.PullFileTo(client, fmt.Sprintf("/home/core/log-bundle-%s.tar.gz", gatherID), clusterLogBundlePath), "failed to pull log file from remote")
+	} else {
+		err = errors.Wrap(err, "failed to run remote command")
+	}
+	wg.Wait()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to stat log file")
+		return "", false, err
 	}
 
-	logBundlePath := filepath.Join(filepath.Dir(clusterLogBundlePath), fmt.Sprintf("log-bundle-%s.tar.gz", gatherID))
+	logBundlePath := filepath.Join(filepath.Dir(clusterLogBundlePath), logBundleName(gatherID))
 	archives := map[string]string{serialLogBundlePath: "serial", clusterLogBundlePath: ""}
+	if len(masters) > 0 {
+		archives[controlPlaneLogBundlePath] = ""
+	}
 	err = serialgather.CombineArchives(logBundlePath, archives)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to combine archives")
+		return "", false, errors.Wrap(err, "failed to combine archives")
+	}
+	if maxSize := gatherBootstrapOpts.selective.MaxSize; maxSize > 0 {
+		if err := serialgather.TruncateArchive(logBundlePath, maxSize); err != nil {
+			return "", false, errors.Wrap(err, "failed to truncate the log bundle")
+		}
+	}
+
+	return logBundlePath, true, nil
+}
+
+// logBundleName returns the name of the log bundle, with the extension of its
+// compression.
+func logBundleName(gatherID string) string {
+	return fmt.Sprintf("log-bundle-%s%s", gatherID, serialgather.Compression(gatherBootstrapOpts.compression).Extension())
+}
+
+// gatherBootstrapSelectively gathers only the units, journal priorities and
+// files selected by the flags, from the bootstrap host and from the control
+// plane hosts through the SSH proxy, or through the bootstrap host when there
+// is none. The services analyzed are not gathered.
+func gatherBootstrapSelectively(client *gossh.Client, bootstrap string, masters []string, gatherID, serialLogBundlePath, directory string) (string, bool, error) {
+	proxy := gatherBootstrapOpts.sshProxy
+	if proxy == "" {
+		proxy = "core@" + bootstrap
+	}
+	selectiveLogBundlePath, err := filepath.Abs(filepath.Join(directory, "selective-"+logBundleName(gatherID)))
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to stat log file")
+	}
+	if err := selective.Gather(client, masters, proxy, gatherBootstrapOpts.sshKeys, gatherBootstrapOpts.selective, gatherBootstrapOpts.parallel, selectiveLogBundlePath); err != nil {
+		logrus.Warnf("Failed to gather the selected logs: %v", err)
 	}
 
-	return logBundlePath, nil
+	logBundlePath := filepath.Join(filepath.Dir(selectiveLogBundlePath), logBundleName(gatherID))
+	archives := map[string]string{serialLogBundlePath: "serial", selectiveLogBundlePath: ""}
+	if err := serialgather.CombineArchives(logBundlePath, archives); err != nil {
+		return "", false, errors.Wrap(err, "failed to combine archives")
+	}
+	return logBundlePath, false, nil
+}
+
+// gatherBootstrapFromBMCs gathers the console output and logs of the control
+// plane bare metal hosts of the install config through their BMCs, when the
+// bootstrap machine cannot be reached over SSH, e.g. because the network is
+// misconfigured. It returns the SSH error when there are no such hosts.
+func gatherBootstrapFromBMCs(gatherID, serialLogBundlePath, directory string, sshErr error) (string, bool, error) {
+	assetStore, err := assetstore.NewStore(directory)
+	if err != nil {
+		return "", false, sshErr
+	}
+	config := &installconfig.InstallConfig{}
+	if err := assetStore.Fetch(config); err != nil || config.Config.Platform.BareMetal == nil {
+		return "", false, sshErr
+	}
+	var hosts []*baremetal.Host
+	for _, host := range config.Config.Platform.BareMetal.Hosts {
+		if host.IsMaster() {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		// The roles of the hosts are optional.
+		hosts = config.Config.Platform.BareMetal.Hosts
+	}
+	if len(hosts) == 0 {
+		return "", false, sshErr
+	}
+
+	logrus.Warn(sshErr)
+	logrus.Info("Pulling console logs from the BMCs of the control plane hosts")
+	bmcLogBundlePath, err := filepath.Abs(filepath.Join(directory, fmt.Sprintf("bmc-log-bundle-%s.tar.gz", gatherID)))
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to stat log file")
+	}
+	if err := baremetalgather.New(logrus.StandardLogger(), bmcLogBundlePath, hosts).Run(); err != nil {
+		logrus.Warnf("Failed to gather logs from the BMCs: %v", err)
+	}
+
+	logBundlePath := filepath.Join(filepath.Dir(bmcLogBundlePath), logBundleName(gatherID))
+	archives := map[string]string{serialLogBundlePath: "serial", bmcLogBundlePath: "bmc"}
+	if err := serialgather.CombineArchives(logBundlePath, archives); err != nil {
+		return "", false, errors.Wrap(err, "failed to combine archives")
+	}
+	return logBundlePath, false, nil
+}
+
+// gatherCluster gathers the cluster operators, the warning events and the
+// pod logs of the failing operators through the API into the directory, for
+// when the install fails to complete after bootstrapping.
+func gatherCluster(ctx context.Context, config *rest.Config, directory string) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	logrus.Info("Gathering the state of the cluster through the API...")
+	bundlePath := filepath.Join(directory, fmt.Sprintf("cluster-api-bundle-%s.tar.gz", time.Now().Format("20060102150405")))
+	if err := clustergather.Gather(ctx, logrus.StandardLogger(), config, bundlePath); err != nil {
+		logrus.Error("Attempted to gather the state of the cluster after installation failure: ", err)
+	}
+	if _, err := os.Stat(bundlePath); err == nil {
+		logrus.Infof("Cluster state captured here %q", bundlePath)
+	}
 }
 
 func logClusterOperatorConditions(ctx context.Context, config *rest.Config) error {
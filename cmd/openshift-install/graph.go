@@ -1,25 +1,53 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/awalterschulze/gographviz"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/openshift/installer/cmd/openshift-install/command"
 	"github.com/openshift/installer/pkg/asset"
 )
 
+const (
+	graphFormatDot     = "dot"
+	graphFormatJSON    = "json"
+	graphFormatMermaid = "mermaid"
+)
+
 var (
+	graphPackageSeparator = regexp.MustCompile(`[. ]`)
+
 	graphOpts struct {
 		outputFile string
+		format     string
 	}
 )
 
+// graphNode is an asset or target in the JSON output of the graph command.
+type graphNode struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Target  bool   `json:"target,omitempty"`
+}
+
+// graphEdge is a dependency in the JSON output of the graph command. The
+// asset From is a dependency of the asset To.
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
 func newGraphCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "graph",
@@ -31,10 +59,26 @@ func newGraphCmd() *cobra.Command {
 		},
 	}
 	cmd.PersistentFlags().StringVar(&graphOpts.outputFile, "output-file", "", "file where the graph is written, if empty prints the graph to Stdout.")
+	cmd.PersistentFlags().StringVar(&graphOpts.format, "format", graphFormatDot, fmt.Sprintf("format of the graph (%s, %s or %s)", graphFormatDot, graphFormatJSON, graphFormatMermaid))
+	if err := cmd.RegisterFlagCompletionFunc("format", command.CompleteValues(graphFormatDot, graphFormatJSON, graphFormatMermaid)); err != nil {
+		logrus.Debugf("Failed to register completion for flag format: %v", err)
+	}
 	return cmd
 }
 
 func runGraphCmd(cmd *cobra.Command, args []string, cmdTargets []target) error {
+	var render func(io.Writer, *gographviz.Graph) error
+	switch graphOpts.format {
+	case graphFormatDot:
+		render = writeDot
+	case graphFormatJSON:
+		render = writeJSON
+	case graphFormatMermaid:
+		render = writeMermaid
+	default:
+		return errors.Errorf("invalid graph format %q, must be %s, %s or %s", graphOpts.format, graphFormatDot, graphFormatJSON, graphFormatMermaid)
+	}
+
 	g := gographviz.NewGraph()
 	g.SetName("G")
 	g.SetDir(true)
@@ -53,9 +97,8 @@ func runGraphCmd(cmd *cobra.Command, args []string, cmdTargets []target) error {
 	}
 
 	g.AddAttr("G", "rankdir", "LR")
-	r := regexp.MustCompile(`[. ]`)
 	for _, node := range g.Nodes.Nodes {
-		cluster := r.Split(node.Name, -1)[0][1:]
+		cluster := graphNodePackage(node.Name)
 		subgraphName := "cluster_" + cluster
 		_, ok := g.SubGraphs.SubGraphs[subgraphName]
 		if !ok {
@@ -74,10 +117,82 @@ func runGraphCmd(cmd *cobra.Command, args []string, cmdTargets []target) error {
 		out = f
 	}
 
-	if _, err := io.WriteString(out, g.String()); err != nil {
-		return err
+	return render(out, g)
+}
+
+func writeDot(out io.Writer, g *gographviz.Graph) error {
+	_, err := io.WriteString(out, g.String())
+	return err
+}
+
+func writeJSON(out io.Writer, g *gographviz.Graph) error {
+	graph := struct {
+		Nodes []graphNode `json:"nodes"`
+		Edges []graphEdge `json:"edges"`
+	}{
+		Nodes: make([]graphNode, 0, len(g.Nodes.Nodes)),
+		Edges: make([]graphEdge, 0, len(g.Edges.Edges)),
+	}
+	for _, node := range g.Nodes.Nodes {
+		graph.Nodes = append(graph.Nodes, graphNode{
+			Name:    graphNodeName(node.Name),
+			Package: graphNodePackage(node.Name),
+			Target:  strings.HasPrefix(graphNodeName(node.Name), "Target "),
+		})
+	}
+	for _, edge := range g.Edges.Edges {
+		graph.Edges = append(graph.Edges, graphEdge{From: graphNodeName(edge.Src), To: graphNodeName(edge.Dst)})
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(graph)
+}
+
+func writeMermaid(out io.Writer, g *gographviz.Graph) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	// Mermaid identifiers cannot contain dots or spaces, so the nodes are
+	// numbered and labeled with their name instead.
+	ids := make(map[string]string, len(g.Nodes.Nodes))
+	packages := []string{}
+	nodesByPackage := map[string][]string{}
+	for i, node := range g.Nodes.Nodes {
+		ids[node.Name] = fmt.Sprintf("n%d", i)
+		pkg := graphNodePackage(node.Name)
+		if _, ok := nodesByPackage[pkg]; !ok {
+			packages = append(packages, pkg)
+		}
+		nodesByPackage[pkg] = append(nodesByPackage[pkg], node.Name)
+	}
+	for _, pkg := range packages {
+		fmt.Fprintf(&b, "  subgraph %s [%s]\n", strings.ReplaceAll(pkg, " ", "_"), pkg)
+		for _, name := range nodesByPackage[pkg] {
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", ids[name], graphNodeName(name))
+		}
+		b.WriteString("  end\n")
 	}
-	return nil
+	for _, edge := range g.Edges.Edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[edge.Src], ids[edge.Dst])
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// graphNodeName returns the name of a node without the quoting required by dot.
+func graphNodeName(name string) string {
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		return unquoted
+	}
+	return name
+}
+
+// graphNodePackage returns the package of the asset for a node, which is also
+// used to cluster the nodes in the dot output. Targets are in the Target package.
+func graphNodePackage(name string) string {
+	return graphPackageSeparator.Split(graphNodeName(name), -1)[0]
 }
 
 func addEdge(g *gographviz.Graph, parent string, asset asset.Asset) {
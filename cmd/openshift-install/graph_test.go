@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/awalterschulze/gographviz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGraph returns a graph of a target with quoted node names, as built by
+// the graph command.
+func testGraph(t *testing.T) *gographviz.Graph {
+	t.Helper()
+	g := gographviz.NewGraph()
+	require.NoError(t, g.SetName("G"))
+	require.NoError(t, g.SetDir(true))
+	for _, node := range []string{`"Target Install Config"`, `"installconfig.InstallConfig"`, `"installconfig.SSHKey"`, `"tls.RootCA"`} {
+		require.NoError(t, g.AddNode("G", node, nil))
+	}
+	require.NoError(t, g.AddEdge(`"installconfig.InstallConfig"`, `"Target Install Config"`, true, nil))
+	require.NoError(t, g.AddEdge(`"installconfig.SSHKey"`, `"installconfig.InstallConfig"`, true, nil))
+	require.NoError(t, g.AddEdge(`"tls.RootCA"`, `"Target Install Config"`, true, nil))
+	return g
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeJSON(&out, testGraph(t)))
+	assert.JSONEq(t, `{
+  "nodes": [
+    {"name": "Target Install Config", "package": "Target", "target": true},
+    {"name": "installconfig.InstallConfig", "package": "installconfig"},
+    {"name": "installconfig.SSHKey", "package": "installconfig"},
+    {"name": "tls.RootCA", "package": "tls"}
+  ],
+  "edges": [
+    {"from": "installconfig.InstallConfig", "to": "Target Install Config"},
+    {"from": "installconfig.SSHKey", "to": "installconfig.InstallConfig"},
+    {"from": "tls.RootCA", "to": "Target Install Config"}
+  ]
+}`, out.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeMermaid(&out, testGraph(t)))
+	assert.Equal(t, `flowchart LR
+  subgraph Target [Target]
+    n0["Target Install Config"]
+  end
+  subgraph installconfig [installconfig]
+    n1["installconfig.InstallConfig"]
+    n2["installconfig.SSHKey"]
+  end
+  subgraph tls [tls]
+    n3["tls.RootCA"]
+  end
+  n1 --> n0
+  n2 --> n1
+  n3 --> n0
+`, out.String())
+}
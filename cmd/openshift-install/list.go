@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/version"
+)
+
+const (
+	listOutputText = "text"
+	listOutputJSON = "json"
+)
+
+var listOpts struct {
+	output   string
+	platform string
+	region   string
+	arch     string
+}
+
+type listedRegion struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the values accepted in the install config",
+		Long:  "",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&listOpts.output, "output", "o", listOutputText, fmt.Sprintf("output format (%s or %s)", listOutputText, listOutputJSON))
+	if err := cmd.RegisterFlagCompletionFunc("output", command.CompleteValues(listOutputText, listOutputJSON)); err != nil {
+		logrus.Debugf("Failed to register completion for flag output: %v", err)
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "platforms",
+		Short: "List the supported platforms",
+		Args:  cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := validateListOutput(); err != nil {
+				logrus.Fatal(err)
+			}
+			if err := printList(command.Platforms()); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	})
+
+	regionsCmd := &cobra.Command{
+		Use:   "regions",
+		Short: "List the regions known for a platform",
+		Long: `Lists the regions known for a platform. The regions of Azure are
+queried from the API and require credentials.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runListRegionsCmd(context.Background()); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	addListPlatformFlag(regionsCmd)
+	cmd.AddCommand(regionsCmd)
+
+	instanceTypesCmd := &cobra.Command{
+		Use:   "instance-types",
+		Short: "List the default instance types for a platform",
+		Long: `Lists the instance types the installer uses for the machine pools of
+a platform when none are configured, in decreasing order of preference.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runListInstanceTypesCmd(); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	addListPlatformFlag(instanceTypesCmd)
+	instanceTypesCmd.Flags().StringVar(&listOpts.region, "region", "", "region the instance types are used in")
+	instanceTypesCmd.Flags().StringVar(&listOpts.arch, "architecture", string(version.DefaultArch()), "architecture of the instances")
+	if err := instanceTypesCmd.RegisterFlagCompletionFunc("region", command.CompleteRegions("platform")); err != nil {
+		logrus.Debugf("Failed to register completion for flag region: %v", err)
+	}
+	if err := instanceTypesCmd.RegisterFlagCompletionFunc("architecture", command.CompleteValues(string(types.ArchitectureAMD64), string(types.ArchitectureARM64))); err != nil {
+		logrus.Debugf("Failed to register completion for flag architecture: %v", err)
+	}
+	cmd.AddCommand(instanceTypesCmd)
+
+	return cmd
+}
+
+func addListPlatformFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&listOpts.platform, "platform", "", "platform to list the values for")
+	if err := cmd.MarkFlagRequired("platform"); err != nil {
+		logrus.Debugf("Failed to mark flag platform as required: %v", err)
+	}
+	if err := cmd.RegisterFlagCompletionFunc("platform", command.CompletePlatforms); err != nil {
+		logrus.Debugf("Failed to register completion for flag platform: %v", err)
+	}
+}
+
+func runListRegionsCmd(ctx context.Context) error {
+	if err := validateListOutput(); err != nil {
+		return err
+	}
+	known, err := installconfig.KnownRegions(ctx, listOpts.platform)
+	if err != nil {
+		return err
+	}
+	regions := make([]listedRegion, 0, len(known))
+	for name, description := range known {
+		regions = append(regions, listedRegion{Name: name, Description: description})
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Name < regions[j].Name })
+
+	if listOpts.output == listOutputJSON {
+		return printJSON(regions)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, region := range regions {
+		fmt.Fprintf(w, "%s\t%s\n", region.Name, region.Description)
+	}
+	return w.Flush()
+}
+
+func runListInstanceTypesCmd() error {
+	if err := validateListOutput(); err != nil {
+		return err
+	}
+	instanceTypes, err := installconfig.DefaultInstanceTypes(listOpts.platform, listOpts.region, types.Architecture(listOpts.arch))
+	if err != nil {
+		return err
+	}
+	return printList(instanceTypes)
+}
+
+func printList(values []string) error {
+	if listOpts.output == listOutputJSON {
+		return printJSON(values)
+	}
+	for _, value := range values {
+		fmt.Println(value)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func validateListOutput() error {
+	if listOpts.output != listOutputText && listOpts.output != listOutputJSON {
+		return errors.Errorf("invalid output format %q, must be %s or %s", listOpts.output, listOutputText, listOutputJSON)
+	}
+	return nil
+}
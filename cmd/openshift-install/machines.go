@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machineclient "github.com/openshift/client-go/machine/clientset/versioned"
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/poll"
+)
+
+const (
+	machineAPINamespace = "openshift-machine-api"
+	clusterAPINamespace = "openshift-cluster-api"
+
+	waitForStateMachinesProvisioned = "machines-provisioned"
+)
+
+// clusterAPIMachines are the Cluster API machines, listed through the dynamic
+// client since their provider is not known to the installer at this point.
+var clusterAPIMachines = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"}
+
+var machineProvisioningOpts struct {
+	stuckAfter time.Duration
+}
+
+// machineState is the state of a Machine API or Cluster API machine.
+type machineState struct {
+	name    string
+	phase   string
+	created time.Time
+	// message is the error reported by the provider of the machine, if any.
+	message string
+}
+
+func newWaitForMachineProvisioningCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "machine-provisioning",
+		Short: "Wait until the machines of the cluster are running",
+		Long: `Wait until the Machine API and Cluster API machines of the cluster are
+running, and the machine sets have all their replicas ready.
+
+The machines which fail, or are still provisioning after --stuck-after, are
+reported with the error of their cloud provider, which otherwise has to be
+looked for in the logs of the machine controllers.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			start := time.Now()
+			ctx := context.Background()
+
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := validateWaitForOutput(); err != nil {
+				logrus.Fatal(err)
+			}
+			config, err := loadKubeconfig(command.RootOpts.Dir)
+			if err != nil {
+				logrus.Fatal(errors.Wrap(err, "loading kubeconfig"))
+			}
+
+			if err := waitForMachineProvisioning(ctx, config, machineProvisioningOpts.stuckAfter); err != nil {
+				logrus.Error(err)
+				printWaitForStatus(ctx, config, start, "", "", err)
+				logrus.Exit(exitCodeInstallFailed)
+			}
+			printWaitForStatus(ctx, config, start, waitForStateMachinesProvisioned, "", nil)
+		},
+	}
+	cmd.Flags().DurationVar(&machineProvisioningOpts.stuckAfter, "stuck-after", 15*time.Minute, "time after which a machine which is not running yet is reported as stuck")
+	return cmd
+}
+
+// waitForMachineProvisioning waits until all the machines are Running and
+// all the machine sets have their replicas ready. It fails as soon as a
+// machine fails, since failed machines are not retried by their controllers.
+func waitForMachineProvisioning(ctx context.Context, config *rest.Config, stuckAfter time.Duration) error {
+	mc, err := machineclient.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a machine client")
+	}
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a dynamic client")
+	}
+
+	timeout := 30 * time.Minute
+	if command.RootOpts.Timeout > 0 {
+		timeout = command.RootOpts.Timeout
+	}
+	untilTime := time.Now().Add(timeout)
+	timezone, _ := untilTime.Zone()
+	logrus.Infof("Waiting up to %v (until %v %s) for the machines to be running...",
+		timeout, untilTime.Format(time.Kitchen), timezone)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	phases := map[string]string{}
+	machineSets := map[string]string{}
+	stuck := map[string]bool{}
+	var pending []machineState
+	var failedErr error
+	waitErr := poll.Until(waitCtx, 15*time.Second, func(ctx context.Context) (bool, error) {
+		machines, err := listMachines(ctx, mc, dc)
+		if err != nil {
+			return false, err
+		}
+
+		pending = nil
+		var failed []string
+		for _, machine := range machines {
+			if machine.phase != phases[machine.name] {
+				logrus.Infof("Machine %s is %s", machine.name, machine.phase)
+				phases[machine.name] = machine.phase
+			}
+			switch machine.phase {
+			case machinev1beta1.PhaseRunning:
+				continue
+			case machinev1beta1.PhaseFailed:
+				failed = append(failed, describeMachine(machine))
+			default:
+				if !stuck[machine.name] && time.Since(machine.created) > stuckAfter {
+					logrus.Warnf("Machine %s is stuck in %s", describeMachine(machine), machine.phase)
+					stuck[machine.name] = true
+				}
+			}
+			pending = append(pending, machine)
+		}
+		if len(failed) > 0 {
+			failedErr = errors.Errorf("machines failed: %s", strings.Join(failed, "; "))
+			return false, failedErr
+		}
+
+		ready, err := machineSetsReady(ctx, mc, machineSets)
+		if err != nil {
+			return false, err
+		}
+		return len(machines) > 0 && len(pending) == 0 && ready, nil
+	})
+	if waitErr == nil {
+		logrus.Info("All the machines are running")
+		return nil
+	}
+	if failedErr != nil {
+		return failedErr
+	}
+	if len(pending) == 0 {
+		return errors.Wrap(waitErr, "failed to wait for the machines")
+	}
+	descriptions := make([]string, 0, len(pending))
+	for _, machine := range pending {
+		descriptions = append(descriptions, fmt.Sprintf("%s is %s", describeMachine(machine), machine.phase))
+	}
+	return errors.Errorf("machines were not running in time: %s: %v", strings.Join(descriptions, "; "), waitErr)
+}
+
+// listMachines returns the Machine API and the Cluster API machines, sorted
+// by name. The APIs which are not installed in the cluster are ignored.
+func listMachines(ctx context.Context, mc *machineclient.Clientset, dc dynamic.Interface) ([]machineState, error) {
+	var machines []machineState
+
+	machineList, err := mc.MachineV1beta1().Machines(machineAPINamespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "failed to list the Machine API machines")
+	}
+	if err == nil {
+		for i := range machineList.Items {
+			machines = append(machines, machineAPIMachineState(&machineList.Items[i]))
+		}
+	}
+
+	capiList, err := dc.Resource(clusterAPIMachines).Namespace(clusterAPINamespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "failed to list the Cluster API machines")
+	}
+	if err == nil {
+		for i := range capiList.Items {
+			machines = append(machines, clusterAPIMachineState(&capiList.Items[i]))
+		}
+	}
+
+	sort.Slice(machines, func(i, j int) bool { return machines[i].name < machines[j].name })
+	return machines, nil
+}
+
+func machineAPIMachineState(machine *machinev1beta1.Machine) machineState {
+	state := machineState{
+		name:    fmt.Sprintf("%s/%s", machine.Namespace, machine.Name),
+		phase:   machinev1beta1.PhaseProvisioning,
+		created: machine.CreationTimestamp.Time,
+	}
+	if machine.Status.Phase != nil && *machine.Status.Phase != "" {
+		state.phase = *machine.Status.Phase
+	}
+
+	switch {
+	case machine.Status.ErrorMessage != nil && *machine.Status.ErrorMessage != "":
+		state.message = *machine.Status.ErrorMessage
+	default:
+		for _, condition := range machine.Status.Conditions {
+			if condition.Status == corev1.ConditionFalse && condition.Message != "" {
+				state.message = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+				break
+			}
+		}
+	}
+	if state.message == "" && machine.Status.ProviderStatus != nil {
+		// The provider statuses differ per platform, but all of them report
+		// the failures to create the instances as conditions.
+		var providerStatus struct {
+			Conditions []machinev1beta1.Condition `json:"conditions"`
+		}
+		if err := json.Unmarshal(machine.Status.ProviderStatus.Raw, &providerStatus); err == nil {
+			for _, condition := range providerStatus.Conditions {
+				if condition.Status == corev1.ConditionFalse && condition.Message != "" {
+					state.message = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+					break
+				}
+			}
+		}
+	}
+	return state
+}
+
+func clusterAPIMachineState(machine *unstructured.Unstructured) machineState {
+	state := machineState{
+		name:    fmt.Sprintf("%s/%s", machine.GetNamespace(), machine.GetName()),
+		phase:   machinev1beta1.PhaseProvisioning,
+		created: machine.GetCreationTimestamp().Time,
+	}
+	if phase, _, _ := unstructured.NestedString(machine.Object, "status", "phase"); phase != "" {
+		state.phase = phase
+	}
+
+	if message, _, _ := unstructured.NestedString(machine.Object, "status", "failureMessage"); message != "" {
+		state.message = message
+		return state
+	}
+	conditions, _, _ := unstructured.NestedSlice(machine.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["status"] != string(corev1.ConditionFalse) {
+			continue
+		}
+		if message, _ := condition["message"].(string); message != "" {
+			reason, _ := condition["reason"].(string)
+			state.message = fmt.Sprintf("%s: %s", reason, message)
+			break
+		}
+	}
+	return state
+}
+
+// machineSetsReady returns whether all the machine sets have their replicas
+// ready, logging the changes of their replicas and their errors.
+func machineSetsReady(ctx context.Context, mc *machineclient.Clientset, statuses map[string]string) (bool, error) {
+	machineSets, err := mc.MachineV1beta1().MachineSets(machineAPINamespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to list the machine sets")
+	}
+
+	ready := true
+	for _, machineSet := range machineSets.Items {
+		replicas := int32(1)
+		if machineSet.Spec.Replicas != nil {
+			replicas = *machineSet.Spec.Replicas
+		}
+		status := fmt.Sprintf("has %d of %d replicas ready", machineSet.Status.ReadyReplicas, replicas)
+		if machineSet.Status.ErrorMessage != nil && *machineSet.Status.ErrorMessage != "" {
+			status = fmt.Sprintf("%s: %s", status, *machineSet.Status.ErrorMessage)
+		}
+		if status != statuses[machineSet.Name] {
+			logrus.Infof("Machine set %s/%s %s", machineSet.Namespace, machineSet.Name, status)
+			statuses[machineSet.Name] = status
+		}
+		ready = ready && machineSet.Status.ReadyReplicas >= replicas
+	}
+	return ready, nil
+}
+
+func describeMachine(machine machineState) string {
+	if machine.message == "" {
+		return machine.name
+	}
+	return fmt.Sprintf("%s (%s)", machine.name, machine.message)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+)
+
+func TestMachineAPIMachineState(t *testing.T) {
+	created := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		status   machinev1beta1.MachineStatus
+		expected machineState
+	}{
+		{
+			name:     "no phase yet",
+			expected: machineState{phase: machinev1beta1.PhaseProvisioning},
+		},
+		{
+			name:     "running",
+			status:   machinev1beta1.MachineStatus{Phase: pointer.String(machinev1beta1.PhaseRunning)},
+			expected: machineState{phase: machinev1beta1.PhaseRunning},
+		},
+		{
+			name: "failed with an error message",
+			status: machinev1beta1.MachineStatus{
+				Phase:        pointer.String(machinev1beta1.PhaseFailed),
+				ErrorMessage: pointer.String("InsufficientInstanceCapacity"),
+				Conditions: machinev1beta1.Conditions{
+					{Type: "InstanceExists", Status: corev1.ConditionFalse, Reason: "ErrorCheckingProvider", Message: "unreachable"},
+				},
+			},
+			expected: machineState{phase: machinev1beta1.PhaseFailed, message: "InsufficientInstanceCapacity"},
+		},
+		{
+			name: "false condition",
+			status: machinev1beta1.MachineStatus{
+				Phase: pointer.String(machinev1beta1.PhaseProvisioning),
+				Conditions: machinev1beta1.Conditions{
+					{Type: "Drainable", Status: corev1.ConditionTrue, Message: "drainable"},
+					{Type: "InstanceExists", Status: corev1.ConditionFalse, Reason: "ErrorCheckingProvider", Message: "unreachable"},
+				},
+			},
+			expected: machineState{phase: machinev1beta1.PhaseProvisioning, message: "ErrorCheckingProvider: unreachable"},
+		},
+		{
+			name: "false provider status condition",
+			status: machinev1beta1.MachineStatus{
+				ProviderStatus: &runtime.RawExtension{Raw: []byte(`{"conditions":[{"type":"MachineCreation","status":"False","reason":"MachineCreationFailed","message":"quota exceeded"}]}`)},
+			},
+			expected: machineState{phase: machinev1beta1.PhaseProvisioning, message: "MachineCreationFailed: quota exceeded"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			machine := &machinev1beta1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: machineAPINamespace, Name: "worker-0", CreationTimestamp: metav1.NewTime(created)},
+				Status:     tc.status,
+			}
+			tc.expected.name = "openshift-machine-api/worker-0"
+			tc.expected.created = created
+			assert.Equal(t, tc.expected, machineAPIMachineState(machine))
+		})
+	}
+}
+
+func TestClusterAPIMachineState(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   map[string]interface{}
+		expected machineState
+	}{
+		{
+			name:     "no phase yet",
+			expected: machineState{phase: machinev1beta1.PhaseProvisioning},
+		},
+		{
+			name:     "running",
+			status:   map[string]interface{}{"phase": "Running"},
+			expected: machineState{phase: "Running"},
+		},
+		{
+			name: "failed with a failure message",
+			status: map[string]interface{}{
+				"phase":          "Failed",
+				"failureMessage": "invalid image",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "InfrastructureReady", "status": "False", "reason": "Failed", "message": "unreachable"},
+				},
+			},
+			expected: machineState{phase: "Failed", message: "invalid image"},
+		},
+		{
+			name: "false condition",
+			status: map[string]interface{}{
+				"phase": "Provisioning",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "BootstrapReady", "status": "True"},
+					map[string]interface{}{"type": "InfrastructureReady", "status": "False", "reason": "WaitingForInstance", "message": "instance is pending"},
+				},
+			},
+			expected: machineState{phase: "Provisioning", message: "WaitingForInstance: instance is pending"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			machine := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			machine.SetNamespace(clusterAPINamespace)
+			machine.SetName("worker-0")
+			if tc.status != nil {
+				machine.Object["status"] = tc.status
+			}
+			tc.expected.name = "openshift-cluster-api/worker-0"
+			assert.Equal(t, tc.expected, clusterAPIMachineState(machine))
+		})
+	}
+}
+
+func TestDescribeMachine(t *testing.T) {
+	cases := []struct {
+		name     string
+		machine  machineState
+		expected string
+	}{
+		{
+			name:     "no message",
+			machine:  machineState{name: "openshift-machine-api/worker-0"},
+			expected: "openshift-machine-api/worker-0",
+		},
+		{
+			name:     "message",
+			machine:  machineState{name: "openshift-machine-api/worker-0", message: "quota exceeded"},
+			expected: "openshift-machine-api/worker-0 (quota exceeded)",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, describeMachine(tc.machine))
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -14,6 +16,17 @@ import (
 	klogv2 "k8s.io/klog/v2"
 
 	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset/cluster"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+	"github.com/openshift/installer/pkg/asset/store"
+	"github.com/openshift/installer/pkg/asset/store/remote"
+	"github.com/openshift/installer/pkg/cachedir"
+	"github.com/openshift/installer/pkg/metrics/progress"
+	"github.com/openshift/installer/pkg/metrics/telemetry"
+	timer "github.com/openshift/installer/pkg/metrics/timer"
+	"github.com/openshift/installer/pkg/notify"
+	"github.com/openshift/installer/pkg/reproducible"
+	"github.com/openshift/installer/pkg/statecrypt"
 )
 
 func main() {
@@ -49,30 +62,77 @@ func installerMain() {
 		newMigrateCmd(),
 		newExplainCmd(),
 		newAgentCmd(),
+		newConfigCmd(),
+		newListCmd(),
+		newDiffCmd(),
+		newCleanCmd(),
+		newRenderCmd(),
+		newDecryptCmd(),
 	} {
 		rootCmd.AddCommand(subCmd)
 	}
+	registerCompletions(rootCmd)
+
+	runProviderPlugin(rootCmd, os.Args[1:])
 
 	if err := rootCmd.Execute(); err != nil {
 		logrus.Fatalf("Error executing openshift-install: %v", err)
 	}
+	pushState()
+	reportTelemetry(true)
+	sendNotification(true)
+	if err := timer.SaveHistory(); err != nil {
+		logrus.Debugf("Failed to save the stage durations: %v", err)
+	}
 }
 
 func newRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:              filepath.Base(os.Args[0]),
-		Short:            "Creates OpenShift clusters",
-		Long:             "",
+		Use:   filepath.Base(os.Args[0]),
+		Short: "Creates OpenShift clusters",
+		Long: `Creates OpenShift clusters.
+
+Every flag can also be set with an environment variable named after it,
+e.g. OPENSHIFT_INSTALL_LOG_LEVEL for --log-level. Flags given on the
+command line take precedence over the environment.
+
+Exit codes:
+  0  success
+  1  unclassified failure
+  3  the install config is invalid or could not be created
+  4  infrastructure provisioning failed
+  5  bootstrapping did not complete in time
+  6  the cluster did not finish initializing in time
+  7  cluster operators did not finish rolling out in time
+  8  quota or permission preflight checks failed`,
 		PersistentPreRun: runRootCmd,
 		SilenceErrors:    true,
 		SilenceUsage:     true,
 	}
 	cmd.PersistentFlags().StringVar(&command.RootOpts.Dir, "dir", ".", "assets directory")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.CacheDir, "cache-dir", "", "directory in which downloaded images are cached (defaults to the user cache directory)")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.LogFile, "log-file", "", "path of the install log file (defaults to "+command.LogFileName+" in the assets directory)")
+	cmd.PersistentFlags().Int64Var(&command.RootOpts.LogMaxSize, "log-max-size", 0, "size in megabytes after which the install log file is rotated, 0 disables rotation")
 	cmd.PersistentFlags().StringVar(&command.RootOpts.LogLevel, "log-level", "info", "log level (e.g. \"debug | info | warn | error\")")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.LogFormat, "log-format", command.LogFormatText, "log format (e.g. \"text | json\")")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.Color, "color", command.ColorAuto, "when to color the output (e.g. \"auto | always | never\")")
+	cmd.PersistentFlags().DurationVar(&command.RootOpts.Timeout, "timeout", 0, "bounds the whole wait of the wait-for commands, and of create cluster, for the Kubernetes API, bootstrapping and installation to complete, instead of the default timeouts of each stage (e.g. \"90m\")")
+	cmd.PersistentFlags().BoolVar(&command.RootOpts.NonInteractive, "non-interactive", false, "fail instead of prompting for any missing input")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.Values, "values", "", "YAML file with the values of the Go template directives in install-config.yaml, which is rendered as a template when set")
+	cmd.PersistentFlags().BoolVar(&command.RootOpts.Reproducible, "reproducible", false, "generate byte-identical manifests and ignition configs for identical inputs, using "+reproducible.SourceDateEpochEnvVar+" as the creation time, IDs derived from the install config and the private keys provided in the tls directory of the assets directory")
+	cmd.PersistentFlags().BoolVar(&command.RootOpts.Concurrent, "concurrent-generation", false, "generate independent assets concurrently, the interactive assets are still generated one at a time")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.Telemetry.Endpoint, "telemetry-endpoint", "", "opt-in: Prometheus push gateway URL to which install phase timings are pushed")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.Telemetry.File, "telemetry-file", "", "opt-in: path of a file to which install phase timings are written as JSON")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.NotifyURL, "notify-url", "", "webhook URL to which the create and wait-for commands post their result as JSON when they finish")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.StateURL, "state-url", "", "object store location (s3://<bucket>/<prefix>, gs://<bucket>/<prefix> or azblob://<account>/<container>/<prefix>) the installer state, metadata and admin kubeconfig are downloaded from before and uploaded to after running the create, wait-for and destroy commands")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.StateEncryptionKey, "state-encryption-key", "", "passphrase, or awskms://<key-id> naming an AWS KMS key, encrypting the installer state and the auth directory (prefer setting "+command.EnvVarName("state-encryption-key")+" to keep it out of the process list)")
+	cmd.PersistentFlags().StringVar(&command.RootOpts.ProgressFD, "progress-fd", "", "file descriptor number or unix socket path to which progress events are written as newline-delimited JSON")
 	return cmd
 }
 
 func runRootCmd(cmd *cobra.Command, args []string) {
+	envErr := command.BindEnv(cmd.Flags())
+
 	logrus.SetOutput(io.Discard)
 	logrus.SetLevel(logrus.TraceLevel)
 
@@ -80,20 +140,228 @@ func runRootCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		level = logrus.InfoLevel
 	}
+	if clusterOpts.quiet && level > logrus.ErrorLevel {
+		// The install log file still records everything.
+		level = logrus.ErrorLevel
+	}
+
+	formatErr := command.ValidateLogFormat(command.RootOpts.LogFormat)
+	if formatErr != nil {
+		command.RootOpts.LogFormat = command.LogFormatText
+	}
 
-	logrus.AddHook(command.NewFileHookWithNewlineTruncate(os.Stderr, level, &logrus.TextFormatter{
-		// Setting ForceColors is necessary because logrus.TextFormatter determines
-		// whether or not to enable colors by looking at the output of the logger.
-		// In this case, the output is io.Discard, which is not a terminal.
-		// Overriding it here allows the same check to be done, but against the
-		// hook's output instead of the logger's output.
-		ForceColors:            terminal.IsTerminal(int(os.Stderr.Fd())),
-		DisableTimestamp:       true,
-		DisableLevelTruncation: true,
-		DisableQuote:           true,
-	}))
+	color, colorErr := command.UseColor(command.RootOpts.Color, terminal.IsTerminal(int(os.Stderr.Fd())))
 
+	if command.RootOpts.LogFormat == command.LogFormatJSON {
+		// JSON records escape newlines themselves, so there is no need to split messages.
+		logrus.AddHook(command.NewFileHook(os.Stderr, level, command.NewJSONFormatter()))
+	} else {
+		logrus.AddHook(command.NewFileHookWithNewlineTruncate(os.Stderr, level, &logrus.TextFormatter{
+			// Setting ForceColors is necessary because logrus.TextFormatter determines
+			// whether or not to enable colors by looking at the output of the logger.
+			// In this case, the output is io.Discard, which is not a terminal.
+			// Overriding it here allows the same check to be done, but against the
+			// hook's output instead of the logger's output, unless the user chose
+			// explicitly with --color.
+			ForceColors:            color,
+			DisableColors:          !color,
+			DisableTimestamp:       true,
+			DisableLevelTruncation: true,
+			DisableQuote:           true,
+		}))
+	}
+
+	if envErr != nil {
+		logrus.Fatal(errors.Wrap(envErr, "invalid environment"))
+	}
 	if err != nil {
 		logrus.Fatal(errors.Wrap(err, "invalid log-level"))
 	}
+	if formatErr != nil {
+		logrus.Fatal(errors.Wrap(formatErr, "invalid log-format"))
+	}
+	if colorErr != nil {
+		logrus.Fatal(errors.Wrap(colorErr, "invalid color"))
+	}
+
+	if command.RootOpts.LogMaxSize < 0 {
+		logrus.Fatal(errors.Errorf("invalid log-max-size %d, must not be negative", command.RootOpts.LogMaxSize))
+	}
+
+	if command.RootOpts.Timeout < 0 {
+		logrus.Fatal(errors.Errorf("invalid timeout %v, must be positive", command.RootOpts.Timeout))
+	}
+
+	if command.RootOpts.CacheDir != "" {
+		// The image caches resolve their location from the environment.
+		if err := os.Setenv(cachedir.EnvVar, command.RootOpts.CacheDir); err != nil {
+			logrus.Fatal(errors.Wrap(err, "failed to set the cache directory"))
+		}
+	}
+
+	if command.RootOpts.NonInteractive {
+		// Any prompt that slips through reads from an empty, non-terminal
+		// input and fails right away instead of blocking forever.
+		devNull, err := os.Open(os.DevNull)
+		if err != nil {
+			logrus.Fatal(errors.Wrap(err, "failed to disable standard input"))
+		}
+		os.Stdin = devNull
+	}
+
+	installconfig.ValuesFile = command.RootOpts.Values
+	store.ConcurrentGeneration = command.RootOpts.Concurrent
+
+	if command.RootOpts.Reproducible {
+		if err := reproducible.Enable(command.RootOpts.Dir); err != nil {
+			logrus.Fatal(errors.Wrap(err, "invalid reproducible mode"))
+		}
+	}
+
+	if err := statecrypt.SetKey(command.RootOpts.StateEncryptionKey); err != nil {
+		logrus.Fatal(errors.Wrap(err, "invalid state-encryption-key"))
+	}
+
+	if command.RootOpts.StateURL != "" && usesRemoteState(cmd) {
+		if err := pullState(); err != nil {
+			logrus.Fatal(errors.Wrap(err, "failed to download the remote state"))
+		}
+		// Failed runs leave state behind too, e.g. to destroy what was created.
+		logrus.RegisterExitHandler(pushState)
+	}
+
+	if command.RootOpts.Telemetry.Enabled() {
+		logrus.RegisterExitHandler(func() { reportTelemetry(false) })
+	}
+
+	if command.RootOpts.NotifyURL != "" && notifies(cmd) {
+		notification = &pendingNotification{
+			command: cmd.CommandPath(),
+			start:   time.Now(),
+			errors:  &notify.ErrorHook{},
+		}
+		logrus.AddHook(notification.errors)
+		logrus.RegisterExitHandler(func() { sendNotification(false) })
+	}
+
+	if command.RootOpts.ProgressFD != "" {
+		out, err := progress.Open(command.RootOpts.ProgressFD)
+		if err != nil {
+			logrus.Fatal(errors.Wrap(err, "invalid progress-fd"))
+		}
+		progress.SetOutput(out)
+	}
+
+	// Estimate the time left in the stages of the commands run after create
+	// cluster, e.g. wait-for, from the previous runs on the same platform.
+	if metadata, err := cluster.LoadMetadata(command.RootOpts.Dir); err == nil {
+		timer.SetPlatform(metadata.ClusterPlatformMetadata.Platform())
+	}
+}
+
+// usesRemoteState returns whether the command reads or changes the installer
+// state mirrored to --state-url.
+func usesRemoteState(cmd *cobra.Command) bool {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		switch c.Name() {
+		case "create", "wait-for", "destroy":
+			return true
+		}
+	}
+	return false
+}
+
+// stateBackend is the object store the assets directory is mirrored to when
+// --state-url is set.
+var stateBackend remote.Backend
+
+func pullState() error {
+	ctx := context.Background()
+	backend, err := remote.New(ctx, command.RootOpts.StateURL)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(command.RootOpts.Dir, 0750); err != nil {
+		return errors.Wrap(err, "failed to create dir")
+	}
+	if err := remote.Pull(ctx, backend, command.RootOpts.Dir); err != nil {
+		return err
+	}
+	stateBackend = backend
+	return nil
+}
+
+// pushState uploads the state files of the assets directory to the remote
+// state, if any.
+func pushState() {
+	if stateBackend == nil {
+		return
+	}
+	backend := stateBackend
+	// Only push once, even if an exit handler runs after a successful push.
+	stateBackend = nil
+	if err := remote.Push(context.Background(), backend, command.RootOpts.Dir); err != nil {
+		logrus.Errorf("Failed to upload the remote state: %v", err)
+	}
+}
+
+// reportTelemetry sends the recorded install phase timings when telemetry has been opted into.
+func reportTelemetry(success bool) {
+	durations := timer.StageDurations()
+	if !command.RootOpts.Telemetry.Enabled() || len(durations) == 0 {
+		return
+	}
+	if err := telemetry.Send(command.RootOpts.Telemetry, durations, success); err != nil {
+		logrus.Warnf("Failed to report install telemetry: %v", err)
+	}
+}
+
+// pendingNotification is the outcome of the running command, posted to
+// --notify-url when it finishes.
+type pendingNotification struct {
+	command string
+	start   time.Time
+	errors  *notify.ErrorHook
+}
+
+var notification *pendingNotification
+
+// notifies returns whether the outcome of the command is posted to
+// --notify-url, which is the case of the create and wait-for commands.
+func notifies(cmd *cobra.Command) bool {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if c.Name() == "create" || c.Name() == "wait-for" {
+			return true
+		}
+	}
+	return false
+}
+
+// sendNotification posts the outcome of the command to --notify-url, if set.
+func sendNotification(success bool) {
+	if notification == nil {
+		return
+	}
+	pending := notification
+	// Only notify once, even if an exit handler runs after a successful run.
+	notification = nil
+
+	event := notify.Event{
+		Command:         pending.command,
+		Result:          notify.ResultSuccess,
+		DurationSeconds: time.Since(pending.start).Round(time.Second).Seconds(),
+		Timestamp:       time.Now().UTC(),
+	}
+	if !success {
+		event.Result = notify.ResultFailure
+		event.ErrorClass = timer.CurrentStage()
+		event.Error = pending.errors.LastError()
+	}
+	if metadata, err := cluster.LoadMetadata(command.RootOpts.Dir); err == nil {
+		event.ClusterName = metadata.ClusterName
+		event.InfraID = metadata.InfraID
+	}
+	if err := notify.Send(context.Background(), command.RootOpts.NotifyURL, event); err != nil {
+		logrus.Warnf("Failed to send the notification: %v", err)
+	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/plugin"
+)
+
+// assetDir returns the asset directory of the command line and environment.
+// It parses them into a throwaway flag set, because the flags of the commands
+// append to their slices every time they are parsed, before cobra parses them.
+func assetDir(args []string) (string, error) {
+	flags := pflag.NewFlagSet("plugin", pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+	flags.SetOutput(io.Discard)
+	dir := flags.String("dir", ".", "")
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+	if err := command.BindEnv(flags); err != nil {
+		return "", err
+	}
+	return *dir, nil
+}
+
+// runProviderPlugin hands the invocation over to an external provider plugin
+// when a create or destroy command targets a platform which is not supported
+// by the installer itself and a matching plugin is found in the PATH. It only
+// returns when no plugin is responsible for the invocation.
+func runProviderPlugin(rootCmd *cobra.Command, args []string) {
+	cmd, _, err := rootCmd.Find(args)
+	if err != nil || !cmd.HasParent() {
+		return
+	}
+
+	var readPlatform func(string) (string, error)
+	switch cmd.Parent().Name() {
+	case "create":
+		readPlatform = plugin.PlatformFromInstallConfig
+	case "destroy":
+		readPlatform = plugin.PlatformFromMetadata
+	default:
+		return
+	}
+	// the asset directory is only read when there is a plugin to delegate to.
+	if len(plugin.List()) == 0 {
+		return
+	}
+
+	dir, err := assetDir(args)
+	if err != nil {
+		return
+	}
+	platform, err := readPlatform(dir)
+	if err != nil || platform == "" || plugin.IsBuiltin(platform) {
+		return
+	}
+
+	path, err := plugin.Lookup(platform)
+	if err != nil {
+		logrus.Debugf("No provider plugin found for platform %q: %v", platform, err)
+		return
+	}
+
+	logrus.Debugf("Delegating to provider plugin %s", path)
+	if err := plugin.Run(path, args); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		logrus.Fatal(errors.Wrapf(err, "failed to run provider plugin %s", path))
+	}
+	os.Exit(0)
+}
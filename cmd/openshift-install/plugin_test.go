@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/plugin"
+)
+
+func TestRunProviderPluginParsesFlagsOnce(t *testing.T) {
+	pluginDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, plugin.Prefix+"example"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", pluginDir)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "install-config.yaml"), []byte("platform:\n  none: {}\n"), 0600))
+
+	rootCmd := &cobra.Command{Use: "openshift-install"}
+	rootCmd.PersistentFlags().StringVar(&command.RootOpts.Dir, "dir", ".", "")
+	rootCmd.AddCommand(newCreateCmd())
+	var trustBundles []string
+	run, postRun := clusterTarget.command.Run, clusterTarget.command.PostRun
+	clusterTarget.command.Run = func(cmd *cobra.Command, args []string) {
+		trustBundles = createOpts.trustBundles
+	}
+	clusterTarget.command.PostRun = nil
+	defer func() {
+		clusterTarget.command.Run, clusterTarget.command.PostRun = run, postRun
+		createOpts.trustBundles = nil
+	}()
+
+	args := []string{"create", "cluster", "--dir", dir, "--trust-bundle", "a.pem", "--trust-bundle", "b.pem"}
+	runProviderPlugin(rootCmd, args)
+	rootCmd.SetArgs(args)
+	require.NoError(t, rootCmd.Execute())
+	assert.Equal(t, []string{"a.pem", "b.pem"}, trustBundles)
+}
+
+func TestAssetDir(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		env      string
+		expected string
+	}{
+		{
+			name:     "default",
+			args:     []string{"create", "cluster"},
+			expected: ".",
+		},
+		{
+			name:     "flag among unknown flags",
+			args:     []string{"create", "cluster", "--trust-bundle", "ca.pem", "--dir=assets", "--log-level", "debug"},
+			expected: "assets",
+		},
+		{
+			name:     "environment",
+			args:     []string{"destroy", "cluster"},
+			env:      "from-env",
+			expected: "from-env",
+		},
+		{
+			name:     "flag over environment",
+			args:     []string{"destroy", "cluster", "--dir", "assets"},
+			env:      "from-env",
+			expected: "assets",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env != "" {
+				t.Setenv(command.EnvVarName("dir"), tc.env)
+			}
+			dir, err := assetDir(tc.args)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, dir)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+)
+
+func newRenderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "render <asset-name>",
+		Short: "Regenerate a single asset from its parents",
+		Long: `Regenerates exactly one asset from its parents as found in the asset
+directory and the installer state, and writes its files to the asset
+directory. No other asset is generated, written or consumed.
+
+The asset is named either by its Go type, e.g. manifests.Infrastructure,
+or by its display name, e.g. "Worker Ignition Config".`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			names := make([]string, 0)
+			for name := range renderableAssets() {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return command.CompleteValues(names...)(nil, nil, toComplete)
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := runRenderCmd(command.RootOpts.Dir, args[0]); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+}
+
+// renderableAssets returns the writable assets reachable from the create
+// targets, keyed by their Go type name without the pointer prefix.
+func renderableAssets() map[string]asset.WritableAsset {
+	assets := map[string]asset.WritableAsset{}
+	visited := map[reflect.Type]bool{}
+	var visit func(a asset.Asset)
+	visit = func(a asset.Asset) {
+		t := reflect.TypeOf(a)
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		if wa, ok := a.(asset.WritableAsset); ok {
+			assets[strings.TrimPrefix(t.String(), "*")] = wa
+		}
+		for _, d := range a.Dependencies() {
+			visit(d)
+		}
+	}
+	for _, t := range targets {
+		for _, a := range t.assets {
+			visit(a)
+		}
+	}
+	return assets
+}
+
+func findRenderableAsset(name string) (asset.WritableAsset, error) {
+	assets := renderableAssets()
+	if a, ok := assets[name]; ok {
+		return a, nil
+	}
+	for _, a := range assets {
+		if strings.EqualFold(a.Name(), name) {
+			return a, nil
+		}
+	}
+	return nil, errors.Errorf("unknown asset %q", name)
+}
+
+func runRenderCmd(directory, name string) error {
+	a, err := findRenderableAsset(name)
+	if err != nil {
+		return err
+	}
+
+	assetStore, err := assetstore.NewStore(directory)
+	if err != nil {
+		return errors.Wrap(err, "failed to create asset store")
+	}
+	parents := asset.Parents{}
+	for _, d := range a.Dependencies() {
+		parent, err := assetStore.Load(d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load %s", d.Name())
+		}
+		if parent == nil {
+			return errors.Errorf("%s has not been generated yet, create the target it belongs to first", d.Name())
+		}
+		parents.Add(parent)
+	}
+
+	if err := a.Generate(parents); err != nil {
+		return errors.Wrapf(err, "failed to generate %s", a.Name())
+	}
+	if err := asFileWriter(a).PersistToFile(directory); err != nil {
+		return errors.Wrapf(err, "failed to write asset (%s) to disk", a.Name())
+	}
+	for _, f := range a.Files() {
+		logrus.Infof("Rendered %s", f.Filename)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+	"github.com/openshift/installer/pkg/asset/tls"
+)
+
+func TestFindRenderableAsset(t *testing.T) {
+	cases := []struct {
+		name        string
+		assetName   string
+		expectedErr string
+	}{
+		{
+			name:      "Go type name",
+			assetName: "tls.KubeAPIServerLocalhostServerCertKey",
+		},
+		{
+			name:      "display name",
+			assetName: "Certificate (kube-apiserver-localhost-server)",
+		},
+		{
+			name:      "case-insensitive display name",
+			assetName: "certificate (KUBE-APISERVER-LOCALHOST-SERVER)",
+		},
+		{
+			name:        "unknown name",
+			assetName:   "tls.UnknownCertKey",
+			expectedErr: `^unknown asset "tls.UnknownCertKey"$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := findRenderableAsset(tc.assetName)
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, &tls.KubeAPIServerLocalhostServerCertKey{}, a)
+		})
+	}
+}
+
+func TestRunRenderCmdMissingParent(t *testing.T) {
+	err := runRenderCmd(t.TempDir(), "tls.KubeAPIServerLocalhostServerCertKey")
+	assert.Regexp(t, `^Certificate \(kube-apiserver-localhost-signer\) has not been generated yet`, err)
+}
+
+func TestRunRenderCmd(t *testing.T) {
+	dir := t.TempDir()
+	assetStore, err := assetstore.NewStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, assetStore.Fetch(&tls.KubeAPIServerLocalhostSignerCertKey{}))
+	stateFile := filepath.Join(dir, ".openshift_install_state.json")
+	state, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+
+	require.NoError(t, runRenderCmd(dir, "tls.KubeAPIServerLocalhostServerCertKey"))
+
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, rel)
+		return err
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		".openshift_install_state.json",
+		"tls/kube-apiserver-localhost-server.crt",
+		"tls/kube-apiserver-localhost-server.key",
+	}, files)
+
+	renderedState, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(state), string(renderedState), "the state file is left unchanged")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/installer/cmd/openshift-install/command"
+	"github.com/openshift/installer/pkg/asset/cluster"
+	awsdestroy "github.com/openshift/installer/pkg/destroy/aws"
+)
+
+var destroyScanOpts struct {
+	infraIDs   []string
+	all        bool
+	namePrefix string
+	regions    []string
+	knownDirs  []string
+	delete     bool
+}
+
+func newDestroyScanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan",
+		Short: "Search an AWS account for the resources of clusters without metadata",
+		Long: `Search an AWS account, across regions, for the resources owned by
+clusters whose assets directory was lost, and optionally delete them.
+
+The clusters with a metadata.json in the assets directory, or in one of the
+known directories, are not reported. Deleting asks for a confirmation, and
+requires --name-prefix along with --all.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := runDestroyScanCmd(context.Background(), os.Stdout); err != nil {
+				logrus.Fatal(err)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&destroyScanOpts.infraIDs, "infra-id", nil, "comma-separated infra IDs of the clusters to search for")
+	cmd.Flags().BoolVar(&destroyScanOpts.all, "all", false, "search for every cluster whose infra ID starts with --name-prefix")
+	cmd.Flags().StringVar(&destroyScanOpts.namePrefix, "name-prefix", "", "prefix of the infra IDs of the clusters searched for with --all")
+	cmd.Flags().StringSliceVar(&destroyScanOpts.regions, "region", nil, "comma-separated regions to search, all the regions enabled in the account by default")
+	cmd.Flags().StringSliceVar(&destroyScanOpts.knownDirs, "known-dir", nil, "comma-separated assets directories of clusters which are not orphaned")
+	cmd.Flags().BoolVar(&destroyScanOpts.delete, "delete", false, "delete the resources of the orphaned clusters")
+	return cmd
+}
+
+func runDestroyScanCmd(ctx context.Context, out io.Writer) error {
+	if (len(destroyScanOpts.infraIDs) == 0) == !destroyScanOpts.all {
+		return errors.New("exactly one of --infra-id and --all is required")
+	}
+	if destroyScanOpts.namePrefix != "" && !destroyScanOpts.all {
+		return errors.New("--name-prefix is only used with --all")
+	}
+	if destroyScanOpts.delete && destroyScanOpts.all && destroyScanOpts.namePrefix == "" {
+		return errors.New("--delete with --all requires --name-prefix, to never delete every cluster of the account")
+	}
+
+	known := sets.NewString()
+	for _, dir := range append([]string{command.RootOpts.Dir}, destroyScanOpts.knownDirs...) {
+		metadata, err := cluster.LoadMetadata(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.Warnf("Failed to load the metadata in %s: %v", dir, err)
+			}
+			continue
+		}
+		known.Insert(metadata.InfraID)
+	}
+
+	orphaned, err := awsdestroy.Scan(ctx, logrus.StandardLogger(), awsdestroy.ScanOptions{
+		InfraIDs:      destroyScanOpts.infraIDs,
+		NamePrefix:    destroyScanOpts.namePrefix,
+		Regions:       destroyScanOpts.regions,
+		KnownInfraIDs: known,
+	})
+	if err != nil {
+		// Report what was found in the regions which could be scanned.
+		logrus.Warn(errors.Wrap(err, "failed to scan some regions"))
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "INFRA ID\tREGIONS\tRESOURCES")
+	for _, c := range orphaned {
+		regions := "global"
+		if len(c.Regions) > 0 {
+			regions = strings.Join(c.Regions, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", c.InfraID, regions, len(c.Resources))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	logrus.Infof("Found %d orphaned clusters", len(orphaned))
+
+	if !destroyScanOpts.delete || len(orphaned) == 0 {
+		return nil
+	}
+	confirmed := false
+	if err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Delete the resources of the %d orphaned clusters listed above?", len(orphaned)),
+	}, &confirmed); err != nil {
+		return errors.Wrap(err, "failed to confirm the deletion")
+	}
+	if !confirmed {
+		logrus.Info("Not deleting the orphaned clusters")
+		return nil
+	}
+	for _, c := range orphaned {
+		logrus.Infof("Destroying %s", c.InfraID)
+		if err := c.Destroy(logrus.WithField("infraID", c.InfraID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,17 +2,84 @@ package main
 
 import (
 	"context"
-	"path/filepath"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/tools/clientcmd"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	configlisters "github.com/openshift/client-go/config/listers/config/v1"
 	"github.com/openshift/installer/cmd/openshift-install/command"
+	destroybootstrap "github.com/openshift/installer/pkg/destroy/bootstrap"
+	"github.com/openshift/installer/pkg/metrics/progress"
 	timer "github.com/openshift/installer/pkg/metrics/timer"
+	"github.com/openshift/installer/pkg/poll"
+	cov1helpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
 )
 
+const (
+	waitForOutputText = "text"
+	waitForOutputJSON = "json"
+
+	waitForStateBootstrapComplete  = "bootstrap-complete"
+	waitForStateInstallComplete    = "install-complete"
+	waitForStateOperatorsAvailable = "cluster-operators-available"
+	waitForStateFailed             = "failed"
+	waitForStateTimedOut           = "timed-out"
+)
+
+var waitForOpts struct {
+	output string
+}
+
+var waitForBootstrapCompleteOpts struct {
+	destroyBootstrap bool
+}
+
+// destroyBootstrapResources destroys the bootstrap resources of the cluster
+// of the assets directory.
+var destroyBootstrapResources = destroybootstrap.Destroy
+
+var waitForInstallCompleteOpts struct {
+	watch bool
+}
+
+var waitForClusterOperatorsOpts struct {
+	operators []string
+}
+
+// waitForStatus is the document printed by the wait-for commands with
+// --output json once they complete or fail.
+type waitForStatus struct {
+	State            string            `json:"state"`
+	Error            string            `json:"error,omitempty"`
+	ConsoleURL       string            `json:"consoleURL,omitempty"`
+	ElapsedSeconds   float64           `json:"elapsedSeconds"`
+	FailingOperators []failingOperator `json:"failingOperators"`
+}
+
+// failingOperator is a cluster operator which is not Available or is
+// Degraded.
+type failingOperator struct {
+	Name       string                                    `json:"name"`
+	Conditions []configv1.ClusterOperatorStatusCondition `json:"conditions"`
+}
+
 func newWaitForCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "wait-for",
@@ -28,27 +95,42 @@ them directly.`,
 	}
 	cmd.AddCommand(newWaitForBootstrapCompleteCmd())
 	cmd.AddCommand(newWaitForInstallCompleteCmd())
+	cmd.AddCommand(newWaitForClusterOperatorsCmd())
+	cmd.AddCommand(newWaitForCSRApprovalCmd())
+	cmd.AddCommand(newWaitForMachineProvisioningCmd())
+	command.AddPollFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().StringVarP(&waitForOpts.output, "output", "o", waitForOutputText, fmt.Sprintf("output format (%s or %s), %s prints the install state, the failing operators, the console URL and the elapsed time to stdout once the wait ends", waitForOutputText, waitForOutputJSON, waitForOutputJSON))
+	if err := cmd.RegisterFlagCompletionFunc("output", command.CompleteValues(waitForOutputText, waitForOutputJSON)); err != nil {
+		logrus.Debugf("Failed to register completion for flag output: %v", err)
+	}
 	return cmd
 }
 
 func newWaitForBootstrapCompleteCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "bootstrap-complete",
 		Short: "Wait until cluster bootstrapping has completed",
 		Args:  cobra.ExactArgs(0),
 		Run: func(_ *cobra.Command, _ []string) {
+			start := time.Now()
 			timer.StartTimer(timer.TotalTimeElapsed)
 			ctx := context.Background()
 
 			cleanup := command.SetupFileHook(command.RootOpts.Dir)
 			defer cleanup()
 
-			config, err := clientcmd.BuildConfigFromFlags("", filepath.Join(command.RootOpts.Dir, "auth", "kubeconfig"))
+			if err := validateWaitForOutput(); err != nil {
+				logrus.Fatal(err)
+			}
+			config, err := loadKubeconfig(command.RootOpts.Dir)
 			if err != nil {
 				logrus.Fatal(errors.Wrap(err, "loading kubeconfig"))
 			}
+			waitCtx, cancelWait := waitContext(ctx)
+			defer cancelWait()
+
 			timer.StartTimer("Bootstrap Complete")
-			if err := waitForBootstrapComplete(ctx, config); err != nil {
+			if err := waitForBootstrapComplete(waitCtx, config); err != nil {
 				if err2 := logClusterOperatorConditions(ctx, config); err2 != nil {
 					logrus.Error("Attempted to gather ClusterOperator status after wait failure: ", err2)
 				}
@@ -57,45 +139,392 @@ func newWaitForBootstrapCompleteCmd() *cobra.Command {
 				logrus.Info("openshift-install gather bootstrap --help")
 				logrus.Error("Bootstrap failed to complete: ", err.Unwrap())
 				logrus.Error(err.Error())
+				printWaitForStatus(ctx, config, start, "", "", err)
 				logrus.Exit(exitCodeBootstrapFailed)
 			}
 
-			logrus.Info("It is now safe to remove the bootstrap resources")
 			timer.StopTimer("Bootstrap Complete")
+			progress.Emit(progress.BootstrapComplete, "")
+			if err := finishBootstrap(command.RootOpts.Dir, waitForBootstrapCompleteOpts.destroyBootstrap); err != nil {
+				logrus.Fatal(err)
+			}
 			timer.StopTimer(timer.TotalTimeElapsed)
 			timer.LogSummary()
+			printWaitForStatus(ctx, config, start, waitForStateBootstrapComplete, "", nil)
 		},
 	}
+	cmd.Flags().BoolVar(&waitForBootstrapCompleteOpts.destroyBootstrap, "destroy-bootstrap", false, "destroy the bootstrap resources created by the installer once bootstrapping completes")
+	return cmd
+}
+
+// finishBootstrap destroys the bootstrap resources once bootstrapping
+// completes when destroy is set, and otherwise tells they can be removed.
+func finishBootstrap(directory string, destroy bool) error {
+	if !destroy {
+		logrus.Info("It is now safe to remove the bootstrap resources")
+		return nil
+	}
+	timer.StartTimer("Bootstrap Destroy")
+	logrus.Info("Destroying the bootstrap resources...")
+	if err := destroyBootstrapResources(directory); err != nil {
+		return err
+	}
+	timer.StopTimer("Bootstrap Destroy")
+	return nil
 }
 
 func newWaitForInstallCompleteCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "install-complete",
 		Short: "Wait until the cluster is ready",
 		Args:  cobra.ExactArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
+			start := time.Now()
 			timer.StartTimer(timer.TotalTimeElapsed)
 			ctx := context.Background()
 
 			cleanup := command.SetupFileHook(command.RootOpts.Dir)
 			defer cleanup()
 
-			config, err := clientcmd.BuildConfigFromFlags("", filepath.Join(command.RootOpts.Dir, "auth", "kubeconfig"))
+			if err := validateWaitForOutput(); err != nil {
+				logrus.Fatal(err)
+			}
+			config, err := loadKubeconfig(command.RootOpts.Dir)
 			if err != nil {
 				logrus.Fatal(errors.Wrap(err, "loading kubeconfig"))
 			}
 
-			err = waitForInstallComplete(ctx, config, command.RootOpts.Dir)
+			if waitForInstallCompleteOpts.watch {
+				if waitForOpts.output == waitForOutputJSON {
+					logrus.Fatalf("--watch cannot be used with --output %s", waitForOutputJSON)
+				}
+				watchCtx, cancel := context.WithCancel(ctx)
+				defer cancel()
+				if err := watchInstallProgress(watchCtx, config, os.Stdout); err != nil {
+					logrus.Fatal(err)
+				}
+			}
+
+			waitCtx, cancelWait := waitContext(ctx)
+			defer cancelWait()
+
+			consoleURL, err := waitForInstallComplete(waitCtx, config, command.RootOpts.Dir)
 			if err != nil {
 				if err2 := logClusterOperatorConditions(ctx, config); err2 != nil {
 					logrus.Error("Attempted to gather ClusterOperator status after wait failure: ", err2)
 				}
+				if installCompleteOpts.gatherOnFailure {
+					gatherCluster(ctx, config, command.RootOpts.Dir)
+				}
 				logTroubleshootingLink()
 				logrus.Error(err)
-				logrus.Exit(exitCodeInstallFailed)
+				printWaitForStatus(ctx, config, start, "", "", err)
+				logrus.Exit(installExitCode(err))
 			}
 			timer.StopTimer(timer.TotalTimeElapsed)
 			timer.LogSummary()
+			printWaitForStatus(ctx, config, start, waitForStateInstallComplete, consoleURL, nil)
 		},
 	}
+	cmd.Flags().BoolVar(&installCompleteOpts.verifyEndpoints, "verify-endpoints", false, "probe the console and the ingress canary over HTTPS, through the cluster proxy if any, before declaring the install complete")
+	cmd.Flags().BoolVar(&installCompleteOpts.gatherOnFailure, "gather-on-failure", false, "gather the cluster operators, the warning events and the pod logs of the failing operators through the API into the assets directory when the install fails to complete")
+	cmd.Flags().BoolVar(&waitForInstallCompleteOpts.watch, "watch", false, "print the cluster version progress and a table of the cluster operator status transitions to stdout while waiting")
+	return cmd
+}
+
+func newWaitForClusterOperatorsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster-operators",
+		Short: "Wait until the named cluster operators are available and not degraded",
+		Long: `Wait until the named cluster operators are available and not degraded.
+
+This is useful when only some operators are needed to proceed, e.g. the
+ingress and authentication operators to log in to the console, without
+waiting for the whole installation to complete.`,
+		Args: cobra.ExactArgs(0),
+		Run: func(_ *cobra.Command, _ []string) {
+			start := time.Now()
+			ctx := context.Background()
+
+			cleanup := command.SetupFileHook(command.RootOpts.Dir)
+			defer cleanup()
+
+			if err := validateWaitForOutput(); err != nil {
+				logrus.Fatal(err)
+			}
+			config, err := loadKubeconfig(command.RootOpts.Dir)
+			if err != nil {
+				logrus.Fatal(errors.Wrap(err, "loading kubeconfig"))
+			}
+
+			waitCtx, cancelWait := waitContext(ctx)
+			defer cancelWait()
+
+			if err := waitForClusterOperators(waitCtx, config, waitForClusterOperatorsOpts.operators); err != nil {
+				logrus.Error(err)
+				printWaitForStatus(ctx, config, start, "", "", err)
+				logrus.Exit(exitCodeOperatorStabilityFailed)
+			}
+			printWaitForStatus(ctx, config, start, waitForStateOperatorsAvailable, "", nil)
+		},
+	}
+	cmd.Flags().StringSliceVar(&waitForClusterOperatorsOpts.operators, "operators", nil, "comma-separated names of the cluster operators to wait for (e.g. \"ingress,authentication\")")
+	if err := cmd.MarkFlagRequired("operators"); err != nil {
+		logrus.Debugf("Failed to mark flag operators as required: %v", err)
+	}
+	return cmd
+}
+
+// waitForClusterOperators waits until each named cluster operator is
+// Available and not Degraded, logging the progress of each operator as its
+// status changes.
+func waitForClusterOperators(ctx context.Context, config *rest.Config, names []string) error {
+	timeout := stageTimeout(ctx, 30*time.Minute)
+	// the informers are bound by the timeout too, in case the API is
+	// unreachable.
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	untilTime := time.Now().Add(timeout)
+	timezone, _ := untilTime.Zone()
+	logrus.Infof("Waiting up to %v (until %v %s) for the cluster operators %s...",
+		timeout, untilTime.Format(time.Kitchen), timezone, strings.Join(names, ", "))
+
+	cc, err := configclient.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a config client")
+	}
+	configInformers := configinformers.NewSharedInformerFactory(cc, 0)
+	clusterOperatorInformer := configInformers.Config().V1().ClusterOperators().Informer()
+	clusterOperatorLister := configInformers.Config().V1().ClusterOperators().Lister()
+	configInformers.Start(waitCtx.Done())
+	if !cache.WaitForCacheSync(waitCtx.Done(), clusterOperatorInformer.HasSynced) {
+		return fmt.Errorf("informers never started")
+	}
+
+	statuses := map[string]string{}
+	waitErr := poll.Until(waitCtx, 1*time.Second, func(ctx context.Context) (bool, error) {
+		done := true
+		for _, name := range names {
+			ready, status, err := clusterOperatorStatus(clusterOperatorLister, name)
+			if err != nil {
+				return false, err
+			}
+			if status != statuses[name] {
+				logrus.Infof("Cluster operator %s %s", name, status)
+				statuses[name] = status
+			}
+			done = done && ready
+		}
+		return done, nil
+	})
+	if waitErr == nil {
+		return nil
+	}
+
+	var waiting []string
+	for _, name := range names {
+		if ready, _, _ := clusterOperatorStatus(clusterOperatorLister, name); !ready {
+			waiting = append(waiting, name)
+		}
+	}
+	if len(waiting) == 0 {
+		return errors.Wrap(waitErr, "failed to wait for the cluster operators")
+	}
+	return errors.Errorf("cluster operators %s did not become available in time: %s", strings.Join(waiting, ", "), waitErr)
+}
+
+// clusterOperatorStatus returns whether the named cluster operator is
+// Available and not Degraded, and describes its status.
+func clusterOperatorStatus(lister configlisters.ClusterOperatorLister, name string) (bool, string, error) {
+	operator, err := lister.Get(name)
+	if apierrors.IsNotFound(err) {
+		return false, "does not exist yet", nil
+	} else if err != nil {
+		return false, "", err
+	}
+
+	conditions := operator.Status.Conditions
+	if degraded := cov1helpers.FindStatusCondition(conditions, configv1.OperatorDegraded); degraded != nil && degraded.Status == configv1.ConditionTrue {
+		return false, fmt.Sprintf("is degraded with %s: %s", degraded.Reason, degraded.Message), nil
+	}
+	available := cov1helpers.FindStatusCondition(conditions, configv1.OperatorAvailable)
+	if available == nil {
+		return false, "has not reported its availability yet", nil
+	}
+	if available.Status != configv1.ConditionTrue {
+		return false, fmt.Sprintf("is not available with %s: %s", available.Reason, available.Message), nil
+	}
+	return true, "is available", nil
+}
+
+func validateWaitForOutput() error {
+	if waitForOpts.output != waitForOutputText && waitForOpts.output != waitForOutputJSON {
+		return errors.Errorf("invalid output format %q, must be %s or %s", waitForOpts.output, waitForOutputText, waitForOutputJSON)
+	}
+	return nil
+}
+
+// printWaitForStatus prints the waitForStatus document to stdout when the
+// output format is JSON. The state is replaced by failed, or timed-out, when
+// the wait ended with an error.
+func printWaitForStatus(ctx context.Context, config *rest.Config, start time.Time, state, consoleURL string, waitErr error) {
+	if waitForOpts.output != waitForOutputJSON {
+		return
+	}
+
+	status := newWaitForStatus(time.Since(start), state, consoleURL, waitErr)
+	operators, err := failingClusterOperators(ctx, config)
+	if err != nil {
+		logrus.Warnf("Failed to list the failing cluster operators: %v", err)
+	}
+	status.FailingOperators = operators
+
+	if err := printJSON(status); err != nil {
+		logrus.Error(errors.Wrap(err, "failed to print the status"))
+	}
+}
+
+// newWaitForStatus returns the waitForStatus document of the wait which
+// reached the state, or which ended with the error, after the elapsed time.
+func newWaitForStatus(elapsed time.Duration, state, consoleURL string, waitErr error) waitForStatus {
+	status := waitForStatus{
+		State:            state,
+		ConsoleURL:       consoleURL,
+		ElapsedSeconds:   elapsed.Round(time.Second).Seconds(),
+		FailingOperators: []failingOperator{},
+	}
+	if waitErr != nil {
+		status.State = waitForStateFailed
+		if wait.Interrupted(waitErr) {
+			status.State = waitForStateTimedOut
+		}
+		status.Error = waitErr.Error()
+		var createErr *clusterCreateError
+		if errors.As(waitErr, &createErr) && createErr.Unwrap() != nil {
+			status.Error = fmt.Sprintf("%v: %s", createErr.Unwrap(), createErr.Error())
+		}
+	}
+	return status
+}
+
+// failingClusterOperators returns the cluster operators which are not
+// Available or are Degraded, with their conditions.
+func failingClusterOperators(ctx context.Context, config *rest.Config) ([]failingOperator, error) {
+	failing := []failingOperator{}
+	client, err := configclient.NewForConfig(config)
+	if err != nil {
+		return failing, errors.Wrap(err, "creating a config client")
+	}
+	operators, err := client.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return failing, errors.Wrap(err, "listing ClusterOperator objects")
+	}
+	return failingOperators(operators.Items), nil
+}
+
+// failingOperators returns the cluster operators which are not Available or
+// are Degraded, with their conditions.
+func failingOperators(operators []configv1.ClusterOperator) []failingOperator {
+	failing := []failingOperator{}
+	for _, operator := range operators {
+		conditions := operator.Status.Conditions
+		if cov1helpers.IsStatusConditionTrue(conditions, configv1.OperatorAvailable) &&
+			!cov1helpers.IsStatusConditionTrue(conditions, configv1.OperatorDegraded) {
+			continue
+		}
+		failing = append(failing, failingOperator{Name: operator.Name, Conditions: conditions})
+	}
+	return failing
+}
+
+// installProgressWatcher prints the changes of the cluster version progress
+// and of the status of the cluster operators.
+type installProgressWatcher struct {
+	out                   io.Writer
+	clusterVersionLister  configlisters.ClusterVersionLister
+	clusterOperatorLister configlisters.ClusterOperatorLister
+	lastProgress          string
+	lastStatuses          map[string]string
+}
+
+// watchInstallProgress prints to out, until the context is done, the cluster
+// version progress and a table of the cluster operators whose status changed
+// since the previous table.
+func watchInstallProgress(ctx context.Context, config *rest.Config, out io.Writer) error {
+	cc, err := configclient.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a config client")
+	}
+	configInformers := configinformers.NewSharedInformerFactory(cc, 0)
+	watcher := &installProgressWatcher{
+		out:                   out,
+		clusterVersionLister:  configInformers.Config().V1().ClusterVersions().Lister(),
+		clusterOperatorLister: configInformers.Config().V1().ClusterOperators().Lister(),
+		lastStatuses:          map[string]string{},
+	}
+	configInformers.Start(ctx.Done())
+
+	go func() {
+		configInformers.WaitForCacheSync(ctx.Done())
+		wait.Until(watcher.render, 5*time.Second, ctx.Done())
+	}()
+	return nil
+}
+
+func (w *installProgressWatcher) render() {
+	now := time.Now().Format("15:04:05")
+
+	if cv, err := w.clusterVersionLister.Get("version"); err == nil {
+		progress := "unknown"
+		if progressing := cov1helpers.FindStatusCondition(cv.Status.Conditions, configv1.OperatorProgressing); progressing != nil && progressing.Message != "" {
+			progress = progressing.Message
+		}
+		if progress != w.lastProgress {
+			fmt.Fprintf(w.out, "%s Cluster version: %s\n", now, progress)
+			w.lastProgress = progress
+		}
+	}
+
+	operators, err := w.clusterOperatorLister.List(labels.Everything())
+	if err != nil {
+		return
+	}
+	sort.Slice(operators, func(i, j int) bool { return operators[i].Name < operators[j].Name })
+
+	tw := tabwriter.NewWriter(w.out, 0, 8, 2, ' ', 0)
+	changed := false
+	for _, operator := range operators {
+		conditions := operator.Status.Conditions
+		status := fmt.Sprintf("%s\t%s\t%s",
+			conditionStatus(conditions, configv1.OperatorAvailable),
+			conditionStatus(conditions, configv1.OperatorProgressing),
+			conditionStatus(conditions, configv1.OperatorDegraded))
+		if status == w.lastStatuses[operator.Name] {
+			continue
+		}
+		w.lastStatuses[operator.Name] = status
+		if !changed {
+			fmt.Fprintf(tw, "%s NAME\tAVAILABLE\tPROGRESSING\tDEGRADED\tMESSAGE\n", now)
+			changed = true
+		}
+		message := ""
+		for _, conditionType := range []configv1.ClusterStatusConditionType{configv1.OperatorDegraded, configv1.OperatorProgressing} {
+			if condition := cov1helpers.FindStatusCondition(conditions, conditionType); condition != nil && condition.Status == configv1.ConditionTrue {
+				message = condition.Message
+				break
+			}
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\n", now, operator.Name, status, message)
+	}
+	tw.Flush()
+}
+
+// conditionStatus returns the status of the condition, or Unknown when the
+// condition is not reported.
+func conditionStatus(conditions []configv1.ClusterOperatorStatusCondition, conditionType configv1.ClusterStatusConditionType) configv1.ConditionStatus {
+	if condition := cov1helpers.FindStatusCondition(conditions, conditionType); condition != nil {
+		return condition.Status
+	}
+	return configv1.ConditionUnknown
 }
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configlisters "github.com/openshift/client-go/config/listers/config/v1"
+)
+
+// clusterOperatorLister returns a lister of the cluster operators.
+func clusterOperatorLister(t *testing.T, operators ...*configv1.ClusterOperator) configlisters.ClusterOperatorLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, operator := range operators {
+		require.NoError(t, indexer.Add(operator))
+	}
+	return configlisters.NewClusterOperatorLister(indexer)
+}
+
+// clusterOperator returns a cluster operator with the conditions.
+func clusterOperator(name string, conditions ...configv1.ClusterOperatorStatusCondition) *configv1.ClusterOperator {
+	return &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     configv1.ClusterOperatorStatus{Conditions: conditions},
+	}
+}
+
+func TestClusterOperatorStatus(t *testing.T) {
+	available := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue}
+	cases := []struct {
+		name           string
+		operator       *configv1.ClusterOperator
+		expectedReady  bool
+		expectedStatus string
+	}{
+		{
+			name:           "missing",
+			expectedStatus: "does not exist yet",
+		},
+		{
+			name:           "available",
+			operator:       clusterOperator("ingress", available),
+			expectedReady:  true,
+			expectedStatus: "is available",
+		},
+		{
+			name:           "no availability",
+			operator:       clusterOperator("ingress"),
+			expectedStatus: "has not reported its availability yet",
+		},
+		{
+			name: "not available",
+			operator: clusterOperator("ingress", configv1.ClusterOperatorStatusCondition{
+				Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse, Reason: "NoRoutes", Message: "no routes admitted",
+			}),
+			expectedStatus: "is not available with NoRoutes: no routes admitted",
+		},
+		{
+			name: "available but degraded",
+			operator: clusterOperator("ingress", available, configv1.ClusterOperatorStatusCondition{
+				Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, Reason: "PodsCrashing", Message: "router pods are crashing",
+			}),
+			expectedStatus: "is degraded with PodsCrashing: router pods are crashing",
+		},
+		{
+			name: "available and not degraded",
+			operator: clusterOperator("ingress", available, configv1.ClusterOperatorStatusCondition{
+				Type: configv1.OperatorDegraded, Status: configv1.ConditionFalse,
+			}),
+			expectedReady:  true,
+			expectedStatus: "is available",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var operators []*configv1.ClusterOperator
+			if tc.operator != nil {
+				operators = append(operators, tc.operator)
+			}
+			ready, status, err := clusterOperatorStatus(clusterOperatorLister(t, operators...), "ingress")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedReady, ready)
+			assert.Equal(t, tc.expectedStatus, status)
+		})
+	}
+}
+
+func TestNewWaitForStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		waitErr  error
+		expected waitForStatus
+	}{
+		{
+			name: "complete",
+			expected: waitForStatus{
+				State:            waitForStateInstallComplete,
+				ConsoleURL:       "https://console.example.com",
+				ElapsedSeconds:   90,
+				FailingOperators: []failingOperator{},
+			},
+		},
+		{
+			name:    "failed",
+			waitErr: errors.New("cluster operators are degraded"),
+			expected: waitForStatus{
+				State:            waitForStateFailed,
+				Error:            "cluster operators are degraded",
+				ConsoleURL:       "https://console.example.com",
+				ElapsedSeconds:   90,
+				FailingOperators: []failingOperator{},
+			},
+		},
+		{
+			name:    "timed out",
+			waitErr: wait.ErrWaitTimeout,
+			expected: waitForStatus{
+				State:            waitForStateTimedOut,
+				Error:            wait.ErrWaitTimeout.Error(),
+				ConsoleURL:       "https://console.example.com",
+				ElapsedSeconds:   90,
+				FailingOperators: []failingOperator{},
+			},
+		},
+		{
+			name:    "failed with a log message",
+			waitErr: newAPIError(errors.New("connection refused")),
+			expected: waitForStatus{
+				State:            waitForStateFailed,
+				Error:            "connection refused: " + newAPIError(nil).Error(),
+				ConsoleURL:       "https://console.example.com",
+				ElapsedSeconds:   90,
+				FailingOperators: []failingOperator{},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := newWaitForStatus(90*time.Second+400*time.Millisecond, waitForStateInstallComplete, "https://console.example.com", tc.waitErr)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+}
+
+func TestFailingOperators(t *testing.T) {
+	available := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue}
+	notAvailable := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse}
+	degraded := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue}
+	cases := []struct {
+		name      string
+		operators []configv1.ClusterOperator
+		expected  []failingOperator
+	}{
+		{
+			name:     "no operators",
+			expected: []failingOperator{},
+		},
+		{
+			name:      "available operators",
+			operators: []configv1.ClusterOperator{*clusterOperator("dns", available), *clusterOperator("ingress", available)},
+			expected:  []failingOperator{},
+		},
+		{
+			name: "not available, degraded and unreported operators",
+			operators: []configv1.ClusterOperator{
+				*clusterOperator("dns", available),
+				*clusterOperator("ingress", notAvailable),
+				*clusterOperator("network", available, degraded),
+				*clusterOperator("storage"),
+			},
+			expected: []failingOperator{
+				{Name: "ingress", Conditions: []configv1.ClusterOperatorStatusCondition{notAvailable}},
+				{Name: "network", Conditions: []configv1.ClusterOperatorStatusCondition{available, degraded}},
+				{Name: "storage"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, failingOperators(tc.operators))
+		})
+	}
+}
+
+func TestInstallExitCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "unstable operators",
+			err:      &unstableOperatorsError{operators: []string{"ingress"}},
+			expected: exitCodeOperatorStabilityFailed,
+		},
+		{
+			name:     "wrapped unstable operators",
+			err:      errors.Wrap(&unstableOperatorsError{operators: []string{"ingress"}}, "failed to wait"),
+			expected: exitCodeOperatorStabilityFailed,
+		},
+		{
+			name:     "other failure",
+			err:      errors.New("failed to initialize the cluster"),
+			expected: exitCodeInstallFailed,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, installExitCode(tc.err))
+		})
+	}
+}
+
+func TestValidateWaitForOutput(t *testing.T) {
+	defer func(output string) { waitForOpts.output = output }(waitForOpts.output)
+	cases := []struct {
+		output      string
+		expectedErr string
+	}{
+		{output: waitForOutputText},
+		{output: waitForOutputJSON},
+		{output: "yaml", expectedErr: `invalid output format "yaml", must be text or json`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.output, func(t *testing.T) {
+			waitForOpts.output = tc.output
+			err := validateWaitForOutput()
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestInstallProgressWatcherRender(t *testing.T) {
+	available := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue}
+	notAvailable := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionFalse}
+	progressing := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorProgressing, Status: configv1.ConditionTrue, Message: "rolling out"}
+	notProgressing := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorProgressing, Status: configv1.ConditionFalse}
+	degraded := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, Message: "pods crashing"}
+	notDegraded := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorDegraded, Status: configv1.ConditionFalse}
+
+	// The renders of the watcher, which prints the changes since the
+	// previous render.
+	renders := []struct {
+		name           string
+		progress       string
+		operators      []*configv1.ClusterOperator
+		expectedOutput string
+	}{
+		{
+			name:     "first render",
+			progress: "Working towards 4.14.0: 100 of 800 done (12% complete)",
+			operators: []*configv1.ClusterOperator{
+				clusterOperator("ingress", notAvailable, progressing, notDegraded),
+				clusterOperator("dns", available, notProgressing, notDegraded),
+			},
+			expectedOutput: `Cluster version: Working towards 4.14.0: 100 of 800 done (12% complete)
+NAME     AVAILABLE  PROGRESSING  DEGRADED  MESSAGE
+dns      True       False        False
+ingress  False      True         False     rolling out
+`,
+		},
+		{
+			name:     "nothing changed",
+			progress: "Working towards 4.14.0: 100 of 800 done (12% complete)",
+			operators: []*configv1.ClusterOperator{
+				clusterOperator("ingress", notAvailable, progressing, notDegraded),
+				clusterOperator("dns", available, notProgressing, notDegraded),
+			},
+		},
+		{
+			name:     "operator changed",
+			progress: "Working towards 4.14.0: 100 of 800 done (12% complete)",
+			operators: []*configv1.ClusterOperator{
+				clusterOperator("ingress", notAvailable, progressing, degraded),
+				clusterOperator("dns", available, notProgressing, notDegraded),
+				clusterOperator("network"),
+			},
+			expectedOutput: `NAME     AVAILABLE  PROGRESSING  DEGRADED  MESSAGE
+ingress  False      True         True      pods crashing
+network  Unknown    Unknown      Unknown
+`,
+		},
+		{
+			name:     "progress changed",
+			progress: "Working towards 4.14.0: 700 of 800 done (87% complete)",
+			operators: []*configv1.ClusterOperator{
+				clusterOperator("ingress", notAvailable, progressing, degraded),
+				clusterOperator("dns", available, notProgressing, notDegraded),
+				clusterOperator("network"),
+			},
+			expectedOutput: "Cluster version: Working towards 4.14.0: 700 of 800 done (87% complete)\n",
+		},
+	}
+
+	out := &bytes.Buffer{}
+	watcher := &installProgressWatcher{out: out, lastStatuses: map[string]string{}}
+	timestamp := regexp.MustCompile(`(?m)^\d\d:\d\d:\d\d `)
+	trailingSpaces := regexp.MustCompile(`(?m) +$`)
+	for _, render := range renders {
+		t.Run(render.name, func(t *testing.T) {
+			versions := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			require.NoError(t, versions.Add(&configv1.ClusterVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: "version"},
+				Status: configv1.ClusterVersionStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+					{Type: configv1.OperatorProgressing, Status: configv1.ConditionTrue, Message: render.progress},
+				}},
+			}))
+			watcher.clusterVersionLister = configlisters.NewClusterVersionLister(versions)
+			watcher.clusterOperatorLister = clusterOperatorLister(t, render.operators...)
+
+			out.Reset()
+			watcher.render()
+			output := timestamp.ReplaceAllString(out.String(), "")
+			assert.Equal(t, render.expectedOutput, trailingSpaces.ReplaceAllString(output, ""))
+		})
+	}
+}
+
+func TestDestroyBootstrapFlag(t *testing.T) {
+	for _, cmd := range newWaitForCmd().Commands() {
+		flag := cmd.Flags().Lookup("destroy-bootstrap")
+		if cmd.Name() == "bootstrap-complete" {
+			assert.NotNil(t, flag, "--destroy-bootstrap is registered on bootstrap-complete")
+			continue
+		}
+		assert.Nil(t, flag, "--destroy-bootstrap is registered on %s", cmd.Name())
+	}
+}
+
+func TestFinishBootstrap(t *testing.T) {
+	cases := []struct {
+		name            string
+		destroy         bool
+		destroyErr      error
+		expectedDestroy bool
+		expectedLog     string
+		expectedErr     string
+	}{
+		{
+			name:        "bootstrap kept",
+			expectedLog: "It is now safe to remove the bootstrap resources",
+		},
+		{
+			name:            "bootstrap destroyed",
+			destroy:         true,
+			expectedDestroy: true,
+			expectedLog:     "Destroying the bootstrap resources...",
+		},
+		{
+			name:            "bootstrap destroy failed",
+			destroy:         true,
+			destroyErr:      errors.New("failed to delete the bootstrap instance"),
+			expectedDestroy: true,
+			expectedLog:     "Destroying the bootstrap resources...",
+			expectedErr:     "failed to delete the bootstrap instance",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			destroyed := ""
+			defer func(destroy func(string) error) { destroyBootstrapResources = destroy }(destroyBootstrapResources)
+			destroyBootstrapResources = func(dir string) error {
+				destroyed = dir
+				return tc.destroyErr
+			}
+			hook := logrusTest.NewGlobal()
+			defer hook.Reset()
+
+			err := finishBootstrap("/assets", tc.destroy)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.expectedDestroy {
+				assert.Equal(t, "/assets", destroyed)
+			} else {
+				assert.Empty(t, destroyed, "the bootstrap resources are not destroyed")
+			}
+			require.NotNil(t, hook.LastEntry())
+			assert.Equal(t, tc.expectedLog, hook.LastEntry().Message)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/assisted-service/client/installer"
+	"github.com/openshift/assisted-service/models"
+)
+
+// AbortInstall aborts the installation of the cluster through the Agent Rest
+// API of the rendezvous host of the assets directory. The installation is
+// cancelled when it has started, stopping the installation of the hosts, and
+// then reset, leaving the hosts to be discovered again once they boot from
+// the agent ISO.
+func AbortInstall(ctx context.Context, assetDir string) error {
+	rest, err := NewNodeZeroRestClient(ctx, assetDir)
+	if err != nil {
+		return err
+	}
+	if !rest.IsRestAPILive() {
+		return errors.Errorf("the Agent Rest API on the rendezvous host %s is not reachable, the rendezvous host may have already rebooted into the cluster", rest.NodeZeroIP)
+	}
+	return abortInstall(ctx, rest)
+}
+
+func abortInstall(ctx context.Context, rest *NodeZeroRestClient) error {
+	clusterID, err := rest.getClusterID()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve clusterID from Agent Rest API")
+	}
+	if clusterID == nil {
+		return errors.New("the cluster is not registered in the Agent Rest API")
+	}
+	result, err := rest.Client.Installer.V2GetCluster(ctx, &installer.V2GetClusterParams{ClusterID: *clusterID})
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve cluster metadata from Agent Rest API")
+	}
+	cluster := result.Payload
+	status := swag.StringValue(cluster.Status)
+
+	switch status {
+	case models.ClusterStatusInstalled:
+		return errors.New("the cluster is already installed")
+	case models.ClusterStatusCancelled, models.ClusterStatusError:
+		// The installation has already stopped, it only has to be reset.
+	case models.ClusterStatusPreparingForInstallation, models.ClusterStatusInstalling,
+		models.ClusterStatusInstallingPendingUserAction, models.ClusterStatusFinalizing:
+		if cluster, err = rest.CancelInstallation(*clusterID); err != nil {
+			return err
+		}
+		logrus.Info("Cluster installation cancelled")
+	default:
+		logrus.Infof("Cluster installation has not started, the cluster is %s", status)
+		return nil
+	}
+
+	if cluster, err = rest.ResetCluster(*clusterID); err != nil {
+		return err
+	}
+	logrus.Info("Cluster installation reset")
+	for _, host := range cluster.Hosts {
+		if swag.StringValue(host.Status) == models.HostStatusResettingPendingUserAction {
+			logrus.Infof("Reboot host %s on the agent ISO to discover it again", host.RequestedHostname)
+		}
+	}
+	return nil
+}
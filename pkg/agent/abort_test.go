@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/assisted-service/client"
+	"github.com/openshift/assisted-service/models"
+)
+
+func TestAbortInstall(t *testing.T) {
+	clusterID := strfmt.UUID("0b5a3c6e-7d0f-4a2b-8c1d-9e8f7a6b5c4d")
+
+	cases := []struct {
+		name          string
+		status        string
+		expectedCalls []string
+		expectedError string
+	}{
+		{
+			name:          "installing",
+			status:        models.ClusterStatusInstalling,
+			expectedCalls: []string{"cancel", "reset"},
+		},
+		{
+			name:          "error",
+			status:        models.ClusterStatusError,
+			expectedCalls: []string{"reset"},
+		},
+		{
+			name:   "not started",
+			status: models.ClusterStatusReady,
+		},
+		{
+			name:          "installed",
+			status:        models.ClusterStatusInstalled,
+			expectedError: "the cluster is already installed",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &models.Cluster{
+				ID:     &clusterID,
+				Status: swag.String(tc.status),
+				Hosts: []*models.Host{
+					{RequestedHostname: "master-0", Status: swag.String(models.HostStatusInstallingInProgress)},
+				},
+			}
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path := strings.TrimPrefix(r.URL.Path, client.DefaultBasePath)
+				w.Header().Set("Content-Type", "application/json")
+				var payload interface{}
+				switch path {
+				case "/v2/clusters":
+					payload = []*models.Cluster{cluster}
+				case "/v2/clusters/" + clusterID.String():
+					payload = cluster
+				case "/v2/clusters/" + clusterID.String() + "/actions/cancel":
+					calls = append(calls, "cancel")
+					cluster.Status = swag.String(models.ClusterStatusCancelled)
+					cluster.Hosts[0].Status = swag.String(models.HostStatusCancelled)
+					w.WriteHeader(http.StatusAccepted)
+					payload = cluster
+				case "/v2/clusters/" + clusterID.String() + "/actions/reset":
+					calls = append(calls, "reset")
+					cluster.Status = swag.String(models.ClusterStatusInsufficient)
+					cluster.Hosts[0].Status = swag.String(models.HostStatusResettingPendingUserAction)
+					w.WriteHeader(http.StatusAccepted)
+					payload = cluster
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(payload) //nolint:errcheck
+			}))
+			defer server.Close()
+			serverURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			rest := newHostRestClient(context.Background(), serverURL.Hostname())
+			rest.config.URL.Host = serverURL.Host
+			rest.Client = client.New(rest.config)
+
+			err = abortInstall(context.Background(), rest)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
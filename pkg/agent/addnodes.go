@@ -0,0 +1,368 @@
+package agent
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/assisted-service/client/installer"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/installer/pkg/poll"
+)
+
+// DefaultAddNodesTimeout is how long WaitForAddNodes waits when no timeout
+// is given.
+const DefaultAddNodesTimeout = 90 * time.Minute
+
+// Phases of a host joining an installed cluster.
+const (
+	addNodesPhaseDiscovery    = "discovery"
+	addNodesPhaseValidation   = "validation"
+	addNodesPhaseInstallation = "installation"
+	addNodesPhaseCSRApproval  = "CSR approval"
+	addNodesPhaseJoined       = "joined"
+	addNodesPhaseFailed       = "failed"
+)
+
+const (
+	// nodeBootstrapperUser is the user requesting the client certificates
+	// of the kubelets of the nodes which have not joined the cluster yet.
+	nodeBootstrapperUser = "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper"
+
+	nodeUserPrefix = "system:node:"
+	nodesGroup     = "system:nodes"
+)
+
+// addNodesHost tracks a host added to an installed cluster with the agent
+// flow, which is reached through the Agent Rest API running on the host
+// until the host reboots into the installed system.
+type addNodesHost struct {
+	ip       string
+	rest     *NodeZeroRestClient
+	hostname string
+	phase    string
+	message  string
+	// rejected are the certificate signing requests of the node which were
+	// not approved, so that they are only reported once.
+	rejected sets.Set[string]
+}
+
+// WaitForAddNodes waits until the hosts, given by their IPs or by their host
+// names, have joined the cluster of the kubeconfig, reporting the status of
+// each host as it goes through discovery, validation, installation and the
+// approval of the certificate signing requests of its node, which are
+// approved when they are valid for the host name. The hosts given
+// by their names, e.g. those of the nodes config of the add-nodes image, do
+// not run the Agent Rest API and are only tracked through their node. A zero
+// timeout waits for DefaultAddNodesTimeout.
+func WaitForAddNodes(ctx context.Context, kubeconfig string, hostIPs, hostnames []string, timeout time.Duration) error {
+	timeout = waitTimeout(timeout, DefaultAddNodesTimeout)
+	kube, err := NewClusterKubeAPIClientFromKubeconfig(ctx, kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	hosts := make([]*addNodesHost, 0, len(hostIPs)+len(hostnames))
+	names := make([]string, 0, len(hostIPs)+len(hostnames))
+	for _, ip := range hostIPs {
+		hosts = append(hosts, &addNodesHost{ip: ip, rest: newHostRestClient(ctx, ip), rejected: sets.New[string]()})
+		names = append(names, ip)
+	}
+	for _, hostname := range hostnames {
+		hosts = append(hosts, &addNodesHost{hostname: hostname, rejected: sets.New[string]()})
+		names = append(names, hostname)
+	}
+
+	untilTime := time.Now().Add(timeout)
+	timezone, _ := untilTime.Zone()
+	logrus.Infof("Waiting up to %v (until %v %s) for the hosts %s to join the cluster...",
+		timeout, untilTime.Format(time.Kitchen), timezone, strings.Join(names, ", "))
+
+	waitContext, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	waitErr := poll.Until(waitContext, 5*time.Second, func(ctx context.Context) (bool, error) {
+		done := true
+		for _, host := range hosts {
+			if host.phase == addNodesPhaseJoined {
+				continue
+			}
+			phase, message := host.status(ctx, kube)
+			if phase != host.phase || message != host.message {
+				if phase == addNodesPhaseFailed {
+					logrus.Errorf("Host %s: %s", host.name(), message)
+				} else {
+					logrus.Infof("Host %s: %s: %s", host.name(), phase, message)
+				}
+				host.phase, host.message = phase, message
+			}
+			if phase == addNodesPhaseFailed {
+				return false, errors.Errorf("host %s failed to join the cluster: %s", host.name(), message)
+			}
+			done = done && phase == addNodesPhaseJoined
+		}
+		return done, nil
+	})
+	if waitErr == nil {
+		return nil
+	}
+	if !wait.Interrupted(waitErr) {
+		return waitErr
+	}
+
+	var waiting []string
+	for _, host := range hosts {
+		if host.phase != addNodesPhaseJoined {
+			waiting = append(waiting, fmt.Sprintf("%s (%s)", host.name(), host.phase))
+		}
+	}
+	return errors.Errorf("hosts %s did not join the cluster in time", strings.Join(waiting, ", "))
+}
+
+// name returns the IP of the host, or its host name when it is given by name.
+func (host *addNodesHost) name() string {
+	if host.ip != "" {
+		return host.ip
+	}
+	return host.hostname
+}
+
+// status returns the phase of the host and a message describing it.
+func (host *addNodesHost) status(ctx context.Context, kube *ClusterKubeAPIClient) (string, string) {
+	if host.rest == nil {
+		if node, _ := kube.findNode(ctx, host.ip, host.hostname); node == nil {
+			return addNodesPhaseDiscovery, "waiting for the node of the host to register"
+		}
+	} else if host.phase != addNodesPhaseCSRApproval {
+		restHost, err := host.restAPIHost()
+		if err != nil {
+			logrus.Debugf("Host %s: %v", host.ip, err)
+		}
+		if restHost != nil {
+			if restHost.RequestedHostname != "" {
+				host.hostname = restHost.RequestedHostname
+			}
+			if phase, message := addNodesPhase(restHost); phase != addNodesPhaseCSRApproval {
+				return phase, message
+			}
+		} else if host.phase != addNodesPhaseInstallation {
+			// The Agent Rest API goes away when the host reboots at the end
+			// of the installation, otherwise the host was either not booted
+			// yet or already joined the cluster.
+			if node, _ := kube.findNode(ctx, host.ip, host.hostname); node == nil {
+				return addNodesPhaseDiscovery, "waiting for the Agent Rest API of the host"
+			}
+		}
+	}
+
+	node, err := kube.findNode(ctx, host.ip, host.hostname)
+	if err != nil {
+		return addNodesPhaseCSRApproval, err.Error()
+	}
+	if node != nil {
+		host.hostname = node.Name
+		if isNodeReady(node) {
+			return addNodesPhaseJoined, fmt.Sprintf("node %s is Ready", node.Name)
+		}
+	}
+	if host.hostname == "" {
+		return addNodesPhaseCSRApproval, "waiting for the node of the host to register"
+	}
+	approved, err := kube.approveNodeCSRs(ctx, host.hostname, node, host.rejected)
+	if err != nil {
+		return addNodesPhaseCSRApproval, err.Error()
+	}
+	if len(approved) > 0 {
+		return addNodesPhaseCSRApproval, fmt.Sprintf("approved the certificate signing requests %s", strings.Join(approved, " "))
+	}
+	return addNodesPhaseCSRApproval, fmt.Sprintf("waiting for node %s to be Ready", host.hostname)
+}
+
+// restAPIHost returns the host registered in the Agent Rest API running on
+// the host, or nil when the API is not reachable.
+func (host *addNodesHost) restAPIHost() (*models.Host, error) {
+	if !host.rest.IsRestAPILive() {
+		return nil, nil
+	}
+	infraEnvID, err := host.rest.getClusterInfraEnvID()
+	if err != nil || infraEnvID == nil {
+		return nil, err
+	}
+	result, err := host.rest.Client.Installer.V2ListHosts(host.rest.ctx, &installer.V2ListHostsParams{InfraEnvID: *infraEnvID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the hosts of the Agent Rest API")
+	}
+	if len(result.Payload) == 0 {
+		return nil, nil
+	}
+	return result.Payload[0], nil
+}
+
+// addNodesPhase returns the phase of a host registered in the Agent Rest API,
+// and a message describing it.
+func addNodesPhase(host *models.Host) (string, string) {
+	status := ""
+	if host.Status != nil {
+		status = *host.Status
+	}
+	statusInfo := status
+	if host.StatusInfo != nil && *host.StatusInfo != "" {
+		statusInfo = *host.StatusInfo
+	}
+
+	switch status {
+	case models.HostStatusDiscovering, models.HostStatusDisconnected:
+		return addNodesPhaseDiscovery, statusInfo
+	case models.HostStatusKnown, models.HostStatusInsufficient, models.HostStatusPendingForInput:
+		return addNodesPhaseValidation, statusInfo
+	case models.HostStatusPreparingForInstallation, models.HostStatusPreparingSuccessful,
+		models.HostStatusInstalling, models.HostStatusInstallingInProgress:
+		if host.Progress != nil && host.Progress.CurrentStage != "" {
+			return addNodesPhaseInstallation, fmt.Sprintf("%s (%d%%)", host.Progress.CurrentStage, host.Progress.InstallationPercentage)
+		}
+		return addNodesPhaseInstallation, statusInfo
+	case models.HostStatusInstalled, models.HostStatusAddedToExistingCluster:
+		return addNodesPhaseCSRApproval, statusInfo
+	case models.HostStatusPreparingFailed, models.HostStatusInstallingPendingUserAction,
+		models.HostStatusError, models.HostStatusCancelled:
+		return addNodesPhaseFailed, statusInfo
+	default:
+		return addNodesPhaseDiscovery, statusInfo
+	}
+}
+
+// findNode returns the node with the name, or with the IP as its internal
+// address when the name is not known.
+func (kube *ClusterKubeAPIClient) findNode(ctx context.Context, ip, name string) (*corev1.Node, error) {
+	nodes, err := kube.Client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the nodes")
+	}
+	for i, node := range nodes.Items {
+		if name != "" && node.Name == name {
+			return &nodes.Items[i], nil
+		}
+		if ip == "" {
+			continue
+		}
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP && address.Address == ip {
+				return &nodes.Items[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// approveNodeCSRs approves the pending certificate signing requests of the
+// kubelet of the node, nil until it registered, which are valid, and returns
+// the names of those approved. Those which are not valid are reported once
+// and added to rejected.
+func (kube *ClusterKubeAPIClient) approveNodeCSRs(ctx context.Context, nodeName string, node *corev1.Node, rejected sets.Set[string]) ([]string, error) {
+	csrs, err := kube.Client.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the certificate signing requests")
+	}
+	var approved []string
+	for i := range csrs.Items {
+		csr := &csrs.Items[i]
+		if len(csr.Status.Conditions) > 0 || rejected.Has(csr.Name) {
+			continue
+		}
+		ok, err := validateNodeCSR(csr, nodeName, node)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			logrus.Warnf("Not approving the certificate signing request %s of node %s: %v", csr.Name, nodeName, err)
+			rejected.Insert(csr.Name)
+			continue
+		}
+		if err := kube.approveCSR(ctx, csr, "agent wait-for add-nodes"); err != nil {
+			return approved, err
+		}
+		logrus.Infof("Approved the certificate signing request %s of node %s", csr.Name, nodeName)
+		approved = append(approved, csr.Name)
+	}
+	return approved, nil
+}
+
+// validateNodeCSR returns whether the certificate signing request is for the
+// client or the serving certificate of the kubelet of the node, and an error
+// when it is but must not be approved. The client certificate must be
+// requested by the node bootstrapper before the node registered, and the
+// serving certificate by the node itself for its own addresses.
+func validateNodeCSR(csr *certificatesv1.CertificateSigningRequest, nodeName string, node *corev1.Node) (bool, error) {
+	if csr.Spec.SignerName != certificatesv1.KubeAPIServerClientKubeletSignerName && csr.Spec.SignerName != certificatesv1.KubeletServingSignerName {
+		return false, nil
+	}
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil {
+		return false, nil
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil || request.Subject.CommonName != nodeUserPrefix+nodeName {
+		return false, nil
+	}
+	if len(request.Subject.Organization) != 1 || request.Subject.Organization[0] != nodesGroup {
+		return true, errors.Errorf("the organization %v is not %s", request.Subject.Organization, nodesGroup)
+	}
+	if len(request.EmailAddresses) > 0 || len(request.URIs) > 0 {
+		return true, errors.New("the request has email or URI subject alternative names")
+	}
+
+	if csr.Spec.SignerName == certificatesv1.KubeAPIServerClientKubeletSignerName {
+		if csr.Spec.Username != nodeBootstrapperUser {
+			return true, errors.Errorf("the client certificate is requested by %s instead of %s", csr.Spec.Username, nodeBootstrapperUser)
+		}
+		if node != nil {
+			return true, errors.New("the node already registered")
+		}
+		if len(request.DNSNames) > 0 || len(request.IPAddresses) > 0 {
+			return true, errors.New("the client certificate request has subject alternative names")
+		}
+		return true, nil
+	}
+
+	if csr.Spec.Username != request.Subject.CommonName {
+		return true, errors.Errorf("the serving certificate is requested by %s", csr.Spec.Username)
+	}
+	if node == nil {
+		return true, errors.New("the node has not registered")
+	}
+	addresses := sets.New[string]()
+	for _, address := range node.Status.Addresses {
+		addresses.Insert(address.Address)
+	}
+	for _, name := range request.DNSNames {
+		if !addresses.Has(name) {
+			return true, errors.Errorf("the name %s is not an address of the node", name)
+		}
+	}
+	for _, ip := range request.IPAddresses {
+		if !addresses.Has(ip.String()) {
+			return true, errors.Errorf("the IP %s is not an address of the node", ip)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/assisted-service/models"
+)
+
+func TestAddNodesPhase(t *testing.T) {
+	status := func(s string) *string { return &s }
+
+	tests := []struct {
+		name            string
+		host            *models.Host
+		expectedPhase   string
+		expectedMessage string
+	}{
+		{
+			name:            "discovering",
+			host:            &models.Host{Status: status(models.HostStatusDiscovering)},
+			expectedPhase:   addNodesPhaseDiscovery,
+			expectedMessage: models.HostStatusDiscovering,
+		},
+		{
+			name:            "insufficient",
+			host:            &models.Host{Status: status(models.HostStatusInsufficient), StatusInfo: status("Host does not meet the minimum hardware requirements")},
+			expectedPhase:   addNodesPhaseValidation,
+			expectedMessage: "Host does not meet the minimum hardware requirements",
+		},
+		{
+			name: "installing",
+			host: &models.Host{
+				Status:   status(models.HostStatusInstallingInProgress),
+				Progress: &models.HostProgressInfo{CurrentStage: models.HostStageWritingImageToDisk, InstallationPercentage: 42},
+			},
+			expectedPhase:   addNodesPhaseInstallation,
+			expectedMessage: "Writing image to disk (42%)",
+		},
+		{
+			name:            "added to the cluster",
+			host:            &models.Host{Status: status(models.HostStatusAddedToExistingCluster), StatusInfo: status("Host has rebooted and no further updates will be posted")},
+			expectedPhase:   addNodesPhaseCSRApproval,
+			expectedMessage: "Host has rebooted and no further updates will be posted",
+		},
+		{
+			name:            "error",
+			host:            &models.Host{Status: status(models.HostStatusError), StatusInfo: status("Failed to write image to disk")},
+			expectedPhase:   addNodesPhaseFailed,
+			expectedMessage: "Failed to write image to disk",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			phase, message := addNodesPhase(tc.host)
+			assert.Equal(t, tc.expectedPhase, phase)
+			assert.Equal(t, tc.expectedMessage, message)
+		})
+	}
+}
+
+func TestValidateNodeCSR(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	request := func(commonName string, dnsNames []string, ips []net.IP) []byte {
+		template := &x509.CertificateRequest{
+			Subject:     pkix.Name{CommonName: commonName, Organization: []string{nodesGroup}},
+			DNSNames:    dnsNames,
+			IPAddresses: ips,
+		}
+		der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	}
+	node := &corev1.Node{
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: "worker-3"},
+			{Type: corev1.NodeInternalIP, Address: "192.168.111.83"},
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		signer        string
+		username      string
+		request       []byte
+		node          *corev1.Node
+		expectedNode  bool
+		expectedError string
+	}{
+		{
+			name:         "client certificate",
+			signer:       certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:     nodeBootstrapperUser,
+			request:      request("system:node:worker-3", nil, nil),
+			expectedNode: true,
+		},
+		{
+			name:         "other node",
+			signer:       certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:     nodeBootstrapperUser,
+			request:      request("system:node:worker-4", nil, nil),
+			expectedNode: false,
+		},
+		{
+			name:          "client certificate requested by another user",
+			signer:        certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:      "system:serviceaccount:default:default",
+			request:       request("system:node:worker-3", nil, nil),
+			expectedNode:  true,
+			expectedError: "the client certificate is requested by system:serviceaccount:default:default instead of " + nodeBootstrapperUser,
+		},
+		{
+			name:          "client certificate of a registered node",
+			signer:        certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:      nodeBootstrapperUser,
+			request:       request("system:node:worker-3", nil, nil),
+			node:          node,
+			expectedNode:  true,
+			expectedError: "the node already registered",
+		},
+		{
+			name:         "serving certificate",
+			signer:       certificatesv1.KubeletServingSignerName,
+			username:     "system:node:worker-3",
+			request:      request("system:node:worker-3", []string{"worker-3"}, []net.IP{net.ParseIP("192.168.111.83")}),
+			node:         node,
+			expectedNode: true,
+		},
+		{
+			name:          "serving certificate of another address",
+			signer:        certificatesv1.KubeletServingSignerName,
+			username:      "system:node:worker-3",
+			request:       request("system:node:worker-3", nil, []net.IP{net.ParseIP("192.168.111.84")}),
+			node:          node,
+			expectedNode:  true,
+			expectedError: "the IP 192.168.111.84 is not an address of the node",
+		},
+		{
+			name:          "serving certificate before the node registered",
+			signer:        certificatesv1.KubeletServingSignerName,
+			username:      "system:node:worker-3",
+			request:       request("system:node:worker-3", []string{"worker-3"}, nil),
+			expectedNode:  true,
+			expectedError: "the node has not registered",
+		},
+		{
+			name:         "other signer",
+			signer:       certificatesv1.KubeAPIServerClientSignerName,
+			username:     nodeBootstrapperUser,
+			request:      request("system:node:worker-3", nil, nil),
+			expectedNode: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			csr := &certificatesv1.CertificateSigningRequest{
+				Spec: certificatesv1.CertificateSigningRequestSpec{
+					SignerName: tc.signer,
+					Username:   tc.username,
+					Request:    tc.request,
+				},
+			}
+			isNode, err := validateNodeCSR(csr, "worker-3", tc.node)
+			assert.Equal(t, tc.expectedNode, isNode)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+	"github.com/openshift/installer/pkg/bmc"
+	agenttypes "github.com/openshift/installer/pkg/types/agent"
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+// BootHosts boots the hosts of the agent-config of the assets directory with
+// a BMC on the agent ISO at the URL. The ISO is inserted in the virtual media
+// of each host, which is then powered on, or restarted, booting from it once.
+// Only the BMCs speaking Redfish are supported. The hosts which fail to boot
+// are reported in the returned error once every host was attempted.
+func BootHosts(ctx context.Context, assetDir, isoURL string) error {
+	config, err := loadAgentConfigHosts(assetDir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	booted := 0
+	for i, host := range config.Hosts {
+		if host.BMC == nil {
+			continue
+		}
+		name := host.Hostname
+		if name == "" {
+			name = fmt.Sprintf("host-%d", i)
+		}
+		if err := bootHost(ctx, logrus.WithField("host", name), host, isoURL); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to boot host %s", name))
+			continue
+		}
+		booted++
+	}
+	if booted == 0 && len(errs) == 0 {
+		return errors.New("no host of the agent config has a BMC")
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// loadAgentConfigHosts returns the agent config of the assets directory,
+// which defines the hosts and their BMC.
+func loadAgentConfigHosts(assetDir string) (*agenttypes.Config, error) {
+	assetStore, err := assetstore.NewStore(assetDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	agentConfigAsset, err := assetStore.Load(&agentconfig.AgentConfig{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the agent config")
+	}
+	if agentConfigAsset == nil || agentConfigAsset.(*agentconfig.AgentConfig).Config == nil {
+		return nil, errors.New("no agent config found, the hosts and their BMC are defined in agent-config.yaml")
+	}
+	return agentConfigAsset.(*agentconfig.AgentConfig).Config, nil
+}
+
+func bootHost(ctx context.Context, logger logrus.FieldLogger, host agenttypes.Host, isoURL string) error {
+	client, systemID, err := bmc.RedfishClientForHost(&baremetal.Host{Name: host.Hostname, BMC: *host.BMC})
+	if err != nil {
+		return errors.Wrap(err, "booting the host requires a Redfish BMC")
+	}
+	system, err := client.System(ctx, systemID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the system")
+	}
+
+	logger.Infof("Inserting %s in the virtual media", isoURL)
+	if err := client.InsertVirtualMedia(ctx, system, isoURL); err != nil {
+		return errors.Wrap(err, "failed to insert the virtual media")
+	}
+	if err := client.SetBootOnce(ctx, system, "Cd"); err != nil {
+		return errors.Wrap(err, "failed to boot from the virtual media")
+	}
+	logger.Info("Powering on")
+	if err := client.PowerOn(ctx, system); err != nil {
+		return errors.Wrap(err, "failed to power on")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/assisted-service/client/installer"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+	"github.com/openshift/installer/pkg/gather/ssh"
+)
+
+// bootstrapArtifacts are the globs, relative to the assets directory, of the
+// files used to boot the hosts with the agent-based installer, which are not
+// needed anymore once the cluster has bootstrapped.
+var bootstrapArtifacts = []string{
+	"agent.*.iso",
+	"boot-artifacts",
+	"rendezvousIP",
+}
+
+// IsAgentInstall determines if the assets directory holds an installation
+// with the agent-based installer.
+func IsAgentInstall(assetDir string) bool {
+	assetStore, err := assetstore.NewStore(assetDir)
+	if err != nil {
+		logrus.Debug(errors.Wrap(err, "failed to create asset store"))
+		return false
+	}
+	agentManifests, err := assetStore.Load(&manifests.AgentManifests{})
+	if err != nil {
+		logrus.Debug(errors.Wrap(err, "failed to load the agent manifests"))
+		return false
+	}
+	return agentManifests != nil
+}
+
+// DestroyBootstrap cleans up after the bootstrap of a cluster installed with
+// the agent-based installer. There are no bootstrap resources to remove, the
+// rendezvous host bootstraps the cluster and then reboots into its final
+// role, so the rendezvous host is rebooted when its reboot is pending and
+// the artifacts used to boot the hosts are removed.
+func DestroyBootstrap(ctx context.Context, assetDir string) error {
+	rest, err := NewNodeZeroRestClient(ctx, assetDir)
+	if err != nil {
+		return err
+	}
+	kube, err := NewClusterKubeAPIClient(ctx, assetDir)
+	if err != nil {
+		return err
+	}
+
+	if !kube.IsKubeAPILive() {
+		return errors.New("the cluster Kube API is not available, wait for the bootstrap to complete with 'openshift-install agent wait-for bootstrap-complete'")
+	}
+	complete, err := kube.IsBootstrapConfigMapComplete()
+	if err != nil {
+		return err
+	}
+	if !complete {
+		return errors.New("the bootstrap has not completed yet, wait for it with 'openshift-install agent wait-for bootstrap-complete'")
+	}
+
+	if err := rebootRendezvousHost(rest); err != nil {
+		return err
+	}
+	return removeBootstrapArtifacts(assetDir)
+}
+
+// rebootRendezvousHost reboots the rendezvous host when it is still running
+// the Agent Rest API but its installation is only waiting for the reboot.
+func rebootRendezvousHost(rest *NodeZeroRestClient) error {
+	if !rest.IsRestAPILive() {
+		logrus.Infof("The rendezvous host %s has rebooted into its final role", rest.NodeZeroIP)
+		return nil
+	}
+
+	host, err := rest.rendezvousHost()
+	if err != nil {
+		return err
+	}
+	if host == nil {
+		logrus.Infof("The rendezvous host %s is not registered in the Agent Rest API, skipping its reboot", rest.NodeZeroIP)
+		return nil
+	}
+
+	status := ""
+	if host.Status != nil {
+		status = *host.Status
+	}
+	stage := models.HostStage("")
+	if host.Progress != nil {
+		stage = host.Progress.CurrentStage
+	}
+	switch {
+	case status == models.HostStatusInstallingPendingUserAction:
+		return errors.Errorf("the rendezvous host %s booted the agent ISO again instead of its disk, remove the ISO from the host and reboot it", rest.NodeZeroIP)
+	case stage != models.HostStageRebooting:
+		return errors.Errorf("the rendezvous host %s has not finished its installation yet (%s), wait for it with 'openshift-install agent wait-for install-complete'", rest.NodeZeroIP, stage)
+	}
+
+	logrus.Infof("Rebooting the rendezvous host %s into its final role", rest.NodeZeroIP)
+	client, err := ssh.NewClient("core", net.JoinHostPort(rest.NodeZeroIP, "22"), nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to the rendezvous host %s, reboot it manually", rest.NodeZeroIP)
+	}
+	defer client.Close()
+	// The connection drops while the host reboots, so the error is not
+	// meaningful.
+	if err := ssh.Run(client, "sudo systemctl reboot"); err != nil {
+		logrus.Debugf("Rebooting the rendezvous host: %v", err)
+	}
+	return nil
+}
+
+// rendezvousHost returns the host registered in the Agent Rest API which
+// bootstraps the cluster, or nil when it is not registered.
+func (rest *NodeZeroRestClient) rendezvousHost() (*models.Host, error) {
+	infraEnvID, err := rest.getClusterInfraEnvID()
+	if err != nil || infraEnvID == nil {
+		return nil, err
+	}
+	result, err := rest.Client.Installer.V2ListHosts(rest.ctx, &installer.V2ListHostsParams{InfraEnvID: *infraEnvID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the hosts of the Agent Rest API")
+	}
+	for _, host := range result.Payload {
+		if host.Bootstrap {
+			return host, nil
+		}
+	}
+	return nil, nil
+}
+
+// removeBootstrapArtifacts removes the artifacts used to boot the hosts from
+// the assets directory.
+func removeBootstrapArtifacts(assetDir string) error {
+	for _, pattern := range bootstrapArtifacts {
+		paths, err := filepath.Glob(filepath.Join(assetDir, pattern))
+		if err != nil {
+			return errors.Wrapf(err, "failed to glob for %s", pattern)
+		}
+		for _, path := range paths {
+			logrus.Infof("Removing %s", path)
+			if err := os.RemoveAll(path); err != nil {
+				return errors.Wrapf(err, "failed to remove %s", path)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveBootstrapArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{
+		"agent.x86_64.iso",
+		"rendezvousIP",
+		filepath.Join("boot-artifacts", "agent.x86_64-initrd.img"),
+		filepath.Join("auth", "kubeconfig"),
+		".openshift_install_state.json",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !assert.NoError(t, removeBootstrapArtifacts(dir)) {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{".openshift_install_state.json", "auth"}, names)
+}
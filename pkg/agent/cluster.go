@@ -2,8 +2,8 @@ package agent
 
 import (
 	"context"
+	"io"
 	"net"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -15,6 +15,7 @@ import (
 	"github.com/openshift/assisted-service/client/installer"
 	"github.com/openshift/assisted-service/models"
 	"github.com/openshift/installer/pkg/gather/ssh"
+	"github.com/openshift/installer/pkg/statecrypt"
 )
 
 // Cluster is a struct designed to help interact with the cluster that is
@@ -27,6 +28,12 @@ type Cluster struct {
 	clusterID              *strfmt.UUID
 	clusterInfraEnvID      *strfmt.UUID
 	installHistory         *clusterInstallStatusHistory
+	eventRecorder          *eventRecorder
+	hostsProgress          *hostsProgressTable
+
+	// ValidationsOutput receives the reports of the validations of the
+	// cluster and of its hosts, as lines of JSON, whenever they change.
+	ValidationsOutput io.Writer
 }
 
 type clientSet struct {
@@ -59,6 +66,7 @@ type clusterInstallStatusHistory struct {
 	ClusterInstallComplete                              bool
 	NotReadyTime                                        time.Time
 	ValidationResults                                   *validationResults
+	ValidationReport                                    *ValidationReport
 	ClusterInitTime                                     time.Time
 }
 
@@ -212,6 +220,11 @@ func (czero *Cluster) IsBootstrapComplete() (bool, bool, error) {
 		}
 
 		czero.PrintInstallStatus(clusterMetadata)
+		if czero.hostsProgress != nil {
+			if err := czero.hostsProgress.write(clusterMetadata); err != nil {
+				logrus.Warn(err)
+			}
+		}
 
 		// If status indicates pending action, log host info to help pinpoint what is missing
 		if (*clusterMetadata.Status != czero.installHistory.RestAPIPreviousClusterStatus) &&
@@ -249,12 +262,22 @@ func (czero *Cluster) IsBootstrapComplete() (bool, bool, error) {
 			return false, false, errors.Wrap(validationsErr, "cluster host validations failed")
 
 		}
+		if czero.ValidationsOutput != nil {
+			if err := czero.writeValidationReport(clusterMetadata); err != nil {
+				logrus.Warn(err)
+			}
+		}
 
 		// Print most recent event associated with the clusterInfraEnvID
 		eventList, err := czero.API.Rest.GetInfraEnvEvents(czero.clusterInfraEnvID)
 		if err != nil {
 			return false, false, errors.Wrap(err, "Unable to retrieve events about the cluster from the Agent Rest API")
 		}
+		if czero.eventRecorder != nil {
+			if err := czero.eventRecorder.record(eventList); err != nil {
+				logrus.Warn(err)
+			}
+		}
 		if len(eventList) == 0 {
 			// No cluster events detected from the Agent Rest API
 		} else {
@@ -277,6 +300,36 @@ func (czero *Cluster) IsBootstrapComplete() (bool, bool, error) {
 	return false, false, nil
 }
 
+// RecordEvents records the events of the Agent Rest API to the events file of
+// the assets directory, as they are retrieved.
+func (czero *Cluster) RecordEvents() error {
+	recorder, err := newEventRecorder(czero.assetDir)
+	if err != nil {
+		return err
+	}
+	czero.eventRecorder = recorder
+	return nil
+}
+
+// WatchHosts writes a table of the hosts with their role, the stage of their
+// installation and its percentage to the writer whenever it changes, while
+// the Agent Rest API is available. The table is redrawn at the top of the
+// screen when redraw is set, which requires the writer to be a terminal.
+func (czero *Cluster) WatchHosts(w io.Writer, redraw bool) {
+	czero.hostsProgress = &hostsProgressTable{w: w, redraw: redraw}
+}
+
+// writeValidationReport writes the report of the validations of the cluster
+// to the ValidationsOutput when they changed.
+func (czero *Cluster) writeValidationReport(cluster *models.Cluster) error {
+	report, err := newValidationReport(cluster)
+	if err != nil {
+		return err
+	}
+	czero.installHistory.ValidationReport, err = writeValidationReport(czero.ValidationsOutput, report, czero.installHistory.ValidationReport)
+	return err
+}
+
 // IsInstallComplete Determine if the cluster has completed installation.
 func (czero *Cluster) IsInstallComplete() (bool, error) {
 
@@ -416,7 +469,7 @@ func (czero *Cluster) PrintInstallationComplete() error {
 	}
 	kubeconfig := filepath.Join(absDir, "auth", "kubeconfig")
 	pwFile := filepath.Join(absDir, "auth", "kubeadmin-password")
-	pw, err := os.ReadFile(pwFile)
+	pw, err := statecrypt.ReadFile(pwFile)
 	if err != nil {
 		return err
 	}
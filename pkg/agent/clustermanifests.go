@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+)
+
+const (
+	installConfigFilename = "install-config.yaml"
+	agentConfigFilename   = "agent-config.yaml"
+)
+
+// ConvertClusterManifests writes the install-config.yaml and the
+// agent-config.yaml of the cluster manifests of the assets directory, e.g.
+// the ZTP manifests of a GitOps repository rendered from a SiteConfig, which
+// are the reverse of the cluster manifests generated by agent create
+// cluster-manifests. The existing install-config.yaml or agent-config.yaml
+// are not overwritten.
+func ConvertClusterManifests(assetDir string) error {
+	for _, filename := range []string{installConfigFilename, agentConfigFilename} {
+		if _, err := os.Stat(filepath.Join(assetDir, filename)); err == nil {
+			return errors.Errorf("%s already exists in %s", filename, assetDir)
+		}
+	}
+
+	assetStore, err := assetstore.NewStore(assetDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to create asset store")
+	}
+	agentManifests := &manifests.AgentManifests{}
+	for _, a := range []asset.WritableAsset{
+		&manifests.AgentPullSecret{},
+		&manifests.InfraEnv{},
+		&manifests.NMStateConfig{},
+		&manifests.AgentClusterInstall{},
+		&manifests.ClusterDeployment{},
+	} {
+		loaded, err := assetStore.Load(a)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load the %s", a.Name())
+		}
+		switch v := loaded.(type) {
+		case *manifests.AgentPullSecret:
+			agentManifests.PullSecret = v.Config
+		case *manifests.InfraEnv:
+			agentManifests.InfraEnv = v.Config
+		case *manifests.NMStateConfig:
+			agentManifests.NMStateConfigs = v.Config
+		case *manifests.AgentClusterInstall:
+			agentManifests.AgentClusterInstall = v.Config
+		case *manifests.ClusterDeployment:
+			agentManifests.ClusterDeployment = v.Config
+		}
+	}
+
+	installConfig, agentConfig, err := agentManifests.AgentInputs()
+	if err != nil {
+		return errors.Wrap(err, "failed to convert the cluster manifests")
+	}
+	for filename, config := range map[string]interface{}{
+		installConfigFilename: installConfig,
+		agentConfigFilename:   agentConfig,
+	} {
+		data, err := yaml.Marshal(config)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s", filename)
+		}
+		if err := os.WriteFile(filepath.Join(assetDir, filename), data, 0600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", filename)
+		}
+		logrus.Infof("Wrote %s", filename)
+	}
+	return nil
+}
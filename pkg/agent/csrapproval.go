@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/installer/pkg/poll"
+)
+
+// DefaultCSRApprovalTimeout is how long WaitForCSRApproval waits when no
+// timeout is given.
+const DefaultCSRApprovalTimeout = 60 * time.Minute
+
+// csrApprover approves the certificate signing requests of the kubelets of
+// the expected hosts joining the cluster.
+type csrApprover struct {
+	kube *ClusterKubeAPIClient
+	// hostnames are the names of the nodes of the expected hosts.
+	hostnames sets.Set[string]
+	// rejected are the requests which were not approved, so that they are
+	// only reported once.
+	rejected sets.Set[string]
+}
+
+// WaitForCSRApproval approves the certificate signing requests of the
+// kubelets of the hosts with the host names, until the cluster of the
+// kubeconfig has the expected number of Ready nodes. The requests of other
+// nodes, and those which are not requested by the node bootstrapper before
+// the node registered, for the client certificate, or by the node itself for
+// its own addresses, for the serving certificate, are not approved. A zero
+// timeout waits for DefaultCSRApprovalTimeout.
+func WaitForCSRApproval(ctx context.Context, kubeconfig string, expectedNodes int, hostnames []string, timeout time.Duration) error {
+	if expectedNodes <= 0 {
+		return errors.Errorf("invalid number of expected nodes %d, must be positive", expectedNodes)
+	}
+	if len(hostnames) == 0 {
+		return errors.New("the host names of the joining nodes are required to approve their certificate signing requests")
+	}
+	timeout = waitTimeout(timeout, DefaultCSRApprovalTimeout)
+	kube, err := NewClusterKubeAPIClientFromKubeconfig(ctx, kubeconfig)
+	if err != nil {
+		return err
+	}
+	approver := &csrApprover{
+		kube:      kube,
+		hostnames: sets.New(hostnames...),
+		rejected:  sets.New[string](),
+	}
+	logrus.Infof("Approving the certificate signing requests of the nodes %s", strings.Join(sets.List(approver.hostnames), ", "))
+
+	untilTime := time.Now().Add(timeout)
+	timezone, _ := untilTime.Zone()
+	logrus.Infof("Waiting up to %v (until %v %s) for %d nodes to be Ready...",
+		timeout, untilTime.Format(time.Kitchen), timezone, expectedNodes)
+
+	waitContext, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lastReady := -1
+	waitErr := poll.Until(waitContext, 10*time.Second, func(ctx context.Context) (bool, error) {
+		ready, err := approver.approve(ctx)
+		if err != nil {
+			if poll.IsTransient(err) {
+				return false, err
+			}
+			logrus.Debugf("Failed to approve the certificate signing requests: %v", err)
+			return false, nil
+		}
+		if ready != lastReady {
+			logrus.Infof("%d of %d nodes are Ready", ready, expectedNodes)
+			lastReady = ready
+		}
+		return ready >= expectedNodes, nil
+	})
+	if waitErr != nil {
+		return errors.Errorf("only %d of %d nodes were Ready in time: %v", lastReady, expectedNodes, waitErr)
+	}
+	return nil
+}
+
+// approve approves the pending requests which are valid, and returns the
+// number of Ready nodes.
+func (a *csrApprover) approve(ctx context.Context) (int, error) {
+	nodeList, err := a.kube.Client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list the nodes")
+	}
+	nodes := make(map[string]*corev1.Node, len(nodeList.Items))
+	ready := 0
+	for i := range nodeList.Items {
+		nodes[nodeList.Items[i].Name] = &nodeList.Items[i]
+		if isNodeReady(&nodeList.Items[i]) {
+			ready++
+		}
+	}
+
+	csrs, err := a.kube.Client.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return ready, errors.Wrap(err, "failed to list the certificate signing requests")
+	}
+	for i := range csrs.Items {
+		csr := &csrs.Items[i]
+		if len(csr.Status.Conditions) > 0 || a.rejected.Has(csr.Name) {
+			continue
+		}
+		nodeName, err := validateExpectedNodeCSR(csr, a.hostnames, nodes)
+		if err != nil {
+			logrus.Warnf("Not approving the certificate signing request %s: %v", csr.Name, err)
+			a.rejected.Insert(csr.Name)
+			continue
+		}
+		if nodeName == "" {
+			continue
+		}
+		if err := a.kube.approveCSR(ctx, csr, "wait-for csr-approval"); err != nil {
+			return ready, err
+		}
+		logrus.Infof("Approved the certificate signing request %s of node %s", csr.Name, nodeName)
+	}
+	return ready, nil
+}
+
+// validateExpectedNodeCSR returns the name of the node of the certificate
+// signing request when it is a valid request of the kubelet of one of the
+// hosts, an empty name when the request is not for a kubelet, and an error
+// otherwise.
+func validateExpectedNodeCSR(csr *certificatesv1.CertificateSigningRequest, hostnames sets.Set[string], nodes map[string]*corev1.Node) (string, error) {
+	if csr.Spec.SignerName != certificatesv1.KubeAPIServerClientKubeletSignerName && csr.Spec.SignerName != certificatesv1.KubeletServingSignerName {
+		return "", nil
+	}
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil {
+		return "", errors.New("the request is not PEM encoded")
+	}
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse the request")
+	}
+	if !strings.HasPrefix(request.Subject.CommonName, nodeUserPrefix) {
+		return "", errors.Errorf("the common name %q is not a node", request.Subject.CommonName)
+	}
+	nodeName := strings.TrimPrefix(request.Subject.CommonName, nodeUserPrefix)
+	if !hostnames.Has(nodeName) {
+		return "", errors.Errorf("node %s is not one of the expected hosts", nodeName)
+	}
+	if csr.Spec.SignerName == certificatesv1.KubeletServingSignerName && !hostnames.Has(strings.TrimPrefix(csr.Spec.Username, nodeUserPrefix)) {
+		return "", errors.Errorf("the serving certificate of node %s is requested by %s, which is not one of the expected hosts", nodeName, csr.Spec.Username)
+	}
+	if _, err := validateNodeCSR(csr, nodeName, nodes[nodeName]); err != nil {
+		return "", errors.Wrapf(err, "node %s", nodeName)
+	}
+	return nodeName, nil
+}
+
+// approveCSR approves the certificate signing request on behalf of the
+// openshift-install command.
+func (kube *ClusterKubeAPIClient) approveCSR(ctx context.Context, csr *certificatesv1.CertificateSigningRequest, command string) error {
+	csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+		Type:    certificatesv1.CertificateApproved,
+		Status:  corev1.ConditionTrue,
+		Reason:  "OpenShiftInstallerApprove",
+		Message: "Approved by openshift-install " + command,
+	})
+	if _, err := kube.Client.CertificatesV1().CertificateSigningRequests().UpdateApproval(ctx, csr.Name, csr, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to approve the certificate signing request %s", csr.Name)
+	}
+	return nil
+}
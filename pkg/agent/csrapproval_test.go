@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestValidateExpectedNodeCSR(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	request := func(commonName string, dnsNames []string, ips []net.IP) []byte {
+		template := &x509.CertificateRequest{
+			Subject:     pkix.Name{CommonName: commonName, Organization: []string{nodesGroup}},
+			DNSNames:    dnsNames,
+			IPAddresses: ips,
+		}
+		der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	}
+	nodes := map[string]*corev1.Node{
+		"worker-0": {
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "worker-0"},
+				{Type: corev1.NodeInternalIP, Address: "192.168.111.80"},
+			}},
+		},
+		"master-0": {
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "master-0"},
+			}},
+		},
+	}
+	hostnames := sets.New("worker-0", "worker-1")
+
+	tests := []struct {
+		name          string
+		signer        string
+		username      string
+		request       []byte
+		expectedNode  string
+		expectedError string
+	}{
+		{
+			name:         "client certificate of an expected host",
+			signer:       certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:     nodeBootstrapperUser,
+			request:      request("system:node:worker-1", nil, nil),
+			expectedNode: "worker-1",
+		},
+		{
+			name:          "client certificate of another host",
+			signer:        certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:      nodeBootstrapperUser,
+			request:       request("system:node:worker-2", nil, nil),
+			expectedError: "node worker-2 is not one of the expected hosts",
+		},
+		{
+			name:          "client certificate requested by another user",
+			signer:        certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:      "system:serviceaccount:default:default",
+			request:       request("system:node:worker-1", nil, nil),
+			expectedError: "node worker-1: the client certificate is requested by system:serviceaccount:default:default instead of " + nodeBootstrapperUser,
+		},
+		{
+			name:          "client certificate of a joined node",
+			signer:        certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:      nodeBootstrapperUser,
+			request:       request("system:node:worker-0", nil, nil),
+			expectedError: "node worker-0: the node already registered",
+		},
+		{
+			name:          "client certificate of a user",
+			signer:        certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:      nodeBootstrapperUser,
+			request:       request("admin", nil, nil),
+			expectedError: `the common name "admin" is not a node`,
+		},
+		{
+			name:          "malformed request",
+			signer:        certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username:      nodeBootstrapperUser,
+			request:       []byte("request"),
+			expectedError: "the request is not PEM encoded",
+		},
+		{
+			name:         "serving certificate of an expected host",
+			signer:       certificatesv1.KubeletServingSignerName,
+			username:     "system:node:worker-0",
+			request:      request("system:node:worker-0", []string{"worker-0"}, []net.IP{net.ParseIP("192.168.111.80")}),
+			expectedNode: "worker-0",
+		},
+		{
+			name:          "serving certificate of another node",
+			signer:        certificatesv1.KubeletServingSignerName,
+			username:      "system:node:master-0",
+			request:       request("system:node:master-0", []string{"master-0"}, nil),
+			expectedError: "node master-0 is not one of the expected hosts",
+		},
+		{
+			name:          "serving certificate requested by another node",
+			signer:        certificatesv1.KubeletServingSignerName,
+			username:      "system:node:master-0",
+			request:       request("system:node:worker-0", []string{"worker-0"}, nil),
+			expectedError: "the serving certificate of node worker-0 is requested by system:node:master-0, which is not one of the expected hosts",
+		},
+		{
+			name:          "serving certificate requested by another expected host",
+			signer:        certificatesv1.KubeletServingSignerName,
+			username:      "system:node:worker-1",
+			request:       request("system:node:worker-0", []string{"worker-0"}, nil),
+			expectedError: "node worker-0: the serving certificate is requested by system:node:worker-1",
+		},
+		{
+			name:          "serving certificate of another address",
+			signer:        certificatesv1.KubeletServingSignerName,
+			username:      "system:node:worker-0",
+			request:       request("system:node:worker-0", nil, []net.IP{net.ParseIP("192.168.111.81")}),
+			expectedError: "node worker-0: the IP 192.168.111.81 is not an address of the node",
+		},
+		{
+			name:          "serving certificate before the node joined",
+			signer:        certificatesv1.KubeletServingSignerName,
+			username:      "system:node:worker-1",
+			request:       request("system:node:worker-1", []string{"worker-1"}, nil),
+			expectedError: "node worker-1: the node has not registered",
+		},
+		{
+			name:     "other signer",
+			signer:   certificatesv1.KubeAPIServerClientSignerName,
+			username: nodeBootstrapperUser,
+			request:  request("system:node:worker-1", nil, nil),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			csr := &certificatesv1.CertificateSigningRequest{
+				Spec: certificatesv1.CertificateSigningRequestSpec{
+					SignerName: tc.signer,
+					Username:   tc.username,
+					Request:    tc.request,
+				},
+			}
+			nodeName, err := validateExpectedNodeCSR(csr, hostnames, nodes)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedNode, nodeName)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/openshift/assisted-service/models"
+)
+
+// EventsFileName is the name of the file of the assets directory which the
+// events of the Agent Rest API are recorded to.
+const EventsFileName = "agent-events.jsonl"
+
+// eventRecorder appends the events of the Agent Rest API to a file, as lines
+// of JSON, skipping the events which were already recorded, including by
+// previous runs.
+type eventRecorder struct {
+	path string
+	seen map[string]bool
+}
+
+// newEventRecorder returns a recorder appending to the events file of the
+// assets directory.
+func newEventRecorder(assetDir string) (*eventRecorder, error) {
+	recorder := &eventRecorder{
+		path: filepath.Join(assetDir, EventsFileName),
+		seen: map[string]bool{},
+	}
+	file, err := os.Open(recorder.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return recorder, nil
+		}
+		return nil, errors.Wrap(err, "failed to read the recorded events")
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event := &models.Event{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			// A line truncated by an interrupted write.
+			continue
+		}
+		recorder.seen[eventKey(event)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read the recorded events")
+	}
+	return recorder, nil
+}
+
+// eventKey identifies the event, which has no ID.
+func eventKey(event *models.Event) string {
+	key := struct {
+		Time    string `json:"t"`
+		Name    string `json:"n"`
+		Host    string `json:"h"`
+		Message string `json:"m"`
+	}{Name: event.Name}
+	if event.EventTime != nil {
+		key.Time = event.EventTime.String()
+	}
+	if event.HostID != nil {
+		key.Host = event.HostID.String()
+	}
+	if event.Message != nil {
+		key.Message = *event.Message
+	}
+	data, _ := json.Marshal(key)
+	return string(data)
+}
+
+// record appends the events which were not recorded yet.
+func (r *eventRecorder) record(events models.EventList) error {
+	var data []byte
+	var keys []string
+	for _, event := range events {
+		key := eventKey(event)
+		if r.seen[key] {
+			continue
+		}
+		line, err := json.Marshal(event)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the event")
+		}
+		data = append(append(data, line...), '\n')
+		keys = append(keys, key)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to open the events file")
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return errors.Wrap(err, "failed to record the events")
+	}
+	for _, key := range keys {
+		r.seen[key] = true
+	}
+	return nil
+}
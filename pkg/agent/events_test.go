@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/assisted-service/models"
+)
+
+func TestEventRecorder(t *testing.T) {
+	eventTime := strfmt.DateTime(time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC))
+	hostID := strfmt.UUID("3b8c2f0e-1d4a-4c5e-9f6b-7a8d9e0f1a2b")
+	registered := &models.Event{EventTime: &eventTime, Name: "host_registration_succeeded", HostID: &hostID, Message: swag.String("Host master-0: registered"), Severity: swag.String(models.EventSeverityInfo)}
+	ntp := &models.Event{EventTime: &eventTime, Name: "host_validation_failed", HostID: &hostID, Message: swag.String("Host master-0: validation 'ntp-synced' is now failing"), Severity: swag.String(models.EventSeverityWarning)}
+	installing := &models.Event{EventTime: &eventTime, Name: "cluster_status_updated", Message: swag.String("Cluster is installing"), Severity: swag.String(models.EventSeverityInfo)}
+
+	assetDir := t.TempDir()
+	recorder, err := newEventRecorder(assetDir)
+	require.NoError(t, err)
+	require.NoError(t, recorder.record(models.EventList{registered, ntp}))
+	// The events are listed again at each poll.
+	require.NoError(t, recorder.record(models.EventList{registered, ntp}))
+
+	// A later run skips the events recorded by the previous ones.
+	recorder, err = newEventRecorder(assetDir)
+	require.NoError(t, err)
+	require.NoError(t, recorder.record(models.EventList{registered, ntp, installing}))
+
+	data, err := os.ReadFile(filepath.Join(assetDir, EventsFileName))
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[0]), "Host master-0: registered")
+	assert.Contains(t, string(lines[1]), "ntp-synced")
+	assert.Contains(t, string(lines[2]), "Cluster is installing")
+}
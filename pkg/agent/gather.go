@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/assisted-service/client/installer"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/installer/pkg/gather"
+	"github.com/openshift/installer/pkg/gather/ssh"
+)
+
+// GatherLogs gathers the logs of an installation with the agent-based
+// installer into a log bundle in the assets directory, and returns the path
+// of the bundle. The host and controller logs, and the events, are downloaded
+// from the Agent Rest API on node zero, and the output of agent-gather is
+// pulled from node zero over SSH, so that the logs are gathered as long as
+// either works, e.g. when no SSH key was provisioned.
+func GatherLogs(ctx context.Context, assetDir string) (string, error) {
+	rest, err := NewNodeZeroRestClient(ctx, assetDir)
+	if err != nil {
+		return "", err
+	}
+	if rest.NodeZeroIP == "" {
+		return "", errors.New("the rendezvous host was not discovered on the machine networks")
+	}
+
+	gatherID := time.Now().Format("20060102150405")
+	directory := filepath.Join(assetDir, fmt.Sprintf("agent-log-bundle-%s", gatherID))
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := gather.DeleteArchiveDirectory(directory); err != nil {
+			// Note: cleanup is best effort, it shouldn't fail the gather
+			logrus.Debugf("Failed to remove archive directory: %v", err)
+		}
+	}()
+
+	var errs []error
+	var files []string
+	logrus.Infof("Downloading the logs from the Agent Rest API on %s", rest.NodeZeroIP)
+	restFiles, err := gatherFromRestAPI(rest, directory)
+	files = append(files, restFiles...)
+	if err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to download the logs from the Agent Rest API"))
+	}
+	logrus.Infof("Pulling the output of agent-gather from %s", rest.NodeZeroIP)
+	sshFile, err := gatherOverSSH(rest.NodeZeroIP, directory)
+	if err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to pull the output of agent-gather"))
+	} else {
+		files = append(files, sshFile)
+	}
+
+	if len(files) == 0 {
+		return "", utilerrors.NewAggregate(errs)
+	}
+	for _, err := range errs {
+		logrus.Warn(err)
+	}
+
+	bundlePath := directory + ".tar.gz"
+	if err := gather.CreateArchive(files, bundlePath); err != nil {
+		return "", errors.Wrap(err, "failed to create archive")
+	}
+	return bundlePath, nil
+}
+
+// gatherFromRestAPI downloads the host and controller logs of the cluster, and
+// the events of its infraenv, from the Agent Rest API into the directory, and
+// returns the paths of the files which were downloaded.
+func gatherFromRestAPI(rest *NodeZeroRestClient, directory string) ([]string, error) {
+	var files []string
+	var errs []error
+
+	infraEnvID, err := rest.getClusterInfraEnvID()
+	switch {
+	case err != nil:
+		errs = append(errs, errors.Wrap(err, "failed to get the infraenv"))
+	case infraEnvID != nil:
+		events, err := rest.GetInfraEnvEvents(infraEnvID)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to list the events"))
+			break
+		}
+		data, err := json.MarshalIndent(events, "", "  ")
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		eventsPath := filepath.Join(directory, "events.json")
+		if err := os.WriteFile(eventsPath, data, 0600); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		files = append(files, eventsPath)
+	}
+
+	clusterID, err := rest.getClusterID()
+	switch {
+	case err != nil:
+		errs = append(errs, errors.Wrap(err, "failed to get the cluster"))
+	case clusterID == nil:
+		errs = append(errs, errors.New("the cluster is not registered, no logs to download"))
+	default:
+		logsPath := filepath.Join(directory, "cluster-logs.tar")
+		if err := downloadClusterLogs(rest, *clusterID, logsPath); err != nil {
+			errs = append(errs, err)
+		} else {
+			files = append(files, logsPath)
+		}
+	}
+	return files, utilerrors.NewAggregate(errs)
+}
+
+// downloadClusterLogs downloads the host and controller logs of the cluster,
+// a tarball of the tarballs of each host and of the controller, to logsPath.
+func downloadClusterLogs(rest *NodeZeroRestClient, clusterID strfmt.UUID, logsPath string) error {
+	file, err := os.Create(logsPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	params := installer.NewV2DownloadClusterLogsParams().
+		WithClusterID(clusterID).
+		WithLogsType(swag.String(string(models.LogsTypeAll)))
+	if _, err := rest.Client.Installer.V2DownloadClusterLogs(rest.ctx, params, file); err != nil {
+		return errors.Wrap(err, "failed to download the cluster logs")
+	}
+	return nil
+}
+
+// gatherOverSSH runs agent-gather on node zero and writes its output, an xz
+// compressed tarball, into the directory.
+func gatherOverSSH(ip, directory string) (string, error) {
+	client, err := ssh.NewClient("core", net.JoinHostPort(ip, "22"), nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to connect to %s", ip)
+	}
+	defer client.Close()
+
+	filePath := filepath.Join(directory, "agent-gather.tar.xz")
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if err := ssh.RunWithOutput(client, "sudo /usr/local/bin/agent-gather -O", file); err != nil {
+		return "", errors.Wrap(err, "failed to run agent-gather")
+	}
+	return filePath, nil
+}
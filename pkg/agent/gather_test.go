@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/assisted-service/client"
+	"github.com/openshift/assisted-service/models"
+)
+
+func TestGatherFromRestAPI(t *testing.T) {
+	clusterID := strfmt.UUID("c8a8b9f2-7a0e-4b6e-9c55-1f0b9a0d3e11")
+	infraEnvID := strfmt.UUID("6f7e5c1a-3b2d-4e8f-a9c0-2d1e4f5a6b7c")
+	cases := []struct {
+		name          string
+		clusters      []*models.Cluster
+		expectedFiles []string
+		expectedError string
+	}{
+		{
+			name:          "registered cluster",
+			clusters:      []*models.Cluster{{ID: &clusterID}},
+			expectedFiles: []string{"events.json", "cluster-logs.tar"},
+		},
+		{
+			name:          "unregistered cluster",
+			expectedFiles: []string{"events.json"},
+			expectedError: "the cluster is not registered, no logs to download",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/api/assisted-install/v2/infra-envs":
+					json.NewEncoder(w).Encode([]*models.InfraEnv{{ID: &infraEnvID}}) //nolint:errcheck
+				case "/api/assisted-install/v2/events":
+					assert.Equal(t, infraEnvID.String(), r.URL.Query().Get("infra_env_id"))
+					json.NewEncoder(w).Encode(models.EventList{{Message: swag.String("Host master-0: registered")}}) //nolint:errcheck
+				case "/api/assisted-install/v2/clusters":
+					json.NewEncoder(w).Encode(tc.clusters) //nolint:errcheck
+				case "/api/assisted-install/v2/clusters/" + clusterID.String() + "/logs":
+					assert.Equal(t, "all", r.URL.Query().Get("logs_type"))
+					w.Header().Set("Content-Type", "application/octet-stream")
+					w.Write([]byte("logs")) //nolint:errcheck
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if !assert.NoError(t, err) {
+				return
+			}
+			config := client.Config{URL: &url.URL{Scheme: "http", Host: serverURL.Host, Path: client.DefaultBasePath}}
+			rest := &NodeZeroRestClient{Client: client.New(config), ctx: context.Background(), config: config}
+
+			directory := t.TempDir()
+			files, err := gatherFromRestAPI(rest, directory)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			assert.Equal(t, tc.expectedFiles, names)
+			if len(tc.clusters) > 0 {
+				logs, err := os.ReadFile(filepath.Join(directory, "cluster-logs.tar"))
+				assert.NoError(t, err)
+				assert.Equal(t, "logs", string(logs))
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/assisted-service/client/installer"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/installer/pkg/poll"
+)
+
+// DefaultHostResetTimeout is how long ResetHost waits for the hosts to
+// register again when no timeout is given.
+const DefaultHostResetTimeout = 30 * time.Minute
+
+// hostResetInterval is how often the cluster being reset is polled.
+var hostResetInterval = 10 * time.Second
+
+// ResetHost retries the installation of the cluster after the host with the
+// hostname, or the ID, registered in the Agent Rest API of the rendezvous
+// host of the assets directory failed. The Agent Rest API does not reset a
+// single host of a cluster which is not installed yet, so the installation
+// of the cluster is cancelled and reset, and triggered again once all the
+// hosts have registered again, which requires those which started writing
+// to their disk to boot from the agent ISO. A zero timeout waits for
+// DefaultHostResetTimeout.
+func ResetHost(ctx context.Context, assetDir, hostname string, timeout time.Duration) error {
+	rest, err := NewNodeZeroRestClient(ctx, assetDir)
+	if err != nil {
+		return err
+	}
+	if !rest.IsRestAPILive() {
+		return errors.Errorf("the Agent Rest API on the rendezvous host %s is not reachable", rest.NodeZeroIP)
+	}
+	return resetHost(ctx, rest, hostname, timeout)
+}
+
+func resetHost(ctx context.Context, rest *NodeZeroRestClient, hostname string, timeout time.Duration) error {
+	timeout = waitTimeout(timeout, DefaultHostResetTimeout)
+	clusterID, err := rest.getClusterID()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve clusterID from Agent Rest API")
+	}
+	infraEnvID, err := rest.getClusterInfraEnvID()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve clusterInfraEnvID from Agent Rest API")
+	}
+	if clusterID == nil || infraEnvID == nil {
+		return errors.New("the cluster is not registered in the Agent Rest API")
+	}
+
+	host, err := rest.FindHost(*infraEnvID, hostname)
+	if err != nil {
+		return err
+	}
+	if host == nil || host.ID == nil {
+		return errors.Errorf("host %s is not registered in the Agent Rest API", hostname)
+	}
+	if stage := hostProgressStage(host); stage != hostStageFailed {
+		return errors.Errorf("host %s is %s, only a failed host can be reset", hostname, swag.StringValue(host.Status))
+	}
+
+	if err := abortInstall(ctx, rest); err != nil {
+		return err
+	}
+
+	waitContext, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	hostStatuses := map[string]string{}
+	installing := false
+	err = poll.Until(waitContext, hostResetInterval, func(ctx context.Context) (bool, error) {
+		result, err := rest.Client.Installer.V2GetCluster(ctx, &installer.V2GetClusterParams{ClusterID: *clusterID})
+		if err != nil {
+			return false, errors.Wrap(err, "unable to retrieve cluster metadata from Agent Rest API")
+		}
+		cluster := result.Payload
+		for _, h := range cluster.Hosts {
+			name := h.RequestedHostname
+			if name == "" && h.ID != nil {
+				name = h.ID.String()
+			}
+			status := swag.StringValue(h.Status)
+			if hostStatuses[name] == status {
+				continue
+			}
+			hostStatuses[name] = status
+			if status == models.HostStatusResettingPendingUserAction {
+				logrus.Infof("Reboot host %s on the agent ISO to register it again", name)
+			} else {
+				logrus.Infof("Host %s is %s", name, status)
+			}
+		}
+		switch swag.StringValue(cluster.Status) {
+		case models.ClusterStatusReady:
+			return true, nil
+		case models.ClusterStatusPreparingForInstallation, models.ClusterStatusInstalling:
+			// The installation was triggered again by other means.
+			installing = true
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errors.Errorf("the hosts did not register again after %s", timeout)
+		}
+		return err
+	}
+	if installing {
+		logrus.Info("Installation of the cluster was already triggered again")
+		return nil
+	}
+
+	if _, err := rest.InstallCluster(*clusterID); err != nil {
+		return err
+	}
+	logrus.Info("Installation of the cluster was triggered again")
+	return nil
+}
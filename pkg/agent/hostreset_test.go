@@ -0,0 +1,183 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/assisted-service/client"
+	"github.com/openshift/assisted-service/models"
+)
+
+// fakeAssistedService follows the state machine of the assisted-service for
+// the cluster level actions of a cluster which is not installed yet, and
+// rejects the actions which are not allowed in the current state with a
+// conflict as the assisted-service does.
+type fakeAssistedService struct {
+	cluster    *models.Cluster
+	infraEnvID strfmt.UUID
+	calls      []string
+}
+
+func (f *fakeAssistedService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, client.DefaultBasePath)
+	clusterPath := "/v2/clusters/" + f.cluster.ID.String()
+	w.Header().Set("Content-Type", "application/json")
+	status := swag.StringValue(f.cluster.Status)
+	var payload interface{}
+	switch path {
+	case "/v2/clusters":
+		payload = []*models.Cluster{f.cluster}
+	case "/v2/infra-envs":
+		payload = []*models.InfraEnv{{ID: &f.infraEnvID, ClusterID: *f.cluster.ID}}
+	case "/v2/infra-envs/" + f.infraEnvID.String() + "/hosts":
+		payload = f.cluster.Hosts
+	case clusterPath:
+		f.rebootHosts()
+		payload = f.cluster
+	case clusterPath + "/actions/cancel":
+		f.calls = append(f.calls, "cancel")
+		if !sets.New(models.ClusterStatusPreparingForInstallation, models.ClusterStatusInstalling,
+			models.ClusterStatusInstallingPendingUserAction, models.ClusterStatusFinalizing, models.ClusterStatusError).Has(status) {
+			f.conflict(w, "cancel")
+			return
+		}
+		f.setStatus(models.ClusterStatusCancelled, models.HostStatusCancelled)
+		w.WriteHeader(http.StatusAccepted)
+		payload = f.cluster
+	case clusterPath + "/actions/reset":
+		f.calls = append(f.calls, "reset")
+		if !sets.New(models.ClusterStatusCancelled, models.ClusterStatusError).Has(status) {
+			f.conflict(w, "reset")
+			return
+		}
+		f.setStatus(models.ClusterStatusInsufficient, models.HostStatusResettingPendingUserAction)
+		w.WriteHeader(http.StatusAccepted)
+		payload = f.cluster
+	case clusterPath + "/actions/install":
+		f.calls = append(f.calls, "install")
+		if status != models.ClusterStatusReady {
+			f.conflict(w, "install")
+			return
+		}
+		f.setStatus(models.ClusterStatusPreparingForInstallation, models.HostStatusPreparingForInstallation)
+		w.WriteHeader(http.StatusAccepted)
+		payload = f.cluster
+	default:
+		// The host level reset and install actions are only allowed for
+		// the hosts added to an installed cluster.
+		f.calls = append(f.calls, path)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(payload) //nolint:errcheck
+}
+
+func (f *fakeAssistedService) conflict(w http.ResponseWriter, action string) {
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(&models.Error{ //nolint:errcheck
+		Code:   swag.String("409"),
+		Reason: swag.String("Can't " + action + " cluster in " + swag.StringValue(f.cluster.Status)),
+	})
+}
+
+func (f *fakeAssistedService) setStatus(clusterStatus, hostStatus string) {
+	f.cluster.Status = swag.String(clusterStatus)
+	for _, host := range f.cluster.Hosts {
+		host.Status = swag.String(hostStatus)
+	}
+}
+
+// rebootHosts registers the reset hosts again, as when they boot from the
+// agent ISO, and makes the cluster ready once they all have.
+func (f *fakeAssistedService) rebootHosts() {
+	if swag.StringValue(f.cluster.Status) != models.ClusterStatusInsufficient {
+		return
+	}
+	for _, host := range f.cluster.Hosts {
+		if swag.StringValue(host.Status) == models.HostStatusResettingPendingUserAction {
+			host.Status = swag.String(models.HostStatusKnown)
+			return
+		}
+	}
+	f.cluster.Status = swag.String(models.ClusterStatusReady)
+}
+
+func TestResetHost(t *testing.T) {
+	hostResetInterval = 10 * time.Millisecond
+	clusterID := strfmt.UUID("0b5a3c6e-7d0f-4a2b-8c1d-9e8f7a6b5c4d")
+	infraEnvID := strfmt.UUID("1c6b4d7f-8e1a-4b3c-9d2e-0f9a8b7c6d5e")
+	masterID := strfmt.UUID("2d7c5e8a-9f2b-4c4d-8e3f-1a0b9c8d7e6f")
+	workerID := strfmt.UUID("3e8d6f9b-0a3c-4d5e-9f4a-2b1c0d9e8f7a")
+
+	cases := []struct {
+		name          string
+		clusterStatus string
+		workerStatus  string
+		expectedCalls []string
+		expectedError string
+	}{
+		{
+			name:          "failed host of a failed cluster",
+			clusterStatus: models.ClusterStatusError,
+			workerStatus:  models.HostStatusError,
+			expectedCalls: []string{"reset", "install"},
+		},
+		{
+			name:          "failed host of an installing cluster",
+			clusterStatus: models.ClusterStatusInstalling,
+			workerStatus:  models.HostStatusError,
+			expectedCalls: []string{"cancel", "reset", "install"},
+		},
+		{
+			name:          "installing host",
+			clusterStatus: models.ClusterStatusInstalling,
+			workerStatus:  models.HostStatusInstallingInProgress,
+			expectedError: "host worker-0 is installing-in-progress, only a failed host can be reset",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeAssistedService{
+				cluster: &models.Cluster{
+					ID:     &clusterID,
+					Status: swag.String(tc.clusterStatus),
+					Hosts: []*models.Host{
+						{ID: &masterID, InfraEnvID: infraEnvID, RequestedHostname: "master-0", Status: swag.String(models.HostStatusInstallingInProgress)},
+						{ID: &workerID, InfraEnvID: infraEnvID, RequestedHostname: "worker-0", Status: swag.String(tc.workerStatus)},
+					},
+				},
+				infraEnvID: infraEnvID,
+			}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+			serverURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			rest := newHostRestClient(context.Background(), serverURL.Hostname())
+			rest.config.URL.Host = serverURL.Host
+			rest.Client = client.New(rest.config)
+
+			err = resetHost(context.Background(), rest, "worker-0", time.Minute)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Empty(t, fake.calls)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCalls, fake.calls)
+			assert.Equal(t, models.ClusterStatusPreparingForInstallation, *fake.cluster.Status)
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift/installer/pkg/statecrypt"
 )
 
 // ClusterKubeAPIClient is a kube client to interact with the cluster that agent installer is installing.
@@ -22,10 +24,19 @@ type ClusterKubeAPIClient struct {
 
 // NewClusterKubeAPIClient Create a new kube client to interact with the cluster under install.
 func NewClusterKubeAPIClient(ctx context.Context, assetDir string) (*ClusterKubeAPIClient, error) {
+	return NewClusterKubeAPIClientFromKubeconfig(ctx, filepath.Join(assetDir, "auth", "kubeconfig"))
+}
+
+// NewClusterKubeAPIClientFromKubeconfig initializes a kube API client from the
+// kubeconfig file.
+func NewClusterKubeAPIClientFromKubeconfig(ctx context.Context, kubeconfigpath string) (*ClusterKubeAPIClient, error) {
 	kubeClient := &ClusterKubeAPIClient{}
 
-	kubeconfigpath := filepath.Join(assetDir, "auth", "kubeconfig")
-	kubeconfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigpath)
+	data, err := statecrypt.ReadFile(kubeconfigpath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading kubeconfig from assets")
+	}
+	kubeconfig, err := clientcmd.RESTConfigFromKubeConfig(data)
 	if err != nil {
 		return nil, errors.Wrap(err, "error loading kubeconfig from assets")
 	}
@@ -13,6 +13,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	configclient "github.com/openshift/client-go/config/clientset/versioned"
 	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	"github.com/openshift/installer/pkg/statecrypt"
 	cov1helpers "github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
 	"github.com/openshift/library-go/pkg/route/routeapihelpers"
 )
@@ -39,7 +40,11 @@ func NewClusterOpenShiftAPIClient(ctx context.Context, assetDir string) (*Cluste
 	ocpClient := &ClusterOpenShiftAPIClient{}
 
 	kubeconfigpath := filepath.Join(assetDir, "auth", "kubeconfig")
-	kubeconfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigpath)
+	data, err := statecrypt.ReadFile(kubeconfigpath)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading kubeconfig from assets")
+	}
+	kubeconfig, err := clientcmd.RESTConfigFromKubeConfig(data)
 	if err != nil {
 		return nil, errors.Wrap(err, "creating kubeconfig for ocp config client")
 	}
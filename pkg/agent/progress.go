@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/openshift/assisted-service/models"
+)
+
+// Stages of the hosts shown in the progress table.
+const (
+	hostStageDiscovering = "discovering"
+	hostStageInstalling  = "installing"
+	hostStageRebooting   = "rebooting"
+	hostStageDone        = "done"
+	hostStageFailed      = "failed"
+)
+
+// clearScreen moves the cursor to the top of the terminal and clears it.
+const clearScreen = "\033[H\033[2J"
+
+// hostProgress is the progress of the installation of a host.
+type hostProgress struct {
+	Hostname   string
+	Role       string
+	Stage      string
+	Percentage int64
+	Status     string
+}
+
+// hostsProgressTable writes the progress of the hosts as a table whenever it
+// changes. On a terminal the table is redrawn at the top of the screen, and
+// the log lines since the previous table are printed below it.
+type hostsProgressTable struct {
+	w        io.Writer
+	redraw   bool
+	previous []hostProgress
+}
+
+// write writes the table of the progress of the hosts of the cluster, unless
+// it is the same as the previous one.
+func (t *hostsProgressTable) write(cluster *models.Cluster) error {
+	hosts := newHostsProgress(cluster)
+	if t.previous != nil && reflect.DeepEqual(t.previous, hosts) {
+		return nil
+	}
+	if t.redraw {
+		if _, err := io.WriteString(t.w, clearScreen); err != nil {
+			return errors.Wrap(err, "failed to write the progress of the hosts")
+		}
+	}
+	if err := writeHostsProgress(t.w, hosts); err != nil {
+		return err
+	}
+	t.previous = hosts
+	return nil
+}
+
+// newHostsProgress returns the progress of the hosts of the cluster, sorted by
+// hostname.
+func newHostsProgress(cluster *models.Cluster) []hostProgress {
+	hosts := []hostProgress{}
+	for _, h := range cluster.Hosts {
+		host := hostProgress{
+			Hostname: h.RequestedHostname,
+			Role:     string(h.Role),
+		}
+		if host.Hostname == "" && h.ID != nil {
+			host.Hostname = h.ID.String()
+		}
+		if h.Role == models.HostRoleAutoAssign && h.SuggestedRole != "" {
+			host.Role = string(h.SuggestedRole)
+		}
+		if h.Bootstrap {
+			host.Role += " (rendezvous)"
+		}
+		if h.Status != nil {
+			host.Status = *h.Status
+		}
+		if h.Progress != nil {
+			host.Percentage = h.Progress.InstallationPercentage
+			if h.Progress.CurrentStage != "" {
+				host.Status = string(h.Progress.CurrentStage)
+			}
+		}
+		host.Stage = hostProgressStage(h)
+		if host.Stage == hostStageDone {
+			host.Percentage = 100
+		}
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].Hostname < hosts[j].Hostname
+	})
+	return hosts
+}
+
+// hostProgressStage returns the stage of the installation of a host
+// registered in the Agent Rest API.
+func hostProgressStage(host *models.Host) string {
+	status := ""
+	if host.Status != nil {
+		status = *host.Status
+	}
+	var currentStage models.HostStage
+	if host.Progress != nil {
+		currentStage = host.Progress.CurrentStage
+	}
+
+	switch status {
+	case models.HostStatusPreparingForInstallation, models.HostStatusPreparingSuccessful,
+		models.HostStatusInstalling, models.HostStatusInstallingInProgress:
+		switch currentStage {
+		case models.HostStageRebooting, models.HostStageWaitingForIgnition,
+			models.HostStageConfiguring, models.HostStageJoined:
+			return hostStageRebooting
+		case models.HostStageDone:
+			return hostStageDone
+		}
+		return hostStageInstalling
+	case models.HostStatusInstalled, models.HostStatusAddedToExistingCluster:
+		return hostStageDone
+	case models.HostStatusPreparingFailed, models.HostStatusInstallingPendingUserAction,
+		models.HostStatusError, models.HostStatusCancelled:
+		return hostStageFailed
+	default:
+		return hostStageDiscovering
+	}
+}
+
+// writeHostsProgress writes the progress of the hosts as a table.
+func writeHostsProgress(w io.Writer, hosts []hostProgress) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tROLE\tSTAGE\tPROGRESS\tSTATUS")
+	for _, host := range hosts {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d%%\t%s\n", host.Hostname, host.Role, host.Stage, host.Percentage, host.Status)
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "failed to write the progress of the hosts")
+	}
+	return nil
+}
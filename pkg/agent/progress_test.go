@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/assisted-service/models"
+)
+
+func TestHostsProgressTable(t *testing.T) {
+	id := strfmt.UUID("e9f3a8c4-2b6d-4f5e-9a1c-7d8e0f1a2b3c")
+	cluster := &models.Cluster{
+		Hosts: []*models.Host{
+			{
+				RequestedHostname: "worker-0",
+				Role:              models.HostRoleAutoAssign,
+				SuggestedRole:     models.HostRoleWorker,
+				Status:            swag.String(models.HostStatusKnown),
+			},
+			{
+				RequestedHostname: "master-1",
+				Role:              models.HostRoleMaster,
+				Status:            swag.String(models.HostStatusInstallingInProgress),
+				Progress:          &models.HostProgressInfo{CurrentStage: models.HostStageRebooting, InstallationPercentage: 71},
+			},
+			{
+				RequestedHostname: "master-0",
+				Role:              models.HostRoleMaster,
+				Bootstrap:         true,
+				Status:            swag.String(models.HostStatusInstallingInProgress),
+				Progress:          &models.HostProgressInfo{CurrentStage: models.HostStageWritingImageToDisk, InstallationPercentage: 42},
+			},
+			{
+				ID:     &id,
+				Role:   models.HostRoleMaster,
+				Status: swag.String(models.HostStatusInstalled),
+			},
+			{
+				RequestedHostname: "worker-1",
+				Role:              models.HostRoleWorker,
+				Status:            swag.String(models.HostStatusError),
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	table := &hostsProgressTable{w: &out}
+	require.NoError(t, table.write(cluster))
+	assert.Equal(t, `HOST                                   ROLE                  STAGE         PROGRESS   STATUS
+e9f3a8c4-2b6d-4f5e-9a1c-7d8e0f1a2b3c   master                done          100%       installed
+master-0                               master (rendezvous)   installing    42%        Writing image to disk
+master-1                               master                rebooting     71%        Rebooting
+worker-0                               worker                discovering   0%         known
+worker-1                               worker                failed        0%         error
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, table.write(cluster))
+	assert.Empty(t, out.String(), "an unchanged table is not written again")
+
+	table.redraw = true
+	cluster.Hosts[1].Progress.CurrentStage = models.HostStageDone
+	require.NoError(t, table.write(cluster))
+	assert.Contains(t, out.String(), clearScreen)
+	assert.Contains(t, out.String(), "master-1                               master                done          100%       Done")
+}
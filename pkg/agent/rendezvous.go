@@ -0,0 +1,199 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/asset/agent/image"
+)
+
+const (
+	// maxDiscoveryPrefix is the largest machine network, as the length of
+	// its prefix, swept for the rendezvous host.
+	maxDiscoveryPrefix = 16
+	discoveryWorkers   = 256
+	discoveryTimeout   = 500 * time.Millisecond
+	// discoverySweepInterval is the least time between two sweeps of the
+	// machine networks.
+	discoverySweepInterval = 5 * time.Minute
+)
+
+// rendezvousDiscovery finds the rendezvous host discovered by the hosts: at the
+// IP found by a previous run, at the IPs of the hostname it registered with the
+// DHCP server, or else by sweeping the machine networks in the background. The
+// rendezvous host is the one serving the Agent Rest API with the certificate
+// issued by the CA of the assets for the name of the rendezvous host.
+type rendezvousDiscovery struct {
+	networks   []*net.IPNet
+	port       string
+	serverName string
+	tlsConfig  *tls.Config
+	// hostname is the name the rendezvous host registers with the DHCP
+	// server, if any.
+	hostname string
+	// cacheFile holds the IP of the rendezvous host once it is found.
+	cacheFile string
+
+	mu        sync.Mutex
+	sweeping  bool
+	lastSweep time.Time
+	swept     string
+}
+
+func newRendezvousDiscovery(networks []*net.IPNet, hostname string, transport http.RoundTripper) (*rendezvousDiscovery, error) {
+	httpTransport, ok := transport.(*http.Transport)
+	if !ok || httpTransport.TLSClientConfig == nil {
+		return nil, errors.New("discovering the rendezvous host requires the authentication of the Agent Rest API, generate the image again with --rest-api-auth")
+	}
+	if len(networks) == 0 && hostname == "" {
+		return nil, errors.New("discovering the rendezvous host requires the machine networks of the install-config, or the hostname of the rendezvous discovery")
+	}
+	return &rendezvousDiscovery{
+		networks:   networks,
+		port:       "8090",
+		serverName: image.RendezvousServerName,
+		tlsConfig:  httpTransport.TLSClientConfig,
+		hostname:   hostname,
+	}, nil
+}
+
+// discover returns the IP of the rendezvous host, or an empty string when it
+// was not found yet, e.g. when it has not booted yet. The machine networks are
+// swept in the background, at most once per discoverySweepInterval, and the
+// IP found by the sweep is returned by a later call.
+func (d *rendezvousDiscovery) discover(ctx context.Context) string {
+	ip := d.lookup(ctx)
+	if ip == "" {
+		ip = d.sweepResult(ctx)
+	}
+	if ip != "" && d.cacheFile != "" {
+		if err := os.WriteFile(d.cacheFile, []byte(ip), 0o644); err != nil { //nolint:gosec // no sensitive info
+			logrus.Debugf("Failed to cache the IP of the rendezvous host: %v", err)
+		}
+	}
+	return ip
+}
+
+// lookup returns the IP of the rendezvous host among the cached IP and the
+// IPs of its hostname, or an empty string when none is the rendezvous host.
+func (d *rendezvousDiscovery) lookup(ctx context.Context) string {
+	candidates := []string{}
+	if d.cacheFile != "" {
+		if data, err := os.ReadFile(d.cacheFile); err == nil {
+			candidates = append(candidates, strings.TrimSpace(string(data)))
+		}
+	}
+	if d.hostname != "" {
+		ips, err := net.DefaultResolver.LookupHost(ctx, d.hostname)
+		if err != nil {
+			logrus.Debugf("Failed to resolve the rendezvous host %s: %v", d.hostname, err)
+		}
+		candidates = append(candidates, ips...)
+	}
+	for _, ip := range candidates {
+		if net.ParseIP(ip) != nil && d.isRendezvousHost(ctx, ip) {
+			return ip
+		}
+	}
+	return ""
+}
+
+// sweepResult returns the IP found by the last sweep of the machine networks,
+// starting a new sweep when none is running and the last one is old enough.
+func (d *rendezvousDiscovery) sweepResult(ctx context.Context) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.swept != "" {
+		return d.swept
+	}
+	if !d.sweeping && time.Since(d.lastSweep) >= discoverySweepInterval {
+		d.sweeping = true
+		go func() {
+			ip := d.sweep(ctx)
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.swept = ip
+			d.sweeping = false
+			d.lastSweep = time.Now()
+		}()
+	}
+	return ""
+}
+
+// sweep returns the IP of the rendezvous host found on the machine networks,
+// or an empty string when it was not found.
+func (d *rendezvousDiscovery) sweep(ctx context.Context) string {
+	ips := make(chan net.IP)
+	found := make(chan string, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < discoveryWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range ips {
+				if d.isRendezvousHost(ctx, ip.String()) {
+					select {
+					case found <- ip.String():
+						cancel()
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+sweep:
+	for _, network := range d.networks {
+		ones, bits := network.Mask.Size()
+		if bits != 32 || ones < maxDiscoveryPrefix {
+			logrus.Debugf("Skipping the machine network %s, only the IPv4 networks up to /%d are swept for the rendezvous host", network, maxDiscoveryPrefix)
+			continue
+		}
+		first := binary.BigEndian.Uint32(network.IP.Mask(network.Mask).To4())
+		for i := uint32(1); i < uint32(1)<<(bits-ones)-1; i++ {
+			ip := make(net.IP, 4)
+			binary.BigEndian.PutUint32(ip, first+i)
+			select {
+			case ips <- ip:
+			case <-ctx.Done():
+				break sweep
+			}
+		}
+	}
+	close(ips)
+	wg.Wait()
+
+	select {
+	case ip := <-found:
+		return ip
+	default:
+		return ""
+	}
+}
+
+// isRendezvousHost returns whether the host with the IP serves the Agent Rest
+// API with the certificate of the rendezvous host.
+func (d *rendezvousDiscovery) isRendezvousHost(ctx context.Context, ip string) bool {
+	dialer := &net.Dialer{Timeout: discoveryTimeout}
+	tlsConfig := d.tlsConfig.Clone()
+	tlsConfig.ServerName = d.serverName
+	conn, err := (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", net.JoinHostPort(ip, d.port))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
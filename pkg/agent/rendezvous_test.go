@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRendezvousDiscovery(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	_, network, err := net.ParseCIDR("127.0.0.0/30")
+	require.NoError(t, err)
+
+	_, err = newRendezvousDiscovery([]*net.IPNet{network}, "", nil)
+	assert.EqualError(t, err, "discovering the rendezvous host requires the authentication of the Agent Rest API, generate the image again with --rest-api-auth")
+	_, err = newRendezvousDiscovery(nil, "", server.Client().Transport)
+	assert.EqualError(t, err, "discovering the rendezvous host requires the machine networks of the install-config, or the hostname of the rendezvous discovery")
+
+	discovery, err := newRendezvousDiscovery([]*net.IPNet{network}, "", server.Client().Transport)
+	require.NoError(t, err)
+	assert.Equal(t, "rendezvous.agent.internal", discovery.serverName)
+	discovery.port = port
+	discovery.serverName = "example.com"
+	discovery.cacheFile = filepath.Join(t.TempDir(), "rendezvousIP")
+	// The sweep runs in the background, its result is returned by the next
+	// calls.
+	assert.Eventually(t, func() bool {
+		return discovery.discover(context.Background()) == "127.0.0.1"
+	}, 10*time.Second, 10*time.Millisecond)
+	cached, err := os.ReadFile(discovery.cacheFile)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", string(cached))
+
+	// The cached IP is found without sweeping.
+	cachedDiscovery, err := newRendezvousDiscovery([]*net.IPNet{network}, "", server.Client().Transport)
+	require.NoError(t, err)
+	cachedDiscovery.port = port
+	cachedDiscovery.serverName = "example.com"
+	cachedDiscovery.cacheFile = discovery.cacheFile
+	assert.Equal(t, "127.0.0.1", cachedDiscovery.lookup(context.Background()))
+
+	// The hostname registered with the DHCP server is resolved.
+	hostnameDiscovery, err := newRendezvousDiscovery(nil, "localhost", server.Client().Transport)
+	require.NoError(t, err)
+	hostnameDiscovery.port = port
+	hostnameDiscovery.serverName = "example.com"
+	assert.Equal(t, "127.0.0.1", hostnameDiscovery.lookup(context.Background()))
+
+	// The hosts with a certificate issued by another CA are not the
+	// rendezvous host.
+	transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: x509.NewCertPool(), MinVersion: tls.VersionTLS12}}
+	discovery, err = newRendezvousDiscovery([]*net.IPNet{network}, "", transport)
+	require.NoError(t, err)
+	discovery.port = port
+	discovery.serverName = "example.com"
+	assert.Equal(t, "", discovery.sweep(context.Background()))
+}
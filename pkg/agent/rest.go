@@ -2,12 +2,19 @@ package agent
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net"
+	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 
+	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http/httpproxy"
 
 	"github.com/openshift/assisted-service/api/v1beta1"
 	"github.com/openshift/assisted-service/client"
@@ -19,9 +26,20 @@ import (
 	"github.com/openshift/installer/pkg/asset/agent/manifests"
 	"github.com/openshift/installer/pkg/asset/installconfig"
 	assetstore "github.com/openshift/installer/pkg/asset/store"
+	"github.com/openshift/installer/pkg/types"
 	"github.com/openshift/installer/pkg/types/agent"
 )
 
+// rendezvousIPFile is the file of the assets directory holding the IP of the
+// rendezvous host, written by the image when it is known up front and by the
+// discovery once it is found.
+const rendezvousIPFile = "rendezvousIP"
+
+// RestAPIProxy is the URL of the proxy through which the Agent Rest API is
+// reached, instead of the proxy of the install-config. The hosts matching
+// the NO_PROXY environment variable are reached directly.
+var RestAPIProxy string
+
 // NodeZeroRestClient is a struct to interact with the Agent Rest API that is on node zero.
 type NodeZeroRestClient struct {
 	Client     *client.AssistedInstall
@@ -29,6 +47,11 @@ type NodeZeroRestClient struct {
 	config     client.Config
 	NodeZeroIP string
 	NodeSSHKey []string
+	transport  http.RoundTripper
+	authInfo   runtime.ClientAuthInfoWriter
+	// discovery finds the rendezvous host when it is discovered by the
+	// hosts, until it is found.
+	discovery *rendezvousDiscovery
 }
 
 // NewNodeZeroRestClient Initialize a new rest client to interact with the Agent Rest API on node zero.
@@ -46,6 +69,12 @@ func NewNodeZeroRestClient(ctx context.Context, assetDir string) (*NodeZeroRestC
 	agentConfig, agentConfigError := assetStore.Load(agentConfigAsset)
 	agentManifests, manifestError := assetStore.Load(agentManifestsAsset)
 	installConfig, installConfigError := assetStore.Load(installConfigAsset)
+	// The assets created by older installers have no authentication of the
+	// Agent Rest API, which is then served over plain HTTP.
+	restAPIAuth, restAPIAuthError := assetStore.Load(&image.RestAPIAuth{})
+	if restAPIAuthError != nil {
+		logrus.Debug(errors.Wrap(restAPIAuthError, "failed to load the Agent Rest API authentication"))
+	}
 
 	if agentConfigError != nil {
 		logrus.Debug(errors.Wrapf(agentConfigError, "failed to load %s", agentConfigAsset.Name()))
@@ -64,7 +93,14 @@ func NewNodeZeroRestClient(ctx context.Context, assetDir string) (*NodeZeroRestC
 	var rendezvousIPError error
 	var emptyNMStateConfigs []*v1beta1.NMStateConfig
 
-	if agentConfig != nil && agentManifests != nil {
+	discoverRendezvousHost := false
+	if agentConfig != nil {
+		discoverRendezvousHost = agentconfig.RendezvousMACAddress(agentConfig.(*agentconfig.AgentConfig).Config) != ""
+	}
+
+	if discoverRendezvousHost {
+		logrus.Debug("The rendezvous host is discovered on the machine networks")
+	} else if agentConfig != nil && agentManifests != nil {
 		RendezvousIP, rendezvousIPError = image.RetrieveRendezvousIP(agentConfig.(*agentconfig.AgentConfig).Config, agentManifests.(*manifests.AgentManifests).NMStateConfigs)
 	} else if agentConfig == nil && agentManifests != nil {
 		RendezvousIP, rendezvousIPError = image.RetrieveRendezvousIP(&agent.Config{}, agentManifests.(*manifests.AgentManifests).NMStateConfigs)
@@ -82,24 +118,144 @@ func NewNodeZeroRestClient(ctx context.Context, assetDir string) (*NodeZeroRestC
 		restClient.NodeSSHKey = append(restClient.NodeSSHKey, installConfig.(*installconfig.InstallConfig).Config.SSHKey)
 	}
 
-	config := client.Config{}
+	if restAPIAuth != nil && restAPIAuth.(*image.RestAPIAuth).UserToken != "" {
+		if err := restClient.setAuth(restAPIAuth.(*image.RestAPIAuth)); err != nil {
+			return nil, err
+		}
+	}
+	var proxy *types.Proxy
+	if installConfig != nil {
+		proxy = installConfig.(*installconfig.InstallConfig).Config.Proxy
+	}
+	if err := restClient.setProxy(RestAPIProxy, proxy); err != nil {
+		return nil, err
+	}
+	if discoverRendezvousHost {
+		var networks []*net.IPNet
+		if installConfig != nil {
+			for _, network := range installConfig.(*installconfig.InstallConfig).Config.Networking.MachineNetwork {
+				cidr := network.CIDR.IPNet
+				networks = append(networks, &cidr)
+			}
+		}
+		hostname := agentConfig.(*agentconfig.AgentConfig).Config.RendezvousDiscovery.Hostname
+		discovery, err := newRendezvousDiscovery(networks, hostname, restClient.transport)
+		if err != nil {
+			return nil, err
+		}
+		// The certificate of the rendezvous host is issued for its name.
+		restClient.transport.(*http.Transport).TLSClientConfig.ServerName = image.RendezvousServerName
+		discovery.cacheFile = filepath.Join(assetDir, rendezvousIPFile)
+		restClient.discovery = discovery
+		RendezvousIP = discovery.discover(ctx)
+		if RendezvousIP != "" {
+			logrus.Infof("Discovered the rendezvous host %s", RendezvousIP)
+			restClient.discovery = nil
+		}
+	}
+	restClient.setHost(ctx, RendezvousIP)
+
+	return restClient, nil
+}
+
+// newHostRestClient Initialize a new rest client to interact with the Agent Rest API on the host with the IP.
+func newHostRestClient(ctx context.Context, ip string) *NodeZeroRestClient {
+	restClient := &NodeZeroRestClient{}
+	restClient.setHost(ctx, ip)
+	return restClient
+}
+
+// setAuth authenticates the rest client to the Agent Rest API with the user
+// token, over HTTPS verified with the CA of the API.
+func (rest *NodeZeroRestClient) setAuth(auth *image.RestAPIAuth) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(auth.CACert) {
+		return errors.New("failed to parse the CA of the Agent Rest API")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	rest.transport = transport
+	rest.authInfo = runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+		return r.SetHeaderParam(image.RestAPIAuthHeader, auth.UserToken)
+	})
+	return nil
+}
+
+// setProxy makes the rest client reach the Agent Rest API through the proxy
+// URL when set, or else through the proxy of the install-config. The hosts
+// matching the noProxy of the proxy, e.g. the IPs of the machine network when
+// it is listed, are reached directly.
+func (rest *NodeZeroRestClient) setProxy(proxyURL string, proxy *types.Proxy) error {
+	var config *httpproxy.Config
+	switch {
+	case proxyURL != "":
+		if _, err := url.Parse(proxyURL); err != nil {
+			return errors.Wrap(err, "invalid proxy URL")
+		}
+		config = &httpproxy.Config{
+			HTTPProxy:  proxyURL,
+			HTTPSProxy: proxyURL,
+			NoProxy:    os.Getenv("NO_PROXY"),
+		}
+		if config.NoProxy == "" {
+			config.NoProxy = os.Getenv("no_proxy")
+		}
+	case proxy != nil && (proxy.HTTPProxy != "" || proxy.HTTPSProxy != ""):
+		config = &httpproxy.Config{
+			HTTPProxy:  proxy.HTTPProxy,
+			HTTPSProxy: proxy.HTTPSProxy,
+			NoProxy:    proxy.NoProxy,
+		}
+	default:
+		return nil
+	}
+
+	transport, ok := rest.transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	proxyFunc := config.ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	rest.transport = transport
+	return nil
+}
+
+// setHost points the rest client to the Agent Rest API on the host with the IP.
+func (rest *NodeZeroRestClient) setHost(ctx context.Context, ip string) {
+	scheme := "http"
+	if rest.authInfo != nil {
+		scheme = "https"
+	}
+	config := client.Config{
+		Transport: rest.transport,
+		AuthInfo:  rest.authInfo,
+	}
 	config.URL = &url.URL{
-		Scheme: "http",
-		Host:   net.JoinHostPort(RendezvousIP, "8090"),
+		Scheme: scheme,
+		Host:   net.JoinHostPort(ip, "8090"),
 		Path:   client.DefaultBasePath,
 	}
-	client := client.New(config)
-
-	restClient.Client = client
-	restClient.ctx = ctx
-	restClient.config = config
-	restClient.NodeZeroIP = RendezvousIP
 
-	return restClient, nil
+	rest.Client = client.New(config)
+	rest.ctx = ctx
+	rest.config = config
+	rest.NodeZeroIP = ip
 }
 
 // IsRestAPILive Determine if the Agent Rest API on node zero has initialized
 func (rest *NodeZeroRestClient) IsRestAPILive() bool {
+	if rest.discovery != nil {
+		ip := rest.discovery.discover(rest.ctx)
+		if ip == "" {
+			logrus.Debug("The rendezvous host was not discovered yet")
+			return false
+		}
+		logrus.Infof("Discovered the rendezvous host %s", ip)
+		rest.discovery = nil
+		rest.setHost(rest.ctx, ip)
+	}
 	// GET /v2/infraenvs
 	listInfraEnvsParams := installer.NewListInfraEnvsParams()
 	_, err := rest.Client.Installer.ListInfraEnvs(rest.ctx, listInfraEnvsParams)
@@ -165,3 +321,51 @@ func (rest *NodeZeroRestClient) getClusterInfraEnvID() (*strfmt.UUID, error) {
 		return nil, nil
 	}
 }
+
+// FindHost returns the host of the infraEnv with the hostname or the ID, or
+// nil when it is not registered in the Agent Rest API.
+func (rest *NodeZeroRestClient) FindHost(infraEnvID strfmt.UUID, name string) (*models.Host, error) {
+	// GET /v2/infra-envs/{infra_env_id}/hosts
+	result, err := rest.Client.Installer.V2ListHosts(rest.ctx, &installer.V2ListHostsParams{InfraEnvID: infraEnvID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the hosts of the Agent Rest API")
+	}
+	for _, host := range result.Payload {
+		if host.RequestedHostname == name || (host.ID != nil && host.ID.String() == name) {
+			return host, nil
+		}
+	}
+	return nil, nil
+}
+
+// InstallCluster triggers the installation of the ready cluster.
+func (rest *NodeZeroRestClient) InstallCluster(clusterID strfmt.UUID) (*models.Cluster, error) {
+	// POST /v2/clusters/{cluster_id}/actions/install
+	result, err := rest.Client.Installer.V2InstallCluster(rest.ctx, &installer.V2InstallClusterParams{ClusterID: clusterID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to install the cluster")
+	}
+	return result.Payload, nil
+}
+
+// CancelInstallation cancels the installation of the cluster, stopping the
+// installation of its hosts.
+func (rest *NodeZeroRestClient) CancelInstallation(clusterID strfmt.UUID) (*models.Cluster, error) {
+	// POST /v2/clusters/{cluster_id}/actions/cancel
+	result, err := rest.Client.Installer.V2CancelInstallation(rest.ctx, &installer.V2CancelInstallationParams{ClusterID: clusterID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to cancel the installation of the cluster")
+	}
+	return result.Payload, nil
+}
+
+// ResetCluster resets the cancelled or failed installation of the cluster,
+// whose hosts have to register again, booting from the agent ISO.
+func (rest *NodeZeroRestClient) ResetCluster(clusterID strfmt.UUID) (*models.Cluster, error) {
+	// POST /v2/clusters/{cluster_id}/actions/reset
+	result, err := rest.Client.Installer.V2ResetCluster(rest.ctx, &installer.V2ResetClusterParams{ClusterID: clusterID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reset the installation of the cluster")
+	}
+	return result.Payload, nil
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/assisted-service/client"
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/installer/pkg/asset/agent/image"
+	"github.com/openshift/installer/pkg/types"
+)
+
+func TestRestClientAuth(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(image.RestAPIAuthHeader) != "user-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]*models.InfraEnv{}) //nolint:errcheck
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(serverURL.Host)
+	require.NoError(t, err)
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	cases := []struct {
+		name     string
+		auth     *image.RestAPIAuth
+		expected bool
+	}{
+		{
+			name:     "authenticated",
+			auth:     &image.RestAPIAuth{CACert: caCert, UserToken: "user-token"},
+			expected: true,
+		},
+		{
+			name: "wrong token",
+			auth: &image.RestAPIAuth{CACert: caCert, UserToken: "agent-token"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rest := &NodeZeroRestClient{}
+			require.NoError(t, rest.setAuth(tc.auth))
+			rest.setHost(context.Background(), host)
+			assert.Equal(t, "https", rest.config.URL.Scheme)
+			// The API listens on 8090 on the rendezvous host.
+			rest.config.URL.Host = net.JoinHostPort(host, port)
+			rest.Client = client.New(rest.config)
+			assert.Equal(t, tc.expected, rest.IsRestAPILive())
+		})
+	}
+}
+
+func TestRestClientProxy(t *testing.T) {
+	t.Setenv("NO_PROXY", "192.0.2.0/24")
+	cases := []struct {
+		name          string
+		proxyURL      string
+		proxy         *types.Proxy
+		ip            string
+		expectedProxy string
+	}{
+		{
+			name: "no proxy",
+			ip:   "198.51.100.10",
+		},
+		{
+			name:          "install-config proxy",
+			proxy:         &types.Proxy{HTTPProxy: "http://proxy.example.com:3128", NoProxy: "example.com"},
+			ip:            "198.51.100.10",
+			expectedProxy: "http://proxy.example.com:3128",
+		},
+		{
+			name:  "install-config machine network in noProxy",
+			proxy: &types.Proxy{HTTPProxy: "http://proxy.example.com:3128", NoProxy: "198.51.100.0/24"},
+			ip:    "198.51.100.10",
+		},
+		{
+			name:          "proxy flag",
+			proxyURL:      "http://flag.example.com:8080",
+			proxy:         &types.Proxy{HTTPProxy: "http://proxy.example.com:3128", NoProxy: "198.51.100.0/24"},
+			ip:            "198.51.100.10",
+			expectedProxy: "http://flag.example.com:8080",
+		},
+		{
+			name:     "proxy flag with NO_PROXY",
+			proxyURL: "http://flag.example.com:8080",
+			ip:       "192.0.2.10",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rest := &NodeZeroRestClient{}
+			require.NoError(t, rest.setProxy(tc.proxyURL, tc.proxy))
+			rest.setHost(context.Background(), tc.ip)
+			assert.Equal(t, "http", rest.config.URL.Scheme)
+			if tc.proxyURL == "" && tc.proxy == nil {
+				assert.Nil(t, rest.transport)
+				return
+			}
+			req, err := http.NewRequest(http.MethodGet, rest.config.URL.String(), nil)
+			require.NoError(t, err)
+			proxyURL, err := rest.transport.(*http.Transport).Proxy(req)
+			require.NoError(t, err)
+			if tc.expectedProxy == "" {
+				assert.Nil(t, proxyURL)
+				return
+			}
+			assert.Equal(t, tc.expectedProxy, proxyURL.String())
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// BootArtifactsDirectory is the directory of the assets directory holding the
+// PXE boot artifacts created by agent create pxe-files.
+const BootArtifactsDirectory = "boot-artifacts"
+
+// ServeOptions are the options of the HTTP server of the PXE boot artifacts.
+type ServeOptions struct {
+	// Address is the address the server listens on, as [host]:port.
+	Address string
+	// CertFile and KeyFile are the paths of the TLS certificate and key of
+	// the server. The server is served over plain HTTP when they are empty.
+	CertFile string
+	KeyFile  string
+}
+
+// ServePXEArtifacts serves the PXE boot artifacts of the assets directory, the
+// kernel, initrd, rootfs and iPXE script, over HTTP until the context is done.
+func ServePXEArtifacts(ctx context.Context, assetDir string, opts ServeOptions) error {
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return errors.New("both the TLS certificate and key are required to serve over HTTPS")
+	}
+	directory := filepath.Join(assetDir, BootArtifactsDirectory)
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the PXE boot artifacts, run agent create pxe-files first")
+	}
+	scheme := "http"
+	if opts.CertFile != "" {
+		scheme = "https"
+	}
+	hasScript := false
+	for _, entry := range entries {
+		logrus.Infof("Serving %s://%s/%s", scheme, opts.Address, entry.Name())
+		if strings.HasSuffix(entry.Name(), ".ipxe") {
+			hasScript = true
+		}
+	}
+	if !hasScript {
+		logrus.Warnf("No iPXE script was created, set bootArtifactsBaseURL in agent-config.yaml to %s://<address of this host>%s and run agent create pxe-files again to create it", scheme, portSuffix(opts.Address))
+	}
+
+	listener, err := net.Listen("tcp", opts.Address)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", opts.Address)
+	}
+	server := &http.Server{
+		Handler:           newPXEHandler(directory),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		if opts.CertFile != "" {
+			errCh <- server.ServeTLS(listener, opts.CertFile, opts.KeyFile)
+		} else {
+			errCh <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return errors.Wrap(err, "failed to serve the PXE boot artifacts")
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// newPXEHandler returns the handler serving the files of the directory, and
+// logging the requests, for the users to follow the boot of the hosts.
+func newPXEHandler(directory string) http.Handler {
+	files := http.FileServer(http.Dir(directory))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		logrus.Infof("%s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		if strings.HasSuffix(r.URL.Path, ".ipxe") {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// portSuffix returns the :port suffix of the address.
+func portSuffix(address string) string {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil || port == "" {
+		return ""
+	}
+	return ":" + port
+}
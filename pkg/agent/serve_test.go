@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPXEHandler(t *testing.T) {
+	directory := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "agent.x86_64.ipxe"), []byte("#!ipxe\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "agent.x86_64-vmlinuz"), []byte("kernel"), 0600))
+	server := httptest.NewServer(newPXEHandler(directory))
+	defer server.Close()
+
+	cases := []struct {
+		name                string
+		method              string
+		path                string
+		expectedStatus      int
+		expectedBody        string
+		expectedContentType string
+	}{
+		{
+			name:                "iPXE script",
+			method:              http.MethodGet,
+			path:                "/agent.x86_64.ipxe",
+			expectedStatus:      http.StatusOK,
+			expectedBody:        "#!ipxe\n",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:           "kernel",
+			method:         http.MethodGet,
+			path:           "/agent.x86_64-vmlinuz",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "kernel",
+		},
+		{
+			name:           "missing",
+			method:         http.MethodGet,
+			path:           "/agent.x86_64-rootfs.img",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "outside of the directory",
+			method:         http.MethodGet,
+			path:           "/../serve_test.go",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "upload",
+			method:         http.MethodPut,
+			path:           "/agent.x86_64-vmlinuz",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, err := http.NewRequest(tc.method, server.URL+tc.path, nil)
+			require.NoError(t, err)
+			response, err := http.DefaultClient.Do(request)
+			require.NoError(t, err)
+			defer response.Body.Close()
+			assert.Equal(t, tc.expectedStatus, response.StatusCode)
+			if tc.expectedBody != "" {
+				body, err := io.ReadAll(response.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedBody, string(body))
+			}
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, response.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestServePXEArtifacts(t *testing.T) {
+	cases := []struct {
+		name          string
+		artifacts     bool
+		opts          ServeOptions
+		expectedError string
+	}{
+		{
+			name:      "stops with the context",
+			artifacts: true,
+			opts:      ServeOptions{Address: "127.0.0.1:0"},
+		},
+		{
+			name:          "no artifacts",
+			opts:          ServeOptions{Address: "127.0.0.1:0"},
+			expectedError: "failed to read the PXE boot artifacts, run agent create pxe-files first",
+		},
+		{
+			name:          "certificate without key",
+			artifacts:     true,
+			opts:          ServeOptions{Address: "127.0.0.1:0", CertFile: "tls.crt"},
+			expectedError: "both the TLS certificate and key are required to serve over HTTPS",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assetDir := t.TempDir()
+			if tc.artifacts {
+				require.NoError(t, os.Mkdir(filepath.Join(assetDir, BootArtifactsDirectory), 0755))
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := ServePXEArtifacts(ctx, assetDir, tc.opts)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
+}
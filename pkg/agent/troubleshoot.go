@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/openshift/assisted-service/client/installer"
+	"github.com/openshift/assisted-service/models"
+)
+
+// clusterRemediations are the remediations of the failed validations of the
+// cluster, by validation ID.
+var clusterRemediations = map[models.ClusterValidationID]string{
+	models.ClusterValidationIDMachineCidrDefined:                "Set networking.machineNetwork in install-config.yaml to the network of the hosts.",
+	models.ClusterValidationIDNoCidrsOverlapping:                "Change networking.machineNetwork, clusterNetwork or serviceNetwork in install-config.yaml so that they don't overlap.",
+	models.ClusterValidationIDNetworksSameAddressFamilies:       "List the clusterNetwork, serviceNetwork and machineNetwork of install-config.yaml with the same IP families, in the same order.",
+	models.ClusterValidationIDMachineCidrEqualsToCalculatedCidr: "Set networking.machineNetwork in install-config.yaml to the network of the API and ingress VIPs.",
+	models.ClusterValidationIDAPIVipsValid:                      "Set the apiVIPs of install-config.yaml to free IPs of the machine network, not used by any host.",
+	models.ClusterValidationIDIngressVipsValid:                  "Set the ingressVIPs of install-config.yaml to free IPs of the machine network, not used by any host.",
+	models.ClusterValidationIDAllHostsAreReadyToInstall:         "Fix the failed validations of the hosts below.",
+	models.ClusterValidationIDSufficientMastersCount:            "Boot the missing hosts on the agent ISO, the number of control plane and compute hosts must match the replicas of install-config.yaml, and the hosts with a role in agent-config.yaml must match them.",
+	models.ClusterValidationIDNtpServerConfigured:               "Add NTP servers reachable by the hosts to additionalNTPSources in agent-config.yaml.",
+	models.ClusterValidationIDPlatformRequirementsSatisfied:     "Check the platform of install-config.yaml, e.g. the vCenter credentials of the vsphere platform.",
+}
+
+// hostRemediations are the remediations of the failed validations of the
+// hosts, by validation ID.
+var hostRemediations = map[models.HostValidationID]string{
+	models.HostValidationIDConnected:                                  "Check that the host reaches the rendezvous host, TCP ports 8090 (Agent Rest API) and 8888 (image service) must be open in the firewalls between them.",
+	models.HostValidationIDMediaConnected:                             "Check that the agent ISO stays mounted on the host, e.g. that the virtual media of its BMC is not disconnected.",
+	models.HostValidationIDHasMinCPUCores:                             "Add CPU cores to the host, or check its hardware in agent-config.yaml.",
+	models.HostValidationIDHasMinMemory:                               "Add memory to the host, or check its hardware in agent-config.yaml.",
+	models.HostValidationIDHasCPUCoresForRole:                         "Add CPU cores to the host, or give it a role requiring fewer, e.g. worker, in agent-config.yaml.",
+	models.HostValidationIDHasMemoryForRole:                           "Add memory to the host, or give it a role requiring less, e.g. worker, in agent-config.yaml.",
+	models.HostValidationIDHasMinValidDisks:                           "Attach an eligible disk of at least 100GB to the host, or select it with the rootDeviceHints of the host in agent-config.yaml.",
+	models.HostValidationIDNoSkipInstallationDisk:                     "Select an installation disk that is not skipped with the rootDeviceHints of the host in agent-config.yaml.",
+	models.HostValidationIDNoSkipMissingDisk:                          "Attach the disk skipped for formatting again, or remove it from the disks to skip.",
+	models.HostValidationIDSufficientInstallationDiskSpeed:            "Select a faster installation disk, e.g. an SSD, with the rootDeviceHints of the host in agent-config.yaml.",
+	models.HostValidationIDHostnameUnique:                             "Give the host a unique hostname in agent-config.yaml or through DHCP.",
+	models.HostValidationIDHostnameValid:                              "Give the host a valid hostname in agent-config.yaml or through DHCP, e.g. not localhost.",
+	models.HostValidationIDBelongsToMachineCidr:                       "Configure an IP of the machine network of install-config.yaml on the host, with the networkConfig of the host in agent-config.yaml or through DHCP.",
+	models.HostValidationIDBelongsToMajorityGroup:                     "Check the connectivity of the host with the other hosts on the machine network, e.g. their VLAN and firewall rules.",
+	models.HostValidationIDNtpSynced:                                  "Add NTP servers reachable by the host to additionalNTPSources in agent-config.yaml, UDP port 123 must be open to them.",
+	models.HostValidationIDTimeSyncedBetweenHostAndService:            "Set the clock of the host, e.g. in its BIOS, or synchronize it with NTP servers in additionalNTPSources of agent-config.yaml.",
+	models.HostValidationIDContainerImagesAvailable:                   "Check that the host pulls the release images, the release registry or the mirror of imageDigestSources in install-config.yaml must be reachable.",
+	models.HostValidationIDHasDefaultRoute:                            "Configure a default route on the host, with the routes of the networkConfig of the host in agent-config.yaml or through DHCP.",
+	models.HostValidationIDAPIDomainNameResolvedCorrectly:             "Add a DNS record of api.<cluster name>.<base domain> to the API VIP, or check the DNS servers of the host.",
+	models.HostValidationIDAPIIntDomainNameResolvedCorrectly:          "Add a DNS record of api-int.<cluster name>.<base domain> to the API VIP, or check the DNS servers of the host.",
+	models.HostValidationIDAppsDomainNameResolvedCorrectly:            "Add a wildcard DNS record of *.apps.<cluster name>.<base domain> to the ingress VIP, or check the DNS servers of the host.",
+	models.HostValidationIDReleaseDomainNameResolvedCorrectly:         "Check that the DNS servers of the host resolve the registry of the release image.",
+	models.HostValidationIDDNSWildcardNotConfigured:                   "Remove the DNS wildcard record of *.<cluster name>.<base domain>, which must not resolve.",
+	models.HostValidationIDNonOverlappingSubnets:                      "Configure the interfaces of the host on distinct subnets in the networkConfig of the host in agent-config.yaml.",
+	models.HostValidationIDNoIPCollisionsInNetwork:                    "Change the IP of the host, or of the other machine using it, in the networkConfig of the host in agent-config.yaml.",
+	models.HostValidationIDSufficientNetworkLatencyRequirementForRole: "Reduce the network latency between the host and the other hosts, e.g. by moving them to the same network.",
+	models.HostValidationIDSufficientPacketLossRequirementForRole:     "Fix the packet loss between the host and the other hosts, e.g. by checking the cables and switches.",
+	models.HostValidationIDValidPlatformNetworkSettings:               "Check that the platform of the host matches the one of install-config.yaml.",
+	models.HostValidationIDCompatibleWithClusterPlatform:              "Check that the platform of the host matches the one of install-config.yaml.",
+	models.HostValidationIDVsphereDiskUUIDEnabled:                     "Set disk.EnableUUID to TRUE in the advanced settings of the virtual machine of the host.",
+	models.HostValidationIDIgnitionDownloadable:                       "Check that the host reaches the API VIP on TCP port 22623 to download its ignition.",
+}
+
+// ValidationFailure is a failed validation of the cluster or of a host, with
+// its remediation.
+type ValidationFailure struct {
+	// Hostname is the hostname of the host, empty for the cluster.
+	Hostname string `json:"hostname,omitempty"`
+	ValidationResult
+	// Remediation is how to fix the failure, empty when unknown.
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// TroubleshootReport is the report of the failed validations of the cluster
+// and of its hosts.
+type TroubleshootReport struct {
+	ClusterStatus string              `json:"clusterStatus"`
+	Failures      []ValidationFailure `json:"failures"`
+}
+
+// Troubleshoot writes the failed validations of the cluster and of its hosts,
+// from the Agent Rest API of the rendezvous host of the assets directory,
+// with how to fix them. The report is written as JSON with asJSON.
+func Troubleshoot(ctx context.Context, assetDir string, w io.Writer, asJSON bool) error {
+	rest, err := NewNodeZeroRestClient(ctx, assetDir)
+	if err != nil {
+		return err
+	}
+	if !rest.IsRestAPILive() {
+		return errors.Errorf("the Agent Rest API on the rendezvous host %s is not reachable", rest.NodeZeroIP)
+	}
+	report, err := troubleshoot(ctx, rest)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the failed validations")
+		}
+		_, err = w.Write(append(data, '\n'))
+		return errors.Wrap(err, "failed to write the failed validations")
+	}
+	return writeTroubleshootReport(w, report)
+}
+
+func troubleshoot(ctx context.Context, rest *NodeZeroRestClient) (*TroubleshootReport, error) {
+	clusterID, err := rest.getClusterID()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve clusterID from Agent Rest API")
+	}
+	if clusterID == nil {
+		return nil, errors.New("the cluster is not registered in the Agent Rest API")
+	}
+	result, err := rest.Client.Installer.V2GetCluster(ctx, &installer.V2GetClusterParams{ClusterID: *clusterID})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve cluster metadata from Agent Rest API")
+	}
+	validations, err := newValidationReport(result.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TroubleshootReport{
+		ClusterStatus: validations.ClusterStatus,
+		Failures:      []ValidationFailure{},
+	}
+	for _, r := range validations.Cluster {
+		if r.Status == validationFailure || r.Status == validationError {
+			report.Failures = append(report.Failures, ValidationFailure{
+				ValidationResult: r,
+				Remediation:      clusterRemediations[models.ClusterValidationID(r.ID)],
+			})
+		}
+	}
+	for _, host := range validations.Hosts {
+		hostname := host.Hostname
+		if hostname == "" {
+			hostname = host.ID
+		}
+		for _, r := range host.Validations {
+			if r.Status == validationFailure || r.Status == validationError {
+				report.Failures = append(report.Failures, ValidationFailure{
+					Hostname:         hostname,
+					ValidationResult: r,
+					Remediation:      hostRemediations[models.HostValidationID(r.ID)],
+				})
+			}
+		}
+	}
+	return report, nil
+}
+
+// writeTroubleshootReport writes the failed validations with their
+// remediations, for the cluster and then for each host.
+func writeTroubleshootReport(w io.Writer, report *TroubleshootReport) error {
+	var err error
+	if len(report.Failures) == 0 {
+		_, err = fmt.Fprintf(w, "No failed validations, the cluster is %s\n", report.ClusterStatus)
+		return errors.Wrap(err, "failed to write the failed validations")
+	}
+	for _, failure := range report.Failures {
+		subject := "Cluster"
+		if failure.Hostname != "" {
+			subject = "Host " + failure.Hostname
+		}
+		remediation := failure.Remediation
+		if remediation == "" {
+			remediation = "No known remediation, see the message of the validation."
+		}
+		if _, err = fmt.Fprintf(w, "%s: %s: %s\n  Remediation: %s\n", subject, failure.ID, failure.Message, remediation); err != nil {
+			return errors.Wrap(err, "failed to write the failed validations")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/assisted-service/client"
+	"github.com/openshift/assisted-service/models"
+)
+
+func TestTroubleshoot(t *testing.T) {
+	clusterID := strfmt.UUID("0b5a3c6e-7d0f-4a2b-8c1d-9e8f7a6b5c4d")
+	cluster := &models.Cluster{
+		ID:              &clusterID,
+		Status:          swag.String(models.ClusterStatusInsufficient),
+		ValidationsInfo: `{"hosts-data":[{"id":"all-hosts-are-ready-to-install","status":"failure","message":"The cluster has hosts that are not ready to install."}]}`,
+		Hosts: []*models.Host{
+			{
+				RequestedHostname: "master-1",
+				Status:            swag.String(models.HostStatusInsufficient),
+				ValidationsInfo:   `{"network":[{"id":"ntp-synced","status":"failure","message":"Host couldn't synchronize with any NTP server"},{"id":"connected","status":"success","message":"Host is connected"}],"hardware":[{"id":"custom-check","status":"error","message":"Custom check failed"}]}`,
+			},
+			{
+				RequestedHostname: "master-0",
+				Status:            swag.String(models.HostStatusKnown),
+				ValidationsInfo:   `{"network":[{"id":"ntp-synced","status":"success","message":"Host NTP is synced"}]}`,
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, client.DefaultBasePath)
+		w.Header().Set("Content-Type", "application/json")
+		var payload interface{}
+		switch path {
+		case "/v2/clusters":
+			payload = []*models.Cluster{cluster}
+		case "/v2/clusters/" + clusterID.String():
+			payload = cluster
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(payload) //nolint:errcheck
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	rest := newHostRestClient(context.Background(), serverURL.Hostname())
+	rest.config.URL.Host = serverURL.Host
+	rest.Client = client.New(rest.config)
+
+	report, err := troubleshoot(context.Background(), rest)
+	require.NoError(t, err)
+	assert.Equal(t, models.ClusterStatusInsufficient, report.ClusterStatus)
+	require.Len(t, report.Failures, 3)
+	assert.Equal(t, "", report.Failures[0].Hostname)
+	assert.Equal(t, "all-hosts-are-ready-to-install", report.Failures[0].ID)
+	assert.Equal(t, "master-1", report.Failures[1].Hostname)
+	assert.Equal(t, "custom-check", report.Failures[1].ID)
+	assert.Empty(t, report.Failures[1].Remediation)
+	assert.Equal(t, "ntp-synced", report.Failures[2].ID)
+	assert.Equal(t, hostRemediations[models.HostValidationIDNtpSynced], report.Failures[2].Remediation)
+
+	var out bytes.Buffer
+	require.NoError(t, writeTroubleshootReport(&out, report))
+	assert.Equal(t, `Cluster: all-hosts-are-ready-to-install: The cluster has hosts that are not ready to install.
+  Remediation: Fix the failed validations of the hosts below.
+Host master-1: custom-check: Custom check failed
+  Remediation: No known remediation, see the message of the validation.
+Host master-1: ntp-synced: Host couldn't synchronize with any NTP server
+  Remediation: Add NTP servers reachable by the host to additionalNTPSources in agent-config.yaml, UDP port 123 must be open to them.
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeTroubleshootReport(&out, &TroubleshootReport{ClusterStatus: models.ClusterStatusReady}))
+	assert.Equal(t, "No failed validations, the cluster is ready\n", out.String())
+}
@@ -2,6 +2,10 @@ package agent
 
 import (
 	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -116,3 +120,115 @@ func logValidationHistory(logPrefix string, history *validationResultHistory, lo
 		}
 	}
 }
+
+// ValidationReport is the structured report of the validations of the
+// cluster and of its hosts, from the Agent Rest API.
+type ValidationReport struct {
+	// Time is when the validations were retrieved.
+	Time time.Time `json:"time"`
+	// ClusterStatus is the status of the cluster.
+	ClusterStatus string `json:"clusterStatus"`
+	// Cluster are the validations of the cluster.
+	Cluster []ValidationResult `json:"cluster"`
+	// Hosts are the validations of the hosts, sorted by hostname.
+	Hosts []HostValidationReport `json:"hosts"`
+}
+
+// HostValidationReport is the report of the validations of a host.
+type HostValidationReport struct {
+	Hostname    string             `json:"hostname"`
+	ID          string             `json:"id"`
+	Status      string             `json:"status"`
+	Validations []ValidationResult `json:"validations"`
+}
+
+// ValidationResult is the result of a validation, e.g. the NTP
+// synchronization, the size of the disks or the connectivity of a host.
+type ValidationResult struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+}
+
+// newValidationReport returns the report of the validations of the cluster.
+func newValidationReport(cluster *models.Cluster) (*ValidationReport, error) {
+	report := &ValidationReport{
+		Time:  time.Now().UTC(),
+		Hosts: []HostValidationReport{},
+	}
+	if cluster.Status != nil {
+		report.ClusterStatus = *cluster.Status
+	}
+	var err error
+	report.Cluster, err = parseValidationsInfo(cluster.ValidationsInfo)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range cluster.Hosts {
+		host := HostValidationReport{
+			Hostname: h.RequestedHostname,
+		}
+		if h.ID != nil {
+			host.ID = h.ID.String()
+		}
+		if h.Status != nil {
+			host.Status = *h.Status
+		}
+		host.Validations, err = parseValidationsInfo(h.ValidationsInfo)
+		if err != nil {
+			return nil, err
+		}
+		report.Hosts = append(report.Hosts, host)
+	}
+	sort.Slice(report.Hosts, func(i, j int) bool {
+		return report.Hosts[i].Hostname < report.Hosts[j].Hostname
+	})
+	return report, nil
+}
+
+func parseValidationsInfo(validationsInfoString string) ([]ValidationResult, error) {
+	results := []ValidationResult{}
+	if validationsInfoString == "" {
+		return results, nil
+	}
+	validationsInfo := common.ValidationsStatus{}
+	if err := json.Unmarshal([]byte(validationsInfoString), &validationsInfo); err != nil {
+		return nil, errors.Wrap(err, "unable to verify validations")
+	}
+	for category, validationResults := range validationsInfo {
+		for _, r := range validationResults {
+			results = append(results, ValidationResult{
+				ID:       r.ID,
+				Category: category,
+				Status:   r.Status,
+				Message:  r.Message,
+			})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Category != results[j].Category {
+			return results[i].Category < results[j].Category
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results, nil
+}
+
+// writeValidationReport writes the report as a line of JSON to the writer,
+// unless the validations are the same as in the previous report, which is
+// returned.
+func writeValidationReport(w io.Writer, report, previous *ValidationReport) (*ValidationReport, error) {
+	if previous != nil && previous.ClusterStatus == report.ClusterStatus &&
+		reflect.DeepEqual(previous.Cluster, report.Cluster) && reflect.DeepEqual(previous.Hosts, report.Hosts) {
+		return previous, nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return previous, errors.Wrap(err, "failed to marshal the validations")
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return previous, errors.Wrap(err, "failed to write the validations")
+	}
+	return report, nil
+}
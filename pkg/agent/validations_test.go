@@ -1,12 +1,19 @@
 package agent
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"testing"
 
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/assisted-service/models"
 )
 
 // ValidationHistory test helpers
@@ -311,3 +318,63 @@ func TestLogValidationHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteValidationReport(t *testing.T) {
+	hostID := strfmt.UUID("3b8c2f0e-1d4a-4c5e-9f6b-7a8d9e0f1a2b")
+	cluster := &models.Cluster{
+		Status:          swag.String(models.ClusterStatusInsufficient),
+		ValidationsInfo: `{"hosts-data":[{"id":"all-hosts-are-ready-to-install","status":"failure","message":"The cluster has hosts that are not ready to install."}]}`,
+		Hosts: []*models.Host{
+			{
+				ID:                &hostID,
+				RequestedHostname: "master-1",
+				Status:            swag.String(models.HostStatusInsufficient),
+				ValidationsInfo:   `{"network":[{"id":"ntp-synced","status":"failure","message":"Host couldn't synchronize with any NTP server"},{"id":"connected","status":"success","message":"Host is connected"}],"hardware":[{"id":"has-min-valid-disks","status":"success","message":"Sufficient disk capacity"}]}`,
+			},
+			{
+				RequestedHostname: "master-0",
+				Status:            swag.String(models.HostStatusKnown),
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	report, err := newValidationReport(cluster)
+	require.NoError(t, err)
+	previous, err := writeValidationReport(&out, report, nil)
+	require.NoError(t, err)
+	assert.Equal(t, report, previous)
+
+	var written ValidationReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &written))
+	assert.Equal(t, models.ClusterStatusInsufficient, written.ClusterStatus)
+	assert.Equal(t, []ValidationResult{
+		{ID: "all-hosts-are-ready-to-install", Category: "hosts-data", Status: "failure", Message: "The cluster has hosts that are not ready to install."},
+	}, written.Cluster)
+	require.Len(t, written.Hosts, 2)
+	assert.Equal(t, HostValidationReport{Hostname: "master-0", Status: models.HostStatusKnown, Validations: []ValidationResult{}}, written.Hosts[0])
+	assert.Equal(t, HostValidationReport{
+		Hostname: "master-1",
+		ID:       hostID.String(),
+		Status:   models.HostStatusInsufficient,
+		Validations: []ValidationResult{
+			{ID: "has-min-valid-disks", Category: "hardware", Status: "success", Message: "Sufficient disk capacity"},
+			{ID: "connected", Category: "network", Status: "success", Message: "Host is connected"},
+			{ID: "ntp-synced", Category: "network", Status: "failure", Message: "Host couldn't synchronize with any NTP server"},
+		},
+	}, written.Hosts[1])
+
+	// The same validations are not written again.
+	report, err = newValidationReport(cluster)
+	require.NoError(t, err)
+	_, err = writeValidationReport(&out, report, previous)
+	require.NoError(t, err)
+	assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte("\n")))
+
+	cluster.Hosts[0].ValidationsInfo = `{"network":[{"id":"ntp-synced","status":"success","message":"Host NTP is synced"}]}`
+	report, err = newValidationReport(cluster)
+	require.NoError(t, err)
+	_, err = writeValidationReport(&out, report, previous)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(out.Bytes(), []byte("\n")))
+}
@@ -6,70 +6,114 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/installer/pkg/poll"
 )
 
+const (
+	// DefaultBootstrapTimeout is how long WaitForBootstrapComplete waits when
+	// no timeout is given.
+	DefaultBootstrapTimeout = 60 * time.Minute
+
+	// DefaultInstallTimeout is how long WaitForInstallComplete waits when no
+	// timeout is given.
+	DefaultInstallTimeout = 90 * time.Minute
+)
+
+// waitTimeout returns the timeout of a wait, or its default timeout when the
+// timeout is zero.
+func waitTimeout(timeout, defaultTimeout time.Duration) time.Duration {
+	if timeout == 0 {
+		return defaultTimeout
+	}
+	return timeout
+}
+
+// TimeLeft returns the timeout of a wait ending at the deadline, so that
+// several waits given the same deadline are bounded as a whole, or zero, i.e.
+// the default timeout of the wait, when the deadline is the zero time.
+func TimeLeft(deadline time.Time) time.Duration {
+	if deadline.IsZero() {
+		return 0
+	}
+	if left := time.Until(deadline); left > 0 {
+		return left
+	}
+	// The deadline has passed: a zero timeout would wait for the default.
+	return time.Nanosecond
+}
+
 // WaitForBootstrapComplete Wait for the bootstrap process to complete on
-// cluster installations triggered by the agent installer.
-func WaitForBootstrapComplete(cluster *Cluster) error {
-	timeout := 60 * time.Minute
-	waitContext, cancel := context.WithTimeout(cluster.Ctx, timeout)
+// cluster installations triggered by the agent installer. A zero timeout
+// waits for DefaultBootstrapTimeout.
+func WaitForBootstrapComplete(cluster *Cluster, timeout time.Duration) error {
+	waitContext, cancel := context.WithTimeout(cluster.Ctx, waitTimeout(timeout, DefaultBootstrapTimeout))
 	defer cancel()
 
 	var lastErrOnExit error
 	var lastErrStr string
-	wait.Until(func() {
+	waitErr := poll.Until(waitContext, 2*time.Second, func(ctx context.Context) (bool, error) {
 		bootstrap, exitOnErr, err := cluster.IsBootstrapComplete()
 		if bootstrap && err == nil {
 			logrus.Info("cluster bootstrap is complete")
-			cancel()
+			return true, nil
 		}
 
 		if err != nil {
 			if exitOnErr {
 				lastErrOnExit = err
-				cancel()
-			} else {
-				if err.Error() != lastErrStr {
-					logrus.Info(err)
-					lastErrStr = err.Error()
-				}
+				return true, nil
+			}
+			if err.Error() != lastErrStr {
+				logrus.Info(err)
+				lastErrStr = err.Error()
+			}
+			if poll.IsTransient(err) {
+				return false, err
 			}
 		}
-	}, 2*time.Second, waitContext.Done())
+		return false, nil
+	})
 
-	waitErr := waitContext.Err()
+	if lastErrOnExit != nil {
+		return errors.Wrap(lastErrOnExit, "bootstrap process returned error")
+	}
 	if waitErr != nil {
-		if errors.Is(waitErr, context.Canceled) && lastErrOnExit != nil {
-			return errors.Wrap(lastErrOnExit, "bootstrap process returned error")
-		}
 		if errors.Is(waitErr, context.DeadlineExceeded) {
 			return errors.Wrap(waitErr, "bootstrap process timed out")
 		}
+		if !errors.Is(waitErr, context.Canceled) {
+			return errors.Wrap(waitErr, "bootstrap process returned error")
+		}
 	}
 
 	return nil
 }
 
 // WaitForInstallComplete Waits for the cluster installation triggered by the
-// agent installer to be complete.
-func WaitForInstallComplete(cluster *Cluster) error {
-	timeout := 90 * time.Minute
-	waitContext, cancel := context.WithTimeout(cluster.Ctx, timeout)
+// agent installer to be complete. A zero timeout waits for
+// DefaultInstallTimeout.
+func WaitForInstallComplete(cluster *Cluster, timeout time.Duration) error {
+	waitContext, cancel := context.WithTimeout(cluster.Ctx, waitTimeout(timeout, DefaultInstallTimeout))
 	defer cancel()
 
-	wait.Until(func() {
+	waitErr := poll.Until(waitContext, 2*time.Second, func(ctx context.Context) (bool, error) {
 		installed, err := cluster.IsInstallComplete()
 		if installed && err == nil {
 			logrus.Info("Cluster is installed")
-			cancel()
+			return true, nil
 		}
+		if err != nil && poll.IsTransient(err) {
+			return false, err
+		}
+		return false, nil
+	})
 
-	}, 2*time.Second, waitContext.Done())
-
-	waitErr := waitContext.Err()
 	if waitErr != nil && waitErr != context.Canceled {
-		return errors.Wrap(waitErr, "Cluster installation timed out")
+		if errors.Is(waitErr, context.DeadlineExceeded) {
+			return errors.Wrap(waitErr, "Cluster installation timed out")
+		}
+		return errors.Wrap(waitErr, "Cluster installation failed")
 	}
 	return nil
 }
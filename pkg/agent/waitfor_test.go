@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitTimeout(t *testing.T) {
+	cases := []struct {
+		name     string
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "zero timeout keeps the default",
+			expected: DefaultBootstrapTimeout,
+		},
+		{
+			name:     "shorter timeout overrides the default",
+			timeout:  10 * time.Minute,
+			expected: 10 * time.Minute,
+		},
+		{
+			name:     "longer timeout overrides the default",
+			timeout:  3 * time.Hour,
+			expected: 3 * time.Hour,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, waitTimeout(tc.timeout, DefaultBootstrapTimeout))
+		})
+	}
+}
+
+func TestTimeLeft(t *testing.T) {
+	cases := []struct {
+		name     string
+		deadline time.Time
+		expected time.Duration
+	}{
+		{
+			name:     "no deadline",
+			expected: 0,
+		},
+		{
+			name:     "deadline ahead",
+			deadline: time.Now().Add(30 * time.Minute),
+			expected: 30 * time.Minute,
+		},
+		{
+			name:     "deadline mostly consumed by the earlier waits",
+			deadline: time.Now().Add(time.Minute),
+			expected: time.Minute,
+		},
+		{
+			name:     "deadline passed",
+			deadline: time.Now().Add(-time.Minute),
+			expected: time.Nanosecond,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, float64(tc.expected), float64(TimeLeft(tc.deadline)), float64(time.Second))
+			if !tc.deadline.IsZero() {
+				assert.NotZero(t, TimeLeft(tc.deadline), "a deadline never falls back to the default timeout")
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	destroybaremetal "github.com/openshift/installer/pkg/destroy/baremetal"
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+// WipeHosts securely erases the drives of the hosts of the agent-config of
+// the assets directory with a BMC, and powers them off, so that the stale
+// RAID and partition signatures of the cluster do not break re-installing
+// the hosts. Only the BMCs speaking Redfish are supported.
+func WipeHosts(ctx context.Context, assetDir string) error {
+	config, err := loadAgentConfigHosts(assetDir)
+	if err != nil {
+		return err
+	}
+
+	var hosts []*baremetal.Host
+	for i, host := range config.Hosts {
+		if host.BMC == nil {
+			continue
+		}
+		name := host.Hostname
+		if name == "" {
+			name = fmt.Sprintf("host-%d", i)
+		}
+		hosts = append(hosts, &baremetal.Host{Name: name, BMC: *host.BMC})
+	}
+	if len(hosts) == 0 {
+		return errors.New("no host of the agent config has a BMC")
+	}
+	return destroybaremetal.WipeHosts(ctx, logrus.StandardLogger(), hosts)
+}
@@ -1,18 +1,27 @@
 package agentconfig
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/coreos/go-semver/semver"
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/yaml"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/bmc"
 	"github.com/openshift/installer/pkg/types/agent"
 	"github.com/openshift/installer/pkg/types/agent/conversion"
+	"github.com/openshift/installer/pkg/types/baremetal"
 	"github.com/openshift/installer/pkg/types/baremetal/validation"
 	"github.com/openshift/installer/pkg/validate"
 )
@@ -184,6 +193,14 @@ func (a *AgentConfig) validateAgent() field.ErrorList {
 		allErrs = append(allErrs, err...)
 	}
 
+	if err := a.validateISOCustomization(); err != nil {
+		allErrs = append(allErrs, err...)
+	}
+
+	if err := a.validateRendezvousDiscovery(); err != nil {
+		allErrs = append(allErrs, err...)
+	}
+
 	return allErrs
 }
 
@@ -223,6 +240,131 @@ func (a *AgentConfig) validateHosts() field.ErrorList {
 		if err := a.validateRoles(hostPath, host); err != nil {
 			allErrs = append(allErrs, err...)
 		}
+
+		if err := a.validateHostIgnitionConfigOverride(hostPath, host); err != nil {
+			allErrs = append(allErrs, err...)
+		}
+
+		if err := a.validateHostBMC(hostPath, host); err != nil {
+			allErrs = append(allErrs, err...)
+		}
+
+		if err := a.validateHostHardware(hostPath, host); err != nil {
+			allErrs = append(allErrs, err...)
+		}
+
+		if err := a.validateHostNodeLabels(hostPath, host); err != nil {
+			allErrs = append(allErrs, err...)
+		}
+
+		if host.ISOCustomization != nil {
+			isoCustomizationPath := hostPath.Child("isoCustomization")
+			allErrs = append(allErrs, validateISOFiles(isoCustomizationPath.Child("files"), host.ISOCustomization.Files)...)
+			allErrs = append(allErrs, validateISOScripts(isoCustomizationPath.Child("scripts"), host.ISOCustomization.Scripts)...)
+			allErrs = append(allErrs, validateSerialConsole(isoCustomizationPath.Child("serialConsole"), host.ISOCustomization.SerialConsole)...)
+		}
+	}
+
+	return allErrs
+}
+
+func (a *AgentConfig) validateHostIgnitionConfigOverride(hostPath *field.Path, host agent.Host) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if host.IgnitionConfigOverride == "" {
+		return allErrs
+	}
+	fldPath := hostPath.Child("IgnitionConfigOverride")
+	var config igntypes.Config
+	if err := json.Unmarshal([]byte(host.IgnitionConfigOverride), &config); err != nil {
+		return append(allErrs, field.Invalid(fldPath, host.IgnitionConfigOverride, err.Error()))
+	}
+	version, err := semver.NewVersion(config.Ignition.Version)
+	if err != nil || version.Major != igntypes.MaxVersion.Major {
+		allErrs = append(allErrs, field.Invalid(fldPath, host.IgnitionConfigOverride, fmt.Sprintf("ignition version must be %d.x.0", igntypes.MaxVersion.Major)))
+	}
+
+	return allErrs
+}
+
+func (a *AgentConfig) validateHostBMC(hostPath *field.Path, host agent.Host) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if host.BMC == nil {
+		return allErrs
+	}
+	bmcPath := hostPath.Child("bmc")
+	if host.BMC.Address == "" {
+		return append(allErrs, field.Required(bmcPath.Child("address"), "BMC address is required"))
+	}
+	if _, _, err := bmc.RedfishClientForHost(&baremetal.Host{BMC: *host.BMC}); err != nil {
+		allErrs = append(allErrs, field.Invalid(bmcPath.Child("address"), host.BMC.Address, err.Error()))
+	}
+	if host.BMC.Username == "" {
+		allErrs = append(allErrs, field.Required(bmcPath.Child("username"), "BMC username is required"))
+	}
+	if host.BMC.Password == "" {
+		allErrs = append(allErrs, field.Required(bmcPath.Child("password"), "BMC password is required"))
+	}
+
+	return allErrs
+}
+
+func (a *AgentConfig) validateHostHardware(hostPath *field.Path, host agent.Host) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if host.Hardware == nil {
+		return allErrs
+	}
+	hardwarePath := hostPath.Child("hardware")
+	if host.Hardware.CPUCores < 0 {
+		allErrs = append(allErrs, field.Invalid(hardwarePath.Child("cpuCores"), host.Hardware.CPUCores, "must not be negative"))
+	}
+	if host.Hardware.MemoryGiB < 0 {
+		allErrs = append(allErrs, field.Invalid(hardwarePath.Child("memoryGiB"), host.Hardware.MemoryGiB, "must not be negative"))
+	}
+	if host.Hardware.DiskSizeGB < 0 {
+		allErrs = append(allErrs, field.Invalid(hardwarePath.Child("diskSizeGB"), host.Hardware.DiskSizeGB, "must not be negative"))
+	}
+
+	return allErrs
+}
+
+func (a *AgentConfig) validateHostNodeLabels(hostPath *field.Path, host agent.Host) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(host.NodeLabels) == 0 && len(host.NodeTaints) == 0 {
+		return allErrs
+	}
+
+	// The node of the host is registered with its hostname and role before
+	// the host joins the cluster.
+	if host.Hostname == "" {
+		allErrs = append(allErrs, field.Required(hostPath.Child("Hostname"), "hostname is required with node labels or taints"))
+	}
+	if host.Role == "" {
+		allErrs = append(allErrs, field.Required(hostPath.Child("Role"), "role is required with node labels or taints"))
+	}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(host.NodeLabels, hostPath.Child("nodeLabels"))...)
+
+	taintsPath := hostPath.Child("nodeTaints")
+	for i, taint := range host.NodeTaints {
+		taintPath := taintsPath.Index(i)
+		for _, msg := range k8svalidation.IsQualifiedName(taint.Key) {
+			allErrs = append(allErrs, field.Invalid(taintPath.Child("key"), taint.Key, msg))
+		}
+		if taint.Value != "" {
+			for _, msg := range k8svalidation.IsValidLabelValue(taint.Value) {
+				allErrs = append(allErrs, field.Invalid(taintPath.Child("value"), taint.Value, msg))
+			}
+		}
+		switch taint.Effect {
+		case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			allErrs = append(allErrs, field.NotSupported(taintPath.Child("effect"), taint.Effect,
+				[]string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}))
+		}
 	}
 
 	return allErrs
@@ -315,6 +457,34 @@ func (a *AgentConfig) validateRendezvousIPNotWorker(rendezvousIP string, hosts [
 	return allErrs
 }
 
+func (a *AgentConfig) validateRendezvousDiscovery() field.ErrorList {
+	var allErrs field.ErrorList
+
+	discovery := a.Config.RendezvousDiscovery
+	if discovery == nil {
+		return nil
+	}
+	discoveryPath := field.NewPath("rendezvousDiscovery")
+
+	if a.Config.RendezvousIP != "" {
+		allErrs = append(allErrs, field.Forbidden(discoveryPath, "the rendezvous host is discovered, rendezvousIP must not be set"))
+	}
+	if discovery.MACAddress != "" {
+		if err := validate.MAC(discovery.MACAddress); err != nil {
+			allErrs = append(allErrs, field.Invalid(discoveryPath.Child("macAddress"), discovery.MACAddress, err.Error()))
+		}
+	} else if RendezvousMACAddress(a.Config) == "" {
+		allErrs = append(allErrs, field.Required(discoveryPath.Child("macAddress"), "the MAC address of the rendezvous host is required when no control plane host has an interface"))
+	}
+	if discovery.Hostname != "" {
+		if err := validate.DomainName(discovery.Hostname, false); err != nil {
+			allErrs = append(allErrs, field.Invalid(discoveryPath.Child("hostname"), discovery.Hostname, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
 func (a *AgentConfig) validateBootArtifactsBaseURL() field.ErrorList {
 	var allErrs field.ErrorList
 
@@ -332,6 +502,138 @@ func (a *AgentConfig) validateBootArtifactsBaseURL() field.ErrorList {
 	return allErrs
 }
 
+func (a *AgentConfig) validateISOCustomization() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if a.Config.ISOCustomization == nil {
+		return nil
+	}
+	isoCustomizationPath := field.NewPath("isoCustomization")
+
+	for i, arg := range a.Config.ISOCustomization.KernelArguments {
+		argPath := isoCustomizationPath.Child("kernelArguments").Index(i)
+		switch {
+		case arg == "":
+			allErrs = append(allErrs, field.Required(argPath, "kernel argument must not be empty"))
+		case strings.ContainsAny(arg, " \t\n"):
+			allErrs = append(allErrs, field.Invalid(argPath, arg, "kernel argument must not contain whitespace"))
+		}
+	}
+
+	allErrs = append(allErrs, validateISOFiles(isoCustomizationPath.Child("files"), a.Config.ISOCustomization.Files)...)
+	allErrs = append(allErrs, validateISOScripts(isoCustomizationPath.Child("scripts"), a.Config.ISOCustomization.Scripts)...)
+	if a.Config.ISOCustomization.SerialConsole != nil {
+		allErrs = append(allErrs, validateSerialConsole(isoCustomizationPath.Child("serialConsole"), a.Config.ISOCustomization.SerialConsole)...)
+		allErrs = append(allErrs, validateSerialConsoleKernelArguments(isoCustomizationPath.Child("kernelArguments"), a.Config.ISOCustomization.KernelArguments)...)
+	}
+
+	return allErrs
+}
+
+func validateISOFiles(fldPath *field.Path, files []agent.ISOFile) field.ErrorList {
+	var allErrs field.ErrorList
+
+	paths := make(map[string]bool)
+	for i, file := range files {
+		pathPath := fldPath.Index(i).Child("path")
+		switch {
+		case file.Path == "":
+			allErrs = append(allErrs, field.Required(pathPath, "file path is required"))
+		case !filepath.IsAbs(file.Path) || filepath.Clean(file.Path) != file.Path:
+			allErrs = append(allErrs, field.Invalid(pathPath, file.Path, "file path must be absolute and clean"))
+		case paths[file.Path]:
+			allErrs = append(allErrs, field.Duplicate(pathPath, file.Path))
+		}
+		paths[file.Path] = true
+
+		if file.Mode != nil && (*file.Mode < 0 || *file.Mode > 0o7777) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("mode"), *file.Mode, "file mode must be between 0 and 07777"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateISOScripts(fldPath *field.Path, scripts []agent.ISOScript) field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := make(map[string]bool)
+	for i, script := range scripts {
+		namePath := fldPath.Index(i).Child("name")
+		switch {
+		case script.Name == "":
+			allErrs = append(allErrs, field.Required(namePath, "script name is required"))
+		case script.Name != filepath.Base(script.Name) || strings.HasPrefix(script.Name, "."):
+			allErrs = append(allErrs, field.Invalid(namePath, script.Name, "script name must be a file name"))
+		case names[script.Name]:
+			allErrs = append(allErrs, field.Duplicate(namePath, script.Name))
+		}
+		names[script.Name] = true
+
+		if !strings.HasPrefix(script.Contents, "#!") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("contents"), script.Name, "script must start with an interpreter directive, e.g. #!/bin/bash"))
+		}
+	}
+
+	return allErrs
+}
+
+// HostsData returns the data of the agent-config.yaml file installed on the
+// hosts, without the BMCs of the hosts and their credentials.
+func (a *AgentConfig) HostsData() ([]byte, error) {
+	hasBMC := false
+	for _, host := range a.Config.Hosts {
+		hasBMC = hasBMC || host.BMC != nil
+	}
+	if !hasBMC {
+		return a.File.Data, nil
+	}
+
+	config := *a.Config
+	config.Hosts = make([]agent.Host, len(a.Config.Hosts))
+	for i, host := range a.Config.Hosts {
+		host.BMC = nil
+		config.Hosts[i] = host
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal %s", agentConfigFilename)
+	}
+	return data, nil
+}
+
+// RendezvousMACAddress returns the MAC address of the interface of the
+// rendezvous host discovered by the hosts, either the one of the rendezvous
+// discovery or the lowest MAC address of the interfaces of the control plane
+// hosts, or of the hosts without a role when no host has the master role. It
+// returns an empty string when the rendezvous host can't be elected.
+func RendezvousMACAddress(config *agent.Config) string {
+	if config == nil || config.RendezvousDiscovery == nil {
+		return ""
+	}
+	if config.RendezvousDiscovery.MACAddress != "" {
+		return strings.ToLower(config.RendezvousDiscovery.MACAddress)
+	}
+	for _, role := range []string{"master", ""} {
+		macs := []string{}
+		for _, host := range config.Hosts {
+			if host.Role != role {
+				continue
+			}
+			for _, iface := range host.Interfaces {
+				if iface.MacAddress != "" {
+					macs = append(macs, strings.ToLower(iface.MacAddress))
+				}
+			}
+		}
+		if len(macs) > 0 {
+			sort.Strings(macs)
+			return macs[0]
+		}
+	}
+	return ""
+}
+
 // HostConfigFileMap is a map from a filepath ("<host>/<file>") to file content
 // for hostconfig files.
 type HostConfigFileMap map[string][]byte
@@ -370,10 +672,38 @@ func (a *AgentConfig) HostConfigFiles() (HostConfigFileMap, error) {
 		if len(host.Role) > 0 {
 			files[filepath.Join(name, "role")] = []byte(host.Role)
 		}
+
+		if host.IgnitionConfigOverride != "" {
+			files[filepath.Join(name, "ignition-config-override.json")] = []byte(host.IgnitionConfigOverride)
+		}
+
+		if console := a.hostSerialConsole(host); console != nil {
+			installerArgs := []string{}
+			for _, karg := range SerialConsoleKernelArguments(console) {
+				installerArgs = append(installerArgs, "--append-karg", karg)
+			}
+			data, err := json.Marshal(installerArgs)
+			if err != nil {
+				return nil, err
+			}
+			files[filepath.Join(name, "installer-args.json")] = data
+		}
 	}
 	return files, nil
 }
 
+// hostSerialConsole returns the serial console of the installed host, its own
+// or else the one shared by all the hosts.
+func (a *AgentConfig) hostSerialConsole(host agent.Host) *agent.SerialConsole {
+	if host.ISOCustomization != nil && host.ISOCustomization.SerialConsole != nil {
+		return host.ISOCustomization.SerialConsole
+	}
+	if a.Config.ISOCustomization != nil {
+		return a.Config.ISOCustomization.SerialConsole
+	}
+	return nil
+}
+
 func unmarshalJSON(b []byte) []byte {
 	output, _ := yaml.JSONToYAML(b)
 	return output
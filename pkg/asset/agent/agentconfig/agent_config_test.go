@@ -7,6 +7,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	aiv1beta1 "github.com/openshift/assisted-service/api/v1beta1"
@@ -281,6 +282,256 @@ hosts:
 			expectedFound: false,
 			expectedError: "invalid Agent Config configuration: Hosts[0].Host: Forbidden: host role has incorrect value. Role must either be 'master' or 'worker'",
 		},
+		{
+			name: "valid-iso-customization",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+isoCustomization:
+  kernelArguments:
+    - rd.debug
+  files:
+    - path: /etc/chrony.d/lab.conf
+      contents: server ntp.lab
+  scripts:
+    - name: firmware.sh
+      contents: |
+        #!/bin/bash
+        echo firmware
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    isoCustomization:
+      files:
+        - path: /etc/sysconfig/raid
+          contents: RAID=1
+          mode: 384`,
+			expectedFound: true,
+		},
+		{
+			name: "invalid-iso-customization",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+isoCustomization:
+  kernelArguments:
+    - rd.debug nomodeset
+  files:
+    - path: etc/chrony.d/lab.conf
+      contents: server ntp.lab
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    isoCustomization:
+      scripts:
+        - name: firmware.sh
+          contents: echo firmware`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: [Hosts[0].isoCustomization.scripts[0].contents: Invalid value: \"firmware.sh\": script must start with an interpreter directive, e.g. #!/bin/bash, isoCustomization.kernelArguments[0]: Invalid value: \"rd.debug nomodeset\": kernel argument must not contain whitespace, isoCustomization.files[0].path: Invalid value: \"etc/chrony.d/lab.conf\": file path must be absolute and clean]",
+		},
+		{
+			name: "serial-console",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+isoCustomization:
+  serialConsole:
+    device: ttyS1
+    baudRate: 9600
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    isoCustomization:
+      serialConsole:
+        device: ttyAMA0`,
+			expectedFound: true,
+		},
+		{
+			name: "invalid-serial-console",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+isoCustomization:
+  kernelArguments:
+    - console=ttyS0
+  serialConsole:
+    baudRate: 14400
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    isoCustomization:
+      serialConsole:
+        device: /dev/ttyS1`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: [Hosts[0].isoCustomization.serialConsole.device: Invalid value: \"/dev/ttyS1\": serial console device must be a tty or hvc device name, e.g. ttyS0, isoCustomization.serialConsole.baudRate: Unsupported value: 14400: supported values: \"9600\", \"19200\", \"38400\", \"57600\", \"115200\", isoCustomization.kernelArguments[0]: Invalid value: \"console=ttyS0\": console kernel arguments must not be set along with serialConsole]",
+		},
+		{
+			name: "node-labels-and-taints",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+hosts:
+  - hostname: worker-0
+    role: worker
+    interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    nodeLabels:
+      cluster.ocs.openshift.io/openshift-storage: ""
+    nodeTaints:
+      - key: node.ocs.openshift.io/storage
+        value: "true"
+        effect: NoSchedule`,
+			expectedFound: true,
+		},
+		{
+			name: "node-labels-without-hostname-and-role",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    nodeLabels:
+      cluster.ocs.openshift.io/openshift-storage: ""`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: [Hosts[0].Hostname: Required value: hostname is required with node labels or taints, Hosts[0].Role: Required value: role is required with node labels or taints]",
+		},
+		{
+			name: "invalid-node-labels-and-taints",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+hosts:
+  - hostname: worker-0
+    role: worker
+    interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    nodeLabels:
+      storage: "yes please"
+    nodeTaints:
+      - key: node.ocs.openshift.io/storage
+        effect: NoRun`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: [Hosts[0].nodeLabels: Invalid value: \"yes please\": a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?'), Hosts[0].nodeTaints[0].effect: Unsupported value: \"NoRun\": supported values: \"NoSchedule\", \"PreferNoSchedule\", \"NoExecute\"]",
+		},
+		{
+			name: "host-bmc",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    bmc:
+      address: redfish-virtualmedia://192.168.111.1:8000/redfish/v1/Systems/1
+      username: admin
+      password: password`,
+			expectedFound: true,
+		},
+		{
+			name: "invalid-host-bmc",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    bmc:
+      address: ipmi://192.168.111.1
+      username: admin`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: [Hosts[0].bmc.address: Invalid value: \"ipmi://192.168.111.1\": ipmi BMCs do not speak Redfish, Hosts[0].bmc.password: Required value: BMC password is required]",
+		},
+		{
+			name: "invalid-host-hardware",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    hardware:
+      cpuCores: 8
+      memoryGiB: -16`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: Hosts[0].hardware.memoryGiB: Invalid value: -16: must not be negative",
+		},
+		{
+			name: "rendezvous-discovery",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousDiscovery: {}
+hosts:
+  - role: master
+    interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a`,
+			expectedFound: true,
+		},
+		{
+			name: "invalid-rendezvous-discovery",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+rendezvousDiscovery: {}
+hosts:
+  - role: worker
+    interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: [rendezvousDiscovery: Forbidden: the rendezvous host is discovered, rendezvousIP must not be set, rendezvousDiscovery.macAddress: Required value: the MAC address of the rendezvous host is required when no control plane host has an interface]",
+		},
+		{
+			name: "invalid-rendezvous-discovery-hostname",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousDiscovery:
+  hostname: Rendezvous_Host
+hosts:
+  - role: master
+    interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a`,
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: rendezvousDiscovery.hostname: Invalid value: \"Rendezvous_Host\": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')",
+		},
 		{
 			name: "different-ifaces-same-host-cannot-have-same-mac",
 			data: `
@@ -331,6 +582,22 @@ hosts:
 			expectedFound: false,
 			expectedError: "invalid Agent Config configuration: Hosts[0].Interfaces[0].macAddress: Invalid value: \"000000\": address 000000: invalid MAC address",
 		},
+		{
+			name: "invalid-ignitionConfigOverride-version",
+			data: `
+apiVersion: v1beta1
+metadata:
+  name: agent-config-cluster0
+rendezvousIP: 192.168.111.80
+hosts:
+  - interfaces:
+      - name: enp3s1
+        macAddress: 28:d2:44:d2:b2:1a
+    ignitionConfigOverride: '{"ignition": {"version": "2.2.0"}}'`,
+
+			expectedFound: false,
+			expectedError: "invalid Agent Config configuration: Hosts[0].IgnitionConfigOverride: Invalid value: \"{\\\"ignition\\\": {\\\"version\\\": \\\"2.2.0\\\"}}\": ignition version must be 3.x.0",
+		},
 		{
 			name: "empty-rendezvousIP",
 			data: `
@@ -626,3 +893,173 @@ func iface(name string, mac string) *InterfacetBuilder {
 func (ib *InterfacetBuilder) build() *aiv1beta1.Interface {
 	return &ib.Interface
 }
+
+func TestAgentConfig_HostsData(t *testing.T) {
+	data := []byte("rendezvousIP: 192.168.111.80\n")
+	a := &AgentConfig{
+		File: &asset.File{Filename: agentConfigFilename, Data: data},
+		Config: &agent.Config{
+			RendezvousIP: "192.168.111.80",
+			Hosts:        []agent.Host{{Hostname: "master-0"}},
+		},
+	}
+	hostsData, err := a.HostsData()
+	assert.NoError(t, err)
+	assert.Equal(t, data, hostsData)
+
+	a.Config.Hosts[0].BMC = &baremetal.BMC{
+		Address:  "redfish-virtualmedia://192.168.111.1:8000/redfish/v1/Systems/1",
+		Username: "admin",
+		Password: "password",
+	}
+	hostsData, err = a.HostsData()
+	assert.NoError(t, err)
+	assert.Contains(t, string(hostsData), "hostname: master-0")
+	assert.NotContains(t, string(hostsData), "password")
+	assert.NotNil(t, a.Config.Hosts[0].BMC, "the BMC of the agent config must not be removed")
+}
+
+func TestAgentConfig_HostConfigFilesSerialConsole(t *testing.T) {
+	a := &AgentConfig{
+		Config: &agent.Config{
+			ISOCustomization: &agent.ISOCustomization{
+				SerialConsole: &agent.SerialConsole{},
+			},
+			Hosts: []agent.Host{
+				{Hostname: "master-0"},
+				{
+					Hostname: "master-1",
+					ISOCustomization: &agent.HostISOCustomization{
+						SerialConsole: &agent.SerialConsole{Device: "ttyS1", BaudRate: 9600},
+					},
+				},
+			},
+		},
+	}
+	files, err := a.HostConfigFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, HostConfigFileMap{
+		"master-0/installer-args.json": []byte(`["--append-karg","console=tty0","--append-karg","console=ttyS0,115200n8"]`),
+		"master-1/installer-args.json": []byte(`["--append-karg","console=tty0","--append-karg","console=ttyS1,9600n8"]`),
+	}, files)
+}
+
+func TestAgentConfig_Nodes(t *testing.T) {
+	cases := []struct {
+		name     string
+		hosts    []agent.Host
+		expected []*corev1.Node
+	}{
+		{
+			name:     "no-labels-or-taints",
+			hosts:    []agent.Host{{Hostname: "worker-0", Role: "worker"}},
+			expected: []*corev1.Node{},
+		},
+		{
+			name: "worker",
+			hosts: []agent.Host{
+				{
+					Hostname:   "worker-0",
+					Role:       "worker",
+					NodeLabels: map[string]string{"cluster.ocs.openshift.io/openshift-storage": ""},
+					NodeTaints: []corev1.Taint{
+						{Key: "node.ocs.openshift.io/storage", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+					},
+				},
+			},
+			expected: []*corev1.Node{
+				{
+					TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "worker-0",
+						Labels: map[string]string{
+							"cluster.ocs.openshift.io/openshift-storage": "",
+							"node-role.kubernetes.io/worker":             "",
+							"node.openshift.io/os_id":                    "rhcos",
+						},
+					},
+					Spec: corev1.NodeSpec{
+						Taints: []corev1.Taint{
+							{Key: "node.ocs.openshift.io/storage", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "master",
+			hosts: []agent.Host{
+				{Hostname: "worker-0", Role: "worker"},
+				{
+					Hostname:   "master-0",
+					Role:       "master",
+					NodeLabels: map[string]string{"topology.kubernetes.io/zone": "rack1"},
+				},
+			},
+			expected: []*corev1.Node{
+				{
+					TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "master-0",
+						Labels: map[string]string{
+							"node-role.kubernetes.io/control-plane": "",
+							"node-role.kubernetes.io/master":        "",
+							"node.openshift.io/os_id":               "rhcos",
+							"topology.kubernetes.io/zone":           "rack1",
+						},
+					},
+					Spec: corev1.NodeSpec{
+						Taints: []corev1.Taint{
+							{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &AgentConfig{Config: &agent.Config{Hosts: tc.hosts}}
+			assert.Equal(t, tc.expected, a.Nodes())
+		})
+	}
+}
+
+func TestRendezvousMACAddress(t *testing.T) {
+	hosts := []agent.Host{
+		{Role: "worker", Interfaces: []*aiv1beta1.Interface{{MacAddress: "00:00:00:00:00:01"}}},
+		{Interfaces: []*aiv1beta1.Interface{{MacAddress: "00:00:00:00:00:02"}}},
+		{Role: "master", Interfaces: []*aiv1beta1.Interface{{MacAddress: "28:D2:44:D2:B2:1B"}, {MacAddress: "28:d2:44:d2:b2:1a"}}},
+	}
+
+	cases := []struct {
+		name     string
+		config   *agent.Config
+		expected string
+	}{
+		{
+			name:   "no discovery",
+			config: &agent.Config{Hosts: hosts},
+		},
+		{
+			name:     "lowest control plane MAC address",
+			config:   &agent.Config{Hosts: hosts, RendezvousDiscovery: &agent.RendezvousDiscovery{}},
+			expected: "28:d2:44:d2:b2:1a",
+		},
+		{
+			name:     "hosts without role",
+			config:   &agent.Config{Hosts: hosts[:2], RendezvousDiscovery: &agent.RendezvousDiscovery{}},
+			expected: "00:00:00:00:00:02",
+		},
+		{
+			name:     "MAC address of the discovery",
+			config:   &agent.Config{Hosts: hosts, RendezvousDiscovery: &agent.RendezvousDiscovery{MACAddress: "52:54:00:AA:BB:CC"}},
+			expected: "52:54:00:aa:bb:cc",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RendezvousMACAddress(tc.config))
+		})
+	}
+}
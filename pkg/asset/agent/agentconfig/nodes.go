@@ -0,0 +1,67 @@
+package agentconfig
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// roleNodeLabels are the labels set by the kubelet on the node of each role
+// when it registers it. Unlike the default labels, the kubelet does not set
+// them on a node that is already registered.
+var roleNodeLabels = map[string][]string{
+	"master": {"node-role.kubernetes.io/master", "node-role.kubernetes.io/control-plane"},
+	"worker": {"node-role.kubernetes.io/worker"},
+}
+
+// Nodes returns the nodes of the hosts with node labels or taints, which are
+// registered in the cluster before the hosts join it. The kubelet of a host
+// adopts the node with its name, keeping the labels and taints, so that they
+// are set before any workload is scheduled on it.
+func (a *AgentConfig) Nodes() []*corev1.Node {
+	if a == nil || a.Config == nil {
+		return nil
+	}
+
+	nodes := []*corev1.Node{}
+	for _, host := range a.Config.Hosts {
+		if len(host.NodeLabels) == 0 && len(host.NodeTaints) == 0 {
+			continue
+		}
+
+		labels := map[string]string{
+			"node.openshift.io/os_id": "rhcos",
+		}
+		for _, label := range roleNodeLabels[host.Role] {
+			labels[label] = ""
+		}
+		for key, value := range host.NodeLabels {
+			labels[key] = value
+		}
+
+		taints := []corev1.Taint{}
+		if host.Role == "master" {
+			// The taint is removed by the machine-config operator when
+			// the control plane nodes are schedulable.
+			taints = append(taints, corev1.Taint{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule})
+		}
+		taints = append(taints, host.NodeTaints...)
+
+		nodes = append(nodes, &corev1.Node{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: corev1.SchemeGroupVersion.String(),
+				Kind:       "Node",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   host.Hostname,
+				Labels: labels,
+			},
+			Spec: corev1.NodeSpec{
+				Taints: taints,
+			},
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
@@ -0,0 +1,83 @@
+package agentconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/installer/pkg/types/agent"
+)
+
+const (
+	defaultSerialConsoleDevice   = "ttyS0"
+	defaultSerialConsoleBaudRate = 115200
+)
+
+var (
+	serialConsoleDeviceRegexp = regexp.MustCompile(`^(tty[A-Za-z]*|hvc)[0-9]+$`)
+	serialConsoleBaudRates    = []int{9600, 19200, 38400, 57600, 115200}
+)
+
+// SerialConsoleDevice returns the device of the serial console, ttyS0 when
+// unset.
+func SerialConsoleDevice(console *agent.SerialConsole) string {
+	if console.Device == "" {
+		return defaultSerialConsoleDevice
+	}
+	return console.Device
+}
+
+// SerialConsoleBaudRate returns the baud rate of the serial console, 115200
+// when unset.
+func SerialConsoleBaudRate(console *agent.SerialConsole) int {
+	if console.BaudRate == 0 {
+		return defaultSerialConsoleBaudRate
+	}
+	return console.BaudRate
+}
+
+// SerialConsoleKernelArguments returns the console kernel arguments of the
+// serial console. The serial console is the last one, which makes it the
+// primary console, the graphical console being kept.
+func SerialConsoleKernelArguments(console *agent.SerialConsole) []string {
+	return []string{
+		"console=tty0",
+		fmt.Sprintf("console=%s,%dn8", SerialConsoleDevice(console), SerialConsoleBaudRate(console)),
+	}
+}
+
+func validateSerialConsole(fldPath *field.Path, console *agent.SerialConsole) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if console == nil {
+		return allErrs
+	}
+	if console.Device != "" && !serialConsoleDeviceRegexp.MatchString(console.Device) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("device"), console.Device, "serial console device must be a tty or hvc device name, e.g. ttyS0"))
+	}
+	if console.BaudRate != 0 {
+		supported := false
+		for _, rate := range serialConsoleBaudRates {
+			supported = supported || rate == console.BaudRate
+		}
+		if !supported {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("baudRate"), console.BaudRate, []string{"9600", "19200", "38400", "57600", "115200"}))
+		}
+	}
+	return allErrs
+}
+
+// validateSerialConsoleKernelArguments checks that the kernel arguments don't
+// set the console along with the serial console.
+func validateSerialConsoleKernelArguments(fldPath *field.Path, kernelArguments []string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, arg := range kernelArguments {
+		if strings.HasPrefix(arg, "console=") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), arg, "console kernel arguments must not be set along with serialConsole"))
+		}
+	}
+	return allErrs
+}
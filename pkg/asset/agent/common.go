@@ -6,12 +6,13 @@ import (
 	"github.com/openshift/installer/pkg/types/baremetal"
 	"github.com/openshift/installer/pkg/types/external"
 	"github.com/openshift/installer/pkg/types/none"
+	"github.com/openshift/installer/pkg/types/nutanix"
 	"github.com/openshift/installer/pkg/types/vsphere"
 )
 
 // SupportedInstallerPlatforms lists the supported platforms for agent installer.
 func SupportedInstallerPlatforms() []string {
-	return []string{baremetal.Name, vsphere.Name, none.Name, external.Name}
+	return []string{baremetal.Name, vsphere.Name, none.Name, external.Name, nutanix.Name}
 }
 
 var supportedHivePlatforms = []hiveext.PlatformType{
@@ -19,6 +20,7 @@ var supportedHivePlatforms = []hiveext.PlatformType{
 	hiveext.VSpherePlatformType,
 	hiveext.NonePlatformType,
 	hiveext.ExternalPlatformType,
+	hiveext.NutanixPlatformType,
 }
 
 // SupportedHivePlatforms lists the supported platforms for AgentClusterInstall.
@@ -40,6 +42,8 @@ func HivePlatformType(platform types.Platform) hiveext.PlatformType {
 		return hiveext.ExternalPlatformType
 	case none.Name:
 		return hiveext.NonePlatformType
+	case nutanix.Name:
+		return hiveext.NutanixPlatformType
 	case vsphere.Name:
 		return hiveext.VSpherePlatformType
 	}
@@ -0,0 +1,379 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/coreos/stream-metadata-go/arch"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vincent-petithory/dataurl"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/assisted-image-service/pkg/isoeditor"
+	"github.com/openshift/assisted-service/models"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/openshift/installer/pkg/asset/ignition"
+	agenttypes "github.com/openshift/installer/pkg/types/agent"
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+const (
+	// NodesConfigFilename is the name of the file of the assets directory
+	// listing the hosts added to an existing cluster.
+	NodesConfigFilename = "nodes-config.yaml"
+
+	addNodesISOFilename = "node.%s.iso"
+	addNodesPath        = "/etc/assisted/add-nodes"
+	addNodesScriptPath  = "/usr/local/bin/add-node.sh"
+)
+
+// addNodeScript installs the host, found by the MAC address of one of its
+// interfaces, as a worker of the cluster to the root device of its nodes
+// config and reboots it.
+const addNodeScript = `#!/bin/bash
+set -euo pipefail
+
+for address in $(cat /sys/class/net/*/address); do
+	if [ -f "` + addNodesPath + `/hosts/${address}.ign" ]; then
+		host="` + addNodesPath + `/hosts/${address}"
+		break
+	fi
+done
+if [ -z "${host:-}" ]; then
+	echo "None of the MAC addresses of the host is in the nodes config, not installing it" >&2
+	exit 1
+fi
+
+device="$(cat "${host}.device")"
+if [ ! -b "${device}" ]; then
+	echo "The root device ${device} of the host is not a block device, not installing it" >&2
+	exit 1
+fi
+echo "Installing the host to ${device}"
+coreos-installer install --ignition-file "${host}.ign" --copy-network "${device}"
+systemctl reboot
+`
+
+const addNodeUnit = `[Unit]
+Description=Install the host as a worker of the cluster
+Wants=network-online.target
+After=network-online.target
+ConditionPathExists=` + addNodesScriptPath + `
+
+[Service]
+Type=oneshot
+ExecStart=` + addNodesScriptPath + `
+StandardOutput=journal+console
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// preNetworkManagerConfigUnit installs the NetworkManager keyfiles of the host
+// with manifests.PreNetworkConfigScript.
+const preNetworkManagerConfigUnit = `[Unit]
+Description=Install the static network configuration of the host
+DefaultDependencies=no
+Before=NetworkManager.service
+
+[Service]
+Type=oneshot
+ExecStart=/usr/local/bin/pre-network-manager-config.sh
+
+[Install]
+WantedBy=NetworkManager.service
+`
+
+var deviceNamePattern = regexp.MustCompile(`^/dev/[\w/.:-]+$`)
+
+// AddNodesCluster is the configuration of an existing cluster the hosts are
+// added to.
+type AddNodesCluster struct {
+	// ReleaseImage is the release image the cluster runs.
+	ReleaseImage string
+	// PullSecret is the pull secret of the cluster.
+	PullSecret string
+	// WorkerIgnition is the pointer ignition of the workers, fetching their
+	// configuration from the machine config server of the cluster.
+	WorkerIgnition []byte
+	// CPUArch is the architecture of the workers of the cluster.
+	CPUArch string
+}
+
+// FetchAddNodesCluster returns the configuration of the cluster of the client
+// config, for the hosts to be added to it.
+func FetchAddNodesCluster(ctx context.Context, config *rest.Config) (*AddNodesCluster, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating a Kubernetes client")
+	}
+	cfgClient, err := configclient.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating a config client")
+	}
+
+	cluster := &AddNodesCluster{}
+	clusterVersion, err := cfgClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the cluster version")
+	}
+	cluster.ReleaseImage = clusterVersion.Status.Desired.Image
+	if cluster.ReleaseImage == "" {
+		return nil, errors.New("the cluster version has no release image")
+	}
+
+	pullSecret, err := client.CoreV1().Secrets("openshift-config").Get(ctx, "pull-secret", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the pull secret of the cluster")
+	}
+	cluster.PullSecret = string(pullSecret.Data[corev1.DockerConfigJsonKey])
+
+	userData, err := client.CoreV1().Secrets("openshift-machine-api").Get(ctx, "worker-user-data", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the ignition of the workers")
+	}
+	cluster.WorkerIgnition = userData.Data["userData"]
+	if len(cluster.WorkerIgnition) == 0 {
+		return nil, errors.New("the worker-user-data secret has no ignition")
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: "node-role.kubernetes.io/worker"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the workers")
+	}
+	if len(nodes.Items) == 0 {
+		nodes, err = client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list the nodes")
+		}
+	}
+	if len(nodes.Items) == 0 {
+		return nil, errors.New("the cluster has no nodes")
+	}
+	cluster.CPUArch = arch.RpmArch(nodes.Items[0].Status.NodeInfo.Architecture)
+	return cluster, nil
+}
+
+// LoadNodesConfig returns the validated nodes config of the assets directory.
+func LoadNodesConfig(directory string) (*agenttypes.NodesConfig, error) {
+	filename := filepath.Join(directory, NodesConfigFilename)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the hosts to add")
+	}
+	config := &agenttypes.NodesConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	if err := validateNodesConfig(config); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", filename)
+	}
+	return config, nil
+}
+
+func validateNodesConfig(config *agenttypes.NodesConfig) error {
+	if len(config.Hosts) == 0 {
+		return errors.New("no hosts to add")
+	}
+	hostnames := sets.New[string]()
+	addresses := sets.New[string]()
+	for i, host := range config.Hosts {
+		if host.Hostname == "" {
+			return errors.Errorf("hosts[%d].hostname is required", i)
+		}
+		if hostnames.Has(host.Hostname) {
+			return errors.Errorf("hosts[%d].hostname %s is duplicated", i, host.Hostname)
+		}
+		hostnames.Insert(host.Hostname)
+		if len(host.Interfaces) == 0 {
+			return errors.Errorf("hosts[%d].interfaces must list the MAC address of an interface of the host", i)
+		}
+		for j, hostInterface := range host.Interfaces {
+			address := strings.ToLower(hostInterface.MacAddress)
+			if address == "" {
+				return errors.Errorf("hosts[%d].interfaces[%d].macAddress is required", i, j)
+			}
+			if addresses.Has(address) {
+				return errors.Errorf("hosts[%d].interfaces[%d].macAddress %s is duplicated", i, j, address)
+			}
+			addresses.Insert(address)
+		}
+		hints := host.RootDeviceHints
+		hints.DeviceName = ""
+		if hints != (baremetal.RootDeviceHints{}) {
+			return errors.Errorf("hosts[%d].rootDeviceHints only supports deviceName", i)
+		}
+		if host.RootDeviceHints.DeviceName == "" {
+			return errors.Errorf("hosts[%d].rootDeviceHints.deviceName is required, the disk of the host is overwritten without confirmation", i)
+		}
+		if !deviceNamePattern.MatchString(host.RootDeviceHints.DeviceName) {
+			return errors.Errorf("hosts[%d].rootDeviceHints.deviceName %s is not a device path", i, host.RootDeviceHints.DeviceName)
+		}
+	}
+	return nil
+}
+
+// CreateAddNodesImage writes the ISO installing the hosts of the nodes config
+// as workers of the cluster to the directory, and returns its path.
+func CreateAddNodesImage(directory string, cluster *AddNodesCluster, nodesConfig *agenttypes.NodesConfig) (string, error) {
+	config, err := addNodesIgnition(cluster, nodesConfig)
+	if err != nil {
+		return "", err
+	}
+	ignitionByte, err := ignition.Marshal(config)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the ignition of the add-nodes image")
+	}
+
+	baseIso, err := addNodesBaseIso(cluster)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get base ISO image")
+	}
+	tmpPath, err := prepareAgentArtifacts(baseIso, nil)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpPath)
+
+	image := &AgentImage{cpuArch: cluster.CPUArch, tmpPath: tmpPath, isoPath: baseIso}
+	image.volumeID, err = isoeditor.VolumeIdentifier(baseIso)
+	if err != nil {
+		return "", err
+	}
+	if err := image.updateIgnitionImg(ignitionByte); err != nil {
+		return "", err
+	}
+
+	isoFile := filepath.Join(directory, fmt.Sprintf(addNodesISOFilename, cluster.CPUArch))
+	os.Remove(isoFile)
+	if err := isoeditor.Create(isoFile, tmpPath, image.volumeID); err != nil {
+		return "", err
+	}
+	return isoFile, nil
+}
+
+// addNodesBaseIso returns the base ISO of the release of the cluster, or else
+// downloaded.
+func addNodesBaseIso(cluster *AddNodesCluster) (string, error) {
+	ocRelease := NewRelease(
+		Config{MaxTries: OcDefaultTries, RetryDelay: OcDefaultRetryDelay},
+		cluster.ReleaseImage, cluster.PullSecret, nil)
+
+	logrus.Infof("Extracting %s base ISO from release payload", cluster.CPUArch)
+	baseIsoFileName, err := ocRelease.GetBaseIso(cluster.CPUArch)
+	if err == nil {
+		return baseIsoFileName, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("base ISO for %s not found in release image, check release image architecture", cluster.CPUArch)
+	}
+	logrus.Warning("Failed to extract base ISO from release payload")
+
+	logrus.Infof("Downloading %s base ISO", cluster.CPUArch)
+	return newGetIso(GetIsoPluggable).getter(cluster.CPUArch)
+}
+
+// addNodesIgnition returns the ignition of the live ISO, installing the hosts
+// with the worker ignition of the cluster merged with their host name.
+func addNodesIgnition(cluster *AddNodesCluster, nodesConfig *agenttypes.NodesConfig) (*igntypes.Config, error) {
+	config := &igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+	}
+	if nodesConfig.SSHKey != "" {
+		config.Passwd.Users = []igntypes.PasswdUser{{
+			Name:              "core",
+			SSHAuthorizedKeys: []igntypes.SSHAuthorizedKey{igntypes.SSHAuthorizedKey(nodesConfig.SSHKey)},
+		}}
+	}
+
+	workerIgnition := dataurl.EncodeBytes(cluster.WorkerIgnition)
+	var staticNetworkConfigs []*models.HostStaticNetworkConfig
+	for i := range nodesConfig.Hosts {
+		host := &nodesConfig.Hosts[i]
+		hostConfig := &igntypes.Config{
+			Ignition: igntypes.Ignition{
+				Version: igntypes.MaxVersion.String(),
+				Config: igntypes.IgnitionConfig{
+					Merge: []igntypes.Resource{{Source: &workerIgnition}},
+				},
+			},
+			Storage: igntypes.Storage{
+				Files: []igntypes.File{ignition.FileFromString("/etc/hostname", "root", 0644, host.Hostname+"\n")},
+			},
+		}
+		if host.IgnitionConfigOverride != "" {
+			override := dataurl.EncodeBytes([]byte(host.IgnitionConfigOverride))
+			hostConfig.Ignition.Config.Merge = append(hostConfig.Ignition.Config.Merge, igntypes.Resource{Source: &override})
+		}
+		hostIgnition, err := ignition.Marshal(hostConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal the ignition of host %s", host.Hostname)
+		}
+		for _, hostInterface := range host.Interfaces {
+			hostPath := path.Join(addNodesPath, "hosts", strings.ToLower(hostInterface.MacAddress))
+			config.Storage.Files = append(config.Storage.Files,
+				ignition.FileFromBytes(hostPath+".ign", "root", 0600, hostIgnition),
+				ignition.FileFromString(hostPath+".device", "root", 0600, host.RootDeviceHints.DeviceName))
+		}
+		if host.NetworkConfig.Raw != nil {
+			macInterfaceMap := make(models.MacInterfaceMap, 0, len(host.Interfaces))
+			for _, hostInterface := range host.Interfaces {
+				macInterfaceMap = append(macInterfaceMap, &models.MacInterfaceMapItems0{
+					MacAddress:     hostInterface.MacAddress,
+					LogicalNicName: hostInterface.Name,
+				})
+			}
+			staticNetworkConfigs = append(staticNetworkConfigs, &models.HostStaticNetworkConfig{
+				MacInterfaceMap: macInterfaceMap,
+				NetworkYaml:     string(host.NetworkConfig.Raw),
+			})
+		}
+	}
+	if err := addNodesStaticNetworkConfig(config, staticNetworkConfigs); err != nil {
+		return nil, err
+	}
+
+	config.Storage.Files = append(config.Storage.Files, ignition.FileFromString(addNodesScriptPath, "root", 0755, addNodeScript))
+	enabled := true
+	contents := addNodeUnit
+	config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+		Name:     "add-node.service",
+		Enabled:  &enabled,
+		Contents: &contents,
+	})
+	return config, nil
+}
+
+// addNodesStaticNetworkConfig adds the NetworkManager keyfiles of the hosts
+// with a network config, and the service installing those of the host.
+func addNodesStaticNetworkConfig(config *igntypes.Config, staticNetworkConfigs []*models.HostStaticNetworkConfig) error {
+	if len(staticNetworkConfigs) == 0 {
+		return nil
+	}
+	if err := addStaticNetworkConfig(config, staticNetworkConfigs); err != nil {
+		return err
+	}
+	enabled := true
+	contents := preNetworkManagerConfigUnit
+	config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+		Name:     "pre-network-manager-config.service",
+		Enabled:  &enabled,
+		Contents: &contents,
+	})
+	return nil
+}
@@ -0,0 +1,143 @@
+package image
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vincent-petithory/dataurl"
+)
+
+func TestLoadNodesConfig(t *testing.T) {
+	cases := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			data: `hosts:
+- hostname: worker-3
+  rootDeviceHints:
+    deviceName: /dev/sda
+  interfaces:
+  - name: eth0
+    macAddress: 00:EF:44:21:E6:A5
+sshKey: ssh-ed25519 AAAA
+`,
+		},
+		{
+			name:          "no hosts",
+			data:          "hosts: []\n",
+			expectedError: "no hosts to add",
+		},
+		{
+			name: "no hostname",
+			data: `hosts:
+- rootDeviceHints:
+    deviceName: /dev/sda
+  interfaces:
+  - macAddress: 00:ef:44:21:e6:a5
+`,
+			expectedError: "hosts[0].hostname is required",
+		},
+		{
+			name: "duplicated MAC address",
+			data: `hosts:
+- hostname: worker-3
+  rootDeviceHints:
+    deviceName: /dev/sda
+  interfaces:
+  - macAddress: 00:ef:44:21:e6:a5
+- hostname: worker-4
+  rootDeviceHints:
+    deviceName: /dev/sda
+  interfaces:
+  - macAddress: 00:EF:44:21:E6:A5
+`,
+			expectedError: "hosts[1].interfaces[0].macAddress 00:ef:44:21:e6:a5 is duplicated",
+		},
+		{
+			name: "no root device",
+			data: `hosts:
+- hostname: worker-3
+  interfaces:
+  - macAddress: 00:ef:44:21:e6:a5
+`,
+			expectedError: "hosts[0].rootDeviceHints.deviceName is required",
+		},
+		{
+			name: "unsupported root device hint",
+			data: `hosts:
+- hostname: worker-3
+  rootDeviceHints:
+    model: ssd
+  interfaces:
+  - macAddress: 00:ef:44:21:e6:a5
+`,
+			expectedError: "hosts[0].rootDeviceHints only supports deviceName",
+		},
+		{
+			name: "invalid device name",
+			data: `hosts:
+- hostname: worker-3
+  rootDeviceHints:
+    deviceName: sda; reboot
+  interfaces:
+  - macAddress: 00:ef:44:21:e6:a5
+`,
+			expectedError: "hosts[0].rootDeviceHints.deviceName sda; reboot is not a device path",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			directory := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(directory, NodesConfigFilename), []byte(tc.data), 0600))
+			config, err := LoadNodesConfig(directory)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "worker-3", config.Hosts[0].Hostname)
+		})
+	}
+}
+
+func TestAddNodesIgnition(t *testing.T) {
+	directory := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(directory, NodesConfigFilename), []byte(`hosts:
+- hostname: worker-3
+  rootDeviceHints:
+    deviceName: /dev/sda
+  interfaces:
+  - name: eth0
+    macAddress: 00:EF:44:21:E6:A5
+sshKey: ssh-ed25519 AAAA
+`), 0600))
+	nodesConfig, err := LoadNodesConfig(directory)
+	require.NoError(t, err)
+
+	cluster := &AddNodesCluster{
+		WorkerIgnition: []byte(`{"ignition":{"version":"3.2.0","config":{"merge":[{"source":"https://api-int.ostest.test.metalkube.org:22623/config/worker"}]}}}`),
+		CPUArch:        "x86_64",
+	}
+	config, err := addNodesIgnition(cluster, nodesConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ssh-ed25519 AAAA", string(config.Passwd.Users[0].SSHAuthorizedKeys[0]))
+	files := map[string]string{}
+	for _, file := range config.Storage.Files {
+		data, err := dataurl.DecodeString(*file.Contents.Source)
+		require.NoError(t, err)
+		files[file.Path] = string(data.Data)
+	}
+	assert.Equal(t, "/dev/sda", files["/etc/assisted/add-nodes/hosts/00:ef:44:21:e6:a5.device"])
+	assert.Contains(t, files["/etc/assisted/add-nodes/hosts/00:ef:44:21:e6:a5.ign"], dataurl.EncodeBytes(cluster.WorkerIgnition))
+	assert.Contains(t, files["/etc/assisted/add-nodes/hosts/00:ef:44:21:e6:a5.ign"], dataurl.EncodeBytes([]byte("worker-3\n")))
+	assert.Equal(t, addNodeScript, files[addNodesScriptPath])
+	require.Len(t, config.Systemd.Units, 1)
+	assert.Equal(t, "add-node.service", config.Systemd.Units[0].Name)
+}
@@ -7,12 +7,16 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"github.com/openshift/assisted-image-service/pkg/isoeditor"
 	"github.com/openshift/assisted-service/models"
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent"
 	config "github.com/openshift/installer/pkg/asset/agent/agentconfig"
 	"github.com/openshift/installer/pkg/asset/agent/manifests"
 	"github.com/openshift/installer/pkg/asset/agent/mirror"
+	"github.com/openshift/installer/pkg/types"
 )
 
 const (
@@ -30,13 +34,18 @@ type AgentArtifacts struct {
 	TmpPath              string
 	IgnitionByte         []byte
 	Kargs                []byte
+	GrubConfig           []byte
 	ISOPath              string
 	BootArtifactsBaseURL string
+	// AdditionalArchs are the artifacts of the other architectures of the
+	// compute pools, when the release payload is multi-arch.
+	AdditionalArchs []ArchArtifacts
 }
 
 // Dependencies returns the assets on which the AgentArtifacts asset depends.
 func (a *AgentArtifacts) Dependencies() []asset.Asset {
 	return []asset.Asset{
+		&MirrorRegistriesCheck{},
 		&Ignition{},
 		&Kargs{},
 		&BaseIso{},
@@ -44,6 +53,7 @@ func (a *AgentArtifacts) Dependencies() []asset.Asset {
 		&manifests.AgentClusterInstall{},
 		&mirror.RegistriesConf{},
 		&config.AgentConfig{},
+		&agent.OptionalInstallConfig{},
 	}
 }
 
@@ -56,8 +66,9 @@ func (a *AgentArtifacts) Generate(dependencies asset.Parents) error {
 	agentClusterInstall := &manifests.AgentClusterInstall{}
 	registriesConf := &mirror.RegistriesConf{}
 	agentconfig := &config.AgentConfig{}
+	installConfig := &agent.OptionalInstallConfig{}
 
-	dependencies.Get(ignition, kargs, baseIso, agentManifests, agentClusterInstall, registriesConf, agentconfig)
+	dependencies.Get(ignition, kargs, baseIso, agentManifests, agentClusterInstall, registriesConf, agentconfig, installConfig)
 
 	ignitionByte, err := json.Marshal(ignition.Config)
 	if err != nil {
@@ -69,27 +80,56 @@ func (a *AgentArtifacts) Generate(dependencies asset.Parents) error {
 	a.IgnitionByte = ignitionByte
 	a.ISOPath = baseIso.File.Filename
 	a.Kargs = kargs.KernelCmdLine()
+	a.GrubConfig = kargs.GrubConfig()
 
 	if agentconfig.Config != nil {
 		a.BootArtifactsBaseURL = strings.Trim(agentconfig.Config.BootArtifactsBaseURL, "/")
 	}
 
-	var agentTuiFiles []string
-	if agentClusterInstall.GetExternalPlatformName() != string(models.PlatformTypeOci) {
-		agentTuiFiles, err = a.fetchAgentTuiFiles(agentManifests.ClusterImageSet.Spec.ReleaseImage, agentManifests.GetPullSecretData(), registriesConf.MirrorConfig)
+	releaseImage := agentManifests.ClusterImageSet.Spec.ReleaseImage
+	withAgentTui := agentClusterInstall.GetExternalPlatformName() != string(models.PlatformTypeOci)
+	a.TmpPath, err = extractAgentArtifacts(a.ISOPath, a.CPUArch, releaseImage, agentManifests.GetPullSecretData(), registriesConf.MirrorConfig, withAgentTui)
+	if err != nil {
+		return err
+	}
+
+	var installConfigData *types.InstallConfig
+	if installConfig.Supplied {
+		installConfigData = installConfig.Config
+	}
+	multiArchRelease := isMultiArchRelease(releaseImage, agentManifests.GetPullSecretData(), registriesConf.MirrorConfig)
+	for _, cpuArch := range additionalCPUArchs(installConfigData, a.CPUArch, releaseImage, multiArchRelease) {
+		archArtifacts := ArchArtifacts{CPUArch: cpuArch}
+		archArtifacts.ISOPath, err = fetchBaseIso(cpuArch, releaseImage, agentManifests.GetPullSecretData(), registriesConf.MirrorConfig)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the %s base ISO image", cpuArch)
+		}
+		archArtifacts.TmpPath, err = extractAgentArtifacts(archArtifacts.ISOPath, cpuArch, releaseImage, agentManifests.GetPullSecretData(), registriesConf.MirrorConfig, withAgentTui)
 		if err != nil {
 			return err
 		}
-	}
-	err = a.prepareAgentArtifacts(a.ISOPath, agentTuiFiles)
-	if err != nil {
-		return err
+		a.AdditionalArchs = append(a.AdditionalArchs, archArtifacts)
 	}
 
 	return nil
 }
 
-func (a *AgentArtifacts) fetchAgentTuiFiles(releaseImage string, pullSecret string, mirrorConfig []mirror.RegistriesConfig) ([]string, error) {
+// extractAgentArtifacts returns the tmp folder the ISO of the architecture is
+// extracted to, with the agent TUI files of the release appended to its
+// initrd when withAgentTui is set.
+func extractAgentArtifacts(iso, cpuArch, releaseImage, pullSecret string, mirrorConfig []mirror.RegistriesConfig, withAgentTui bool) (string, error) {
+	var agentTuiFiles []string
+	if withAgentTui {
+		var err error
+		agentTuiFiles, err = fetchAgentTuiFiles(releaseImage, pullSecret, mirrorConfig, cpuArch)
+		if err != nil {
+			return "", err
+		}
+	}
+	return prepareAgentArtifacts(iso, agentTuiFiles)
+}
+
+func fetchAgentTuiFiles(releaseImage string, pullSecret string, mirrorConfig []mirror.RegistriesConfig, cpuArch string) ([]string, error) {
 	release := NewRelease(
 		Config{MaxTries: OcDefaultTries, RetryDelay: OcDefaultRetryDelay},
 		releaseImage, pullSecret, mirrorConfig)
@@ -98,7 +138,7 @@ func (a *AgentArtifacts) fetchAgentTuiFiles(releaseImage string, pullSecret stri
 	files := []string{}
 
 	for _, srcFile := range agentTuiFilenames {
-		extracted, err := release.ExtractFile("agent-installer-utils", srcFile)
+		extracted, err := release.ExtractFile("agent-installer-utils", srcFile, cpuArch)
 		if err != nil {
 			return nil, err
 		}
@@ -116,28 +156,29 @@ func (a *AgentArtifacts) fetchAgentTuiFiles(releaseImage string, pullSecret stri
 	return files, nil
 }
 
-func (a *AgentArtifacts) prepareAgentArtifacts(iso string, additionalFiles []string) error {
+// prepareAgentArtifacts returns the tmp folder the ISO is extracted to, with
+// the additional files appended to its initrd.
+func prepareAgentArtifacts(iso string, additionalFiles []string) (string, error) {
 	// Create a tmp folder to store all the pieces required to generate the agent artifacts.
 	tmpPath, err := os.MkdirTemp("", "agent")
 	if err != nil {
-		return err
+		return "", err
 	}
-	a.TmpPath = tmpPath
 
-	err = isoeditor.Extract(iso, a.TmpPath)
+	err = isoeditor.Extract(iso, tmpPath)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	err = a.appendAgentFilesToInitrd(additionalFiles)
+	err = appendAgentFilesToInitrd(tmpPath, additionalFiles)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return tmpPath, nil
 }
 
-func (a *AgentArtifacts) appendAgentFilesToInitrd(additionalFiles []string) error {
+func appendAgentFilesToInitrd(tmpPath string, additionalFiles []string) error {
 	ca := NewCpioArchive()
 
 	dstPath := "/agent-files/"
@@ -172,7 +213,7 @@ for i in $(find /agent-files/ -printf "%P\n"); do chcon system_u:object_r:bin_t:
 	}
 
 	// Append the archive to initrd.img
-	initrdImgPath := filepath.Join(a.TmpPath, "images", "pxeboot", "initrd.img")
+	initrdImgPath := filepath.Join(tmpPath, "images", "pxeboot", "initrd.img")
 	initrdImg, err := os.OpenFile(initrdImgPath, os.O_WRONLY|os.O_APPEND, 0)
 	if err != nil {
 		return err
@@ -20,6 +20,11 @@ const (
 	agentISOFilename = "agent.%s.iso"
 )
 
+// MinimalISO creates a minimal ISO without the rootfs, which is written to
+// the boot artifacts folder and downloaded by the hosts from the
+// bootArtifactsBaseURL of the agent-config when they boot the ISO.
+var MinimalISO bool
+
 // AgentImage is an asset that generates the bootable image used to install clusters.
 type AgentImage struct {
 	cpuArch              string
@@ -30,6 +35,12 @@ type AgentImage struct {
 	rootFSURL            string
 	bootArtifactsBaseURL string
 	platform             hiveext.PlatformType
+	// releasePayloadPath is the directory of the release payload ISO, when
+	// the release payload is embedded.
+	releasePayloadPath string
+	// additionalImages are the images of the other architectures, when the
+	// release payload is multi-arch.
+	additionalImages []*AgentImage
 }
 
 var _ asset.WritableAsset = (*AgentImage)(nil)
@@ -55,36 +66,57 @@ func (a *AgentImage) Generate(dependencies asset.Parents) error {
 	a.tmpPath = agentArtifacts.TmpPath
 	a.isoPath = agentArtifacts.ISOPath
 	a.bootArtifactsBaseURL = agentArtifacts.BootArtifactsBaseURL
+	a.platform = agentManifests.AgentClusterInstall.Spec.PlatformType
+	a.additionalImages = nil
 
-	volumeID, err := isoeditor.VolumeIdentifier(a.isoPath)
-	if err != nil {
+	if err := a.prepare(baseIso, agentArtifacts); err != nil {
 		return err
 	}
-	a.volumeID = volumeID
 
-	a.platform = agentManifests.AgentClusterInstall.Spec.PlatformType
-	if a.platform == hiveext.ExternalPlatformType {
-		// when the bootArtifactsBaseURL is specified, construct the custom rootfs URL
-		if a.bootArtifactsBaseURL != "" {
-			a.rootFSURL = fmt.Sprintf("%s/%s", a.bootArtifactsBaseURL, fmt.Sprintf("agent.%s-rootfs.img", a.cpuArch))
-			logrus.Debugf("Using custom rootfs URL: %s", a.rootFSURL)
-		} else {
-			// Default to the URL from the RHCOS streams file
-			defaultRootFSURL, err := baseIso.getRootFSURL(a.cpuArch)
-			if err != nil {
+	for _, archArtifacts := range agentArtifacts.AdditionalArchs {
+		archImage := &AgentImage{
+			cpuArch:              archArtifacts.CPUArch,
+			rendezvousIP:         a.rendezvousIP,
+			tmpPath:              archArtifacts.TmpPath,
+			isoPath:              archArtifacts.ISOPath,
+			bootArtifactsBaseURL: a.bootArtifactsBaseURL,
+			platform:             a.platform,
+		}
+		if err := archImage.prepare(baseIso, agentArtifacts); err != nil {
+			return err
+		}
+		a.additionalImages = append(a.additionalImages, archImage)
+	}
+
+	if EmbedRelease {
+		releaseImage := agentManifests.ClusterImageSet.Spec.ReleaseImage
+		for _, image := range append([]*AgentImage{a}, a.additionalImages...) {
+			if err := image.pullReleasePayload(releaseImage, agentManifests.GetPullSecretData()); err != nil {
 				return err
 			}
-			a.rootFSURL = defaultRootFSURL
-			logrus.Debugf("Using default rootfs URL: %s", a.rootFSURL)
 		}
 	}
 
-	err = a.updateIgnitionImg(agentArtifacts.IgnitionByte)
+	return nil
+}
+
+// prepare embeds the ignition and kernel arguments in the extracted ISO of
+// the architecture of the image.
+func (a *AgentImage) prepare(baseIso *BaseIso, agentArtifacts *AgentArtifacts) error {
+	if err := a.embedIgnition(agentArtifacts.IgnitionByte); err != nil {
+		return err
+	}
+
+	if err := a.setRootFSURL(baseIso); err != nil {
+		return err
+	}
+
+	err := a.appendKargs(agentArtifacts.Kargs)
 	if err != nil {
 		return err
 	}
 
-	err = a.appendKargs(agentArtifacts.Kargs)
+	err = a.appendGrubConfig(agentArtifacts.GrubConfig)
 	if err != nil {
 		return err
 	}
@@ -92,6 +124,49 @@ func (a *AgentImage) Generate(dependencies asset.Parents) error {
 	return nil
 }
 
+// embedIgnition reads the volume identifier of the ISO and embeds the
+// ignition in its extracted initrd.
+func (a *AgentImage) embedIgnition(ignition []byte) error {
+	volumeID, err := isoeditor.VolumeIdentifier(a.isoPath)
+	if err != nil {
+		return err
+	}
+	a.volumeID = volumeID
+
+	return a.updateIgnitionImg(ignition)
+}
+
+// setRootFSURL sets the URL the hosts download the rootfs from when the
+// image is a minimal ISO.
+func (a *AgentImage) setRootFSURL(baseIso *BaseIso) error {
+	if !a.minimal() {
+		return nil
+	}
+	// when the bootArtifactsBaseURL is specified, construct the custom rootfs URL
+	if a.bootArtifactsBaseURL != "" {
+		a.rootFSURL = fmt.Sprintf("%s/%s", a.bootArtifactsBaseURL, fmt.Sprintf("agent.%s-rootfs.img", a.cpuArch))
+		logrus.Debugf("Using custom rootfs URL: %s", a.rootFSURL)
+		return nil
+	}
+	if a.platform != hiveext.ExternalPlatformType {
+		return errors.New("a minimal ISO requires bootArtifactsBaseURL in agent-config.yaml, the URL the hosts download the rootfs from")
+	}
+	// Default to the URL from the RHCOS streams file
+	defaultRootFSURL, err := baseIso.getRootFSURL(a.cpuArch)
+	if err != nil {
+		return err
+	}
+	a.rootFSURL = defaultRootFSURL
+	logrus.Debugf("Using default rootfs URL: %s", a.rootFSURL)
+	return nil
+}
+
+// minimal returns whether the image is a minimal ISO, whose rootfs is
+// downloaded when it boots. It always is for the external platform.
+func (a *AgentImage) minimal() bool {
+	return MinimalISO || a.platform == hiveext.ExternalPlatformType
+}
+
 func (a *AgentImage) updateIgnitionImg(ignition []byte) error {
 	ca := NewCpioArchive()
 	err := ca.StoreBytes("config.ign", ignition, 0o644)
@@ -174,9 +249,46 @@ func (a *AgentImage) appendKargs(kargs []byte) error {
 	return nil
 }
 
+// appendGrubConfig appends the GRUB commands to the GRUB configs of the ISO.
+// They are appended, rather than inserted, to keep the offsets of the kernel
+// arguments embed areas, and GRUB runs them before showing its menu.
+func (a *AgentImage) appendGrubConfig(grubConfig []byte) error {
+	if len(grubConfig) == 0 {
+		return nil
+	}
+
+	kargsFiles, err := isoeditor.KargsFiles(a.isoPath)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range kargsFiles {
+		if filepath.Base(f) != "grub.cfg" {
+			continue
+		}
+		file, err := os.OpenFile(filepath.Join(a.tmpPath, f), os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(grubConfig)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PersistToFile writes the iso image in the assets folder
 func (a *AgentImage) PersistToFile(directory string) error {
-	defer os.RemoveAll(a.tmpPath)
+	defer func() {
+		os.RemoveAll(a.tmpPath)
+		os.RemoveAll(a.releasePayloadPath)
+		for _, archImage := range a.additionalImages {
+			os.RemoveAll(archImage.tmpPath)
+			os.RemoveAll(archImage.releasePayloadPath)
+		}
+	}()
 
 	// If the volumeId or tmpPath are not set then it means that either one of the AgentImage
 	// dependencies or the asset itself failed for some reason
@@ -184,50 +296,72 @@ func (a *AgentImage) PersistToFile(directory string) error {
 		return errors.New("cannot generate ISO image due to configuration errors")
 	}
 
+	// When the bootArtifactsBaseURL is specified, output the rootfs files
+	// alongside the minimal ISOs
+	if a.minimal() && a.bootArtifactsBaseURL != "" {
+		err := createDir(filepath.Join(directory, bootArtifactsPath))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, image := range append([]*AgentImage{a}, a.additionalImages...) {
+		if err := image.persistISO(directory); err != nil {
+			return err
+		}
+		if image.releasePayloadPath != "" {
+			if err := image.persistReleasePayload(directory); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The rendezvous IP is unknown when the hosts discover it.
+	if a.rendezvousIP != "" {
+		err := os.WriteFile(filepath.Join(directory, "rendezvousIP"), []byte(a.rendezvousIP), 0o644) //nolint:gosec // no sensitive info
+		if err != nil {
+			return err
+		}
+	}
+	// For external platform OCI, add CCM manifests in the openshift directory.
+	if a.platform == hiveext.ExternalPlatformType {
+		logrus.Infof("When using %s oci platform, always make sure CCM manifests were added in the %s directory.", hiveext.ExternalPlatformType, manifests.OpenshiftManifestDir())
+	}
+
+	return nil
+}
+
+// persistISO writes the iso image of the architecture of the image in the
+// assets folder.
+func (a *AgentImage) persistISO(directory string) error {
 	agentIsoFile := filepath.Join(directory, fmt.Sprintf(agentISOFilename, a.cpuArch))
 
 	// Remove symlink if it exists
 	os.Remove(agentIsoFile)
 
-	var err error
-	// For external platform when the bootArtifactsBaseURL is specified,
-	// output the rootfs file alongside the minimal ISO
-	if a.platform == hiveext.ExternalPlatformType {
+	if a.minimal() {
 		if a.bootArtifactsBaseURL != "" {
 			bootArtifactsFullPath := filepath.Join(directory, bootArtifactsPath)
-			err := createDir(bootArtifactsFullPath)
-			if err != nil {
-				return err
-			}
-			err = extractRootFS(bootArtifactsFullPath, a.tmpPath, a.cpuArch)
+			err := extractRootFS(bootArtifactsFullPath, a.tmpPath, a.cpuArch)
 			if err != nil {
 				return err
 			}
 			logrus.Infof("RootFS file created in: %s. Upload it at %s", bootArtifactsFullPath, a.rootFSURL)
 		}
-		err = isoeditor.CreateMinimalISO(a.tmpPath, a.volumeID, a.rootFSURL, a.cpuArch, agentIsoFile)
+		err := isoeditor.CreateMinimalISO(a.tmpPath, a.volumeID, a.rootFSURL, a.cpuArch, agentIsoFile)
 		if err != nil {
 			return err
 		}
 		logrus.Infof("Generated minimal ISO at %s", agentIsoFile)
-	} else {
-		// Generate full ISO
-		err = isoeditor.Create(agentIsoFile, a.tmpPath, a.volumeID)
-		if err != nil {
-			return err
-		}
-		logrus.Infof("Generated ISO at %s", agentIsoFile)
+		return nil
 	}
 
-	err = os.WriteFile(filepath.Join(directory, "rendezvousIP"), []byte(a.rendezvousIP), 0o644) //nolint:gosec // no sensitive info
+	// Generate full ISO
+	err := isoeditor.Create(agentIsoFile, a.tmpPath, a.volumeID)
 	if err != nil {
 		return err
 	}
-	// For external platform OCI, add CCM manifests in the openshift directory.
-	if a.platform == hiveext.ExternalPlatformType {
-		logrus.Infof("When using %s oci platform, always make sure CCM manifests were added in the %s directory.", hiveext.ExternalPlatformType, manifests.OpenshiftManifestDir())
-	}
-
+	logrus.Infof("Generated ISO at %s", agentIsoFile)
 	return nil
 }
 
@@ -0,0 +1,73 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	hiveext "github.com/openshift/assisted-service/api/hiveextension/v1beta1"
+)
+
+func TestAgentImageSetRootFSURL(t *testing.T) {
+	cases := []struct {
+		name                 string
+		minimalISO           bool
+		platform             hiveext.PlatformType
+		bootArtifactsBaseURL string
+		expectedMinimal      bool
+		expectedRootFSURL    string
+		expectedError        string
+	}{
+		{
+			name:     "full ISO",
+			platform: hiveext.BareMetalPlatformType,
+		},
+		{
+			name:                 "full ISO with boot artifacts",
+			platform:             hiveext.BareMetalPlatformType,
+			bootArtifactsBaseURL: "http://192.168.111.1/boot-artifacts",
+		},
+		{
+			name:                 "minimal ISO",
+			minimalISO:           true,
+			platform:             hiveext.BareMetalPlatformType,
+			bootArtifactsBaseURL: "http://192.168.111.1/boot-artifacts",
+			expectedMinimal:      true,
+			expectedRootFSURL:    "http://192.168.111.1/boot-artifacts/agent.x86_64-rootfs.img",
+		},
+		{
+			name:            "minimal ISO without boot artifacts",
+			minimalISO:      true,
+			platform:        hiveext.NonePlatformType,
+			expectedMinimal: true,
+			expectedError:   "a minimal ISO requires bootArtifactsBaseURL in agent-config.yaml, the URL the hosts download the rootfs from",
+		},
+		{
+			name:                 "external platform",
+			platform:             hiveext.ExternalPlatformType,
+			bootArtifactsBaseURL: "http://192.168.111.1/boot-artifacts",
+			expectedMinimal:      true,
+			expectedRootFSURL:    "http://192.168.111.1/boot-artifacts/agent.x86_64-rootfs.img",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(minimalISO bool) { MinimalISO = minimalISO }(MinimalISO)
+			MinimalISO = tc.minimalISO
+
+			a := &AgentImage{
+				cpuArch:              "x86_64",
+				platform:             tc.platform,
+				bootArtifactsBaseURL: tc.bootArtifactsBaseURL,
+			}
+			assert.Equal(t, tc.expectedMinimal, a.minimal())
+			err := a.setRootFSURL(&BaseIso{})
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedRootFSURL, a.rootFSURL)
+		})
+	}
+}
@@ -25,6 +25,9 @@ type AgentPXEFiles struct {
 	tmpPath              string
 	bootArtifactsBaseURL string
 	kernelArgs           string
+	// additionalFiles are the PXE files of the other architectures, when the
+	// release payload is multi-arch.
+	additionalFiles []*AgentPXEFiles
 }
 
 type coreOSKargs struct {
@@ -45,8 +48,35 @@ func (a *AgentPXEFiles) Generate(dependencies asset.Parents) error {
 	agentArtifacts := &AgentArtifacts{}
 	dependencies.Get(agentArtifacts)
 
+	if err := validatePXEFilesFormat(agentArtifacts.BootArtifactsBaseURL); err != nil {
+		return err
+	}
+
 	a.tmpPath = agentArtifacts.TmpPath
+	a.cpuArch = agentArtifacts.CPUArch
+	a.bootArtifactsBaseURL = agentArtifacts.BootArtifactsBaseURL
+	a.additionalFiles = nil
+	if err := a.prepare(agentArtifacts); err != nil {
+		return err
+	}
 
+	for _, archArtifacts := range agentArtifacts.AdditionalArchs {
+		archFiles := &AgentPXEFiles{
+			cpuArch:              archArtifacts.CPUArch,
+			tmpPath:              archArtifacts.TmpPath,
+			bootArtifactsBaseURL: a.bootArtifactsBaseURL,
+		}
+		if err := archFiles.prepare(agentArtifacts); err != nil {
+			return err
+		}
+		a.additionalFiles = append(a.additionalFiles, archFiles)
+	}
+	return nil
+}
+
+// prepare sets up the initrd and kernel arguments of the architecture of
+// the PXE files.
+func (a *AgentPXEFiles) prepare(agentArtifacts *AgentArtifacts) error {
 	ignitionContent := &isoeditor.IgnitionContent{Config: agentArtifacts.IgnitionByte}
 	initrdImgPath := filepath.Join(a.tmpPath, "images", "pxeboot", "initrd.img")
 	custom, err := isoeditor.NewInitRamFSStreamReader(initrdImgPath, ignitionContent)
@@ -55,8 +85,6 @@ func (a *AgentPXEFiles) Generate(dependencies asset.Parents) error {
 	}
 
 	a.imageReader = custom
-	a.cpuArch = agentArtifacts.CPUArch
-	a.bootArtifactsBaseURL = agentArtifacts.BootArtifactsBaseURL
 
 	kernelArgs, err := getKernelArgs(filepath.Join(a.tmpPath, "coreos", "kargs.json"))
 	if err != nil {
@@ -74,7 +102,12 @@ func (a *AgentPXEFiles) PersistToFile(directory string) error {
 		return errors.New("cannot generate PXE assets due to configuration errors")
 	}
 
-	defer a.imageReader.Close()
+	defer func() {
+		a.imageReader.Close()
+		for _, archFiles := range a.additionalFiles {
+			archFiles.imageReader.Close()
+		}
+	}()
 	bootArtifactsFullPath := filepath.Join(directory, bootArtifactsPath)
 
 	err := createDir(bootArtifactsFullPath)
@@ -82,7 +115,26 @@ func (a *AgentPXEFiles) PersistToFile(directory string) error {
 		return err
 	}
 
-	err = extractRootFS(bootArtifactsFullPath, a.tmpPath, a.cpuArch)
+	err = a.persistArchFiles(bootArtifactsFullPath)
+	if err != nil {
+		return err
+	}
+	for _, archFiles := range a.additionalFiles {
+		if err := archFiles.persistArchFiles(bootArtifactsFullPath); err != nil {
+			return err
+		}
+	}
+
+	logrus.Infof("PXE boot artifacts created in: %s", bootArtifactsFullPath)
+	logrus.Infof("Kernel parameters for PXE boot: %s", a.kernelArgs)
+
+	return nil
+}
+
+// persistArchFiles writes the PXE assets of the architecture of the files in
+// the boot artifacts folder.
+func (a *AgentPXEFiles) persistArchFiles(bootArtifactsFullPath string) error {
+	err := extractRootFS(bootArtifactsFullPath, a.tmpPath, a.cpuArch)
 	if err != nil {
 		return err
 	}
@@ -124,9 +176,9 @@ func (a *AgentPXEFiles) PersistToFile(directory string) error {
 		}
 	}
 
-	logrus.Infof("PXE boot artifacts created in: %s", bootArtifactsFullPath)
-	logrus.Infof("Kernel parameters for PXE boot: %s", a.kernelArgs)
-
+	if PXEFilesFormat == PXEFormatUKI {
+		return a.createUKI(bootArtifactsFullPath)
+	}
 	return nil
 }
 
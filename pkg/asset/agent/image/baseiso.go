@@ -122,16 +122,7 @@ func (i *BaseIso) Generate(dependencies asset.Parents) error {
 	// ic := &agent.OptionalInstallConfig{}
 	// p.Get(ic)
 
-	var err error
-	var baseIsoFileName string
-
-	if urlOverride, ok := os.LookupEnv("OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE"); ok && urlOverride != "" {
-		logrus.Warn("Found override for OS Image. Please be warned, this is not advised")
-		baseIsoFileName, err = DownloadImageFile(urlOverride)
-	} else {
-		baseIsoFileName, err = i.retrieveBaseIso(dependencies)
-	}
-
+	baseIsoFileName, err := i.retrieveBaseIso(dependencies)
 	if err == nil {
 		logrus.Debugf("Using base ISO image %s", baseIsoFileName)
 		i.File = &asset.File{Filename: baseIsoFileName}
@@ -143,11 +134,9 @@ func (i *BaseIso) Generate(dependencies asset.Parents) error {
 }
 
 func (i *BaseIso) retrieveBaseIso(dependencies asset.Parents) (string, error) {
-	// use the GetIso function to get the BaseIso from the release payload
 	agentManifests := &manifests.AgentManifests{}
 	dependencies.Get(agentManifests)
-	var baseIsoFileName string
-	var err error
+	registriesConf := &mirror.RegistriesConf{}
 
 	// Default iso archName to x86_64.
 	archName := arch.RpmArch(types.ArchitectureAMD64)
@@ -157,21 +146,36 @@ func (i *BaseIso) retrieveBaseIso(dependencies asset.Parents) (string, error) {
 		if agentManifests.InfraEnv.Spec.CpuArchitecture != "" {
 			archName = agentManifests.InfraEnv.Spec.CpuArchitecture
 		}
-		releaseImage := agentManifests.ClusterImageSet.Spec.ReleaseImage
-		pullSecret := agentManifests.GetPullSecretData()
-		registriesConf := &mirror.RegistriesConf{}
-		dependencies.Get(agentManifests, registriesConf)
+		dependencies.Get(registriesConf)
+		return getBaseIso(archName, agentManifests.ClusterImageSet.Spec.ReleaseImage, agentManifests.GetPullSecretData(), registriesConf.MirrorConfig)
+	}
+	return getBaseIso(archName, "", "", nil)
+}
 
+// getBaseIso returns the base ISO of the architecture, or the one downloaded
+// from OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE when it is set.
+func getBaseIso(archName, releaseImage, pullSecret string, mirrorConfig []mirror.RegistriesConfig) (string, error) {
+	if urlOverride, ok := os.LookupEnv("OPENSHIFT_INSTALL_OS_IMAGE_OVERRIDE"); ok && urlOverride != "" {
+		logrus.Warn("Found override for OS Image. Please be warned, this is not advised")
+		return DownloadImageFile(urlOverride)
+	}
+	return fetchBaseIso(archName, releaseImage, pullSecret, mirrorConfig)
+}
+
+// fetchBaseIso returns the base ISO of the architecture, from the release
+// payload when the release image is set or else downloaded.
+func fetchBaseIso(archName, releaseImage, pullSecret string, mirrorConfig []mirror.RegistriesConfig) (string, error) {
+	// use the GetIso function to get the BaseIso from the release payload
+	if releaseImage != "" {
 		// If we have the image registry location and 'oc' command is available then get from release payload
 		ocRelease := NewRelease(
 			Config{MaxTries: OcDefaultTries, RetryDelay: OcDefaultRetryDelay},
-			releaseImage, pullSecret, registriesConf.MirrorConfig)
+			releaseImage, pullSecret, mirrorConfig)
 
-		logrus.Info("Extracting base ISO from release payload")
-		baseIsoFileName, err = ocRelease.GetBaseIso(archName)
+		logrus.Infof("Extracting %s base ISO from release payload", archName)
+		baseIsoFileName, err := ocRelease.GetBaseIso(archName)
 		if err == nil {
 			logrus.Debugf("Extracted base ISO image %s from release payload", baseIsoFileName)
-			i.File = &asset.File{Filename: baseIsoFileName}
 			return baseIsoFileName, nil
 		}
 
@@ -184,7 +188,7 @@ func (i *BaseIso) retrieveBaseIso(dependencies asset.Parents) (string, error) {
 		}
 	}
 
-	logrus.Info("Downloading base ISO")
+	logrus.Infof("Downloading %s base ISO", archName)
 	isoGetter := newGetIso(GetIsoPluggable)
 	return isoGetter.getter(archName)
 }
@@ -29,3 +29,17 @@ func TestInfraBaseIso_Generate(t *testing.T) {
 	baseIso := asset.Files()[0]
 	assert.Equal(t, baseIso.Filename, "some-openshift-release.iso")
 }
+
+func TestFetchBaseIsoDownload(t *testing.T) {
+	defer func(getter getIsoFile) { GetIsoPluggable = getter }(GetIsoPluggable)
+	var downloaded string
+	GetIsoPluggable = func(archName string) (string, error) {
+		downloaded = archName
+		return "rhcos-aarch64.iso", nil
+	}
+
+	baseIso, err := fetchBaseIso("aarch64", "", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "rhcos-aarch64.iso", baseIso)
+	assert.Equal(t, "aarch64", downloaded, "the ISO of the architecture is downloaded without a release image")
+}
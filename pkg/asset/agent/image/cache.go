@@ -17,6 +17,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/ulikunitz/xz"
 	"golang.org/x/sys/unix"
+
+	"github.com/openshift/installer/pkg/cachedir"
 )
 
 // Note this code resides in tfvars/internal so it can't be imported and was therefore
@@ -55,7 +57,7 @@ func GetCacheDir(dataType string) (string, error) {
 		return "", errors.Errorf("data type can't be an empty string")
 	}
 
-	userCacheDir, err := os.UserCacheDir()
+	userCacheDir, err := cachedir.UserCacheDir()
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,134 @@
+package image
+
+import (
+	"path"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/asset/ignition"
+)
+
+const (
+	hostConfigPath = "/etc/assisted/hostconfig"
+
+	hostConfigScriptPath = "/usr/local/bin/agent-host-config.sh"
+
+	hostInstallerArgsFile = "installer-args.json"
+
+	hostIgnitionConfigOverrideFile = "ignition-config-override.json"
+)
+
+// hostConfigScript updates the hosts registered with the Agent Rest API with
+// the installer arguments and the ignition config override of their host
+// config, when it runs on the
+// rendezvous host. The hosts are matched by the MAC addresses of their
+// inventory, and the installation waits until they are all updated.
+const hostConfigScript = `#!/bin/bash
+set -euo pipefail
+
+hostconfig_dir="${HOSTCONFIG_DIR:-` + hostConfigPath + `}"
+` + restAPIScriptPrelude + `
+
+for dir in "${hostconfig_dir}"/*/; do
+	installer_args="${dir}` + hostInstallerArgsFile + `"
+	ignition_config_override="${dir}` + hostIgnitionConfigOverrideFile + `"
+	if [ ! -f "${dir}mac_addresses" ] || { [ ! -f "${installer_args}" ] && [ ! -f "${ignition_config_override}" ]; }; then
+		continue
+	fi
+	name=$(basename "${dir}")
+	macs=$(jq -Rnc '[inputs | select(length > 0) | ascii_downcase]' < "${dir}mac_addresses")
+	until host_id=$(curl_api "${api}/infra-envs/${infra_env_id}/hosts" |
+		jq -er --argjson macs "${macs}" 'first(.[] | select((.inventory // "") != "") | select(any((.inventory | fromjson).interfaces[]?.mac_address | ascii_downcase; IN($macs[]))) | .id)'); do
+		echo "Waiting for the host ${name} to register"
+		sleep 10
+	done
+
+	if [ -f "${installer_args}" ]; then
+		echo "Updating the installer arguments of the host ${name}"
+		until jq -c '{args: .}' "${installer_args}" |
+			curl_api --request PATCH --data @- "${api}/infra-envs/${infra_env_id}/hosts/${host_id}/installer-args" > /dev/null; do
+			sleep 10
+		done
+	fi
+
+	if [ -f "${ignition_config_override}" ]; then
+		echo "Updating the ignition config override of the host ${name}"
+		until jq -Rsc '{config: .}' "${ignition_config_override}" |
+			curl_api --request PATCH --data @- "${api}/infra-envs/${infra_env_id}/hosts/${host_id}/ignition" > /dev/null; do
+			sleep 10
+		done
+	fi
+done
+`
+
+// restAPIScriptPrelude defines curl_api, calling the Agent Rest API of the
+// rendezvous host, exits when the script doesn't run on the rendezvous host,
+// and waits for the infra env, whose ID is infra_env_id.
+const restAPIScriptPrelude = `api="${SERVICE_BASE_URL}api/assisted-install/v2"
+
+curl_api() {
+	local args=(--silent --show-error --fail --header "Content-Type: application/json")
+	if [ -n "${USER_AUTH_TOKEN:-}" ]; then
+		args+=(--header "` + RestAPIAuthHeader + `: ${USER_AUTH_TOKEN}")
+	fi
+	if [ -n "${SERVICE_CA_CERT_PATH:-}" ]; then
+		args+=(--cacert "${SERVICE_CA_CERT_PATH}")
+	fi
+	curl "${args[@]}" "$@"
+}
+
+if ! ip -o addr show | awk '{split($4, a, "/"); print a[1]}' | grep -qxF "${NODE_ZERO_IP}"; then
+	echo "This host is not the rendezvous host"
+	exit 0
+fi
+
+until infra_env_id=$(curl_api "${api}/infra-envs" | jq -er '.[0].id'); do
+	echo "Waiting for the infra env"
+	sleep 10
+done
+`
+
+const hostConfigUnit = `[Unit]
+Description=Update the registered hosts with their host config
+Wants=network-online.target
+After=network-online.target agent-rendezvous-discovery.service assisted-service.service apply-host-config.service
+Before=start-cluster-installation.service
+ConditionPathExists=` + hostConfigScriptPath + `
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+EnvironmentFile=` + rendezvousHostEnvPath + `
+ExecStart=` + hostConfigScriptPath + `
+StandardOutput=journal+console
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// addHostConfigService adds the service updating the registered hosts with
+// the parts of their host config that the agent installer client doesn't
+// apply, if any.
+func addHostConfigService(config *igntypes.Config, files agentconfig.HostConfigFileMap) {
+	found := false
+	for name := range files {
+		switch path.Base(name) {
+		case hostInstallerArgsFile, hostIgnitionConfigOverrideFile:
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	config.Storage.Files = append(config.Storage.Files,
+		ignition.FileFromString(hostConfigScriptPath, "root", 0755, hostConfigScript))
+	enabled := true
+	contents := hostConfigUnit
+	config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+		Name:     "agent-host-config.service",
+		Enabled:  &enabled,
+		Contents: &contents,
+	})
+}
@@ -0,0 +1,126 @@
+package image
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+)
+
+func TestAddHostConfigService(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    agentconfig.HostConfigFileMap
+		expected bool
+	}{
+		{
+			name: "no-host-config-to-update",
+			files: agentconfig.HostConfigFileMap{
+				"master-0/mac_addresses": []byte("52:54:00:aa:bb:01\n"),
+				"master-0/role":          []byte("master"),
+			},
+		},
+		{
+			name: "installer-args",
+			files: agentconfig.HostConfigFileMap{
+				"master-0/mac_addresses":       []byte("52:54:00:aa:bb:01\n"),
+				"master-0/installer-args.json": []byte(`["--append-karg","console=ttyS0"]`),
+			},
+			expected: true,
+		},
+		{
+			name: "ignition-config-override",
+			files: agentconfig.HostConfigFileMap{
+				"master-0/mac_addresses":                 []byte("52:54:00:aa:bb:01\n"),
+				"master-0/ignition-config-override.json": []byte(`{"ignition":{"version":"3.2.0"}}`),
+			},
+			expected: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &igntypes.Config{}
+			addHostConfigService(config, tc.files)
+			if !tc.expected {
+				assert.Empty(t, config.Storage.Files)
+				assert.Empty(t, config.Systemd.Units)
+				return
+			}
+			if assert.Len(t, config.Storage.Files, 1) {
+				assert.Equal(t, hostConfigScriptPath, config.Storage.Files[0].Path)
+			}
+			if assert.Len(t, config.Systemd.Units, 1) {
+				assert.Equal(t, "agent-host-config.service", config.Systemd.Units[0].Name)
+			}
+		})
+	}
+}
+
+func TestHostConfigScript(t *testing.T) {
+	for _, command := range []string{"bash", "curl", "jq", "ip"} {
+		if _, err := exec.LookPath(command); err != nil {
+			t.Skipf("%s is not installed", command)
+		}
+	}
+
+	updates := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get(RestAPIAuthHeader))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/assisted-install/v2/infra-envs":
+			w.Write([]byte(`[{"id":"infra-env"}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/assisted-install/v2/infra-envs/infra-env/hosts":
+			master, _ := json.Marshal(`{"interfaces":[{"mac_address":"52:54:00:aa:bb:01"}]}`)
+			worker, _ := json.Marshal(`{"interfaces":[{"mac_address":"52:54:00:AA:BB:02"}]}`)
+			w.Write([]byte(`[{"id":"unknown"},{"id":"master-0-id","inventory":` + string(master) + `},{"id":"worker-0-id","inventory":` + string(worker) + `}]`))
+		case r.Method == http.MethodPatch:
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			updates[r.URL.Path] = string(body)
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "agent-host-config.sh")
+	require.NoError(t, os.WriteFile(script, []byte(hostConfigScript), 0755)) //nolint:gosec // the script must be executable
+	hostConfigDir := filepath.Join(dir, "hostconfig")
+	for name, content := range map[string]string{
+		"master-0/mac_addresses":                 "52:54:00:aa:bb:01\n",
+		"master-0/role":                          "master",
+		"master-0/ignition-config-override.json": `{"ignition":{"version":"3.2.0"}}`,
+		"worker-0/mac_addresses":                 "52:54:00:aa:bb:02\n52:54:00:aa:bb:03\n",
+		"worker-0/installer-args.json":           `["--append-karg","console=ttyS0"]`,
+	} {
+		path := filepath.Join(hostConfigDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	cmd := exec.Command("bash", script)
+	cmd.Env = append(os.Environ(),
+		"HOSTCONFIG_DIR="+hostConfigDir,
+		"NODE_ZERO_IP=127.0.0.1",
+		"SERVICE_BASE_URL="+server.URL+"/",
+		"USER_AUTH_TOKEN=token",
+	)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	assert.Equal(t, map[string]string{
+		"/api/assisted-install/v2/infra-envs/infra-env/hosts/master-0-id/ignition":       `{"config":"{\"ignition\":{\"version\":\"3.2.0\"}}"}`,
+		"/api/assisted-install/v2/infra-envs/infra-env/hosts/worker-0-id/installer-args": `{"args":["--append-karg","console=ttyS0"]}`,
+	}, updates)
+}
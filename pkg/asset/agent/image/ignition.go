@@ -87,6 +87,8 @@ func (a *Ignition) Dependencies() []asset.Asset {
 		&agentconfig.AgentConfig{},
 		&mirror.RegistriesConf{},
 		&mirror.CaBundle{},
+		&RestAPIAuth{},
+		&InteractiveConfig{},
 	}
 }
 
@@ -120,29 +122,38 @@ func (a *Ignition) Generate(dependencies asset.Parents) error {
 		},
 	}
 
-	nodeZeroIP, err := RetrieveRendezvousIP(agentConfigAsset.Config, agentManifests.NMStateConfigs)
-	if err != nil {
-		return err
+	// The IP of the rendezvous host is replaced by the hosts when they
+	// discover it.
+	nodeZeroIP := rendezvousIPPlaceholder
+	rendezvousMAC := agentconfig.RendezvousMACAddress(agentConfigAsset.Config)
+	if rendezvousMAC != "" {
+		logrus.Infof("The rendezvous host (node0) is discovered by the hosts, it is the host with the MAC address %s", rendezvousMAC)
+	} else {
+		var err error
+		nodeZeroIP, err = RetrieveRendezvousIP(agentConfigAsset.Config, agentManifests.NMStateConfigs)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("The rendezvous host IP (node0 IP) is %s", nodeZeroIP)
+		a.RendezvousIP = nodeZeroIP
 	}
-
-	logrus.Infof("The rendezvous host IP (node0 IP) is %s", nodeZeroIP)
-
-	a.RendezvousIP = nodeZeroIP
 	// Default to x86_64
 	archName := arch.RpmArch(types.ArchitectureAMD64)
 	if infraEnv.Spec.CpuArchitecture != "" {
 		archName = infraEnv.Spec.CpuArchitecture
 	}
 
-	releaseImageList, err := releaseImageList(agentManifests.ClusterImageSet.Spec.ReleaseImage, archName)
-	if err != nil {
-		return err
-	}
-
 	registriesConfig := &mirror.RegistriesConf{}
 	registryCABundle := &mirror.CaBundle{}
 	dependencies.Get(registriesConfig, registryCABundle)
 
+	releaseImage := agentManifests.ClusterImageSet.Spec.ReleaseImage
+	multiArchRelease := isMultiArchRelease(releaseImage, agentManifests.GetPullSecretData(), registriesConfig.MirrorConfig)
+	releaseImageList, err := releaseImageList(releaseImage, archName, multiArchRelease)
+	if err != nil {
+		return err
+	}
+
 	publicContainerRegistries := getPublicContainerRegistries(registriesConfig)
 
 	releaseImageMirror := mirror.GetMirrorFromRelease(agentManifests.ClusterImageSet.Spec.ReleaseImage, registriesConfig)
@@ -166,6 +177,9 @@ func (a *Ignition) Generate(dependencies asset.Parents) error {
 		imageTypeISO = "minimal-iso"
 	}
 
+	restAPIAuth := &RestAPIAuth{}
+	dependencies.Get(restAPIAuth)
+
 	agentTemplateData := getTemplateData(
 		clusterName,
 		agentManifests.GetPullSecretData(),
@@ -179,16 +193,21 @@ func (a *Ignition) Generate(dependencies asset.Parents) error {
 		osImage,
 		infraEnv.Spec.Proxy,
 		imageTypeISO)
+	if restAPIAuth.UserToken != "" {
+		agentTemplateData.ServiceProtocol = "https"
+	}
 
 	err = bootstrap.AddStorageFiles(&config, "/", "agent/files", agentTemplateData)
 	if err != nil {
 		return err
 	}
 
+	// The file holds the tokens of the Agent Rest API.
 	rendezvousHostFile := ignition.FileFromString(rendezvousHostEnvPath,
-		"root", 0644,
-		getRendezvousHostEnv(agentTemplateData.ServiceProtocol, nodeZeroIP))
+		"root", 0600,
+		getRendezvousHostEnv(agentTemplateData.ServiceProtocol, nodeZeroIP, restAPIAuth))
 	config.Storage.Files = append(config.Storage.Files, rendezvousHostFile)
+	addRestAPIAuthData(&config, restAPIAuth)
 
 	err = addBootstrapScripts(&config, agentManifests.ClusterImageSet.Spec.ReleaseImage)
 	if err != nil {
@@ -204,8 +223,12 @@ func (a *Ignition) Generate(dependencies asset.Parents) error {
 
 	// add AgentConfig if provided
 	if agentConfigAsset.Config != nil {
+		agentConfigData, err := agentConfigAsset.HostsData()
+		if err != nil {
+			return err
+		}
 		agentConfigFile := ignition.FileFromBytes(filepath.Join(manifestPath, filepath.Base(agentConfigAsset.File.Filename)),
-			"root", 0600, agentConfigAsset.File.Data)
+			"root", 0600, agentConfigData)
 		config.Storage.Files = append(config.Storage.Files, agentConfigFile)
 	}
 
@@ -227,6 +250,13 @@ func (a *Ignition) Generate(dependencies asset.Parents) error {
 		return err
 	}
 
+	interactiveConfig := &InteractiveConfig{}
+	dependencies.Get(interactiveConfig)
+	err = addInteractiveConfig(&config, interactiveConfig, a.RendezvousIP)
+	if err != nil {
+		return err
+	}
+
 	addTLSData(&config, dependencies)
 
 	addMirrorData(&config, registriesConfig, registryCABundle)
@@ -236,11 +266,29 @@ func (a *Ignition) Generate(dependencies asset.Parents) error {
 		return err
 	}
 
+	err = addNodeManifests(&config, agentConfigAsset)
+	if err != nil {
+		return err
+	}
+
+	addISOCustomization(&config, agentConfigAsset)
+
+	if rendezvousMAC != "" {
+		addRendezvousDiscovery(&config, rendezvousMAC, agentConfigAsset.Config.RendezvousDiscovery.Hostname)
+	}
+
 	err = addExtraManifests(&config, extraManifests)
 	if err != nil {
 		return err
 	}
 
+	if EmbedRelease {
+		provisionRequirements := agentManifests.AgentClusterInstall.Spec.ProvisionRequirements
+		if err := addReleasePayload(&config, provisionRequirements.ControlPlaneAgents+provisionRequirements.WorkerAgents); err != nil {
+			return err
+		}
+	}
+
 	a.Config = &config
 	return nil
 }
@@ -312,22 +360,58 @@ func getTemplateData(name, pullSecret, releaseImageList, releaseImage,
 	}
 }
 
-func getRendezvousHostEnv(serviceProtocol, nodeZeroIP string) string {
+// getRendezvousHostEnv returns the environment of the services of the
+// rendezvous host, with the configuration of the TLS and of the authentication
+// of the Agent Rest API when auth is set.
+func getRendezvousHostEnv(serviceProtocol, nodeZeroIP string, auth *RestAPIAuth) string {
+	serviceHost := nodeZeroIP
+	if nodeZeroIP == rendezvousIPPlaceholder && auth != nil && auth.UserToken != "" {
+		// The certificate of a discovered rendezvous host is issued for
+		// its name, which the hosts resolve to the IP they discovered.
+		serviceHost = RendezvousServerName
+	}
 	serviceBaseURL := url.URL{
 		Scheme: serviceProtocol,
-		Host:   net.JoinHostPort(nodeZeroIP, "8090"),
+		Host:   net.JoinHostPort(serviceHost, "8090"),
 		Path:   "/",
 	}
 	imageServiceBaseURL := url.URL{
 		Scheme: serviceProtocol,
-		Host:   net.JoinHostPort(nodeZeroIP, "8888"),
+		Host:   net.JoinHostPort(serviceHost, "8888"),
 		Path:   "/",
 	}
 
-	return fmt.Sprintf(`NODE_ZERO_IP=%s
+	env := fmt.Sprintf(`NODE_ZERO_IP=%s
 SERVICE_BASE_URL=%s
 IMAGE_SERVICE_BASE_URL=%s
 `, nodeZeroIP, serviceBaseURL.String(), imageServiceBaseURL.String())
+	if auth == nil || auth.UserToken == "" {
+		return env
+	}
+	return env + fmt.Sprintf(`SERVE_HTTPS=true
+HTTPS_CERT_FILE=%s
+HTTPS_KEY_FILE=%s
+SERVICE_CA_CERT_PATH=%s
+AUTH_TYPE=local
+EC_PUBLIC_KEY_PEM_PATH=%s
+USER_AUTH_TOKEN=%s
+AGENT_AUTH_TOKEN=%s
+`, path.Join(restAPITLSPath, "agent-rest-api.crt"), path.Join(restAPITLSPath, "agent-rest-api.key"),
+		path.Join(restAPITLSPath, "agent-rest-api-ca.crt"), path.Join(restAPITLSPath, "agent-rest-api-auth.pub"),
+		auth.UserToken, auth.AgentToken)
+}
+
+// addRestAPIAuthData adds the certificates of the Agent Rest API and the key
+// verifying its tokens to the rendezvous host.
+func addRestAPIAuthData(config *igntypes.Config, auth *RestAPIAuth) {
+	if auth.UserToken == "" {
+		return
+	}
+	config.Storage.Files = append(config.Storage.Files,
+		ignition.FileFromBytes(path.Join(restAPITLSPath, "agent-rest-api-ca.crt"), "root", 0644, auth.CACert),
+		ignition.FileFromBytes(path.Join(restAPITLSPath, "agent-rest-api-auth.pub"), "root", 0644, auth.PublicKey),
+		ignition.FileFromBytes(path.Join(restAPITLSPath, "agent-rest-api.crt"), "root", 0644, auth.ServerCert),
+		ignition.FileFromBytes(path.Join(restAPITLSPath, "agent-rest-api.key"), "root", 0600, auth.ServerKey))
 }
 
 func addStaticNetworkConfig(config *igntypes.Config, staticNetworkConfig []*models.HostStaticNetworkConfig) (err error) {
@@ -420,9 +504,10 @@ func addHostConfig(config *igntypes.Config, agentConfig *agentconfig.AgentConfig
 	}
 
 	for path, content := range confs {
-		hostConfigFile := ignition.FileFromBytes(filepath.Join("/etc/assisted/hostconfig", path), "root", 0644, content)
+		hostConfigFile := ignition.FileFromBytes(filepath.Join(hostConfigPath, path), "root", 0644, content)
 		config.Storage.Files = append(config.Storage.Files, hostConfigFile)
 	}
+	addHostConfigService(config, confs)
 	return nil
 }
 
@@ -65,7 +65,7 @@ func TestIgnition_getTemplateData(t *testing.T) {
 	haveMirrorConfig := true
 	publicContainerRegistries := "quay.io,registry.ci.openshift.org"
 
-	releaseImageList, err := releaseImageList(clusterImageSet.Spec.ReleaseImage, "x86_64")
+	releaseImageList, err := releaseImageList(clusterImageSet.Spec.ReleaseImage, "x86_64", false)
 	assert.NoError(t, err)
 
 	arch := "x86_64"
@@ -104,10 +104,24 @@ func TestIgnition_getTemplateData(t *testing.T) {
 
 func TestIgnition_getRendezvousHostEnv(t *testing.T) {
 	nodeZeroIP := "2001:db8::dead:beef"
-	rendezvousHostEnv := getRendezvousHostEnv("http", nodeZeroIP)
+	rendezvousHostEnv := getRendezvousHostEnv("http", nodeZeroIP, nil)
 	assert.Equal(t,
 		"NODE_ZERO_IP="+nodeZeroIP+"\nSERVICE_BASE_URL=http://["+nodeZeroIP+"]:8090/\nIMAGE_SERVICE_BASE_URL=http://["+nodeZeroIP+"]:8888/\n",
 		rendezvousHostEnv)
+
+	rendezvousHostEnv = getRendezvousHostEnv("https", nodeZeroIP, &RestAPIAuth{UserToken: "user-token", AgentToken: "agent-token"})
+	assert.Equal(t,
+		"NODE_ZERO_IP="+nodeZeroIP+"\nSERVICE_BASE_URL=https://["+nodeZeroIP+"]:8090/\nIMAGE_SERVICE_BASE_URL=https://["+nodeZeroIP+"]:8888/\n"+
+			"SERVE_HTTPS=true\nHTTPS_CERT_FILE=/opt/agent/tls/agent-rest-api.crt\nHTTPS_KEY_FILE=/opt/agent/tls/agent-rest-api.key\n"+
+			"SERVICE_CA_CERT_PATH=/opt/agent/tls/agent-rest-api-ca.crt\nAUTH_TYPE=local\nEC_PUBLIC_KEY_PEM_PATH=/opt/agent/tls/agent-rest-api-auth.pub\n"+
+			"USER_AUTH_TOKEN=user-token\nAGENT_AUTH_TOKEN=agent-token\n",
+		rendezvousHostEnv)
+
+	// The discovered rendezvous host is reached by the name of its
+	// certificate.
+	rendezvousHostEnv = getRendezvousHostEnv("https", rendezvousIPPlaceholder, &RestAPIAuth{UserToken: "user-token", AgentToken: "agent-token"})
+	assert.Contains(t, rendezvousHostEnv,
+		"NODE_ZERO_IP=__RENDEZVOUS_IP__\nSERVICE_BASE_URL=https://rendezvous.agent.internal:8090/\nIMAGE_SERVICE_BASE_URL=https://rendezvous.agent.internal:8888/\n")
 }
 
 func TestIgnition_addStaticNetworkConfig(t *testing.T) {
@@ -333,6 +347,66 @@ func TestAddHostConfig_Roles(t *testing.T) {
 	}
 }
 
+func TestAddNodeManifests(t *testing.T) {
+	agentConfig := &agentconfig.AgentConfig{
+		Config: &agent.Config{
+			Hosts: []agent.Host{
+				{
+					Hostname: "master-0",
+					Role:     "master",
+				},
+				{
+					Hostname:   "worker-0",
+					Role:       "worker",
+					NodeLabels: map[string]string{"cluster.ocs.openshift.io/openshift-storage": ""},
+					NodeTaints: []v1.Taint{
+						{Key: "node.ocs.openshift.io/storage", Value: "true", Effect: v1.TaintEffectNoSchedule},
+					},
+				},
+			},
+		},
+	}
+
+	config := &igntypes.Config{}
+	err := addNodeManifests(config, agentConfig)
+	assert.NoError(t, err)
+	if assert.Len(t, config.Storage.Files, 1) {
+		assert.Equal(t, "/etc/assisted/extra-manifests/agent-node-worker-0.yaml", config.Storage.Files[0].Path)
+		data, err := dataurl.DecodeString(*config.Storage.Files[0].Contents.Source)
+		assert.NoError(t, err)
+		assert.Equal(t, `apiVersion: v1
+kind: Node
+metadata:
+  creationTimestamp: null
+  labels:
+    cluster.ocs.openshift.io/openshift-storage: ""
+    node-role.kubernetes.io/worker: ""
+    node.openshift.io/os_id: rhcos
+  name: worker-0
+spec:
+  taints:
+  - effect: NoSchedule
+    key: node.ocs.openshift.io/storage
+    value: "true"
+status:
+  daemonEndpoints:
+    kubeletEndpoint:
+      Port: 0
+  nodeInfo:
+    architecture: ""
+    bootID: ""
+    containerRuntimeVersion: ""
+    kernelVersion: ""
+    kubeProxyVersion: ""
+    kubeletVersion: ""
+    machineID: ""
+    operatingSystem: ""
+    osImage: ""
+    systemUUID: ""
+`, string(data.Data))
+	}
+}
+
 func generatedFiles(otherFiles ...string) []string {
 	files := []string{
 		"/etc/assisted/rendezvous-host.env",
@@ -650,6 +724,7 @@ func buildIgnitionAssetDefaultDependencies(t *testing.T) []asset.Asset {
 		&manifests.ExtraManifests{},
 		&mirror.RegistriesConf{},
 		&mirror.CaBundle{},
+		&RestAPIAuth{},
 		&password.KubeadminPassword{},
 		&tls.KubeAPIServerLBSignerCertKey{},
 		&tls.KubeAPIServerLocalhostSignerCertKey{},
@@ -0,0 +1,154 @@
+package image
+
+import (
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/assisted-service/models"
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	"github.com/openshift/installer/pkg/asset/ignition"
+)
+
+// interactiveConsoleConnectionsPath is where NetworkManager loads the
+// connections of the network configuration of the interactive console from.
+const interactiveConsoleConnectionsPath = "/etc/NetworkManager/system-connections"
+
+// interactiveConsoleUnits are the units of the interactive console of the
+// agent, the agent-tui, which are masked when it is unattended.
+var interactiveConsoleUnits = []string{"agent-interactive-console.service", "agent-interactive-console-serial@.service"}
+
+// InteractiveConfigFile is the path of the file pre-seeding the interactive
+// console of the agent, the agent-tui, of the generated images.
+var InteractiveConfigFile string
+
+// interactiveConfig is the configuration of the interactive console of the
+// agent.
+type interactiveConfig struct {
+	// RendezvousIP is the IP of the rendezvous host, which must match the
+	// one of agent-config.yaml, if any.
+	RendezvousIP string `json:"rendezvousIP,omitempty"`
+	// NetworkConfig is the NMState configuration of the hosts, converted to
+	// NetworkManager connections which the console checks the connectivity
+	// of and lets the users change.
+	NetworkConfig map[string]interface{} `json:"networkConfig,omitempty"`
+	// Unattended masks the console, the hosts do not wait for the users to
+	// check the pre-seeded network configuration.
+	Unattended bool `json:"unattended,omitempty"`
+}
+
+// InteractiveConfig is an asset with the configuration of the
+// InteractiveConfigFile. It is always loaded from the file, so that changing
+// the file, or not setting it anymore, regenerates the ignition instead of
+// keeping the configuration of the state file.
+type InteractiveConfig struct {
+	Config *interactiveConfig
+}
+
+var _ asset.WritableAsset = (*InteractiveConfig)(nil)
+
+// Name returns the human-friendly name of the asset.
+func (*InteractiveConfig) Name() string {
+	return "Agent Interactive Console Config"
+}
+
+// Dependencies returns no dependencies.
+func (*InteractiveConfig) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Generate reads the InteractiveConfigFile.
+func (a *InteractiveConfig) Generate(asset.Parents) error {
+	return a.load()
+}
+
+// Files returns no files, the InteractiveConfigFile belongs to the users.
+func (*InteractiveConfig) Files() []*asset.File {
+	return nil
+}
+
+// Load reads the InteractiveConfigFile, and always finds the asset.
+func (a *InteractiveConfig) Load(asset.FileFetcher) (bool, error) {
+	return true, a.load()
+}
+
+// load reads and validates the InteractiveConfigFile, if set.
+func (a *InteractiveConfig) load() error {
+	a.Config = nil
+	if InteractiveConfigFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(InteractiveConfigFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the interactive console configuration")
+	}
+	config := &interactiveConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return errors.Wrapf(err, "failed to parse the interactive console configuration %s", InteractiveConfigFile)
+	}
+	if config.RendezvousIP != "" && net.ParseIP(config.RendezvousIP) == nil {
+		return errors.Errorf("invalid rendezvousIP %q in the interactive console configuration %s", config.RendezvousIP, InteractiveConfigFile)
+	}
+	a.Config = config
+	return nil
+}
+
+// addInteractiveConfig adds the network configuration of the interactive
+// console config to the hosts, and masks the console when it is unattended.
+// The rendezvous IP of the config must be the one of the hosts, if any.
+func addInteractiveConfig(config *igntypes.Config, interactiveConfig *InteractiveConfig, rendezvousIP string) error {
+	if interactiveConfig == nil || interactiveConfig.Config == nil {
+		return nil
+	}
+	consoleConfig := interactiveConfig.Config
+
+	if consoleConfig.RendezvousIP != "" && consoleConfig.RendezvousIP != rendezvousIP {
+		if rendezvousIP == "" {
+			return errors.Errorf("the rendezvousIP %s of the interactive console configuration is not supported, the rendezvous host is not set by its IP in agent-config.yaml", consoleConfig.RendezvousIP)
+		}
+		return errors.Errorf("the rendezvousIP %s of the interactive console configuration conflicts with the rendezvous host %s", consoleConfig.RendezvousIP, rendezvousIP)
+	}
+
+	if len(consoleConfig.NetworkConfig) > 0 {
+		networkYAML, err := yaml.Marshal(consoleConfig.NetworkConfig)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the network configuration of the interactive console")
+		}
+		files, err := manifests.GetNMIgnitionFiles([]*models.HostStaticNetworkConfig{{NetworkYaml: string(networkYAML)}})
+		if err != nil {
+			return errors.Wrap(err, "failed to convert the network configuration of the interactive console")
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file.FilePath, ".nmconnection") {
+				continue
+			}
+			config.Storage.Files = append(config.Storage.Files, ignition.FileFromString(
+				path.Join(interactiveConsoleConnectionsPath, path.Base(file.FilePath)), "root", 0600, file.FileContents))
+		}
+	}
+
+	if consoleConfig.Unattended {
+		masked := true
+		for _, name := range interactiveConsoleUnits {
+			found := false
+			for i := range config.Systemd.Units {
+				if config.Systemd.Units[i].Name == name {
+					config.Systemd.Units[i].Mask = &masked
+					config.Systemd.Units[i].Enabled = nil
+					config.Systemd.Units[i].Contents = nil
+					found = true
+				}
+			}
+			if !found {
+				config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{Name: name, Mask: &masked})
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package image
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInteractiveConfigLoad(t *testing.T) {
+	cases := []struct {
+		name          string
+		data          string
+		expected      *interactiveConfig
+		expectedError string
+	}{
+		{
+			name: "unattended",
+			data: `rendezvousIP: 192.168.111.80
+unattended: true
+`,
+			expected: &interactiveConfig{RendezvousIP: "192.168.111.80", Unattended: true},
+		},
+		{
+			name:          "invalid rendezvous IP",
+			data:          "rendezvousIP: node0\n",
+			expectedError: `invalid rendezvousIP "node0" in the interactive console configuration`,
+		},
+		{
+			name:          "unknown field",
+			data:          "rendezvousHost: 192.168.111.80\n",
+			expectedError: "failed to parse the interactive console configuration",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			InteractiveConfigFile = filepath.Join(t.TempDir(), "interactive-config.yaml")
+			defer func() { InteractiveConfigFile = "" }()
+			require.NoError(t, os.WriteFile(InteractiveConfigFile, []byte(tc.data), 0600))
+
+			interactiveConfig := &InteractiveConfig{}
+			found, err := interactiveConfig.Load(nil)
+			assert.True(t, found)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, interactiveConfig.Config)
+		})
+	}
+}
+
+func TestInteractiveConfigLoadUnset(t *testing.T) {
+	interactiveConfig := &InteractiveConfig{Config: &interactiveConfig{Unattended: true}}
+	found, err := interactiveConfig.Load(nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Nil(t, interactiveConfig.Config)
+}
+
+func TestAddInteractiveConfig(t *testing.T) {
+	cases := []struct {
+		name          string
+		config        *interactiveConfig
+		rendezvousIP  string
+		expectedMask  bool
+		expectedError string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name:         "unattended",
+			config:       &interactiveConfig{RendezvousIP: "192.168.111.80", Unattended: true},
+			rendezvousIP: "192.168.111.80",
+			expectedMask: true,
+		},
+		{
+			name:          "conflicting rendezvous IP",
+			config:        &interactiveConfig{RendezvousIP: "192.168.111.81"},
+			rendezvousIP:  "192.168.111.80",
+			expectedError: "the rendezvousIP 192.168.111.81 of the interactive console configuration conflicts with the rendezvous host 192.168.111.80",
+		},
+		{
+			name:          "rendezvous IP without rendezvous host",
+			config:        &interactiveConfig{RendezvousIP: "192.168.111.80"},
+			expectedError: "the rendezvous host is not set by its IP in agent-config.yaml",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			enabled := true
+			contents := "[Service]\n"
+			config := &igntypes.Config{
+				Systemd: igntypes.Systemd{
+					Units: []igntypes.Unit{{Name: "agent-interactive-console.service", Enabled: &enabled, Contents: &contents}},
+				},
+			}
+			err := addInteractiveConfig(config, &InteractiveConfig{Config: tc.config}, tc.rendezvousIP)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			if !tc.expectedMask {
+				assert.Len(t, config.Systemd.Units, 1)
+				assert.Nil(t, config.Systemd.Units[0].Mask)
+				return
+			}
+			require.Len(t, config.Systemd.Units, 2)
+			for _, unit := range config.Systemd.Units {
+				assert.True(t, *unit.Mask, unit.Name)
+				assert.Nil(t, unit.Enabled, unit.Name)
+				assert.Nil(t, unit.Contents, unit.Name)
+			}
+		})
+	}
+}
+
+func TestAddInteractiveConfigNetworkConfig(t *testing.T) {
+	if _, err := exec.LookPath("nmstatectl"); err != nil {
+		t.Skip("nmstatectl is not installed")
+	}
+
+	config := &igntypes.Config{}
+	err := addInteractiveConfig(config, &InteractiveConfig{
+		Config: &interactiveConfig{
+			NetworkConfig: map[string]interface{}{
+				"interfaces": []interface{}{
+					map[string]interface{}{"name": "eth0", "type": "ethernet", "state": "up"},
+				},
+			},
+		},
+	}, "")
+	require.NoError(t, err)
+	require.Len(t, config.Storage.Files, 1)
+	assert.Equal(t, "/etc/NetworkManager/system-connections/eth0.nmconnection", config.Storage.Files[0].Path)
+}
@@ -0,0 +1,139 @@
+package image
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/asset/ignition"
+	"github.com/openshift/installer/pkg/types/agent"
+)
+
+const (
+	isoCustomizationPath       = "/etc/assisted/iso-customization"
+	isoCustomizationScriptPath = "/usr/local/bin/agent-iso-customization.sh"
+)
+
+// isoCustomizationScript runs the scripts shared by all the hosts, then
+// installs the files and runs the scripts of the host, found by the MAC
+// address of one of its interfaces.
+const isoCustomizationScript = `#!/bin/bash
+set -euo pipefail
+
+run_scripts() {
+	for script in "$1"/scripts/*; do
+		[ -f "${script}" ] || continue
+		echo "Running ${script}"
+		"${script}"
+	done
+}
+
+if [ -d "` + isoCustomizationPath + `/scripts" ]; then
+	run_scripts "` + isoCustomizationPath + `"
+fi
+
+for host in "` + isoCustomizationPath + `"/hosts/*; do
+	[ -f "${host}/mac_addresses" ] || continue
+	for address in $(cat /sys/class/net/*/address); do
+		if grep -qxF "${address}" "${host}/mac_addresses"; then
+			if [ -d "${host}/files" ]; then
+				cp -a "${host}/files/." /
+			fi
+			run_scripts "${host}"
+			exit 0
+		fi
+	done
+done
+`
+
+const isoCustomizationUnit = `[Unit]
+Description=Apply the ISO customization of the agent config
+Wants=network-online.target
+After=network-online.target
+Before=agent.service node-zero.service
+ConditionPathExists=` + isoCustomizationScriptPath + `
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=` + isoCustomizationScriptPath + `
+StandardOutput=journal+console
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// addISOCustomization adds the files and the scripts of the ISO customization
+// of the agent config, and the service applying them when the ISO boots.
+func addISOCustomization(config *igntypes.Config, agentConfig *agentconfig.AgentConfig) {
+	if agentConfig.Config == nil {
+		return
+	}
+
+	found := false
+	if customization := agentConfig.Config.ISOCustomization; customization != nil {
+		for _, file := range customization.Files {
+			config.Storage.Files = append(config.Storage.Files, isoFile(file.Path, file))
+		}
+		addISOScripts(config, isoCustomizationPath, customization.Scripts)
+		found = len(customization.Files) > 0 || len(customization.Scripts) > 0
+	}
+
+	for i, host := range agentConfig.Config.Hosts {
+		customization := host.ISOCustomization
+		if customization == nil || (len(customization.Files) == 0 && len(customization.Scripts) == 0) {
+			continue
+		}
+		found = true
+
+		name := fmt.Sprintf("host-%d", i)
+		if host.Hostname != "" {
+			name = host.Hostname
+		}
+		hostPath := filepath.Join(isoCustomizationPath, "hosts", name)
+
+		macs := []string{}
+		for _, iface := range host.Interfaces {
+			macs = append(macs, strings.ToLower(iface.MacAddress)+"\n")
+		}
+		config.Storage.Files = append(config.Storage.Files,
+			ignition.FileFromString(filepath.Join(hostPath, "mac_addresses"), "root", 0644, strings.Join(macs, "")))
+
+		for _, file := range customization.Files {
+			config.Storage.Files = append(config.Storage.Files, isoFile(filepath.Join(hostPath, "files", file.Path), file))
+		}
+		addISOScripts(config, hostPath, customization.Scripts)
+	}
+
+	if !found {
+		return
+	}
+	config.Storage.Files = append(config.Storage.Files, ignition.FileFromString(isoCustomizationScriptPath, "root", 0755, isoCustomizationScript))
+	enabled := true
+	contents := isoCustomizationUnit
+	config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+		Name:     "agent-iso-customization.service",
+		Enabled:  &enabled,
+		Contents: &contents,
+	})
+}
+
+func isoFile(path string, file agent.ISOFile) igntypes.File {
+	mode := 0644
+	if file.Mode != nil {
+		mode = *file.Mode
+	}
+	return ignition.FileFromString(path, "root", mode, file.Contents)
+}
+
+// addISOScripts adds the scripts to the scripts directory of dir, prefixed
+// with their index so that they run in order.
+func addISOScripts(config *igntypes.Config, dir string, scripts []agent.ISOScript) {
+	for i, script := range scripts {
+		path := filepath.Join(dir, "scripts", fmt.Sprintf("%03d-%s", i, script.Name))
+		config.Storage.Files = append(config.Storage.Files, ignition.FileFromString(path, "root", 0755, script.Contents))
+	}
+}
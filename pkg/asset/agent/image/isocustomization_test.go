@@ -0,0 +1,148 @@
+package image
+
+import (
+	"testing"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vincent-petithory/dataurl"
+
+	hiveext "github.com/openshift/assisted-service/api/hiveextension/v1beta1"
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	"github.com/openshift/installer/pkg/types/agent"
+)
+
+func TestAddISOCustomization(t *testing.T) {
+	mode := 0600
+	agentConfig := &agentconfig.AgentConfig{
+		Config: &agent.Config{
+			ISOCustomization: &agent.ISOCustomization{
+				KernelArguments: []string{"rd.debug"},
+				Files: []agent.ISOFile{
+					{Path: "/etc/chrony.d/lab.conf", Contents: "server ntp.lab\n"},
+				},
+				Scripts: []agent.ISOScript{
+					{Name: "firmware.sh", Contents: "#!/bin/bash\necho firmware\n"},
+				},
+			},
+			Hosts: []agent.Host{
+				{
+					Hostname:   "control-0",
+					Interfaces: []*v1beta1.Interface{{Name: "eth0", MacAddress: "28:D2:44:D2:B2:1A"}},
+					ISOCustomization: &agent.HostISOCustomization{
+						Files: []agent.ISOFile{
+							{Path: "/etc/sysconfig/raid", Contents: "RAID=1\n", Mode: &mode},
+						},
+					},
+				},
+				{
+					Hostname:   "control-1",
+					Interfaces: []*v1beta1.Interface{{Name: "eth0", MacAddress: "28:d2:44:d2:b2:1b"}},
+				},
+			},
+		},
+	}
+
+	config := &igntypes.Config{}
+	addISOCustomization(config, agentConfig)
+
+	files := map[string]string{}
+	modes := map[string]int{}
+	for _, file := range config.Storage.Files {
+		data, err := dataurl.DecodeString(*file.Contents.Source)
+		require.NoError(t, err)
+		files[file.Path] = string(data.Data)
+		modes[file.Path] = *file.Mode
+	}
+	assert.Equal(t, map[string]string{
+		"/etc/chrony.d/lab.conf":                                                   "server ntp.lab\n",
+		"/etc/assisted/iso-customization/scripts/000-firmware.sh":                  "#!/bin/bash\necho firmware\n",
+		"/etc/assisted/iso-customization/hosts/control-0/mac_addresses":            "28:d2:44:d2:b2:1a\n",
+		"/etc/assisted/iso-customization/hosts/control-0/files/etc/sysconfig/raid": "RAID=1\n",
+		isoCustomizationScriptPath:                                                 isoCustomizationScript,
+	}, files)
+	assert.Equal(t, 0644, modes["/etc/chrony.d/lab.conf"])
+	assert.Equal(t, 0755, modes["/etc/assisted/iso-customization/scripts/000-firmware.sh"])
+	assert.Equal(t, 0600, modes["/etc/assisted/iso-customization/hosts/control-0/files/etc/sysconfig/raid"])
+
+	require.Len(t, config.Systemd.Units, 1)
+	assert.Equal(t, "agent-iso-customization.service", config.Systemd.Units[0].Name)
+}
+
+func TestAddISOCustomizationUnset(t *testing.T) {
+	config := &igntypes.Config{}
+	addISOCustomization(config, &agentconfig.AgentConfig{
+		Config: &agent.Config{
+			ISOCustomization: &agent.ISOCustomization{KernelArguments: []string{"rd.debug"}},
+		},
+	})
+	assert.Empty(t, config.Storage.Files)
+	assert.Empty(t, config.Systemd.Units)
+}
+
+func TestKargsKernelCmdLine(t *testing.T) {
+	kargs := &Kargs{fips: true, extraArgs: []string{"rd.debug", "nomodeset"}}
+	assert.Equal(t, " fips=1 rd.debug nomodeset", string(kargs.KernelCmdLine()))
+}
+
+func TestKargsSerialConsole(t *testing.T) {
+	kargs := &Kargs{serial: &agent.SerialConsole{Device: "ttyS1"}, extraArgs: []string{"rd.debug"}}
+	kargs.consoleArgs = " console=tty0 console=ttyS1,115200n8"
+	assert.Equal(t, " console=tty0 console=ttyS1,115200n8 rd.debug", string(kargs.KernelCmdLine()))
+	assert.Equal(t, "\nserial --unit=1 --speed=115200\nterminal_input serial console\nterminal_output serial console\n", string(kargs.GrubConfig()))
+
+	kargs.serial = &agent.SerialConsole{Device: "ttyAMA0", BaudRate: 9600}
+	assert.Empty(t, kargs.GrubConfig())
+}
+
+func TestKargsGenerateConsoleArgs(t *testing.T) {
+	cases := []struct {
+		name     string
+		platform string
+		serial   *agent.SerialConsole
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: "",
+		},
+		{
+			name:     "oci",
+			platform: "oci",
+			expected: " console=ttyS0",
+		},
+		{
+			name:     "serial-console",
+			serial:   &agent.SerialConsole{Device: "ttyS1"},
+			expected: " console=tty0 console=ttyS1,115200n8",
+		},
+		{
+			name:     "oci-and-serial-console",
+			platform: "oci",
+			serial:   &agent.SerialConsole{Device: "ttyS1", BaudRate: 9600},
+			expected: " console=ttyS0 console=tty0 console=ttyS1,9600n8",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			agentClusterInstall := &manifests.AgentClusterInstall{Config: &hiveext.AgentClusterInstall{}}
+			if tc.platform != "" {
+				agentClusterInstall.Config.Spec.ExternalPlatformSpec = &hiveext.ExternalPlatformSpec{PlatformName: tc.platform}
+			}
+			agentConfig := &agentconfig.AgentConfig{Config: &agent.Config{}}
+			if tc.serial != nil {
+				agentConfig.Config.ISOCustomization = &agent.ISOCustomization{SerialConsole: tc.serial}
+			}
+			parents := asset.Parents{}
+			parents.Add(agentClusterInstall, agentConfig)
+
+			kargs := &Kargs{}
+			require.NoError(t, kargs.Generate(parents))
+			assert.Equal(t, tc.expected, string(kargs.KernelCmdLine()))
+		})
+	}
+}
@@ -1,31 +1,40 @@
 package image
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/sirupsen/logrus"
 
 	hiveext "github.com/openshift/assisted-service/api/hiveextension/v1beta1"
 	"github.com/openshift/assisted-service/models"
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
 	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	"github.com/openshift/installer/pkg/types/agent"
 )
 
 // Kargs is an Asset that generates the additional kernel args.
 type Kargs struct {
 	consoleArgs string
 	fips        bool
+	extraArgs   []string
+	serial      *agent.SerialConsole
 }
 
 // Dependencies returns the assets on which the Kargs asset depends.
 func (a *Kargs) Dependencies() []asset.Asset {
 	return []asset.Asset{
 		&manifests.AgentClusterInstall{},
+		&agentconfig.AgentConfig{},
 	}
 }
 
 // Generate generates the kernel args configurations for the agent ISO image and PXE assets.
 func (a *Kargs) Generate(dependencies asset.Parents) error {
 	agentClusterInstall := &manifests.AgentClusterInstall{}
-	dependencies.Get(agentClusterInstall)
+	agentConfig := &agentconfig.AgentConfig{}
+	dependencies.Get(agentClusterInstall, agentConfig)
 
 	// Add kernel args for external oci platform
 	if agentClusterInstall.GetExternalPlatformName() == string(models.PlatformTypeOci) {
@@ -35,6 +44,14 @@ func (a *Kargs) Generate(dependencies asset.Parents) error {
 
 	a.fips = agentClusterInstall.FIPSEnabled()
 
+	if agentConfig.Config != nil && agentConfig.Config.ISOCustomization != nil {
+		a.extraArgs = agentConfig.Config.ISOCustomization.KernelArguments
+		a.serial = agentConfig.Config.ISOCustomization.SerialConsole
+	}
+	if a.serial != nil {
+		a.consoleArgs += " " + strings.Join(agentconfig.SerialConsoleKernelArguments(a.serial), " ")
+	}
+
 	return nil
 }
 
@@ -49,5 +66,23 @@ func (a *Kargs) KernelCmdLine() []byte {
 	if a.fips {
 		cmdLine += " fips=1"
 	}
+	if len(a.extraArgs) > 0 {
+		cmdLine += " " + strings.Join(a.extraArgs, " ")
+	}
 	return []byte(cmdLine)
 }
+
+// GrubConfig returns the GRUB commands appended to the GRUB config of the
+// ISO, which set the serial terminal of the serial console. Only the ttyS
+// devices are serial units of GRUB.
+func (a *Kargs) GrubConfig() []byte {
+	if a.serial == nil {
+		return nil
+	}
+	var unit int
+	if _, err := fmt.Sscanf(agentconfig.SerialConsoleDevice(a.serial), "ttyS%d", &unit); err != nil {
+		return nil
+	}
+	return []byte(fmt.Sprintf("\nserial --unit=%d --speed=%d\nterminal_input serial console\nterminal_output serial console\n",
+		unit, agentconfig.SerialConsoleBaudRate(a.serial)))
+}
@@ -0,0 +1,494 @@
+package image
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	"github.com/openshift/installer/pkg/asset/agent/mirror"
+)
+
+const (
+	templateReleaseInfo         = "oc adm release info -o json --insecure=%t %s"
+	templateReleaseInfoWithIcsp = "oc adm release info -o json --insecure=%t --icsp-file=%s %s"
+)
+
+// manifestMediaTypes are the media types of the manifests of the release
+// payload images, including the manifest lists of multi-arch payloads.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// SkipMirrorCheck skips the check of the mirror registries, for the mirror
+// registries only reachable from the hosts.
+var SkipMirrorCheck bool
+
+// MirrorRegistriesCheck is an asset that verifies, before the agent artifacts
+// are generated, that the mirror registries are reachable and hold the images
+// of the release payload, so that disconnected installs do not fail on the
+// hosts long after booting them.
+type MirrorRegistriesCheck struct {
+}
+
+var _ asset.Asset = (*MirrorRegistriesCheck)(nil)
+
+// Name returns the human-friendly name of the asset.
+func (*MirrorRegistriesCheck) Name() string {
+	return "Mirror Registries Check"
+}
+
+// Dependencies returns the assets on which the MirrorRegistriesCheck asset depends.
+func (*MirrorRegistriesCheck) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&manifests.AgentManifests{},
+		&mirror.RegistriesConf{},
+		&agent.OptionalInstallConfig{},
+	}
+}
+
+// Generate checks the mirror registries.
+func (*MirrorRegistriesCheck) Generate(dependencies asset.Parents) error {
+	agentManifests := &manifests.AgentManifests{}
+	registriesConf := &mirror.RegistriesConf{}
+	installConfig := &agent.OptionalInstallConfig{}
+	dependencies.Get(agentManifests, registriesConf, installConfig)
+
+	mirrors := mirrorEndpoints(registriesConf)
+	if len(mirrors) == 0 {
+		return nil
+	}
+	if SkipMirrorCheck {
+		logrus.Warn("Skipping the check of the mirror registries")
+		return nil
+	}
+	if _, err := exec.LookPath("oc"); err != nil {
+		logrus.Warning("Unable to check the mirror registries because \"oc\" command is not available")
+		return nil
+	}
+
+	releaseImage := agentManifests.ClusterImageSet.Spec.ReleaseImage
+	pullSecret := agentManifests.GetPullSecretData()
+	images, err := releasePayloadImages(releaseImage, pullSecret, registriesConf.MirrorConfig)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the images of the release payload %s from the mirror registries", releaseImage)
+	}
+
+	trustBundle := ""
+	if installConfig.Supplied {
+		trustBundle = installConfig.Config.AdditionalTrustBundle
+	}
+	checker, err := newMirrorChecker(pullSecret, trustBundle)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Checking that the mirror registries hold the %d images of the release payload", len(images))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	return checker.check(ctx, mirrors, images)
+}
+
+// mirrorEndpoint is a mirror of the source repositories of the location,
+// which the hosts do not pull from when they are blocked.
+type mirrorEndpoint struct {
+	location string
+	mirror   string
+	insecure bool
+	blocked  bool
+}
+
+// mirrorEndpoints returns all the mirrors of the registries.conf, where the
+// mirror config only keeps the first mirror of each location.
+func mirrorEndpoints(registriesConf *mirror.RegistriesConf) []mirrorEndpoint {
+	mirrors := []mirrorEndpoint{}
+	if registriesConf.Config == nil {
+		return mirrors
+	}
+	for _, registry := range registriesConf.Config.Registries {
+		location := registry.Location
+		if location == "" {
+			location = registry.Prefix
+		}
+		for _, endpoint := range registry.Mirrors {
+			mirrors = append(mirrors, mirrorEndpoint{
+				location: location,
+				mirror:   endpoint.Location,
+				insecure: endpoint.Insecure,
+				blocked:  registry.Blocked,
+			})
+		}
+	}
+	return mirrors
+}
+
+type releaseInfo struct {
+	Digest     string `json:"digest"`
+	References struct {
+		Spec struct {
+			Tags []struct {
+				From struct {
+					Name string `json:"name"`
+				} `json:"from"`
+			} `json:"tags"`
+		} `json:"spec"`
+	} `json:"references"`
+}
+
+// releasePayloadImages returns the release image and the images of its
+// payload, by digest.
+func releasePayloadImages(releaseImage, pullSecret string, mirrorConfig []mirror.RegistriesConfig) ([]string, error) {
+	icspFile, err := getIcspFileFromRegistriesConfig(mirrorConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer removeIcspFile(icspFile)
+	cmd := fmt.Sprintf(templateReleaseInfo, true, releaseImage)
+	if icspFile != "" {
+		cmd = fmt.Sprintf(templateReleaseInfoWithIcsp, true, icspFile, releaseImage)
+	}
+	logrus.Debugf("Fetching the images of the release payload (%s)", cmd)
+	output, err := execute(pullSecret, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseReleaseInfo(releaseImage, output)
+}
+
+func parseReleaseInfo(releaseImage, output string) ([]string, error) {
+	info := releaseInfo{}
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the release info")
+	}
+
+	images := []string{}
+	if repository, _ := splitImageDigest(releaseImage); repository != "" {
+		images = append(images, releaseImage)
+	} else if info.Digest != "" {
+		images = append(images, fmt.Sprintf("%s@%s", imageRepository(releaseImage), info.Digest))
+	}
+	for _, tag := range info.References.Spec.Tags {
+		if tag.From.Name != "" {
+			images = append(images, tag.From.Name)
+		}
+	}
+	return images, nil
+}
+
+// splitImageDigest splits the image pulled by digest into its repository and
+// digest, and returns empty strings for the other images.
+func splitImageDigest(image string) (string, string) {
+	parts := strings.SplitN(image, "@", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+// imageRepository returns the repository of the image pulled by tag.
+func imageRepository(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
+// mirroredRepository returns the repository of the mirror holding the source
+// repository, when the mirror mirrors it.
+func mirroredRepository(m mirrorEndpoint, repository string) (string, bool) {
+	if repository == m.location {
+		return m.mirror, true
+	}
+	if strings.HasPrefix(repository, m.location+"/") {
+		return m.mirror + strings.TrimPrefix(repository, m.location), true
+	}
+	return "", false
+}
+
+// mirrorChecker queries the mirror registries with the Docker Registry HTTP
+// API V2.
+type mirrorChecker struct {
+	client         *http.Client
+	insecureClient *http.Client
+	auths          map[string]string
+	tokens         map[string]string
+	pings          map[string]error
+}
+
+func newMirrorChecker(pullSecret, trustBundle string) (*mirrorChecker, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if trustBundle != "" && !pool.AppendCertsFromPEM([]byte(trustBundle)) {
+		return nil, errors.New("failed to parse the additional trust bundle")
+	}
+
+	auths := map[string]string{}
+	if pullSecret != "" {
+		secret := struct {
+			Auths map[string]struct {
+				Auth string `json:"auth"`
+			} `json:"auths"`
+		}{}
+		if err := json.Unmarshal([]byte(pullSecret), &secret); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the pull secret")
+		}
+		for registry, auth := range secret.Auths {
+			auths[registry] = auth.Auth
+		}
+	}
+
+	return &mirrorChecker{
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+		},
+		insecureClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, //nolint:gosec // the mirror is insecure in registries.conf
+		},
+		auths:  auths,
+		tokens: map[string]string{},
+		pings:  map[string]error{},
+	}, nil
+}
+
+// check checks that each image of the release payload is served by one of
+// the mirrors of its source repository, or else by its source repository when
+// it is not blocked, which the hosts fall back to. Only the images served by
+// none of them fail the check, the unreachable mirrors and the images missing
+// from a mirror are warned about.
+func (c *mirrorChecker) check(ctx context.Context, mirrors []mirrorEndpoint, images []string) error {
+	var errs []error
+	for _, image := range images {
+		repository, digest := splitImageDigest(image)
+		mirrored, served, blocked := false, false, false
+		for _, m := range mirrors {
+			mirroredRepo, ok := mirroredRepository(m, repository)
+			if !ok {
+				continue
+			}
+			mirrored = true
+			blocked = blocked || m.blocked
+			if !c.reachable(ctx, m) {
+				continue
+			}
+			found, err := c.hasManifest(ctx, m, mirroredRepo, digest)
+			if err != nil {
+				logrus.Warnf("Failed to check %s@%s: %v", mirroredRepo, digest, err)
+				continue
+			}
+			if found {
+				served = true
+				break
+			}
+			logrus.Warnf("Mirror %s of %s is missing the image %s@%s of the release payload", m.mirror, m.location, mirroredRepo, digest)
+		}
+		if !mirrored || served {
+			continue
+		}
+
+		if blocked {
+			errs = append(errs, errors.Errorf("image %s of the release payload is served by none of its mirrors, and its source is blocked", image))
+			continue
+		}
+		found, err := c.hasManifest(ctx, mirrorEndpoint{}, repository, digest)
+		switch {
+		case err != nil:
+			errs = append(errs, errors.Wrapf(err, "image %s of the release payload is served by none of its mirrors, and failed to check its source", image))
+		case !found:
+			errs = append(errs, errors.Errorf("image %s of the release payload is served by none of its mirrors nor by its source", image))
+		default:
+			logrus.Warnf("Image %s of the release payload is served by none of its mirrors, the hosts pull it from its source", image)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// reachable returns whether the registry of the mirror serves the V2 API,
+// warning once about each unreachable mirror.
+func (c *mirrorChecker) reachable(ctx context.Context, m mirrorEndpoint) bool {
+	if err, ok := c.pings[m.mirror]; ok {
+		return err == nil
+	}
+	host := strings.SplitN(m.mirror, "/", 2)[0]
+	err := c.ping(ctx, m, host)
+	if err != nil {
+		logrus.Warnf("Mirror registry %s of %s is not reachable: %v", m.mirror, m.location, err)
+	}
+	c.pings[m.mirror] = err
+	return err == nil
+}
+
+func (c *mirrorChecker) httpClient(m mirrorEndpoint) *http.Client {
+	if m.insecure {
+		return c.insecureClient
+	}
+	return c.client
+}
+
+// ping checks that the registry serves the V2 API. An unauthorized response
+// is a success, the registry requiring authentication.
+func (c *mirrorChecker) ping(ctx context.Context, m mirrorEndpoint, host string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/v2/", host), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient(m).Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return errors.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// hasManifest returns whether the repository holds the manifest of the digest.
+func (c *mirrorChecker) hasManifest(ctx context.Context, m mirrorEndpoint, repository, digest string) (bool, error) {
+	_, found, err := c.manifestMediaType(ctx, m, repository, digest)
+	return found, err
+}
+
+// manifestMediaType returns the media type of the manifest of the reference,
+// a digest or a tag, and whether the repository holds it.
+func (c *mirrorChecker) manifestMediaType(ctx context.Context, m mirrorEndpoint, repository, reference string) (string, bool, error) {
+	host, path := repository, ""
+	if parts := strings.SplitN(repository, "/", 2); len(parts) == 2 {
+		host, path = parts[0], parts[1]
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, path, reference)
+
+	resp, err := c.head(ctx, m, manifestURL, c.tokens[repository])
+	if err != nil {
+		return "", false, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := c.token(ctx, m, host, path, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", false, err
+		}
+		c.tokens[repository] = token
+		resp, err = c.head(ctx, m, manifestURL, token)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get("Content-Type"), true, nil
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, errors.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+func (c *mirrorChecker) head(ctx context.Context, m mirrorEndpoint, manifestURL, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := c.httpClient(m).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// token returns the authorization answering the challenge of the registry,
+// with the credentials of the pull secret for the registry, if any.
+func (c *mirrorChecker) token(ctx context.Context, m mirrorEndpoint, host, path, challenge string) (string, error) {
+	auth, ok := c.auths[host]
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if !ok {
+			return "", errors.Errorf("the pull secret has no credentials for %s", host)
+		}
+		return "Basic " + auth, nil
+	case "bearer":
+	default:
+		return "", errors.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", errors.Errorf("invalid authentication realm %q", params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", path))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		req.Header.Set("Authorization", "Basic "+auth)
+	}
+	// Without credentials, the registry may still issue an anonymous token.
+	resp, err := c.httpClient(m).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to authenticate to %s: %s", host, resp.Status)
+	}
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", errors.Wrapf(err, "failed to authenticate to %s", host)
+	}
+	if body.Token == "" {
+		body.Token = body.AccessToken
+	}
+	return "Bearer " + body.Token, nil
+}
+
+// parseChallenge parses the WWW-Authenticate header into its lower-case
+// scheme and its parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	scheme := strings.ToLower(parts[0])
+	if len(parts) == 1 {
+		return scheme, params
+	}
+	for _, param := range strings.Split(parts[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return scheme, params
+}
@@ -0,0 +1,231 @@
+package image
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReleaseInfo(t *testing.T) {
+	output := `{
+  "digest": "sha256:1111",
+  "references": {
+    "spec": {
+      "tags": [
+        {"name": "agent-installer-api-server", "from": {"name": "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222"}},
+        {"name": "machine-os-images", "from": {"name": "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:3333"}}
+      ]
+    }
+  }
+}`
+
+	images, err := parseReleaseInfo("quay.io/openshift-release-dev/ocp-release:4.15.0-x86_64", output)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"quay.io/openshift-release-dev/ocp-release@sha256:1111",
+		"quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222",
+		"quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:3333",
+	}, images)
+
+	images, err = parseReleaseInfo("quay.io/openshift-release-dev/ocp-release@sha256:4444", output)
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/openshift-release-dev/ocp-release@sha256:4444", images[0])
+}
+
+func TestMirrorCheck(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:password"))
+	present := map[string]bool{
+		"/v2/ocp/release/manifests/sha256:1111":    true,
+		"/v2/ocp/art-dev/manifests/sha256:2222":    true,
+		"/v2/other/release/manifests/sha256:1111":  true,
+		"/v2/source/release/manifests/sha256:1111": true,
+		"/v2/source/art-dev/manifests/sha256:2222": true,
+	}
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.Header.Get("Authorization") != "Basic "+auth {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token": "%s"}`, r.URL.Query().Get("scope"))
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasPrefix(r.URL.Path, "/v2/"):
+			repository := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v2/"), "/manifests/", 2)[0]
+			if r.Header.Get("Authorization") != fmt.Sprintf("Bearer repository:%s:pull", repository) {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !present[r.URL.Path] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+	trustBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	pullSecret := fmt.Sprintf(`{"auths": {"%s": {"auth": "%s"}}}`, host, auth)
+
+	images := []string{
+		host + "/source/release@sha256:1111",
+		host + "/source/art-dev@sha256:2222",
+	}
+	cases := []struct {
+		name          string
+		mirrors       []mirrorEndpoint
+		images        []string
+		expectedError string
+	}{
+		{
+			name: "complete",
+			mirrors: []mirrorEndpoint{
+				{location: host + "/source/release", mirror: host + "/ocp/release"},
+				{location: host + "/source/art-dev", mirror: host + "/ocp/art-dev"},
+			},
+		},
+		{
+			name: "served by the next mirror",
+			mirrors: []mirrorEndpoint{
+				{location: host + "/source", mirror: host + "/other", blocked: true},
+				{location: host + "/source", mirror: host + "/ocp", blocked: true},
+			},
+		},
+		{
+			name: "served by the source",
+			mirrors: []mirrorEndpoint{
+				{location: host + "/source", mirror: host + "/other"},
+			},
+		},
+		{
+			name: "missing digest with blocked source",
+			mirrors: []mirrorEndpoint{
+				{location: host + "/source", mirror: host + "/other", blocked: true},
+			},
+			expectedError: fmt.Sprintf("image %s/source/art-dev@sha256:2222 of the release payload is served by none of its mirrors, and its source is blocked", host),
+		},
+		{
+			name: "missing digest in mirror and source",
+			mirrors: []mirrorEndpoint{
+				{location: host + "/gone", mirror: host + "/other"},
+			},
+			images:        []string{host + "/gone/art-dev@sha256:2222"},
+			expectedError: fmt.Sprintf("image %s/gone/art-dev@sha256:2222 of the release payload is served by none of its mirrors nor by its source", host),
+		},
+		{
+			name: "unreachable mirror served by the source",
+			mirrors: []mirrorEndpoint{
+				{location: host + "/source", mirror: "127.0.0.1:1/ocp"},
+			},
+		},
+		{
+			name: "unreachable mirror with blocked source",
+			mirrors: []mirrorEndpoint{
+				{location: host + "/source", mirror: "127.0.0.1:1/ocp", blocked: true},
+			},
+			expectedError: fmt.Sprintf("image %s/source/release@sha256:1111 of the release payload is served by none of its mirrors, and its source is blocked", host),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker, err := newMirrorChecker(pullSecret, trustBundle)
+			require.NoError(t, err)
+			checkedImages := images
+			if tc.images != nil {
+				checkedImages = tc.images
+			}
+			err = checker.check(context.Background(), tc.mirrors, checkedImages)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestMirrorCheckerIsManifestList(t *testing.T) {
+	mediaTypes := map[string]string{
+		"/v2/ocp/release/manifests/4.15.0-multi":     "application/vnd.docker.distribution.manifest.list.v2+json",
+		"/v2/ocp/release/manifests/sha256:1111":      "application/vnd.oci.image.index.v1+json",
+		"/v2/source/release/manifests/4.15.0-x86_64": "application/vnd.docker.distribution.manifest.v2+json",
+		"/v2/source/release/manifests/4.15.0-multi":  "application/vnd.docker.distribution.manifest.v2+json",
+		"/v2/source/release/manifests/latest":        "application/vnd.oci.image.index.v1+json",
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, ok := mediaTypes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", mediaType)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+	trustBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	cases := []struct {
+		name          string
+		mirrors       []mirrorEndpoint
+		image         string
+		expected      bool
+		expectedError string
+	}{
+		{
+			name:  "single-arch",
+			image: host + "/source/release:4.15.0-x86_64",
+		},
+		{
+			name:     "manifest list of the mirror",
+			mirrors:  []mirrorEndpoint{{location: host + "/source", mirror: host + "/ocp"}},
+			image:    host + "/source/release:4.15.0-multi",
+			expected: true,
+		},
+		{
+			name:  "multi tag of a single-arch manifest",
+			image: host + "/source/release:4.15.0-multi",
+		},
+		{
+			name:     "image index by digest",
+			mirrors:  []mirrorEndpoint{{location: host + "/source", mirror: host + "/ocp"}},
+			image:    host + "/source/release@sha256:1111",
+			expected: true,
+		},
+		{
+			name:     "missing from the mirror",
+			mirrors:  []mirrorEndpoint{{location: host + "/source", mirror: host + "/other"}},
+			image:    host + "/source/release",
+			expected: true,
+		},
+		{
+			name:          "missing",
+			image:         host + "/source/release:4.14.0-multi",
+			expectedError: fmt.Sprintf("manifest of %s/source/release:4.14.0-multi not found", host),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker, err := newMirrorChecker("", trustBundle)
+			require.NoError(t, err)
+			multiArch, err := checker.isManifestList(context.Background(), tc.mirrors, tc.image)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, multiArch)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package image
+
+import (
+	"sort"
+
+	"github.com/coreos/stream-metadata-go/arch"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/types"
+)
+
+// ArchArtifacts are the artifacts of an additional architecture of the agent
+// ISO images and PXE files, sharing the ignition and kernel arguments of the
+// main architecture.
+type ArchArtifacts struct {
+	CPUArch string
+	TmpPath string
+	ISOPath string
+}
+
+// additionalCPUArchs returns the architectures of the compute pools of the
+// install config other than the main architecture, for which the agent
+// artifacts are also generated when the release payload is multi-arch.
+func additionalCPUArchs(installConfig *types.InstallConfig, cpuArch, releaseImage string, multiArchRelease bool) []string {
+	if installConfig == nil {
+		return nil
+	}
+	found := map[string]bool{}
+	for _, compute := range installConfig.Compute {
+		if compute.Architecture == "" {
+			continue
+		}
+		if computeArch := arch.RpmArch(string(compute.Architecture)); computeArch != cpuArch {
+			found[computeArch] = true
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	archs := make([]string, 0, len(found))
+	for computeArch := range found {
+		archs = append(archs, computeArch)
+	}
+	sort.Strings(archs)
+	if !multiArchRelease {
+		logrus.Warnf("Not generating the agent artifacts for the %v compute architectures, the release image %s is not multi-arch", archs, releaseImage)
+		return nil
+	}
+	return archs
+}
@@ -0,0 +1,58 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/types"
+)
+
+func TestAdditionalCPUArchs(t *testing.T) {
+	cases := []struct {
+		name          string
+		installConfig *types.InstallConfig
+		releaseImage  string
+		multiArch     bool
+		expected      []string
+	}{
+		{
+			name:         "no install config",
+			releaseImage: "quay.io/openshift-release-dev/ocp-release:4.14.0-multi",
+			multiArch:    true,
+		},
+		{
+			name: "same architecture",
+			installConfig: &types.InstallConfig{
+				Compute: []types.MachinePool{{Architecture: types.ArchitectureAMD64}},
+			},
+			releaseImage: "quay.io/openshift-release-dev/ocp-release:4.14.0-multi",
+			multiArch:    true,
+		},
+		{
+			name: "arm64 compute",
+			installConfig: &types.InstallConfig{
+				Compute: []types.MachinePool{
+					{Architecture: types.ArchitectureARM64},
+					{Architecture: types.ArchitectureAMD64},
+					{Architecture: types.ArchitectureARM64},
+				},
+			},
+			releaseImage: "quay.io/openshift-release-dev/ocp-release:4.14.0-multi",
+			multiArch:    true,
+			expected:     []string{"aarch64"},
+		},
+		{
+			name: "single-arch release",
+			installConfig: &types.InstallConfig{
+				Compute: []types.MachinePool{{Architecture: types.ArchitectureARM64}},
+			},
+			releaseImage: "quay.io/openshift-release-dev/ocp-release:4.14.0-x86_64",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, additionalCPUArchs(tc.installConfig, "x86_64", tc.releaseImage, tc.multiArch))
+		})
+	}
+}
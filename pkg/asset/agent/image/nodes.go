@@ -0,0 +1,27 @@
+package image
+
+import (
+	"fmt"
+	"path/filepath"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/asset/ignition"
+)
+
+// addNodeManifests adds the manifests of the nodes registered before the hosts
+// join the cluster to the extra manifests.
+func addNodeManifests(config *igntypes.Config, agentConfig *agentconfig.AgentConfig) error {
+	for _, node := range agentConfig.Nodes() {
+		data, err := yaml.Marshal(node)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal the node %s", node.Name)
+		}
+		path := filepath.Join(extraManifestPath, fmt.Sprintf("agent-node-%s.yaml", node.Name))
+		config.Storage.Files = append(config.Storage.Files, ignition.FileFromBytes(path, "root", 0644, data))
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/coreos/stream-metadata-go/arch"
 	"github.com/sirupsen/logrus"
 	"github.com/thedevsaddam/retry"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -45,7 +46,7 @@ type Config struct {
 // Release is the interface to use the oc command to the get image info
 type Release interface {
 	GetBaseIso(architecture string) (string, error)
-	ExtractFile(image string, filename string) ([]string, error)
+	ExtractFile(image string, filename string, architecture string) ([]string, error)
 }
 
 type release struct {
@@ -72,8 +73,9 @@ const (
 	templateImageExtractWithIcsp = "oc image extract --path %s:%s --confirm --icsp-file=%s %s"
 )
 
-// ExtractFile extracts the specified file from the given image name, and store it in the cache dir.
-func (r *release) ExtractFile(image string, filename string) ([]string, error) {
+// ExtractFile extracts the specified file from the given image name, for the
+// architecture, and store it in the cache dir.
+func (r *release) ExtractFile(image string, filename string, architecture string) ([]string, error) {
 	imagePullSpec, err := r.getImageFromRelease(image)
 	if err != nil {
 		return nil, err
@@ -83,8 +85,15 @@ func (r *release) ExtractFile(image string, filename string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	if isMultiArchRelease(r.releaseImage, r.pullSecret, r.mirrorConfig) {
+		// The files of the architectures have the same names
+		cacheDir = filepath.Join(cacheDir, architecture)
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, err
+		}
+	}
 
-	path, err := r.extractFileFromImage(imagePullSpec, filename, cacheDir)
+	path, err := r.extractFileFromImage(imagePullSpec, filename, cacheDir, architecture)
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +132,7 @@ func (r *release) GetBaseIso(architecture string) (string, error) {
 	}
 
 	// Get the base ISO from the payload
-	path, err := r.extractFileFromImage(image, filename, cacheDir)
+	path, err := r.extractFileFromImage(image, filename, cacheDir, architecture)
 	if err != nil {
 		return "", err
 	}
@@ -168,7 +177,7 @@ func (r *release) getImageFromRelease(imageName string) (string, error) {
 	return image, nil
 }
 
-func (r *release) extractFileFromImage(image, file, cacheDir string) ([]string, error) {
+func (r *release) extractFileFromImage(image, file, cacheDir, architecture string) ([]string, error) {
 	var cmd string
 	if len(r.mirrorConfig) > 0 {
 		icspFile, err := getIcspFileFromRegistriesConfig(r.mirrorConfig)
@@ -180,6 +189,10 @@ func (r *release) extractFileFromImage(image, file, cacheDir string) ([]string,
 	} else {
 		cmd = fmt.Sprintf(templateImageExtract, file, cacheDir, image)
 	}
+	// The images of the multi-arch release payloads are manifest lists
+	if isMultiArchRelease(r.releaseImage, r.pullSecret, r.mirrorConfig) {
+		cmd += fmt.Sprintf(" --filter-by-os=linux/%s", arch.GoArch(architecture))
+	}
 	path := filepath.Join(cacheDir, path.Base(file))
 	// Remove file if it exists
 	if err := removeCacheFile(path); err != nil {
@@ -268,7 +281,7 @@ func (r *release) verifyCacheFile(image, file, architecture string) (bool, error
 	defer os.RemoveAll(tempDir)
 
 	shaFilename := fmt.Sprintf(coreOsSha256FileName, architecture)
-	shaFile, err := r.extractFileFromImage(image, shaFilename, tempDir)
+	shaFile, err := r.extractFileFromImage(image, shaFilename, tempDir, architecture)
 	if err != nil {
 		logrus.Debug("Could not get SHA from payload for cache comparison")
 		return false, nil
@@ -1,25 +1,112 @@
 package image
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/asset/agent/mirror"
 	"github.com/openshift/installer/pkg/version"
 )
 
+const (
+	// multiArch is the architecture of the release payloads for all the
+	// architectures.
+	multiArch = "multi"
+)
+
+// multiArchitectures are the architectures of the multi-arch release payloads.
+var multiArchitectures = []string{"x86_64", "aarch64", "ppc64le", "s390x"}
+
 type releaseImage struct {
-	ReleaseVersion string `json:"openshift_version"`
-	Arch           string `json:"cpu_architecture"`
-	PullSpec       string `json:"url"`
-	Tag            string `json:"version"`
+	ReleaseVersion   string   `json:"openshift_version"`
+	Arch             string   `json:"cpu_architecture"`
+	CPUArchitectures []string `json:"cpu_architectures,omitempty"`
+	PullSpec         string   `json:"url"`
+	Tag              string   `json:"version"`
 }
 
 func isDigest(pullspec string) bool {
 	return regexp.MustCompile(`.*sha256:[a-fA-F0-9]{64}$`).MatchString(pullspec)
 }
 
+// manifestListMediaTypes are the media types of the manifests of the
+// multi-arch release payloads.
+var manifestListMediaTypes = map[string]bool{
+	"application/vnd.docker.distribution.manifest.list.v2+json": true,
+	"application/vnd.oci.image.index.v1+json":                   true,
+}
+
+// multiArchReleases caches whether the release payloads are multi-arch, by
+// pull spec.
+var multiArchReleases = map[string]bool{}
+
+// isMultiArchRelease returns whether the release payload is multi-arch, i.e.
+// whether its manifest is a manifest list, as served by the mirrors of its
+// repository or else by its source. A release payload whose manifest cannot
+// be retrieved is not multi-arch.
+func isMultiArchRelease(pullSpec, pullSecret string, mirrorConfig []mirror.RegistriesConfig) bool {
+	if multiArch, ok := multiArchReleases[pullSpec]; ok {
+		return multiArch
+	}
+	checker, err := newMirrorChecker(pullSecret, "")
+	if err == nil {
+		mirrors := make([]mirrorEndpoint, 0, len(mirrorConfig))
+		for _, config := range mirrorConfig {
+			mirrors = append(mirrors, mirrorEndpoint{location: config.Location, mirror: config.Mirror})
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		multiArchReleases[pullSpec], err = checker.isManifestList(ctx, mirrors, pullSpec)
+	}
+	if err != nil {
+		logrus.Warnf("Unable to check whether the release image %s is multi-arch: %v", pullSpec, err)
+	}
+	return multiArchReleases[pullSpec]
+}
+
+// isManifestList returns whether the manifest of the image, by tag or
+// digest, is a manifest list, as served by the first of its mirrors holding
+// it, or else by its source.
+func (c *mirrorChecker) isManifestList(ctx context.Context, mirrors []mirrorEndpoint, image string) (bool, error) {
+	repository, reference := splitImageDigest(image)
+	if repository == "" {
+		repository = imageRepository(image)
+		reference = strings.TrimPrefix(image, repository+":")
+		if reference == image {
+			reference = "latest"
+		}
+	}
+	for _, m := range mirrors {
+		mirroredRepo, ok := mirroredRepository(m, repository)
+		if !ok {
+			continue
+		}
+		mediaType, found, err := c.manifestMediaType(ctx, m, mirroredRepo, reference)
+		if err != nil {
+			logrus.Debugf("Failed to get the manifest of %s from %s: %v", image, m.mirror, err)
+			continue
+		}
+		if found {
+			return manifestListMediaTypes[mediaType], nil
+		}
+	}
+	mediaType, found, err := c.manifestMediaType(ctx, mirrorEndpoint{}, repository, reference)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, errors.Errorf("manifest of %s not found", image)
+	}
+	return manifestListMediaTypes[mediaType], nil
+}
+
 func releaseImageFromPullSpec(pullSpec, arch string) (releaseImage, error) {
 
 	// When the pullspec it's a digest let's use the current version
@@ -59,12 +146,18 @@ func releaseImageFromPullSpec(pullSpec, arch string) (releaseImage, error) {
 	}, nil
 }
 
-func releaseImageList(pullSpec, arch string) (string, error) {
+// releaseImageList returns the release images of assisted-service, with the
+// architectures of the release payload when it is multi-arch.
+func releaseImageList(pullSpec, arch string, multiArchRelease bool) (string, error) {
 
 	relImage, err := releaseImageFromPullSpec(pullSpec, arch)
 	if err != nil {
 		return "", err
 	}
+	if multiArchRelease {
+		relImage.Arch = multiArch
+		relImage.CPUArchitectures = multiArchitectures
+	}
 
 	imageList := []interface{}{relImage}
 	text, err := json.Marshal(imageList)
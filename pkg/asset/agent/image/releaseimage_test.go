@@ -11,6 +11,7 @@ func TestReleaseImageList(t *testing.T) {
 		name     string
 		pullSpec string
 		arch     string
+		multi    bool
 		result   string
 	}{
 		{
@@ -37,6 +38,13 @@ func TestReleaseImageList(t *testing.T) {
 			arch:     "x86_64",
 			result:   "[{\"openshift_version\":\"4.11\",\"cpu_architecture\":\"x86_64\",\"url\":\"registry.ci.openshift.org/ocp/release:4.11.0-0.ci-2022-05-16-202609\",\"version\":\"4.11.0-0.ci-2022-05-16-202609\"}]",
 		},
+		{
+			name:     "multi",
+			pullSpec: "quay.io/openshift-release-dev/ocp-release:4.14.0-multi",
+			arch:     "x86_64",
+			multi:    true,
+			result:   "[{\"openshift_version\":\"4.14\",\"cpu_architecture\":\"multi\",\"cpu_architectures\":[\"x86_64\",\"aarch64\",\"ppc64le\",\"s390x\"],\"url\":\"quay.io/openshift-release-dev/ocp-release:4.14.0-multi\",\"version\":\"4.14.0-multi\"}]",
+		},
 		{
 			name:     "CI-ephemeral",
 			pullSpec: "registry.build04.ci.openshift.org/ci-op-m7rfgytz/release@sha256:ebb203f24ee060d61bdb466696a9c20b3841f9929badf9b81fc99cbedc2a679e",
@@ -47,7 +55,7 @@ func TestReleaseImageList(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			output, err := releaseImageList(tc.pullSpec, tc.arch)
+			output, err := releaseImageList(tc.pullSpec, tc.arch, tc.multi)
 			assert.NoError(t, err)
 			if err == nil {
 				assert.Equal(t, tc.result, output)
@@ -65,7 +73,7 @@ func TestReleaseImageListErrors(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc, func(t *testing.T) {
-			_, err := releaseImageList(tc, "x86_64")
+			_, err := releaseImageList(tc, "x86_64", false)
 			assert.Error(t, err)
 		})
 	}
@@ -0,0 +1,390 @@
+package image
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/coreos/stream-metadata-go/arch"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/assisted-image-service/pkg/isoeditor"
+	"github.com/openshift/installer/pkg/asset/ignition"
+)
+
+// EmbedRelease copies the images of the release payload into a release
+// payload ISO written alongside the agent ISO, as OCI layouts. While the ISO
+// is attached, the hosts serve them with a local registry, which mirrors
+// their repositories in registries.conf, so that the installation pulls no
+// image from a remote registry.
+var EmbedRelease bool
+
+// EmbedImages are the images, e.g. of the operators, copied into the release
+// payload ISO along with the images of the release payload.
+var EmbedImages []string
+
+// EmbedRegistryImage is the image of the registry serving the release payload
+// on the hosts, copied into the release payload ISO for the architecture of
+// the hosts. It must be referenced by digest.
+var EmbedRegistryImage string
+
+const (
+	releasePayloadISOFilename = "agent.%s.release.iso"
+	releasePayloadVolumeLabel = "agent-release"
+	releasePayloadMountPath   = "/var/mnt/release"
+	releasePayloadMountUnit   = "var-mnt-release.mount"
+	releasePayloadService     = "agent-release-registry.service"
+	releasePayloadCleanup     = "agent-release-cleanup.service"
+
+	// releasePayloadLayouts is the directory of the OCI layouts of the
+	// images, one per repository, in the release payload ISO.
+	releasePayloadLayouts = "oci"
+	// releasePayloadRegistryArchive is the OCI archive of the registry image
+	// in the release payload ISO.
+	releasePayloadRegistryArchive = "registry.tar"
+	releasePayloadRegistryConfig  = "registry-config.json"
+	releasePayloadRegistriesConf  = "registries.conf"
+	releasePayloadRegistryImage   = "localhost/agent-release-registry:latest"
+	releasePayloadRegistry        = "localhost:5005"
+
+	registriesConfDropInPath = "/etc/containers/registries.conf.d/99-agent-release-payload.conf"
+
+	// releasePayloadHostsScriptPath adds the units serving the release
+	// payload to the ignition config override of the installed hosts.
+	releasePayloadHostsScriptPath = "/usr/local/bin/agent-release-hosts.sh"
+	// releasePayloadHostsConfigPath is the ignition config of the units
+	// serving the release payload on the installed hosts.
+	releasePayloadHostsConfigPath = "/etc/assisted/agent-release-payload.ign"
+
+	templateReleaseImages = "oc adm release info -o=jsonpath={.references.spec.tags[*].from.name} --insecure=true --filter-by-os=linux/%s %s"
+)
+
+// imageDigest matches the digests the registry image is referenced by.
+var imageDigest = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// releasePayloadMountUnitContents mounts the release payload ISO, when it is
+// attached.
+const releasePayloadMountUnitContents = `[Unit]
+Description=Mount the release payload ISO of the agent installer
+ConditionPathExists=/dev/disk/by-label/` + releasePayloadVolumeLabel + `
+
+[Mount]
+What=/dev/disk/by-label/` + releasePayloadVolumeLabel + `
+Where=` + releasePayloadMountPath + `
+Type=iso9660
+Options=ro,nofail
+`
+
+// releasePayloadServiceContents serves the release payload of the ISO with
+// the registry, and mirrors the repositories of the images to it.
+const releasePayloadServiceContents = `[Unit]
+Description=Serve the release payload of the agent installer
+ConditionPathExists=/dev/disk/by-label/` + releasePayloadVolumeLabel + `
+Wants=network-online.target ` + releasePayloadMountUnit + `
+After=network-online.target ` + releasePayloadMountUnit + `
+Before=crio.service kubelet.service agent.service node-zero.service
+
+[Service]
+Type=simple
+Restart=on-failure
+ExecStartPre=/usr/bin/install -D -m 0644 ` + releasePayloadMountPath + `/` + releasePayloadRegistriesConf + ` ` + registriesConfDropInPath + `
+ExecStartPre=/usr/bin/skopeo copy oci-archive:` + releasePayloadMountPath + `/` + releasePayloadRegistryArchive + ` containers-storage:` + releasePayloadRegistryImage + `
+ExecStartPre=-/usr/bin/podman rm -f agent-release-registry
+ExecStart=/usr/bin/podman run --rm --name agent-release-registry --net host --security-opt label=disable -v ` + releasePayloadMountPath + `/` + releasePayloadLayouts + `:/var/lib/registry:ro -v ` + releasePayloadMountPath + `/` + releasePayloadRegistryConfig + `:/etc/zot/config.json:ro ` + releasePayloadRegistryImage + `
+ExecStop=/usr/bin/podman stop agent-release-registry
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// releasePayloadCleanupContents removes the registry, the mirrors and the
+// units serving the release payload from the installed hosts at their first
+// boot without the release payload ISO, once the installation is done.
+const releasePayloadCleanupContents = `[Unit]
+Description=Remove the release payload registry of the agent installer
+ConditionPathExists=!/dev/disk/by-label/` + releasePayloadVolumeLabel + `
+After=local-fs.target
+Before=crio.service kubelet.service
+
+[Service]
+Type=oneshot
+ExecStart=/bin/rm -f ` + registriesConfDropInPath + `
+ExecStart=-/usr/bin/podman rmi -f ` + releasePayloadRegistryImage + `
+ExecStart=/bin/systemctl disable ` + releasePayloadService + ` ` + releasePayloadCleanup + `
+ExecStart=/bin/rm -f /etc/systemd/system/` + releasePayloadMountUnit + ` /etc/systemd/system/` + releasePayloadService + ` /etc/systemd/system/` + releasePayloadCleanup + `
+ExecStart=/bin/systemctl daemon-reload
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// releasePayloadHostsScript merges the units of releasePayloadHostsConfigPath
+// into the ignition config override of every host registered with the Agent
+// Rest API, once the EXPECTED_HOSTS are, when it runs on the rendezvous host.
+// It runs after the host config is applied, to keep the ignition config
+// overrides of the hosts.
+const releasePayloadHostsScript = `#!/bin/bash
+set -euo pipefail
+
+` + restAPIScriptPrelude + `
+until [ "$(curl_api "${api}/infra-envs/${infra_env_id}/hosts" | jq 'length')" -ge "${EXPECTED_HOSTS}" ]; do
+	echo "Waiting for the ${EXPECTED_HOSTS} hosts to register"
+	sleep 10
+done
+
+for host_id in $(curl_api "${api}/infra-envs/${infra_env_id}/hosts" | jq -r '.[].id'); do
+	echo "Serving the release payload on the installed host ${host_id}"
+	until curl_api "${api}/infra-envs/${infra_env_id}/hosts/${host_id}" |
+		jq -ec --slurpfile payload "` + releasePayloadHostsConfigPath + `" '
+			(.ignition_config_overrides // "" | if . == "" then {ignition: {version: $payload[0].ignition.version}} else fromjson end) |
+			if any(.systemd.units[]?; .name == "` + releasePayloadService + `") then
+				empty
+			else
+				{config: (.systemd.units = (.systemd.units // []) + $payload[0].systemd.units | tostring)}
+			end' |
+		{ data=$(cat); [ -z "${data}" ] || curl_api --request PATCH --data "${data}" "${api}/infra-envs/${infra_env_id}/hosts/${host_id}/ignition" > /dev/null; }; do
+		sleep 10
+	done
+done
+`
+
+// releasePayloadHostsUnit runs releasePayloadHostsScript before the
+// installation starts.
+const releasePayloadHostsUnit = `[Unit]
+Description=Serve the release payload on the installed hosts
+Wants=network-online.target
+After=network-online.target agent-rendezvous-discovery.service assisted-service.service agent-host-config.service
+Before=start-cluster-installation.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+EnvironmentFile=` + rendezvousHostEnvPath + `
+Environment=EXPECTED_HOSTS=%d
+ExecStart=` + releasePayloadHostsScriptPath + `
+StandardOutput=journal+console
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// releasePayloadRegistryConfigContents is the configuration of the registry,
+// serving the OCI layouts read-only on the host.
+const releasePayloadRegistryConfigContents = `{
+  "storage": {
+    "rootDirectory": "/var/lib/registry",
+    "dedupe": false,
+    "gc": false
+  },
+  "http": {
+    "address": "127.0.0.1",
+    "port": "5005"
+  },
+  "log": {
+    "level": "warn"
+  }
+}
+`
+
+// validateEmbedRelease checks that the registry image is pinned, so that the
+// release payload ISO serves what was reviewed.
+func validateEmbedRelease() error {
+	if EmbedRegistryImage == "" {
+		return errors.New("embedding the release payload requires the image of the registry serving it on the hosts, referenced by digest")
+	}
+	if _, digest := splitImageDigest(EmbedRegistryImage); !imageDigest.MatchString(digest) {
+		return errors.Errorf("the registry image %s must be referenced by its sha256 digest", EmbedRegistryImage)
+	}
+	return nil
+}
+
+// addReleasePayload serves the release payload of the ISO on the hosts
+// booting the agent ISO and, through their ignition config overrides, on the
+// expected hosts once installed, for as long as the ISO stays attached.
+func addReleasePayload(config *igntypes.Config, expectedHosts int) error {
+	if err := validateEmbedRelease(); err != nil {
+		return err
+	}
+	addReleasePayloadUnits(config)
+
+	hostsConfig := igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+	}
+	addReleasePayloadUnits(&hostsConfig)
+	enabled := true
+	cleanup := releasePayloadCleanupContents
+	hostsConfig.Systemd.Units = append(hostsConfig.Systemd.Units, igntypes.Unit{
+		Name:     releasePayloadCleanup,
+		Enabled:  &enabled,
+		Contents: &cleanup,
+	})
+	data, err := json.Marshal(hostsConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the ignition config serving the release payload on the installed hosts")
+	}
+
+	hostsUnit := fmt.Sprintf(releasePayloadHostsUnit, expectedHosts)
+	config.Storage.Files = append(config.Storage.Files,
+		ignition.FileFromBytes(releasePayloadHostsConfigPath, "root", 0644, data),
+		ignition.FileFromString(releasePayloadHostsScriptPath, "root", 0755, releasePayloadHostsScript))
+	config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+		Name:     "agent-release-hosts.service",
+		Enabled:  &enabled,
+		Contents: &hostsUnit,
+	})
+	return nil
+}
+
+// addReleasePayloadUnits adds the units mounting the release payload ISO and
+// serving its release payload.
+func addReleasePayloadUnits(config *igntypes.Config) {
+	enabled := true
+	mount := releasePayloadMountUnitContents
+	service := releasePayloadServiceContents
+	config.Systemd.Units = append(config.Systemd.Units,
+		igntypes.Unit{
+			Name:     releasePayloadMountUnit,
+			Contents: &mount,
+		},
+		igntypes.Unit{
+			Name:     releasePayloadService,
+			Enabled:  &enabled,
+			Contents: &service,
+		},
+	)
+}
+
+// payloadImageLayout returns the repository of the image, which is the path
+// of its OCI layout, and the tag of the image in the layout. Images pulled by
+// digest are tagged with their digest.
+func payloadImageLayout(image string) (string, string, error) {
+	repository, digest := splitImageDigest(image)
+	tag := strings.Replace(digest, ":", "-", 1)
+	if repository == "" {
+		repository = imageRepository(image)
+		tag = strings.TrimPrefix(image, repository+":")
+		if repository == image {
+			tag = "latest"
+		}
+	}
+	if host := strings.SplitN(repository, "/", 2)[0]; !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return "", "", errors.Errorf("image %s must be fully qualified with its registry", image)
+	}
+	return repository, tag, nil
+}
+
+// releasePayloadRegistriesConfContents returns the registries.conf drop-in
+// mirroring the repositories to the registry of the hosts.
+func releasePayloadRegistriesConfContents(repositories []string) string {
+	var buf bytes.Buffer
+	for _, repository := range repositories {
+		fmt.Fprintf(&buf, "[[registry]]\nlocation = %q\n\n[[registry.mirror]]\nlocation = %q\ninsecure = true\n\n", repository, releasePayloadRegistry+"/"+repository)
+	}
+	return buf.String()
+}
+
+// pullReleasePayload copies the images of the release payload, the
+// EmbedImages, and the registry image for the architecture of the image into
+// the release payload ISO.
+func (a *AgentImage) pullReleasePayload(releaseImage, pullSecret string) error {
+	if _, err := exec.LookPath("skopeo"); err != nil {
+		return errors.Wrap(err, "embedding the release payload requires skopeo")
+	}
+
+	output, err := execute(pullSecret, fmt.Sprintf(templateReleaseImages, arch.GoArch(a.cpuArch), releaseImage))
+	if err != nil {
+		return errors.Wrap(err, "failed to list the images of the release payload")
+	}
+	images := append([]string{releaseImage}, strings.Fields(output)...)
+	images = append(images, EmbedImages...)
+
+	a.releasePayloadPath, err = os.MkdirTemp("", "agent-release")
+	if err != nil {
+		return err
+	}
+
+	authFile, err := os.CreateTemp("", "registry-config")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(authFile.Name())
+	_, err = authFile.Write([]byte(pullSecret))
+	authFile.Close()
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Copying %d images into the release payload ISO, this may take a while", len(images))
+	repositories := map[string]bool{}
+	for _, image := range images {
+		repository, tag, err := payloadImageLayout(image)
+		if err != nil {
+			return err
+		}
+		repositories[repository] = true
+		layout := filepath.Join(a.releasePayloadPath, releasePayloadLayouts, filepath.FromSlash(repository))
+		if err := os.MkdirAll(layout, 0o755); err != nil {
+			return err
+		}
+		logrus.Debugf("Copying %s", image)
+		if err := skopeoCopy(authFile.Name(), image, "oci:"+layout+":"+tag, "--all", "--preserve-digests"); err != nil {
+			return err
+		}
+	}
+
+	logrus.Debugf("Copying the registry image %s", EmbedRegistryImage)
+	if err := skopeoCopy(authFile.Name(), EmbedRegistryImage, "oci-archive:"+filepath.Join(a.releasePayloadPath, releasePayloadRegistryArchive), "--override-arch", arch.GoArch(a.cpuArch)); err != nil {
+		return err
+	}
+
+	sorted := make([]string, 0, len(repositories))
+	for repository := range repositories {
+		sorted = append(sorted, repository)
+	}
+	sort.Strings(sorted)
+	files := map[string]string{
+		releasePayloadRegistriesConf: releasePayloadRegistriesConfContents(sorted),
+		releasePayloadRegistryConfig: releasePayloadRegistryConfigContents,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(a.releasePayloadPath, name), []byte(contents), 0o644); err != nil { //nolint:gosec // no sensitive info
+			return err
+		}
+	}
+	return nil
+}
+
+// skopeoCopy copies the image from its registry to the destination.
+func skopeoCopy(authFile, image, destination string, args ...string) error {
+	args = append([]string{"copy", "--quiet", "--authfile", authFile}, args...)
+	cmd := exec.Command("skopeo", append(args, "docker://"+image, destination)...) //nolint:gosec
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to copy %s: %s", image, stderr.String())
+	}
+	return nil
+}
+
+// persistReleasePayload writes the release payload ISO of the architecture
+// of the image in the assets folder.
+func (a *AgentImage) persistReleasePayload(directory string) error {
+	releasePayloadFile := filepath.Join(directory, fmt.Sprintf(releasePayloadISOFilename, a.cpuArch))
+	os.Remove(releasePayloadFile)
+
+	if err := isoeditor.Create(releasePayloadFile, a.releasePayloadPath, releasePayloadVolumeLabel); err != nil {
+		return errors.Wrap(err, "failed to create the release payload ISO")
+	}
+	logrus.Infof("Generated release payload ISO at %s. Attach it to the hosts along with the agent ISO, it can be detached once they are installed", releasePayloadFile)
+	return nil
+}
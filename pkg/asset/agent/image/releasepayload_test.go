@@ -0,0 +1,140 @@
+package image
+
+import (
+	"encoding/json"
+	"testing"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vincent-petithory/dataurl"
+)
+
+const testRegistryImage = "ghcr.io/project-zot/zot-linux-amd64@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestAddReleasePayload(t *testing.T) {
+	defer func(image string) { EmbedRegistryImage = image }(EmbedRegistryImage)
+	EmbedRegistryImage = testRegistryImage
+
+	config := &igntypes.Config{}
+	require.NoError(t, addReleasePayload(config, 3))
+
+	files := map[string]string{}
+	for _, file := range config.Storage.Files {
+		data, err := dataurl.DecodeString(*file.Contents.Source)
+		require.NoError(t, err)
+		files[file.Path] = string(data.Data)
+	}
+	require.Len(t, files, 2, "the containers storage configuration must not be replaced")
+	assert.Contains(t, files[releasePayloadHostsScriptPath], "/hosts/${host_id}/ignition")
+	require.Len(t, config.Systemd.Units, 3)
+	assert.Equal(t, releasePayloadMountUnit, config.Systemd.Units[0].Name)
+	assert.Contains(t, *config.Systemd.Units[0].Contents, "What=/dev/disk/by-label/agent-release\n")
+	assert.Equal(t, releasePayloadService, config.Systemd.Units[1].Name)
+	assert.Contains(t, *config.Systemd.Units[1].Contents, "-v /var/mnt/release/oci:/var/lib/registry:ro")
+	assert.Contains(t, *config.Systemd.Units[1].Contents, "/var/mnt/release/registries.conf /etc/containers/registries.conf.d/99-agent-release-payload.conf\n")
+	assert.Contains(t, *config.Systemd.Units[1].Contents, "ConditionPathExists=/dev/disk/by-label/agent-release\n")
+	assert.Equal(t, "agent-release-hosts.service", config.Systemd.Units[2].Name)
+	assert.Contains(t, *config.Systemd.Units[2].Contents, "Environment=EXPECTED_HOSTS=3\n")
+
+	hostsConfig := igntypes.Config{}
+	require.NoError(t, json.Unmarshal([]byte(files[releasePayloadHostsConfigPath]), &hostsConfig))
+	assert.Equal(t, igntypes.MaxVersion.String(), hostsConfig.Ignition.Version)
+	assert.Empty(t, hostsConfig.Storage.Files, "the release payload must not be copied to the disk of the installed hosts")
+	require.Len(t, hostsConfig.Systemd.Units, 3)
+	assert.Equal(t, releasePayloadMountUnit, hostsConfig.Systemd.Units[0].Name)
+	assert.Equal(t, releasePayloadService, hostsConfig.Systemd.Units[1].Name)
+	assert.Equal(t, releasePayloadCleanup, hostsConfig.Systemd.Units[2].Name)
+	cleanup := *hostsConfig.Systemd.Units[2].Contents
+	assert.Contains(t, cleanup, "ConditionPathExists=!/dev/disk/by-label/agent-release\n")
+	assert.Contains(t, cleanup, "ExecStart=/bin/rm -f /etc/containers/registries.conf.d/99-agent-release-payload.conf\n")
+}
+
+func TestValidateEmbedRelease(t *testing.T) {
+	defer func(image string) { EmbedRegistryImage = image }(EmbedRegistryImage)
+
+	cases := []struct {
+		image string
+		err   string
+	}{
+		{
+			image: testRegistryImage,
+		},
+		{
+			image: "",
+			err:   "embedding the release payload requires the image of the registry serving it on the hosts, referenced by digest",
+		},
+		{
+			image: "ghcr.io/project-zot/zot-linux-amd64:v2.0.0",
+			err:   "the registry image ghcr.io/project-zot/zot-linux-amd64:v2.0.0 must be referenced by its sha256 digest",
+		},
+		{
+			image: "ghcr.io/project-zot/zot-linux-amd64@sha256:0123",
+			err:   "the registry image ghcr.io/project-zot/zot-linux-amd64@sha256:0123 must be referenced by its sha256 digest",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			EmbedRegistryImage = tc.image
+			err := validateEmbedRelease()
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPayloadImageLayout(t *testing.T) {
+	cases := []struct {
+		image      string
+		repository string
+		tag        string
+		err        string
+	}{
+		{
+			image:      "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:0123456789abcdef",
+			repository: "quay.io/openshift-release-dev/ocp-v4.0-art-dev",
+			tag:        "sha256-0123456789abcdef",
+		},
+		{
+			image:      "quay.io/openshift-release-dev/ocp-release:4.15.0-x86_64",
+			repository: "quay.io/openshift-release-dev/ocp-release",
+			tag:        "4.15.0-x86_64",
+		},
+		{
+			image:      "registry.example.com:5000/operators/catalog",
+			repository: "registry.example.com:5000/operators/catalog",
+			tag:        "latest",
+		},
+		{
+			image: "operators/catalog:v1",
+			err:   "image operators/catalog:v1 must be fully qualified with its registry",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			repository, tag, err := payloadImageLayout(tc.image)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.repository, repository)
+			assert.Equal(t, tc.tag, tag)
+		})
+	}
+}
+
+func TestReleasePayloadRegistriesConf(t *testing.T) {
+	expected := `[[registry]]
+location = "quay.io/openshift-release-dev/ocp-release"
+
+[[registry.mirror]]
+location = "localhost:5005/quay.io/openshift-release-dev/ocp-release"
+insecure = true
+
+`
+	assert.Equal(t, expected, releasePayloadRegistriesConfContents([]string{"quay.io/openshift-release-dev/ocp-release"}))
+}
@@ -0,0 +1,158 @@
+package image
+
+import (
+	"fmt"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+
+	"github.com/openshift/installer/pkg/asset/ignition"
+)
+
+const (
+	// rendezvousIPPlaceholder stands for the IP of the rendezvous host in the
+	// environment of the rendezvous host when the IP is discovered by the
+	// hosts.
+	rendezvousIPPlaceholder = "__RENDEZVOUS_IP__"
+
+	rendezvousDiscoveryScriptPath = "/usr/local/bin/agent-rendezvous-discovery.sh"
+)
+
+// rendezvousDiscoveryScript elects the host with the rendezvous MAC address as
+// the rendezvous host. The other hosts discover its IP by sweeping the IPv4
+// subnets of their interfaces and finding the MAC address in their neighbor
+// table, which is filled even when the hosts do not answer to ping. The hosts
+// then resolve the name of the certificate of the Agent Rest API to its IP.
+// When a hostname is set, the rendezvous host sends it to the DHCP server of
+// its interface, for the installer to resolve it.
+const rendezvousDiscoveryScript = `#!/bin/bash
+set -euo pipefail
+
+rendezvous_mac="${RENDEZVOUS_MAC}"
+rendezvous_hostname="${RENDEZVOUS_HOSTNAME:-}"
+env_file="` + rendezvousHostEnvPath + `"
+server_name="` + RendezvousServerName + `"
+
+register_hostname() {
+	local iface connection
+	for iface in /sys/class/net/*; do
+		if [ "$(cat "${iface}/address" 2> /dev/null)" = "${rendezvous_mac}" ]; then
+			connection=$(nmcli -g GENERAL.CONNECTION device show "$(basename "${iface}")")
+			if [ -n "${connection}" ] && [ "$(nmcli -g ipv4.method connection show "${connection}")" = "auto" ]; then
+				echo "Registering the rendezvous host as ${rendezvous_hostname} with the DHCP server"
+				nmcli connection modify "${connection}" ipv4.dhcp-hostname "${rendezvous_hostname}" ipv4.dhcp-send-hostname yes &&
+					nmcli connection up "${connection}" ||
+					echo "Failed to register the rendezvous host with the DHCP server"
+			fi
+			return 0
+		fi
+	done
+}
+
+local_rendezvous_ip() {
+	for iface in /sys/class/net/*; do
+		if [ "$(cat "${iface}/address" 2> /dev/null)" = "${rendezvous_mac}" ]; then
+			ip -4 -o addr show dev "$(basename "${iface}")" scope global | awk '{split($4, a, "/"); print a[1]; exit}'
+			return 0
+		fi
+	done
+	return 1
+}
+
+ip_to_int() {
+	local IFS=.
+	read -r a b c d <<< "$1"
+	echo $(( (a << 24) | (b << 16) | (c << 8) | d ))
+}
+
+int_to_ip() {
+	echo "$(( ($1 >> 24) & 255 )).$(( ($1 >> 16) & 255 )).$(( ($1 >> 8) & 255 )).$(( $1 & 255 ))"
+}
+
+sweep_subnets() {
+	ip -4 -o addr show scope global | awk '{print $4}' | while read -r cidr; do
+		prefix="${cidr#*/}"
+		# Larger subnets take too long to sweep.
+		if [ "${prefix}" -lt 22 ] || [ "${prefix}" -gt 30 ]; then
+			continue
+		fi
+		network=$(( $(ip_to_int "${cidr%/*}") & ~((1 << (32 - prefix)) - 1) & 0xffffffff ))
+		for (( i = 1; i < (1 << (32 - prefix)) - 1; i++ )); do
+			ping -c 1 -W 1 "$(int_to_ip $(( network + i )))" > /dev/null 2>&1 &
+			if (( i % 256 == 0 )); then
+				wait
+			fi
+		done
+		wait
+	done
+}
+
+neighbor_ip() {
+	ip -4 neigh show | awk -v mac="${rendezvous_mac}" '{for (i = 1; i < NF; i++) if ($i == "lladdr" && tolower($(i + 1)) == mac) {print $1; exit}}'
+}
+
+if [ -n "${rendezvous_hostname}" ]; then
+	register_hostname
+fi
+
+while true; do
+	if rendezvous_ip=$(local_rendezvous_ip); then
+		if [ -n "${rendezvous_ip}" ]; then
+			echo "This host is the rendezvous host ${rendezvous_ip}"
+			break
+		fi
+		echo "Waiting for the IPv4 address of the rendezvous host interface ${rendezvous_mac}"
+	else
+		rendezvous_ip=$(neighbor_ip)
+		if [ -n "${rendezvous_ip}" ]; then
+			echo "Discovered the rendezvous host ${rendezvous_ip}"
+			break
+		fi
+		echo "Looking for the rendezvous host ${rendezvous_mac} on the local subnets"
+		sweep_subnets
+		rendezvous_ip=$(neighbor_ip)
+		if [ -n "${rendezvous_ip}" ]; then
+			echo "Discovered the rendezvous host ${rendezvous_ip}"
+			break
+		fi
+	fi
+	sleep 10
+done
+
+sed -i "/ ${server_name}\$/d" /etc/hosts
+echo "${rendezvous_ip} ${server_name}" >> /etc/hosts
+sed -i "s/` + rendezvousIPPlaceholder + `/${rendezvous_ip}/g" "${env_file}"
+`
+
+const rendezvousDiscoveryUnit = `[Unit]
+Description=Discover the rendezvous host of the agent installation
+Wants=network-online.target
+After=network-online.target
+Before=agent.service node-zero.service agent-interactive-console.service set-hostname.service
+ConditionPathExists=` + rendezvousDiscoveryScriptPath + `
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+Environment=RENDEZVOUS_MAC=%s
+Environment=RENDEZVOUS_HOSTNAME=%s
+ExecStart=` + rendezvousDiscoveryScriptPath + `
+StandardOutput=journal+console
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// addRendezvousDiscovery adds the service discovering the rendezvous host
+// with the MAC address when the ISO boots, and registering it with the
+// hostname, if any.
+func addRendezvousDiscovery(config *igntypes.Config, rendezvousMAC, rendezvousHostname string) {
+	config.Storage.Files = append(config.Storage.Files,
+		ignition.FileFromString(rendezvousDiscoveryScriptPath, "root", 0755, rendezvousDiscoveryScript))
+	enabled := true
+	contents := fmt.Sprintf(rendezvousDiscoveryUnit, rendezvousMAC, rendezvousHostname)
+	config.Systemd.Units = append(config.Systemd.Units, igntypes.Unit{
+		Name:     "agent-rendezvous-discovery.service",
+		Enabled:  &enabled,
+		Contents: &contents,
+	})
+}
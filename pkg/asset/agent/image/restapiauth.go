@@ -0,0 +1,205 @@
+package image
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	"github.com/openshift/installer/pkg/asset/tls"
+)
+
+const (
+	// restAPITLSPath is the directory of the TLS certificates of the Agent
+	// Rest API on the rendezvous host.
+	restAPITLSPath = "/opt/agent/tls"
+
+	// RestAPIAuthHeader is the header of the token authenticating the users
+	// of the Agent Rest API.
+	RestAPIAuthHeader = "Authorization"
+
+	// RendezvousServerName is the name the certificate of the Agent Rest API
+	// is issued for when the rendezvous host is discovered, since its IP is
+	// not known up front. The hosts resolve it to the discovered IP, and the
+	// installer verifies it when connecting to the IP.
+	RendezvousServerName = "rendezvous.agent.internal"
+)
+
+// RestAPIAuthEnabled serves the Agent Rest API of the generated images over
+// HTTPS with authentication. The ISO then holds the tokens of the API, and the
+// agent services of the release must support the TLS and the local
+// authentication of assisted-service.
+var RestAPIAuthEnabled bool
+
+// RestAPIAuthOption is an asset with the value of RestAPIAuthEnabled. It is
+// only loaded when the flag is set, enabling the authentication of an existing
+// state file; otherwise the option of the state file is kept, so that the
+// commands reading it, like wait-for, keep authenticating to the API.
+type RestAPIAuthOption struct {
+	Enabled bool
+}
+
+var _ asset.WritableAsset = (*RestAPIAuthOption)(nil)
+
+// Name returns the human-friendly name of the asset.
+func (*RestAPIAuthOption) Name() string {
+	return "Agent Rest API Authentication Option"
+}
+
+// Dependencies returns no dependencies.
+func (*RestAPIAuthOption) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Generate reads RestAPIAuthEnabled.
+func (a *RestAPIAuthOption) Generate(asset.Parents) error {
+	a.Enabled = RestAPIAuthEnabled
+	return nil
+}
+
+// Files returns no files, the option is a flag.
+func (*RestAPIAuthOption) Files() []*asset.File {
+	return nil
+}
+
+// Load finds the asset when RestAPIAuthEnabled is set.
+func (a *RestAPIAuthOption) Load(asset.FileFetcher) (bool, error) {
+	if !RestAPIAuthEnabled {
+		return false, nil
+	}
+	a.Enabled = true
+	return true, nil
+}
+
+// RestAPIAuth is an asset generating the TLS certificates and the
+// authentication tokens of the Agent Rest API on the rendezvous host, when
+// enabled by the RestAPIAuthOption. The API is otherwise served over plain
+// HTTP without authentication on the provisioning network.
+type RestAPIAuth struct {
+	// CACert is the PEM encoded CA which signed the certificate of the API.
+	CACert []byte
+	// ServerCert and ServerKey are the PEM encoded certificate and key of
+	// the API, for the rendezvous IP, or for the RendezvousServerName when
+	// the rendezvous host is discovered by the hosts.
+	ServerCert []byte
+	ServerKey  []byte
+	// PublicKey is the PEM encoded ECDSA key verifying the tokens.
+	PublicKey []byte
+	// UserToken authenticates the installer to the API.
+	UserToken string
+	// AgentToken authenticates the agents of the hosts to the API.
+	AgentToken string
+}
+
+var _ asset.Asset = (*RestAPIAuth)(nil)
+
+// Name returns the human-friendly name of the asset.
+func (a *RestAPIAuth) Name() string {
+	return "Agent Rest API Authentication"
+}
+
+// Dependencies returns the assets on which the RestAPIAuth asset depends.
+func (a *RestAPIAuth) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&agentconfig.AgentConfig{},
+		&manifests.AgentManifests{},
+		&RestAPIAuthOption{},
+	}
+}
+
+// Generate generates the certificates of the API for the rendezvous IP, and
+// the key pair signing the tokens, when the authentication is enabled.
+func (a *RestAPIAuth) Generate(dependencies asset.Parents) error {
+	agentConfigAsset := &agentconfig.AgentConfig{}
+	agentManifests := &manifests.AgentManifests{}
+	option := &RestAPIAuthOption{}
+	dependencies.Get(agentConfigAsset, agentManifests, option)
+
+	*a = RestAPIAuth{}
+	discovered := agentconfig.RendezvousMACAddress(agentConfigAsset.Config) != ""
+	if !option.Enabled {
+		// The installer only connects to the discovered rendezvous host with
+		// the certificate issued for the RendezvousServerName.
+		if discovered {
+			return errors.New("the rendezvousDiscovery of agent-config.yaml requires the authentication of the Agent Rest API, enabled with --rest-api-auth")
+		}
+		return nil
+	}
+
+	caKey, caCert, err := tls.GenerateSelfSignedCertificate(&tls.CertCfg{
+		Subject:   pkix.Name{CommonName: "agent-rest-api-ca", OrganizationalUnit: []string{"openshift"}},
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  tls.ValidityOneYear,
+		IsCA:      true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to generate the CA of the Agent Rest API")
+	}
+	a.CACert = tls.CertToPem(caCert)
+
+	serverCfg := &tls.CertCfg{
+		Subject:      pkix.Name{CommonName: "agent-rest-api", OrganizationalUnit: []string{"openshift"}},
+		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Validity:     tls.ValidityOneYear,
+	}
+	if discovered {
+		serverCfg.DNSNames = []string{RendezvousServerName}
+	} else {
+		rendezvousIP, err := RetrieveRendezvousIP(agentConfigAsset.Config, agentManifests.NMStateConfigs)
+		if err != nil {
+			return err
+		}
+		serverCfg.IPAddresses = []net.IP{net.ParseIP(rendezvousIP)}
+	}
+	// The key of the CA is dropped, it is never written to the ISO.
+	serverKey, serverCert, err := tls.GenerateSignedCertificate(caKey, caCert, serverCfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate the certificate of the Agent Rest API")
+	}
+	a.ServerCert = tls.CertToPem(serverCert)
+	a.ServerKey = tls.PrivateKeyToPem(serverKey)
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate the key signing the tokens")
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&signingKey.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the key verifying the tokens")
+	}
+	userToken, err := restAPIToken(signingKey, "userAuth")
+	if err != nil {
+		return err
+	}
+	agentToken, err := restAPIToken(signingKey, "agentAuth")
+	if err != nil {
+		return err
+	}
+
+	a.PublicKey = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+	a.UserToken = userToken
+	a.AgentToken = agentToken
+	return nil
+}
+
+// restAPIToken returns a token of the authentication scheme, signed with the
+// key, as verified by the local authenticator of assisted-service.
+func restAPIToken(key *ecdsa.PrivateKey, scheme string) (string, error) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"auth_scheme": scheme,
+	}).SignedString(key)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to sign the %s token", scheme)
+	}
+	return token, nil
+}
@@ -0,0 +1,116 @@
+package image
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	"github.com/openshift/installer/pkg/asset/tls"
+	"github.com/openshift/installer/pkg/types/agent"
+)
+
+func TestRestAPIAuth_Generate(t *testing.T) {
+	parents := asset.Parents{}
+	parents.Add(
+		&agentconfig.AgentConfig{Config: &agent.Config{RendezvousIP: "192.168.111.80"}},
+		&manifests.AgentManifests{},
+		&RestAPIAuthOption{Enabled: true},
+	)
+	auth := &RestAPIAuth{}
+	require.NoError(t, auth.Generate(parents))
+
+	caCert, err := tls.PemToCertificate(auth.CACert)
+	require.NoError(t, err)
+	serverCert, err := tls.PemToCertificate(auth.ServerCert)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	_, err = serverCert.Verify(x509.VerifyOptions{DNSName: "192.168.111.80", Roots: pool})
+	assert.NoError(t, err)
+	_, err = tls.PemToPrivateKey(auth.ServerKey)
+	assert.NoError(t, err)
+
+	block, _ := pem.Decode(auth.PublicKey)
+	require.NotNil(t, block)
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+	for token, scheme := range map[string]string{auth.UserToken: "userAuth", auth.AgentToken: "agentAuth"} {
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+			return publicKey, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, scheme, claims["auth_scheme"])
+	}
+}
+
+func TestRestAPIAuth_GenerateRendezvousDiscovery(t *testing.T) {
+	parents := asset.Parents{}
+	parents.Add(
+		&agentconfig.AgentConfig{Config: &agent.Config{
+			RendezvousDiscovery: &agent.RendezvousDiscovery{MACAddress: "52:54:00:aa:bb:cc"},
+		}},
+		&manifests.AgentManifests{},
+		&RestAPIAuthOption{Enabled: true},
+	)
+	auth := &RestAPIAuth{}
+	require.NoError(t, auth.Generate(parents))
+
+	// The certificate is issued for the name of the rendezvous host, the key
+	// of the CA is not kept.
+	serverCert, err := tls.PemToCertificate(auth.ServerCert)
+	require.NoError(t, err)
+	assert.Equal(t, []string{RendezvousServerName}, serverCert.DNSNames)
+	assert.Empty(t, serverCert.IPAddresses)
+	_, err = tls.PemToPrivateKey(auth.ServerKey)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, auth.UserToken)
+}
+
+func TestRestAPIAuth_GenerateDisabled(t *testing.T) {
+	parents := asset.Parents{}
+	parents.Add(
+		&agentconfig.AgentConfig{Config: &agent.Config{RendezvousIP: "192.168.111.80"}},
+		&manifests.AgentManifests{},
+		&RestAPIAuthOption{},
+	)
+	auth := &RestAPIAuth{UserToken: "stale-token"}
+	require.NoError(t, auth.Generate(parents))
+	assert.Equal(t, &RestAPIAuth{}, auth)
+}
+
+func TestRestAPIAuth_GenerateRendezvousDiscoveryDisabled(t *testing.T) {
+	parents := asset.Parents{}
+	parents.Add(
+		&agentconfig.AgentConfig{Config: &agent.Config{
+			RendezvousDiscovery: &agent.RendezvousDiscovery{MACAddress: "52:54:00:aa:bb:cc"},
+		}},
+		&manifests.AgentManifests{},
+		&RestAPIAuthOption{},
+	)
+	auth := &RestAPIAuth{}
+	assert.EqualError(t, auth.Generate(parents), "the rendezvousDiscovery of agent-config.yaml requires the authentication of the Agent Rest API, enabled with --rest-api-auth")
+}
+
+func TestRestAPIAuthOption_Load(t *testing.T) {
+	defer func(enabled bool) { RestAPIAuthEnabled = enabled }(RestAPIAuthEnabled)
+
+	RestAPIAuthEnabled = false
+	option := &RestAPIAuthOption{}
+	found, err := option.Load(nil)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	RestAPIAuthEnabled = true
+	found, err = option.Load(nil)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, option.Enabled)
+}
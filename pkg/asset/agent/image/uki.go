@@ -0,0 +1,115 @@
+package image
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PXEFormatPXE generates the kernel, initrd and rootfs of the PXE files.
+	PXEFormatPXE = "pxe"
+	// PXEFormatUKI also generates a Unified Kernel Image, bundling the
+	// kernel, the initrd and the kernel arguments in a single EFI binary
+	// booted with UEFI HTTP boot.
+	PXEFormatUKI = "uki"
+)
+
+var (
+	// PXEFilesFormat is the format of the generated PXE files.
+	PXEFilesFormat = PXEFormatPXE
+
+	// UKISigningKeyFile and UKISigningCertFile are the private key and the
+	// certificate signing the Unified Kernel Images for Secure Boot. The
+	// images are not signed when they are unset.
+	UKISigningKeyFile  string
+	UKISigningCertFile string
+
+	// UKIRootFSURL is the URL the hosts booting the Unified Kernel Images
+	// download the rootfs from, %s being replaced by the CPU architecture,
+	// instead of the boot artifacts base URL.
+	UKIRootFSURL string
+)
+
+// ukiEFIArchs are the EFI architectures of the CPU architectures with UEFI.
+var ukiEFIArchs = map[string]string{
+	"x86_64":  "x64",
+	"aarch64": "aa64",
+}
+
+func validatePXEFilesFormat(bootArtifactsBaseURL string) error {
+	switch PXEFilesFormat {
+	case PXEFormatPXE, PXEFormatUKI:
+	default:
+		return errors.Errorf("unsupported PXE files format %q, must be %q or %q", PXEFilesFormat, PXEFormatPXE, PXEFormatUKI)
+	}
+	if (UKISigningKeyFile == "") != (UKISigningCertFile == "") {
+		return errors.New("signing the Unified Kernel Images requires both the signing key and certificate")
+	}
+	// The rootfs is too large for the memory UEFI HTTP boot loads the image
+	// into, so it is always downloaded by the live initramfs.
+	if PXEFilesFormat == PXEFormatUKI && bootArtifactsBaseURL == "" && UKIRootFSURL == "" {
+		return errors.New("the Unified Kernel Images require bootArtifactsBaseURL in agent-config.yaml, or the rootfs URL, which the hosts download the rootfs from")
+	}
+	return nil
+}
+
+// ukiRootFSURL returns the URL the hosts booting the Unified Kernel Image of
+// the architecture download the rootfs from.
+func (a *AgentPXEFiles) ukiRootFSURL() string {
+	if UKIRootFSURL == "" {
+		return fmt.Sprintf("%s/agent.%s-rootfs.img", a.bootArtifactsBaseURL, a.cpuArch)
+	}
+	if strings.Contains(UKIRootFSURL, "%s") {
+		return fmt.Sprintf(UKIRootFSURL, a.cpuArch)
+	}
+	return UKIRootFSURL
+}
+
+// createUKI builds the agent.<arch>.efi Unified Kernel Image out of the PXE
+// files of the architecture with ukify. The rootfs is downloaded from the
+// rootfs URL when it is set, and from the boot artifacts base URL otherwise.
+func (a *AgentPXEFiles) createUKI(bootArtifactsFullPath string) error {
+	efiArch, ok := ukiEFIArchs[a.cpuArch]
+	if !ok {
+		logrus.Warnf("Unified Kernel Images require UEFI, skipping the %s image", a.cpuArch)
+		return nil
+	}
+
+	ukifyPath, err := exec.LookPath("ukify")
+	if err != nil {
+		return errors.Wrap(err, "install the systemd-ukify package to create Unified Kernel Images")
+	}
+
+	ukiFile := fmt.Sprintf("agent.%s.efi", a.cpuArch)
+	args := []string{
+		"build",
+		"--efi-arch=" + efiArch,
+		"--linux=" + filepath.Join(bootArtifactsFullPath, fmt.Sprintf("agent.%s-vmlinuz", a.cpuArch)),
+		"--initrd=" + filepath.Join(bootArtifactsFullPath, fmt.Sprintf("agent.%s-initrd.img", a.cpuArch)),
+		"--cmdline=" + fmt.Sprintf("coreos.live.rootfs_url=%s %s", a.ukiRootFSURL(), strings.TrimSpace(a.kernelArgs)),
+		"--os-release=" + fmt.Sprintf("ID=agent-installer\nNAME=\"OpenShift Agent Installer\"\nPRETTY_NAME=\"OpenShift Agent Installer (%s)\"\n", a.cpuArch),
+		"--output=" + filepath.Join(bootArtifactsFullPath, ukiFile),
+	}
+	if UKISigningKeyFile != "" {
+		args = append(args,
+			"--secureboot-private-key="+UKISigningKeyFile,
+			"--secureboot-certificate="+UKISigningCertFile)
+	} else {
+		logrus.Warnf("The Unified Kernel Image %s is not signed, it can't boot with Secure Boot enabled", ukiFile)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(ukifyPath, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "failed to create the Unified Kernel Image %s: %s", ukiFile, strings.TrimSpace(stderr.String()))
+	}
+	logrus.Infof("Created Unified Kernel Image %s in %s directory", ukiFile, bootArtifactsFullPath)
+	return nil
+}
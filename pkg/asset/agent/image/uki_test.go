@@ -0,0 +1,174 @@
+package image
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUkify writes its arguments to the output file.
+const fakeUkify = `#!/bin/sh
+for arg in "$@"; do
+	case "${arg}" in
+	--output=*) output="${arg#--output=}" ;;
+	esac
+done
+printf '%s\n' "$@" > "${output}"
+`
+
+func TestCreateUKI(t *testing.T) {
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "ukify"), []byte(fakeUkify), 0755)) //nolint:gosec
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cases := []struct {
+		name                 string
+		cpuArch              string
+		bootArtifactsBaseURL string
+		rootFSURL            string
+		signed               bool
+		expectedArgs         []string
+		expectedNoImage      bool
+	}{
+		{
+			name:                 "rootfs URL",
+			cpuArch:              "x86_64",
+			bootArtifactsBaseURL: "http://boot.example.com",
+			rootFSURL:            "http://mirror.example.com/rhcos-%s-live-rootfs.img",
+			expectedArgs: []string{
+				"build",
+				"--efi-arch=x64",
+				"--linux=<dir>/agent.x86_64-vmlinuz",
+				"--initrd=<dir>/agent.x86_64-initrd.img",
+				"--cmdline=coreos.live.rootfs_url=http://mirror.example.com/rhcos-x86_64-live-rootfs.img rw ignition.firstboot fips=1",
+			},
+		},
+		{
+			name:      "rootfs URL without architecture",
+			cpuArch:   "x86_64",
+			rootFSURL: "http://mirror.example.com/rootfs.img",
+			expectedArgs: []string{
+				"build",
+				"--efi-arch=x64",
+				"--linux=<dir>/agent.x86_64-vmlinuz",
+				"--initrd=<dir>/agent.x86_64-initrd.img",
+				"--cmdline=coreos.live.rootfs_url=http://mirror.example.com/rootfs.img rw ignition.firstboot fips=1",
+			},
+		},
+		{
+			name:                 "rootfs downloaded and signed",
+			cpuArch:              "aarch64",
+			bootArtifactsBaseURL: "http://boot.example.com",
+			signed:               true,
+			expectedArgs: []string{
+				"build",
+				"--efi-arch=aa64",
+				"--linux=<dir>/agent.aarch64-vmlinuz",
+				"--initrd=<dir>/agent.aarch64-initrd.img",
+				"--cmdline=coreos.live.rootfs_url=http://boot.example.com/agent.aarch64-rootfs.img rw ignition.firstboot fips=1",
+			},
+		},
+		{
+			name:            "no UEFI",
+			cpuArch:         "s390x",
+			expectedNoImage: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.signed {
+				UKISigningKeyFile, UKISigningCertFile = "db.key", "db.crt"
+				defer func() { UKISigningKeyFile, UKISigningCertFile = "", "" }()
+			}
+			UKIRootFSURL = tc.rootFSURL
+			defer func() { UKIRootFSURL = "" }()
+			dir := t.TempDir()
+			files := &AgentPXEFiles{
+				cpuArch:              tc.cpuArch,
+				bootArtifactsBaseURL: tc.bootArtifactsBaseURL,
+				kernelArgs:           "rw ignition.firstboot fips=1",
+			}
+			require.NoError(t, files.createUKI(dir))
+
+			data, err := os.ReadFile(filepath.Join(dir, "agent."+tc.cpuArch+".efi"))
+			if tc.expectedNoImage {
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+			require.NoError(t, err)
+			args := strings.ReplaceAll(string(data), dir, "<dir>")
+			assert.True(t, strings.HasPrefix(args, strings.Join(tc.expectedArgs, "\n")+"\n--os-release="), args)
+			assert.Equal(t, tc.signed, strings.Contains(args, "--secureboot-private-key=db.key\n--secureboot-certificate=db.crt\n"))
+		})
+	}
+}
+
+func TestValidatePXEFilesFormat(t *testing.T) {
+	cases := []struct {
+		name                 string
+		format               string
+		signingKey           string
+		signingCert          string
+		rootFSURL            string
+		bootArtifactsBaseURL string
+		expectedError        string
+	}{
+		{
+			name:   "pxe",
+			format: PXEFormatPXE,
+		},
+		{
+			name:          "unsupported format",
+			format:        "efi",
+			expectedError: `unsupported PXE files format "efi", must be "pxe" or "uki"`,
+		},
+		{
+			name:                 "uki",
+			format:               PXEFormatUKI,
+			bootArtifactsBaseURL: "http://boot.example.com",
+		},
+		{
+			name:                 "signed uki",
+			format:               PXEFormatUKI,
+			signingKey:           "db.key",
+			signingCert:          "db.crt",
+			bootArtifactsBaseURL: "http://boot.example.com",
+		},
+		{
+			name:                 "uki signing key without certificate",
+			format:               PXEFormatUKI,
+			signingKey:           "db.key",
+			bootArtifactsBaseURL: "http://boot.example.com",
+			expectedError:        "signing the Unified Kernel Images requires both the signing key and certificate",
+		},
+		{
+			name:      "uki with rootfs URL",
+			format:    PXEFormatUKI,
+			rootFSURL: "http://mirror.example.com/rhcos-%s-live-rootfs.img",
+		},
+		{
+			name:          "uki without rootfs URL",
+			format:        PXEFormatUKI,
+			expectedError: "the Unified Kernel Images require bootArtifactsBaseURL in agent-config.yaml, or the rootfs URL, which the hosts download the rootfs from",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				PXEFilesFormat, UKISigningKeyFile, UKISigningCertFile, UKIRootFSURL = PXEFormatPXE, "", "", ""
+			}()
+			PXEFilesFormat, UKISigningKeyFile, UKISigningCertFile, UKIRootFSURL = tc.format, tc.signingKey, tc.signingCert, tc.rootFSURL
+
+			err := validatePXEFilesFormat(tc.bootArtifactsBaseURL)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -51,7 +51,8 @@ func GetConfigImageFiles() []string {
 		"/opt/agent/tls/kube-apiserver-localhost-signer.crt",
 		"/opt/agent/tls/kube-apiserver-service-network-signer.key",
 		"/opt/agent/tls/kube-apiserver-service-network-signer.crt",
-		rendezvousHostEnvPath, // This file must be last in the list
+		restAPITLSPath + "/agent-rest-api", // TLS certificates and key of the Agent Rest API
+		rendezvousHostEnvPath,              // This file must be last in the list
 	}
 }
 
@@ -77,6 +78,7 @@ func (a *UnconfiguredIgnition) Dependencies() []asset.Asset {
 		&manifests.NMStateConfig{},
 		&mirror.RegistriesConf{},
 		&mirror.CaBundle{},
+		&InteractiveConfig{},
 	}
 }
 
@@ -113,15 +115,17 @@ func (a *UnconfiguredIgnition) Generate(dependencies asset.Parents) error {
 		archName = infraEnv.Spec.CpuArchitecture
 	}
 
-	releaseImageList, err := releaseImageList(clusterImageSet.Spec.ReleaseImage, archName)
-	if err != nil {
-		return err
-	}
-
 	registriesConfig := &mirror.RegistriesConf{}
 	registryCABundle := &mirror.CaBundle{}
 	dependencies.Get(registriesConfig, registryCABundle)
 
+	// The pull secret is only known once the image is configured.
+	multiArchRelease := isMultiArchRelease(clusterImageSet.Spec.ReleaseImage, "", registriesConfig.MirrorConfig)
+	releaseImageList, err := releaseImageList(clusterImageSet.Spec.ReleaseImage, archName, multiArchRelease)
+	if err != nil {
+		return err
+	}
+
 	infraEnvID := uuid.New().String()
 	logrus.Debug("Generated random infra-env id ", infraEnvID)
 
@@ -190,6 +194,13 @@ func (a *UnconfiguredIgnition) Generate(dependencies asset.Parents) error {
 		return err
 	}
 
+	interactiveConfig := &InteractiveConfig{}
+	dependencies.Get(interactiveConfig)
+	err = addInteractiveConfig(&config, interactiveConfig, "")
+	if err != nil {
+		return err
+	}
+
 	addMirrorData(&config, registriesConfig, registryCABundle)
 
 	a.Config = &config
@@ -201,3 +201,9 @@ func generatedFilesUnconfiguredIgnition(otherFiles ...string) []string {
 	unconfiguredIgnitionFiles = append(unconfiguredIgnitionFiles, otherFiles...)
 	return append(unconfiguredIgnitionFiles, commonFiles()...)
 }
+
+func TestGetConfigImageFiles(t *testing.T) {
+	files := GetConfigImageFiles()
+	assert.Contains(t, files, restAPITLSPath+"/agent-rest-api", "the TLS certificates of the REST API are in the config image")
+	assert.Equal(t, rendezvousHostEnvPath, files[len(files)-1], "the rendezvous host env file must be last")
+}
@@ -0,0 +1,111 @@
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/assisted-image-service/pkg/isoeditor"
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent/manifests"
+	"github.com/openshift/installer/pkg/asset/agent/mirror"
+)
+
+const (
+	unconfiguredISOFilename = "agent-unconfigured.%s.iso"
+)
+
+// UnconfiguredImage is an asset that generates a generic bootable image
+// without any cluster configuration. The hosts booting it wait for the
+// configuration ISO of the agent config-image, so the same image can be
+// flashed on all the hosts before the configuration of their site is known.
+type UnconfiguredImage struct {
+	image *AgentImage
+}
+
+var _ asset.WritableAsset = (*UnconfiguredImage)(nil)
+
+// Dependencies returns the assets on which the UnconfiguredImage asset depends.
+func (a *UnconfiguredImage) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&UnconfiguredIgnition{},
+		&manifests.ClusterImageSet{},
+		&manifests.AgentPullSecret{},
+		&mirror.RegistriesConf{},
+	}
+}
+
+// Generate generates the unconfigured image.
+func (a *UnconfiguredImage) Generate(dependencies asset.Parents) error {
+	unconfiguredIgnition := &UnconfiguredIgnition{}
+	clusterImageSet := &manifests.ClusterImageSet{}
+	pullSecret := &manifests.AgentPullSecret{}
+	registriesConf := &mirror.RegistriesConf{}
+	dependencies.Get(unconfiguredIgnition, clusterImageSet, pullSecret, registriesConf)
+
+	ignitionByte, err := json.Marshal(unconfiguredIgnition.Config)
+	if err != nil {
+		return err
+	}
+
+	cpuArch := unconfiguredIgnition.CPUArch
+	releaseImage := clusterImageSet.Config.Spec.ReleaseImage
+	baseIso, err := getBaseIso(cpuArch, releaseImage, pullSecret.GetPullSecretData(), registriesConf.MirrorConfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to get base ISO image")
+	}
+	tmpPath, err := extractAgentArtifacts(baseIso, cpuArch, releaseImage, pullSecret.GetPullSecretData(), registriesConf.MirrorConfig, true)
+	if err != nil {
+		return err
+	}
+
+	image := &AgentImage{cpuArch: cpuArch, tmpPath: tmpPath, isoPath: baseIso}
+	if err := image.embedIgnition(ignitionByte); err != nil {
+		return err
+	}
+	a.image = image
+	return nil
+}
+
+// PersistToFile writes the unconfigured image in the assets folder.
+func (a *UnconfiguredImage) PersistToFile(directory string) error {
+	// If the image is not set then it means that either one of the
+	// UnconfiguredImage dependencies or the asset itself failed for some reason
+	if a.image == nil {
+		return errors.New("cannot generate the unconfigured ISO image due to configuration errors")
+	}
+	defer os.RemoveAll(a.image.tmpPath)
+
+	isoFile := filepath.Join(directory, fmt.Sprintf(unconfiguredISOFilename, a.image.cpuArch))
+
+	// Remove symlink if it exists
+	os.Remove(isoFile)
+
+	if err := isoeditor.Create(isoFile, a.image.tmpPath, a.image.volumeID); err != nil {
+		return err
+	}
+	logrus.Infof("Generated unconfigured ISO at %s, configure its hosts with the ISO of agent create config-image", isoFile)
+	return nil
+}
+
+// Name returns the human-friendly name of the asset.
+func (a *UnconfiguredImage) Name() string {
+	return "Agent Installer Unconfigured ISO"
+}
+
+// Load returns the ISO from disk.
+func (a *UnconfiguredImage) Load(f asset.FileFetcher) (bool, error) {
+	// The ISO will not be needed by another asset so load is noop.
+	// This is implemented because it is required by WritableAsset
+	return false, nil
+}
+
+// Files returns the files generated by the asset.
+func (a *UnconfiguredImage) Files() []*asset.File {
+	// Return empty array because File will never be loaded.
+	return []*asset.File{}
+}
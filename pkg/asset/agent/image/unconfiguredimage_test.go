@@ -0,0 +1,15 @@
+package image
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnconfiguredImage_PersistToFileWithoutImage(t *testing.T) {
+	dir := t.TempDir()
+	err := (&UnconfiguredImage{}).PersistToFile(dir)
+	assert.EqualError(t, err, "cannot generate the unconfigured ISO image due to configuration errors")
+	assert.NoFileExists(t, filepath.Join(dir, "agent-unconfigured.x86_64.iso"))
+}
@@ -16,6 +16,7 @@ import (
 	baremetaldefaults "github.com/openshift/installer/pkg/types/baremetal/defaults"
 	"github.com/openshift/installer/pkg/types/external"
 	"github.com/openshift/installer/pkg/types/none"
+	"github.com/openshift/installer/pkg/types/nutanix"
 	"github.com/openshift/installer/pkg/types/validation"
 	"github.com/openshift/installer/pkg/types/vsphere"
 )
@@ -77,6 +78,14 @@ func (a *OptionalInstallConfig) validateInstallConfig(installConfig *types.Insta
 		allErrs = append(allErrs, err...)
 	}
 
+	if err := a.validateIPFamilies(installConfig); err != nil {
+		allErrs = append(allErrs, err...)
+	}
+
+	if err := a.validatePlatformCredentials(installConfig); err != nil {
+		allErrs = append(allErrs, err...)
+	}
+
 	warnUnusedConfig(installConfig)
 
 	numMasters, numWorkers := GetReplicaCount(installConfig)
@@ -142,6 +151,35 @@ func (a *OptionalInstallConfig) validateSupportedArchs(installConfig *types.Inst
 	return allErrs
 }
 
+// validatePlatformCredentials checks that the vCenter credentials of the
+// vSphere platform are complete when a vCenter is specified, since the cluster
+// uses them for its storage and machine management. The vCenter is optional
+// for the agent installer, and the credentials of the Nutanix platform are
+// required by the install-config validation.
+func (a *OptionalInstallConfig) validatePlatformCredentials(installConfig *types.InstallConfig) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if installConfig.Platform.VSphere == nil {
+		return allErrs
+	}
+	for i, vcenter := range installConfig.Platform.VSphere.VCenters {
+		if vcenter.Server == "" {
+			continue
+		}
+		fieldPath := field.NewPath("Platform", "VSphere", fmt.Sprintf("VCenters[%d]", i))
+		if vcenter.Username == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("Username"), "must specify the username"))
+		}
+		if vcenter.Password == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("Password"), "must specify the password"))
+		}
+		if len(vcenter.Datacenters) == 0 {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("Datacenters"), "must specify at least one datacenter"))
+		}
+	}
+	return allErrs
+}
+
 func (a *OptionalInstallConfig) validateSNOConfiguration(installConfig *types.InstallConfig) field.ErrorList {
 	var allErrs field.ErrorList
 	var fieldPath *field.Path
@@ -330,38 +368,6 @@ func warnUnusedConfig(installConfig *types.InstallConfig) {
 	case vsphere.Name:
 		vspherePlatform := installConfig.Platform.VSphere
 
-		if vspherePlatform.DeprecatedVCenter != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "VCenter")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedVCenter))
-		}
-		if vspherePlatform.DeprecatedUsername != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "Username")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedUsername))
-		}
-		if vspherePlatform.DeprecatedPassword != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "Password")
-			logrus.Warnf(fmt.Sprintf("%s is ignored", fieldPath))
-		}
-		if vspherePlatform.DeprecatedDatacenter != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "Datacenter")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedDatacenter))
-		}
-		if vspherePlatform.DeprecatedDefaultDatastore != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "DefaultDatastore")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedDefaultDatastore))
-		}
-		if vspherePlatform.DeprecatedFolder != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "Folder")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedFolder))
-		}
-		if vspherePlatform.DeprecatedCluster != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "Cluster")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedCluster))
-		}
-		if vspherePlatform.DeprecatedResourcePool != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "ResourcePool")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedResourcePool))
-		}
 		if vspherePlatform.ClusterOSImage != "" {
 			fieldPath := field.NewPath("Platform", "VSphere", "ClusterOSImage")
 			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.ClusterOSImage))
@@ -370,21 +376,26 @@ func warnUnusedConfig(installConfig *types.InstallConfig) {
 			fieldPath := field.NewPath("Platform", "VSphere", "DefaultMachinePlatform")
 			logrus.Warnf(fmt.Sprintf("%s: %v is ignored", fieldPath, vspherePlatform.DefaultMachinePlatform))
 		}
-		if vspherePlatform.DeprecatedNetwork != "" {
-			fieldPath := field.NewPath("Platform", "VSphere", "Network")
-			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DeprecatedNetwork))
-		}
 		if vspherePlatform.DiskType != "" {
 			fieldPath := field.NewPath("Platform", "VSphere", "DiskType")
 			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.DiskType))
 		}
-		if len(vspherePlatform.VCenters) > 1 {
-			fieldPath := field.NewPath("Platform", "VSphere", "VCenters")
-			logrus.Warnf(fmt.Sprintf("%s: %v is ignored", fieldPath, vspherePlatform.VCenters))
+		// Only the first vCenter is used, the others are not logged in full
+		// since they hold credentials.
+		for i := 1; i < len(vspherePlatform.VCenters); i++ {
+			fieldPath := field.NewPath("Platform", "VSphere", fmt.Sprintf("VCenters[%d]", i))
+			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, vspherePlatform.VCenters[i].Server))
+		}
+	case nutanix.Name:
+		nutanixPlatform := installConfig.Platform.Nutanix
+
+		if nutanixPlatform.ClusterOSImage != "" {
+			fieldPath := field.NewPath("Platform", "Nutanix", "ClusterOSImage")
+			logrus.Warnf(fmt.Sprintf("%s: %s is ignored", fieldPath, nutanixPlatform.ClusterOSImage))
 		}
-		if len(vspherePlatform.FailureDomains) > 1 {
-			fieldPath := field.NewPath("Platform", "VSphere", "FailureDomains")
-			logrus.Warnf(fmt.Sprintf("%s: %v is ignored", fieldPath, vspherePlatform.FailureDomains))
+		if nutanixPlatform.DefaultMachinePlatform != nil && !reflect.DeepEqual(*nutanixPlatform.DefaultMachinePlatform, nutanix.MachinePool{}) {
+			fieldPath := field.NewPath("Platform", "Nutanix", "DefaultMachinePlatform")
+			logrus.Warnf(fmt.Sprintf("%s: %v is ignored", fieldPath, nutanixPlatform.DefaultMachinePlatform))
 		}
 	}
 	// "External" is the default set from generic install config code
@@ -40,7 +40,7 @@ platform:
 pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
 `,
 			expectedFound: false,
-			expectedError: `invalid install-config configuration: Platform: Unsupported value: "aws": supported values: "baremetal", "vsphere", "none", "external"`,
+			expectedError: `invalid install-config configuration: Platform: Unsupported value: "aws": supported values: "baremetal", "vsphere", "none", "external", "nutanix"`,
 		},
 		{
 			name: "apiVips not set for baremetal Compact platform",
@@ -102,7 +102,28 @@ platform:
 pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
 `,
 			expectedFound: false,
-			expectedError: `invalid install-config configuration: platform.vsphere.ingressVIPs: Required value: must specify VIP for ingress, when VIP for API is set`,
+			expectedError: `invalid install-config configuration: [platform.vsphere.ingressVIPs: Required value: must specify VIP for ingress, when VIP for API is set, Platform.VSphere.APIVIPs: Invalid value: "192.168.122.10": IP expected to be in one of the machine networks: 10.0.0.0/16]`,
+		},
+		{
+			name: "incomplete vCenter credentials for vsphere platform",
+			data: `
+apiVersion: v1
+metadata:
+  name: test-cluster
+baseDomain: test-domain
+platform:
+  vsphere:
+    apiVips:
+      - 10.0.0.10
+    ingressVips:
+      - 10.0.0.11
+    vcenters:
+      - server: vcenter.example.com
+        user: testUsername
+pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
+`,
+			expectedFound: false,
+			expectedError: `invalid install-config configuration: [Platform.VSphere.VCenters[0].Password: Required value: must specify the password, Platform.VSphere.VCenters[0].Datacenters: Required value: must specify at least one datacenter]`,
 		},
 		{
 			name: "no compute.replicas set for SNO",
@@ -181,7 +202,7 @@ platform:
 pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
 `,
 			expectedFound: false,
-			expectedError: "invalid install-config configuration: [Platform: Unsupported value: \"aws\": supported values: \"baremetal\", \"vsphere\", \"none\", \"external\", Platform: Invalid value: \"aws\": Only platform none and external supports 1 ControlPlane and 0 Compute nodes]",
+			expectedError: "invalid install-config configuration: [Platform: Unsupported value: \"aws\": supported values: \"baremetal\", \"vsphere\", \"none\", \"external\", \"nutanix\", Platform: Invalid value: \"aws\": Only platform none and external supports 1 ControlPlane and 0 Compute nodes]",
 		},
 		{
 			name: "invalid architecture for SNO cluster",
@@ -280,6 +301,51 @@ pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
 			expectedFound: false,
 			expectedError: `invalid install-config configuration: Platform.External.CloudControllerManager: Invalid value: "": When using external oci platform, Platform.External.CloudControllerManager must be set to External`,
 		},
+		{
+			name: "misordered dual-stack networks for baremetal platform",
+			data: `
+apiVersion: v1
+metadata:
+  name: test-cluster
+baseDomain: test-domain
+networking:
+  clusterNetwork:
+  - cidr: fd01::/48
+    hostPrefix: 64
+  - cidr: 10.128.0.0/14
+    hostPrefix: 23
+  networkType: OVNKubernetes
+  machineNetwork:
+  - cidr: 192.168.122.0/23
+  - cidr: fd2e:6f44:5dd8:c956::/120
+  serviceNetwork:
+  - 172.30.0.0/16
+  - fd02::/112
+compute:
+  - architecture: amd64
+    hyperthreading: Enabled
+    name: worker
+    platform: {}
+    replicas: 0
+controlPlane:
+  architecture: amd64
+  hyperthreading: Enabled
+  name: master
+  platform: {}
+  replicas: 3
+platform:
+  baremetal:
+    apiVIPs:
+      - fd2e:6f44:5dd8:c956::10
+      - 192.168.122.10
+    ingressVIPs:
+      - 192.168.122.11
+      - fd2e:6f44:5dd8:c956::11
+pullSecret: "{\"auths\":{\"example.com\":{\"auth\":\"authorization value\"}}}"
+`,
+			expectedFound: false,
+			expectedError: `invalid install-config configuration: [Networking.ClusterNetwork: Invalid value: "fd01::/48, 10.128.0.0/14": the first network is IPv6 but the primary IP family of the machine networks is IPv4, the networks of a dual-stack cluster must be listed in the same IP family order, Platform.BareMetal.APIVIPs: Invalid value: []string{"fd2e:6f44:5dd8:c956::10", "192.168.122.10"}: the first VIP must be of the primary IP family of the machine networks, IPv4]`,
+		},
 		{
 			name: "valid configuration for none platform for sno",
 			data: `
@@ -28,6 +28,7 @@ import (
 	"github.com/openshift/installer/pkg/types/defaults"
 	"github.com/openshift/installer/pkg/types/external"
 	"github.com/openshift/installer/pkg/types/none"
+	"github.com/openshift/installer/pkg/types/nutanix"
 	"github.com/openshift/installer/pkg/types/vsphere"
 )
 
@@ -69,7 +70,10 @@ type agentClusterInstallPlatform struct {
 	BareMetal *agentClusterInstallOnPremPlatform `json:"baremetal,omitempty"`
 	// VSphere is the configuration used when installing on vSphere.
 	// +optional
-	VSphere *agentClusterInstallOnPremPlatform `json:"vsphere,omitempty"`
+	VSphere *vsphere.Platform `json:"vsphere,omitempty"`
+	// Nutanix is the configuration used when installing on Nutanix.
+	// +optional
+	Nutanix *nutanix.Platform `json:"nutanix,omitempty"`
 	// External is the configuration used when installing on external cloud provider.
 	// +optional
 	External *agentClusterInstallOnPremExternalPlatform `json:"external,omitempty"`
@@ -202,17 +206,29 @@ func (a *AgentClusterInstall) Generate(dependencies asset.Parents) error {
 			agentClusterInstall.Spec.APIVIP = installConfig.Config.Platform.BareMetal.APIVIPs[0]
 			agentClusterInstall.Spec.IngressVIP = installConfig.Config.Platform.BareMetal.IngressVIPs[0]
 		} else if installConfig.Config.Platform.VSphere != nil {
-			if len(installConfig.Config.Platform.VSphere.APIVIPs) > 1 {
+			if vspherePlatform := vsphereOverrides(installConfig.Config.Platform.VSphere); vspherePlatform != nil {
 				icOverridden = true
 				icOverrides.Platform = &agentClusterInstallPlatform{
-					VSphere: &agentClusterInstallOnPremPlatform{
-						APIVIPs:     installConfig.Config.Platform.VSphere.APIVIPs,
-						IngressVIPs: installConfig.Config.Platform.VSphere.IngressVIPs,
-					},
+					VSphere: vspherePlatform,
 				}
 			}
 			agentClusterInstall.Spec.APIVIP = installConfig.Config.Platform.VSphere.APIVIPs[0]
 			agentClusterInstall.Spec.IngressVIP = installConfig.Config.Platform.VSphere.IngressVIPs[0]
+		} else if installConfig.Config.Platform.Nutanix != nil {
+			// The Prism Central credentials are required by the installer to
+			// generate the cloud credentials secret of the cluster.
+			icOverridden = true
+			icOverrides.Platform = &agentClusterInstallPlatform{
+				Nutanix: &nutanix.Platform{
+					PrismCentral:  installConfig.Config.Platform.Nutanix.PrismCentral,
+					PrismElements: installConfig.Config.Platform.Nutanix.PrismElements,
+					SubnetUUIDs:   installConfig.Config.Platform.Nutanix.SubnetUUIDs,
+					APIVIPs:       installConfig.Config.Platform.Nutanix.APIVIPs,
+					IngressVIPs:   installConfig.Config.Platform.Nutanix.IngressVIPs,
+				},
+			}
+			agentClusterInstall.Spec.APIVIP = installConfig.Config.Platform.Nutanix.APIVIPs[0]
+			agentClusterInstall.Spec.IngressVIP = installConfig.Config.Platform.Nutanix.IngressVIPs[0]
 		} else if installConfig.Config.Platform.External != nil {
 			icOverridden = true
 			icOverrides.Platform = &agentClusterInstallPlatform{
@@ -255,6 +271,37 @@ func (a *AgentClusterInstall) Generate(dependencies asset.Parents) error {
 	return a.finish()
 }
 
+// vsphereOverrides returns the vSphere platform to override in the
+// install-config of the cluster: the VIPs of a dual-stack cluster, and the
+// vCenter with its credentials and failure domains, from which the installer
+// generates the cloud credentials secret and the cloud provider config needed
+// by the storage and the machine management of the cluster. Only the first
+// vCenter is supported, and none is given when its server is not specified.
+// It returns nil when there is nothing to override.
+func vsphereOverrides(platform *vsphere.Platform) *vsphere.Platform {
+	overridden := false
+	overrides := &vsphere.Platform{}
+	if len(platform.APIVIPs) > 1 {
+		overridden = true
+		overrides.APIVIPs = platform.APIVIPs
+		overrides.IngressVIPs = platform.IngressVIPs
+	}
+	if len(platform.VCenters) > 0 && platform.VCenters[0].Server != "" {
+		overridden = true
+		vcenter := platform.VCenters[0]
+		overrides.VCenters = []vsphere.VCenter{vcenter}
+		for _, failureDomain := range platform.FailureDomains {
+			if failureDomain.Server == vcenter.Server {
+				overrides.FailureDomains = append(overrides.FailureDomains, failureDomain)
+			}
+		}
+	}
+	if !overridden {
+		return nil
+	}
+	return overrides
+}
+
 // Files returns the files generated by the asset.
 func (a *AgentClusterInstall) Files() []*asset.File {
 	if a.File != nil {
@@ -20,6 +20,8 @@ import (
 	"github.com/openshift/installer/pkg/asset/mock"
 	"github.com/openshift/installer/pkg/types"
 	externaltype "github.com/openshift/installer/pkg/types/external"
+	"github.com/openshift/installer/pkg/types/nutanix"
+	"github.com/openshift/installer/pkg/types/vsphere"
 )
 
 func TestAgentClusterInstall_Generate(t *testing.T) {
@@ -118,6 +120,62 @@ func TestAgentClusterInstall_Generate(t *testing.T) {
 		installConfigOverrides: `{"platform":{"external":{"platformName":"oci","cloudControllerManager":"External"}}}`,
 	})
 
+	installConfigWVSphereCredentials := getValidOptionalInstallConfig()
+	installConfigWVSphereCredentials.Config.Platform = types.Platform{
+		VSphere: &vsphere.Platform{
+			APIVIPs:     []string{"192.168.122.10"},
+			IngressVIPs: []string{"192.168.122.11"},
+			VCenters: []vsphere.VCenter{{
+				Server:      "vcenter.example.com",
+				Port:        443,
+				Username:    "testUsername",
+				Password:    "testPassword",
+				Datacenters: []string{"testDatacenter"},
+			}},
+			FailureDomains: []vsphere.FailureDomain{{
+				Name:   "testFailureDomain",
+				Region: "testRegion",
+				Zone:   "testZone",
+				Server: "vcenter.example.com",
+				Topology: vsphere.Topology{
+					Datacenter:     "testDatacenter",
+					ComputeCluster: "/testDatacenter/host/testCluster",
+					Datastore:      "/testDatacenter/datastore/testDatastore",
+				},
+			}},
+		},
+	}
+
+	goodVSphereCredentialsACI := getGoodACI()
+	goodVSphereCredentialsACI.Spec.PlatformType = hiveext.VSpherePlatformType
+	goodVSphereCredentialsACI.SetAnnotations(map[string]string{
+		installConfigOverrides: `{"platform":{"vsphere":{"vcenters":[{"server":"vcenter.example.com","port":443,"user":"testUsername","password":"testPassword","datacenters":["testDatacenter"]}],"failureDomains":[{"name":"testFailureDomain","region":"testRegion","zone":"testZone","server":"vcenter.example.com","topology":{"datacenter":"testDatacenter","computeCluster":"/testDatacenter/host/testCluster","datastore":"/testDatacenter/datastore/testDatastore"}}]}}}`,
+	})
+
+	installConfigWNutanixPlatform := getValidOptionalInstallConfig()
+	installConfigWNutanixPlatform.Config.Platform = types.Platform{
+		Nutanix: &nutanix.Platform{
+			PrismCentral: nutanix.PrismCentral{
+				Endpoint: nutanix.PrismEndpoint{Address: "prism-central.example.com", Port: 9440},
+				Username: "testUsername",
+				Password: "testPassword",
+			},
+			PrismElements: []nutanix.PrismElement{{
+				UUID:     "0005b0f1-8f43-a0f2-02b7-3cecef193712",
+				Endpoint: nutanix.PrismEndpoint{Address: "prism-element.example.com", Port: 9440},
+			}},
+			SubnetUUIDs: []string{"c7938dc6-7659-453e-a688-e26020c68e43"},
+			APIVIPs:     []string{"192.168.122.10"},
+			IngressVIPs: []string{"192.168.122.11"},
+		},
+	}
+
+	goodNutanixPlatformACI := getGoodACI()
+	goodNutanixPlatformACI.Spec.PlatformType = hiveext.NutanixPlatformType
+	goodNutanixPlatformACI.SetAnnotations(map[string]string{
+		installConfigOverrides: `{"platform":{"nutanix":{"prismCentral":{"endpoint":{"address":"prism-central.example.com","port":9440},"username":"testUsername","password":"testPassword"},"prismElements":[{"uuid":"0005b0f1-8f43-a0f2-02b7-3cecef193712","endpoint":{"address":"prism-element.example.com","port":9440}}],"apiVIPs":["192.168.122.10"],"ingressVIPs":["192.168.122.11"],"subnetUUIDs":["c7938dc6-7659-453e-a688-e26020c68e43"]}}}`,
+	})
+
 	cases := []struct {
 		name           string
 		dependencies   []asset.Asset
@@ -208,6 +266,20 @@ func TestAgentClusterInstall_Generate(t *testing.T) {
 			},
 			expectedConfig: goodExternalOCIPlatformACI,
 		},
+		{
+			name: "valid configuration vsphere platform with credentials",
+			dependencies: []asset.Asset{
+				installConfigWVSphereCredentials,
+			},
+			expectedConfig: goodVSphereCredentialsACI,
+		},
+		{
+			name: "valid configuration nutanix platform",
+			dependencies: []asset.Asset{
+				installConfigWNutanixPlatform,
+			},
+			expectedConfig: goodNutanixPlatformACI,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -759,7 +831,7 @@ spec:
   sshPublicKey: |
     ssh-rsa AAAAmyKey`,
 			expectedFound: false,
-			expectedError: "invalid PlatformType configured: spec.platformType: Unsupported value: \"aws\": supported values: \"BareMetal\", \"VSphere\", \"None\", \"External\"",
+			expectedError: "invalid PlatformType configured: spec.platformType: Unsupported value: \"aws\": supported values: \"BareMetal\", \"VSphere\", \"None\", \"External\", \"Nutanix\"",
 		},
 	}
 	for _, tc := range cases {
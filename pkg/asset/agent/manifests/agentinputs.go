@@ -0,0 +1,258 @@
+package manifests
+
+import (
+	"encoding/json"
+
+	"github.com/coreos/stream-metadata-go/arch"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	hiveext "github.com/openshift/assisted-service/api/hiveextension/v1beta1"
+	"github.com/openshift/installer/pkg/ipnet"
+	"github.com/openshift/installer/pkg/types"
+	agenttypes "github.com/openshift/installer/pkg/types/agent"
+	"github.com/openshift/installer/pkg/types/baremetal"
+	"github.com/openshift/installer/pkg/types/external"
+	"github.com/openshift/installer/pkg/types/none"
+	"github.com/openshift/installer/pkg/types/vsphere"
+)
+
+// AgentInputs converts the cluster manifests, as generated by the agent
+// installer or written for GitOps ZTP, back into the install-config and the
+// agent-config the agent installer generates them from. SiteConfig manifests
+// are not converted, only the ZTP manifests they are rendered into.
+//
+// The rendezvous IP is the first IP of the NMStateConfigs, which the agent
+// installer uses when there is no agent-config, and the hostnames are the
+// ones of their NMState hostname config, if any. The rendezvous host is a
+// control plane host, the roles of the other hosts are only set when all the
+// hosts are control plane hosts, the hosts are otherwise assigned a role at
+// install time.
+func (m *AgentManifests) AgentInputs() (*types.InstallConfig, *agenttypes.Config, error) {
+	if m.AgentClusterInstall == nil || m.ClusterDeployment == nil || m.InfraEnv == nil || m.PullSecret == nil {
+		return nil, nil, errors.New("the AgentClusterInstall, ClusterDeployment, InfraEnv and pull secret manifests are required")
+	}
+	aci := m.AgentClusterInstall
+
+	icOverrides := agentClusterInstallInstallConfigOverrides{}
+	if overrides, ok := aci.Annotations[installConfigOverrides]; ok {
+		if err := json.Unmarshal([]byte(overrides), &icOverrides); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to unmarshal AgentClusterInstall installConfigOverrides")
+		}
+	}
+
+	architecture := types.Architecture(types.ArchitectureAMD64)
+	if m.InfraEnv.Spec.CpuArchitecture != "" {
+		architecture = types.Architecture(arch.GoArch(m.InfraEnv.Spec.CpuArchitecture))
+	}
+	sshKey := aci.Spec.SSHPublicKey
+	if sshKey == "" {
+		sshKey = m.InfraEnv.Spec.SSHAuthorizedKey
+	}
+
+	installConfig := &types.InstallConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: types.InstallConfigVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.ClusterDeployment.Spec.ClusterName,
+			Namespace: m.ClusterDeployment.Namespace,
+		},
+		BaseDomain:            m.ClusterDeployment.Spec.BaseDomain,
+		PullSecret:            m.GetPullSecretData(),
+		SSHKey:                sshKey,
+		AdditionalTrustBundle: m.InfraEnv.Spec.AdditionalTrustBundle,
+		FIPS:                  icOverrides.FIPS,
+		Capabilities:          icOverrides.Capabilities,
+		CPUPartitioning:       icOverrides.CPUPartitioning,
+		ControlPlane: &types.MachinePool{
+			Name:         "master",
+			Replicas:     pointer.Int64(int64(aci.Spec.ProvisionRequirements.ControlPlaneAgents)),
+			Architecture: architecture,
+		},
+		Compute: []types.MachinePool{
+			{
+				Name:         "worker",
+				Replicas:     pointer.Int64(int64(aci.Spec.ProvisionRequirements.WorkerAgents)),
+				Architecture: architecture,
+			},
+		},
+	}
+
+	if icOverrides.Networking != nil {
+		installConfig.Networking = icOverrides.Networking
+	} else {
+		networking, err := agentClusterInstallNetworking(aci)
+		if err != nil {
+			return nil, nil, err
+		}
+		installConfig.Networking = networking
+	}
+
+	if aci.Spec.Proxy != nil {
+		installConfig.Proxy = &types.Proxy{
+			HTTPProxy:  aci.Spec.Proxy.HTTPProxy,
+			HTTPSProxy: aci.Spec.Proxy.HTTPSProxy,
+			NoProxy:    aci.Spec.Proxy.NoProxy,
+		}
+	}
+
+	platform, err := agentClusterInstallPlatformToInstallConfig(aci, icOverrides.Platform)
+	if err != nil {
+		return nil, nil, err
+	}
+	installConfig.Platform = platform
+
+	agentConfig := &agenttypes.Config{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AgentConfig",
+			APIVersion: agenttypes.AgentConfigVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.ClusterDeployment.Spec.ClusterName,
+			Namespace: m.ClusterDeployment.Namespace,
+		},
+		AdditionalNTPSources: m.InfraEnv.Spec.AdditionalNTPSources,
+	}
+	if len(m.NMStateConfigs) > 0 {
+		rendezvousIP, err := GetNodeZeroIP(nil, m.NMStateConfigs)
+		if err == nil {
+			agentConfig.RendezvousIP = rendezvousIP
+		}
+	}
+	for _, nmStateConfig := range m.NMStateConfigs {
+		host := agenttypes.Host{
+			Hostname:      nmStateHostname(nmStateConfig.Spec.NetConfig.Raw),
+			Interfaces:    nmStateConfig.Spec.Interfaces,
+			NetworkConfig: nmStateConfig.Spec.NetConfig,
+		}
+		if aci.Spec.ProvisionRequirements.WorkerAgents == 0 {
+			host.Role = "master"
+		} else if agentConfig.RendezvousIP != "" {
+			if ip, err := getFirstIP(nmStateConfig.Spec.NetConfig.Raw); err == nil && ip == agentConfig.RendezvousIP {
+				host.Role = "master"
+			}
+		}
+		agentConfig.Hosts = append(agentConfig.Hosts, host)
+	}
+
+	return installConfig, agentConfig, nil
+}
+
+// nmStateHostname returns the hostname of the NMState config, if any.
+func nmStateHostname(raw []byte) string {
+	config := struct {
+		Hostname struct {
+			Config  string `json:"config"`
+			Running string `json:"running"`
+		} `json:"hostname"`
+	}{}
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return ""
+	}
+	if config.Hostname.Config != "" {
+		return config.Hostname.Config
+	}
+	return config.Hostname.Running
+}
+
+// agentClusterInstallNetworking returns the networking of the install-config
+// from the networking of the AgentClusterInstall.
+func agentClusterInstallNetworking(aci *hiveext.AgentClusterInstall) (*types.Networking, error) {
+	networking := &types.Networking{
+		NetworkType: aci.Spec.Networking.NetworkType,
+	}
+	for _, cn := range aci.Spec.Networking.ClusterNetwork {
+		cidr, err := ipnet.ParseCIDR(cn.CIDR)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid clusterNetwork CIDR %s", cn.CIDR)
+		}
+		networking.ClusterNetwork = append(networking.ClusterNetwork, types.ClusterNetworkEntry{
+			CIDR:       *cidr,
+			HostPrefix: cn.HostPrefix,
+		})
+	}
+	for _, sn := range aci.Spec.Networking.ServiceNetwork {
+		cidr, err := ipnet.ParseCIDR(sn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid serviceNetwork CIDR %s", sn)
+		}
+		networking.ServiceNetwork = append(networking.ServiceNetwork, *cidr)
+	}
+	for _, mn := range aci.Spec.Networking.MachineNetwork {
+		cidr, err := ipnet.ParseCIDR(mn.CIDR)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid machineNetwork CIDR %s", mn.CIDR)
+		}
+		networking.MachineNetwork = append(networking.MachineNetwork, types.MachineNetworkEntry{
+			CIDR: *cidr,
+		})
+	}
+	return networking, nil
+}
+
+// agentClusterInstallPlatformToInstallConfig returns the platform of the
+// install-config from the platform type and the VIPs of the
+// AgentClusterInstall, and from the platform of its install-config overrides.
+func agentClusterInstallPlatformToInstallConfig(aci *hiveext.AgentClusterInstall, overrides *agentClusterInstallPlatform) (types.Platform, error) {
+	if overrides == nil {
+		overrides = &agentClusterInstallPlatform{}
+	}
+	apiVIPs, ingressVIPs := aci.Spec.APIVIPs, aci.Spec.IngressVIPs
+	if len(apiVIPs) == 0 && aci.Spec.APIVIP != "" {
+		apiVIPs = []string{aci.Spec.APIVIP}
+	}
+	if len(ingressVIPs) == 0 && aci.Spec.IngressVIP != "" {
+		ingressVIPs = []string{aci.Spec.IngressVIP}
+	}
+
+	platformType := aci.Spec.PlatformType
+	if platformType == "" {
+		// The default of the Agent Rest API.
+		platformType = hiveext.BareMetalPlatformType
+		if aci.Spec.Networking.UserManagedNetworking != nil && *aci.Spec.Networking.UserManagedNetworking {
+			platformType = hiveext.NonePlatformType
+		}
+	}
+
+	switch platformType {
+	case hiveext.BareMetalPlatformType:
+		platform := &baremetal.Platform{APIVIPs: apiVIPs, IngressVIPs: ingressVIPs}
+		if overrides.BareMetal != nil {
+			platform.APIVIPs, platform.IngressVIPs = overrides.BareMetal.APIVIPs, overrides.BareMetal.IngressVIPs
+		}
+		return types.Platform{BareMetal: platform}, nil
+	case hiveext.VSpherePlatformType:
+		platform := &vsphere.Platform{}
+		if overrides.VSphere != nil {
+			platform = overrides.VSphere
+		}
+		if len(platform.APIVIPs) == 0 {
+			platform.APIVIPs, platform.IngressVIPs = apiVIPs, ingressVIPs
+		}
+		return types.Platform{VSphere: platform}, nil
+	case hiveext.NutanixPlatformType:
+		if overrides.Nutanix == nil {
+			return types.Platform{}, errors.New("the Nutanix platform requires its Prism Central and Prism Elements in the install-config overrides of the AgentClusterInstall")
+		}
+		platform := overrides.Nutanix
+		if len(platform.APIVIPs) == 0 {
+			platform.APIVIPs, platform.IngressVIPs = apiVIPs, ingressVIPs
+		}
+		return types.Platform{Nutanix: platform}, nil
+	case hiveext.ExternalPlatformType:
+		platform := &external.Platform{}
+		if aci.Spec.ExternalPlatformSpec != nil {
+			platform.PlatformName = aci.Spec.ExternalPlatformSpec.PlatformName
+		}
+		if overrides.External != nil {
+			platform.CloudControllerManager = overrides.External.CloudControllerManager
+		}
+		return types.Platform{External: platform}, nil
+	case hiveext.NonePlatformType:
+		return types.Platform{None: &none.Platform{}}, nil
+	}
+	return types.Platform{}, errors.Errorf("unsupported platform type %s of the AgentClusterInstall", platformType)
+}
@@ -0,0 +1,187 @@
+package manifests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	aiv1beta1 "github.com/openshift/assisted-service/api/v1beta1"
+	hivev1 "github.com/openshift/hive/apis/hive/v1"
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/agent"
+	"github.com/openshift/installer/pkg/types"
+	agenttypes "github.com/openshift/installer/pkg/types/agent"
+	"github.com/openshift/installer/pkg/types/baremetal"
+	"github.com/openshift/installer/pkg/types/vsphere"
+)
+
+func TestAgentManifests_AgentInputs(t *testing.T) {
+	installConfigWithOverrides := getValidOptionalInstallConfigDualStackDualVIPs()
+	installConfigWithOverrides.Config.FIPS = true
+
+	installConfigWithVSphere := getValidOptionalInstallConfig()
+	installConfigWithVSphere.Config.Platform = types.Platform{
+		VSphere: &vsphere.Platform{
+			APIVIPs:     []string{"192.168.122.10"},
+			IngressVIPs: []string{"192.168.122.11"},
+			VCenters: []vsphere.VCenter{{
+				Server:      "vcenter.example.com",
+				Port:        443,
+				Username:    "testUsername",
+				Password:    "testPassword",
+				Datacenters: []string{"testDatacenter"},
+			}},
+		},
+	}
+
+	cases := []struct {
+		name             string
+		installConfig    *agent.OptionalInstallConfig
+		expectedPlatform types.Platform
+	}{
+		{
+			name:          "baremetal",
+			installConfig: getValidOptionalInstallConfig(),
+			expectedPlatform: types.Platform{
+				BareMetal: &baremetal.Platform{
+					APIVIPs:     []string{"192.168.122.10"},
+					IngressVIPs: []string{"192.168.122.11"},
+				},
+			},
+		},
+		{
+			name:          "install-config overrides",
+			installConfig: installConfigWithOverrides,
+			expectedPlatform: types.Platform{
+				BareMetal: &baremetal.Platform{
+					APIVIPs:     []string{"192.168.122.10", "2001:db8:1111:2222:ffff:ffff:ffff:cafe"},
+					IngressVIPs: []string{"192.168.122.11", "2001:db8:1111:2222:ffff:ffff:ffff:dead"},
+				},
+			},
+		},
+		{
+			name:             "vsphere with credentials",
+			installConfig:    installConfigWithVSphere,
+			expectedPlatform: installConfigWithVSphere.Config.Platform,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			agentConfig := getValidAgentConfig()
+			parents := asset.Parents{}
+			parents.Add(tc.installConfig, agentConfig)
+
+			pullSecret := &AgentPullSecret{}
+			infraEnv := &InfraEnv{}
+			aci := &AgentClusterInstall{}
+			clusterDeployment := &ClusterDeployment{}
+			for _, a := range []asset.WritableAsset{pullSecret, infraEnv, aci, clusterDeployment} {
+				assert.NoError(t, a.Generate(parents))
+			}
+			nmStateConfig := &aiv1beta1.NMStateConfig{
+				Spec: aiv1beta1.NMStateConfigSpec{
+					Interfaces: agentConfig.Config.Hosts[0].Interfaces,
+					NetConfig:  agentConfig.Config.Hosts[0].NetworkConfig,
+				},
+			}
+			agentManifests := &AgentManifests{
+				PullSecret:          pullSecret.Config,
+				InfraEnv:            infraEnv.Config,
+				NMStateConfigs:      []*aiv1beta1.NMStateConfig{nmStateConfig},
+				AgentClusterInstall: aci.Config,
+				ClusterDeployment:   clusterDeployment.Config,
+			}
+
+			installConfig, convertedAgentConfig, err := agentManifests.AgentInputs()
+			assert.NoError(t, err)
+
+			expected := tc.installConfig.Config
+			assert.Equal(t, expected.ObjectMeta, installConfig.ObjectMeta)
+			assert.Equal(t, expected.BaseDomain, installConfig.BaseDomain)
+			assert.JSONEq(t, expected.PullSecret, installConfig.PullSecret)
+			assert.Equal(t, strings.Trim(expected.SSHKey, "|\n\t"), installConfig.SSHKey)
+			assert.Equal(t, expected.FIPS, installConfig.FIPS)
+			assert.Equal(t, expected.Networking, installConfig.Networking)
+			assert.Equal(t, tc.expectedPlatform, installConfig.Platform)
+			assert.Equal(t, *expected.ControlPlane.Replicas, *installConfig.ControlPlane.Replicas)
+			assert.Equal(t, int64(5), *installConfig.Compute[0].Replicas)
+
+			assert.Equal(t, agentConfig.Config.ObjectMeta.Namespace, convertedAgentConfig.ObjectMeta.Namespace)
+			assert.Equal(t, "192.168.122.21", convertedAgentConfig.RendezvousIP)
+			assert.Equal(t, []agenttypes.Host{{
+				Role:          "master",
+				Interfaces:    agentConfig.Config.Hosts[0].Interfaces,
+				NetworkConfig: agentConfig.Config.Hosts[0].NetworkConfig,
+			}}, convertedAgentConfig.Hosts)
+		})
+	}
+}
+
+func TestAgentManifests_AgentInputsMissingManifests(t *testing.T) {
+	_, _, err := (&AgentManifests{AgentClusterInstall: getGoodACI()}).AgentInputs()
+	assert.EqualError(t, err, "the AgentClusterInstall, ClusterDeployment, InfraEnv and pull secret manifests are required")
+}
+
+func TestAgentManifests_AgentInputsHosts(t *testing.T) {
+	netConfig := func(hostname, ip string) aiv1beta1.NetConfig {
+		return aiv1beta1.NetConfig{Raw: unmarshalJSON([]byte(fmt.Sprintf(`
+hostname:
+  config: %s
+interfaces:
+  - name: eth0
+    type: ethernet
+    ipv4:
+      enabled: true
+      address:
+        - ip: %s
+          prefix-length: 24`, hostname, ip)))}
+	}
+	nmStateConfigs := []*aiv1beta1.NMStateConfig{
+		{Spec: aiv1beta1.NMStateConfigSpec{NetConfig: aiv1beta1.NetConfig{Raw: unmarshalJSON([]byte("interfaces: []"))}}},
+		{Spec: aiv1beta1.NMStateConfigSpec{NetConfig: netConfig("control-0.example.org", "192.168.122.2")}},
+		{Spec: aiv1beta1.NMStateConfigSpec{NetConfig: netConfig("worker-0.example.org", "192.168.122.3")}},
+	}
+
+	cases := []struct {
+		name          string
+		workerAgents  int
+		expectedRoles []string
+	}{
+		{
+			name:          "compact cluster",
+			expectedRoles: []string{"master", "master", "master"},
+		},
+		{
+			name:          "workers",
+			workerAgents:  1,
+			expectedRoles: []string{"", "master", ""},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			aci := getGoodACI()
+			aci.Spec.ProvisionRequirements.WorkerAgents = tc.workerAgents
+			agentManifests := &AgentManifests{
+				PullSecret:          &corev1.Secret{StringData: map[string]string{".dockerconfigjson": testSecret}},
+				InfraEnv:            &aiv1beta1.InfraEnv{},
+				NMStateConfigs:      nmStateConfigs,
+				AgentClusterInstall: aci,
+				ClusterDeployment:   &hivev1.ClusterDeployment{},
+			}
+
+			_, agentConfig, err := agentManifests.AgentInputs()
+			assert.NoError(t, err)
+			assert.Equal(t, "192.168.122.2", agentConfig.RendezvousIP)
+			hostnames, roles := []string{}, []string{}
+			for _, host := range agentConfig.Hosts {
+				hostnames = append(hostnames, host.Hostname)
+				roles = append(roles, host.Role)
+			}
+			assert.Equal(t, []string{"", "control-0.example.org", "worker-0.example.org"}, hostnames)
+			assert.Equal(t, tc.expectedRoles, roles)
+		})
+	}
+}
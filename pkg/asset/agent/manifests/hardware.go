@@ -0,0 +1,86 @@
+package manifests
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/openshift/installer/pkg/asset/agent"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/types"
+)
+
+// Topologies of the cluster, which set the hardware requirements of the hosts.
+const (
+	topologySingleNode = "single-node"
+	topologyCompact    = "compact"
+	topologyHA         = "high-availability"
+)
+
+// hardwareRequirements are the minimum requirements of a host, as checked by
+// the agent when the host is discovered.
+type hardwareRequirements struct {
+	cpuCores  int
+	memoryGiB int
+	diskGB    int
+}
+
+// minimumHardware returns the minimum requirements of a host with the role in
+// a cluster of the topology.
+func minimumHardware(topology, role string) hardwareRequirements {
+	switch {
+	case topology == topologySingleNode:
+		return hardwareRequirements{cpuCores: 8, memoryGiB: 16, diskGB: 100}
+	case role == "master":
+		return hardwareRequirements{cpuCores: 4, memoryGiB: 16, diskGB: 100}
+	default:
+		return hardwareRequirements{cpuCores: 2, memoryGiB: 8, diskGB: 100}
+	}
+}
+
+// clusterTopology returns the topology of the cluster of the install-config.
+func clusterTopology(installConfig *types.InstallConfig) string {
+	numMasters, numWorkers := agent.GetReplicaCount(installConfig)
+	switch {
+	case numMasters == 1:
+		return topologySingleNode
+	case numWorkers == 0:
+		return topologyCompact
+	default:
+		return topologyHA
+	}
+}
+
+// validateHostHardware checks the hardware of the hosts of the agent-config
+// which declare it against the minimum requirements of their role in the
+// topology of the cluster, so that undersized hosts are found before the
+// image is booted.
+func validateHostHardware(installConfig *types.InstallConfig, agentConfig *agentconfig.AgentConfig) error {
+	numRequiredMasters, _ := agent.GetReplicaCount(installConfig)
+	topology := clusterTopology(installConfig)
+
+	var allErrs field.ErrorList
+	for i, role := range hostRoles(numRequiredMasters, agentConfig.Config.Hosts) {
+		host := agentConfig.Config.Hosts[i]
+		if host.Hardware == nil {
+			continue
+		}
+		hardwarePath := field.NewPath("Hosts").Index(i).Child("hardware")
+		minimum := minimumHardware(topology, role)
+		detail := fmt.Sprintf("a %s host of a %s cluster requires at least", role, topology)
+		if host.Hardware.CPUCores != 0 && host.Hardware.CPUCores < minimum.cpuCores {
+			allErrs = append(allErrs, field.Invalid(hardwarePath.Child("cpuCores"), host.Hardware.CPUCores, fmt.Sprintf("%s %d CPU cores", detail, minimum.cpuCores)))
+		}
+		if host.Hardware.MemoryGiB != 0 && host.Hardware.MemoryGiB < minimum.memoryGiB {
+			allErrs = append(allErrs, field.Invalid(hardwarePath.Child("memoryGiB"), host.Hardware.MemoryGiB, fmt.Sprintf("%s %d GiB of memory", detail, minimum.memoryGiB)))
+		}
+		if host.Hardware.DiskSizeGB != 0 && host.Hardware.DiskSizeGB < minimum.diskGB {
+			allErrs = append(allErrs, field.Invalid(hardwarePath.Child("diskSizeGB"), host.Hardware.DiskSizeGB, fmt.Sprintf("%s a %d GB installation disk", detail, minimum.diskGB)))
+		}
+	}
+	if len(allErrs) > 0 {
+		return errors.Wrap(allErrs.ToAggregate(), "hosts do not meet the minimum hardware requirements")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/types"
+	agenttypes "github.com/openshift/installer/pkg/types/agent"
+)
+
+func TestValidateHostHardware(t *testing.T) {
+	cases := []struct {
+		name          string
+		masters       int64
+		workers       int64
+		hosts         []agenttypes.Host
+		expectedError string
+	}{
+		{
+			name:    "no declared hardware",
+			masters: 3,
+			workers: 2,
+			hosts:   []agenttypes.Host{{Role: "master"}, {Role: "worker"}},
+		},
+		{
+			name:    "high-availability",
+			masters: 3,
+			workers: 2,
+			hosts: []agenttypes.Host{
+				{Role: "master", Hardware: &agenttypes.HostHardware{CPUCores: 4, MemoryGiB: 16, DiskSizeGB: 120}},
+				{Role: "worker", Hardware: &agenttypes.HostHardware{CPUCores: 2, MemoryGiB: 8, DiskSizeGB: 100}},
+			},
+		},
+		{
+			name:    "undersized master",
+			masters: 3,
+			workers: 2,
+			hosts: []agenttypes.Host{
+				{Hardware: &agenttypes.HostHardware{CPUCores: 2, MemoryGiB: 8}},
+			},
+			expectedError: "hosts do not meet the minimum hardware requirements: [Hosts[0].hardware.cpuCores: Invalid value: 2: a master host of a high-availability cluster requires at least 4 CPU cores, Hosts[0].hardware.memoryGiB: Invalid value: 8: a master host of a high-availability cluster requires at least 16 GiB of memory]",
+		},
+		{
+			name:    "compact",
+			masters: 3,
+			hosts: []agenttypes.Host{
+				{Role: "master", Hardware: &agenttypes.HostHardware{DiskSizeGB: 50}},
+			},
+			expectedError: "hosts do not meet the minimum hardware requirements: Hosts[0].hardware.diskSizeGB: Invalid value: 50: a master host of a compact cluster requires at least a 100 GB installation disk",
+		},
+		{
+			name:    "single-node",
+			masters: 1,
+			hosts: []agenttypes.Host{
+				{Hardware: &agenttypes.HostHardware{CPUCores: 4, MemoryGiB: 16}},
+			},
+			expectedError: "hosts do not meet the minimum hardware requirements: Hosts[0].hardware.cpuCores: Invalid value: 4: a master host of a single-node cluster requires at least 8 CPU cores",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			installConfig := &types.InstallConfig{
+				ControlPlane: &types.MachinePool{Replicas: pointer.Int64Ptr(tc.masters)},
+				Compute:      []types.MachinePool{{Replicas: pointer.Int64Ptr(tc.workers)}},
+			}
+			agentConfig := &agentconfig.AgentConfig{Config: &agenttypes.Config{Hosts: tc.hosts}}
+
+			err := validateHostHardware(installConfig, agentConfig)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package manifests
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/openshift/installer/pkg/asset/agent"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	"github.com/openshift/installer/pkg/types"
+)
+
+// validateIPFamilies checks that the rendezvous IP is of the primary IP
+// family of the machine networks, which the cluster is bootstrapped on, and
+// that the network config of each host configuring IPs configures the primary
+// IP family.
+func validateIPFamilies(installConfig *types.InstallConfig, agentConfig *agentconfig.AgentConfig) error {
+	if installConfig == nil || installConfig.Networking == nil {
+		return nil
+	}
+	primary, _ := agent.MachineNetworkIPFamilies(installConfig)
+	if primary == "" {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if ip := net.ParseIP(agentConfig.Config.RendezvousIP); ip != nil && agent.IPFamily(ip) != primary {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("rendezvousIP"), agentConfig.Config.RendezvousIP,
+			fmt.Sprintf("the rendezvous IP must be of the primary IP family of the machine networks, %s", primary)))
+	}
+
+	for i, host := range agentConfig.Config.Hosts {
+		if host.NetworkConfig.Raw == nil {
+			continue
+		}
+		configured, err := configuredIPFamilies(host.NetworkConfig.Raw)
+		if err != nil || len(configured) == 0 {
+			// An invalid network config is reported by its validation, and
+			// one without any IP configuration leaves the defaults of the
+			// host.
+			continue
+		}
+		if !configured[primary] {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("Hosts").Index(i).Child("networkConfig"), "",
+				fmt.Sprintf("no %s address, DHCP or autoconf is configured on any interface, but it is the primary IP family of the machine networks", primary)))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return errors.Wrap(allErrs.ToAggregate(), "invalid IP families")
+	}
+	return nil
+}
+
+// configuredIPFamilies returns the IP families configured on the interfaces
+// of the NMState network config, with static addresses or dynamically.
+func configuredIPFamilies(nmstateRaw []byte) (map[string]bool, error) {
+	var config nmStateConfig
+	if err := yaml.Unmarshal(nmstateRaw, &config); err != nil {
+		return nil, err
+	}
+	configured := map[string]bool{}
+	for _, intf := range config.Interfaces {
+		if intf.IPV4.DHCP || len(intf.IPV4.Address) > 0 {
+			configured[agent.IPv4] = true
+		}
+		if intf.IPV6.DHCP || intf.IPV6.Autoconf || len(intf.IPV6.Address) > 0 {
+			configured[agent.IPv6] = true
+		}
+	}
+	return configured, nil
+}
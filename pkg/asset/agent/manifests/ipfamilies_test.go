@@ -0,0 +1,113 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/assisted-service/api/v1beta1"
+	"github.com/openshift/installer/pkg/asset/agent/agentconfig"
+	agenttypes "github.com/openshift/installer/pkg/types/agent"
+)
+
+func TestValidateIPFamilies(t *testing.T) {
+	ipv4Static := `
+interfaces:
+  - name: eth0
+    type: ethernet
+    ipv4:
+      enabled: true
+      address:
+        - ip: 10.10.11.20
+          prefix-length: 24`
+	ipv6Static := `
+interfaces:
+  - name: eth0
+    type: ethernet
+    ipv6:
+      enabled: true
+      address:
+        - ip: 2001:db8:5dd8:c956::20
+          prefix-length: 64`
+	ipv6Autoconf := `
+interfaces:
+  - name: eth0
+    type: ethernet
+    ipv4:
+      enabled: true
+      dhcp: true
+    ipv6:
+      enabled: true
+      autoconf: true`
+
+	cases := []struct {
+		name          string
+		dualStack     bool
+		rendezvousIP  string
+		networkConfig string
+		expectedError string
+	}{
+		{
+			name:          "ipv4",
+			rendezvousIP:  "10.10.11.2",
+			networkConfig: ipv4Static,
+		},
+		{
+			name:         "ipv4 host without ip configuration",
+			rendezvousIP: "10.10.11.2",
+			networkConfig: `
+interfaces:
+  - name: eth0
+    type: ethernet
+    state: up`,
+		},
+		{
+			name:          "dual-stack",
+			dualStack:     true,
+			rendezvousIP:  "10.10.11.2",
+			networkConfig: ipv6Autoconf,
+		},
+		{
+			name:          "ipv6 rendezvous ip of dual-stack",
+			dualStack:     true,
+			rendezvousIP:  "2001:db8:5dd8:c956::2",
+			networkConfig: ipv4Static,
+			expectedError: `invalid IP families: rendezvousIP: Invalid value: "2001:db8:5dd8:c956::2": the rendezvous IP must be of the primary IP family of the machine networks, IPv4`,
+		},
+		{
+			name:          "ipv6 only host of dual-stack",
+			dualStack:     true,
+			rendezvousIP:  "10.10.11.2",
+			networkConfig: ipv6Static,
+			expectedError: `invalid IP families: Hosts[0].networkConfig: Invalid value: "": no IPv4 address, DHCP or autoconf is configured on any interface, but it is the primary IP family of the machine networks`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			installConfig := getValidOptionalInstallConfig()
+			if tc.dualStack {
+				installConfig = getValidOptionalInstallConfigDualStack()
+			}
+			agentConfig := &agentconfig.AgentConfig{
+				Config: &agenttypes.Config{
+					RendezvousIP: tc.rendezvousIP,
+					Hosts: []agenttypes.Host{
+						{
+							Hostname: "control-0.example.org",
+							NetworkConfig: v1beta1.NetConfig{
+								Raw: unmarshalJSON([]byte(tc.networkConfig)),
+							},
+						},
+					},
+				},
+			}
+
+			err := validateIPFamilies(installConfig.Config, agentConfig)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
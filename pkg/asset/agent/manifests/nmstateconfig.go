@@ -35,17 +35,24 @@ type NMStateConfig struct {
 	File                *asset.File
 	StaticNetworkConfig []*models.HostStaticNetworkConfig
 	Config              []*aiv1beta1.NMStateConfig
+
+	// netConfigPaths are the paths of the network config of each of the
+	// Config, used to report validation errors.
+	netConfigPaths []*field.Path
 }
 
 type nmStateConfig struct {
 	Interfaces []struct {
 		IPV4 struct {
+			DHCP    bool `yaml:"dhcp,omitempty"`
 			Address []struct {
 				IP string `yaml:"ip,omitempty"`
 			} `yaml:"address,omitempty"`
 		} `yaml:"ipv4,omitempty"`
 		IPV6 struct {
-			Address []struct {
+			DHCP     bool `yaml:"dhcp,omitempty"`
+			Autoconf bool `yaml:"autoconf,omitempty"`
+			Address  []struct {
 				IP string `yaml:"ip,omitempty"`
 			} `yaml:"address,omitempty"`
 		} `yaml:"ipv6,omitempty"`
@@ -77,16 +84,23 @@ func (n *NMStateConfig) Generate(dependencies asset.Parents) error {
 
 	staticNetworkConfig := []*models.HostStaticNetworkConfig{}
 	nmStateConfigs := []*aiv1beta1.NMStateConfig{}
+	netConfigPaths := []*field.Path{}
 	var data string
 	var isNetworkConfigAvailable bool
 
 	if agentConfig.Config != nil {
+		if err := validateIPFamilies(installConfig.Config, agentConfig); err != nil {
+			return err
+		}
 		if len(agentConfig.Config.Hosts) == 0 {
 			return nil
 		}
 		if err := validateHostCount(installConfig.Config, agentConfig); err != nil {
 			return err
 		}
+		if err := validateHostHardware(installConfig.Config, agentConfig); err != nil {
+			return err
+		}
 
 		for i, host := range agentConfig.Config.Hosts {
 			if host.NetworkConfig.Raw != nil {
@@ -117,6 +131,7 @@ func (n *NMStateConfig) Generate(dependencies asset.Parents) error {
 
 				}
 				nmStateConfigs = append(nmStateConfigs, &nmStateConfig)
+				netConfigPaths = append(netConfigPaths, field.NewPath("Hosts").Index(i).Child("NetworkConfig"))
 
 				staticNetworkConfig = append(staticNetworkConfig, &models.HostStaticNetworkConfig{
 					MacInterfaceMap: buildMacInterfaceMap(nmStateConfig),
@@ -139,6 +154,7 @@ func (n *NMStateConfig) Generate(dependencies asset.Parents) error {
 		if isNetworkConfigAvailable {
 			n.Config = nmStateConfigs
 			n.StaticNetworkConfig = staticNetworkConfig
+			n.netConfigPaths = netConfigPaths
 
 			n.File = &asset.File{
 				Filename: nmStateConfigFilename,
@@ -176,6 +192,7 @@ func (n *NMStateConfig) Load(f asset.FileFetcher) (bool, error) {
 
 	var staticNetworkConfig []*models.HostStaticNetworkConfig
 	var nmStateConfigList []*aiv1beta1.NMStateConfig
+	var netConfigPaths []*field.Path
 
 	for i := range yamlList {
 		nmStateConfig := yamlList[i]
@@ -184,9 +201,11 @@ func (n *NMStateConfig) Load(f asset.FileFetcher) (bool, error) {
 			NetworkYaml:     string(nmStateConfig.Spec.NetConfig.Raw),
 		})
 		nmStateConfigList = append(nmStateConfigList, &nmStateConfig)
+		netConfigPaths = append(netConfigPaths, field.NewPath("NMStateConfig").Index(i).Child("Spec", "NetConfig"))
 	}
 
 	n.File, n.StaticNetworkConfig, n.Config = file, staticNetworkConfig, nmStateConfigList
+	n.netConfigPaths = netConfigPaths
 	if err = n.finish(); err != nil {
 		return false, err
 	}
@@ -195,8 +214,12 @@ func (n *NMStateConfig) Load(f asset.FileFetcher) (bool, error) {
 
 func (n *NMStateConfig) finish() error {
 
-	if err := n.validateWithNMStateCtl(); err != nil {
-		return err
+	if errList := n.validateNMStateSchema().ToAggregate(); errList != nil {
+		return errors.Wrapf(errList, "invalid NMStateConfig configuration")
+	}
+
+	if errList := n.validateWithNMStateCtl().ToAggregate(); errList != nil {
+		return errors.Wrapf(errList, "staticNetwork configuration is not valid")
 	}
 
 	if errList := n.validateNMStateConfig().ToAggregate(); errList != nil {
@@ -205,17 +228,43 @@ func (n *NMStateConfig) finish() error {
 	return nil
 }
 
-func (n *NMStateConfig) validateWithNMStateCtl() error {
+func (n *NMStateConfig) validateNMStateSchema() field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, nmStateConfig := range n.Config {
+		allErrs = append(allErrs, validateNMStateSchema(nmStateConfig.Spec.NetConfig.Raw, n.netConfigPath(i))...)
+	}
+
+	return allErrs
+}
+
+func (n *NMStateConfig) validateWithNMStateCtl() field.ErrorList {
 	level := logrus.GetLevel()
 	logrus.SetLevel(logrus.WarnLevel)
 	staticNetworkConfigGenerator := staticnetworkconfig.New(logrus.WithField("pkg", "manifests"), staticnetworkconfig.Config{MaxConcurrentGenerations: 2})
 	defer logrus.SetLevel(level)
 
-	// Validate the network config using nmstatectl
-	if err := staticNetworkConfigGenerator.ValidateStaticConfigParams(context.Background(), n.StaticNetworkConfig); err != nil {
-		return errors.Wrapf(err, "staticNetwork configuration is not valid")
+	allErrs := field.ErrorList{}
+
+	// Validate the network config of each host using nmstatectl
+	for i, hostConfig := range n.StaticNetworkConfig {
+		if err := staticNetworkConfigGenerator.ValidateHostStaticConfigParams(context.Background(), hostConfig); err != nil {
+			name := ""
+			if i < len(n.Config) {
+				name = n.Config[i].Name
+			}
+			allErrs = append(allErrs, field.Invalid(n.netConfigPath(i), name, err.Error()))
+		}
 	}
-	return nil
+	return allErrs
+}
+
+// netConfigPath returns the path of the network config of the i-th host.
+func (n *NMStateConfig) netConfigPath(i int) *field.Path {
+	if i < len(n.netConfigPaths) {
+		return n.netConfigPaths[i]
+	}
+	return field.NewPath("NMStateConfig").Index(i).Child("Spec", "NetConfig")
 }
 
 func (n *NMStateConfig) validateNMStateConfig() field.ErrorList {
@@ -379,31 +428,46 @@ func buildMacInterfaceMap(nmStateConfig aiv1beta1.NMStateConfig) models.MacInter
 	return macInterfaceMap
 }
 
-func validateHostCount(installConfig *types.InstallConfig, agentConfig *agentconfig.AgentConfig) error {
-	numRequiredMasters, numRequiredWorkers := agent.GetReplicaCount(installConfig)
-
+// hostRoles returns the roles of the hosts. The hosts without a role are
+// assigned as masters until there are enough of them, and then as workers.
+func hostRoles(numRequiredMasters int64, hosts []agenttypes.Host) []string {
+	roles := make([]string, len(hosts))
 	numMasters := int64(0)
-	numWorkers := int64(0)
 	// Check for hosts explicitly defined
-	for _, host := range agentConfig.Config.Hosts {
-		switch host.Role {
-		case "master":
+	for i, host := range hosts {
+		roles[i] = host.Role
+		if host.Role == "master" {
 			numMasters++
-		case "worker":
-			numWorkers++
 		}
 	}
 
 	// If role is not defined it will first be assigned as a master
-	for _, host := range agentConfig.Config.Hosts {
-		if host.Role == "" {
+	for i := range hosts {
+		if roles[i] == "" {
 			if numMasters < numRequiredMasters {
+				roles[i] = "master"
 				numMasters++
 			} else {
-				numWorkers++
+				roles[i] = "worker"
 			}
 		}
 	}
+	return roles
+}
+
+func validateHostCount(installConfig *types.InstallConfig, agentConfig *agentconfig.AgentConfig) error {
+	numRequiredMasters, numRequiredWorkers := agent.GetReplicaCount(installConfig)
+
+	numMasters := int64(0)
+	numWorkers := int64(0)
+	for _, role := range hostRoles(numRequiredMasters, agentConfig.Config.Hosts) {
+		switch role {
+		case "master":
+			numMasters++
+		case "worker":
+			numWorkers++
+		}
+	}
 
 	if numMasters != 0 && numMasters < numRequiredMasters {
 		logrus.Warnf("not enough master hosts defined (%v) to support all the configured ControlPlane replicas (%v)", numMasters, numRequiredMasters)
@@ -346,34 +346,31 @@ spec:
 			expectedError:      "staticNetwork configuration is not valid",
 		},
 
-		// This test case currently does not work for libnmstate 2.2.9,
-		// due a regression that will be fixed in https://github.com/nmstate/nmstate/issues/2311
-		// 		{
-		// 			name: "invalid-address-for-type",
-		// 			data: `
-		// metadata:
-		//   name: mynmstateconfig
-		//   namespace: spoke-cluster
-		//   labels:
-		//     cluster0-nmstate-label-name: cluster0-nmstate-label-value
-		// spec:
-		//   config:
-		//     interfaces:
-		//       - name: eth0
-		//         type: ethernet
-		//         state: up
-		//         mac-address: 52:54:01:aa:aa:a1
-		//         ipv6:
-		//           enabled: true
-		//           address:
-		//             - ip: 192.168.122.21
-		//               prefix-length: 24
-		//   interfaces:
-		//     - name: "eth0"
-		//       macAddress: "52:54:01:aa:aa:a1"`,
-		// 			requiresNmstatectl: true,
-		// 			expectedError:      "staticNetwork configuration is not valid",
-		// 		},
+		{
+			name: "invalid-address-for-type",
+			data: `
+metadata:
+  name: mynmstateconfig
+  namespace: spoke-cluster
+  labels:
+    cluster0-nmstate-label-name: cluster0-nmstate-label-value
+spec:
+  config:
+    interfaces:
+      - name: eth0
+        type: ethernet
+        state: up
+        mac-address: 52:54:01:aa:aa:a1
+        ipv6:
+          enabled: true
+          address:
+            - ip: 192.168.122.21
+              prefix-length: 24
+  interfaces:
+    - name: "eth0"
+      macAddress: "52:54:01:aa:aa:a1"`,
+			expectedError: `invalid NMStateConfig configuration: NMStateConfig[0].Spec.NetConfig.interfaces[0].ipv6.address[0].ip: Invalid value: "192.168.122.21": must be an IPv6 address`,
+		},
 
 		{
 			name: "missing-label",
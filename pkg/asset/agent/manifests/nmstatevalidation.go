@@ -0,0 +1,267 @@
+package manifests
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// The keys and values known to the installer, which only warns about the
+// others: they may be supported by a newer nmstate, and nmstatectl validates
+// them.
+var (
+	nmStateTopLevelKeys = []string{"dispatch", "dns-resolver", "hostname", "interfaces", "ovn", "ovs-db", "route-rules", "routes", "version"}
+
+	nmStateInterfaceTypes = []string{
+		"bond", "dummy", "ethernet", "hsr", "infiniband", "ipsec", "ipvlan", "linux-bridge", "loopback",
+		"mac-vlan", "mac-vtap", "macsec", "ovs-bridge", "ovs-interface", "team", "unknown", "veth", "vlan",
+		"vrf", "vxlan", "xfrm",
+	}
+
+	nmStateInterfaceStates = []string{"absent", "down", "ignore", "up"}
+
+	nmStateBondModes = []string{"802.3ad", "active-backup", "balance-alb", "balance-rr", "balance-tlb", "balance-xor", "broadcast"}
+)
+
+// validateNMStateSchema checks the structure of the NMState network config of
+// a single host, so that the most common mistakes are reported with the path
+// of the offending field without requiring nmstatectl. The unknown keys and
+// values are warned about and left to nmstatectl.
+func validateNMStateSchema(raw []byte, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	var state map[string]interface{}
+	if err := k8syaml.Unmarshal(raw, &state); err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(raw), fmt.Sprintf("failed to parse network config: %v", err)))
+	}
+
+	for _, key := range sets.StringKeySet(state).List() {
+		if !sets.New(nmStateTopLevelKeys...).Has(key) {
+			warnNotSupported(fldPath.Child(key), key, nmStateTopLevelKeys)
+		}
+	}
+
+	if value, ok := state["interfaces"]; ok {
+		allErrs = append(allErrs, validateNMStateInterfaces(value, fldPath.Child("interfaces"))...)
+	}
+	if value, ok := state["routes"]; ok {
+		allErrs = append(allErrs, validateNMStateRoutes(value, fldPath.Child("routes"))...)
+	}
+	if value, ok := state["dns-resolver"]; ok {
+		allErrs = append(allErrs, validateNMStateDNSResolver(value, fldPath.Child("dns-resolver"))...)
+	}
+
+	return allErrs
+}
+
+func validateNMStateInterfaces(value interface{}, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	interfaces, ok := value.([]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath, value, "must be a list of interfaces"))
+	}
+
+	for i, item := range interfaces {
+		intfPath := fldPath.Index(i)
+		intf, ok := item.(map[string]interface{})
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(intfPath, item, "must be an interface"))
+			continue
+		}
+
+		if name, _ := intf["name"].(string); name == "" {
+			allErrs = append(allErrs, field.Required(intfPath.Child("name"), "interface name is required"))
+		}
+		if intfType, ok := intf["type"]; ok && !isOneOf(intfType, nmStateInterfaceTypes) {
+			warnNotSupported(intfPath.Child("type"), intfType, nmStateInterfaceTypes)
+		}
+		if state, ok := intf["state"]; ok && !isOneOf(state, nmStateInterfaceStates) {
+			warnNotSupported(intfPath.Child("state"), state, nmStateInterfaceStates)
+		}
+		if mac, ok := intf["mac-address"]; ok {
+			if s, _ := mac.(string); s == "" {
+				allErrs = append(allErrs, field.Invalid(intfPath.Child("mac-address"), mac, "must be a MAC address"))
+			} else if _, err := net.ParseMAC(s); err != nil {
+				allErrs = append(allErrs, field.Invalid(intfPath.Child("mac-address"), mac, err.Error()))
+			}
+		}
+		if ipv4, ok := intf["ipv4"]; ok {
+			allErrs = append(allErrs, validateNMStateIP(ipv4, false, intfPath.Child("ipv4"))...)
+		}
+		if ipv6, ok := intf["ipv6"]; ok {
+			allErrs = append(allErrs, validateNMStateIP(ipv6, true, intfPath.Child("ipv6"))...)
+		}
+		if vlan, ok := intf["vlan"]; ok {
+			allErrs = append(allErrs, validateNMStateVLAN(vlan, intfPath.Child("vlan"))...)
+		}
+		if bond, ok := intf["link-aggregation"].(map[string]interface{}); ok {
+			if mode, ok := bond["mode"]; ok && !isOneOf(mode, nmStateBondModes) {
+				warnNotSupported(intfPath.Child("link-aggregation", "mode"), mode, nmStateBondModes)
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateNMStateIP(value interface{}, ipv6 bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	config, ok := value.(map[string]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath, value, "must be an IP configuration"))
+	}
+	addresses, ok := config["address"]
+	if !ok {
+		return allErrs
+	}
+	addressList, ok := addresses.([]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath.Child("address"), addresses, "must be a list of addresses"))
+	}
+
+	family, maxPrefix := "IPv4", 32
+	if ipv6 {
+		family, maxPrefix = "IPv6", 128
+	}
+	for i, item := range addressList {
+		addrPath := fldPath.Child("address").Index(i)
+		address, ok := item.(map[string]interface{})
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(addrPath, item, "must be an address"))
+			continue
+		}
+
+		ipStr, _ := address["ip"].(string)
+		ip := net.ParseIP(ipStr)
+		switch {
+		case ipStr == "":
+			allErrs = append(allErrs, field.Required(addrPath.Child("ip"), "IP address is required"))
+		case ip == nil:
+			allErrs = append(allErrs, field.Invalid(addrPath.Child("ip"), address["ip"], "must be an IP address"))
+		case (ip.To4() == nil) != ipv6:
+			allErrs = append(allErrs, field.Invalid(addrPath.Child("ip"), ipStr, fmt.Sprintf("must be an %s address", family)))
+		}
+
+		prefix, ok := address["prefix-length"].(float64)
+		if !ok {
+			allErrs = append(allErrs, field.Required(addrPath.Child("prefix-length"), "prefix length is required"))
+		} else if prefix != float64(int(prefix)) || prefix < 0 || int(prefix) > maxPrefix {
+			allErrs = append(allErrs, field.Invalid(addrPath.Child("prefix-length"), prefix, fmt.Sprintf("must be between 0 and %d", maxPrefix)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateNMStateVLAN(value interface{}, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	vlan, ok := value.(map[string]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath, value, "must be a VLAN configuration"))
+	}
+	if baseIface, _ := vlan["base-iface"].(string); baseIface == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("base-iface"), "VLAN base interface is required"))
+	}
+	id, ok := vlan["id"].(float64)
+	if !ok {
+		allErrs = append(allErrs, field.Required(fldPath.Child("id"), "VLAN id is required"))
+	} else if id != float64(int(id)) || id < 0 || id > 4094 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("id"), id, "must be between 0 and 4094"))
+	}
+
+	return allErrs
+}
+
+func validateNMStateRoutes(value interface{}, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	routes, ok := value.(map[string]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath, value, "must be a routes configuration"))
+	}
+	config, ok := routes["config"]
+	if !ok {
+		return allErrs
+	}
+	configList, ok := config.([]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath.Child("config"), config, "must be a list of routes"))
+	}
+
+	for i, item := range configList {
+		routePath := fldPath.Child("config").Index(i)
+		route, ok := item.(map[string]interface{})
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(routePath, item, "must be a route"))
+			continue
+		}
+		// Absent routes are matched against the current ones and may be
+		// partially specified.
+		if state, _ := route["state"].(string); state == "absent" {
+			continue
+		}
+
+		destination, _ := route["destination"].(string)
+		if destination == "" {
+			allErrs = append(allErrs, field.Required(routePath.Child("destination"), "route destination is required"))
+		} else if _, _, err := net.ParseCIDR(destination); err != nil {
+			allErrs = append(allErrs, field.Invalid(routePath.Child("destination"), destination, "must be a CIDR"))
+		}
+		if nextHop, ok := route["next-hop-address"]; ok {
+			if s, _ := nextHop.(string); net.ParseIP(s) == nil {
+				allErrs = append(allErrs, field.Invalid(routePath.Child("next-hop-address"), nextHop, "must be an IP address"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateNMStateDNSResolver(value interface{}, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	resolver, ok := value.(map[string]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath, value, "must be a DNS resolver configuration"))
+	}
+	config, ok := resolver["config"].(map[string]interface{})
+	if !ok {
+		return allErrs
+	}
+	servers, ok := config["server"]
+	if !ok {
+		return allErrs
+	}
+	serverList, ok := servers.([]interface{})
+	if !ok {
+		return append(allErrs, field.Invalid(fldPath.Child("config", "server"), servers, "must be a list of IP addresses"))
+	}
+
+	for i, server := range serverList {
+		// IPv6 link-local servers are scoped with the %<interface> suffix.
+		s, _ := server.(string)
+		if net.ParseIP(strings.SplitN(s, "%", 2)[0]) == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("config", "server").Index(i), server, "must be an IP address"))
+		}
+	}
+
+	return allErrs
+}
+
+// warnNotSupported warns about a value unknown to the installer.
+func warnNotSupported(fldPath *field.Path, value interface{}, known []string) {
+	logrus.Warnf("%s: unknown value %v, expected one of %s, left to nmstatectl to validate", fldPath, value, strings.Join(known, ", "))
+}
+
+func isOneOf(value interface{}, allowed []string) bool {
+	s, ok := value.(string)
+	return ok && sets.New(allowed...).Has(s)
+}
@@ -0,0 +1,153 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateNMStateSchema(t *testing.T) {
+	cases := []struct {
+		name            string
+		config          string
+		expectedError   string
+		expectedWarning string
+	}{
+		{
+			name:   "valid",
+			config: rawNMStateConfig,
+		},
+		{
+			name: "valid-vlan-bond",
+			config: `
+interfaces:
+  - name: bond0
+    type: bond
+    state: up
+    link-aggregation:
+      mode: active-backup
+      port:
+        - eth0
+        - eth1
+  - name: bond0.100
+    type: vlan
+    state: up
+    vlan:
+      base-iface: bond0
+      id: 100
+    ipv6:
+      enabled: true
+      address:
+        - ip: 2001:db8::10
+          prefix-length: 64
+dns-resolver:
+  config:
+    server:
+      - fe80::1%bond0.100`,
+		},
+		{
+			name: "unknown-top-level-key",
+			config: `
+interface:
+  - name: eth0`,
+			expectedWarning: "Hosts[0].NetworkConfig.interface: unknown value interface, expected one of dispatch, dns-resolver, hostname, interfaces, ovn, ovs-db, route-rules, routes, version, left to nmstatectl to validate",
+		},
+		{
+			name: "missing-interface-name",
+			config: `
+interfaces:
+  - type: ethernet`,
+			expectedError: "Hosts[0].NetworkConfig.interfaces[0].name: Required value: interface name is required",
+		},
+		{
+			name: "unknown-interface-type",
+			config: `
+interfaces:
+  - name: eth0
+    type: ethernets`,
+			expectedWarning: "Hosts[0].NetworkConfig.interfaces[0].type: unknown value ethernets, expected one of",
+		},
+		{
+			name: "invalid-mac-address",
+			config: `
+interfaces:
+  - name: eth0
+    mac-address: 52:54:01:aa:aa`,
+			expectedError: `Hosts[0].NetworkConfig.interfaces[0].mac-address: Invalid value: "52:54:01:aa:aa"`,
+		},
+		{
+			name: "ipv4-address-for-ipv6",
+			config: `
+interfaces:
+  - name: eth0
+    ipv6:
+      enabled: true
+      address:
+        - ip: 192.168.122.21
+          prefix-length: 24`,
+			expectedError: `Hosts[0].NetworkConfig.interfaces[0].ipv6.address[0].ip: Invalid value: "192.168.122.21": must be an IPv6 address`,
+		},
+		{
+			name: "invalid-prefix-length",
+			config: `
+interfaces:
+  - name: eth0
+    ipv4:
+      enabled: true
+      address:
+        - ip: 192.168.122.21
+          prefix-length: 33`,
+			expectedError: "Hosts[0].NetworkConfig.interfaces[0].ipv4.address[0].prefix-length: Invalid value: 33: must be between 0 and 32",
+		},
+		{
+			name: "vlan-id-out-of-range",
+			config: `
+interfaces:
+  - name: eth0.5000
+    type: vlan
+    vlan:
+      base-iface: eth0
+      id: 5000`,
+			expectedError: "Hosts[0].NetworkConfig.interfaces[0].vlan.id: Invalid value: 5000: must be between 0 and 4094",
+		},
+		{
+			name: "invalid-route-destination",
+			config: `
+routes:
+  config:
+    - destination: 0.0.0.0
+      next-hop-address: 192.168.122.1`,
+			expectedError: `Hosts[0].NetworkConfig.routes.config[0].destination: Invalid value: "0.0.0.0": must be a CIDR`,
+		},
+		{
+			name: "invalid-dns-server",
+			config: `
+dns-resolver:
+  config:
+    server:
+      - dns.example.com`,
+			expectedError: `Hosts[0].NetworkConfig.dns-resolver.config.server[0]: Invalid value: "dns.example.com": must be an IP address`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := logrustest.NewGlobal()
+			defer hook.Reset()
+			err := validateNMStateSchema([]byte(tc.config), field.NewPath("Hosts").Index(0).Child("NetworkConfig")).ToAggregate()
+			if tc.expectedWarning == "" {
+				assert.Empty(t, hook.AllEntries())
+			} else if assert.Len(t, hook.AllEntries(), 1) {
+				assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+				assert.Contains(t, hook.LastEntry().Message, tc.expectedWarning)
+			}
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
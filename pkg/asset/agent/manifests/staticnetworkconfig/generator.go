@@ -37,6 +37,7 @@ type StaticNetworkConfig interface {
 	GenerateStaticNetworkConfigData(ctx context.Context, hostsYAMLS string) ([]StaticNetworkConfigData, error)
 	FormatStaticNetworkConfigForDB(staticNetworkConfig []*models.HostStaticNetworkConfig) (string, error)
 	ValidateStaticConfigParams(ctx context.Context, staticNetworkConfig []*models.HostStaticNetworkConfig) error
+	ValidateHostStaticConfigParams(ctx context.Context, hostConfig *models.HostStaticNetworkConfig) error
 }
 
 type staticNetworkConfigGenerator struct {
@@ -218,15 +219,24 @@ func (s *staticNetworkConfigGenerator) formatNMConnection(nmConnection string) (
 func (s *staticNetworkConfigGenerator) ValidateStaticConfigParams(ctx context.Context, staticNetworkConfig []*models.HostStaticNetworkConfig) error {
 	var err *multierror.Error
 	for i, hostConfig := range staticNetworkConfig {
-		err = multierror.Append(err, s.validateMacInterfaceName(i, hostConfig.MacInterfaceMap))
-		if validateErr := s.validateNMStateYaml(ctx, hostConfig.NetworkYaml); validateErr != nil {
-			err = multierror.Append(err, fmt.Errorf("failed to validate network yaml for host %d, %w", i, validateErr))
+		if validateErr := s.ValidateHostStaticConfigParams(ctx, hostConfig); validateErr != nil {
+			err = multierror.Append(err, fmt.Errorf("invalid static network config for host %d, %w", i, validateErr))
 		}
 	}
 	return err.ErrorOrNil()
 }
 
-func (s *staticNetworkConfigGenerator) validateMacInterfaceName(hostIdx int, macInterfaceMap models.MacInterfaceMap) error {
+// ValidateHostStaticConfigParams validates the NMState data of a single host.
+func (s *staticNetworkConfigGenerator) ValidateHostStaticConfigParams(ctx context.Context, hostConfig *models.HostStaticNetworkConfig) error {
+	var err *multierror.Error
+	err = multierror.Append(err, s.validateMacInterfaceName(hostConfig.MacInterfaceMap))
+	if validateErr := s.validateNMStateYaml(ctx, hostConfig.NetworkYaml); validateErr != nil {
+		err = multierror.Append(err, fmt.Errorf("failed to validate network yaml, %w", validateErr))
+	}
+	return err.ErrorOrNil()
+}
+
+func (s *staticNetworkConfigGenerator) validateMacInterfaceName(macInterfaceMap models.MacInterfaceMap) error {
 	interfaceCheck := make(map[string]struct{}, len(macInterfaceMap))
 	macCheck := make(map[string]struct{}, len(macInterfaceMap))
 	for _, macInterface := range macInterfaceMap {
@@ -234,7 +244,7 @@ func (s *staticNetworkConfigGenerator) validateMacInterfaceName(hostIdx int, mac
 		macCheck[macInterface.MacAddress] = struct{}{}
 	}
 	if len(interfaceCheck) < len(macInterfaceMap) || len(macCheck) < len(macInterfaceMap) {
-		return fmt.Errorf("MACs and Interfaces for host %d must be unique", hostIdx)
+		return errors.New("MACs and Interfaces must be unique")
 	}
 	return nil
 }
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/validation"
+)
+
+// IP families of the networks of the cluster.
+const (
+	IPv4 = "IPv4"
+	IPv6 = "IPv6"
+)
+
+// IPFamily returns the IP family of the IP.
+func IPFamily(ip net.IP) string {
+	if ip.To4() != nil {
+		return IPv4
+	}
+	return IPv6
+}
+
+// MachineNetworkIPFamilies returns the primary IP family of the machine
+// networks, the one of the first machine network, and whether they are dual
+// stack. The primary IP family is empty when there is no machine network.
+func MachineNetworkIPFamilies(installConfig *types.InstallConfig) (primary string, dualStack bool) {
+	families := map[string]bool{}
+	for i, network := range installConfig.Networking.MachineNetwork {
+		family := IPFamily(network.CIDR.IP)
+		if i == 0 {
+			primary = family
+		}
+		families[family] = true
+	}
+	return primary, len(families) == 2
+}
+
+// validateIPFamilies checks that the networks of a dual-stack cluster list
+// the primary IP family of the machine networks first, as the VIPs do, and
+// that the VIPs are in the machine networks, where the hosts serve them.
+func (a *OptionalInstallConfig) validateIPFamilies(installConfig *types.InstallConfig) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if installConfig.Networking == nil {
+		return allErrs
+	}
+	primary, dualStack := MachineNetworkIPFamilies(installConfig)
+	if primary == "" {
+		return allErrs
+	}
+
+	if dualStack {
+		clusterNetworks := []string{}
+		for _, network := range installConfig.Networking.ClusterNetwork {
+			clusterNetworks = append(clusterNetworks, network.CIDR.String())
+		}
+		serviceNetworks := []string{}
+		for _, network := range installConfig.Networking.ServiceNetwork {
+			serviceNetworks = append(serviceNetworks, network.String())
+		}
+		for _, networks := range []struct {
+			path  *field.Path
+			cidrs []string
+		}{
+			{path: field.NewPath("Networking", "ClusterNetwork"), cidrs: clusterNetworks},
+			{path: field.NewPath("Networking", "ServiceNetwork"), cidrs: serviceNetworks},
+		} {
+			if len(networks.cidrs) == 0 {
+				continue
+			}
+			ip, _, err := net.ParseCIDR(networks.cidrs[0])
+			if err != nil {
+				continue
+			}
+			if family := IPFamily(ip); family != primary {
+				allErrs = append(allErrs, field.Invalid(networks.path, strings.Join(networks.cidrs, ", "),
+					fmt.Sprintf("the first network is %s but the primary IP family of the machine networks is %s, the networks of a dual-stack cluster must be listed in the same IP family order", family, primary)))
+			}
+		}
+	}
+
+	var apiVIPs, ingressVIPs []string
+	var platformPath *field.Path
+	vipsInMachineNetwork := false
+	switch {
+	case installConfig.Platform.BareMetal != nil:
+		apiVIPs, ingressVIPs = installConfig.Platform.BareMetal.APIVIPs, installConfig.Platform.BareMetal.IngressVIPs
+		platformPath = field.NewPath("Platform", "BareMetal")
+	case installConfig.Platform.VSphere != nil:
+		apiVIPs, ingressVIPs = installConfig.Platform.VSphere.APIVIPs, installConfig.Platform.VSphere.IngressVIPs
+		platformPath = field.NewPath("Platform", "VSphere")
+		// The VIPs of the baremetal platform are already checked to be in
+		// the machine networks, but not the ones of vSphere and Nutanix,
+		// which the hosts serve unless the load balancer is user managed.
+		loadBalancer := installConfig.Platform.VSphere.LoadBalancer
+		vipsInMachineNetwork = loadBalancer == nil || loadBalancer.Type != configv1.LoadBalancerTypeUserManaged
+	case installConfig.Platform.Nutanix != nil:
+		apiVIPs, ingressVIPs = installConfig.Platform.Nutanix.APIVIPs, installConfig.Platform.Nutanix.IngressVIPs
+		platformPath = field.NewPath("Platform", "Nutanix")
+		loadBalancer := installConfig.Platform.Nutanix.LoadBalancer
+		vipsInMachineNetwork = loadBalancer == nil || loadBalancer.Type != configv1.LoadBalancerTypeUserManaged
+	default:
+		return allErrs
+	}
+	for _, vips := range []struct {
+		path *field.Path
+		ips  []string
+	}{
+		{path: platformPath.Child("APIVIPs"), ips: apiVIPs},
+		{path: platformPath.Child("IngressVIPs"), ips: ingressVIPs},
+	} {
+		if dualStack && len(vips.ips) == 2 {
+			if ip := net.ParseIP(vips.ips[0]); ip != nil && IPFamily(ip) != primary {
+				allErrs = append(allErrs, field.Invalid(vips.path, vips.ips,
+					fmt.Sprintf("the first VIP must be of the primary IP family of the machine networks, %s", primary)))
+			}
+		}
+		if vipsInMachineNetwork {
+			for _, vip := range vips.ips {
+				if err := validation.ValidateIPinMachineCIDR(vip, installConfig.Networking); err != nil {
+					allErrs = append(allErrs, field.Invalid(vips.path, vip, err.Error()))
+				}
+			}
+		}
+	}
+
+	return allErrs
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/statecrypt"
 )
 
 const (
@@ -15,6 +17,9 @@ const (
 	ClusterCreationError = "failed to create cluster"
 	// InstallConfigError wraps all configuration errors in one single error
 	InstallConfigError = "failed to create install config"
+	// PreflightCheckError wraps quota and permission check failures, which are
+	// detected before any infrastructure is created
+	PreflightCheckError = "failed preflight checks"
 )
 
 // Asset used to install OpenShift.
@@ -42,6 +47,17 @@ type WritableAsset interface {
 	Load(FileFetcher) (found bool, err error)
 }
 
+// InteractiveAsset is an Asset that may prompt the user, itself or through its
+// dependencies. The store fetches the dependencies of an interactive asset one
+// at a time, and never generates two interactive assets at once, so that
+// prompts are never interleaved.
+type InteractiveAsset interface {
+	Asset
+
+	// Interactive marks the asset as interactive.
+	Interactive()
+}
+
 // File is a file for an Asset.
 type File struct {
 	// Filename is the name of the file.
@@ -58,7 +74,14 @@ func PersistToFile(asset WritableAsset, directory string) error {
 		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
 			return errors.Wrap(err, "failed to create dir")
 		}
-		if err := os.WriteFile(path, f.Data, 0o640); err != nil { //nolint:gosec // no sensitive info
+		data := f.Data
+		if statecrypt.IsSensitive(f.Filename) {
+			var err error
+			if data, err = statecrypt.Encrypt(data); err != nil {
+				return errors.Wrapf(err, "failed to encrypt %s", f.Filename)
+			}
+		}
+		if err := os.WriteFile(path, data, 0o640); err != nil { //nolint:gosec // no sensitive info
 			return errors.Wrap(err, "failed to write file")
 		}
 	}
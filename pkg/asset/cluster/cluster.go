@@ -18,6 +18,7 @@ import (
 	"github.com/openshift/installer/pkg/asset/installconfig"
 	"github.com/openshift/installer/pkg/asset/password"
 	"github.com/openshift/installer/pkg/asset/quota"
+	"github.com/openshift/installer/pkg/metrics/progress"
 	"github.com/openshift/installer/pkg/metrics/timer"
 	"github.com/openshift/installer/pkg/terraform"
 	platformstages "github.com/openshift/installer/pkg/terraform/stages/platform"
@@ -29,6 +30,11 @@ import (
 var (
 	// InstallDir is the directory containing install assets.
 	InstallDir string
+
+	// Resume makes the Cluster asset continue the provisioning of a previous,
+	// failed attempt: stages which completed are skipped and the state of the
+	// stage which failed is reused.
+	Resume bool
 )
 
 // Cluster uses the terraform executable to launch a cluster
@@ -87,6 +93,7 @@ func (c *Cluster) Generate(parents asset.Parents) (err error) {
 	}
 
 	platform := installConfig.Config.Platform.Name()
+	timer.SetPlatform(platform)
 
 	if azure := installConfig.Config.Platform.Azure; azure != nil && azure.CloudName == typesazure.StackCloud {
 		platform = typesazure.StackTerraformName
@@ -95,6 +102,12 @@ func (c *Cluster) Generate(parents asset.Parents) (err error) {
 	stages := platformstages.StagesForPlatform(platform)
 
 	terraformDir := filepath.Join(InstallDir, "terraform")
+	if Resume {
+		// An interrupted attempt may not have cleaned up after itself.
+		if err := os.RemoveAll(terraformDir); err != nil {
+			return errors.Wrap(err, "could not remove the terraform directory")
+		}
+	}
 	if err := os.Mkdir(terraformDir, 0777); err != nil {
 		return errors.Wrap(err, "could not create the terraform directory")
 	}
@@ -108,6 +121,8 @@ func (c *Cluster) Generate(parents asset.Parents) (err error) {
 	terraform.UnpackTerraform(terraformDirPath, stages)
 
 	logrus.Infof("Creating infrastructure resources...")
+	timer.StartTimer("Infrastructure")
+	defer timer.StopTimer("Infrastructure")
 	switch platform {
 	case typesaws.Name:
 		if err := aws.PreTerraform(context.TODO(), clusterID.InfraID, installConfig); err != nil {
@@ -129,6 +144,19 @@ func (c *Cluster) Generate(parents asset.Parents) (err error) {
 	}
 
 	for _, stage := range stages {
+		if Resume {
+			state, outputs, err := completedStage(stage)
+			if err != nil {
+				return err
+			}
+			if outputs != nil {
+				logrus.Infof("Skipping stage %q completed by a previous attempt", stage.Name())
+				tfvarsFiles = append(tfvarsFiles, outputs)
+				c.FileList = append(c.FileList, state, outputs)
+				continue
+			}
+		}
+
 		outputs, err := c.applyStage(platform, stage, terraformDirPath, tfvarsFiles)
 		if err != nil {
 			return errors.Wrapf(err, "failure applying terraform for %q stage", stage.Name())
@@ -137,6 +165,7 @@ func (c *Cluster) Generate(parents asset.Parents) (err error) {
 		c.FileList = append(c.FileList, outputs)
 	}
 
+	progress.Emit(progress.InfrastructureProvisioned, platform)
 	return nil
 }
 
@@ -148,6 +177,10 @@ func (c *Cluster) Files() []*asset.File {
 // Load returns error if the tfstate file is already on-disk, because we want to
 // prevent user from accidentally re-launching the cluster.
 func (c *Cluster) Load(f asset.FileFetcher) (found bool, err error) {
+	if Resume {
+		return false, nil
+	}
+
 	matches, err := filepath.Glob("terraform(.*)?.tfstate")
 	if err != nil {
 		return true, err
@@ -167,6 +200,12 @@ func (c *Cluster) applyStage(platform string, stage terraform.Stage, terraformDi
 	}
 	defer os.RemoveAll(tmpDir)
 
+	if Resume {
+		if err := restoreStageState(stage, tmpDir); err != nil {
+			return nil, err
+		}
+	}
+
 	var extraOpts []tfexec.ApplyOption
 	for _, file := range tfvarsFiles {
 		if err := os.WriteFile(filepath.Join(tmpDir, file.Filename), file.Data, 0o600); err != nil {
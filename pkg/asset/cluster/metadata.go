@@ -149,3 +149,16 @@ func LoadMetadata(dir string) (*types.ClusterMetadata, error) {
 
 	return metadata, err
 }
+
+// SaveMetadata writes the cluster metadata to an asset directory, e.g. when
+// it was reconstructed to destroy a cluster whose metadata was lost.
+func SaveMetadata(dir string, metadata *types.ClusterMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.Wrap(err, "failed to Marshal ClusterMetadata")
+	}
+	if err := os.WriteFile(filepath.Join(dir, metadataFileName), data, 0o640); err != nil { //nolint:gosec // no sensitive info
+		return errors.Wrap(err, "failed to write the metadata")
+	}
+	return nil
+}
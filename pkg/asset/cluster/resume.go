@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/terraform"
+)
+
+// completedStage returns the state and outputs files which a previous attempt
+// left in InstallDir for the stage. The outputs file is only written once the
+// stage has been applied successfully, so it serves as the checkpoint marker;
+// nil files are returned when either of them is missing.
+func completedStage(stage terraform.Stage) (state *asset.File, outputs *asset.File, err error) {
+	files := make([]*asset.File, 0, 2)
+	for _, filename := range []string{stage.StateFilename(), stage.OutputsFilename()} {
+		data, err := os.ReadFile(filepath.Join(InstallDir, filename))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil, nil
+			}
+			return nil, nil, errors.Wrapf(err, "failed to read %s", filename)
+		}
+		files = append(files, &asset.File{Filename: filename, Data: data})
+	}
+	return files[0], files[1], nil
+}
+
+// restoreStageState copies the state file which a previous attempt left in
+// InstallDir for the stage into dir, so that terraform picks up the resources
+// already created instead of creating them again.
+func restoreStageState(stage terraform.Stage, dir string) error {
+	data, err := os.ReadFile(filepath.Join(InstallDir, stage.StateFilename()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to read %s", stage.StateFilename())
+	}
+	return os.WriteFile(filepath.Join(dir, terraform.StateFilename), data, 0o600)
+}
@@ -0,0 +1,65 @@
+package cluster
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/terraform"
+	"github.com/openshift/installer/pkg/terraform/stages"
+)
+
+func TestCompletedStage(t *testing.T) {
+	stage := stages.NewStage("aws", "network", nil)
+	cases := []struct {
+		name      string
+		files     []string
+		completed bool
+	}{
+		{
+			name: "not started",
+		},
+		{
+			name:  "failed",
+			files: []string{stage.StateFilename()},
+		},
+		{
+			name:      "completed",
+			files:     []string{stage.StateFilename(), stage.OutputsFilename()},
+			completed: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			InstallDir = t.TempDir()
+			for _, f := range tc.files {
+				assert.NoError(t, os.WriteFile(filepath.Join(InstallDir, f), []byte(f), 0o600))
+			}
+			state, outputs, err := completedStage(stage)
+			assert.NoError(t, err)
+			if !tc.completed {
+				assert.Nil(t, outputs)
+				return
+			}
+			assert.Equal(t, stage.StateFilename(), state.Filename)
+			assert.Equal(t, []byte(stage.OutputsFilename()), outputs.Data)
+		})
+	}
+}
+
+func TestRestoreStageState(t *testing.T) {
+	stage := stages.NewStage("aws", "network", nil)
+	InstallDir = t.TempDir()
+	dir := t.TempDir()
+
+	assert.NoError(t, restoreStageState(stage, dir))
+	assert.NoFileExists(t, filepath.Join(dir, terraform.StateFilename))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(InstallDir, stage.StateFilename()), []byte("state"), 0o600))
+	assert.NoError(t, restoreStageState(stage, dir))
+	data, err := os.ReadFile(filepath.Join(dir, terraform.StateFilename))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("state"), data)
+}
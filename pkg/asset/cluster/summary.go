@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/alibabacloud"
+	"github.com/openshift/installer/pkg/types/aws"
+	"github.com/openshift/installer/pkg/types/azure"
+	"github.com/openshift/installer/pkg/types/gcp"
+	"github.com/openshift/installer/pkg/types/ibmcloud"
+	"github.com/openshift/installer/pkg/types/powervs"
+)
+
+// InfrastructureSummary describes the infrastructure created for a cluster.
+type InfrastructureSummary struct {
+	Platform string
+	// BootstrapMachines and ControlPlaneMachines are created by the installer,
+	// ComputeMachines by the machine API once the cluster is up.
+	BootstrapMachines    int
+	ControlPlaneMachines int64
+	ComputeMachines      int64
+	// LoadBalancers and DNSRecords only count the API endpoints provisioned
+	// by the installer, the ingress ones are managed by the cluster.
+	LoadBalancers int
+	DNSRecords    int
+}
+
+// platformsWithAPILoadBalancers are the platforms on which the installer
+// provisions load balancers and DNS records for the API.
+var platformsWithAPILoadBalancers = map[string]bool{
+	alibabacloud.Name: true,
+	aws.Name:          true,
+	azure.Name:        true,
+	gcp.Name:          true,
+	ibmcloud.Name:     true,
+	powervs.Name:      true,
+}
+
+// SummarizeInfrastructure returns an estimate of the infrastructure created
+// for the install config, without calling any provisioning API.
+func SummarizeInfrastructure(config *types.InstallConfig) *InfrastructureSummary {
+	summary := &InfrastructureSummary{
+		Platform:          config.Platform.Name(),
+		BootstrapMachines: 1,
+	}
+	if config.ControlPlane != nil && config.ControlPlane.Replicas != nil {
+		summary.ControlPlaneMachines = *config.ControlPlane.Replicas
+	}
+	for _, pool := range config.Compute {
+		if pool.Replicas != nil {
+			summary.ComputeMachines += *pool.Replicas
+		}
+	}
+
+	if platformsWithAPILoadBalancers[summary.Platform] {
+		// An internal load balancer and the api and api-int records in the
+		// private zone, plus an external load balancer and a public api
+		// record when the cluster is published to the Internet.
+		summary.LoadBalancers = 1
+		summary.DNSRecords = 2
+		if config.Publish != types.InternalPublishingStrategy {
+			summary.LoadBalancers++
+			summary.DNSRecords++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/aws"
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+func TestSummarizeInfrastructure(t *testing.T) {
+	cases := []struct {
+		name     string
+		platform types.Platform
+		publish  types.PublishingStrategy
+		expected InfrastructureSummary
+	}{
+		{
+			name:     "aws external",
+			platform: types.Platform{AWS: &aws.Platform{}},
+			publish:  types.ExternalPublishingStrategy,
+			expected: InfrastructureSummary{
+				Platform:             "aws",
+				BootstrapMachines:    1,
+				ControlPlaneMachines: 3,
+				ComputeMachines:      5,
+				LoadBalancers:        2,
+				DNSRecords:           3,
+			},
+		},
+		{
+			name:     "aws internal",
+			platform: types.Platform{AWS: &aws.Platform{}},
+			publish:  types.InternalPublishingStrategy,
+			expected: InfrastructureSummary{
+				Platform:             "aws",
+				BootstrapMachines:    1,
+				ControlPlaneMachines: 3,
+				ComputeMachines:      5,
+				LoadBalancers:        1,
+				DNSRecords:           2,
+			},
+		},
+		{
+			name:     "baremetal",
+			platform: types.Platform{BareMetal: &baremetal.Platform{}},
+			publish:  types.ExternalPublishingStrategy,
+			expected: InfrastructureSummary{
+				Platform:             "baremetal",
+				BootstrapMachines:    1,
+				ControlPlaneMachines: 3,
+				ComputeMachines:      5,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &types.InstallConfig{
+				Platform:     tc.platform,
+				Publish:      tc.publish,
+				ControlPlane: &types.MachinePool{Replicas: pointer.Int64(3)},
+				Compute: []types.MachinePool{
+					{Name: "worker", Replicas: pointer.Int64(3)},
+					{Name: "infra", Replicas: pointer.Int64(2)},
+				},
+			}
+			assert.Equal(t, &tc.expected, SummarizeInfrastructure(config))
+		})
+	}
+}
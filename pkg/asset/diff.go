@@ -0,0 +1,43 @@
+package asset
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/diff"
+	"github.com/pkg/errors"
+)
+
+// DiffFiles writes a unified diff from the contents of the files in the
+// directory to the given files to w, and returns whether any of them differ.
+// Files missing from the directory are diffed against /dev/null. Files in the
+// directory which are not among the given files are ignored.
+func DiffFiles(w io.Writer, directory string, files []*File) (bool, error) {
+	sorted := make([]*File, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Filename < sorted[j].Filename })
+
+	changed := false
+	for _, f := range sorted {
+		fromFile := "a/" + f.Filename
+		onDisk, err := os.ReadFile(filepath.Join(directory, f.Filename))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return changed, errors.Wrapf(err, "failed to read %s", f.Filename)
+			}
+			fromFile = os.DevNull
+		}
+		if err == nil && bytes.Equal(onDisk, f.Data) {
+			continue
+		}
+		changed = true
+
+		if err := diff.Text(fromFile, "b/"+f.Filename, onDisk, f.Data, w); err != nil {
+			return changed, errors.Wrapf(err, "failed to diff %s", f.Filename)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,68 @@
+package asset
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFiles(t *testing.T) {
+	cases := []struct {
+		name     string
+		onDisk   map[string]string
+		files    []*File
+		changed  bool
+		expected string
+	}{
+		{
+			name:   "identical",
+			onDisk: map[string]string{"file1": "a\n"},
+			files:  []*File{{Filename: "file1", Data: []byte("a\n")}},
+		},
+		{
+			name:    "modified",
+			onDisk:  map[string]string{"dir/file1": "a\nb\n"},
+			files:   []*File{{Filename: "dir/file1", Data: []byte("a\nc\n")}},
+			changed: true,
+			expected: `--- a/dir/file1
++++ b/dir/file1
+@@ -1,2 +1,2 @@
+ a
+-b
++c
+`,
+		},
+		{
+			name:    "missing on disk",
+			files:   []*File{{Filename: "file1", Data: []byte("a\n")}},
+			changed: true,
+			expected: `--- /dev/null
++++ b/file1
+@@ -0,0 +1,1 @@
++a
+`,
+		},
+		{
+			name:   "extra on disk",
+			onDisk: map[string]string{"file1": "a\n"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, data := range tc.onDisk {
+				path := filepath.Join(dir, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+			}
+			var out bytes.Buffer
+			changed, err := DiffFiles(&out, dir, tc.files)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.changed, changed)
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/ignition"
 )
 
 const (
@@ -21,10 +22,14 @@ func (a *Bootstrap) Generate(dependencies asset.Parents) error {
 	if err := a.generateConfig(dependencies, templateData); err != nil {
 		return err
 	}
+	certificates := &ignition.Certificates{}
+	dependencies.Get(certificates)
+	a.Config.Storage.Files = append(a.Config.Storage.Files, certificates.IgnitionFiles()...)
 
 	if err := a.generateFile(bootstrapIgnFilename); err != nil {
 		return err
 	}
+	ignition.LogSize(a.File.Filename, a.Config, a.File.Data)
 	return nil
 }
 
@@ -0,0 +1,138 @@
+package ignition
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"sort"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/tls"
+)
+
+const (
+	// trustBundlePath is where the additional trust bundle is installed.
+	// RHCOS adds the certificate authorities of the anchors directory to the
+	// trust store of the host at boot.
+	trustBundlePath = "/etc/pki/ca-trust/source/anchors/openshift-install-trust-bundle.crt"
+
+	// registryCertsDir is the directory of the certificate authorities of
+	// the container registries, in a host[:port] subdirectory per registry.
+	registryCertsDir = "/etc/containers/certs.d"
+)
+
+var (
+	// TrustBundleFiles are the paths of PEM files with certificate
+	// authorities added to the trust store of the bootstrap and of every
+	// node, in their generated Ignition configs.
+	TrustBundleFiles []string
+
+	// RegistryCertFiles maps mirror registries, as host[:port], to the path
+	// of a PEM file with the certificate authorities of the registry, which
+	// are trusted for the registry by the bootstrap and by every node.
+	RegistryCertFiles map[string]string
+)
+
+// Certificates is an asset with the certificates of TrustBundleFiles and
+// RegistryCertFiles. The certificates are loaded when the flags are set, so
+// that changing them regenerates the Ignition configs instead of keeping the
+// ones of the state file, and are kept in the state file otherwise.
+type Certificates struct {
+	TrustBundle   []byte
+	RegistryCerts map[string][]byte
+}
+
+var _ asset.WritableAsset = (*Certificates)(nil)
+
+// Name returns the human-friendly name of the asset.
+func (*Certificates) Name() string {
+	return "Ignition Certificates"
+}
+
+// Dependencies returns no dependencies.
+func (*Certificates) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Generate reads the certificates of the flags, if any.
+func (a *Certificates) Generate(asset.Parents) error {
+	return a.load()
+}
+
+// Files returns no files, the certificate files belong to the users.
+func (*Certificates) Files() []*asset.File {
+	return nil
+}
+
+// Load reads the certificates of the flags, when they are set.
+func (a *Certificates) Load(asset.FileFetcher) (bool, error) {
+	if len(TrustBundleFiles) == 0 && len(RegistryCertFiles) == 0 {
+		return false, nil
+	}
+	return true, a.load()
+}
+
+func (a *Certificates) load() error {
+	a.TrustBundle, a.RegistryCerts = nil, nil
+
+	var bundle [][]byte
+	for _, filename := range TrustBundleFiles {
+		data, err := readCertificates(filename)
+		if err != nil {
+			return err
+		}
+		bundle = append(bundle, data)
+	}
+	if len(bundle) > 0 {
+		a.TrustBundle = bytes.Join(bundle, []byte("\n"))
+	}
+
+	for registry, filename := range RegistryCertFiles {
+		if registry == "" || path.Base(registry) != registry {
+			return errors.Errorf("invalid registry %q, must be host[:port]", registry)
+		}
+		data, err := readCertificates(filename)
+		if err != nil {
+			return err
+		}
+		if a.RegistryCerts == nil {
+			a.RegistryCerts = map[string][]byte{}
+		}
+		a.RegistryCerts[registry] = data
+	}
+	return nil
+}
+
+// IgnitionFiles returns the Ignition files installing the certificates.
+func (a *Certificates) IgnitionFiles() []igntypes.File {
+	var files []igntypes.File
+	if len(a.TrustBundle) > 0 {
+		files = append(files, FileFromBytes(trustBundlePath, "root", 0644, a.TrustBundle))
+	}
+
+	registries := make([]string, 0, len(a.RegistryCerts))
+	for registry := range a.RegistryCerts {
+		registries = append(registries, registry)
+	}
+	sort.Strings(registries)
+	for _, registry := range registries {
+		files = append(files, FileFromBytes(path.Join(registryCertsDir, registry, "ca.crt"), "root", 0644, a.RegistryCerts[registry]))
+	}
+	return files
+}
+
+// readCertificates returns the content of the file, which must hold PEM
+// encoded certificates.
+func readCertificates(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read certificates")
+	}
+	if _, err := tls.PemToCertificate(data); err != nil {
+		return nil, errors.Wrapf(err, "invalid certificates in %s", filename)
+	}
+	return data, nil
+}
@@ -0,0 +1,91 @@
+package ignition
+
+import (
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/asset/tls"
+)
+
+func TestCertificates(t *testing.T) {
+	dir := t.TempDir()
+	_, cert, err := tls.GenerateSelfSignedCertificate(&tls.CertCfg{
+		Subject:  pkix.Name{CommonName: "test-ca", OrganizationalUnit: []string{"openshift"}},
+		Validity: tls.ValidityOneDay,
+		IsCA:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, tls.CertToPem(cert), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.crt")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		trustBundles  []string
+		registryCerts map[string]string
+		expectedPaths []string
+		expectedError string
+	}{
+		{
+			name: "no certificates",
+		},
+		{
+			name:         "trust bundles and registry certificates",
+			trustBundles: []string{caFile, caFile},
+			registryCerts: map[string]string{
+				"registry.example.com:5000": caFile,
+				"mirror.example.com":        caFile,
+			},
+			expectedPaths: []string{
+				"/etc/pki/ca-trust/source/anchors/openshift-install-trust-bundle.crt",
+				"/etc/containers/certs.d/mirror.example.com/ca.crt",
+				"/etc/containers/certs.d/registry.example.com:5000/ca.crt",
+			},
+		},
+		{
+			name:          "invalid certificates",
+			trustBundles:  []string{invalidFile},
+			expectedError: `^invalid certificates in .*/invalid.crt: could not find a PEM block in the certificate$`,
+		},
+		{
+			name:          "invalid registry",
+			registryCerts: map[string]string{"../etc": caFile},
+			expectedError: `^invalid registry "\.\./etc", must be host\[:port\]$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			TrustBundleFiles = tc.trustBundles
+			RegistryCertFiles = tc.registryCerts
+			defer func() {
+				TrustBundleFiles = nil
+				RegistryCertFiles = nil
+			}()
+
+			certificates := &Certificates{}
+			found, err := certificates.Load(nil)
+			assert.Equal(t, len(tc.trustBundles) > 0 || len(tc.registryCerts) > 0, found)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			var paths []string
+			for _, f := range certificates.IgnitionFiles() {
+				paths = append(paths, f.Path)
+			}
+			assert.Equal(t, tc.expectedPaths, paths)
+		})
+	}
+}
@@ -52,7 +52,7 @@ func TestMasterIgnitionCustomizationsGenerate(t *testing.T) {
 			assert.NoError(t, err, "unexpected error generating root CA")
 
 			parents := asset.Parents{}
-			parents.Add(installConfig, rootCA)
+			parents.Add(installConfig, rootCA, &ignition.Certificates{})
 
 			master := &Master{}
 			err = master.Generate(parents)
@@ -8,6 +8,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/ignition"
 	"github.com/openshift/installer/pkg/asset/installconfig"
 	"github.com/openshift/installer/pkg/asset/tls"
 	"github.com/openshift/installer/pkg/ipnet"
@@ -42,7 +43,8 @@ func TestMasterGenerate(t *testing.T) {
 	assert.NoError(t, err, "unexpected error generating root CA")
 
 	parents := asset.Parents{}
-	parents.Add(installConfig, rootCA)
+	certificates := &ignition.Certificates{RegistryCerts: map[string][]byte{"mirror.example.com": []byte("ca")}}
+	parents.Add(installConfig, rootCA, certificates)
 
 	master := &Master{}
 	err = master.Generate(parents)
@@ -56,4 +58,7 @@ func TestMasterGenerate(t *testing.T) {
 		actualIgnitionConfigNames[i] = f.Filename
 	}
 	assert.Equal(t, expectedIgnitionConfigNames, actualIgnitionConfigNames, "unexpected names for master ignition configs")
+	if assert.Len(t, master.Config.Storage.Files, 1) {
+		assert.Equal(t, "/etc/containers/certs.d/mirror.example.com/ca.crt", master.Config.Storage.Files[0].Path)
+	}
 }
@@ -30,6 +30,7 @@ func (a *Worker) Dependencies() []asset.Asset {
 	return []asset.Asset{
 		&installconfig.InstallConfig{},
 		&tls.RootCA{},
+		&ignition.Certificates{},
 	}
 }
 
@@ -37,9 +38,11 @@ func (a *Worker) Dependencies() []asset.Asset {
 func (a *Worker) Generate(dependencies asset.Parents) error {
 	installConfig := &installconfig.InstallConfig{}
 	rootCA := &tls.RootCA{}
-	dependencies.Get(installConfig, rootCA)
+	certificates := &ignition.Certificates{}
+	dependencies.Get(installConfig, rootCA, certificates)
 
 	a.Config = pointerIgnitionConfig(installConfig.Config, rootCA.Cert(), "worker")
+	a.Config.Storage.Files = append(a.Config.Storage.Files, certificates.IgnitionFiles()...)
 
 	data, err := ignition.Marshal(a.Config)
 	if err != nil {
@@ -52,7 +52,7 @@ func TestWorkerIgnitionCustomizationsGenerate(t *testing.T) {
 			assert.NoError(t, err, "unexpected error generating root CA")
 
 			parents := asset.Parents{}
-			parents.Add(installConfig, rootCA)
+			parents.Add(installConfig, rootCA, &ignition.Certificates{})
 
 			worker := &Worker{}
 			err = worker.Generate(parents)
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/ignition"
 	"github.com/openshift/installer/pkg/asset/installconfig"
 	"github.com/openshift/installer/pkg/asset/tls"
 	"github.com/openshift/installer/pkg/ipnet"
@@ -32,7 +33,7 @@ func TestWorkerGenerate(t *testing.T) {
 	assert.NoError(t, err, "unexpected error generating root CA")
 
 	parents := asset.Parents{}
-	parents.Add(installConfig, rootCA)
+	parents.Add(installConfig, rootCA, &ignition.Certificates{})
 
 	worker := &Worker{}
 	err = worker.Generate(parents)
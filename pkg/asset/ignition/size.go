@@ -0,0 +1,132 @@
+package ignition
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/types/alibabacloud"
+	"github.com/openshift/installer/pkg/types/aws"
+	"github.com/openshift/installer/pkg/types/azure"
+	"github.com/openshift/installer/pkg/types/gcp"
+	"github.com/openshift/installer/pkg/types/ibmcloud"
+	"github.com/openshift/installer/pkg/types/openstack"
+)
+
+// largestPartsCount is the number of the largest parts of an Ignition config
+// which are reported.
+const largestPartsCount = 5
+
+// userDataLimit is the size of the largest user data the instances of a
+// platform accept.
+type userDataLimit struct {
+	// size is the limit in bytes.
+	size int
+	// base64 is whether the limit applies to the base64 encoded user data.
+	base64 bool
+	// description names the limit in the warnings.
+	description string
+}
+
+// userDataLimits are the user data limits of the platforms whose instances
+// get their Ignition config as user data.
+var userDataLimits = map[string]userDataLimit{
+	alibabacloud.Name: {size: 32 * 1024, description: "the 32 KiB ECS user data limit"},
+	aws.Name:          {size: 16 * 1024, description: "the 16 KiB EC2 user data limit"},
+	azure.Name:        {size: 64 * 1024, base64: true, description: "the 64 KiB Azure custom data limit"},
+	gcp.Name:          {size: 256 * 1024, description: "the 256 KiB GCE metadata value limit"},
+	ibmcloud.Name:     {size: 64 * 1024, description: "the 64 KiB VPC user data limit"},
+	openstack.Name:    {size: 65535, base64: true, description: "the 64 KiB Nova user data limit"},
+}
+
+// part is a file or other resource embedded into an Ignition config.
+type part struct {
+	name string
+	size int
+}
+
+// LogSize logs the size of the Ignition config and its largest parts.
+func LogSize(filename string, config *igntypes.Config, data []byte) {
+	logrus.Debugf("%s is %s, its largest parts are %s", filename, formatSize(len(data)), formatParts(largestParts(config)))
+}
+
+// CheckUserDataSize warns when the Ignition config, which is given to the
+// instances of the platform as user data, is larger than the platform
+// accepts. Instances would otherwise fail to be created with an error from
+// the cloud which does not say why.
+func CheckUserDataSize(platformName, filename string, data []byte) {
+	limit, ok := userDataLimits[platformName]
+	if !ok {
+		return
+	}
+	size := len(data)
+	if limit.base64 {
+		size = base64.StdEncoding.EncodedLen(size)
+	}
+	if size <= limit.size {
+		logrus.Debugf("%s is %s, within %s", filename, formatSize(size), limit.description)
+		return
+	}
+
+	config := &igntypes.Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		logrus.Warnf("%s is %s, which exceeds %s", filename, formatSize(size), limit.description)
+		return
+	}
+	logrus.Warnf("%s is %s, which exceeds %s, consider shrinking its largest parts: %s", filename, formatSize(size), limit.description, formatParts(largestParts(config)))
+}
+
+// largestParts returns the largest files, certificate authorities and
+// merged or replacing configs embedded into the Ignition config.
+func largestParts(config *igntypes.Config) []part {
+	var parts []part
+	addResource := func(name string, resource igntypes.Resource) {
+		if resource.Source != nil {
+			parts = append(parts, part{name: name, size: len(*resource.Source)})
+		}
+	}
+	for _, file := range config.Storage.Files {
+		addResource(file.Path, file.Contents)
+		for i, resource := range file.Append {
+			addResource(fmt.Sprintf("%s (append #%d)", file.Path, i+1), resource)
+		}
+	}
+	for i, ca := range config.Ignition.Security.TLS.CertificateAuthorities {
+		addResource(fmt.Sprintf("certificate authority #%d", i+1), ca)
+	}
+	for i, merge := range config.Ignition.Config.Merge {
+		addResource(fmt.Sprintf("merged config #%d", i+1), merge)
+	}
+	addResource("replacing config", config.Ignition.Config.Replace)
+
+	sort.SliceStable(parts, func(i, j int) bool {
+		return parts[i].size > parts[j].size
+	})
+	if len(parts) > largestPartsCount {
+		parts = parts[:largestPartsCount]
+	}
+	return parts
+}
+
+func formatParts(parts []part) string {
+	if len(parts) == 0 {
+		return "none"
+	}
+	formatted := make([]string, 0, len(parts))
+	for _, p := range parts {
+		formatted = append(formatted, fmt.Sprintf("%s (%s)", p.name, formatSize(p.size)))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func formatSize(size int) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f KiB", float64(size)/1024)
+}
@@ -0,0 +1,92 @@
+package ignition
+
+import (
+	"strings"
+	"testing"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func pointerConfig(caSize int) []byte {
+	config := &igntypes.Config{
+		Ignition: igntypes.Ignition{Version: igntypes.MaxVersion.String()},
+	}
+	source := "data:text/plain;charset=utf-8;base64," + strings.Repeat("A", caSize)
+	config.Ignition.Security.TLS.CertificateAuthorities = []igntypes.Resource{{Source: &source}}
+	data, err := Marshal(config)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestCheckUserDataSize(t *testing.T) {
+	cases := []struct {
+		name            string
+		platform        string
+		data            []byte
+		expectedWarnMsg string
+	}{
+		{
+			name:     "within the limit",
+			platform: "aws",
+			data:     pointerConfig(1024),
+		},
+		{
+			name:            "above the limit",
+			platform:        "aws",
+			data:            pointerConfig(20 * 1024),
+			expectedWarnMsg: `^master.ign is 20\.\d KiB, which exceeds the 16 KiB EC2 user data limit, consider shrinking its largest parts: certificate authority #1 \(20\.0 KiB\)$`,
+		},
+		{
+			name:            "above the base64 encoded limit",
+			platform:        "azure",
+			data:            pointerConfig(50 * 1024),
+			expectedWarnMsg: `^master.ign is 66\.\d KiB, which exceeds the 64 KiB Azure custom data limit`,
+		},
+		{
+			name:     "platform without a limit",
+			platform: "baremetal",
+			data:     pointerConfig(1024 * 1024),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := logrusTest.NewGlobal()
+			defer hook.Reset()
+
+			CheckUserDataSize(tc.platform, "master.ign", tc.data)
+
+			var warnings []string
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel {
+					warnings = append(warnings, entry.Message)
+				}
+			}
+			if tc.expectedWarnMsg == "" {
+				assert.Empty(t, warnings)
+				return
+			}
+			if assert.Len(t, warnings, 1) {
+				assert.Regexp(t, tc.expectedWarnMsg, warnings[0])
+			}
+		})
+	}
+}
+
+func TestLargestParts(t *testing.T) {
+	config := &igntypes.Config{}
+	for i, size := range []int{10, 70, 30, 50, 20, 60, 40} {
+		file := FileFromString("/etc/file"+string(rune('0'+i)), "root", 0644, strings.Repeat("a", size*1024))
+		config.Storage.Files = append(config.Storage.Files, file)
+	}
+
+	var names []string
+	for _, p := range largestParts(config) {
+		names = append(names, p.name)
+	}
+	assert.Equal(t, []string{"/etc/file1", "/etc/file5", "/etc/file3", "/etc/file6", "/etc/file2"}, names)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	ccaws "github.com/openshift/cloud-credential-operator/pkg/aws"
 )
@@ -39,6 +40,10 @@ const (
 	// PermissionDeleteHostedZone is a set of permissions required when the installer destroys a route53 hosted zone.
 	PermissionDeleteHostedZone PermissionGroup = "delete-hosted-zone"
 
+	// PermissionDestroyCluster is the set of permissions required, in addition to the delete groups, when the installer
+	// destroys a cluster.
+	PermissionDestroyCluster PermissionGroup = "destroy-cluster"
+
 	// PermissionKMSEncryptionKeys is an additional set of permissions required when the installer uses user provided kms encryption keys.
 	PermissionKMSEncryptionKeys PermissionGroup = "kms-encryption-keys"
 )
@@ -247,6 +252,56 @@ var permissions = map[PermissionGroup][]string{
 	PermissionDeleteHostedZone: {
 		"route53:DeleteHostedZone",
 	},
+	// Permissions required, in addition to the delete groups, for destroying a cluster
+	PermissionDestroyCluster: {
+		"ec2:DeleteSecurityGroup",
+		"ec2:DeleteSnapshot",
+		"ec2:DeleteVpcEndpointServiceConfigurations",
+		"ec2:DeleteVpcPeeringConnection",
+		"ec2:DeregisterImage",
+		"ec2:DescribeImages",
+		"ec2:DescribeInstances",
+		"ec2:DescribeInternetGateways",
+		"ec2:DescribeNatGateways",
+		"ec2:DescribeNetworkInterfaces",
+		"ec2:DescribePlacementGroups",
+		"ec2:DescribeRouteTables",
+		"ec2:DescribeSecurityGroups",
+		"ec2:DescribeSubnets",
+		"ec2:DescribeVpcEndpointConnections",
+		"ec2:DescribeVpcEndpoints",
+		"ec2:RevokeSecurityGroupEgress",
+		"ec2:RevokeSecurityGroupIngress",
+		"ec2:TerminateInstances",
+		"elasticfilesystem:DeleteAccessPoint",
+		"elasticfilesystem:DeleteFileSystem",
+		"elasticfilesystem:DeleteMountTarget",
+		"elasticfilesystem:DescribeAccessPoints",
+		"elasticfilesystem:DescribeMountTargets",
+		"elasticloadbalancing:DeleteListener",
+		"elasticloadbalancing:DeleteLoadBalancer",
+		"elasticloadbalancing:DescribeLoadBalancers",
+		"iam:DeleteInstanceProfile",
+		"iam:DeleteRole",
+		"iam:DeleteRolePolicy",
+		"iam:DeleteUserPolicy",
+		"iam:DetachRolePolicy",
+		"iam:GetInstanceProfile",
+		"iam:GetRole",
+		"iam:GetUser",
+		"iam:ListAccessKeys",
+		"iam:ListInstanceProfilesForRole",
+		"iam:ListRoles",
+		"iam:ListUsers",
+		"iam:RemoveRoleFromInstanceProfile",
+		"route53:ChangeResourceRecordSets",
+		"route53:GetHostedZone",
+		"route53:ListHostedZonesByName",
+		"route53:ListResourceRecordSets",
+		"s3:DeleteBucket",
+		"s3:DeleteObjectVersion",
+		"s3:ListBucket",
+	},
 	PermissionKMSEncryptionKeys: {
 		"kms:Decrypt",
 		"kms:Encrypt",
@@ -259,19 +314,27 @@ var permissions = map[PermissionGroup][]string{
 	},
 }
 
+// Permissions returns the sorted permissions of the permission groups.
+func Permissions(groups []PermissionGroup) ([]string, error) {
+	requiredPermissions := sets.New[string]()
+	for _, group := range groups {
+		groupPerms, ok := permissions[group]
+		if !ok {
+			return nil, errors.Errorf("unable to access permissions group %s", group)
+		}
+		requiredPermissions.Insert(groupPerms...)
+	}
+	return sets.List(requiredPermissions), nil
+}
+
 // ValidateCreds will try to create an AWS session, and also verify that the current credentials
 // are sufficient to perform an installation, and that they can be used for cluster runtime
 // as either capable of creating new credentials for components that interact with the cloud or
 // being able to be passed through as-is to the components that need cloud credentials
 func ValidateCreds(ssn *session.Session, groups []PermissionGroup, region string) error {
-	// Compile a list of permissions based on the permission groups provided
-	requiredPermissions := []string{}
-	for _, group := range groups {
-		groupPerms, ok := permissions[group]
-		if !ok {
-			return errors.Errorf("unable to access permissions group %s", group)
-		}
-		requiredPermissions = append(requiredPermissions, groupPerms...)
+	requiredPermissions, err := Permissions(groups)
+	if err != nil {
+		return err
 	}
 
 	client, err := ccaws.NewClientFromIAMClient(iam.New(ssn))
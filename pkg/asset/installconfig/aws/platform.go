@@ -17,7 +17,7 @@ import (
 // Platform collects AWS-specific configuration.
 func Platform() (*aws.Platform, error) {
 	architecture := version.DefaultArch()
-	regions := knownPublicRegions(architecture)
+	regions := KnownPublicRegions(architecture)
 	longRegions := make([]string, 0, len(regions))
 	shortRegions := make([]string, 0, len(regions))
 	for id, location := range regions {
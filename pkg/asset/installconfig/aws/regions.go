@@ -7,10 +7,10 @@ import (
 	"github.com/openshift/installer/pkg/types"
 )
 
-// knownPublicRegions is the subset of public AWS regions where RHEL CoreOS images are published.
+// KnownPublicRegions returns the subset of public AWS regions where RHEL CoreOS images are published.
 // This subset does not include supported regions which are found in other partitions, such as us-gov-east-1.
 // Returns: a map of region identifier to region description.
-func knownPublicRegions(architecture types.Architecture) map[string]string {
+func KnownPublicRegions(architecture types.Architecture) map[string]string {
 	required := rhcos.AMIRegions(architecture)
 
 	regions := make(map[string]string)
@@ -25,7 +25,7 @@ func knownPublicRegions(architecture types.Architecture) map[string]string {
 // IsKnownPublicRegion returns true if a specified region is Known to the installer.
 // A known region is the subset of public AWS regions where RHEL CoreOS images are published.
 func IsKnownPublicRegion(region string, architecture types.Architecture) bool {
-	if _, ok := knownPublicRegions(architecture)[region]; ok {
+	if _, ok := KnownPublicRegions(architecture)[region]; ok {
 		return true
 	}
 	return false
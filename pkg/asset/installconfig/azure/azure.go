@@ -3,6 +3,7 @@ package azure
 import (
 	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -99,6 +100,25 @@ func Platform() (*azure.Platform, error) {
 	}, nil
 }
 
+// KnownRegions returns a map of the public cloud regions available to the
+// configured subscription to their display name. The credentials are only
+// read from the auth file, the user is never asked for them.
+func KnownRegions(ctx context.Context) (map[string]string, error) {
+	authFilePath := defaultAuthFilePath
+	if f := os.Getenv(azureAuthEnv); len(f) > 0 {
+		authFilePath = f
+	}
+	credentials, err := credentialsFromFile(authFilePath)
+	if err != nil {
+		return nil, err
+	}
+	ssn, err := GetSessionWithCredentials(azure.PublicCloud, "", credentials)
+	if err != nil {
+		return nil, err
+	}
+	return getRegions(ctx, NewClient(ssn))
+}
+
 func getRegions(ctx context.Context, client API) (map[string]string, error) {
 	locations, err := client.ListLocations(ctx)
 	if err != nil {
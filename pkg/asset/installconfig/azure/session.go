@@ -143,35 +143,26 @@ func credentialsFromFileOrUser() (*Credentials, error) {
 		authFilePath = f
 	}
 
-	var authFile Credentials
-
-	contents, err := os.ReadFile(authFilePath)
+	authFile, err := credentialsFromFile(authFilePath)
 	if err != nil {
 		// If the file with creds was not found, ask user for auth info
-		if errors.Is(err, fs.ErrNotExist) {
-			logrus.Infof("Asking user to provide authentication info")
-			credentials, cerr := askForCredentials()
-			if cerr != nil {
-				return nil, errors.Wrap(cerr, "failed to retrieve credentials from user")
-			}
-			logrus.Infof("Saving user credentials to %q", authFilePath)
-			if cerr = saveCredentials(*credentials, authFilePath); cerr != nil {
-				return nil, errors.Wrap(cerr, "failed to save credentials")
-			}
-			authFile = *credentials
-		} else {
+		if !errors.Is(err, fs.ErrNotExist) {
 			// File was found but we failed to read it, just error out and let the user handle it
 			return nil, err
 		}
-	} else {
-		err = json.Unmarshal(contents, &authFile)
-		if err != nil {
+		logrus.Infof("Asking user to provide authentication info")
+		credentials, cerr := askForCredentials()
+		if cerr != nil {
+			return nil, errors.Wrap(cerr, "failed to retrieve credentials from user")
+		}
+		logrus.Infof("Saving user credentials to %q", authFilePath)
+		if cerr = saveCredentials(*credentials, authFilePath); cerr != nil {
+			return nil, errors.Wrap(cerr, "failed to save credentials")
+		}
+		if err := checkCredentials(*credentials); err != nil {
 			return nil, err
 		}
-	}
-
-	if err := checkCredentials(authFile); err != nil {
-		return nil, err
+		authFile = credentials
 	}
 
 	if _, has := onceLoggers[authFilePath]; !has {
@@ -181,6 +172,23 @@ func credentialsFromFileOrUser() (*Credentials, error) {
 		logrus.Infof("Credentials loaded from file %q", authFilePath)
 	})
 
+	return authFile, nil
+}
+
+// credentialsFromFile returns the credentials of the auth file, without
+// asking the user for them when the file does not exist.
+func credentialsFromFile(authFilePath string) (*Credentials, error) {
+	contents, err := os.ReadFile(authFilePath)
+	if err != nil {
+		return nil, err
+	}
+	var authFile Credentials
+	if err := json.Unmarshal(contents, &authFile); err != nil {
+		return nil, err
+	}
+	if err := checkCredentials(authFile); err != nil {
+		return nil, err
+	}
 	return &authFile, nil
 }
 
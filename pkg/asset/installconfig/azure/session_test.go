@@ -0,0 +1,53 @@
+package azure
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialsFromFile(t *testing.T) {
+	cases := []struct {
+		name          string
+		contents      string
+		expected      *Credentials
+		expectedError string
+	}{
+		{
+			name:          "missing file",
+			expectedError: "no such file or directory",
+		},
+		{
+			name:          "missing tenant",
+			contents:      `{"subscriptionId": "subscription"}`,
+			expectedError: "could not retrieve tenantId from auth file",
+		},
+		{
+			name:     "client secret",
+			contents: `{"subscriptionId": "subscription", "tenantId": "tenant", "clientId": "client", "clientSecret": "secret"}`,
+			expected: &Credentials{SubscriptionID: "subscription", TenantID: "tenant", ClientID: "client", ClientSecret: "secret"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			authFilePath := filepath.Join(t.TempDir(), "osServicePrincipal.json")
+			if tc.contents != "" {
+				require.NoError(t, os.WriteFile(authFilePath, []byte(tc.contents), 0600))
+			}
+			credentials, err := credentialsFromFile(authFilePath)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				if tc.contents == "" {
+					assert.ErrorIs(t, err, fs.ErrNotExist)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, credentials)
+		})
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"strings"
 
 	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
 	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/yaml"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/reproducible"
 )
 
 const (
@@ -43,6 +46,18 @@ func (a *ClusterID) Generate(dep asset.Parents) error {
 	// and the maximum length for most resources is approx 32.
 	maxLen := 27
 
+	if reproducible.Enabled() {
+		// Derive the IDs from the install config, so that the same install
+		// config always gets the same IDs.
+		data, err := yaml.Marshal(ica.Config)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the install config")
+		}
+		a.InfraID = generateInfraIDWithSuffix(ica.Config.ObjectMeta.Name, maxLen, reproducible.String(randomLen, data))
+		a.UUID = uuid.NewSHA1(uuid.NameSpace_OID, data).String()
+		return nil
+	}
+
 	// add random chars to the end to randomize
 	a.InfraID = generateInfraID(ica.Config.ObjectMeta.Name, maxLen)
 	a.UUID = uuid.New()
@@ -58,7 +73,14 @@ func (a *ClusterID) Name() string {
 // - is of length maxLen
 // - only contains `alphanum` or `-`
 func generateInfraID(base string, maxLen int) string {
-	maxBaseLen := maxLen - (randomLen + 1)
+	// add random chars to the end to randomize
+	return generateInfraIDWithSuffix(base, maxLen, utilrand.String(randomLen))
+}
+
+// generateInfraIDWithSuffix is generateInfraID with the given suffix instead of
+// random chars.
+func generateInfraIDWithSuffix(base string, maxLen int, suffix string) string {
+	maxBaseLen := maxLen - (len(suffix) + 1)
 
 	// replace all characters that are not `alphanum` or `-` with `-`
 	re := regexp.MustCompile("[^A-Za-z0-9-]")
@@ -74,6 +96,5 @@ func generateInfraID(base string, maxLen int) string {
 	}
 	base = strings.TrimRight(base, "-")
 
-	// add random chars to the end to randomize
-	return fmt.Sprintf("%s-%s", base, utilrand.String(randomLen))
+	return fmt.Sprintf("%s-%s", base, suffix)
 }
@@ -49,6 +49,17 @@ func MakeAsset(config *types.InstallConfig) *InstallConfig {
 	}
 }
 
+// PromptedFields lists the install-config fields the user is queried for
+// when no install-config.yaml is provided.
+var PromptedFields = []string{
+	"sshKey",
+	"baseDomain",
+	"metadata.name",
+	"networking.machineNetwork",
+	"pullSecret",
+	"platform",
+}
+
 // Dependencies returns all of the dependencies directly needed by an
 // InstallConfig asset.
 func (a *InstallConfig) Dependencies() []asset.Asset {
@@ -62,6 +73,10 @@ func (a *InstallConfig) Dependencies() []asset.Asset {
 	}
 }
 
+// Interactive marks the InstallConfig as interactive, since its dependencies
+// query the user for any values that were not provided.
+func (a *InstallConfig) Interactive() {}
+
 // Generate generates the install-config.yaml file.
 func (a *InstallConfig) Generate(parents asset.Parents) error {
 	sshPublicKey := &sshPublicKey{}
@@ -108,6 +123,12 @@ func (a *InstallConfig) Generate(parents asset.Parents) error {
 	a.Config.PowerVS = platform.PowerVS
 	a.Config.Nutanix = platform.Nutanix
 
+	if Profile != "" {
+		if err := ApplyProfile(a.Config, Profile); err != nil {
+			return err
+		}
+	}
+
 	defaults.SetInstallConfigDefaults(a.Config)
 
 	return a.finish("")
@@ -44,6 +44,16 @@ func (a *AssetBase) LoadFromFile(f asset.FileFetcher) (found bool, err error) {
 		return false, errors.Wrap(err, asset.InstallConfigError)
 	}
 
+	if ValuesFile != "" {
+		values, err := loadValues()
+		if err != nil {
+			return false, errors.Wrap(err, asset.InstallConfigError)
+		}
+		if file.Data, err = renderTemplate(file.Data, values); err != nil {
+			return false, errors.Wrap(err, asset.InstallConfigError)
+		}
+	}
+
 	config := &types.InstallConfig{}
 	if err := yaml.UnmarshalStrict(file.Data, config, yaml.DisallowUnknownFields); err != nil {
 		err = errors.Wrapf(err, "failed to unmarshal %s", installConfigFilename)
@@ -0,0 +1,33 @@
+package installconfig
+
+import (
+	"github.com/pkg/errors"
+
+	configv1 "github.com/openshift/api/config/v1"
+	gcpconfig "github.com/openshift/installer/pkg/asset/installconfig/gcp"
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/aws"
+	awsdefaults "github.com/openshift/installer/pkg/types/aws/defaults"
+	"github.com/openshift/installer/pkg/types/azure"
+	azuredefaults "github.com/openshift/installer/pkg/types/azure/defaults"
+	"github.com/openshift/installer/pkg/types/gcp"
+)
+
+// DefaultInstanceTypes returns the instance types the installer picks for the
+// machine pools of the platform in the region when none are configured, in
+// decreasing order of preference.
+func DefaultInstanceTypes(platform, region string, arch types.Architecture) ([]string, error) {
+	switch platform {
+	case aws.Name:
+		return awsdefaults.InstanceTypes(region, arch, configv1.HighlyAvailableTopologyMode), nil
+	case azure.Name:
+		return []string{
+			azuredefaults.ControlPlaneInstanceType(azure.PublicCloud, region, arch),
+			azuredefaults.ComputeInstanceType(azure.PublicCloud, region, arch),
+		}, nil
+	case gcp.Name:
+		return []string{gcpconfig.DefaultInstanceTypeForArch(arch)}, nil
+	default:
+		return nil, errors.Errorf("listing instance types is not supported for platform %q", platform)
+	}
+}
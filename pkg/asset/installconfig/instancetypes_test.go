@@ -0,0 +1,49 @@
+package installconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/types"
+)
+
+func TestDefaultInstanceTypes(t *testing.T) {
+	cases := []struct {
+		platform string
+		arch     types.Architecture
+		expected []string
+		err      string
+	}{
+		{
+			platform: "aws",
+			arch:     types.ArchitectureARM64,
+			expected: []string{"m6g.xlarge"},
+		},
+		{
+			platform: "azure",
+			arch:     types.ArchitectureAMD64,
+			expected: []string{"Standard_D8s_v3", "Standard_D4s_v3"},
+		},
+		{
+			platform: "gcp",
+			arch:     types.ArchitectureAMD64,
+			expected: []string{"n2-standard-4"},
+		},
+		{
+			platform: "none",
+			err:      `^listing instance types is not supported for platform "none"$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.platform, func(t *testing.T) {
+			instanceTypes, err := DefaultInstanceTypes(tc.platform, "", tc.arch)
+			if tc.err != "" {
+				assert.Regexp(t, tc.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, instanceTypes)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package installconfig
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/aws"
+	"github.com/openshift/installer/pkg/types/azure"
+	"github.com/openshift/installer/pkg/types/defaults"
+	"github.com/openshift/installer/pkg/types/gcp"
+	"github.com/openshift/installer/pkg/types/ibmcloud"
+	"github.com/openshift/installer/pkg/types/none"
+	"github.com/openshift/installer/pkg/types/validation"
+)
+
+// NewConfigOptions holds the values an install config is built from without
+// querying the user.
+type NewConfigOptions struct {
+	// Platform is the name of the platform the cluster is installed on.
+	Platform string
+	// Region is the region the cluster is installed in, for cloud platforms.
+	Region string
+	// ClusterName is the name of the cluster.
+	ClusterName string
+	// BaseDomain is the base domain of the cluster.
+	BaseDomain string
+	// PullSecret is the container registry pull secret.
+	PullSecret string
+	// SSHKey is the public SSH key added to the cluster nodes.
+	SSHKey string
+	// GCPProjectID is the GCP project the cluster is created in.
+	GCPProjectID string
+	// AzureBaseDomainResourceGroupName is the Azure resource group holding
+	// the DNS zone of the base domain.
+	AzureBaseDomainResourceGroupName string
+	// Profile is the name of a built-in profile, or the path of a profile
+	// file, applied to the install config.
+	Profile string
+}
+
+// newConfigPlatforms maps the platforms supported by NewConfig to a function
+// filling in the platform section from the options.
+var newConfigPlatforms = map[string]func(*types.Platform, NewConfigOptions){
+	aws.Name: func(p *types.Platform, opts NewConfigOptions) {
+		p.AWS = &aws.Platform{Region: opts.Region}
+	},
+	azure.Name: func(p *types.Platform, opts NewConfigOptions) {
+		p.Azure = &azure.Platform{
+			Region:                      opts.Region,
+			BaseDomainResourceGroupName: opts.AzureBaseDomainResourceGroupName,
+		}
+	},
+	gcp.Name: func(p *types.Platform, opts NewConfigOptions) {
+		p.GCP = &gcp.Platform{
+			ProjectID: opts.GCPProjectID,
+			Region:    opts.Region,
+		}
+	},
+	ibmcloud.Name: func(p *types.Platform, opts NewConfigOptions) {
+		p.IBMCloud = &ibmcloud.Platform{Region: opts.Region}
+	},
+	none.Name: func(p *types.Platform, _ NewConfigOptions) {
+		p.None = &none.Platform{}
+	},
+}
+
+// NewConfigPlatforms returns the platforms supported by NewConfig.
+func NewConfigPlatforms() []string {
+	platforms := make([]string, 0, len(newConfigPlatforms))
+	for name := range newConfigPlatforms {
+		platforms = append(platforms, name)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// NewConfig returns an install config built from the options, with all the
+// defaults filled in. The config is validated without connecting to the
+// platform, so checks which need credentials still happen when it is consumed.
+func NewConfig(opts NewConfigOptions) (*types.InstallConfig, error) {
+	setPlatform, ok := newConfigPlatforms[opts.Platform]
+	if !ok {
+		return nil, errors.Errorf("platform %q is not supported, must be one of %v", opts.Platform, NewConfigPlatforms())
+	}
+
+	config := &types.InstallConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: types.InstallConfigVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: opts.ClusterName,
+		},
+		SSHKey:     opts.SSHKey,
+		BaseDomain: opts.BaseDomain,
+		PullSecret: opts.PullSecret,
+	}
+	setPlatform(&config.Platform, opts)
+
+	if opts.Profile != "" {
+		if err := ApplyProfile(config, opts.Profile); err != nil {
+			return nil, err
+		}
+	}
+
+	defaults.SetInstallConfigDefaults(config)
+
+	if err := validation.ValidateInstallConfig(config, false).ToAggregate(); err != nil {
+		return nil, errors.Wrap(err, "invalid install config")
+	}
+	return config, nil
+}
@@ -0,0 +1,121 @@
+package installconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/types"
+)
+
+func TestNewConfig(t *testing.T) {
+	base := NewConfigOptions{
+		ClusterName: "test-cluster",
+		BaseDomain:  "test-domain.com",
+		PullSecret:  `{"auths":{"example.com":{"auth":"authorization value"}}}`,
+	}
+	cases := []struct {
+		name     string
+		platform string
+		region   string
+		project  string
+		profile  string
+		check    func(*testing.T, *types.InstallConfig)
+		err      string
+	}{
+		{
+			name:     "aws",
+			platform: "aws",
+			region:   "us-east-1",
+			check: func(t *testing.T, config *types.InstallConfig) {
+				if assert.NotNil(t, config.Platform.AWS) {
+					assert.Equal(t, "us-east-1", config.Platform.AWS.Region)
+				}
+			},
+		},
+		{
+			name:     "gcp",
+			platform: "gcp",
+			region:   "us-east1",
+			project:  "test-project",
+			check: func(t *testing.T, config *types.InstallConfig) {
+				if assert.NotNil(t, config.Platform.GCP) {
+					assert.Equal(t, "test-project", config.Platform.GCP.ProjectID)
+				}
+			},
+		},
+		{
+			name:     "none",
+			platform: "none",
+			check: func(t *testing.T, config *types.InstallConfig) {
+				assert.NotNil(t, config.Platform.None)
+			},
+		},
+		{
+			name:     "compact profile",
+			platform: "none",
+			profile:  "compact",
+			check: func(t *testing.T, config *types.InstallConfig) {
+				assert.Equal(t, int64(3), *config.ControlPlane.Replicas)
+				if assert.Len(t, config.Compute, 1) {
+					assert.Equal(t, int64(0), *config.Compute[0].Replicas)
+				}
+			},
+		},
+		{
+			name:     "large-prod profile",
+			platform: "aws",
+			region:   "us-east-1",
+			profile:  "large-prod",
+			check: func(t *testing.T, config *types.InstallConfig) {
+				if assert.NotNil(t, config.ControlPlane.Platform.AWS) {
+					assert.Equal(t, "m6i.2xlarge", config.ControlPlane.Platform.AWS.InstanceType)
+				}
+				assert.Nil(t, config.ControlPlane.Platform.GCP)
+				if assert.Len(t, config.Compute, 1) {
+					assert.Equal(t, int64(6), *config.Compute[0].Replicas)
+				}
+				assert.Equal(t, "us-east-1", config.Platform.AWS.Region)
+				assert.Equal(t, "10.128.0.0/12", config.Networking.ClusterNetwork[0].CIDR.String())
+			},
+		},
+		{
+			name:     "unknown profile",
+			platform: "none",
+			profile:  "tiny",
+			err:      `^profile "tiny" is neither a file nor one of \[compact large-prod sno\]$`,
+		},
+		{
+			name:     "unsupported platform",
+			platform: "baremetal",
+			err:      `^platform "baremetal" is not supported, must be one of \[aws azure gcp ibmcloud none\]$`,
+		},
+		{
+			name:     "missing region",
+			platform: "aws",
+			err:      `^invalid install config: .*platform\.aws\.region: Required value.*$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := base
+			opts.Platform = tc.platform
+			opts.Region = tc.region
+			opts.GCPProjectID = tc.project
+			opts.Profile = tc.profile
+			config, err := NewConfig(opts)
+			if tc.err != "" {
+				assert.Regexp(t, tc.err, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, types.InstallConfigVersion, config.APIVersion)
+			assert.Equal(t, "test-cluster", config.ObjectMeta.Name)
+			assert.NotNil(t, config.Networking)
+			assert.NotNil(t, config.ControlPlane)
+			tc.check(t, config)
+		})
+	}
+}
@@ -44,6 +44,10 @@ func (a *PlatformCredsCheck) Dependencies() []asset.Asset {
 	}
 }
 
+// Interactive marks the PlatformCredsCheck as interactive, since it may prompt
+// for the credentials of the platform.
+func (a *PlatformCredsCheck) Interactive() {}
+
 // Generate queries for input from the user.
 func (a *PlatformCredsCheck) Generate(dependencies asset.Parents) error {
 	ctx := context.TODO()
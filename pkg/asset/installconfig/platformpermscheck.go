@@ -42,8 +42,16 @@ func (a *PlatformPermsCheck) Dependencies() []asset.Asset {
 	}
 }
 
+// Interactive marks the PlatformPermsCheck as interactive, since it may prompt
+// for the credentials of the platform.
+func (a *PlatformPermsCheck) Interactive() {}
+
 // Generate queries for input from the user.
 func (a *PlatformPermsCheck) Generate(dependencies asset.Parents) error {
+	return errors.Wrap(a.generate(dependencies), asset.PreflightCheckError)
+}
+
+func (a *PlatformPermsCheck) generate(dependencies asset.Parents) error {
 	ctx := context.TODO()
 	ic := &InstallConfig{}
 	dependencies.Get(ic)
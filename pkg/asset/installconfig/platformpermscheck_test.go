@@ -0,0 +1,50 @@
+package installconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/none"
+)
+
+func TestPlatformPermsCheckGenerate(t *testing.T) {
+	cases := []struct {
+		name        string
+		config      *types.InstallConfig
+		expectedErr string
+	}{
+		{
+			name: "credentials mode set",
+			config: &types.InstallConfig{
+				CredentialsMode: types.ManualCredentialsMode,
+			},
+		},
+		{
+			name: "no permissions to check",
+			config: &types.InstallConfig{
+				Platform: types.Platform{None: &none.Platform{}},
+			},
+		},
+		{
+			name:        "unknown platform",
+			config:      &types.InstallConfig{},
+			expectedErr: `^failed preflight checks: unknown platform type ""$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parents := asset.Parents{}
+			parents.Add(MakeAsset(tc.config))
+
+			err := (&PlatformPermsCheck{}).Generate(parents)
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
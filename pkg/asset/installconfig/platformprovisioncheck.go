@@ -50,6 +50,10 @@ func (a *PlatformProvisionCheck) Dependencies() []asset.Asset {
 	}
 }
 
+// Interactive marks the PlatformProvisionCheck as interactive, since it may prompt
+// for the credentials of the platform.
+func (a *PlatformProvisionCheck) Interactive() {}
+
 // Generate queries for input from the user.
 func (a *PlatformProvisionCheck) Generate(dependencies asset.Parents) error {
 	ic := &InstallConfig{}
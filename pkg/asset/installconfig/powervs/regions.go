@@ -11,7 +11,8 @@ import (
 	"github.com/openshift/installer/pkg/types/powervs"
 )
 
-func knownRegions() map[string]string {
+// KnownRegions returns a map of the Power VS region identifiers known to the installer to their description.
+func KnownRegions() map[string]string {
 
 	regions := make(map[string]string)
 
@@ -23,7 +24,7 @@ func knownRegions() map[string]string {
 
 // IsKnownRegion return true is a specified region is Known to the installer.
 func IsKnownRegion(region string) bool {
-	if _, ok := knownRegions()[region]; ok {
+	if _, ok := KnownRegions()[region]; ok {
 		return true
 	}
 	return false
@@ -35,7 +36,7 @@ func knownZones(region string) []string {
 
 // IsKnownZone return true is a specified zone is Known to the installer.
 func IsKnownZone(region string, zone string) bool {
-	if _, ok := knownRegions()[region]; ok {
+	if _, ok := KnownRegions()[region]; ok {
 		zones := knownZones(region)
 		for _, z := range zones {
 			if z == zone {
@@ -49,7 +50,7 @@ func IsKnownZone(region string, zone string) bool {
 
 // GetRegion prompts the user to select a region and returns that region.
 func GetRegion(defaultRegion string) (string, error) {
-	regions := knownRegions()
+	regions := KnownRegions()
 
 	longRegions := make([]string, 0, len(regions))
 	shortRegions := make([]string, 0, len(regions))
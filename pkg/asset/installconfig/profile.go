@@ -0,0 +1,123 @@
+package installconfig
+
+import (
+	"embed"
+	"encoding/json"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/installer/pkg/types"
+)
+
+// Profile is the name of a built-in profile, or the path of a profile file,
+// applied to the install config when it is generated.
+var Profile string
+
+// profiles holds the built-in profiles.
+//
+//go:embed profiles/*.yaml
+var profiles embed.FS
+
+// Profiles returns the names of the built-in profiles.
+func Profiles() []string {
+	entries, err := profiles.ReadDir("profiles")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadProfile returns the content of the built-in profile with the name or,
+// when there is none, of the profile file at the path.
+func loadProfile(profile string) ([]byte, error) {
+	if data, err := profiles.ReadFile(path.Join("profiles", profile+".yaml")); err == nil {
+		return data, nil
+	}
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("profile %q is neither a file nor one of %v", profile, Profiles())
+		}
+		return nil, errors.Wrapf(err, "failed to read profile %s", profile)
+	}
+	return data, nil
+}
+
+// ApplyProfile applies a profile to the config, before its defaults are set.
+//
+// A profile is a partial install config, e.g. the sizes of the machine pools
+// and the networking, whose fields replace the ones of the config. Lists are
+// replaced as a whole. The platform sections of the profile, at the top level
+// and in the machine pools, only apply to the config of the same platform, so
+// that a profile can give instance types for several platforms.
+func ApplyProfile(config *types.InstallConfig, profile string) error {
+	data, err := loadProfile(profile)
+	if err != nil {
+		return err
+	}
+	patch := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &patch); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal profile %s", profile)
+	}
+
+	platformName := config.Platform.Name()
+	filterPlatforms(patch, platformName)
+	if controlPlane, ok := patch["controlPlane"].(map[string]interface{}); ok {
+		filterPlatforms(controlPlane, platformName)
+	}
+	if compute, ok := patch["compute"].([]interface{}); ok {
+		for _, pool := range compute {
+			if pool, ok := pool.(map[string]interface{}); ok {
+				filterPlatforms(pool, platformName)
+			}
+		}
+	}
+
+	original, err := json.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal install config")
+	}
+	patchData, err := json.Marshal(patch)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal profile %s", profile)
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, patchData, types.InstallConfig{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply profile %s", profile)
+	}
+
+	applied := &types.InstallConfig{}
+	if err := yaml.UnmarshalStrict(patched, applied); err != nil {
+		return errors.Wrapf(err, "invalid profile %s", profile)
+	}
+	*config = *applied
+	return nil
+}
+
+// filterPlatforms removes the sections of the platform field of the object
+// which are not for the named platform.
+func filterPlatforms(obj map[string]interface{}, platformName string) {
+	platforms, ok := obj["platform"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for name := range platforms {
+		if name != platformName {
+			delete(platforms, name)
+		}
+	}
+	if len(platforms) == 0 {
+		delete(obj, "platform")
+	}
+}
@@ -0,0 +1,47 @@
+package installconfig
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	awsconfig "github.com/openshift/installer/pkg/asset/installconfig/aws"
+	azureconfig "github.com/openshift/installer/pkg/asset/installconfig/azure"
+	powervsconfig "github.com/openshift/installer/pkg/asset/installconfig/powervs"
+	"github.com/openshift/installer/pkg/types/aws"
+	"github.com/openshift/installer/pkg/types/azure"
+	"github.com/openshift/installer/pkg/types/gcp"
+	gcpvalidation "github.com/openshift/installer/pkg/types/gcp/validation"
+	"github.com/openshift/installer/pkg/types/ibmcloud"
+	ibmcloudvalidation "github.com/openshift/installer/pkg/types/ibmcloud/validation"
+	"github.com/openshift/installer/pkg/types/powervs"
+	"github.com/openshift/installer/pkg/version"
+)
+
+// KnownRegions returns a map of the regions known to the installer for the
+// platform to their description. Azure regions are queried from the API and
+// require credentials.
+func KnownRegions(ctx context.Context, platform string) (map[string]string, error) {
+	switch platform {
+	case aws.Name:
+		return awsconfig.KnownPublicRegions(version.DefaultArch()), nil
+	case azure.Name:
+		return azureconfig.KnownRegions(ctx)
+	case gcp.Name:
+		regions := make(map[string]string, len(gcpvalidation.Regions))
+		for id, description := range gcpvalidation.Regions {
+			regions[id] = description
+		}
+		return regions, nil
+	case ibmcloud.Name:
+		regions := make(map[string]string, len(ibmcloudvalidation.Regions))
+		for id, description := range ibmcloudvalidation.Regions {
+			regions[id] = description
+		}
+		return regions, nil
+	case powervs.Name:
+		return powervsconfig.KnownRegions(), nil
+	default:
+		return nil, errors.Errorf("listing regions is not supported for platform %q", platform)
+	}
+}
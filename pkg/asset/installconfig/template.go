@@ -0,0 +1,63 @@
+package installconfig
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ValuesFile is the path of a YAML file with the values of the Go template
+// directives in install-config.yaml, e.g. {{ .clusterName }}. When it is
+// set, install-config.yaml is rendered as a template against the values when
+// it is loaded, before it is validated.
+var ValuesFile string
+
+// templateFuncs are the functions available to install-config templates, in
+// addition to the builtin ones.
+var templateFuncs = template.FuncMap{
+	// toYaml returns the value marshaled as YAML, without the trailing newline.
+	"toYaml": func(value interface{}) (string, error) {
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(data), "\n"), nil
+	},
+	// indent indents every line of s with n spaces.
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+}
+
+// loadValues returns the values of ValuesFile.
+func loadValues() (map[string]interface{}, error) {
+	data, err := os.ReadFile(ValuesFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the values file")
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", ValuesFile)
+	}
+	return values, nil
+}
+
+// renderTemplate renders the install config template against the values.
+// Values missing from the values file are errors, rather than rendered as
+// empty strings which would only be caught, if at all, by the validation.
+func renderTemplate(data []byte, values map[string]interface{}) ([]byte, error) {
+	tmpl, err := template.New(installConfigFilename).Option("missingkey=error").Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the %s template", installConfigFilename)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return nil, errors.Wrapf(err, "failed to render the %s template", installConfigFilename)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,60 @@
+package installconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]interface{}{
+		"clusterName": "test-cluster",
+		"replicas":    3,
+		"machineNetwork": []interface{}{
+			map[string]interface{}{"cidr": "10.0.0.0/16"},
+		},
+	}
+	cases := []struct {
+		name          string
+		template      string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "no directives",
+			template: "baseDomain: example.com\n",
+			expected: "baseDomain: example.com\n",
+		},
+		{
+			name:     "values",
+			template: "metadata:\n  name: {{ .clusterName }}\ncontrolPlane:\n  replicas: {{ .replicas }}\n",
+			expected: "metadata:\n  name: test-cluster\ncontrolPlane:\n  replicas: 3\n",
+		},
+		{
+			name:     "yaml values",
+			template: "networking:\n  machineNetwork:\n{{ toYaml .machineNetwork | indent 2 }}\n",
+			expected: "networking:\n  machineNetwork:\n  - cidr: 10.0.0.0/16\n",
+		},
+		{
+			name:          "missing value",
+			template:      "baseDomain: {{ .baseDomain }}\n",
+			expectedError: `^failed to render the install-config.yaml template: template: install-config.yaml:1:15: executing "install-config.yaml" at <.baseDomain>: map has no entry for key "baseDomain"$`,
+		},
+		{
+			name:          "invalid template",
+			template:      "baseDomain: {{ .baseDomain\n",
+			expectedError: `^failed to parse the install-config.yaml template: `,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := renderTemplate([]byte(tc.template), values)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package alibabacloud
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -152,11 +153,16 @@ func tagsFromResourceTags(clusterID string, resourceTags map[string]string) ([]m
 	for idx := range tags {
 		forbiddenTags.Insert(tags[idx].Key)
 	}
-	for k, v := range resourceTags {
+	keys := make([]string, 0, len(resourceTags))
+	for k := range resourceTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
 		if forbiddenTags.Has(k) {
 			return nil, fmt.Errorf("user tags may not clobber %s", k)
 		}
-		tags = append(tags, machinev1.Tag{Key: k, Value: v})
+		tags = append(tags, machinev1.Tag{Key: k, Value: resourceTags[k]})
 	}
 	return tags, nil
 }
@@ -0,0 +1,101 @@
+package machineconfig
+
+import (
+	"path/filepath"
+	"strings"
+
+	butane "github.com/coreos/butane/config"
+	"github.com/coreos/butane/config/common"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+)
+
+const (
+	butaneVariant = "openshift"
+	roleLabel     = "machineconfiguration.openshift.io/role"
+)
+
+// butaneRoles are the roles that can be inferred from the name of a Butane config.
+var butaneRoles = []string{"master", "worker"}
+
+// ForButane transpiles the Butane config in the named file into a
+// MachineConfig. Only the openshift variant is supported, and warnings fail
+// the translation as with butane --strict. The name of the MachineConfig
+// defaults to the name of the file and its role, unless set by the metadata
+// labels of the config, is inferred from the name of the file, e.g.
+// 99-worker-chrony.bu.
+func ForButane(filename string, data []byte) (*mcfgv1.MachineConfig, error) {
+	config, err := butaneWithMetadata(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	out, report, err := butane.TranslateBytes(config, common.TranslateBytesOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to transpile Butane config %s", filename)
+	}
+	if len(report.Entries) > 0 {
+		return nil, errors.Errorf("failed to transpile Butane config %s: %s", filename, strings.TrimSpace(report.String()))
+	}
+
+	mc := &mcfgv1.MachineConfig{}
+	if err := yaml.Unmarshal(out, mc); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the MachineConfig transpiled from Butane config %s", filename)
+	}
+	return mc, nil
+}
+
+// butaneWithMetadata returns the Butane config in the named file with the
+// name and the role label of its metadata set, from the name of the file
+// when the config does not set them.
+func butaneWithMetadata(filename string, data []byte) ([]byte, error) {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse Butane config %s", filename)
+	}
+	if variant, _ := config["variant"].(string); variant != butaneVariant {
+		return nil, errors.Errorf("unsupported variant %q in Butane config %s, must be %q", config["variant"], filename, butaneVariant)
+	}
+
+	metadata, ok := config["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+	}
+	if name, _ := metadata["name"].(string); name == "" {
+		metadata["name"] = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	labels, ok := metadata["labels"].(map[string]interface{})
+	if !ok {
+		labels = map[string]interface{}{}
+	}
+	if role, _ := labels[roleLabel].(string); role == "" {
+		role = butaneRole(filename)
+		if role == "" {
+			return nil, errors.Errorf("cannot infer the role of Butane config %s, either set the %s label or include one of %s in the file name", filename, roleLabel, strings.Join(butaneRoles, ", "))
+		}
+		labels[roleLabel] = role
+	}
+	metadata["labels"] = labels
+	config["metadata"] = metadata
+
+	return yaml.Marshal(config)
+}
+
+// butaneRole returns the role named by one of the dash, underscore or dot
+// separated words of the file name, if any.
+func butaneRole(filename string) string {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	words := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for _, word := range words {
+		for _, role := range butaneRoles {
+			if word == role {
+				return role
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package machineconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestButaneWithMetadata(t *testing.T) {
+	cases := []struct {
+		name           string
+		filename       string
+		config         string
+		expectedConfig string
+		expectedErr    string
+	}{
+		{
+			name:     "name and role inferred from the file name",
+			filename: "butane/99-worker-chrony.bu",
+			config: `variant: openshift
+version: 4.14.0
+storage:
+  files:
+  - path: /etc/chrony.conf
+    mode: 0644
+    contents:
+      inline: |
+        server ntp.example.com iburst
+`,
+			expectedConfig: `variant: openshift
+version: 4.14.0
+metadata:
+  name: 99-worker-chrony
+  labels:
+    machineconfiguration.openshift.io/role: worker
+storage:
+  files:
+  - path: /etc/chrony.conf
+    mode: 420
+    contents:
+      inline: |
+        server ntp.example.com iburst
+`,
+		},
+		{
+			name:     "name and role from the metadata",
+			filename: "butane/kdump.bu",
+			config: `variant: openshift
+version: 4.14.0
+metadata:
+  name: 99-master-kdump
+  labels:
+    machineconfiguration.openshift.io/role: master
+    team: storage
+`,
+			expectedConfig: `variant: openshift
+version: 4.14.0
+metadata:
+  name: 99-master-kdump
+  labels:
+    machineconfiguration.openshift.io/role: master
+    team: storage
+`,
+		},
+		{
+			name:        "role cannot be inferred",
+			filename:    "butane/kdump.bu",
+			config:      "variant: openshift\nversion: 4.14.0\n",
+			expectedErr: `^cannot infer the role of Butane config butane/kdump.bu`,
+		},
+		{
+			name:        "unsupported variant",
+			filename:    "butane/99-worker-kdump.bu",
+			config:      "variant: fcos\nversion: 1.4.0\n",
+			expectedErr: `^unsupported variant "fcos" in Butane config butane/99-worker-kdump.bu, must be "openshift"$`,
+		},
+		{
+			name:        "invalid YAML",
+			filename:    "butane/99-worker-kdump.bu",
+			config:      "variant: [openshift\n",
+			expectedErr: `^failed to parse Butane config butane/99-worker-kdump.bu: `,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := butaneWithMetadata(tc.filename, []byte(tc.config))
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.YAMLEq(t, tc.expectedConfig, string(config))
+			}
+		})
+	}
+}
+
+func TestForButane(t *testing.T) {
+	mc, err := ForButane("butane/99-worker-chrony.bu", []byte(`variant: openshift
+version: 4.8.0
+storage:
+  files:
+  - path: /etc/chrony.conf
+    mode: 0644
+    overwrite: true
+    contents:
+      inline: |
+        server ntp.example.com iburst
+openshift:
+  kernel_arguments:
+  - nosmt
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "99-worker-chrony", mc.Name)
+	assert.Equal(t, "worker", mc.Labels[roleLabel])
+	assert.Equal(t, []string{"nosmt"}, mc.Spec.KernelArguments)
+	assert.Contains(t, string(mc.Spec.Config.Raw), `"path":"/etc/chrony.conf"`)
+
+	_, err = ForButane("butane/99-worker-kdump.bu", []byte("variant: openshift\nversion: 4.8.0\nunknown: true\n"))
+	assert.Regexp(t, `^failed to transpile Butane config butane/99-worker-kdump.bu: `, err)
+}
@@ -0,0 +1,86 @@
+package manifests
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/machines/machineconfig"
+)
+
+const (
+	butaneDir = "butane"
+)
+
+var (
+	_ asset.WritableAsset = (*ButaneConfigs)(nil)
+)
+
+// ButaneConfigs are the user-provided Butane configs in the butane directory
+// of the asset dir. Each config is transpiled into a MachineConfig manifest.
+type ButaneConfigs struct {
+	FileList []*asset.File
+}
+
+// Name returns a human friendly name for the asset.
+func (b *ButaneConfigs) Name() string {
+	return "Butane Configs"
+}
+
+// Dependencies returns no dependencies.
+func (b *ButaneConfigs) Dependencies() []asset.Asset {
+	return nil
+}
+
+// Generate generates no Butane configs.
+func (b *ButaneConfigs) Generate(asset.Parents) error {
+	b.FileList = nil
+	return nil
+}
+
+// Files returns the Butane config files.
+func (b *ButaneConfigs) Files() []*asset.File {
+	return b.FileList
+}
+
+// Load returns the Butane configs from the butane directory, if any.
+func (b *ButaneConfigs) Load(f asset.FileFetcher) (bool, error) {
+	fileList, err := f.FetchByPattern(filepath.Join(butaneDir, "*.bu"))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to load *.bu files")
+	}
+	if len(fileList) == 0 {
+		return false, nil
+	}
+
+	b.FileList = fileList
+	asset.SortFiles(b.FileList)
+	if _, err := b.MachineConfigFiles(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MachineConfigFiles returns the MachineConfig manifests transpiled from the
+// Butane configs.
+func (b *ButaneConfigs) MachineConfigFiles() ([]*asset.File, error) {
+	files := make([]*asset.File, 0, len(b.FileList))
+	for _, f := range b.FileList {
+		mc, err := machineconfig.ForButane(f.Filename, f.Data)
+		if err != nil {
+			return nil, err
+		}
+		data, err := yaml.Marshal(mc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal MachineConfig for %s", f.Filename)
+		}
+		files = append(files, &asset.File{
+			Filename: filepath.Join(openshiftManifestDir, fmt.Sprintf("99_butane_%s.yaml", mc.Name)),
+			Data:     data,
+		})
+	}
+	return files, nil
+}
@@ -107,6 +107,9 @@ func (i *Infrastructure) Generate(dependencies asset.Parents) error {
 			for k, v := range installConfig.Config.AWS.UserTags {
 				resourceTags = append(resourceTags, configv1.AWSResourceTag{Key: k, Value: v})
 			}
+			sort.Slice(resourceTags, func(i, j int) bool {
+				return resourceTags[i].Key < resourceTags[j].Key
+			})
 		}
 		config.Status.PlatformStatus.AWS = &configv1.AWSPlatformStatus{
 			Region:       installConfig.Config.Platform.AWS.Region,
@@ -147,6 +150,9 @@ func (i *Infrastructure) Generate(dependencies asset.Parents) error {
 			for k, v := range installConfig.Config.Azure.UserTags {
 				resourceTags = append(resourceTags, configv1.AzureResourceTag{Key: k, Value: v})
 			}
+			sort.Slice(resourceTags, func(i, j int) bool {
+				return resourceTags[i].Key < resourceTags[j].Key
+			})
 			config.Status.PlatformStatus.Azure.ResourceTags = resourceTags
 		}
 	case alibabacloud.Name:
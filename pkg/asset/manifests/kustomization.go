@@ -0,0 +1,339 @@
+package manifests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+const (
+	kustomizeDir = "kustomize"
+)
+
+var (
+	kustomizationFilenames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+	_ asset.WritableAsset = (*Kustomization)(nil)
+)
+
+// Kustomization is the user-provided kustomization in the kustomize directory
+// of the asset dir. Its patches are applied to the generated manifests and
+// openshift manifests before they are embedded into the bootstrap ignition.
+//
+// Only the patches of the kustomization are supported. Patches are applied as
+// strategic merge patches to the kinds whose schema is known and as JSON merge
+// patches to any other kind.
+type Kustomization struct {
+	FileList []*asset.File
+}
+
+// kustomization is the subset of the kustomization.yaml format supported by
+// the installer.
+type kustomization struct {
+	Patches               []kustomizationPatch `json:"patches,omitempty"`
+	PatchesStrategicMerge []string             `json:"patchesStrategicMerge,omitempty"`
+}
+
+// kustomizationPatch is a patch, either in a file or inline, and an optional
+// selector of the resources it applies to. Without a target, the patch
+// applies to the resource with its own apiVersion, kind, name and namespace.
+type kustomizationPatch struct {
+	Path   string                    `json:"path,omitempty"`
+	Patch  string                    `json:"patch,omitempty"`
+	Target *kustomizationPatchTarget `json:"target,omitempty"`
+}
+
+type kustomizationPatchTarget struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// resourceID identifies the resource of a manifest.
+type resourceID struct {
+	GroupVersionKind schema.GroupVersionKind
+	Name             string
+	Namespace        string
+}
+
+// Name returns a human friendly name for the asset.
+func (k *Kustomization) Name() string {
+	return "Kustomization"
+}
+
+// Dependencies returns no dependencies.
+func (k *Kustomization) Dependencies() []asset.Asset {
+	return nil
+}
+
+// Generate generates an empty kustomization, which leaves the manifests as
+// they are.
+func (k *Kustomization) Generate(asset.Parents) error {
+	k.FileList = nil
+	return nil
+}
+
+// Files returns the files of the kustomization.
+func (k *Kustomization) Files() []*asset.File {
+	return k.FileList
+}
+
+// Load returns the kustomization from the kustomize directory, if any.
+func (k *Kustomization) Load(f asset.FileFetcher) (bool, error) {
+	fileList, err := f.FetchByPattern(filepath.Join(kustomizeDir, "*"))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to load kustomization")
+	}
+	if len(fileList) == 0 {
+		return false, nil
+	}
+
+	k.FileList = fileList
+	asset.SortFiles(k.FileList)
+	if _, _, err := k.patches(); err != nil {
+		return false, errors.Wrap(err, "invalid kustomization")
+	}
+	return true, nil
+}
+
+// Apply applies the patches of the kustomization to the files, which are
+// modified in place.
+func (k *Kustomization) Apply(files []*asset.File) error {
+	if len(k.FileList) == 0 {
+		return nil
+	}
+	patches, targets, err := k.patches()
+	if err != nil {
+		return errors.Wrap(err, "invalid kustomization")
+	}
+
+	for _, file := range files {
+		ext := filepath.Ext(file.Filename)
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		docs, err := splitDocuments(file.Data)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", file.Filename)
+		}
+
+		patched := false
+		for i, doc := range docs {
+			id, err := resourceIDOf(doc)
+			if err != nil || id.GroupVersionKind.Kind == "" {
+				continue
+			}
+			for j, patch := range patches {
+				if !targets[j].matches(id) {
+					continue
+				}
+				logrus.Debugf("Applying kustomization patch to %s", file.Filename)
+				if docs[i], err = applyPatch(docs[i], patch, id.GroupVersionKind); err != nil {
+					return errors.Wrapf(err, "failed to patch %s", file.Filename)
+				}
+				patched = true
+			}
+		}
+		if !patched {
+			continue
+		}
+
+		if ext == ".json" && len(docs) == 1 {
+			file.Data = docs[0]
+			continue
+		}
+		var buf bytes.Buffer
+		for i, doc := range docs {
+			data, err := yaml.JSONToYAML(doc)
+			if err != nil {
+				return errors.Wrapf(err, "failed to marshal %s", file.Filename)
+			}
+			if i > 0 {
+				buf.WriteString("---\n")
+			}
+			buf.Write(data)
+		}
+		file.Data = buf.Bytes()
+	}
+	return nil
+}
+
+// patches returns the patches of the kustomization as JSON, along with the
+// target of each patch.
+func (k *Kustomization) patches() ([][]byte, []kustomizationPatchTarget, error) {
+	files := make(map[string][]byte, len(k.FileList))
+	for _, file := range k.FileList {
+		files[filepath.Base(file.Filename)] = file.Data
+	}
+
+	var config kustomization
+	found := false
+	for _, name := range kustomizationFilenames {
+		if data, ok := files[name]; ok {
+			if err := yaml.UnmarshalStrict(data, &config); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to unmarshal %s", name)
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil, errors.Errorf("%s directory does not contain a %s", kustomizeDir, kustomizationFilenames[0])
+	}
+
+	specs := config.Patches
+	for _, p := range config.PatchesStrategicMerge {
+		specs = append(specs, kustomizationPatch{Path: p})
+	}
+
+	patches := make([][]byte, 0, len(specs))
+	targets := make([]kustomizationPatchTarget, 0, len(specs))
+	for i, spec := range specs {
+		name := spec.Path
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		data := []byte(spec.Patch)
+		if spec.Path != "" {
+			var ok bool
+			if data, ok = files[path.Clean(spec.Path)]; !ok {
+				return nil, nil, errors.Errorf("patch %s not found in the %s directory", spec.Path, kustomizeDir)
+			}
+		}
+		patch, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to unmarshal patch %s", name)
+		}
+
+		target := spec.Target
+		if target == nil {
+			id, err := resourceIDOf(patch)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to read the resource of patch %s", name)
+			}
+			if id.GroupVersionKind.Kind == "" || id.Name == "" {
+				return nil, nil, errors.Errorf("patch %s must have a target, or a kind and a name", name)
+			}
+			target = &kustomizationPatchTarget{
+				Group:     id.GroupVersionKind.Group,
+				Version:   id.GroupVersionKind.Version,
+				Kind:      id.GroupVersionKind.Kind,
+				Name:      id.Name,
+				Namespace: id.Namespace,
+			}
+		}
+		patches = append(patches, patch)
+		targets = append(targets, *target)
+	}
+	return patches, targets, nil
+}
+
+func (t kustomizationPatchTarget) matches(id resourceID) bool {
+	return (t.Group == "" || t.Group == id.GroupVersionKind.Group) &&
+		(t.Version == "" || t.Version == id.GroupVersionKind.Version) &&
+		(t.Kind == "" || t.Kind == id.GroupVersionKind.Kind) &&
+		(t.Name == "" || t.Name == id.Name) &&
+		(t.Namespace == "" || t.Namespace == id.Namespace)
+}
+
+// splitDocuments returns the JSON of each document of a YAML or JSON file.
+func splitDocuments(data []byte) ([][]byte, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	var docs [][]byte
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		jsonDoc, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(jsonDoc, []byte("null")) {
+			// The document only holds comments.
+			continue
+		}
+		docs = append(docs, jsonDoc)
+	}
+}
+
+func resourceIDOf(doc []byte) (resourceID, error) {
+	var resource struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(doc, &resource); err != nil {
+		return resourceID{}, err
+	}
+	gv, err := schema.ParseGroupVersion(resource.APIVersion)
+	if err != nil {
+		return resourceID{}, err
+	}
+	return resourceID{
+		GroupVersionKind: gv.WithKind(resource.Kind),
+		Name:             resource.Metadata.Name,
+		Namespace:        resource.Metadata.Namespace,
+	}, nil
+}
+
+// applyPatch applies a strategic merge patch if the schema of the kind is
+// known, or a JSON merge patch otherwise.
+func applyPatch(doc, patch []byte, gvk schema.GroupVersionKind) ([]byte, error) {
+	if obj, err := manifestScheme.New(gvk); err == nil {
+		return strategicpatch.StrategicMergePatch(doc, patch, obj)
+	}
+
+	var original, patchObj interface{}
+	if err := json.Unmarshal(doc, &original); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &patchObj); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(original, patchObj))
+}
+
+// mergePatch applies a JSON merge patch as defined in RFC 7386.
+func mergePatch(original, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	originalMap, ok := original.(map[string]interface{})
+	if !ok {
+		originalMap = map[string]interface{}{}
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(originalMap, key)
+			continue
+		}
+		originalMap[key] = mergePatch(originalMap[key], value)
+	}
+	return originalMap
+}
@@ -0,0 +1,108 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+func TestKustomizationApply(t *testing.T) {
+	configMap := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cluster-config-v1
+  namespace: kube-system
+data:
+  install-config: foo
+`
+	infrastructure := `apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: cluster
+spec:
+  cloudConfig:
+    name: cloud-provider-config
+    key: config
+`
+	cases := []struct {
+		name          string
+		kustomization map[string]string
+		expected      map[string]string
+		expectedErr   string
+	}{
+		{
+			name: "strategic merge patch of a core kind",
+			kustomization: map[string]string{
+				"kustomization.yaml": "patchesStrategicMerge:\n- patch.yaml\n",
+				"patch.yaml":         "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cluster-config-v1\n  namespace: kube-system\ndata:\n  extra: bar\n",
+			},
+			expected: map[string]string{
+				"manifests/cluster-config.yaml": `apiVersion: v1
+data:
+  extra: bar
+  install-config: foo
+kind: ConfigMap
+metadata:
+  name: cluster-config-v1
+  namespace: kube-system
+`,
+				"manifests/cluster-infrastructure-02-config.yml": infrastructure,
+			},
+		},
+		{
+			name: "merge patch with a target",
+			kustomization: map[string]string{
+				"kustomization.yaml": "patches:\n- target:\n    kind: Infrastructure\n  patch: |\n    spec:\n      cloudConfig: null\n",
+			},
+			expected: map[string]string{
+				"manifests/cluster-config.yaml": configMap,
+				"manifests/cluster-infrastructure-02-config.yml": `apiVersion: config.openshift.io/v1
+kind: Infrastructure
+metadata:
+  name: cluster
+spec: {}
+`,
+			},
+		},
+		{
+			name: "patch without a resource",
+			kustomization: map[string]string{
+				"kustomization.yaml": "patches:\n- patch: |\n    spec: {}\n",
+			},
+			expectedErr: `^invalid kustomization: patch #1 must have a target, or a kind and a name$`,
+		},
+		{
+			name: "missing patch file",
+			kustomization: map[string]string{
+				"kustomization.yaml": "patchesStrategicMerge:\n- missing.yaml\n",
+			},
+			expectedErr: `^invalid kustomization: patch missing.yaml not found in the kustomize directory$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			k := &Kustomization{}
+			for name, data := range tc.kustomization {
+				k.FileList = append(k.FileList, &asset.File{Filename: "kustomize/" + name, Data: []byte(data)})
+			}
+			files := []*asset.File{
+				{Filename: "manifests/cluster-config.yaml", Data: []byte(configMap)},
+				{Filename: "manifests/cluster-infrastructure-02-config.yml", Data: []byte(infrastructure)},
+			}
+
+			err := k.Apply(files)
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			actual := map[string]string{}
+			for _, f := range files {
+				actual[f.Filename] = string(f.Data)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
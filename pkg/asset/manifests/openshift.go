@@ -65,6 +65,8 @@ func (o *Openshift) Dependencies() []asset.Asset {
 		&password.KubeadminPassword{},
 		&openshiftinstall.Config{},
 		&FeatureGate{},
+		&Kustomization{},
+		&ButaneConfigs{},
 
 		&openshift.CloudCredsSecret{},
 		&openshift.KubeadminPasswordSecret{},
@@ -82,7 +84,9 @@ func (o *Openshift) Generate(dependencies asset.Parents) error {
 	kubeadminPassword := &password.KubeadminPassword{}
 	openshiftInstall := &openshiftinstall.Config{}
 	featureGate := &FeatureGate{}
-	dependencies.Get(installConfig, kubeadminPassword, clusterID, openshiftInstall, featureGate)
+	kustomization := &Kustomization{}
+	butaneConfigs := &ButaneConfigs{}
+	dependencies.Get(installConfig, kubeadminPassword, clusterID, openshiftInstall, featureGate, kustomization, butaneConfigs)
 	var cloudCreds cloudCredsSecretData
 	platform := installConfig.Config.Platform.Name()
 	switch platform {
@@ -315,6 +319,15 @@ func (o *Openshift) Generate(dependencies asset.Parents) error {
 	o.FileList = append(o.FileList, openshiftInstall.Files()...)
 	o.FileList = append(o.FileList, featureGate.Files()...)
 
+	butaneFiles, err := butaneConfigs.MachineConfigFiles()
+	if err != nil {
+		return err
+	}
+	o.FileList = append(o.FileList, butaneFiles...)
+
+	if err := kustomization.Apply(o.FileList); err != nil {
+		return errors.Wrap(err, "failed to apply kustomization")
+	}
 	asset.SortFiles(o.FileList)
 
 	return nil
@@ -350,6 +363,10 @@ func (o *Openshift) Load(f asset.FileFetcher) (bool, error) {
 		o.FileList = append(o.FileList, file)
 	}
 
+	if err := validateManifests(o.FileList); err != nil {
+		return false, err
+	}
+
 	asset.SortFiles(o.FileList)
 	return len(o.FileList) > 0, nil
 }
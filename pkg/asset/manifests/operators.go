@@ -66,6 +66,7 @@ func (m *Manifests) Dependencies() []asset.Asset {
 		&ImageDigestMirrorSet{},
 		&tls.RootCA{},
 		&tls.MCSCertKey{},
+		&Kustomization{},
 
 		&bootkube.CVOOverrides{},
 		&bootkube.KubeCloudConfig{},
@@ -87,8 +88,9 @@ func (m *Manifests) Generate(dependencies asset.Parents) error {
 	imageContentSourcePolicy := &ImageContentSourcePolicy{}
 	clusterCSIDriverConfig := &ClusterCSIDriverConfig{}
 	imageDigestMirrorSet := &ImageDigestMirrorSet{}
+	kustomization := &Kustomization{}
 
-	dependencies.Get(installConfig, ingress, dns, network, infra, proxy, scheduler, imageContentSourcePolicy, imageDigestMirrorSet, clusterCSIDriverConfig)
+	dependencies.Get(installConfig, ingress, dns, network, infra, proxy, scheduler, imageContentSourcePolicy, imageDigestMirrorSet, clusterCSIDriverConfig, kustomization)
 
 	redactedConfig, err := redactedInstallConfig(*installConfig.Config)
 	if err != nil {
@@ -126,6 +128,9 @@ func (m *Manifests) Generate(dependencies asset.Parents) error {
 	m.FileList = append(m.FileList, clusterCSIDriverConfig.Files()...)
 	m.FileList = append(m.FileList, imageDigestMirrorSet.Files()...)
 
+	if err := kustomization.Apply(m.FileList); err != nil {
+		return errors.Wrap(err, "failed to apply kustomization")
+	}
 	asset.SortFiles(m.FileList)
 
 	return nil
@@ -208,6 +213,9 @@ func (m *Manifests) Load(f asset.FileFetcher) (bool, error) {
 	if len(fileList) == 0 {
 		return false, nil
 	}
+	if err := validateManifests(fileList); err != nil {
+		return false, err
+	}
 
 	kubeSysConfig := &configurationObject{}
 	var found bool
@@ -0,0 +1,97 @@
+package manifests
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/installer/pkg/asset"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+)
+
+// manifestScheme holds the kinds whose schema is known to the installer.
+// Manifests of any other kind are only checked for the fields every
+// Kubernetes object must have.
+var manifestScheme = func() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(configv1.Install(scheme))
+	utilruntime.Must(operatorv1.Install(scheme))
+	utilruntime.Must(mcfgv1.Install(scheme))
+	return scheme
+}()
+
+// validateManifests checks that every document in the manifest files is a
+// Kubernetes object and, for the kinds known to the installer, that it
+// matches the schema of the kind. The fields unknown to the schema are only
+// reported as warnings. Invalid manifests would otherwise only be
+// reported, if at all, when the bootstrap node fails to apply them.
+func validateManifests(files []*asset.File) error {
+	allErrs := field.ErrorList{}
+	for _, file := range files {
+		switch filepath.Ext(file.Filename) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		docs, err := splitDocuments(file.Data)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath(file.Filename), "", err.Error()))
+			continue
+		}
+		for i, doc := range docs {
+			fldPath := field.NewPath(file.Filename)
+			if len(docs) > 1 {
+				fldPath = fldPath.Index(i)
+			}
+			allErrs = append(allErrs, validateManifest(doc, fldPath)...)
+		}
+	}
+	if len(allErrs) > 0 {
+		return errors.Wrap(allErrs.ToAggregate(), "invalid manifests")
+	}
+	return nil
+}
+
+func validateManifest(doc []byte, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	id, err := resourceIDOf(doc)
+	if err != nil {
+		return append(allErrs, field.Invalid(fldPath, "", err.Error()))
+	}
+	if id.GroupVersionKind.Version == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("apiVersion"), "manifests must be Kubernetes objects"))
+	}
+	if id.GroupVersionKind.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), "manifests must be Kubernetes objects"))
+	}
+	if id.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("metadata", "name"), "manifests must be Kubernetes objects"))
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	obj, err := manifestScheme.New(id.GroupVersionKind)
+	if err != nil {
+		// No schema is known for the kind.
+		return allErrs
+	}
+	if err := yaml.Unmarshal(doc, obj); err != nil {
+		return append(allErrs, field.Invalid(fldPath, id.GroupVersionKind.Kind, err.Error()))
+	}
+	// The unknown fields may be those of a newer version of the kind than
+	// the one known to the installer, so they are left to the API server.
+	if err := yaml.UnmarshalStrict(doc, obj); err != nil {
+		logrus.Warnf("%s: %s: %v", fldPath, id.GroupVersionKind.Kind, err)
+	}
+	return allErrs
+}
@@ -0,0 +1,102 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/asset"
+)
+
+func TestValidateManifests(t *testing.T) {
+	cases := []struct {
+		name            string
+		filename        string
+		data            string
+		expectedErr     string
+		expectedWarning string
+	}{
+		{
+			name:     "valid machine config",
+			filename: "manifests/99-worker-kargs.yaml",
+			data: `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-kargs
+  labels:
+    machineconfiguration.openshift.io/role: worker
+spec:
+  kernelArguments:
+  - nosmt
+`,
+		},
+		{
+			name:     "unknown field in machine config",
+			filename: "manifests/99-worker-kargs.yaml",
+			data: `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-kargs
+spec:
+  kernelArgs:
+  - nosmt
+`,
+			expectedWarning: `^manifests/99-worker-kargs.yaml: MachineConfig: .*unknown field "kernelArgs"$`,
+		},
+		{
+			name:     "wrong type in network policy",
+			filename: "manifests/deny-all.yaml",
+			data: `apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-all
+  namespace: default
+spec:
+  podSelector: []
+`,
+			expectedErr: `^invalid manifests: manifests/deny-all.yaml: Invalid value: "NetworkPolicy": .*cannot unmarshal array`,
+		},
+		{
+			name:     "unknown kind",
+			filename: "manifests/custom.yaml",
+			data: `apiVersion: example.com/v1
+kind: Custom
+metadata:
+  name: custom
+spec:
+  anything: goes
+`,
+		},
+		{
+			name:        "not an object",
+			filename:    "manifests/multi.yaml",
+			data:        "# comment only\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\nfoo: bar\n",
+			expectedErr: `^invalid manifests: \[manifests/multi.yaml\[1\].apiVersion: Required value: manifests must be Kubernetes objects, manifests/multi.yaml\[1\].kind: Required value: manifests must be Kubernetes objects, manifests/multi.yaml\[1\].metadata.name: Required value: manifests must be Kubernetes objects\]$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			hook := logrustest.NewGlobal()
+			defer hook.Reset()
+			err := validateManifests([]*asset.File{{Filename: tc.filename, Data: []byte(tc.data)}})
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedErr, err)
+			}
+			var warnings []string
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel {
+					warnings = append(warnings, entry.Message)
+				}
+			}
+			if tc.expectedWarning == "" {
+				assert.Empty(t, warnings)
+			} else if assert.Len(t, warnings, 1) {
+				assert.Regexp(t, tc.expectedWarning, warnings[0])
+			}
+		})
+	}
+}
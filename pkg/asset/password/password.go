@@ -6,14 +6,20 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/reproducible"
 )
 
 var (
 	// kubeadminPasswordPath is the path where kubeadmin user password is stored.
 	kubeadminPasswordPath = filepath.Join("auth", "kubeadmin-password")
+
+	// kubeadminPasswordHashPath is the path where a predefined hash of the
+	// kubeadmin user password is loaded from.
+	kubeadminPasswordHashPath = filepath.Join("tls", "kubeadmin-password.hash")
 )
 
 // KubeadminPassword is the asset for the kubeadmin user password
@@ -32,6 +38,9 @@ func (a *KubeadminPassword) Dependencies() []asset.Asset {
 
 // Generate the kubeadmin password
 func (a *KubeadminPassword) Generate(asset.Parents) error {
+	if reproducible.Enabled() {
+		logrus.Warnf("Generating a new kubeadmin password, provide %s in the assets directory to generate the kubeadmin secret reproducibly", kubeadminPasswordHashPath)
+	}
 	err := a.generateRandomPasswordHash(23)
 	if err != nil {
 		return err
@@ -103,8 +112,7 @@ func (a *KubeadminPassword) Files() []*asset.File {
 
 // Load loads a predefined hash only, if one is supplied
 func (a *KubeadminPassword) Load(f asset.FileFetcher) (found bool, err error) {
-	hashFilePath := filepath.Join("tls", "kubeadmin-password.hash")
-	hashFile, err := f.FetchByName(hashFilePath)
+	hashFile, err := f.FetchByName(kubeadminPasswordHashPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return false, nil
@@ -56,6 +56,10 @@ func (a *PlatformQuotaCheck) Dependencies() []asset.Asset {
 
 // Generate queries for input from the user.
 func (a *PlatformQuotaCheck) Generate(dependencies asset.Parents) error {
+	return errors.Wrap(a.generate(dependencies), asset.PreflightCheckError)
+}
+
+func (a *PlatformQuotaCheck) generate(dependencies asset.Parents) error {
 	ic := &installconfig.InstallConfig{}
 	mastersAsset := &machines.Master{}
 	workersAsset := &machines.Worker{}
@@ -0,0 +1,47 @@
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+	"github.com/openshift/installer/pkg/asset/machines"
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/none"
+)
+
+func TestPlatformQuotaCheckGenerate(t *testing.T) {
+	cases := []struct {
+		name        string
+		platform    types.Platform
+		expectedErr string
+	}{
+		{
+			name:     "no quota to check",
+			platform: types.Platform{None: &none.Platform{}},
+		},
+		{
+			name:        "unknown platform",
+			expectedErr: `^failed preflight checks: unknown platform type ""$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parents := asset.Parents{}
+			parents.Add(
+				installconfig.MakeAsset(&types.InstallConfig{Platform: tc.platform}),
+				&machines.Master{},
+				&machines.Worker{},
+			)
+
+			err := (&PlatformQuotaCheck{}).Generate(parents)
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
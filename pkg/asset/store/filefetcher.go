@@ -1,10 +1,10 @@
 package store
 
 import (
-	"os"
 	"path/filepath"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/statecrypt"
 )
 
 type fileFetcher struct {
@@ -13,7 +13,7 @@ type fileFetcher struct {
 
 // FetchByName returns the file with the given name.
 func (f *fileFetcher) FetchByName(name string) (*asset.File, error) {
-	data, err := os.ReadFile(filepath.Join(f.directory, name))
+	data, err := statecrypt.ReadFile(filepath.Join(f.directory, name))
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +29,7 @@ func (f *fileFetcher) FetchByPattern(pattern string) (files []*asset.File, err e
 
 	files = make([]*asset.File, 0, len(matches))
 	for _, path := range matches {
-		data, err := os.ReadFile(path)
+		data, err := statecrypt.ReadFile(path)
 		if err != nil {
 			return nil, err
 		}
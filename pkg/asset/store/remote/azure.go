@@ -0,0 +1,66 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+type azureBackend struct {
+	location *location
+	client   *azblob.Client
+}
+
+func newAzureBackend(loc *location) (*azureBackend, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", loc.account), cred, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &azureBackend{location: loc, client: client}, nil
+}
+
+func (b *azureBackend) List(ctx context.Context) ([]string, error) {
+	var names []string
+	options := &azblob.ListBlobsFlatOptions{}
+	if b.location.prefix != "" {
+		prefix := b.location.prefix + "/"
+		options.Prefix = &prefix
+	}
+	pager := b.client.NewListBlobsFlatPager(b.location.bucket, options)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range page.Segment.BlobItems {
+			if blob.Name == nil {
+				continue
+			}
+			if name, ok := b.location.relativeName(*blob.Name); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+func (b *azureBackend) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := b.client.DownloadStream(ctx, b.location.bucket, b.location.objectName(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (b *azureBackend) Put(ctx context.Context, name string, data []byte) error {
+	_, err := b.client.UploadBuffer(ctx, b.location.bucket, b.location.objectName(name), data, nil)
+	return err
+}
@@ -0,0 +1,66 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/api/option"
+	storage "google.golang.org/api/storage/v1"
+
+	gcpconfig "github.com/openshift/installer/pkg/asset/installconfig/gcp"
+	"github.com/openshift/installer/pkg/version"
+)
+
+type gcsBackend struct {
+	location *location
+	service  *storage.Service
+}
+
+func newGCSBackend(ctx context.Context, loc *location) (*gcsBackend, error) {
+	ssn, err := gcpconfig.GetSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	service, err := storage.NewService(ctx,
+		option.WithCredentials(ssn.Credentials),
+		option.WithUserAgent(fmt.Sprintf("OpenShift/4.x Installer/%s", version.Raw)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &gcsBackend{location: loc, service: service}, nil
+}
+
+func (b *gcsBackend) List(ctx context.Context) ([]string, error) {
+	var names []string
+	call := b.service.Objects.List(b.location.bucket).Context(ctx)
+	if b.location.prefix != "" {
+		call = call.Prefix(b.location.prefix + "/")
+	}
+	err := call.Pages(ctx, func(objects *storage.Objects) error {
+		for _, object := range objects.Items {
+			if name, ok := b.location.relativeName(object.Name); ok {
+				names = append(names, name)
+			}
+		}
+		return nil
+	})
+	return names, err
+}
+
+func (b *gcsBackend) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := b.service.Objects.Get(b.location.bucket, b.location.objectName(name)).Context(ctx).Download()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (b *gcsBackend) Put(ctx context.Context, name string, data []byte) error {
+	object := &storage.Object{Name: b.location.objectName(name)}
+	_, err := b.service.Objects.Insert(b.location.bucket, object).Media(bytes.NewReader(data)).Context(ctx).Do()
+	return err
+}
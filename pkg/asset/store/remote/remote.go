@@ -0,0 +1,179 @@
+// Package remote mirrors an asset directory to and from an object store, so
+// that the installer state can be shared between hosts.
+package remote
+
+import (
+	"context"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Backend is an object store holding the files of an asset directory.
+type Backend interface {
+	// List returns the names of all the objects, relative to the location.
+	List(ctx context.Context) ([]string, error)
+	// Get returns the contents of the named object.
+	Get(ctx context.Context, name string) ([]byte, error)
+	// Put creates or replaces the named object.
+	Put(ctx context.Context, name string, data []byte) error
+}
+
+// location is an object store location parsed from a state URL.
+type location struct {
+	scheme string
+	// account is only set for Azure, where it is the storage account.
+	account string
+	bucket  string
+	prefix  string
+}
+
+// parseURL parses a state URL of the form s3://<bucket>/<prefix>,
+// gs://<bucket>/<prefix> or azblob://<account>/<container>/<prefix>.
+func parseURL(rawURL string) (*location, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid state URL %q", rawURL)
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("invalid state URL %q, a bucket is required", rawURL)
+	}
+	loc := &location{scheme: u.Scheme, bucket: u.Host, prefix: strings.Trim(u.Path, "/")}
+	switch u.Scheme {
+	case "s3", "gs":
+	case "azblob":
+		parts := strings.SplitN(loc.prefix, "/", 2)
+		if parts[0] == "" {
+			return nil, errors.Errorf("invalid state URL %q, a container is required", rawURL)
+		}
+		loc.account, loc.bucket, loc.prefix = u.Host, parts[0], ""
+		if len(parts) == 2 {
+			loc.prefix = parts[1]
+		}
+	default:
+		return nil, errors.Errorf("invalid state URL %q, the scheme must be s3, gs or azblob", rawURL)
+	}
+	return loc, nil
+}
+
+// objectName returns the name of the object for the name relative to the
+// location.
+func (l *location) objectName(name string) string {
+	if l.prefix == "" {
+		return name
+	}
+	return path.Join(l.prefix, name)
+}
+
+// relativeName returns the name relative to the location of the object, and
+// false if the object is outside of the location.
+func (l *location) relativeName(object string) (string, bool) {
+	if l.prefix == "" {
+		return object, true
+	}
+	if !strings.HasPrefix(object, l.prefix+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(object, l.prefix+"/"), true
+}
+
+// New returns the backend for the state URL.
+func New(ctx context.Context, rawURL string) (Backend, error) {
+	loc, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch loc.scheme {
+	case "s3":
+		return newS3Backend(ctx, loc)
+	case "gs":
+		return newGCSBackend(ctx, loc)
+	default:
+		return newAzureBackend(loc)
+	}
+}
+
+// stateFileName is the name of the state file of the asset store.
+const stateFileName = ".openshift_install_state.json"
+
+// isStateFile returns whether the file, relative to the asset directory, is
+// needed by the create, wait-for and destroy commands on another host: the
+// state file, the metadata, the admin kubeconfig and the terraform states.
+// The log and the other credentials, e.g. the kubeadmin password, are never
+// mirrored.
+func isStateFile(name string) bool {
+	switch name {
+	case stateFileName, "metadata.json", "auth/kubeconfig":
+		return true
+	}
+	return !strings.Contains(name, "/") && strings.HasPrefix(name, "terraform.") && strings.HasSuffix(name, ".tfstate")
+}
+
+// Pull downloads the state files of the backend into the directory,
+// replacing the files already there.
+func Pull(ctx context.Context, backend Backend, directory string) error {
+	names, err := backend.List(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the remote state")
+	}
+	count := 0
+	for _, name := range names {
+		if !isStateFile(name) {
+			continue
+		}
+		data, err := backend.Get(ctx, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to download %s", name)
+		}
+		filePath := filepath.Join(directory, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0750); err != nil {
+			return errors.Wrap(err, "failed to create dir")
+		}
+		if err := os.WriteFile(filePath, data, 0o600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", name)
+		}
+		count++
+	}
+	logrus.Debugf("Downloaded %d files from the remote state", count)
+	return nil
+}
+
+// Push uploads the state files of the directory to the backend. Objects of
+// the backend are never deleted, even when their file is no longer in the
+// directory.
+func Push(ctx context.Context, backend Backend, directory string) error {
+	count := 0
+	err := filepath.WalkDir(directory, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !isStateFile(name) {
+			return nil
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if err := backend.Put(ctx, name, data); err != nil {
+			return errors.Wrapf(err, "failed to upload %s", name)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("Uploaded %d files to the remote state", count)
+	return nil
+}
@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryBackend map[string][]byte
+
+func (b memoryBackend) List(context.Context) ([]string, error) {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b memoryBackend) Get(_ context.Context, name string) ([]byte, error) {
+	return b[name], nil
+}
+
+func (b memoryBackend) Put(_ context.Context, name string, data []byte) error {
+	b[name] = data
+	return nil
+}
+
+func TestParseURL(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected *location
+		err      string
+	}{
+		{
+			url:      "s3://bucket/some/prefix/",
+			expected: &location{scheme: "s3", bucket: "bucket", prefix: "some/prefix"},
+		},
+		{
+			url:      "gs://bucket",
+			expected: &location{scheme: "gs", bucket: "bucket"},
+		},
+		{
+			url:      "azblob://account/container/prefix",
+			expected: &location{scheme: "azblob", account: "account", bucket: "container", prefix: "prefix"},
+		},
+		{
+			url: "azblob://account",
+			err: `^invalid state URL "azblob://account", a container is required$`,
+		},
+		{
+			url: "file:///tmp/state",
+			err: `^invalid state URL "file:///tmp/state", a bucket is required$`,
+		},
+		{
+			url: "http://bucket/prefix",
+			err: `^invalid state URL "http://bucket/prefix", the scheme must be s3, gs or azblob$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			loc, err := parseURL(tc.url)
+			if tc.err != "" {
+				assert.Regexp(t, tc.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, loc)
+		})
+	}
+}
+
+func TestLocationNames(t *testing.T) {
+	loc := &location{prefix: "cluster"}
+	assert.Equal(t, "cluster/auth/kubeconfig", loc.objectName("auth/kubeconfig"))
+
+	name, ok := loc.relativeName("cluster/auth/kubeconfig")
+	assert.True(t, ok)
+	assert.Equal(t, "auth/kubeconfig", name)
+
+	_, ok = loc.relativeName("cluster2/metadata.json")
+	assert.False(t, ok)
+}
+
+func TestIsStateFile(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected bool
+	}{
+		{name: ".openshift_install_state.json", expected: true},
+		{name: "metadata.json", expected: true},
+		{name: "auth/kubeconfig", expected: true},
+		{name: "terraform.tfstate", expected: true},
+		{name: "terraform.bootstrap.tfstate", expected: true},
+		{name: "auth/kubeadmin-password"},
+		{name: ".openshift_install.log"},
+		{name: "install-config.yaml"},
+		{name: "bootstrap.ign"},
+		{name: "backup/terraform.tfstate"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isStateFile(tc.name))
+		})
+	}
+}
+
+func TestPushPull(t *testing.T) {
+	backend := memoryBackend{"install-config.yaml": []byte("consumed")}
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "auth"), 0o750))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "metadata.json"), []byte("metadata"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "auth", "kubeconfig"), []byte("kubeconfig"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "auth", "kubeadmin-password"), []byte("password"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".openshift_install.log"), []byte("log"), 0o600))
+
+	assert.NoError(t, Push(context.Background(), backend, dir))
+	assert.Equal(t, memoryBackend{
+		"install-config.yaml": []byte("consumed"),
+		"metadata.json":       []byte("metadata"),
+		"auth/kubeconfig":     []byte("kubeconfig"),
+	}, backend)
+
+	pulled := t.TempDir()
+	assert.NoError(t, Pull(context.Background(), backend, pulled))
+	data, err := os.ReadFile(filepath.Join(pulled, "auth", "kubeconfig"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("kubeconfig"), data)
+	_, err = os.Stat(filepath.Join(pulled, "install-config.yaml"))
+	assert.True(t, os.IsNotExist(err), "only the state files must be pulled")
+}
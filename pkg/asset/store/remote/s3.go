@@ -0,0 +1,72 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+
+	awsconfig "github.com/openshift/installer/pkg/asset/installconfig/aws"
+)
+
+type s3Backend struct {
+	location *location
+	client   *s3.S3
+}
+
+func newS3Backend(ctx context.Context, loc *location) (*s3Backend, error) {
+	ssn, err := awsconfig.GetSession()
+	if err != nil {
+		return nil, err
+	}
+	region, err := s3manager.GetBucketRegion(ctx, ssn, loc.bucket, "us-east-1")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the region of bucket %s", loc.bucket)
+	}
+	return &s3Backend{
+		location: loc,
+		client:   s3.New(ssn, aws.NewConfig().WithRegion(region)),
+	}, nil
+}
+
+func (b *s3Backend) List(ctx context.Context) ([]string, error) {
+	var names []string
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(b.location.bucket)}
+	if b.location.prefix != "" {
+		input.Prefix = aws.String(b.location.prefix + "/")
+	}
+	err := b.client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			if name, ok := b.location.relativeName(aws.StringValue(object.Key)); ok {
+				names = append(names, name)
+			}
+		}
+		return true
+	})
+	return names, err
+}
+
+func (b *s3Backend) Get(ctx context.Context, name string) ([]byte, error) {
+	output, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.location.bucket),
+		Key:    aws.String(b.location.objectName(name)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+func (b *s3Backend) Put(ctx context.Context, name string, data []byte) error {
+	_, err := b.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.location.bucket),
+		Key:    aws.String(b.location.objectName(name)),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
@@ -5,17 +5,26 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/metrics/progress"
+	"github.com/openshift/installer/pkg/statecrypt"
 )
 
 const (
 	stateFileName = ".openshift_install_state.json"
 )
 
+// ConcurrentGeneration makes the stores generate the independent dependencies
+// of an asset concurrently. Interactive assets are still generated one at a
+// time so that their prompts are never interleaved.
+var ConcurrentGeneration bool
+
 // assetSource indicates from where the asset was fetched
 type assetSource int
 
@@ -41,6 +50,18 @@ type assetState struct {
 	// presentOnDisk is true if the asset in on-disk. This is set whether the
 	// asset is sourced from on-disk or not. It is used in purging consumed assets.
 	presentOnDisk bool
+	// generation is the in-progress generation of the asset, if any.
+	generation *generation
+}
+
+// generation tracks an asset that is being generated so that concurrent
+// fetches of the same asset wait for it instead of generating it again.
+type generation struct {
+	// done is closed once the generation has finished.
+	done chan struct{}
+	// err is the error from the generation. It must only be read after done
+	// is closed.
+	err error
 }
 
 // storeImpl is the implementation of Store.
@@ -49,6 +70,14 @@ type storeImpl struct {
 	assets          map[reflect.Type]*assetState
 	stateFileAssets map[string]json.RawMessage
 	fileFetcher     asset.FileFetcher
+
+	// mu guards the assetStates while assets are generated concurrently.
+	mu sync.Mutex
+	// workers bounds the number of assets that are generated at once. When
+	// nil, dependencies are fetched one at a time, in order.
+	workers chan struct{}
+	// interactive serializes the generation of the interactive assets.
+	interactive sync.Mutex
 }
 
 // NewStore returns an asset store that implements the asset.Store interface.
@@ -62,6 +91,9 @@ func newStore(dir string) (*storeImpl, error) {
 		fileFetcher: &fileFetcher{directory: dir},
 		assets:      map[reflect.Type]*assetState{},
 	}
+	if ConcurrentGeneration {
+		store.workers = make(chan struct{}, runtime.GOMAXPROCS(0))
+	}
 
 	if err := store.loadStateFile(); err != nil {
 		return nil, err
@@ -69,6 +101,21 @@ func newStore(dir string) (*storeImpl, error) {
 	return store, nil
 }
 
+// CopyState writes the state file found in the src directory to the dst
+// directory, leaving out the given assets so that fetching them from a store
+// in dst regenerates them from their parents.
+func CopyState(src, dst string, exclude ...asset.Asset) error {
+	s, err := newStore(src)
+	if err != nil {
+		return err
+	}
+	for _, a := range exclude {
+		delete(s.stateFileAssets, reflect.TypeOf(a).String())
+	}
+	s.directory = dst
+	return s.saveStateFile()
+}
+
 // Fetch retrieves the state of the given asset, generating it and its
 // dependencies if necessary. When purging consumed assets, none of the
 // assets in preserved will be purged.
@@ -136,6 +183,10 @@ func (s *storeImpl) loadStateFile() error {
 		}
 		return err
 	}
+	data, err = statecrypt.Decrypt(data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decrypt state file %q", path)
+	}
 	err = json.Unmarshal(data, &assets)
 	if err != nil {
 		return errors.Wrapf(err, "failed to unmarshal state file %q", path)
@@ -179,6 +230,11 @@ func (s *storeImpl) saveStateFile() error {
 		return err
 	}
 
+	data, err = statecrypt.Encrypt(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt state file")
+	}
+
 	path := filepath.Join(s.directory, stateFileName)
 	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
 		return err
@@ -193,41 +249,114 @@ func (s *storeImpl) saveStateFile() error {
 // necessary, and returns whether or not the asset had to be regenerated and
 // any errors.
 func (s *storeImpl) fetch(a asset.Asset, indent string) error {
-	logrus.Debugf("%sFetching %s...", indent, a.Name())
-
-	assetState, ok := s.assets[reflect.TypeOf(a)]
-	if !ok {
+	if _, ok := s.assets[reflect.TypeOf(a)]; !ok {
 		if _, err := s.load(a, ""); err != nil {
 			return err
 		}
-		assetState = s.assets[reflect.TypeOf(a)]
 	}
+	return s.fetchLoaded(a, indent, s.workers == nil)
+}
+
+// fetchLoaded populates the given asset, which must have been loaded along
+// with all of its ancestors. Independent dependencies are fetched
+// concurrently unless serial is set.
+func (s *storeImpl) fetchLoaded(a asset.Asset, indent string, serial bool) error {
+	logrus.Debugf("%sFetching %s...", indent, a.Name())
+
+	s.mu.Lock()
+	assetState := s.assets[reflect.TypeOf(a)]
 
 	// Return immediately if the asset has been fetched before,
 	// this is because we are doing a depth-first-search, it's guaranteed
 	// that we always fetch the parent before children, so we don't need
 	// to worry about invalidating anything in the cache.
 	if assetState.source != unfetched {
+		s.mu.Unlock()
 		logrus.Debugf("%sReusing previously-fetched %s", indent, a.Name())
 		reflect.ValueOf(a).Elem().Set(reflect.ValueOf(assetState.asset).Elem())
 		return nil
 	}
 
-	// Re-generate the asset
+	// Wait for the asset if it is already being generated on behalf of
+	// another dependent.
+	if gen := assetState.generation; gen != nil {
+		s.mu.Unlock()
+		<-gen.done
+		if gen.err != nil {
+			return gen.err
+		}
+		logrus.Debugf("%sReusing previously-fetched %s", indent, a.Name())
+		reflect.ValueOf(a).Elem().Set(reflect.ValueOf(assetState.asset).Elem())
+		return nil
+	}
+	gen := &generation{done: make(chan struct{})}
+	assetState.generation = gen
+	s.mu.Unlock()
+
+	gen.err = s.generate(a, indent, serial)
+
+	s.mu.Lock()
+	if gen.err == nil {
+		assetState.asset = a
+		assetState.source = generatedSource
+	}
+	assetState.generation = nil
+	close(gen.done)
+	s.mu.Unlock()
+	return gen.err
+}
+
+// generate fetches the dependencies of the given asset and then generates it.
+// The dependencies of interactive assets are always fetched one at a time so
+// that the user is prompted in a stable order, and interactive assets are
+// never generated at the same time as one another.
+func (s *storeImpl) generate(a asset.Asset, indent string, serial bool) error {
+	_, interactive := a.(asset.InteractiveAsset)
+	if interactive {
+		serial = true
+	}
+
 	dependencies := a.Dependencies()
+	errs := make([]error, len(dependencies))
+	if serial {
+		for i, d := range dependencies {
+			if errs[i] = s.fetchLoaded(d, increaseIndent(indent), serial); errs[i] != nil {
+				break
+			}
+		}
+	} else {
+		var wg sync.WaitGroup
+		for i, d := range dependencies {
+			wg.Add(1)
+			go func(i int, d asset.Asset) {
+				defer wg.Done()
+				errs[i] = s.fetchLoaded(d, increaseIndent(indent), serial)
+			}(i, d)
+		}
+		wg.Wait()
+	}
+
+	// Re-generate the asset
 	parents := make(asset.Parents, len(dependencies))
-	for _, d := range dependencies {
-		if err := s.fetch(d, increaseIndent(indent)); err != nil {
-			return errors.Wrapf(err, "failed to fetch dependency of %q", a.Name())
+	for i, d := range dependencies {
+		if errs[i] != nil {
+			return errors.Wrapf(errs[i], "failed to fetch dependency of %q", a.Name())
 		}
 		parents.Add(d)
 	}
+	if s.workers != nil {
+		s.workers <- struct{}{}
+		defer func() { <-s.workers }()
+	}
+	if interactive {
+		s.interactive.Lock()
+		defer s.interactive.Unlock()
+	}
 	logrus.Debugf("%sGenerating %s...", indent, a.Name())
 	if err := a.Generate(parents); err != nil {
 		return errors.Wrapf(err, "failed to generate asset %q", a.Name())
 	}
-	assetState.asset = a
-	assetState.source = generatedSource
+	progress.Emit(progress.AssetGenerated, a.Name())
 	return nil
 }
 
@@ -371,3 +500,61 @@ func (s *storeImpl) Load(a asset.Asset) (asset.Asset, error) {
 
 	return s.assets[reflect.TypeOf(a)].asset, nil
 }
+
+// Clean removes the assets from the directory and from its state file, and
+// writes back to the directory the assets to restore which were consumed,
+// i.e. which are only left in the state file. This way the inputs of the
+// removed assets can be edited before the assets are generated again.
+// Assets which are on disk but not in the state file were not generated by
+// the installer and are left untouched.
+func Clean(dir string, remove []asset.WritableAsset, restore []asset.WritableAsset) error {
+	s, err := newStore(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range remove {
+		if !s.isAssetInState(a) {
+			continue
+		}
+		logrus.Infof("Removing %s", a.Name())
+		if err := s.Destroy(a); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", a.Name())
+		}
+	}
+
+	for _, a := range restore {
+		if !s.isAssetInState(a) {
+			continue
+		}
+		if err := s.loadAssetFromState(a); err != nil {
+			return err
+		}
+		onDisk, err := anyFileOnDisk(a, dir)
+		if err != nil {
+			return err
+		}
+		if onDisk {
+			continue
+		}
+		logrus.Infof("Restoring consumed %s", a.Name())
+		if err := asset.PersistToFile(a, dir); err != nil {
+			return errors.Wrapf(err, "failed to restore %s", a.Name())
+		}
+	}
+	return nil
+}
+
+// anyFileOnDisk returns whether any file of the asset is in the directory.
+func anyFileOnDisk(a asset.WritableAsset, dir string) (bool, error) {
+	for _, f := range a.Files() {
+		_, err := os.Stat(filepath.Join(dir, f.Filename))
+		if err == nil {
+			return true, nil
+		}
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
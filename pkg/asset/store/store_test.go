@@ -4,20 +4,25 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/openshift/installer/pkg/asset"
+	"github.com/openshift/installer/pkg/statecrypt"
 )
 
 var (
 	// It is unfortunate that these need to be global variables. However, the
 	// asset store creates new assets by type, so the tests cannot store behavior
 	// state in the assets themselves.
-	generationLog []string
-	dependencies  map[reflect.Type][]asset.Asset
-	onDiskAssets  map[reflect.Type]bool
+	generationLog   []string
+	generationLogMu sync.Mutex
+	dependencies    map[reflect.Type][]asset.Asset
+	onDiskAssets    map[reflect.Type]bool
 )
 
 func clearAssetBehaviors() {
@@ -31,6 +36,8 @@ func dependenciesTestStoreAsset(a asset.Asset) []asset.Asset {
 }
 
 func generateTestStoreAsset(a asset.Asset) error {
+	generationLogMu.Lock()
+	defer generationLogMu.Unlock()
 	generationLog = append(generationLog, a.Name())
 	return nil
 }
@@ -288,6 +295,82 @@ func TestStoreFetch(t *testing.T) {
 	}
 }
 
+func TestStoreFetchConcurrently(t *testing.T) {
+	clearAssetBehaviors()
+	store := &storeImpl{
+		directory: t.TempDir(),
+		assets:    map[reflect.Type]*assetState{},
+		workers:   make(chan struct{}, 2),
+	}
+	a, b, c, d := newTestStoreAsset("a"), newTestStoreAsset("b"), newTestStoreAsset("c"), newTestStoreAsset("d")
+	dependencies[reflect.TypeOf(a)] = []asset.Asset{b, c, d}
+	dependencies[reflect.TypeOf(b)] = []asset.Asset{d}
+	dependencies[reflect.TypeOf(c)] = []asset.Asset{d}
+
+	err := store.Fetch(a)
+	assert.NoError(t, err, "error fetching asset")
+	if assert.Len(t, generationLog, 4) {
+		assert.Equal(t, "d", generationLog[0])
+		assert.ElementsMatch(t, []string{"b", "c"}, generationLog[1:3])
+		assert.Equal(t, "a", generationLog[3])
+	}
+}
+
+// interactiveGenerations counts the interactive test assets being generated,
+// and interactiveOverlap records whether two of them ever were at once.
+var (
+	interactiveGenerations int32
+	interactiveOverlap     int32
+)
+
+func generateInteractiveTestStoreAsset(a asset.Asset) error {
+	if atomic.AddInt32(&interactiveGenerations, 1) > 1 {
+		atomic.StoreInt32(&interactiveOverlap, 1)
+	}
+	defer atomic.AddInt32(&interactiveGenerations, -1)
+	time.Sleep(10 * time.Millisecond)
+	return generateTestStoreAsset(a)
+}
+
+type testStoreInteractiveAssetB struct{ testStoreAssetB }
+
+func (a *testStoreInteractiveAssetB) Interactive() {}
+
+func (a *testStoreInteractiveAssetB) Generate(asset.Parents) error {
+	return generateInteractiveTestStoreAsset(a)
+}
+
+type testStoreInteractiveAssetC struct{ testStoreAssetC }
+
+func (a *testStoreInteractiveAssetC) Interactive() {}
+
+func (a *testStoreInteractiveAssetC) Generate(asset.Parents) error {
+	return generateInteractiveTestStoreAsset(a)
+}
+
+func TestStoreFetchInteractiveAssetsConcurrently(t *testing.T) {
+	clearAssetBehaviors()
+	atomic.StoreInt32(&interactiveOverlap, 0)
+	store := &storeImpl{
+		directory: t.TempDir(),
+		assets:    map[reflect.Type]*assetState{},
+		workers:   make(chan struct{}, 2),
+	}
+	a, b, c := newTestStoreAsset("a"), &testStoreInteractiveAssetB{}, &testStoreInteractiveAssetC{}
+	dependencies[reflect.TypeOf(a)] = []asset.Asset{b, c}
+
+	err := store.Fetch(a)
+	assert.NoError(t, err, "error fetching asset")
+	assert.ElementsMatch(t, []string{"b", "c", "a"}, generationLog)
+	assert.Zero(t, atomic.LoadInt32(&interactiveOverlap), "interactive assets were generated at once")
+}
+
+func TestNewStoreIsSerialByDefault(t *testing.T) {
+	store, err := newStore(t.TempDir())
+	assert.NoError(t, err)
+	assert.Nil(t, store.workers)
+}
+
 func TestStoreFetchOnDiskAssets(t *testing.T) {
 	cases := []struct {
 		name                  string
@@ -474,3 +557,97 @@ func TestStoreLoadOnDiskAssets(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyState(t *testing.T) {
+	clearAssetBehaviors()
+
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	store, err := newStore(srcDir)
+	if !assert.NoError(t, err, "unexpected error creating store") {
+		t.Fatal()
+	}
+	for _, a := range []asset.Asset{&testStoreAssetA{}, &testStoreAssetB{}} {
+		if !assert.NoError(t, store.Fetch(a), "unexpected error fetching asset %q", a.Name()) {
+			t.Fatal()
+		}
+	}
+
+	if !assert.NoError(t, CopyState(srcDir, dstDir, &testStoreAssetB{})) {
+		t.Fatal()
+	}
+	copied, err := newStore(dstDir)
+	if !assert.NoError(t, err, "unexpected error creating store") {
+		t.Fatal()
+	}
+	assert.True(t, copied.isAssetInState(&testStoreAssetA{}), "expected asset A to be copied")
+	assert.False(t, copied.isAssetInState(&testStoreAssetB{}), "expected asset B to be left out")
+	source, err := newStore(srcDir)
+	if !assert.NoError(t, err, "unexpected error creating store") {
+		t.Fatal()
+	}
+	assert.True(t, source.isAssetInState(&testStoreAssetB{}), "expected source state to be untouched")
+}
+
+func TestStoreEncryptedState(t *testing.T) {
+	clearAssetBehaviors()
+	defer statecrypt.SetKey("")
+
+	tempDir := t.TempDir()
+	if !assert.NoError(t, statecrypt.SetKey("passphrase")) {
+		t.Fatal()
+	}
+
+	store, err := newStore(tempDir)
+	if !assert.NoError(t, err, "unexpected error creating store") {
+		t.Fatal()
+	}
+	if !assert.NoError(t, store.Fetch(&testStoreAssetA{}), "unexpected error fetching asset") {
+		t.Fatal()
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, stateFileName))
+	if !assert.NoError(t, err, "unexpected error reading state file") {
+		t.Fatal()
+	}
+	assert.True(t, statecrypt.IsEncrypted(data), "expected state file to be encrypted")
+
+	store, err = newStore(tempDir)
+	if !assert.NoError(t, err, "unexpected error loading encrypted state") {
+		t.Fatal()
+	}
+	assert.True(t, store.isAssetInState(&testStoreAssetA{}), "expected asset in decrypted state")
+
+	statecrypt.SetKey("")
+	_, err = newStore(tempDir)
+	assert.Error(t, err, "expected error loading encrypted state without key")
+}
+
+func TestClean(t *testing.T) {
+	clearAssetBehaviors()
+
+	tempDir := t.TempDir()
+	store, err := newStore(tempDir)
+	if !assert.NoError(t, err, "unexpected error creating store") {
+		t.Fatal()
+	}
+	for _, a := range []asset.WritableAsset{&testStoreAssetA{}, &testStoreAssetB{}} {
+		if !assert.NoError(t, store.Fetch(a), "unexpected error fetching asset %q", a.Name()) {
+			t.Fatal()
+		}
+	}
+
+	err = Clean(tempDir, []asset.WritableAsset{&testStoreAssetB{}, &testStoreAssetC{}}, []asset.WritableAsset{&testStoreAssetA{}})
+	if !assert.NoError(t, err, "unexpected error cleaning") {
+		t.Fatal()
+	}
+	cleaned, err := newStore(tempDir)
+	if !assert.NoError(t, err, "unexpected error creating store") {
+		t.Fatal()
+	}
+	assert.True(t, cleaned.isAssetInState(&testStoreAssetA{}), "expected asset A to be kept")
+	assert.False(t, cleaned.isAssetInState(&testStoreAssetB{}), "expected asset B to be removed")
+	_, err = os.Stat(filepath.Join(tempDir, (&testStoreAssetA{}).Name()))
+	assert.NoError(t, err, "expected consumed asset A to be restored")
+}
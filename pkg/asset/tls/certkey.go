@@ -143,7 +143,12 @@ func (c *SignedCertKey) Generate(
 		return errors.Wrap(err, "failed to parse x509 certificate")
 	}
 
-	key, crt, err = GenerateSignedCertificate(caKey, caCert, cfg)
+	key, err = privateKey(filenameBase)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate private key")
+	}
+
+	crt, err = signCertificate(key, caKey, caCert, cfg)
 	if err != nil {
 		logrus.Debugf("Failed to generate signed cert/key pair: %s", err)
 		return errors.Wrap(err, "failed to generate signed cert/key pair")
@@ -171,7 +176,12 @@ func (c *SelfSignedCertKey) Generate(
 	cfg *CertCfg,
 	filenameBase string,
 ) error {
-	key, crt, err := GenerateSelfSignedCertificate(cfg)
+	key, err := privateKey(filenameBase)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate private key")
+	}
+
+	crt, err := SelfSignedCertificate(cfg, key)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate self-signed cert/key pair")
 	}
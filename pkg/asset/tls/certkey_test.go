@@ -4,9 +4,14 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/reproducible"
 )
 
 func TestSignedCertKeyGenerate(t *testing.T) {
@@ -89,3 +94,49 @@ func TestSignedCertKeyGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedCertKeyGenerateReproducible(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, tlsDir), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"root-ca", "test-cert"} {
+		key, err := PrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, assetFilePath(name+".key")), PrivateKeyToPem(key), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(reproducible.SourceDateEpochEnvVar, "1700000000")
+	if err := reproducible.Enable(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer reproducible.Disable()
+
+	cfg := &CertCfg{
+		Subject:   pkix.Name{CommonName: "test-cert", OrganizationalUnit: []string{"openshift"}},
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		Validity:  ValidityOneDay,
+	}
+	generate := func() *SignedCertKey {
+		rootCA := &RootCA{}
+		if err := rootCA.Generate(nil); err != nil {
+			t.Fatal(err)
+		}
+		certKey := &SignedCertKey{}
+		if err := certKey.Generate(cfg, rootCA, "test-cert", AppendParent); err != nil {
+			t.Fatal(err)
+		}
+		return certKey
+	}
+
+	first, second := generate(), generate()
+	assert.Equal(t, first.KeyRaw, second.KeyRaw)
+	assert.Equal(t, first.CertRaw, second.CertRaw)
+
+	cert, err := PemToCertificate(first.CertRaw)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), cert.NotBefore.UTC())
+}
@@ -23,7 +23,7 @@ type KeyPair struct {
 
 // Generate generates the rsa private / public key pair.
 func (k *KeyPair) Generate(filenameBase string) error {
-	key, err := PrivateKey()
+	key, err := privateKey(filenameBase)
 	if err != nil {
 		return errors.Wrap(err, "failed to generate private key")
 	}
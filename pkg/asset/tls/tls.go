@@ -17,6 +17,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/reproducible"
 )
 
 const (
@@ -59,9 +61,45 @@ func PrivateKey() (*rsa.PrivateKey, error) {
 	return rsaKey, nil
 }
 
+// privateKey returns the private key provided for the named asset file in
+// reproducible mode, or a newly generated one.
+func privateKey(filenameBase string) (*rsa.PrivateKey, error) {
+	if !reproducible.Enabled() {
+		return PrivateKey()
+	}
+	filename := assetFilePath(filenameBase + ".key")
+	data, err := reproducible.File(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", filename)
+	}
+	if data == nil {
+		logrus.Warnf("Generating a new private key for %s, provide %s in the assets directory to generate it reproducibly", filenameBase, filename)
+		return PrivateKey()
+	}
+	key, err := PemToPrivateKey(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	return key, nil
+}
+
+// serialNumber returns a random serial number or, in reproducible mode, one
+// derived from the subject and public key of the certificate.
+func serialNumber(subject pkix.Name, subjectKeyID []byte) (*big.Int, error) {
+	if !reproducible.Enabled() {
+		return rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	}
+	sum := reproducible.Sum([]byte(subject.String()), subjectKeyID)
+	return new(big.Int).Mod(new(big.Int).SetBytes(sum[:]), new(big.Int).SetInt64(math.MaxInt64)), nil
+}
+
 // SelfSignedCertificate creates a self signed certificate
 func SelfSignedCertificate(cfg *CertCfg, key *rsa.PrivateKey) (*x509.Certificate, error) {
-	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	subjectKeyID, err := generateSubjectKeyID(key.Public())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set subject key identifier")
+	}
+	serial, err := serialNumber(cfg.Subject, subjectKeyID)
 	if err != nil {
 		return nil, err
 	}
@@ -69,20 +107,16 @@ func SelfSignedCertificate(cfg *CertCfg, key *rsa.PrivateKey) (*x509.Certificate
 		BasicConstraintsValid: true,
 		IsCA:                  cfg.IsCA,
 		KeyUsage:              cfg.KeyUsages,
-		NotAfter:              time.Now().Add(cfg.Validity),
-		NotBefore:             time.Now(),
+		NotAfter:              reproducible.Now().Add(cfg.Validity),
+		NotBefore:             reproducible.Now(),
 		SerialNumber:          serial,
 		Subject:               cfg.Subject,
+		SubjectKeyId:          subjectKeyID,
 	}
 	// verifies that the CN and/or OU for the cert is set
 	if len(cfg.Subject.CommonName) == 0 || len(cfg.Subject.OrganizationalUnit) == 0 {
 		return nil, errors.Errorf("certification's subject is not set, or invalid")
 	}
-	pub := key.Public()
-	cert.SubjectKeyId, err = generateSubjectKeyID(pub)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to set subject key identifier")
-	}
 	certBytes, err := x509.CreateCertificate(rand.Reader, &cert, &cert, key.Public(), key)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create certificate")
@@ -98,7 +132,11 @@ func SignedCertificate(
 	caCert *x509.Certificate,
 	caKey *rsa.PrivateKey,
 ) (*x509.Certificate, error) {
-	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+	subjectKeyID, err := generateSubjectKeyID(key.Public())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set subject key identifier")
+	}
+	serial, err := serialNumber(csr.Subject, subjectKeyID)
 	if err != nil {
 		return nil, err
 	}
@@ -108,19 +146,15 @@ func SignedCertificate(
 		ExtKeyUsage:           cfg.ExtKeyUsages,
 		IPAddresses:           csr.IPAddresses,
 		KeyUsage:              cfg.KeyUsages,
-		NotAfter:              time.Now().Add(cfg.Validity),
+		NotAfter:              reproducible.Now().Add(cfg.Validity),
 		NotBefore:             caCert.NotBefore,
 		SerialNumber:          serial,
 		Subject:               csr.Subject,
+		SubjectKeyId:          subjectKeyID,
 		IsCA:                  cfg.IsCA,
 		Version:               3,
 		BasicConstraintsValid: true,
 	}
-	pub := key.Public()
-	certTmpl.SubjectKeyId, err = generateSubjectKeyID(pub)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to set subject key identifier")
-	}
 	certBytes, err := x509.CreateCertificate(rand.Reader, &certTmpl, caCert, key.Public(), caKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create x509 certificate")
@@ -159,26 +193,35 @@ func GenerateSignedCertificate(caKey *rsa.PrivateKey, caCert *x509.Certificate,
 		return nil, nil, errors.Wrap(err, "failed to generate private key")
 	}
 
+	cert, err := signCertificate(key, caKey, caCert, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, cert, nil
+}
+
+// signCertificate creates a cert for the key defined by CertCfg and signed by CA.
+func signCertificate(key, caKey *rsa.PrivateKey, caCert *x509.Certificate, cfg *CertCfg) (*x509.Certificate, error) {
 	// create a CSR
 	csrTmpl := x509.CertificateRequest{Subject: cfg.Subject, DNSNames: cfg.DNSNames, IPAddresses: cfg.IPAddresses}
 	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTmpl, key)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed to create certificate request")
+		return nil, errors.Wrap(err, "failed to create certificate request")
 	}
 
 	csr, err := x509.ParseCertificateRequest(csrBytes)
 	if err != nil {
 		logrus.Debugf("Failed to parse x509 certificate request: %s", err)
-		return nil, nil, errors.Wrap(err, "error parsing x509 certificate request")
+		return nil, errors.Wrap(err, "error parsing x509 certificate request")
 	}
 
 	// create a cert
 	cert, err := SignedCertificate(cfg, csr, key, caCert, caKey)
 	if err != nil {
 		logrus.Debugf("Failed to create a signed certificate: %s", err)
-		return nil, nil, errors.Wrap(err, "failed to create a signed certificate")
+		return nil, errors.Wrap(err, "failed to create a signed certificate")
 	}
-	return key, cert, nil
+	return cert, nil
 }
 
 // GenerateSelfSignedCertificate generates a key/cert pair defined by CertCfg.
@@ -0,0 +1,71 @@
+// Package bmc provides minimal clients of the BMCs of bare metal hosts, to
+// wipe and debug the hosts when the cluster cannot.
+package bmc
+
+import (
+	"github.com/metal3-io/baremetal-operator/pkg/hardwareutils/bmc"
+	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+// IPMIDetails are the details to connect to an IPMI BMC.
+type IPMIDetails struct {
+	Address  string
+	Port     string
+	Username string
+	Password string
+}
+
+// driverInfo returns the driver info of the BMC of the host, and its type.
+func driverInfo(host *baremetal.Host) (map[string]interface{}, string, error) {
+	accessDetails, err := bmc.NewAccessDetails(host.BMC.Address, host.BMC.DisableCertificateVerification)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to parse the BMC address")
+	}
+	info := accessDetails.DriverInfo(bmc.Credentials{
+		Username: host.BMC.Username,
+		Password: host.BMC.Password,
+	})
+	return info, accessDetails.Type(), nil
+}
+
+// RedfishClientForHost returns a Redfish client for the BMC of the host and
+// the path of the system of the host. It fails when the BMC does not speak
+// Redfish.
+func RedfishClientForHost(host *baremetal.Host) (*RedfishClient, string, error) {
+	info, bmcType, err := driverInfo(host)
+	if err != nil {
+		return nil, "", err
+	}
+	address, ok := info["redfish_address"].(string)
+	if !ok {
+		return nil, "", errors.Errorf("%s BMCs do not speak Redfish", bmcType)
+	}
+	systemID, _ := info["redfish_system_id"].(string)
+	verifyCA, ok := info["redfish_verify_ca"].(bool)
+	if !ok {
+		verifyCA = true
+	}
+	return NewRedfishClient(address, host.BMC.Username, host.BMC.Password, verifyCA), systemID, nil
+}
+
+// IPMIDetailsForHost returns the details to connect to the BMC of the host.
+// It fails when the BMC does not speak IPMI.
+func IPMIDetailsForHost(host *baremetal.Host) (*IPMIDetails, error) {
+	info, bmcType, err := driverInfo(host)
+	if err != nil {
+		return nil, err
+	}
+	address, ok := info["ipmi_address"].(string)
+	if !ok {
+		return nil, errors.Errorf("%s BMCs do not speak IPMI", bmcType)
+	}
+	port, _ := info["ipmi_port"].(string)
+	return &IPMIDetails{
+		Address:  address,
+		Port:     port,
+		Username: host.BMC.Username,
+		Password: host.BMC.Password,
+	}, nil
+}
@@ -0,0 +1,401 @@
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// redfishTaskInterval is how often the tasks started by the actions are
+// polled.
+var redfishTaskInterval = 10 * time.Second
+
+// RedfishClient is a minimal client of the Redfish API of a BMC, covering
+// the calls needed to erase the drives of a system, power it off and read
+// its logs, and to boot it on a virtual media.
+type RedfishClient struct {
+	address  string
+	username string
+	password string
+	client   *http.Client
+}
+
+// RedfishSystem is the Redfish computer system of a host.
+type RedfishSystem struct {
+	// ID is the path of the system.
+	ID         string      `json:"-"`
+	PowerState string      `json:"PowerState"`
+	Storage    redfishLink `json:"Storage"`
+	LogService redfishLink `json:"LogServices"`
+	// VirtualMedia is only set by the BMCs attaching the virtual media to
+	// the system rather than to its managers.
+	VirtualMedia redfishLink `json:"VirtualMedia"`
+	Links        struct {
+		ManagedBy []redfishLink `json:"ManagedBy"`
+	} `json:"Links"`
+	Actions struct {
+		Reset redfishAction `json:"#ComputerSystem.Reset"`
+	} `json:"Actions"`
+}
+
+type redfishLink struct {
+	ID string `json:"@odata.id"`
+}
+
+type redfishAction struct {
+	Target string `json:"target"`
+}
+
+type redfishCollection struct {
+	Members []redfishLink `json:"Members"`
+}
+
+type redfishStorage struct {
+	Drives []redfishLink `json:"Drives"`
+}
+
+type redfishDrive struct {
+	Actions struct {
+		SecureErase redfishAction `json:"#Drive.SecureErase"`
+	} `json:"Actions"`
+}
+
+type redfishTask struct {
+	TaskState  string `json:"TaskState"`
+	TaskStatus string `json:"TaskStatus"`
+	Messages   []struct {
+		Message string `json:"Message"`
+	} `json:"Messages"`
+}
+
+type redfishLogService struct {
+	Entries redfishLink `json:"Entries"`
+}
+
+type redfishManager struct {
+	VirtualMedia redfishLink `json:"VirtualMedia"`
+}
+
+type redfishVirtualMedia struct {
+	MediaTypes []string `json:"MediaTypes"`
+	Inserted   bool     `json:"Inserted"`
+	Actions    struct {
+		InsertMedia redfishAction `json:"#VirtualMedia.InsertMedia"`
+		EjectMedia  redfishAction `json:"#VirtualMedia.EjectMedia"`
+	} `json:"Actions"`
+}
+
+// NewRedfishClient returns a client of the Redfish API at the address.
+func NewRedfishClient(address, username, password string, verifyCA bool) *RedfishClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !verifyCA {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // requested with disableCertificateVerification
+	}
+	return &RedfishClient{
+		address:  strings.TrimSuffix(address, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Transport: transport, Timeout: time.Minute},
+	}
+}
+
+func (c *RedfishClient) do(ctx context.Context, method, path string, body, into interface{}) error {
+	resp, err := c.request(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if into == nil {
+		return nil
+	}
+	return errors.Wrapf(json.NewDecoder(resp.Body).Decode(into), "failed to decode %s", path)
+}
+
+// request sends the request and returns the response, whose body the caller
+// closes, or an error when the BMC responds with an error status.
+func (c *RedfishClient) request(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.address+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// action posts the action and, when the BMC runs it asynchronously, waits
+// for the task it started to complete.
+func (c *RedfishClient) action(ctx context.Context, target string, body interface{}) error {
+	resp, err := c.request(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return nil
+	}
+	monitor := resp.Header.Get("Location")
+	if monitor == "" {
+		return nil
+	}
+	if u, err := url.Parse(monitor); err == nil && u.IsAbs() {
+		monitor = u.RequestURI()
+	}
+	return c.waitForTask(ctx, monitor)
+}
+
+// waitForTask polls the task monitor, or the task, with the path until the
+// task completes, and returns an error when the task did not complete
+// successfully.
+func (c *RedfishClient) waitForTask(ctx context.Context, path string) error {
+	for {
+		resp, err := c.request(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the status of the task")
+		}
+		task := &redfishTask{}
+		decodeErr := json.NewDecoder(resp.Body).Decode(task)
+		resp.Body.Close()
+
+		running := resp.StatusCode == http.StatusAccepted
+		if decodeErr == nil && task.TaskState != "" {
+			switch task.TaskState {
+			case "Completed":
+				if task.TaskStatus != "" && task.TaskStatus != "OK" && task.TaskStatus != "Warning" {
+					return errors.Errorf("the task %s completed with status %s%s", path, task.TaskStatus, task.messages())
+				}
+				return nil
+			case "Exception", "Killed", "Cancelled", "Interrupted":
+				return errors.Errorf("the task %s is %s%s", path, task.TaskState, task.messages())
+			default:
+				running = true
+			}
+		}
+		if !running {
+			// The task monitor returns the response of the operation
+			// once the task completed.
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "waiting for the task %s", path)
+		case <-time.After(redfishTaskInterval):
+		}
+	}
+}
+
+func (t *redfishTask) messages() string {
+	var messages []string
+	for _, message := range t.Messages {
+		if message.Message != "" {
+			messages = append(messages, message.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(messages, "; ")
+}
+
+// System returns the Redfish system with the path.
+func (c *RedfishClient) System(ctx context.Context, path string) (*RedfishSystem, error) {
+	system := &RedfishSystem{ID: path}
+	if err := c.do(ctx, http.MethodGet, path, nil, system); err != nil {
+		return nil, err
+	}
+	return system, nil
+}
+
+// Drives returns the paths of the drives attached to the storage
+// controllers of the system.
+func (c *RedfishClient) Drives(ctx context.Context, system *RedfishSystem) ([]string, error) {
+	if system.Storage.ID == "" {
+		return nil, errors.New("the system has no storage")
+	}
+	storages := &redfishCollection{}
+	if err := c.do(ctx, http.MethodGet, system.Storage.ID, nil, storages); err != nil {
+		return nil, err
+	}
+	var drives []string
+	for _, member := range storages.Members {
+		storage := &redfishStorage{}
+		if err := c.do(ctx, http.MethodGet, member.ID, nil, storage); err != nil {
+			return nil, err
+		}
+		for _, drive := range storage.Drives {
+			drives = append(drives, drive.ID)
+		}
+	}
+	return drives, nil
+}
+
+// SecureErase erases the drive with the path, waiting for the task erasing it
+// to complete when the BMC erases it asynchronously. It fails when the drive
+// does not support secure erase.
+func (c *RedfishClient) SecureErase(ctx context.Context, path string) error {
+	drive := &redfishDrive{}
+	if err := c.do(ctx, http.MethodGet, path, nil, drive); err != nil {
+		return err
+	}
+	target := drive.Actions.SecureErase.Target
+	if target == "" {
+		return errors.Errorf("drive %s does not support secure erase", path)
+	}
+	return c.action(ctx, target, struct{}{})
+}
+
+// PowerOff powers the system off, unless it is already off.
+func (c *RedfishClient) PowerOff(ctx context.Context, system *RedfishSystem) error {
+	if strings.EqualFold(system.PowerState, "Off") {
+		return nil
+	}
+	target := system.Actions.Reset.Target
+	if target == "" {
+		return errors.New("the system cannot be reset")
+	}
+	return c.do(ctx, http.MethodPost, target, map[string]string{"ResetType": "ForceOff"}, nil)
+}
+
+// LogEntries returns the raw entries of each log service of the system,
+// e.g. its system event log, by the path of the log service.
+func (c *RedfishClient) LogEntries(ctx context.Context, system *RedfishSystem) (map[string]json.RawMessage, error) {
+	if system.LogService.ID == "" {
+		return nil, errors.New("the system has no log services")
+	}
+	services := &redfishCollection{}
+	if err := c.do(ctx, http.MethodGet, system.LogService.ID, nil, services); err != nil {
+		return nil, err
+	}
+	entries := make(map[string]json.RawMessage, len(services.Members))
+	for _, member := range services.Members {
+		service := &redfishLogService{}
+		if err := c.do(ctx, http.MethodGet, member.ID, nil, service); err != nil {
+			return nil, err
+		}
+		if service.Entries.ID == "" {
+			continue
+		}
+		var raw json.RawMessage
+		if err := c.do(ctx, http.MethodGet, service.Entries.ID, nil, &raw); err != nil {
+			return nil, err
+		}
+		entries[member.ID] = raw
+	}
+	return entries, nil
+}
+
+// InsertVirtualMedia inserts the image at the URL in the CD or DVD virtual
+// media of the system, ejecting the image inserted before if any. The BMC
+// downloads the image from the URL.
+func (c *RedfishClient) InsertVirtualMedia(ctx context.Context, system *RedfishSystem, imageURL string) error {
+	path, media, err := c.cdVirtualMedia(ctx, system)
+	if err != nil {
+		return err
+	}
+	if media.Inserted {
+		if media.Actions.EjectMedia.Target != "" {
+			err = c.do(ctx, http.MethodPost, media.Actions.EjectMedia.Target, struct{}{}, nil)
+		} else {
+			err = c.do(ctx, http.MethodPatch, path, map[string]interface{}{"Image": nil, "Inserted": false}, nil)
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to eject the inserted virtual media")
+		}
+	}
+	insert := map[string]interface{}{"Image": imageURL, "Inserted": true, "WriteProtected": true}
+	if media.Actions.InsertMedia.Target != "" {
+		return c.do(ctx, http.MethodPost, media.Actions.InsertMedia.Target, insert, nil)
+	}
+	return c.do(ctx, http.MethodPatch, path, insert, nil)
+}
+
+// cdVirtualMedia returns the path of the first CD or DVD virtual media of the
+// system, or of its managers.
+func (c *RedfishClient) cdVirtualMedia(ctx context.Context, system *RedfishSystem) (string, *redfishVirtualMedia, error) {
+	collections := []string{}
+	if system.VirtualMedia.ID != "" {
+		collections = append(collections, system.VirtualMedia.ID)
+	}
+	for _, link := range system.Links.ManagedBy {
+		manager := &redfishManager{}
+		if err := c.do(ctx, http.MethodGet, link.ID, nil, manager); err != nil {
+			return "", nil, err
+		}
+		if manager.VirtualMedia.ID != "" {
+			collections = append(collections, manager.VirtualMedia.ID)
+		}
+	}
+	for _, collection := range collections {
+		members := &redfishCollection{}
+		if err := c.do(ctx, http.MethodGet, collection, nil, members); err != nil {
+			return "", nil, err
+		}
+		for _, member := range members.Members {
+			media := &redfishVirtualMedia{}
+			if err := c.do(ctx, http.MethodGet, member.ID, nil, media); err != nil {
+				return "", nil, err
+			}
+			for _, mediaType := range media.MediaTypes {
+				if mediaType == "CD" || mediaType == "DVD" {
+					return member.ID, media, nil
+				}
+			}
+		}
+	}
+	return "", nil, errors.New("the system has no CD or DVD virtual media")
+}
+
+// SetBootOnce boots the system from the boot source target, e.g. Cd, on its
+// next boot only.
+func (c *RedfishClient) SetBootOnce(ctx context.Context, system *RedfishSystem, target string) error {
+	boot := map[string]interface{}{
+		"Boot": map[string]string{
+			"BootSourceOverrideTarget":  target,
+			"BootSourceOverrideEnabled": "Once",
+		},
+	}
+	return c.do(ctx, http.MethodPatch, system.ID, boot, nil)
+}
+
+// PowerOn powers the system on, or restarts it when it is already on so that
+// it boots from its boot source override.
+func (c *RedfishClient) PowerOn(ctx context.Context, system *RedfishSystem) error {
+	target := system.Actions.Reset.Target
+	if target == "" {
+		return errors.New("the system cannot be reset")
+	}
+	resetType := "On"
+	if strings.EqualFold(system.PowerState, "On") {
+		resetType = "ForceRestart"
+	}
+	return c.do(ctx, http.MethodPost, target, map[string]string{"ResetType": resetType}, nil)
+}
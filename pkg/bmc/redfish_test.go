@@ -0,0 +1,150 @@
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogEntries(t *testing.T) {
+	resources := map[string]string{
+		"/redfish/v1/Systems/1":                         `{"PowerState": "On", "LogServices": {"@odata.id": "/redfish/v1/Systems/1/LogServices"}}`,
+		"/redfish/v1/Systems/1/LogServices":             `{"Members": [{"@odata.id": "/redfish/v1/Systems/1/LogServices/SEL"}, {"@odata.id": "/redfish/v1/Systems/1/LogServices/Empty"}]}`,
+		"/redfish/v1/Systems/1/LogServices/SEL":         `{"Entries": {"@odata.id": "/redfish/v1/Systems/1/LogServices/SEL/Entries"}}`,
+		"/redfish/v1/Systems/1/LogServices/SEL/Entries": `{"Members": [{"Message": "POST error"}]}`,
+		"/redfish/v1/Systems/1/LogServices/Empty":       `{}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource, ok := resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(resource)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	client := NewRedfishClient(server.URL, "admin", "password", true)
+	system, err := client.System(context.Background(), "/redfish/v1/Systems/1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	entries, err := client.LogEntries(context.Background(), system)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{
+		"/redfish/v1/Systems/1/LogServices/SEL": json.RawMessage(`{"Members": [{"Message": "POST error"}]}`),
+	}, entries)
+}
+
+func TestBootVirtualMedia(t *testing.T) {
+	resources := map[string]string{
+		"/redfish/v1/Systems/1":                      `{"PowerState": "Off", "Links": {"ManagedBy": [{"@odata.id": "/redfish/v1/Managers/1"}]}, "Actions": {"#ComputerSystem.Reset": {"target": "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset"}}}`,
+		"/redfish/v1/Managers/1":                     `{"VirtualMedia": {"@odata.id": "/redfish/v1/Managers/1/VirtualMedia"}}`,
+		"/redfish/v1/Managers/1/VirtualMedia":        `{"Members": [{"@odata.id": "/redfish/v1/Managers/1/VirtualMedia/Floppy"}, {"@odata.id": "/redfish/v1/Managers/1/VirtualMedia/CD"}]}`,
+		"/redfish/v1/Managers/1/VirtualMedia/Floppy": `{"MediaTypes": ["Floppy"]}`,
+		"/redfish/v1/Managers/1/VirtualMedia/CD":     `{"MediaTypes": ["CD", "DVD"], "Inserted": true, "Actions": {"#VirtualMedia.InsertMedia": {"target": "/redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.InsertMedia"}, "#VirtualMedia.EjectMedia": {"target": "/redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.EjectMedia"}}}`,
+	}
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		resource, ok := resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(resource)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewRedfishClient(server.URL, "admin", "password", true)
+	system, err := client.System(ctx, "/redfish/v1/Systems/1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, client.InsertVirtualMedia(ctx, system, "http://192.168.111.1:8080/agent.x86_64.iso"))
+	assert.NoError(t, client.SetBootOnce(ctx, system, "Cd"))
+	assert.NoError(t, client.PowerOn(ctx, system))
+	assert.Equal(t, []string{
+		`POST /redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.EjectMedia {}`,
+		`POST /redfish/v1/Managers/1/VirtualMedia/CD/Actions/VirtualMedia.InsertMedia {"Image":"http://192.168.111.1:8080/agent.x86_64.iso","Inserted":true,"WriteProtected":true}`,
+		`PATCH /redfish/v1/Systems/1 {"Boot":{"BootSourceOverrideEnabled":"Once","BootSourceOverrideTarget":"Cd"}}`,
+		`POST /redfish/v1/Systems/1/Actions/ComputerSystem.Reset {"ResetType":"On"}`,
+	}, requests)
+}
+
+func TestSecureEraseTask(t *testing.T) {
+	redfishTaskInterval = time.Millisecond
+	cases := []struct {
+		name          string
+		states        []string
+		status        string
+		expectedError string
+	}{
+		{
+			name:   "completed",
+			states: []string{"New", "Running", "Completed"},
+			status: "OK",
+		},
+		{
+			name:          "exception",
+			states:        []string{"Running", "Exception"},
+			status:        "Critical",
+			expectedError: "the task /redfish/v1/TaskService/Tasks/1 is Exception: Drive is write protected",
+		},
+		{
+			name:          "completed with errors",
+			states:        []string{"Completed"},
+			status:        "Critical",
+			expectedError: "the task /redfish/v1/TaskService/Tasks/1 completed with status Critical: Drive is write protected",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			polls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/redfish/v1/Systems/1/Storage/1/Drives/1":
+					w.Write([]byte(`{"Actions": {"#Drive.SecureErase": {"target": "/redfish/v1/Systems/1/Storage/1/Drives/1/Actions/Drive.SecureErase"}}}`)) //nolint:errcheck
+				case "/redfish/v1/Systems/1/Storage/1/Drives/1/Actions/Drive.SecureErase":
+					w.Header().Set("Location", "http://"+r.Host+"/redfish/v1/TaskService/Tasks/1")
+					w.WriteHeader(http.StatusAccepted)
+				case "/redfish/v1/TaskService/Tasks/1":
+					state := tc.states[polls]
+					polls++
+					if state != "Completed" && state != "Exception" {
+						w.WriteHeader(http.StatusAccepted)
+					}
+					json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck
+						"TaskState":  state,
+						"TaskStatus": tc.status,
+						"Messages":   []map[string]string{{"Message": "Drive is write protected"}},
+					})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client := NewRedfishClient(server.URL, "admin", "password", true)
+			err := client.SecureErase(context.Background(), "/redfish/v1/Systems/1/Storage/1/Drives/1")
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, len(tc.states), polls)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ipmitool is the command used to connect to the serial console of IPMI
+// BMCs, which is not available over HTTP.
+var ipmitool = "ipmitool"
+
+// CaptureSerialConsole returns the output of the serial console of the host
+// with the IPMI BMC during the duration, through serial-over-LAN. A newline
+// is sent to the console first, to print e.g. the login prompt or the
+// emergency shell. It requires ipmitool.
+func CaptureSerialConsole(ctx context.Context, details *IPMIDetails, duration time.Duration) ([]byte, error) {
+	path, err := exec.LookPath(ipmitool)
+	if err != nil {
+		return nil, errors.Wrap(err, "capturing the serial console requires ipmitool")
+	}
+	args := []string{"-I", "lanplus", "-H", details.Address, "-U", details.Username, "-E"}
+	if details.Port != "" {
+		args = append(args, "-p", details.Port)
+	}
+	// The password is passed in the environment, since the arguments of
+	// the processes are visible to every user.
+	env := append(os.Environ(), "IPMI_PASSWORD="+details.Password)
+
+	// Close the session left by a previous capture, if any.
+	deactivate := exec.CommandContext(ctx, path, append(args, "sol", "deactivate")...) //nolint:gosec
+	deactivate.Env = env
+	_ = deactivate.Run()
+
+	captureCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	var output bytes.Buffer
+	activate := exec.CommandContext(captureCtx, path, append(args, "sol", "activate")...) //nolint:gosec
+	activate.Env = env
+	activate.Stdout = &output
+	activate.Stderr = &output
+	// Do not wait for the output of processes left by a killed ipmitool.
+	activate.WaitDelay = time.Second
+	// The console is kept open until the capture ends, since ipmitool
+	// leaves once its input is closed.
+	console, err := activate.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := activate.Start(); err != nil {
+		return nil, errors.Wrap(err, "failed to start ipmitool")
+	}
+	if _, err := io.WriteString(console, "\n"); err != nil {
+		logrus.Debugf("Failed to write to the serial console: %v", err)
+	}
+	err = activate.Wait()
+	if captureCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		// The capture ends by killing ipmitool.
+		err = nil
+	}
+	if err != nil {
+		return output.Bytes(), errors.Wrapf(err, "failed to capture the serial console: %s", bytes.TrimSpace(output.Bytes()))
+	}
+	return output.Bytes(), nil
+}
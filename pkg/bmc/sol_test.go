@@ -0,0 +1,43 @@
+package bmc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureSerialConsole(t *testing.T) {
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "ipmitool")
+	script := `#!/bin/sh
+case "$*" in
+*deactivate) exit 0 ;;
+esac
+echo "args: $*"
+echo "password: $IPMI_PASSWORD"
+read line
+echo "localhost login:"
+sleep 10
+`
+	if err := os.WriteFile(fake, []byte(script), 0700); err != nil { //nolint:gosec
+		t.Fatal(err)
+	}
+	defer func(original string) { ipmitool = original }(ipmitool)
+	ipmitool = fake
+
+	output, err := CaptureSerialConsole(context.Background(), &IPMIDetails{
+		Address:  "192.168.111.1",
+		Port:     "6230",
+		Username: "admin",
+		Password: "secret",
+	}, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, `args: -I lanplus -H 192.168.111.1 -U admin -E -p 6230 sol activate
+password: secret
+localhost login:
+`, string(output))
+}
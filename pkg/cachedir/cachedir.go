@@ -0,0 +1,19 @@
+// Package cachedir resolves the base directory in which the installer caches
+// downloaded data such as RHCOS images and ISOs.
+package cachedir
+
+import (
+	"os"
+)
+
+// EnvVar is the environment variable overriding the base cache directory.
+const EnvVar = "OPENSHIFT_INSTALL_CACHE_DIR"
+
+// UserCacheDir returns the base cache directory. It is the directory named by
+// OPENSHIFT_INSTALL_CACHE_DIR when set, and the user cache directory otherwise.
+func UserCacheDir() (string, error) {
+	if dir := os.Getenv(EnvVar); dir != "" {
+		return dir, nil
+	}
+	return os.UserCacheDir()
+}
@@ -0,0 +1,24 @@
+package cachedir
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCacheDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/home/user/.cache")
+
+	t.Setenv(EnvVar, "")
+	dir, err := UserCacheDir()
+	assert.NoError(t, err)
+	expected, err := os.UserCacheDir()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, dir)
+
+	t.Setenv(EnvVar, "/scratch/cache")
+	dir, err = UserCacheDir()
+	assert.NoError(t, err)
+	assert.Equal(t, "/scratch/cache", dir)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -62,10 +65,41 @@ type ClusterUninstaller struct {
 	ClusterDomain  string
 	HostedZoneRole string
 
+	// ResourceFilter skips the resources with some tags or of some types.
+	ResourceFilter providers.ResourceFilter
+
+	// ResourceTracker records what happens to the resources, if set.
+	ResourceTracker *providers.ResourceTracker
+
+	// RateLimits controls the parallelism of the deletions and the rate of
+	// the calls to the AWS APIs.
+	RateLimits providers.RateLimits
+
+	// ForceOptions gives up on the resources which keep failing to be
+	// deleted.
+	ForceOptions providers.ForceOptions
+
+	// ImageRegistryPreserved keeps the S3 bucket of the image registry.
+	ImageRegistryPreserved bool
+
+	// NetworkAccount holds the credentials of the account owning the shared
+	// VPC of the cluster. HostedZoneRole is assumed to clean up the private
+	// hosted zone of the cluster when it is empty.
+	NetworkAccount providers.NetworkAccount
+
 	// Session is the AWS session to be used for deletion.  If nil, a
 	// new session will be created based on the usual credential
 	// configuration (AWS_PROFILE, AWS_ACCESS_KEY_ID, etc.).
 	Session *session.Session
+
+	// networkSession is the session of the account owning the shared VPC of
+	// the cluster, nil when the cluster has no resources in another account.
+	networkSession *session.Session
+	// networkTagClients are the tag clients of networkSession.
+	networkTagClients map[*resourcegroupstaggingapi.ResourceGroupsTaggingAPI]bool
+	// networkARNs are the resources of the cluster found in the account
+	// owning the shared VPC, which are deleted with networkSession.
+	networkARNs sets.String
 }
 
 // New returns an AWS destroyer from ClusterMetadata.
@@ -94,6 +128,36 @@ func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (providers.
 	}, nil
 }
 
+// SetResourceFilter implements providers.Filterable.
+func (o *ClusterUninstaller) SetResourceFilter(filter providers.ResourceFilter) {
+	o.ResourceFilter = filter
+}
+
+// SetRateLimits implements providers.RateLimited.
+func (o *ClusterUninstaller) SetRateLimits(limits providers.RateLimits) {
+	o.RateLimits = limits
+}
+
+// SetForceOptions implements providers.Forceable.
+func (o *ClusterUninstaller) SetForceOptions(options providers.ForceOptions) {
+	o.ForceOptions = options
+}
+
+// PreserveImageRegistry implements providers.ImageRegistryPreserver.
+func (o *ClusterUninstaller) PreserveImageRegistry() {
+	o.ImageRegistryPreserved = true
+}
+
+// SetNetworkAccount implements providers.CrossAccount.
+func (o *ClusterUninstaller) SetNetworkAccount(account providers.NetworkAccount) {
+	o.NetworkAccount = account
+}
+
+// SetResourceTracker implements providers.Tracked.
+func (o *ClusterUninstaller) SetResourceTracker(tracker *providers.ResourceTracker) {
+	o.ResourceTracker = tracker
+}
+
 func (o *ClusterUninstaller) validate() error {
 	if len(o.Filters) == 0 {
 		return errors.Errorf("you must specify at least one tag filter")
@@ -115,57 +179,23 @@ func (o *ClusterUninstaller) RunWithContext(ctx context.Context) ([]string, erro
 		return nil, err
 	}
 
-	awsSession := o.Session
-	if awsSession == nil {
-		// Relying on appropriate AWS ENV vars (eg AWS_PROFILE, AWS_ACCESS_KEY_ID, etc)
-		awsSession, err = session.NewSession(aws.NewConfig().WithRegion(o.Region))
-		if err != nil {
-			return nil, err
-		}
-	}
-	awsSession.Handlers.Build.PushBackNamed(request.NamedHandler{
-		Name: "openshiftInstaller.OpenshiftInstallerUserAgentHandler",
-		Fn:   request.MakeAddToUserAgentHandler("OpenShift/4.x Destroyer", version.Raw),
-	})
-
-	tagClients := []*resourcegroupstaggingapi.ResourceGroupsTaggingAPI{
-		resourcegroupstaggingapi.New(awsSession),
-	}
-
-	if o.HostedZoneRole != "" {
-		cfg := awssession.GetR53ClientCfg(awsSession, o.HostedZoneRole)
-		// This client is specifically for finding route53 zones,
-		// so it needs to use the global us-east-1 region.
-		cfg.Region = aws.String(endpoints.UsEast1RegionID)
-		tagClients = append(tagClients, resourcegroupstaggingapi.New(awsSession, cfg))
-	}
-
-	switch o.Region {
-	case endpoints.CnNorth1RegionID, endpoints.CnNorthwest1RegionID:
-		break
-	case endpoints.UsIsoEast1RegionID, endpoints.UsIsoWest1RegionID, endpoints.UsIsobEast1RegionID:
-		break
-	case endpoints.UsGovEast1RegionID, endpoints.UsGovWest1RegionID:
-		if o.Region != endpoints.UsGovWest1RegionID {
-			tagClients = append(tagClients,
-				resourcegroupstaggingapi.New(awsSession, aws.NewConfig().WithRegion(endpoints.UsGovWest1RegionID)))
-		}
-	default:
-		if o.Region != endpoints.UsEast1RegionID {
-			tagClients = append(tagClients,
-				resourcegroupstaggingapi.New(awsSession, aws.NewConfig().WithRegion(endpoints.UsEast1RegionID)))
-		}
+	awsSession, err := o.session()
+	if err != nil {
+		return nil, err
 	}
+	tagClients := o.tagClients(awsSession)
 
 	iamClient := iam.New(awsSession)
 	iamRoleSearch := &iamRoleSearch{
 		client:  iamClient,
 		filters: o.Filters,
+		exclude: o.ResourceFilter,
 		logger:  o.Logger,
 	}
 	iamUserSearch := &iamUserSearch{
 		client:  iamClient,
 		filters: o.Filters,
+		exclude: o.ResourceFilter,
 		logger:  o.Logger,
 	}
 
@@ -190,7 +220,7 @@ func (o *ClusterUninstaller) RunWithContext(ctx context.Context) ([]string, erro
 	err = wait.PollImmediateUntil(
 		time.Second*10,
 		func() (done bool, err error) {
-			instancesRunning, instancesNotTerminated, err := findEC2Instances(ctx, ec2Client, deleted, o.Filters, o.Logger)
+			instancesRunning, instancesNotTerminated, err := findEC2Instances(ctx, ec2Client, deleted, o.Filters, o.ResourceFilter, o.Logger)
 			if err != nil {
 				o.Logger.WithError(err).Info("error while finding EC2 instances to delete")
 				if err := ctx.Err(); err != nil {
@@ -262,9 +292,169 @@ func (o *ClusterUninstaller) RunWithContext(ctx context.Context) ([]string, erro
 		return nil, err
 	}
 
+	if gaveUp := tracker.givenUp(); len(gaveUp) > 0 {
+		return gaveUp, errors.Errorf("gave up on %d resources which kept failing to be deleted: %s", len(gaveUp), strings.Join(gaveUp, ", "))
+	}
 	return nil, nil
 }
 
+// ListResources returns the resources which Run would delete, without
+// deleting them.
+func (o *ClusterUninstaller) ListResources(ctx context.Context) ([]providers.Resource, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	awsSession, err := o.session()
+	if err != nil {
+		return nil, err
+	}
+
+	iamClient := iam.New(awsSession)
+	iamRoleSearch := &iamRoleSearch{client: iamClient, filters: o.Filters, exclude: o.ResourceFilter, logger: o.Logger}
+	iamUserSearch := &iamUserSearch{client: iamClient, filters: o.Filters, exclude: o.ResourceFilter, logger: o.Logger}
+	arns, _, err := o.findResourcesToDelete(ctx, o.tagClients(awsSession), iamClient, iamRoleSearch, iamUserSearch, sets.NewString())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find the resources to delete")
+	}
+
+	resources := make([]providers.Resource, 0, arns.Len())
+	for _, arnString := range arns.List() {
+		resources = append(resources, arnResource(arnString))
+	}
+	return resources, nil
+}
+
+// isImageRegistryBucket returns whether the ARN is of the S3 bucket of the
+// image registry.
+func (o *ClusterUninstaller) isImageRegistryBucket(arnString string) bool {
+	parsed, err := arn.Parse(arnString)
+	if err != nil || o.ClusterID == "" {
+		return false
+	}
+	return parsed.Service == "s3" && strings.HasPrefix(parsed.Resource, providers.ImageRegistryStoragePrefix(o.ClusterID))
+}
+
+// arnResource returns the resource of the ARN, whose type is the service and
+// the type of the resource in the service, e.g. "ec2:instance".
+func arnResource(arnString string) providers.Resource {
+	parsed, err := arn.Parse(arnString)
+	if err != nil {
+		return providers.Resource{Type: "unknown", ID: arnString}
+	}
+	resourceType := parsed.Resource
+	if i := strings.IndexAny(resourceType, "/:"); i >= 0 {
+		resourceType = resourceType[:i]
+	}
+	if resourceType == parsed.Resource {
+		// e.g. S3 buckets, whose ARN resource is only the bucket name.
+		resourceType = ""
+	}
+	return providers.Resource{
+		Type:   strings.TrimSuffix(fmt.Sprintf("%s:%s", parsed.Service, resourceType), ":"),
+		ID:     arnString,
+		Region: parsed.Region,
+	}
+}
+
+// session returns the AWS session used to find and delete the resources.
+func (o *ClusterUninstaller) session() (*session.Session, error) {
+	awsSession := o.Session
+	if awsSession == nil {
+		// Relying on appropriate AWS ENV vars (eg AWS_PROFILE, AWS_ACCESS_KEY_ID, etc)
+		var err error
+		awsSession, err = session.NewSession(aws.NewConfig().WithRegion(o.Region))
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The handlers replace the ones of a previous call, e.g. listing the
+	// resources before deleting them.
+	awsSession.Handlers.Build.SetBackNamed(request.NamedHandler{
+		Name: "openshiftInstaller.OpenshiftInstallerUserAgentHandler",
+		Fn:   request.MakeAddToUserAgentHandler("OpenShift/4.x Destroyer", version.Raw),
+	})
+	if limiter := o.RateLimits.Limiter(); limiter != nil {
+		// Send handlers run for every attempt, so the retries are limited too.
+		awsSession.Handlers.Send.SetFrontNamed(request.NamedHandler{
+			Name: "openshiftInstaller.RateLimitHandler",
+			Fn: func(r *request.Request) {
+				if err := limiter.Wait(r.Context()); err != nil {
+					r.Error = err
+				}
+			},
+		})
+	}
+	o.networkSession = nil
+	if creds := o.networkCredentials(awsSession); creds != nil {
+		o.networkSession = awsSession.Copy(&aws.Config{Credentials: creds})
+	}
+	return awsSession, nil
+}
+
+// networkCredentials returns the credentials of the account owning the
+// shared VPC of the cluster, or nil when the cluster has no resources in
+// another account.
+func (o *ClusterUninstaller) networkCredentials(awsSession *session.Session) *credentials.Credentials {
+	switch {
+	case o.NetworkAccount.Profile != "":
+		return credentials.NewSharedCredentials("", o.NetworkAccount.Profile)
+	case o.NetworkAccount.Role != "":
+		return stscreds.NewCredentials(awsSession, o.NetworkAccount.Role)
+	case o.HostedZoneRole != "":
+		return stscreds.NewCredentials(awsSession, o.HostedZoneRole)
+	default:
+		return nil
+	}
+}
+
+// tagClients returns the clients of the tagging API of the regions in which
+// the resources of the cluster are searched: the region of the cluster, and
+// the region of the global resources of its partition.
+func (o *ClusterUninstaller) tagClients(awsSession *session.Session) []*resourcegroupstaggingapi.ResourceGroupsTaggingAPI {
+	tagClients := []*resourcegroupstaggingapi.ResourceGroupsTaggingAPI{
+		resourcegroupstaggingapi.New(awsSession),
+	}
+
+	o.networkTagClients = map[*resourcegroupstaggingapi.ResourceGroupsTaggingAPI]bool{}
+	o.networkARNs = sets.NewString()
+	if o.networkSession != nil {
+		// This client is specifically for finding route53 zones,
+		// so it needs to use the global us-east-1 region.
+		networkClients := []*resourcegroupstaggingapi.ResourceGroupsTaggingAPI{
+			resourcegroupstaggingapi.New(o.networkSession, aws.NewConfig().WithRegion(endpoints.UsEast1RegionID)),
+		}
+		// The hosted zone role is only allowed to manage the private hosted
+		// zone, while the network account also holds the shared subnets of
+		// the cluster in its region.
+		if !o.NetworkAccount.IsEmpty() && o.Region != endpoints.UsEast1RegionID {
+			networkClients = append(networkClients, resourcegroupstaggingapi.New(o.networkSession))
+		}
+		for _, client := range networkClients {
+			o.networkTagClients[client] = true
+		}
+		tagClients = append(tagClients, networkClients...)
+	}
+
+	switch o.Region {
+	case endpoints.CnNorth1RegionID, endpoints.CnNorthwest1RegionID:
+		break
+	case endpoints.UsIsoEast1RegionID, endpoints.UsIsoWest1RegionID, endpoints.UsIsobEast1RegionID:
+		break
+	case endpoints.UsGovEast1RegionID, endpoints.UsGovWest1RegionID:
+		if o.Region != endpoints.UsGovWest1RegionID {
+			tagClients = append(tagClients,
+				resourcegroupstaggingapi.New(awsSession, aws.NewConfig().WithRegion(endpoints.UsGovWest1RegionID)))
+		}
+	default:
+		if o.Region != endpoints.UsEast1RegionID {
+			tagClients = append(tagClients,
+				resourcegroupstaggingapi.New(awsSession, aws.NewConfig().WithRegion(endpoints.UsEast1RegionID)))
+		}
+	}
+
+	return tagClients
+}
+
 // findResourcesToDelete returns the resources that should be deleted.
 //
 //	tagClients - clients of the tagging API to use to search for resources.
@@ -311,6 +501,21 @@ func (o *ClusterUninstaller) findResourcesToDelete(
 	}
 	resources = resources.Union(iamUserResources)
 
+	for _, arnString := range resources.UnsortedList() {
+		resource := arnResource(arnString)
+		if !o.ResourceFilter.IncludesType(resource.Type) {
+			o.ResourceTracker.Skipped(resource, "type not included")
+			resources.Delete(arnString)
+			continue
+		}
+		if o.ImageRegistryPreserved && o.isImageRegistryBucket(arnString) {
+			o.ResourceTracker.Skipped(resource, "image registry storage preserved")
+			resources.Delete(arnString)
+			continue
+		}
+		o.ResourceTracker.Discovered(resource)
+	}
+
 	return resources, tagClientsWithResources, utilerrors.NewAggregate(errs)
 }
 
@@ -339,9 +544,22 @@ func (o *ClusterUninstaller) findResourcesByTag(
 			func(results *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
 				for _, resource := range results.ResourceTagMappingList {
 					arnString := *resource.ResourceARN
-					if !deleted.Has(arnString) {
-						resources.Insert(arnString)
+					if deleted.Has(arnString) {
+						continue
 					}
+					tags := make(map[string]string, len(resource.Tags))
+					for _, tag := range resource.Tags {
+						tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+					}
+					if o.ResourceFilter.ExcludesTags(tags) {
+						o.Logger.WithField("arn", arnString).Debug("Skipping the excluded resource")
+						o.ResourceTracker.Skipped(arnResource(arnString), "excluded by tag")
+						continue
+					}
+					if o.networkTagClients[tagClient] {
+						o.networkARNs.Insert(arnString)
+					}
+					resources.Insert(arnString)
 				}
 				return !lastPage
 			},
@@ -361,7 +579,10 @@ func (o *ClusterUninstaller) findResourcesByTag(
 //
 // The first return is the ARNs of the resources that were successfully deleted
 func (o *ClusterUninstaller) deleteResources(ctx context.Context, awsSession *session.Session, resources []string, tracker *errorTracker) (sets.String, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 	deleted := sets.NewString()
+	parallel := make(chan struct{}, o.RateLimits.Parallel())
 	for _, arnString := range resources {
 		logger := o.Logger.WithField("arn", arnString)
 		parsedARN, err := arn.Parse(arnString)
@@ -369,16 +590,49 @@ func (o *ClusterUninstaller) deleteResources(ctx context.Context, awsSession *se
 			logger.WithError(err).Debug("could not parse ARN")
 			continue
 		}
-		if err := deleteARN(ctx, awsSession, parsedARN, o.Logger); err != nil {
-			tracker.suppressWarning(arnString, err, logger)
-			if err := ctx.Err(); err != nil {
-				return deleted, err
-			}
-			continue
+		select {
+		case parallel <- struct{}{}:
+		case <-ctx.Done():
 		}
-		deleted.Insert(arnString)
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(arnString string, parsedARN arn.ARN) {
+			defer func() {
+				<-parallel
+				wg.Done()
+			}()
+			resource := arnResource(arnString)
+			o.ResourceTracker.Discovered(resource)
+			deleteSession := awsSession
+			if o.networkARNs.Has(arnString) {
+				deleteSession = o.networkSession
+			}
+			var kept *keptResourceError
+			if err := deleteARN(ctx, deleteSession, parsedARN, o.ResourceFilter, o.Logger); errors.As(err, &kept) {
+				logger.Info(kept.Error())
+				o.ResourceTracker.Skipped(resource, kept.reason)
+			} else if err != nil {
+				if !o.ForceOptions.GivesUp(tracker.failingSince(arnString)) {
+					tracker.suppressWarning(arnString, err, logger)
+					o.ResourceTracker.Failed(resource, err)
+					return
+				}
+				// Treat the resource as deleted so that it is not retried.
+				logger.WithError(err).Warn("Giving up on the resource, which keeps failing to be deleted")
+				tracker.giveUp(arnString)
+				o.ResourceTracker.Skipped(resource, fmt.Sprintf("gave up after failing to be deleted: %v", err))
+			} else {
+				o.ResourceTracker.Deleted(resource)
+			}
+			mu.Lock()
+			deleted.Insert(arnString)
+			mu.Unlock()
+		}(arnString, parsedARN)
 	}
-	return deleted, nil
+	wg.Wait()
+	return deleted, ctx.Err()
 }
 
 func splitSlash(name string, input string) (base string, suffix string, err error) {
@@ -491,10 +745,20 @@ func findPublicRoute53(ctx context.Context, client *route53.Route53, dnsName str
 	return "", nil
 }
 
-func deleteARN(ctx context.Context, session *session.Session, arn arn.ARN, logger logrus.FieldLogger) error {
+// keptResourceError is returned when a resource is kept because deleting it
+// would delete resources which the resource filter excludes.
+type keptResourceError struct {
+	reason string
+}
+
+func (e *keptResourceError) Error() string {
+	return "kept the resource, it " + e.reason
+}
+
+func deleteARN(ctx context.Context, session *session.Session, arn arn.ARN, exclude providers.ResourceFilter, logger logrus.FieldLogger) error {
 	switch arn.Service {
 	case "ec2":
-		return deleteEC2(ctx, session, arn, logger)
+		return deleteEC2(ctx, session, arn, exclude, logger)
 	case "elasticloadbalancing":
 		return deleteElasticLoadBalancing(ctx, session, arn, logger)
 	case "iam":
@@ -0,0 +1,149 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
+)
+
+func TestIsImageRegistryBucket(t *testing.T) {
+	cases := []struct {
+		name      string
+		clusterID string
+		arn       string
+		expected  bool
+	}{
+		{name: "image registry bucket", clusterID: "test-x2k4f", arn: "arn:aws:s3:::test-x2k4f-image-registry-us-east-1-abcdefghij", expected: true},
+		{name: "image registry bucket in another partition", clusterID: "test-x2k4f", arn: "arn:aws-us-gov:s3:::test-x2k4f-image-registry-us-gov-west-1-abc", expected: true},
+		{name: "other bucket of the cluster", clusterID: "test-x2k4f", arn: "arn:aws:s3:::test-x2k4f-bootstrap"},
+		{name: "image registry bucket of another cluster", clusterID: "test-x2k4f", arn: "arn:aws:s3:::test-x2k4fz-image-registry-us-east-1-abc"},
+		{name: "other service", clusterID: "test-x2k4f", arn: "arn:aws:ec2:us-east-1:123456789012:volume/test-x2k4f-image-registry-us-east-1-abc"},
+		{name: "no cluster ID", arn: "arn:aws:s3:::-image-registry-us-east-1-abc"},
+		{name: "invalid ARN", clusterID: "test-x2k4f", arn: "test-x2k4f-image-registry-us-east-1-abc"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &ClusterUninstaller{ClusterID: tc.clusterID}
+			assert.Equal(t, tc.expected, o.isImageRegistryBucket(tc.arn))
+		})
+	}
+}
+
+func TestNetworkCredentials(t *testing.T) {
+	credentialsFile := filepath.Join(t.TempDir(), "credentials")
+	err := os.WriteFile(credentialsFile, []byte("[shared-vpc]\naws_access_key_id = AKIANETWORK\naws_secret_access_key = secret\n"), 0o600)
+	require.NoError(t, err)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	awsSession, err := session.NewSession(aws.NewConfig().WithRegion("us-east-1").WithCredentials(credentials.NewStaticCredentials("AKIACLUSTER", "secret", "")))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name              string
+		account           providers.NetworkAccount
+		hostedZoneRole    string
+		expectedNil       bool
+		expectedAccessKey string
+	}{
+		{
+			name:        "no network account",
+			expectedNil: true,
+		},
+		{
+			name:              "profile",
+			account:           providers.NetworkAccount{Profile: "shared-vpc"},
+			hostedZoneRole:    "arn:aws:iam::123456789012:role/hosted-zone",
+			expectedAccessKey: "AKIANETWORK",
+		},
+		{
+			name:    "role",
+			account: providers.NetworkAccount{Role: "arn:aws:iam::123456789012:role/shared-vpc"},
+		},
+		{
+			name:           "hosted zone role",
+			hostedZoneRole: "arn:aws:iam::123456789012:role/hosted-zone",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &ClusterUninstaller{NetworkAccount: tc.account, HostedZoneRole: tc.hostedZoneRole}
+			creds := o.networkCredentials(awsSession)
+			if tc.expectedNil {
+				assert.Nil(t, creds)
+				return
+			}
+			require.NotNil(t, creds)
+			if tc.expectedAccessKey != "" {
+				value, err := creds.Get()
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedAccessKey, value.AccessKeyID)
+			}
+		})
+	}
+}
+
+func TestARNResource(t *testing.T) {
+	cases := []struct {
+		name     string
+		arn      string
+		expected providers.Resource
+	}{
+		{
+			name:     "S3 bucket without a resource type",
+			arn:      "arn:aws:s3:::test-x2k4f-image-registry-us-east-1-abcdefghij",
+			expected: providers.Resource{Type: "s3", ID: "arn:aws:s3:::test-x2k4f-image-registry-us-east-1-abcdefghij"},
+		},
+		{
+			name:     "EC2 instance",
+			arn:      "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0",
+			expected: providers.Resource{Type: "ec2:instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0", Region: "us-east-1"},
+		},
+		{
+			name:     "Route 53 hosted zone",
+			arn:      "arn:aws:route53:::hostedzone/Z0123456789ABCDEFGHIJ",
+			expected: providers.Resource{Type: "route53:hostedzone", ID: "arn:aws:route53:::hostedzone/Z0123456789ABCDEFGHIJ"},
+		},
+		{
+			name:     "resource type separated by a colon",
+			arn:      "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer:app/test-x2k4f-int/0123456789abcdef",
+			expected: providers.Resource{Type: "elasticloadbalancing:loadbalancer", ID: "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer:app/test-x2k4f-int/0123456789abcdef", Region: "us-east-1"},
+		},
+		{
+			name:     "malformed ARN",
+			arn:      "i-0123456789abcdef0",
+			expected: providers.Resource{Type: "unknown", ID: "i-0123456789abcdef0"},
+		},
+		{
+			name:     "truncated ARN",
+			arn:      "arn:aws:ec2",
+			expected: providers.Resource{Type: "unknown", ID: "arn:aws:ec2"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, arnResource(tc.arn))
+		})
+	}
+}
+
+func TestSessionHandlersAreIdempotent(t *testing.T) {
+	awsSession, err := session.NewSession(aws.NewConfig().WithRegion("us-east-1").WithCredentials(credentials.NewStaticCredentials("AKIACLUSTER", "secret", "")))
+	require.NoError(t, err)
+	buildHandlers, sendHandlers := awsSession.Handlers.Build.Len(), awsSession.Handlers.Send.Len()
+
+	o := &ClusterUninstaller{Session: awsSession, RateLimits: providers.RateLimits{RequestsPerSecond: 10}}
+	// e.g. listing the resources, and then deleting them.
+	for i := 0; i < 2; i++ {
+		_, err := o.session()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, buildHandlers+1, awsSession.Handlers.Build.Len())
+	assert.Equal(t, sendHandlers+1, awsSession.Handlers.Send.Len())
+}
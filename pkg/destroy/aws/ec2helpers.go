@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
@@ -16,6 +17,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
 )
 
 // findEC2Instances returns the EC2 instances with tags that satisfy the filters.
@@ -23,7 +26,8 @@ import (
 // stage and the second list is the list of resources that are not terminated.
 //
 //	deleted - the resources that have already been deleted. Any resources specified in this set will be ignored.
-func findEC2Instances(ctx context.Context, ec2Client *ec2.EC2, deleted sets.String, filters []Filter, logger logrus.FieldLogger) ([]string, []string, error) {
+//	resourceFilter - the instances it excludes are ignored.
+func findEC2Instances(ctx context.Context, ec2Client *ec2.EC2, deleted sets.String, filters []Filter, resourceFilter providers.ResourceFilter, logger logrus.FieldLogger) ([]string, []string, error) {
 	if ec2Client.Config.Region == nil {
 		return nil, nil, errors.New("EC2 client does not have region configured")
 	}
@@ -58,6 +62,14 @@ func findEC2Instances(ctx context.Context, ec2Client *ec2.EC2, deleted sets.Stri
 							continue
 						}
 
+						tags := make(map[string]string, len(instance.Tags))
+						for _, tag := range instance.Tags {
+							tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+						}
+						if !resourceFilter.IncludesType("ec2:instance") || resourceFilter.ExcludesTags(tags) {
+							continue
+						}
+
 						instanceLogger := logger.WithField("instance", *instance.InstanceId)
 						arn := fmt.Sprintf("arn:%s:ec2:%s:%s:instance/%s", partition.ID(), *ec2Client.Config.Region, *reservation.OwnerId, *instance.InstanceId)
 						if *instance.State.Name == "terminated" {
@@ -85,7 +97,7 @@ func findEC2Instances(ctx context.Context, ec2Client *ec2.EC2, deleted sets.Stri
 	return resourcesRunning, resourcesNotTerminated, nil
 }
 
-func deleteEC2(ctx context.Context, session *session.Session, arn arn.ARN, logger logrus.FieldLogger) error {
+func deleteEC2(ctx context.Context, session *session.Session, arn arn.ARN, exclude providers.ResourceFilter, logger logrus.FieldLogger) error {
 	client := ec2.New(session)
 
 	resourceType, id, err := splitSlash("resource", arn.Resource)
@@ -122,7 +134,7 @@ func deleteEC2(ctx context.Context, session *session.Session, arn arn.ARN, logge
 	case "volume":
 		return deleteEC2Volume(ctx, client, id, logger)
 	case "vpc":
-		return deleteEC2VPC(ctx, client, elb.New(session), elbv2.New(session), id, logger)
+		return deleteEC2VPC(ctx, client, elb.New(session), elbv2.New(session), id, exclude, logger)
 	case "vpc-endpoint":
 		return deleteEC2VPCEndpoint(ctx, client, id, logger)
 	case "vpc-peering-connection":
@@ -692,7 +704,20 @@ func deleteEC2Volume(ctx context.Context, client *ec2.EC2, id string, logger log
 	return nil
 }
 
-func deleteEC2VPC(ctx context.Context, ec2Client *ec2.EC2, elbClient *elb.ELB, elbv2Client *elbv2.ELBV2, id string, logger logrus.FieldLogger) error {
+// deleteEC2VPC deletes the VPC with the resources it contains, which are not
+// always tagged. The VPC is kept when it contains subnets, security groups or
+// network interfaces which the resource filter excludes.
+func deleteEC2VPC(ctx context.Context, ec2Client *ec2.EC2, elbClient *elb.ELB, elbv2Client *elbv2.ELBV2, id string, exclude providers.ResourceFilter, logger logrus.FieldLogger) error {
+	if !exclude.IsEmpty() {
+		excluded, err := findExcludedVPCResources(ctx, ec2Client, id, exclude)
+		if err != nil {
+			return err
+		}
+		if len(excluded) > 0 {
+			return &keptResourceError{reason: fmt.Sprintf("contains the excluded resources %s", strings.Join(excluded, ", "))}
+		}
+	}
+
 	// first delete any Load Balancers under this VPC (not all of them are tagged)
 	v1lbError := deleteElasticLoadBalancerClassicByVPC(ctx, elbClient, id, logger)
 	v2lbError := deleteElasticLoadBalancerV2ByVPC(ctx, elbv2Client, id, logger)
@@ -733,6 +758,75 @@ func deleteEC2VPC(ctx context.Context, ec2Client *ec2.EC2, elbClient *elb.ELB, e
 	return nil
 }
 
+// findExcludedVPCResources returns the IDs of the subnets, security groups and
+// network interfaces of the VPC which the resource filter excludes.
+func findExcludedVPCResources(ctx context.Context, client *ec2.EC2, vpc string, exclude providers.ResourceFilter) ([]string, error) {
+	filters := []*ec2.Filter{{Name: aws.String("vpc-id"), Values: []*string{aws.String(vpc)}}}
+	var subnets []*ec2.Subnet
+	err := client.DescribeSubnetsPagesWithContext(ctx, &ec2.DescribeSubnetsInput{Filters: filters},
+		func(results *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			subnets = append(subnets, results.Subnets...)
+			return !lastPage
+		})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the subnets of VPC %s", vpc)
+	}
+	var groups []*ec2.SecurityGroup
+	err = client.DescribeSecurityGroupsPagesWithContext(ctx, &ec2.DescribeSecurityGroupsInput{Filters: filters},
+		func(results *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+			groups = append(groups, results.SecurityGroups...)
+			return !lastPage
+		})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the security groups of VPC %s", vpc)
+	}
+	var networkInterfaces []*ec2.NetworkInterface
+	err = client.DescribeNetworkInterfacesPagesWithContext(ctx, &ec2.DescribeNetworkInterfacesInput{Filters: filters},
+		func(results *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+			networkInterfaces = append(networkInterfaces, results.NetworkInterfaces...)
+			return !lastPage
+		})
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing the network interfaces of VPC %s", vpc)
+	}
+	return excludedVPCResources(subnets, groups, networkInterfaces, exclude), nil
+}
+
+// excludedVPCResources returns the IDs of the subnets, security groups and
+// network interfaces which the resource filter excludes, either by their tags
+// or by their type.
+func excludedVPCResources(subnets []*ec2.Subnet, groups []*ec2.SecurityGroup, networkInterfaces []*ec2.NetworkInterface, exclude providers.ResourceFilter) []string {
+	var excluded []string
+	for _, subnet := range subnets {
+		if !exclude.IncludesType("ec2:subnet") || exclude.ExcludesTags(ec2TagsMap(subnet.Tags)) {
+			excluded = append(excluded, aws.StringValue(subnet.SubnetId))
+		}
+	}
+	for _, group := range groups {
+		// The default security group is deleted with the VPC.
+		if aws.StringValue(group.GroupName) == "default" {
+			continue
+		}
+		if !exclude.IncludesType("ec2:security-group") || exclude.ExcludesTags(ec2TagsMap(group.Tags)) {
+			excluded = append(excluded, aws.StringValue(group.GroupId))
+		}
+	}
+	for _, networkInterface := range networkInterfaces {
+		if !exclude.IncludesType("ec2:network-interface") || exclude.ExcludesTags(ec2TagsMap(networkInterface.TagSet)) {
+			excluded = append(excluded, aws.StringValue(networkInterface.NetworkInterfaceId))
+		}
+	}
+	return excluded
+}
+
+func ec2TagsMap(ec2Tags []*ec2.Tag) map[string]string {
+	tags := make(map[string]string, len(ec2Tags))
+	for _, tag := range ec2Tags {
+		tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	return tags
+}
+
 func deleteEC2VPCEndpoint(ctx context.Context, client *ec2.EC2, id string, logger logrus.FieldLogger) error {
 	_, err := client.DeleteVpcEndpointsWithContext(ctx, &ec2.DeleteVpcEndpointsInput{
 		VpcEndpointIds: []*string{aws.String(id)},
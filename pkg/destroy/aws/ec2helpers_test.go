@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
+)
+
+func TestExcludedVPCResources(t *testing.T) {
+	sharedTag := []*ec2.Tag{{Key: aws.String("shared"), Value: aws.String("true")}}
+	subnets := []*ec2.Subnet{
+		{SubnetId: aws.String("subnet-cluster")},
+		{SubnetId: aws.String("subnet-shared"), Tags: sharedTag},
+	}
+	groups := []*ec2.SecurityGroup{
+		{GroupId: aws.String("sg-default"), GroupName: aws.String("default"), Tags: sharedTag},
+		{GroupId: aws.String("sg-cluster"), GroupName: aws.String("cluster")},
+		{GroupId: aws.String("sg-shared"), GroupName: aws.String("shared"), Tags: sharedTag},
+	}
+	networkInterfaces := []*ec2.NetworkInterface{
+		{NetworkInterfaceId: aws.String("eni-cluster")},
+		{NetworkInterfaceId: aws.String("eni-shared"), TagSet: sharedTag},
+	}
+
+	cases := []struct {
+		name     string
+		filter   providers.ResourceFilter
+		expected []string
+	}{
+		{
+			name: "no filter",
+		},
+		{
+			name:     "excluded tag",
+			filter:   providers.ResourceFilter{ExcludeTags: []string{"shared=true"}},
+			expected: []string{"subnet-shared", "sg-shared", "eni-shared"},
+		},
+		{
+			name:   "other tag value",
+			filter: providers.ResourceFilter{ExcludeTags: []string{"shared=false"}},
+		},
+		{
+			name:     "types not included",
+			filter:   providers.ResourceFilter{IncludeTypes: []string{"ec2:vpc", "ec2:subnet"}},
+			expected: []string{"sg-cluster", "sg-shared", "eni-cluster", "eni-shared"},
+		},
+		{
+			name:   "service included",
+			filter: providers.ResourceFilter{IncludeTypes: []string{"ec2"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, excludedVPCResources(subnets, groups, networkInterfaces, tc.filter))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -12,11 +13,46 @@ const (
 
 // errorTracker holds a history of errors
 type errorTracker struct {
+	mu      sync.Mutex
 	history map[string]time.Time
+	// failures holds when each identifier started failing.
+	failures map[string]time.Time
+	// gaveUp holds the identifiers which were given up on.
+	gaveUp []string
+}
+
+// failingSince records that the identifier failed and returns when it
+// started failing.
+func (o *errorTracker) failingSince(identifier string) time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.failures == nil {
+		o.failures = map[string]time.Time{}
+	}
+	if _, ok := o.failures[identifier]; !ok {
+		o.failures[identifier] = time.Now()
+	}
+	return o.failures[identifier]
+}
+
+// giveUp records that the identifier was given up on.
+func (o *errorTracker) giveUp(identifier string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.gaveUp = append(o.gaveUp, identifier)
+}
+
+// givenUp returns the identifiers which were given up on.
+func (o *errorTracker) givenUp() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.gaveUp...)
 }
 
 // suppressWarning logs errors WARN once every duration and the rest to DEBUG
 func (o *errorTracker) suppressWarning(identifier string, err error, logger logrus.FieldLogger) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
 	if o.history == nil {
 		o.history = map[string]time.Time{}
 	}
@@ -12,11 +12,14 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
 )
 
 type iamRoleSearch struct {
 	client    *iam.IAM
 	filters   []Filter
+	exclude   providers.ResourceFilter
 	logger    logrus.FieldLogger
 	unmatched map[string]struct{}
 }
@@ -66,7 +69,7 @@ func (search *iamRoleSearch) find(ctx context.Context) (arns []string, names []s
 					for _, tag := range role.Tags {
 						tags[*tag.Key] = *tag.Value
 					}
-					if tagMatch(search.filters, tags) {
+					if tagMatch(search.filters, tags) && !search.exclude.ExcludesTags(tags) {
 						arns = append(arns, *role.Arn)
 						names = append(names, *role.RoleName)
 					} else {
@@ -88,6 +91,7 @@ func (search *iamRoleSearch) find(ctx context.Context) (arns []string, names []s
 type iamUserSearch struct {
 	client    *iam.IAM
 	filters   []Filter
+	exclude   providers.ResourceFilter
 	logger    logrus.FieldLogger
 	unmatched map[string]struct{}
 }
@@ -137,7 +141,7 @@ func (search *iamUserSearch) arns(ctx context.Context) ([]string, error) {
 					for _, tag := range user.Tags {
 						tags[*tag.Key] = *tag.Value
 					}
-					if tagMatch(search.filters, tags) {
+					if tagMatch(search.filters, tags) && !search.exclude.ExcludesTags(tags) {
 						arns = append(arns, *user.Arn)
 					} else {
 						search.unmatched[*user.Arn] = exists
@@ -0,0 +1,129 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
+
+	awssession "github.com/openshift/installer/pkg/asset/installconfig/aws"
+)
+
+// destroyPermissionGroups are the permission groups of the calls made to find
+// and delete the resources of a cluster. The permissions only needed to untag
+// the shared resources of a cluster are not included.
+var destroyPermissionGroups = []awssession.PermissionGroup{
+	awssession.PermissionDeleteBase,
+	awssession.PermissionDeleteNetworking,
+	awssession.PermissionDeleteHostedZone,
+	awssession.PermissionDestroyCluster,
+}
+
+// Preflight implements providers.Preflighter. It simulates the policies of
+// the principal of the credentials and fails, listing the missing
+// permissions, when they are not allowed to delete the resources of the
+// cluster. The check is skipped with a warning when the policies cannot be
+// simulated, e.g. without the iam:SimulatePrincipalPolicy permission.
+func (o *ClusterUninstaller) Preflight(ctx context.Context) error {
+	awsSession := o.Session
+	if awsSession == nil {
+		var err error
+		awsSession, err = session.NewSession(aws.NewConfig().WithRegion(o.Region))
+		if err != nil {
+			return err
+		}
+	}
+
+	identity, err := sts.New(awsSession).GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the identity of the credentials")
+	}
+	iamClient := iam.New(awsSession)
+	principal, err := policySourceARN(ctx, iamClient, aws.StringValue(identity.Arn))
+	if err != nil {
+		o.Logger.Warnf("Skipping the check of the permissions to destroy the cluster: %v", err)
+		return nil
+	}
+	if principal == "" {
+		// The root user of the account is allowed everything.
+		return nil
+	}
+
+	destroyActions, err := awssession.Permissions(destroyPermissionGroups)
+	if err != nil {
+		return err
+	}
+	missing, err := missingPermissions(ctx, iamClient, principal, destroyActions, o.Region)
+	if err != nil {
+		o.Logger.Warnf("Skipping the check of the permissions to destroy the cluster: %v", err)
+		return nil
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("the credentials of %s are missing permissions to destroy the cluster: %s", principal, strings.Join(missing, ", "))
+	}
+	o.Logger.Debugf("The credentials of %s are allowed to destroy the cluster", principal)
+	return nil
+}
+
+// policySourceARN returns the ARN of the IAM user or role whose policies
+// apply to the caller with the ARN, or an empty ARN for the root user of the
+// account. The ARN of an assumed role session is that of its role.
+func policySourceARN(ctx context.Context, client iamiface.IAMAPI, callerARN string) (string, error) {
+	parsed, err := arn.Parse(callerARN)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse the ARN %s", callerARN)
+	}
+	switch {
+	case parsed.Service == "iam" && parsed.Resource == "root":
+		return "", nil
+	case parsed.Service == "iam" && strings.HasPrefix(parsed.Resource, "user/"):
+		return callerARN, nil
+	case parsed.Service == "sts" && strings.HasPrefix(parsed.Resource, "assumed-role/"):
+		// assumed-role/<role name>/<session name>, and the role may have a
+		// path, which is only known from the role.
+		name := strings.Split(parsed.Resource, "/")[1]
+		role, err := client.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String(name)})
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get the role %s", name)
+		}
+		return aws.StringValue(role.Role.Arn), nil
+	default:
+		return "", errors.Errorf("the policies of %s cannot be simulated", callerARN)
+	}
+}
+
+// missingPermissions returns the actions which the policies of the
+// principal do not allow in the region.
+func missingPermissions(ctx context.Context, client iamiface.IAMAPI, principal string, actions []string, region string) ([]string, error) {
+	input := &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     aws.StringSlice(actions),
+	}
+	if region != "" {
+		input.ContextEntries = []*iam.ContextEntry{{
+			ContextKeyName:   aws.String("aws:RequestedRegion"),
+			ContextKeyType:   aws.String(iam.ContextKeyTypeEnumString),
+			ContextKeyValues: aws.StringSlice([]string{region}),
+		}}
+	}
+
+	var missing []string
+	err := client.SimulatePrincipalPolicyPagesWithContext(ctx, input, func(page *iam.SimulatePolicyResponse, lastPage bool) bool {
+		for _, result := range page.EvaluationResults {
+			if aws.StringValue(result.EvalDecision) != iam.PolicyEvaluationDecisionTypeAllowed {
+				missing = append(missing, aws.StringValue(result.EvalActionName))
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to simulate the policies")
+	}
+	return missing, nil
+}
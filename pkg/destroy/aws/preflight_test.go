@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	awssession "github.com/openshift/installer/pkg/asset/installconfig/aws"
+)
+
+type fakeIAM struct {
+	iamiface.IAMAPI
+	roles   map[string]string
+	allowed map[string]bool
+	input   *iam.SimulatePrincipalPolicyInput
+}
+
+func (f *fakeIAM) GetRoleWithContext(_ aws.Context, input *iam.GetRoleInput, _ ...request.Option) (*iam.GetRoleOutput, error) {
+	arn, ok := f.roles[aws.StringValue(input.RoleName)]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return &iam.GetRoleOutput{Role: &iam.Role{Arn: aws.String(arn)}}, nil
+}
+
+func (f *fakeIAM) SimulatePrincipalPolicyPagesWithContext(_ aws.Context, input *iam.SimulatePrincipalPolicyInput, fn func(*iam.SimulatePolicyResponse, bool) bool, _ ...request.Option) error {
+	f.input = input
+	page := &iam.SimulatePolicyResponse{}
+	for _, action := range input.ActionNames {
+		decision := iam.PolicyEvaluationDecisionTypeImplicitDeny
+		if f.allowed[aws.StringValue(action)] {
+			decision = iam.PolicyEvaluationDecisionTypeAllowed
+		}
+		page.EvaluationResults = append(page.EvaluationResults, &iam.EvaluationResult{
+			EvalActionName: action,
+			EvalDecision:   aws.String(decision),
+		})
+	}
+	fn(page, true)
+	return nil
+}
+
+func TestDestroyPermissions(t *testing.T) {
+	actions, err := awssession.Permissions(destroyPermissionGroups)
+	require.NoError(t, err)
+	for _, action := range []string{
+		"tag:GetResources",
+		"ec2:TerminateInstances",
+		"ec2:DeleteVpc",
+		"ec2:DeleteSecurityGroup",
+		"elasticloadbalancing:DeleteLoadBalancer",
+		"iam:DeleteRole",
+		"route53:DeleteHostedZone",
+		"route53:ChangeResourceRecordSets",
+		"s3:DeleteBucket",
+	} {
+		assert.Contains(t, actions, action)
+	}
+	assert.NotContains(t, actions, "ec2:RunInstances", "destroying a cluster does not require the permissions to create it")
+	assert.NotContains(t, actions, "tag:UnTagResources", "the permissions only needed for shared resources are not required")
+}
+
+func TestPolicySourceARN(t *testing.T) {
+	client := &fakeIAM{roles: map[string]string{"installer": "arn:aws:iam::123456789012:role/ci/installer"}}
+	cases := []struct {
+		name          string
+		callerARN     string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:      "root",
+			callerARN: "arn:aws:iam::123456789012:root",
+		},
+		{
+			name:      "user",
+			callerARN: "arn:aws:iam::123456789012:user/installer",
+			expected:  "arn:aws:iam::123456789012:user/installer",
+		},
+		{
+			name:      "assumed role with a path",
+			callerARN: "arn:aws:sts::123456789012:assumed-role/installer/session",
+			expected:  "arn:aws:iam::123456789012:role/ci/installer",
+		},
+		{
+			name:          "federated user",
+			callerARN:     "arn:aws:sts::123456789012:federated-user/installer",
+			expectedError: "the policies of arn:aws:sts::123456789012:federated-user/installer cannot be simulated",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := policySourceARN(context.Background(), client, tc.callerARN)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, principal)
+		})
+	}
+}
+
+func TestMissingPermissions(t *testing.T) {
+	cases := []struct {
+		name     string
+		allowed  []string
+		region   string
+		expected []string
+	}{
+		{
+			name:    "all allowed",
+			allowed: []string{"ec2:DeleteVpc", "ec2:TerminateInstances"},
+			region:  "us-east-1",
+		},
+		{
+			name:     "missing",
+			allowed:  []string{"ec2:DeleteVpc"},
+			expected: []string{"ec2:TerminateInstances"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeIAM{allowed: map[string]bool{}}
+			for _, action := range tc.allowed {
+				client.allowed[action] = true
+			}
+			missing, err := missingPermissions(context.Background(), client, "arn:aws:iam::123456789012:user/installer", []string{"ec2:DeleteVpc", "ec2:TerminateInstances"}, tc.region)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, missing)
+			if tc.region != "" {
+				assert.Equal(t, tc.region, aws.StringValue(client.input.ContextEntries[0].ContextKeyValues[0]))
+			} else {
+				assert.Empty(t, client.input.ContextEntries)
+			}
+		})
+	}
+}
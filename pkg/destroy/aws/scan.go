@@ -0,0 +1,289 @@
+package aws
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	awssession "github.com/openshift/installer/pkg/asset/installconfig/aws"
+	"github.com/openshift/installer/pkg/types"
+	awstypes "github.com/openshift/installer/pkg/types/aws"
+)
+
+// clusterTagPrefix is the prefix of the key of the tag which the installer
+// puts on the resources it creates, followed by the infra ID of the cluster.
+const clusterTagPrefix = "kubernetes.io/cluster/"
+
+// ScanOptions selects the clusters searched by Scan.
+type ScanOptions struct {
+	// InfraIDs are the infra IDs of the clusters to search for. When empty,
+	// every cluster whose infra ID starts with NamePrefix is searched for.
+	InfraIDs   []string
+	NamePrefix string
+
+	// Regions are the regions searched, all the regions enabled in the
+	// account when empty.
+	Regions []string
+
+	// KnownInfraIDs are the infra IDs of the clusters which still have
+	// their metadata, which are not orphaned.
+	KnownInfraIDs sets.String
+}
+
+// OrphanedCluster is a cluster with resources left in the account.
+type OrphanedCluster struct {
+	InfraID string
+	// ClusterDomain is the domain of the cluster, from the name of its
+	// hosted zone, empty when it has none left.
+	ClusterDomain string
+	// Regions are the regions with resources of the cluster. Global
+	// resources, e.g. IAM roles, have no region.
+	Regions []string
+	// Resources are the ARNs of the resources of the cluster.
+	Resources []string
+}
+
+// Scan searches the account for the resources owned by clusters which are not
+// known, across the regions.
+func Scan(ctx context.Context, logger logrus.FieldLogger, opts ScanOptions) ([]OrphanedCluster, error) {
+	awsSession, err := awssession.GetSessionWithOptions()
+	if err != nil {
+		return nil, err
+	}
+	regions := opts.Regions
+	if len(regions) == 0 {
+		if regions, err = enabledRegions(ctx, awsSession); err != nil {
+			return nil, err
+		}
+	}
+
+	clusters := map[string]*OrphanedCluster{}
+	var errs []error
+	for _, region := range regions {
+		logger.Debugf("Scanning %s", region)
+		client := resourcegroupstaggingapi.New(awsSession, aws.NewConfig().WithRegion(region))
+		infraIDs := opts.InfraIDs
+		if len(infraIDs) == 0 {
+			if infraIDs, err = clusterInfraIDs(ctx, client, opts.NamePrefix); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to list the clusters in %s", region))
+				continue
+			}
+		}
+		for _, infraID := range infraIDs {
+			if opts.KnownInfraIDs.Has(infraID) {
+				logger.Debugf("Skipping %s, which has its metadata", infraID)
+				continue
+			}
+			arns, err := ownedResources(ctx, client, infraID)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to list the resources of %s in %s", infraID, region))
+				continue
+			}
+			for _, resourceARN := range arns {
+				cluster, ok := clusters[infraID]
+				if !ok {
+					cluster = &OrphanedCluster{InfraID: infraID}
+					clusters[infraID] = cluster
+				}
+				cluster.add(resourceARN)
+			}
+		}
+	}
+
+	// Route53 is global, its hosted zones are tagged in us-east-1.
+	route53Tagging := resourcegroupstaggingapi.New(awsSession, aws.NewConfig().WithRegion(endpoints.UsEast1RegionID))
+	route53Client := route53.New(awsSession)
+	orphaned := make([]OrphanedCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.ClusterDomain, err = clusterDomain(ctx, route53Tagging, route53Client, cluster.InfraID); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to find the domain of %s", cluster.InfraID))
+		}
+		orphaned = append(orphaned, *cluster)
+	}
+	sort.Slice(orphaned, func(i, j int) bool { return orphaned[i].InfraID < orphaned[j].InfraID })
+	return orphaned, utilerrors.NewAggregate(errs)
+}
+
+// add adds the resource to the cluster, once, with its region.
+func (c *OrphanedCluster) add(resourceARN string) {
+	for _, existing := range c.Resources {
+		if existing == resourceARN {
+			return
+		}
+	}
+	c.Resources = append(c.Resources, resourceARN)
+	parsed, err := arn.Parse(resourceARN)
+	if err != nil || parsed.Region == "" {
+		return
+	}
+	for _, region := range c.Regions {
+		if region == parsed.Region {
+			return
+		}
+	}
+	c.Regions = append(c.Regions, parsed.Region)
+	sort.Strings(c.Regions)
+}
+
+// Metadata returns the metadata to destroy the cluster in the region.
+func (c *OrphanedCluster) Metadata(region string) *types.ClusterMetadata {
+	return &types.ClusterMetadata{
+		ClusterName: c.InfraID,
+		InfraID:     c.InfraID,
+		ClusterPlatformMetadata: types.ClusterPlatformMetadata{
+			AWS: &awstypes.Metadata{
+				Region:        region,
+				ClusterDomain: c.ClusterDomain,
+				Identifier:    []map[string]string{{clusterTagPrefix + c.InfraID: "owned"}},
+			},
+		},
+	}
+}
+
+// Destroy deletes the resources of the cluster in each of its regions, or
+// in us-east-1 when it only has global resources left.
+func (c *OrphanedCluster) Destroy(logger logrus.FieldLogger) error {
+	regions := c.Regions
+	if len(regions) == 0 {
+		regions = []string{endpoints.UsEast1RegionID}
+	}
+	for _, region := range regions {
+		destroyer, err := New(logger, c.Metadata(region))
+		if err != nil {
+			return err
+		}
+		if _, err := destroyer.Run(); err != nil {
+			return errors.Wrapf(err, "failed to destroy %s in %s", c.InfraID, region)
+		}
+	}
+	return nil
+}
+
+// enabledRegions returns the regions enabled in the account.
+func enabledRegions(ctx context.Context, awsSession *session.Session) ([]string, error) {
+	cfg := aws.NewConfig()
+	if aws.StringValue(awsSession.Config.Region) == "" {
+		cfg = cfg.WithRegion(endpoints.UsEast1RegionID)
+	}
+	client := ec2.New(awsSession, cfg)
+	result, err := client.DescribeRegionsWithContext(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the regions")
+	}
+	regions := make([]string, 0, len(result.Regions))
+	for _, region := range result.Regions {
+		regions = append(regions, aws.StringValue(region.RegionName))
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
+
+// clusterInfraIDs returns the infra IDs, starting with the prefix, of the
+// clusters tagging resources in the region of the client.
+func clusterInfraIDs(ctx context.Context, client *resourcegroupstaggingapi.ResourceGroupsTaggingAPI, prefix string) ([]string, error) {
+	var infraIDs []string
+	err := client.GetTagKeysPagesWithContext(ctx, &resourcegroupstaggingapi.GetTagKeysInput{},
+		func(results *resourcegroupstaggingapi.GetTagKeysOutput, lastPage bool) bool {
+			for _, key := range results.TagKeys {
+				if infraID, ok := tagKeyInfraID(aws.StringValue(key), prefix); ok {
+					infraIDs = append(infraIDs, infraID)
+				}
+			}
+			return !lastPage
+		})
+	return infraIDs, err
+}
+
+// tagKeyInfraID returns the infra ID of the cluster of the tag key, and false
+// if the key is not a cluster tag or the infra ID does not start with the
+// prefix.
+func tagKeyInfraID(key, prefix string) (string, bool) {
+	if !strings.HasPrefix(key, clusterTagPrefix) {
+		return "", false
+	}
+	infraID := strings.TrimPrefix(key, clusterTagPrefix)
+	if infraID == "" || !strings.HasPrefix(infraID, prefix) {
+		return "", false
+	}
+	return infraID, true
+}
+
+// clusterDomain returns the domain of the cluster, which is the name of the
+// hosted zone tagged with the cluster tag, whether the zone is owned or
+// shared. An empty domain is returned when there is no such zone.
+func clusterDomain(ctx context.Context, tagging *resourcegroupstaggingapi.ResourceGroupsTaggingAPI, client *route53.Route53, infraID string) (string, error) {
+	var zoneIDs []string
+	err := tagging.GetResourcesPagesWithContext(ctx,
+		&resourcegroupstaggingapi.GetResourcesInput{
+			TagFilters:          []*resourcegroupstaggingapi.TagFilter{{Key: aws.String(clusterTagPrefix + infraID)}},
+			ResourceTypeFilters: []*string{aws.String("route53:hostedzone")},
+		},
+		func(results *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+			for _, resource := range results.ResourceTagMappingList {
+				if id, ok := hostedZoneID(aws.StringValue(resource.ResourceARN)); ok {
+					zoneIDs = append(zoneIDs, id)
+				}
+			}
+			return !lastPage
+		})
+	if err != nil {
+		return "", err
+	}
+	for _, id := range zoneIDs {
+		response, err := client.GetHostedZoneWithContext(ctx, &route53.GetHostedZoneInput{Id: aws.String(id)})
+		if err != nil {
+			return "", err
+		}
+		if name := strings.TrimSuffix(aws.StringValue(response.HostedZone.Name), "."); name != "" {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// hostedZoneID returns the ID of the hosted zone of the ARN, and false if the
+// ARN is not the one of a hosted zone.
+func hostedZoneID(resourceARN string) (string, bool) {
+	parsed, err := arn.Parse(resourceARN)
+	if err != nil || parsed.Service != "route53" {
+		return "", false
+	}
+	resourceType, id, err := splitSlash("resource", parsed.Resource)
+	if err != nil || resourceType != "hostedzone" {
+		return "", false
+	}
+	return id, true
+}
+
+// ownedResources returns the ARNs of the resources owned by the cluster in
+// the region of the client.
+func ownedResources(ctx context.Context, client *resourcegroupstaggingapi.ResourceGroupsTaggingAPI, infraID string) ([]string, error) {
+	var arns []string
+	err := client.GetResourcesPagesWithContext(ctx,
+		&resourcegroupstaggingapi.GetResourcesInput{
+			TagFilters: []*resourcegroupstaggingapi.TagFilter{{
+				Key:    aws.String(clusterTagPrefix + infraID),
+				Values: []*string{aws.String("owned")},
+			}},
+			ResourcesPerPage: aws.Int64(100),
+		},
+		func(results *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+			for _, resource := range results.ResourceTagMappingList {
+				arns = append(arns, aws.StringValue(resource.ResourceARN))
+			}
+			return !lastPage
+		})
+	return arns, err
+}
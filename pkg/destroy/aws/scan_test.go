@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/types"
+	awstypes "github.com/openshift/installer/pkg/types/aws"
+)
+
+func TestTagKeyInfraID(t *testing.T) {
+	cases := []struct {
+		name    string
+		key     string
+		prefix  string
+		infraID string
+		ok      bool
+	}{
+		{name: "cluster tag", key: "kubernetes.io/cluster/ci-op-abc-x7k2q", infraID: "ci-op-abc-x7k2q", ok: true},
+		{name: "matching prefix", key: "kubernetes.io/cluster/ci-op-abc-x7k2q", prefix: "ci-op-", infraID: "ci-op-abc-x7k2q", ok: true},
+		{name: "other prefix", key: "kubernetes.io/cluster/prod-x7k2q", prefix: "ci-op-"},
+		{name: "other tag", key: "sigs.k8s.io/cluster-api-provider-aws/cluster/ci-op-abc"},
+		{name: "no infra ID", key: "kubernetes.io/cluster/"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			infraID, ok := tagKeyInfraID(tc.key, tc.prefix)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.infraID, infraID)
+		})
+	}
+}
+
+func TestHostedZoneID(t *testing.T) {
+	cases := []struct {
+		arn string
+		id  string
+		ok  bool
+	}{
+		{arn: "arn:aws:route53:::hostedzone/Z0123456789ABC", id: "Z0123456789ABC", ok: true},
+		{arn: "arn:aws:route53:::healthcheck/1234"},
+		{arn: "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123"},
+		{arn: "not-an-arn"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.arn, func(t *testing.T) {
+			id, ok := hostedZoneID(tc.arn)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.id, id)
+		})
+	}
+}
+
+func TestOrphanedClusterAdd(t *testing.T) {
+	c := &OrphanedCluster{InfraID: "ci-op-x7k2q"}
+	c.add("arn:aws:ec2:us-west-2:123456789012:vpc/vpc-0123")
+	c.add("arn:aws:ec2:us-east-1:123456789012:instance/i-0123")
+	c.add("arn:aws:ec2:us-west-2:123456789012:vpc/vpc-0123")
+	c.add("arn:aws:iam::123456789012:role/ci-op-x7k2q-master-role")
+
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, c.Regions)
+	assert.Len(t, c.Resources, 3)
+}
+
+func TestOrphanedClusterMetadata(t *testing.T) {
+	c := &OrphanedCluster{InfraID: "ci-op-x7k2q", ClusterDomain: "ci-op.example.com"}
+	assert.Equal(t, &types.ClusterMetadata{
+		ClusterName: "ci-op-x7k2q",
+		InfraID:     "ci-op-x7k2q",
+		ClusterPlatformMetadata: types.ClusterPlatformMetadata{
+			AWS: &awstypes.Metadata{
+				Region:        "us-west-2",
+				ClusterDomain: "ci-op.example.com",
+				Identifier:    []map[string]string{{"kubernetes.io/cluster/ci-op-x7k2q": "owned"}},
+			},
+		},
+	}, c.Metadata("us-west-2"))
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
@@ -210,10 +209,9 @@ func (o *ClusterUninstaller) cleanSharedHostedZone(ctx context.Context, session
 	// in which case we need a separate client.
 	publicZoneClient := route53.New(session)
 	privateZoneClient := route53.New(session)
-	if o.HostedZoneRole != "" {
-		creds := stscreds.NewCredentials(session, o.HostedZoneRole)
-		privateZoneClient = route53.New(session, &aws.Config{Credentials: creds})
-		logger.Infof("Assuming role %s to destroy records in private hosted zone", o.HostedZoneRole)
+	if o.networkSession != nil {
+		privateZoneClient = route53.New(o.networkSession)
+		logger.Info("Using the credentials of the network account to destroy records in private hosted zone")
 	}
 
 	if o.ClusterDomain == "" {
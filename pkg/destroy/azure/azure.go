@@ -47,6 +47,10 @@ type ClusterUninstaller struct {
 	ResourceGroupName           string
 	BaseDomainResourceGroupName string
 
+	// ImageRegistryPreserved keeps the storage account of the image
+	// registry, moved to its own resource group.
+	ImageRegistryPreserved bool
+
 	Logger logrus.FieldLogger
 
 	resourceGroupsClient    resources.GroupsClient
@@ -122,6 +126,11 @@ func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (providers.
 	}, nil
 }
 
+// PreserveImageRegistry implements providers.ImageRegistryPreserver.
+func (o *ClusterUninstaller) PreserveImageRegistry() {
+	o.ImageRegistryPreserved = true
+}
+
 // Run is the entrypoint to start the uninstall process.
 func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 	var errs []error
@@ -132,6 +141,13 @@ func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 		return nil, err
 	}
 
+	if o.ImageRegistryPreserved {
+		// The storage would be deleted with the resource group otherwise.
+		if err := o.preserveImageRegistry(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
 	// 2 hours
 	timeout := 120 * time.Minute
 	waitCtx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -229,6 +245,45 @@ func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 	return nil, utilerrors.NewAggregate(errs)
 }
 
+// ListResources returns the resource group of the cluster and the resources
+// in it, which Run deletes. The records of the cluster in the public DNS zone
+// and the application registrations, which Run deletes as well, are not
+// listed.
+func (o *ClusterUninstaller) ListResources(ctx context.Context) ([]providers.Resource, error) {
+	if err := o.configureClients(); err != nil {
+		return nil, err
+	}
+
+	group, err := o.resourceGroupsClient.Get(ctx, o.ResourceGroupName)
+	if err != nil {
+		if wasNotFound(group.Response.Response) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get the resource group %s", o.ResourceGroupName)
+	}
+	resourceList := []providers.Resource{{
+		Type:   to.String(group.Type),
+		ID:     to.String(group.ID),
+		Region: to.String(group.Location),
+	}}
+
+	client := resources.NewClientWithBaseURI(o.Environment.ResourceManagerEndpoint, o.SubscriptionID)
+	client.Authorizer = o.Authorizer
+	iter, err := client.ListByResourceGroupComplete(ctx, o.ResourceGroupName, "", "", nil)
+	for ; err == nil && iter.NotDone(); err = iter.NextWithContext(ctx) {
+		resource := iter.Value()
+		resourceList = append(resourceList, providers.Resource{
+			Type:   to.String(resource.Type),
+			ID:     to.String(resource.ID),
+			Region: to.String(resource.Location),
+		})
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the resources of the resource group %s", o.ResourceGroupName)
+	}
+	return resourceList, nil
+}
+
 func deleteAzureStackPublicRecords(ctx context.Context, o *ClusterUninstaller) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
@@ -0,0 +1,68 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+)
+
+// imageRegistryStorageAccountPrefix is the prefix of the name of the storage
+// account which the image registry operator creates in the resource group
+// of the cluster.
+const imageRegistryStorageAccountPrefix = "imageregistry"
+
+// preserveImageRegistry moves the storage account of the image registry out
+// of the resource group of the cluster, which is deleted with all its
+// resources, to the resource group `<infra ID>-image-registry-rg`.
+func (o *ClusterUninstaller) preserveImageRegistry(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	client := resources.NewClientWithBaseURI(o.Environment.ResourceManagerEndpoint, o.SubscriptionID)
+	client.Authorizer = o.Authorizer
+	var accounts []string
+	iter, err := client.ListByResourceGroupComplete(ctx, o.ResourceGroupName, "resourceType eq 'Microsoft.Storage/storageAccounts'", "", nil)
+	for ; err == nil && iter.NotDone(); err = iter.NextWithContext(ctx) {
+		if strings.HasPrefix(to.String(iter.Value().Name), imageRegistryStorageAccountPrefix) {
+			accounts = append(accounts, to.String(iter.Value().ID))
+		}
+	}
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to list the storage accounts of the resource group %s", o.ResourceGroupName)
+	}
+	if len(accounts) == 0 {
+		o.Logger.Debug("No image registry storage account to preserve")
+		return nil
+	}
+
+	group, err := o.resourceGroupsClient.Get(ctx, o.ResourceGroupName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the resource group %s", o.ResourceGroupName)
+	}
+	targetName := fmt.Sprintf("%s-image-registry-rg", o.InfraID)
+	target, err := o.resourceGroupsClient.CreateOrUpdate(ctx, targetName, resources.Group{Location: group.Location})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the resource group %s", targetName)
+	}
+
+	o.Logger.Infof("Moving the image registry storage to the resource group %s", targetName)
+	future, err := client.MoveResources(ctx, o.ResourceGroupName, resources.MoveInfo{
+		ResourcesProperty:   &accounts,
+		TargetResourceGroup: target.ID,
+	})
+	if err == nil {
+		err = future.WaitForCompletionRef(ctx, client.Client)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to move the image registry storage to the resource group %s", targetName)
+	}
+	return nil
+}
@@ -4,12 +4,15 @@
 package baremetal
 
 import (
+	"context"
+
 	"github.com/libvirt/libvirt-go"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/installer/pkg/destroy/providers"
 	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/baremetal"
 )
 
 // ClusterUninstaller holds the various options for the cluster we want to delete.
@@ -18,6 +21,15 @@ type ClusterUninstaller struct {
 	LibvirtURI              string
 	BootstrapProvisioningIP string
 	Logger                  logrus.FieldLogger
+
+	hostsToWipe []*baremetal.Host
+}
+
+var _ providers.HostWiper = (*ClusterUninstaller)(nil)
+
+// SetHostsToWipe makes Run erase the disks of the hosts and power them off.
+func (o *ClusterUninstaller) SetHostsToWipe(hosts []*baremetal.Host) {
+	o.hostsToWipe = hosts
 }
 
 // Run is the entrypoint to start the uninstall process.
@@ -34,6 +46,13 @@ func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 		return nil, errors.Wrap(err, "failed to clean baremetal bootstrap storage pool")
 	}
 
+	if len(o.hostsToWipe) > 0 {
+		o.Logger.Info("Wiping the disks of the hosts")
+		if err := WipeHosts(context.TODO(), o.Logger, o.hostsToWipe); err != nil {
+			return nil, err
+		}
+	}
+
 	o.Logger.Debug("FIXME: delete resources!")
 
 	return nil, nil
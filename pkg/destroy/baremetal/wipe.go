@@ -0,0 +1,59 @@
+package baremetal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/installer/pkg/bmc"
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+// WipeHosts connects to the BMC of each host, securely erases the drives of
+// the host, waiting for the BMC to complete erasing them, and powers it off, so that the stale RAID and partition signatures
+// of the cluster do not break re-provisioning the host. Only the BMCs
+// speaking Redfish are supported. The hosts which fail to be wiped are
+// reported in the returned error once every host was attempted.
+func WipeHosts(ctx context.Context, logger logrus.FieldLogger, hosts []*baremetal.Host) error {
+	var errs []error
+	for _, host := range hosts {
+		if err := wipeHost(ctx, logger.WithField("host", host.Name), host); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to wipe host %s", host.Name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func wipeHost(ctx context.Context, logger logrus.FieldLogger, host *baremetal.Host) error {
+	client, systemID, err := bmc.RedfishClientForHost(host)
+	if err != nil {
+		return errors.Wrap(err, "wiping the disks requires a Redfish BMC")
+	}
+	system, err := client.System(ctx, systemID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the system")
+	}
+
+	drives, err := client.Drives(ctx, system)
+	if err != nil {
+		return errors.Wrap(err, "failed to list the drives")
+	}
+	var errs []error
+	for _, drive := range drives {
+		logger.Infof("Erasing drive %s", drive)
+		if err := client.SecureErase(ctx, drive); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// The host is powered off even when some drives could not be erased, so
+	// that it does not keep running the destroyed cluster. SecureErase only
+	// returns once the erase task completed, so that it is not interrupted.
+	logger.Info("Powering off")
+	if err := client.PowerOff(ctx, system); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to power off"))
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,148 @@
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+type fakeRedfish struct {
+	mu        sync.Mutex
+	resources map[string]interface{}
+	posts     []string
+}
+
+func (f *fakeRedfish) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "password" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method == http.MethodPost {
+		f.posts = append(f.posts, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	resource, ok := f.resources[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(resource) //nolint:errcheck
+}
+
+func newFakeRedfish(powerState string, eraseDrive2 bool) *fakeRedfish {
+	drive2 := map[string]interface{}{}
+	if eraseDrive2 {
+		drive2["Actions"] = map[string]interface{}{
+			"#Drive.SecureErase": map[string]string{"target": "/redfish/v1/Systems/1/Storage/1/Drives/2/Actions/Drive.SecureErase"},
+		}
+	}
+	return &fakeRedfish{resources: map[string]interface{}{
+		"/redfish/v1/Systems/1": map[string]interface{}{
+			"PowerState": powerState,
+			"Storage":    map[string]string{"@odata.id": "/redfish/v1/Systems/1/Storage"},
+			"Actions": map[string]interface{}{
+				"#ComputerSystem.Reset": map[string]string{"target": "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset"},
+			},
+		},
+		"/redfish/v1/Systems/1/Storage": map[string]interface{}{
+			"Members": []map[string]string{{"@odata.id": "/redfish/v1/Systems/1/Storage/1"}},
+		},
+		"/redfish/v1/Systems/1/Storage/1": map[string]interface{}{
+			"Drives": []map[string]string{
+				{"@odata.id": "/redfish/v1/Systems/1/Storage/1/Drives/1"},
+				{"@odata.id": "/redfish/v1/Systems/1/Storage/1/Drives/2"},
+			},
+		},
+		"/redfish/v1/Systems/1/Storage/1/Drives/1": map[string]interface{}{
+			"Actions": map[string]interface{}{
+				"#Drive.SecureErase": map[string]string{"target": "/redfish/v1/Systems/1/Storage/1/Drives/1/Actions/Drive.SecureErase"},
+			},
+		},
+		"/redfish/v1/Systems/1/Storage/1/Drives/2": drive2,
+	}}
+}
+
+func TestWipeHosts(t *testing.T) {
+	cases := []struct {
+		name          string
+		powerState    string
+		eraseDrive2   bool
+		address       func(url string) string
+		expectedPosts []string
+		expectedError string
+	}{
+		{
+			name:        "erased and powered off",
+			powerState:  "On",
+			eraseDrive2: true,
+			expectedPosts: []string{
+				"/redfish/v1/Systems/1/Storage/1/Drives/1/Actions/Drive.SecureErase",
+				"/redfish/v1/Systems/1/Storage/1/Drives/2/Actions/Drive.SecureErase",
+				"/redfish/v1/Systems/1/Actions/ComputerSystem.Reset",
+			},
+		},
+		{
+			name:        "already powered off",
+			powerState:  "Off",
+			eraseDrive2: true,
+			expectedPosts: []string{
+				"/redfish/v1/Systems/1/Storage/1/Drives/1/Actions/Drive.SecureErase",
+				"/redfish/v1/Systems/1/Storage/1/Drives/2/Actions/Drive.SecureErase",
+			},
+		},
+		{
+			name:       "drive without secure erase is still powered off",
+			powerState: "On",
+			expectedPosts: []string{
+				"/redfish/v1/Systems/1/Storage/1/Drives/1/Actions/Drive.SecureErase",
+				"/redfish/v1/Systems/1/Actions/ComputerSystem.Reset",
+			},
+			expectedError: `failed to wipe host master-0: drive /redfish/v1/Systems/1/Storage/1/Drives/2 does not support secure erase`,
+		},
+		{
+			name:          "ipmi",
+			address:       func(string) string { return "ipmi://192.168.111.1" },
+			expectedError: `failed to wipe host master-0: wiping the disks requires a Redfish BMC: ipmi BMCs do not speak Redfish`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFakeRedfish(tc.powerState, tc.eraseDrive2)
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			address := strings.Replace(server.URL, "http://", "redfish+http://", 1) + "/redfish/v1/Systems/1"
+			if tc.address != nil {
+				address = tc.address(server.URL)
+			}
+			hosts := []*baremetal.Host{{
+				Name: "master-0",
+				BMC: baremetal.BMC{
+					Username: "admin",
+					Password: "password",
+					Address:  address,
+				},
+			}}
+
+			err := WipeHosts(context.Background(), logrus.StandardLogger(), hosts)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+			assert.Equal(t, tc.expectedPosts, fake.posts)
+		})
+	}
+}
@@ -2,12 +2,14 @@
 package bootstrap
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 
+	"github.com/openshift/installer/pkg/agent"
 	"github.com/openshift/installer/pkg/asset/cluster"
 	openstackasset "github.com/openshift/installer/pkg/asset/cluster/openstack"
 	osp "github.com/openshift/installer/pkg/destroy/openstack"
@@ -17,9 +19,14 @@ import (
 	"github.com/openshift/installer/pkg/types/openstack"
 )
 
-// Destroy uses Terraform to remove bootstrap resources.
+// Destroy uses Terraform to remove bootstrap resources. Installations with
+// the agent-based installer, which have no bootstrap resources, are cleaned
+// up after the bootstrap of the rendezvous host instead.
 func Destroy(dir string) (err error) {
 	metadata, err := cluster.LoadMetadata(dir)
+	if os.IsNotExist(err) && agent.IsAgentInstall(dir) {
+		return agent.DestroyBootstrap(context.Background(), dir)
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,180 @@
+package destroy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	operatorclient "github.com/openshift/client-go/operator/clientset/versioned"
+	"github.com/openshift/installer/pkg/types"
+	awstypes "github.com/openshift/installer/pkg/types/aws"
+	azuretypes "github.com/openshift/installer/pkg/types/azure"
+	gcptypes "github.com/openshift/installer/pkg/types/gcp"
+)
+
+// MetadataFromCluster reconstructs the metadata needed to destroy a running
+// cluster, whose `metadata.json` was lost, from its Infrastructure,
+// ClusterVersion, DNS and CloudCredential objects.
+func MetadataFromCluster(ctx context.Context, config *rest.Config) (*types.ClusterMetadata, error) {
+	configClient, err := configclient.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the config client")
+	}
+	operatorClient, err := operatorclient.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the operator client")
+	}
+	return metadataFromCluster(ctx, configClient, operatorClient)
+}
+
+func metadataFromCluster(ctx context.Context, configClient configclient.Interface, operatorClient operatorclient.Interface) (*types.ClusterMetadata, error) {
+	infra, err := configClient.ConfigV1().Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the Infrastructure")
+	}
+	version, err := configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the ClusterVersion")
+	}
+	dns, err := configClient.ConfigV1().DNSes().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the DNS")
+	}
+
+	infraID := infra.Status.InfrastructureName
+	if infraID == "" {
+		return nil, errors.New("the Infrastructure has no infrastructure name")
+	}
+	clusterDomain := dns.Spec.BaseDomain
+	clusterName, _, _ := strings.Cut(clusterDomain, ".")
+	metadata := &types.ClusterMetadata{
+		ClusterName: clusterName,
+		ClusterID:   string(version.Spec.ClusterID),
+		InfraID:     infraID,
+	}
+
+	status := infra.Status.PlatformStatus
+	if status == nil {
+		return nil, errors.New("the Infrastructure has no platform status")
+	}
+	switch {
+	case status.Type == configv1.AWSPlatformType && status.AWS != nil:
+		endpoints := make([]awstypes.ServiceEndpoint, 0, len(status.AWS.ServiceEndpoints))
+		for _, endpoint := range status.AWS.ServiceEndpoints {
+			endpoints = append(endpoints, awstypes.ServiceEndpoint{Name: endpoint.Name, URL: endpoint.URL})
+		}
+		hostedZoneRole := ""
+		if dns.Spec.Platform.AWS != nil {
+			hostedZoneRole = dns.Spec.Platform.AWS.PrivateZoneIAMRole
+		}
+		identifier := []map[string]string{{fmt.Sprintf("kubernetes.io/cluster/%s", infraID): "owned"}}
+		if metadata.ClusterID != "" {
+			identifier = append(identifier, map[string]string{"openshiftClusterID": metadata.ClusterID})
+		}
+		metadata.AWS = &awstypes.Metadata{
+			Region:           status.AWS.Region,
+			Identifier:       identifier,
+			ServiceEndpoints: endpoints,
+			ClusterDomain:    clusterDomain,
+			HostedZoneRole:   hostedZoneRole,
+		}
+	case status.Type == configv1.AzurePlatformType && status.Azure != nil:
+		metadata.Azure = &azuretypes.Metadata{
+			ARMEndpoint:                 status.Azure.ARMEndpoint,
+			CloudName:                   azuretypes.CloudEnvironment(status.Azure.CloudName),
+			ResourceGroupName:           status.Azure.ResourceGroupName,
+			BaseDomainResourceGroupName: azureResourceGroup(dns.Spec.PublicZone),
+		}
+		if metadata.Azure.CloudName == "" {
+			metadata.Azure.CloudName = azuretypes.PublicCloud
+		}
+	case status.Type == configv1.GCPPlatformType && status.GCP != nil:
+		metadata.GCP = &gcptypes.Metadata{
+			Region:            status.GCP.Region,
+			ProjectID:         status.GCP.ProjectID,
+			PrivateZoneDomain: fmt.Sprintf("%s.", clusterDomain),
+		}
+	default:
+		return nil, errors.Errorf("reconstructing the metadata is not supported on %q", status.Type)
+	}
+
+	logCredentialsMode(ctx, operatorClient)
+	return metadata, nil
+}
+
+// azureResourceGroup returns the resource group of the DNS zone, whose ID is
+// the Azure resource ID of the zone.
+func azureResourceGroup(zone *configv1.DNSZone) string {
+	if zone == nil {
+		return ""
+	}
+	segments := strings.Split(zone.ID, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if strings.EqualFold(segments[i], "resourceGroups") {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+// logCredentialsMode warns when the cloud credentials of the components of
+// the cluster were created out of the cluster, since destroying the cluster
+// does not delete them.
+func logCredentialsMode(ctx context.Context, operatorClient operatorclient.Interface) {
+	credential, err := operatorClient.OperatorV1().CloudCredentials().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			logrus.Debugf("Failed to get the CloudCredential: %v", err)
+		}
+		return
+	}
+	if credential.Spec.CredentialsMode == operatorv1.CloudCredentialsModeManual {
+		logrus.Warn("The cloud credentials of the cluster are managed manually, delete them, e.g. with ccoctl, once the cluster is destroyed")
+	}
+}
+
+// SyntheticMetadata returns the metadata to destroy the cluster with the
+// infra ID in the region, for partially created clusters whose
+// `metadata.json` was lost. It only identifies the resources of the cluster,
+// so e.g. the DNS records of the cluster in shared zones are left.
+func SyntheticMetadata(platform, region, infraID string) (*types.ClusterMetadata, error) {
+	if infraID == "" {
+		return nil, errors.New("the infra ID is required")
+	}
+	clusterName := infraID
+	if i := strings.LastIndex(infraID, "-"); i > 0 {
+		clusterName = infraID[:i]
+	}
+	metadata := &types.ClusterMetadata{
+		ClusterName: clusterName,
+		InfraID:     infraID,
+	}
+	switch platform {
+	case awstypes.Name:
+		if region == "" {
+			return nil, errors.Errorf("the region is required on %q", platform)
+		}
+		metadata.AWS = &awstypes.Metadata{
+			Region:     region,
+			Identifier: []map[string]string{{fmt.Sprintf("kubernetes.io/cluster/%s", infraID): "owned"}},
+		}
+	case azuretypes.Name:
+		metadata.Azure = &azuretypes.Metadata{
+			CloudName:         azuretypes.PublicCloud,
+			Region:            region,
+			ResourceGroupName: fmt.Sprintf("%s-rg", infraID),
+		}
+	default:
+		return nil, errors.Errorf("destroying a cluster without its metadata is not supported on %q", platform)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,151 @@
+package destroy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/installer/pkg/types"
+	awstypes "github.com/openshift/installer/pkg/types/aws"
+	azuretypes "github.com/openshift/installer/pkg/types/azure"
+)
+
+func TestMetadataFromCluster(t *testing.T) {
+	version := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "3f4b0c24-6a43-4a3b-9f0e-3c8a5c2b1d7e"},
+	}
+
+	cases := []struct {
+		name     string
+		status   *configv1.PlatformStatus
+		dns      configv1.DNSSpec
+		expected *types.ClusterMetadata
+		err      string
+	}{
+		{
+			name: "aws",
+			status: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS: &configv1.AWSPlatformStatus{
+					Region:           "us-east-1",
+					ServiceEndpoints: []configv1.AWSServiceEndpoint{{Name: "ec2", URL: "https://ec2.example.com"}},
+				},
+			},
+			dns: configv1.DNSSpec{BaseDomain: "test-cluster.example.com"},
+			expected: &types.ClusterMetadata{
+				ClusterName: "test-cluster",
+				ClusterID:   "3f4b0c24-6a43-4a3b-9f0e-3c8a5c2b1d7e",
+				InfraID:     "test-cluster-x2k4f",
+				ClusterPlatformMetadata: types.ClusterPlatformMetadata{
+					AWS: &awstypes.Metadata{
+						Region: "us-east-1",
+						Identifier: []map[string]string{
+							{"kubernetes.io/cluster/test-cluster-x2k4f": "owned"},
+							{"openshiftClusterID": "3f4b0c24-6a43-4a3b-9f0e-3c8a5c2b1d7e"},
+						},
+						ServiceEndpoints: []awstypes.ServiceEndpoint{{Name: "ec2", URL: "https://ec2.example.com"}},
+						ClusterDomain:    "test-cluster.example.com",
+					},
+				},
+			},
+		},
+		{
+			name: "azure",
+			status: &configv1.PlatformStatus{
+				Type:  configv1.AzurePlatformType,
+				Azure: &configv1.AzurePlatformStatus{ResourceGroupName: "test-cluster-x2k4f-rg"},
+			},
+			dns: configv1.DNSSpec{
+				BaseDomain: "test-cluster.example.com",
+				PublicZone: &configv1.DNSZone{ID: "/subscriptions/sub/resourceGroups/dns-rg/providers/Microsoft.Network/dnszones/example.com"},
+			},
+			expected: &types.ClusterMetadata{
+				ClusterName: "test-cluster",
+				ClusterID:   "3f4b0c24-6a43-4a3b-9f0e-3c8a5c2b1d7e",
+				InfraID:     "test-cluster-x2k4f",
+				ClusterPlatformMetadata: types.ClusterPlatformMetadata{
+					Azure: &azuretypes.Metadata{
+						CloudName:                   azuretypes.PublicCloud,
+						ResourceGroupName:           "test-cluster-x2k4f-rg",
+						BaseDomainResourceGroupName: "dns-rg",
+					},
+				},
+			},
+		},
+		{
+			name:   "unsupported",
+			status: &configv1.PlatformStatus{Type: configv1.BareMetalPlatformType},
+			err:    `reconstructing the metadata is not supported on "BareMetal"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			infra := &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status: configv1.InfrastructureStatus{
+					InfrastructureName: "test-cluster-x2k4f",
+					PlatformStatus:     tc.status,
+				},
+			}
+			dns := &configv1.DNS{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}, Spec: tc.dns}
+			objects := map[string]interface{}{
+				"/apis/config.openshift.io/v1/infrastructures/cluster": infra,
+				"/apis/config.openshift.io/v1/clusterversions/version": version,
+				"/apis/config.openshift.io/v1/dnses/cluster":           dns,
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				object, ok := objects[r.URL.Path]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(object) //nolint:errcheck
+			}))
+			defer server.Close()
+
+			metadata, err := MetadataFromCluster(context.Background(), &rest.Config{Host: server.URL})
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, metadata)
+			}
+		})
+	}
+}
+
+func TestSyntheticMetadata(t *testing.T) {
+	metadata, err := SyntheticMetadata("aws", "us-east-1", "mycluster-abc12")
+	if assert.NoError(t, err) {
+		assert.Equal(t, &types.ClusterMetadata{
+			ClusterName: "mycluster",
+			InfraID:     "mycluster-abc12",
+			ClusterPlatformMetadata: types.ClusterPlatformMetadata{
+				AWS: &awstypes.Metadata{
+					Region:     "us-east-1",
+					Identifier: []map[string]string{{"kubernetes.io/cluster/mycluster-abc12": "owned"}},
+				},
+			},
+		}, metadata)
+	}
+
+	metadata, err = SyntheticMetadata("azure", "eastus", "mycluster-abc12")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "mycluster-abc12-rg", metadata.Azure.ResourceGroupName)
+	}
+
+	_, err = SyntheticMetadata("aws", "", "mycluster-abc12")
+	assert.EqualError(t, err, `the region is required on "aws"`)
+	_, err = SyntheticMetadata("vsphere", "", "mycluster-abc12")
+	assert.EqualError(t, err, `destroying a cluster without its metadata is not supported on "vsphere"`)
+}
@@ -0,0 +1,136 @@
+package destroy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/asset/cluster"
+)
+
+// DefaultOCMURL is the URL of the OpenShift Cluster Manager API of
+// console.redhat.com, in which the clusters are registered.
+const DefaultOCMURL = "https://api.openshift.com"
+
+// ocmAuthRegistry is the registry of the pull secret whose token identifies
+// the clusters to the OpenShift Cluster Manager.
+const ocmAuthRegistry = "cloud.openshift.com"
+
+type ocmSubscription struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type ocmSubscriptionList struct {
+	Items []ocmSubscription `json:"items"`
+}
+
+// Deregister archives the subscription of the cluster based on
+// `metadata.json` in `rootDir` in the OpenShift Cluster Manager at apiURL,
+// so that the destroyed cluster does not linger in the subscription
+// inventory. It authenticates with the cloud.openshift.com token of the pull
+// secret in the install config of `rootDir`, like the cluster itself does.
+func Deregister(ctx context.Context, rootDir, apiURL string) error {
+	metadata, err := cluster.LoadMetadata(rootDir)
+	if err != nil {
+		return err
+	}
+	if metadata.ClusterID == "" {
+		return errors.New("the metadata has no cluster ID")
+	}
+	installConfig, err := loadInstallConfig(rootDir)
+	if err != nil {
+		return err
+	}
+	return deregister(ctx, &http.Client{Timeout: time.Minute}, apiURL, metadata.ClusterID, installConfig.Config.PullSecret)
+}
+
+func deregister(ctx context.Context, client *http.Client, apiURL, clusterID, pullSecret string) error {
+	token, err := ocmToken(pullSecret)
+	if err != nil {
+		return err
+	}
+	authorization := fmt.Sprintf("AccessToken %s:%s", clusterID, token)
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	query := url.Values{"search": []string{fmt.Sprintf("external_cluster_id='%s'", clusterID)}}
+	subscriptions := &ocmSubscriptionList{}
+	if err := ocmRequest(ctx, client, http.MethodGet, apiURL+"/api/accounts_mgmt/v1/subscriptions?"+query.Encode(), authorization, nil, subscriptions); err != nil {
+		return errors.Wrap(err, "failed to find the subscription of the cluster")
+	}
+	if len(subscriptions.Items) == 0 {
+		logrus.Infof("Cluster %s is not registered in the OpenShift Cluster Manager", clusterID)
+		return nil
+	}
+	for _, subscription := range subscriptions.Items {
+		if subscription.Status == "Archived" {
+			logrus.Debugf("Subscription %s of cluster %s is already archived", subscription.ID, clusterID)
+			continue
+		}
+		body := map[string]string{"status": "Archived"}
+		if err := ocmRequest(ctx, client, http.MethodPatch, apiURL+"/api/accounts_mgmt/v1/subscriptions/"+url.PathEscape(subscription.ID), authorization, body, nil); err != nil {
+			return errors.Wrapf(err, "failed to archive subscription %s", subscription.ID)
+		}
+		logrus.Infof("Archived subscription %s of cluster %s in the OpenShift Cluster Manager", subscription.ID, clusterID)
+	}
+	return nil
+}
+
+// ocmToken returns the cloud.openshift.com token of the pull secret.
+func ocmToken(pullSecret string) (string, error) {
+	var secret struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal([]byte(pullSecret), &secret); err != nil {
+		return "", errors.Wrap(err, "failed to parse the pull secret")
+	}
+	auth, ok := secret.Auths[ocmAuthRegistry]
+	if !ok || auth.Auth == "" {
+		return "", errors.Errorf("the pull secret has no %s token", ocmAuthRegistry)
+	}
+	return auth.Auth, nil
+}
+
+func ocmRequest(ctx context.Context, client *http.Client, method, endpoint, authorization string, body, into interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if into == nil {
+		return nil
+	}
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(into), "failed to decode the response")
+}
@@ -0,0 +1,91 @@
+package destroy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeregister(t *testing.T) {
+	const (
+		clusterID  = "3f4b0c24-6a43-4a3b-9f0e-3c8a5c2b1d7e"
+		pullSecret = `{"auths":{"cloud.openshift.com":{"auth":"b2NtOnRva2Vu"},"quay.io":{"auth":"cXVheTp0b2tlbg=="}}}`
+	)
+
+	cases := []struct {
+		name          string
+		pullSecret    string
+		subscriptions []ocmSubscription
+		patchStatus   int
+		expectedPatch []string
+		expectedError string
+	}{
+		{
+			name:          "archived",
+			pullSecret:    pullSecret,
+			subscriptions: []ocmSubscription{{ID: "sub-1", Status: "Active"}},
+			expectedPatch: []string{"/api/accounts_mgmt/v1/subscriptions/sub-1"},
+		},
+		{
+			name:          "already archived",
+			pullSecret:    pullSecret,
+			subscriptions: []ocmSubscription{{ID: "sub-1", Status: "Archived"}},
+		},
+		{
+			name:       "not registered",
+			pullSecret: pullSecret,
+		},
+		{
+			name:          "archive fails",
+			pullSecret:    pullSecret,
+			subscriptions: []ocmSubscription{{ID: "sub-1", Status: "Disconnected"}},
+			patchStatus:   http.StatusForbidden,
+			expectedPatch: []string{"/api/accounts_mgmt/v1/subscriptions/sub-1"},
+			expectedError: "failed to archive subscription sub-1: 403 Forbidden: denied",
+		},
+		{
+			name:          "no token",
+			pullSecret:    `{"auths":{"quay.io":{"auth":"cXVheTp0b2tlbg=="}}}`,
+			expectedError: "the pull secret has no cloud.openshift.com token",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var patched []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "AccessToken "+clusterID+":b2NtOnRva2Vu" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				switch r.Method {
+				case http.MethodGet:
+					assert.Equal(t, "/api/accounts_mgmt/v1/subscriptions", r.URL.Path)
+					assert.Equal(t, "external_cluster_id='"+clusterID+"'", r.URL.Query().Get("search"))
+					json.NewEncoder(w).Encode(ocmSubscriptionList{Items: tc.subscriptions}) //nolint:errcheck
+				case http.MethodPatch:
+					patched = append(patched, r.URL.Path)
+					var body map[string]string
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+					assert.Equal(t, map[string]string{"status": "Archived"}, body)
+					if tc.patchStatus != 0 {
+						w.WriteHeader(tc.patchStatus)
+						w.Write([]byte("denied")) //nolint:errcheck
+					}
+				}
+			}))
+			defer server.Close()
+
+			err := deregister(context.Background(), server.Client(), server.URL, clusterID, tc.pullSecret)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+			assert.Equal(t, tc.expectedPatch, patched)
+		})
+	}
+}
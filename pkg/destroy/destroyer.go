@@ -1,10 +1,14 @@
 package destroy
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/installer/pkg/asset/cluster"
+	"github.com/openshift/installer/pkg/asset/installconfig"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
 	"github.com/openshift/installer/pkg/destroy/providers"
 )
 
@@ -26,3 +30,155 @@ func New(logger logrus.FieldLogger, rootDir string) (providers.Destroyer, error)
 	}
 	return creator(logger, metadata)
 }
+
+// NewFiltered returns a Destroyer like New, which skips the resources the
+// filter excludes. It fails when the filter is not empty and the Destroyer
+// of the platform cannot skip resources.
+func NewFiltered(logger logrus.FieldLogger, rootDir string, filter providers.ResourceFilter) (providers.Destroyer, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	destroyer, err := New(logger, rootDir)
+	if err != nil || filter.IsEmpty() {
+		return destroyer, err
+	}
+	filterable, ok := destroyer.(providers.Filterable)
+	if !ok {
+		return nil, errors.Errorf("filtering the resources to destroy is not supported on %q", platform(rootDir))
+	}
+	filterable.SetResourceFilter(filter)
+	return destroyer, nil
+}
+
+// SetRateLimits sets the rate limits of the Destroyer based on
+// `metadata.json` in `rootDir`. It fails when the limits are not empty and
+// the Destroyer of the platform cannot be rate limited.
+func SetRateLimits(destroyer providers.Destroyer, rootDir string, limits providers.RateLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	if limits.IsEmpty() {
+		return nil
+	}
+	limited, ok := destroyer.(providers.RateLimited)
+	if !ok {
+		return errors.Errorf("rate limiting the destroy is not supported on %q", platform(rootDir))
+	}
+	limited.SetRateLimits(limits)
+	return nil
+}
+
+// SetForceOptions sets the force options of the Destroyer based on
+// `metadata.json` in `rootDir`. It fails when the options are not empty and
+// the Destroyer of the platform cannot give up on resources.
+func SetForceOptions(destroyer providers.Destroyer, rootDir string, options providers.ForceOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if options.IsEmpty() {
+		return nil
+	}
+	forceable, ok := destroyer.(providers.Forceable)
+	if !ok {
+		return errors.Errorf("forcing the destroy is not supported on %q", platform(rootDir))
+	}
+	forceable.SetForceOptions(options)
+	return nil
+}
+
+// SetNetworkAccount sets the account owning the shared network of the
+// Destroyer based on `metadata.json` in `rootDir`. It fails when the account
+// is not empty and the Destroyer of the platform cannot clean up resources in
+// another account.
+func SetNetworkAccount(destroyer providers.Destroyer, rootDir string, account providers.NetworkAccount) error {
+	if err := account.Validate(); err != nil {
+		return err
+	}
+	if account.IsEmpty() {
+		return nil
+	}
+	crossAccount, ok := destroyer.(providers.CrossAccount)
+	if !ok {
+		return errors.Errorf("destroying resources in the network account is not supported on %q", platform(rootDir))
+	}
+	crossAccount.SetNetworkAccount(account)
+	return nil
+}
+
+// PreserveImageRegistry makes the Destroyer based on `metadata.json` in
+// `rootDir` keep the storage of the image registry. It fails when the
+// Destroyer of the platform cannot keep it.
+func PreserveImageRegistry(destroyer providers.Destroyer, rootDir string) error {
+	preserver, ok := destroyer.(providers.ImageRegistryPreserver)
+	if !ok {
+		return errors.Errorf("preserving the image registry storage is not supported on %q", platform(rootDir))
+	}
+	preserver.PreserveImageRegistry()
+	return nil
+}
+
+// WipeHosts makes the Destroyer based on `metadata.json` in `rootDir` erase
+// the disks of the hosts in the install config of `rootDir`, through their
+// BMCs, and power them off. It fails when the Destroyer of the platform
+// cannot wipe the hosts.
+func WipeHosts(destroyer providers.Destroyer, rootDir string) error {
+	wiper, ok := destroyer.(providers.HostWiper)
+	if !ok {
+		return errors.Errorf("wiping the disks of the hosts is not supported on %q", platform(rootDir))
+	}
+	installConfig, err := loadInstallConfig(rootDir)
+	if err != nil {
+		return errors.Wrap(err, "the install config, which holds the BMCs of the hosts, is required to wipe them")
+	}
+	config := installConfig.Config
+	if config.Platform.BareMetal == nil || len(config.Platform.BareMetal.Hosts) == 0 {
+		return errors.New("the install config has no bare metal hosts to wipe, the hosts of an agent config are wiped with agent wipe-hosts")
+	}
+	wiper.SetHostsToWipe(config.Platform.BareMetal.Hosts)
+	return nil
+}
+
+// Preflight checks, before deleting anything, that the Destroyer can
+// destroy the cluster, when the Destroyer of the platform supports it.
+func Preflight(ctx context.Context, destroyer providers.Destroyer) error {
+	preflighter, ok := destroyer.(providers.Preflighter)
+	if !ok {
+		return nil
+	}
+	return preflighter.Preflight(ctx)
+}
+
+// ListResources returns the resources which the Destroyer based on
+// `metadata.json` in `rootDir` would delete, without deleting them.
+func ListResources(ctx context.Context, destroyer providers.Destroyer, rootDir string) ([]providers.Resource, error) {
+	lister, ok := destroyer.(providers.ResourceLister)
+	if !ok {
+		return nil, errors.Errorf("listing the resources to destroy is not supported on %q", platform(rootDir))
+	}
+	return lister.ListResources(ctx)
+}
+
+// loadInstallConfig loads the install config from the asset store in
+// `rootDir`.
+func loadInstallConfig(rootDir string) (*installconfig.InstallConfig, error) {
+	store, err := assetstore.NewStore(rootDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create asset store")
+	}
+	asset, err := store.Load(&installconfig.InstallConfig{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the install config")
+	}
+	if asset == nil {
+		return nil, errors.New("the install config is not in the asset store")
+	}
+	return asset.(*installconfig.InstallConfig), nil
+}
+
+func platform(rootDir string) string {
+	metadata, err := cluster.LoadMetadata(rootDir)
+	if err != nil {
+		return ""
+	}
+	return metadata.Platform()
+}
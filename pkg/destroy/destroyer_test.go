@@ -0,0 +1,158 @@
+package destroy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
+	"github.com/openshift/installer/pkg/types"
+)
+
+type fakeDestroyer struct{}
+
+func (d *fakeDestroyer) Run() (*types.ClusterQuota, error) {
+	return nil, nil
+}
+
+type fakeImageRegistryPreserver struct {
+	fakeDestroyer
+	preserved bool
+}
+
+func (d *fakeImageRegistryPreserver) PreserveImageRegistry() {
+	d.preserved = true
+}
+
+type fakeCrossAccount struct {
+	fakeDestroyer
+	account providers.NetworkAccount
+}
+
+func (d *fakeCrossAccount) SetNetworkAccount(account providers.NetworkAccount) {
+	d.account = account
+}
+
+type fakeResourceLister struct {
+	fakeDestroyer
+	resources []providers.Resource
+}
+
+func (d *fakeResourceLister) ListResources(context.Context) ([]providers.Resource, error) {
+	return d.resources, nil
+}
+
+func TestPreserveImageRegistry(t *testing.T) {
+	cases := []struct {
+		name          string
+		destroyer     providers.Destroyer
+		expectedError string
+	}{
+		{
+			name:      "supported",
+			destroyer: &fakeImageRegistryPreserver{},
+		},
+		{
+			name:          "not supported",
+			destroyer:     &fakeDestroyer{},
+			expectedError: `preserving the image registry storage is not supported on ""`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := PreserveImageRegistry(tc.destroyer, t.TempDir())
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.True(t, tc.destroyer.(*fakeImageRegistryPreserver).preserved)
+			}
+		})
+	}
+}
+
+func TestSetNetworkAccount(t *testing.T) {
+	role := providers.NetworkAccount{Role: "arn:aws:iam::123456789012:role/shared-vpc"}
+	cases := []struct {
+		name            string
+		destroyer       providers.Destroyer
+		account         providers.NetworkAccount
+		expectedAccount providers.NetworkAccount
+		expectedError   string
+	}{
+		{
+			name:            "supported",
+			destroyer:       &fakeCrossAccount{},
+			account:         role,
+			expectedAccount: role,
+		},
+		{
+			name:      "empty account",
+			destroyer: &fakeCrossAccount{},
+		},
+		{
+			name:      "empty account not supported",
+			destroyer: &fakeDestroyer{},
+		},
+		{
+			name:          "not supported",
+			destroyer:     &fakeDestroyer{},
+			account:       role,
+			expectedError: `destroying resources in the network account is not supported on ""`,
+		},
+		{
+			name:          "invalid account",
+			destroyer:     &fakeCrossAccount{},
+			account:       providers.NetworkAccount{Role: role.Role, Profile: "shared-vpc"},
+			expectedError: "the network account is identified by either a role or a profile, not both",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := SetNetworkAccount(tc.destroyer, t.TempDir(), tc.account)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			if crossAccount, ok := tc.destroyer.(*fakeCrossAccount); ok {
+				assert.Equal(t, tc.expectedAccount, crossAccount.account)
+			}
+		})
+	}
+}
+
+func TestListResources(t *testing.T) {
+	resources := []providers.Resource{{Type: "ec2:instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-0123456789abcdef0", Region: "us-east-1"}}
+	cases := []struct {
+		name          string
+		destroyer     providers.Destroyer
+		expected      []providers.Resource
+		expectedError string
+	}{
+		{
+			name:      "supported",
+			destroyer: &fakeResourceLister{resources: resources},
+			expected:  resources,
+		},
+		{
+			name:          "not supported",
+			destroyer:     &fakeDestroyer{},
+			expectedError: `listing the resources to destroy is not supported on ""`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			listed, err := ListResources(context.Background(), tc.destroyer, t.TempDir())
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, listed)
+			}
+		})
+	}
+}
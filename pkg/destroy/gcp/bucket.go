@@ -3,10 +3,13 @@ package gcp
 import (
 	"context"
 	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 	"google.golang.org/api/googleapi"
 	storage "google.golang.org/api/storage/v1"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
 )
 
 var (
@@ -15,7 +18,20 @@ var (
 )
 
 func (o *ClusterUninstaller) listBuckets(ctx context.Context) ([]cloudResource, error) {
-	return o.listBucketsWithFilter(ctx, "items(name),nextPageToken", o.ClusterID+"-", nil)
+	var filterFunc func(*storage.Bucket) bool
+	if o.ImageRegistryPreserved {
+		filterFunc = notImageRegistryBucket(o.ClusterID)
+	}
+	return o.listBucketsWithFilter(ctx, "items(name),nextPageToken", o.ClusterID+"-", filterFunc)
+}
+
+// notImageRegistryBucket returns a filter of the buckets which are not the
+// storage of the image registry of the cluster with the infra ID.
+func notImageRegistryBucket(infraID string) func(*storage.Bucket) bool {
+	registryPrefix := multiDashes.ReplaceAllString(providers.ImageRegistryStoragePrefix(infraID), "-")
+	return func(bucket *storage.Bucket) bool {
+		return !strings.HasPrefix(bucket.Name, registryPrefix)
+	}
 }
 
 // listBucketsWithFilter lists buckets in the project that satisfy the filter criteria.
@@ -0,0 +1,27 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	storage "google.golang.org/api/storage/v1"
+)
+
+func TestNotImageRegistryBucket(t *testing.T) {
+	cases := []struct {
+		name     string
+		infraID  string
+		bucket   string
+		expected bool
+	}{
+		{name: "image registry bucket", infraID: "test-x2k4f", bucket: "test-x2k4f-image-registry-us-central1-abcdefghij"},
+		{name: "image registry bucket of an infra ID ending with a dash", infraID: "test-", bucket: "test-image-registry-us-central1-abc"},
+		{name: "other bucket of the cluster", infraID: "test-x2k4f", bucket: "test-x2k4f-bootstrap-ignition", expected: true},
+		{name: "image registry bucket of another cluster", infraID: "test-x2k4f", bucket: "test-x2k4fz-image-registry-us-central1-abc", expected: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, notImageRegistryBucket(tc.infraID)(&storage.Bucket{Name: tc.bucket}))
+		})
+	}
+}
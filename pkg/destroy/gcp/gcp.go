@@ -41,6 +41,9 @@ type ClusterUninstaller struct {
 	PrivateZoneDomain string
 	ClusterID         string
 
+	// ImageRegistryPreserved keeps the bucket of the image registry.
+	ImageRegistryPreserved bool
+
 	computeSvc *compute.Service
 	iamSvc     *iam.Service
 	dnsSvc     *dns.Service
@@ -76,22 +79,16 @@ func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (providers.
 	}, nil
 }
 
+// PreserveImageRegistry implements providers.ImageRegistryPreserver.
+func (o *ClusterUninstaller) PreserveImageRegistry() {
+	o.ImageRegistryPreserved = true
+}
+
 // Run is the entrypoint to start the uninstall process
 func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 	ctx := context.Background()
-	ssn, err := gcpconfig.GetSession(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get session")
-	}
-
-	options := []option.ClientOption{
-		option.WithCredentials(ssn.Credentials),
-		option.WithUserAgent(fmt.Sprintf("OpenShift/4.x Destroyer/%s", version.Raw)),
-	}
-
-	o.computeSvc, err = compute.NewService(ctx, options...)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create compute service")
+	if err := o.configureClients(ctx); err != nil {
+		return nil, err
 	}
 
 	cctx, cancel := context.WithTimeout(ctx, longTimeout)
@@ -110,36 +107,107 @@ func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 		return nil, errors.Wrap(err, "failed to cache machine types")
 	}
 
+	err := wait.PollImmediateInfinite(
+		time.Second*10,
+		o.destroyCluster,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to destroy cluster")
+	}
+
+	quota := gcptypes.Quota(o.pendingItemTracker.removedQuota)
+	return &types.ClusterQuota{GCP: &quota}, nil
+}
+
+// configureClients creates the clients of the services of the resources.
+func (o *ClusterUninstaller) configureClients(ctx context.Context) error {
+	ssn, err := gcpconfig.GetSession(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get session")
+	}
+
+	options := []option.ClientOption{
+		option.WithCredentials(ssn.Credentials),
+		option.WithUserAgent(fmt.Sprintf("OpenShift/4.x Destroyer/%s", version.Raw)),
+	}
+
+	o.computeSvc, err = compute.NewService(ctx, options...)
+	if err != nil {
+		return errors.Wrap(err, "failed to create compute service")
+	}
+
 	o.iamSvc, err = iam.NewService(ctx, options...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create iam service")
+		return errors.Wrap(err, "failed to create iam service")
 	}
 
 	o.dnsSvc, err = dns.NewService(ctx, options...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create dns service")
+		return errors.Wrap(err, "failed to create dns service")
 	}
 
 	o.storageSvc, err = storage.NewService(ctx, options...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create storage service")
+		return errors.Wrap(err, "failed to create storage service")
 	}
 
 	o.rmSvc, err = resourcemanager.NewService(ctx, options...)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create resourcemanager service")
+		return errors.Wrap(err, "failed to create resourcemanager service")
 	}
+	return nil
+}
 
-	err = wait.PollImmediateInfinite(
-		time.Second*10,
-		o.destroyCluster,
-	)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to destroy cluster")
+// ListResources returns the resources which Run would delete, without
+// deleting them. The load balancer resources created by the cloud controller
+// of the cluster are not listed.
+func (o *ClusterUninstaller) ListResources(ctx context.Context) ([]providers.Resource, error) {
+	if err := o.configureClients(ctx); err != nil {
+		return nil, err
 	}
 
-	quota := gcptypes.Quota(o.pendingItemTracker.removedQuota)
-	return &types.ClusterQuota{GCP: &quota}, nil
+	listFuncs := []func(ctx context.Context) ([]cloudResource, error){
+		o.listInstances,
+		o.listDisks,
+		o.listServiceAccounts,
+		o.listImages,
+		o.listBuckets,
+		o.listRoutes,
+		o.listFirewalls,
+		o.listAddresses,
+		o.listTargetPools,
+		o.listInstanceGroups,
+		o.listForwardingRules,
+		o.listBackendServices,
+		o.listHealthChecks,
+		o.listHTTPHealthChecks,
+		o.listRouters,
+		o.listSubnetworks,
+		o.listNetworks,
+	}
+	var resources []providers.Resource
+	for _, list := range listFuncs {
+		found, err := list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range found {
+			id := item.url
+			if id == "" {
+				id = item.name
+			}
+			resources = append(resources, providers.Resource{Type: item.typeName, ID: id, Region: item.zone})
+		}
+	}
+
+	privateZone, _, err := o.listDNSZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if privateZone != nil {
+		resources = append(resources, providers.Resource{Type: "dnszone", ID: privateZone.name})
+	}
+	return resources, nil
 }
 
 func (o *ClusterUninstaller) destroyCluster() (bool, error) {
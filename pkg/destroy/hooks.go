@@ -0,0 +1,68 @@
+package destroy
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/lineprinter"
+)
+
+// RunHooks runs the executables in `hooksDir`, in lexical order, after the
+// cluster based on `metadata.json` in `rootDir` was destroyed, to tear down
+// the resources of the cluster which are not managed by the installer, e.g.
+// external DNS records. Each hook gets `metadata.json` on its standard input
+// and runs in `rootDir`. RunHooks stops at the first hook which fails.
+func RunHooks(ctx context.Context, hooksDir string, rootDir string) error {
+	entries, err := os.ReadDir(hooksDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the hooks directory")
+	}
+	metadata, err := os.ReadFile(filepath.Join(rootDir, "metadata.json"))
+	if err != nil {
+		return errors.Wrap(err, "failed to read the metadata")
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the info of hook %s", entry.Name())
+		}
+		if info.IsDir() {
+			continue
+		}
+		if info.Mode().Perm()&0o111 == 0 {
+			logrus.Warnf("Skipping destroy hook %s, which is not executable", entry.Name())
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		logrus.Infof("Running destroy hook %s", name)
+		path, err := filepath.Abs(filepath.Join(hooksDir, name))
+		if err != nil {
+			return err
+		}
+		cmd := exec.CommandContext(ctx, path) //nolint:gosec // the hooks are provided by the user
+		cmd.Dir = rootDir
+		cmd.Stdin = bytes.NewReader(metadata)
+		output := &lineprinter.LinePrinter{Print: (&lineprinter.Trimmer{WrappedPrint: logrus.WithField("hook", name).Info}).Print}
+		cmd.Stdout = output
+		cmd.Stderr = output
+		err = cmd.Run()
+		output.Close()
+		if err != nil {
+			return errors.Wrapf(err, "destroy hook %s failed", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package destroy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunHooks(t *testing.T) {
+	rootDir := t.TempDir()
+	metadata := `{"clusterName":"test-cluster","infraID":"test-cluster-x2k4f"}`
+	if err := os.WriteFile(filepath.Join(rootDir, "metadata.json"), []byte(metadata), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		hooks  map[string]string
+		err    string
+		output string
+	}{
+		{
+			name: "in order, with the metadata",
+			hooks: map[string]string{
+				"20-dns":        "#!/bin/sh\necho dns >> hooks.out\ncat >> hooks.out\n",
+				"10-monitoring": "#!/bin/sh\necho monitoring >> hooks.out\n",
+			},
+			output: "monitoring\ndns\n" + metadata,
+		},
+		{
+			name: "stops at the first failure",
+			hooks: map[string]string{
+				"10-fail":  "#!/bin/sh\nexit 3\n",
+				"20-after": "#!/bin/sh\necho after >> hooks.out\n",
+			},
+			err: "destroy hook 10-fail failed: exit status 3",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Remove(filepath.Join(rootDir, "hooks.out"))
+			hooksDir := t.TempDir()
+			for name, script := range tc.hooks {
+				if err := os.WriteFile(filepath.Join(hooksDir, name), []byte(script), 0o700); err != nil { //nolint:gosec // the hooks must be executable
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(filepath.Join(hooksDir, "README"), []byte("not a hook"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := RunHooks(context.Background(), hooksDir, rootDir)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				assert.NoFileExists(t, filepath.Join(rootDir, "hooks.out"))
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			output, err := os.ReadFile(filepath.Join(rootDir, "hooks.out"))
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.output, string(output))
+			}
+		})
+	}
+}
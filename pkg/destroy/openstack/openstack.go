@@ -76,6 +76,10 @@ type ClusterUninstaller struct {
 	// InfraID contains unique cluster identifier
 	InfraID string
 	Logger  logrus.FieldLogger
+
+	// ImageRegistryPreserved keeps the Swift container of the image
+	// registry.
+	ImageRegistryPreserved bool
 }
 
 // New returns an OpenStack destroyer from ClusterMetadata.
@@ -88,6 +92,11 @@ func New(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (providers.
 	}, nil
 }
 
+// PreserveImageRegistry implements providers.ImageRegistryPreserver.
+func (o *ClusterUninstaller) PreserveImageRegistry() {
+	o.ImageRegistryPreserved = true
+}
+
 // Run is the entrypoint to start the uninstall process.
 func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 	opts := openstackdefaults.DefaultClientOpts(o.Cloud)
@@ -119,6 +128,9 @@ func (o *ClusterUninstaller) Run() (*types.ClusterQuota, error) {
 		"deleteFloatingIPs":     deleteFloatingIPs,
 		"deleteImages":          deleteImages,
 	}
+	if o.ImageRegistryPreserved {
+		deleteFuncs["deleteContainers"] = keepContainers(providers.ImageRegistryStoragePrefix(o.InfraID))
+	}
 	returnChannel := make(chan string)
 
 	// launch goroutines
@@ -1105,6 +1117,18 @@ func deleteNetworks(opts *clientconfig.ClientOpts, filter Filter, logger logrus.
 }
 
 func deleteContainers(opts *clientconfig.ClientOpts, filter Filter, logger logrus.FieldLogger) (bool, error) {
+	return deleteContainersKeeping(opts, filter, logger, "")
+}
+
+// keepContainers returns a deleteFunc deleting the containers, except the
+// ones whose name starts with the prefix.
+func keepContainers(prefix string) deleteFunc {
+	return func(opts *clientconfig.ClientOpts, filter Filter, logger logrus.FieldLogger) (bool, error) {
+		return deleteContainersKeeping(opts, filter, logger, prefix)
+	}
+}
+
+func deleteContainersKeeping(opts *clientconfig.ClientOpts, filter Filter, logger logrus.FieldLogger, keepPrefix string) (bool, error) {
 	logger.Debug("Deleting openstack containers")
 	defer logger.Debugf("Exiting deleting openstack containers")
 
@@ -1149,6 +1173,10 @@ func deleteContainers(opts *clientconfig.ClientOpts, filter Filter, logger logru
 		return false, nil
 	}
 	for _, container := range allContainers {
+		if keepPrefix != "" && strings.HasPrefix(container, keepPrefix) {
+			logger.Debugf("Keeping container %q", container)
+			continue
+		}
 		metadata, err := containers.Get(conn, container, nil).ExtractMetadata()
 		if err != nil {
 			// Some containers that we fetched previously can already be deleted in
@@ -0,0 +1,36 @@
+package providers
+
+import (
+	"github.com/pkg/errors"
+)
+
+// NetworkAccount identifies the credentials of the account owning the shared
+// network of a cluster, e.g. the shared VPC, so that the Destroyers can find
+// and clean up the resources of the cluster in that account, like its
+// records in a private hosted zone and the shared tags of its subnets.
+type NetworkAccount struct {
+	// Role is the role to assume in the network account.
+	Role string
+	// Profile is the profile of the credentials of the network account.
+	Profile string
+}
+
+// CrossAccount is implemented by the Destroyers which can clean up the
+// resources of the cluster in the account owning its shared network.
+type CrossAccount interface {
+	SetNetworkAccount(account NetworkAccount)
+}
+
+// IsEmpty returns whether the cluster has no resources in another account,
+// as far as the Destroyers are told.
+func (n NetworkAccount) IsEmpty() bool {
+	return n.Role == "" && n.Profile == ""
+}
+
+// Validate returns an error when the account is set more than once.
+func (n NetworkAccount) Validate() error {
+	if n.Role != "" && n.Profile != "" {
+		return errors.New("the network account is identified by either a role or a profile, not both")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkAccount(t *testing.T) {
+	cases := []struct {
+		name          string
+		account       NetworkAccount
+		expectedEmpty bool
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			expectedEmpty: true,
+		},
+		{
+			name:    "role",
+			account: NetworkAccount{Role: "arn:aws:iam::123456789012:role/shared-vpc"},
+		},
+		{
+			name:    "profile",
+			account: NetworkAccount{Profile: "shared-vpc"},
+		},
+		{
+			name:          "role and profile",
+			account:       NetworkAccount{Role: "arn:aws:iam::123456789012:role/shared-vpc", Profile: "shared-vpc"},
+			expectedError: "the network account is identified by either a role or a profile, not both",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedEmpty, tc.account.IsEmpty())
+			if tc.expectedError != "" {
+				assert.EqualError(t, tc.account.Validate(), tc.expectedError)
+			} else {
+				assert.NoError(t, tc.account.Validate())
+			}
+		})
+	}
+}
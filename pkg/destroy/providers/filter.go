@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ResourceFilter selects which of the resources of the cluster are deleted,
+// e.g. to keep the resources shared with other clusters which carry the tags
+// of the cluster too.
+type ResourceFilter struct {
+	// ExcludeTags are the tags, as key or key=value, of the resources which
+	// are not deleted.
+	ExcludeTags []string
+	// IncludeTypes are, when set, the types of the only resources which are
+	// deleted, e.g. "ec2:instance", or "ec2" for all the types of a service.
+	IncludeTypes []string
+}
+
+// Filterable is implemented by the Destroyers which can skip some of the
+// resources of the cluster.
+type Filterable interface {
+	SetResourceFilter(filter ResourceFilter)
+}
+
+// IsEmpty returns whether the filter selects all the resources.
+func (f ResourceFilter) IsEmpty() bool {
+	return len(f.ExcludeTags) == 0 && len(f.IncludeTypes) == 0
+}
+
+// Validate returns an error when the filter is invalid.
+func (f ResourceFilter) Validate() error {
+	for _, tag := range f.ExcludeTags {
+		if key, _, _ := strings.Cut(tag, "="); key == "" {
+			return errors.Errorf("invalid tag %q, must be key or key=value", tag)
+		}
+	}
+	for _, resourceType := range f.IncludeTypes {
+		if resourceType == "" {
+			return errors.New("invalid empty resource type")
+		}
+	}
+	return nil
+}
+
+// ExcludesTags returns whether a resource with the tags is not deleted.
+func (f ResourceFilter) ExcludesTags(tags map[string]string) bool {
+	for _, tag := range f.ExcludeTags {
+		key, value, hasValue := strings.Cut(tag, "=")
+		if actual, ok := tags[key]; ok && (!hasValue || actual == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesType returns whether a resource of the type is deleted.
+func (f ResourceFilter) IncludesType(resourceType string) bool {
+	if len(f.IncludeTypes) == 0 {
+		return true
+	}
+	for _, included := range f.IncludeTypes {
+		if resourceType == included || strings.HasPrefix(resourceType, included+":") {
+			return true
+		}
+	}
+	return false
+}
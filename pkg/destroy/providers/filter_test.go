@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceFilter(t *testing.T) {
+	filter := ResourceFilter{
+		ExcludeTags:  []string{"shared", "team=network"},
+		IncludeTypes: []string{"ec2", "route53:hostedzone"},
+	}
+
+	cases := []struct {
+		name     string
+		tags     map[string]string
+		excluded bool
+	}{
+		{name: "no tags"},
+		{name: "key only", tags: map[string]string{"shared": "true"}, excluded: true},
+		{name: "key and value", tags: map[string]string{"team": "network"}, excluded: true},
+		{name: "other value", tags: map[string]string{"team": "storage"}},
+		{name: "other key", tags: map[string]string{"kubernetes.io/cluster/test-x2k4f": "owned"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.excluded, filter.ExcludesTags(tc.tags))
+		})
+	}
+
+	assert.True(t, filter.IncludesType("ec2"))
+	assert.True(t, filter.IncludesType("ec2:instance"))
+	assert.True(t, filter.IncludesType("route53:hostedzone"))
+	assert.False(t, filter.IncludesType("route53"))
+	assert.False(t, filter.IncludesType("ec2x:instance"))
+	assert.False(t, filter.IncludesType("s3"))
+	assert.True(t, ResourceFilter{}.IncludesType("s3"))
+}
+
+func TestResourceFilterValidate(t *testing.T) {
+	assert.NoError(t, ResourceFilter{ExcludeTags: []string{"shared", "team=network", "empty="}}.Validate())
+	assert.EqualError(t, ResourceFilter{ExcludeTags: []string{"=value"}}.Validate(), `invalid tag "=value", must be key or key=value`)
+	assert.EqualError(t, ResourceFilter{IncludeTypes: []string{""}}.Validate(), "invalid empty resource type")
+}
@@ -0,0 +1,58 @@
+package providers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultResourceTimeout is how long a resource can keep failing to be
+// deleted before a forced destroy gives up on it.
+const DefaultResourceTimeout = 10 * time.Minute
+
+// ForceOptions make the Destroyers give up on the resources which keep
+// failing to be deleted, e.g. a network interface held by an external
+// service, instead of retrying them until they are deleted.
+type ForceOptions struct {
+	// Force gives up on the resources failing to be deleted for longer
+	// than ResourceTimeout.
+	Force bool
+	// ResourceTimeout is DefaultResourceTimeout when zero.
+	ResourceTimeout time.Duration
+}
+
+// Forceable is implemented by the Destroyers which can give up on the
+// resources which keep failing to be deleted.
+type Forceable interface {
+	SetForceOptions(options ForceOptions)
+}
+
+// IsEmpty returns whether the Destroyers retry the resources until they are
+// deleted.
+func (f ForceOptions) IsEmpty() bool {
+	return !f.Force && f.ResourceTimeout == 0
+}
+
+// Validate returns an error when the options are invalid.
+func (f ForceOptions) Validate() error {
+	if f.ResourceTimeout < 0 {
+		return errors.Errorf("invalid resource timeout %s, must not be negative", f.ResourceTimeout)
+	}
+	if f.ResourceTimeout > 0 && !f.Force {
+		return errors.New("the resource timeout requires forcing the destroy")
+	}
+	return nil
+}
+
+// GivesUp returns whether a resource failing to be deleted since the time is
+// given up on.
+func (f ForceOptions) GivesUp(failingSince time.Time) bool {
+	if !f.Force {
+		return false
+	}
+	timeout := f.ResourceTimeout
+	if timeout == 0 {
+		timeout = DefaultResourceTimeout
+	}
+	return time.Since(failingSince) > timeout
+}
@@ -0,0 +1,23 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForceOptionsValidate(t *testing.T) {
+	assert.NoError(t, ForceOptions{}.Validate())
+	assert.NoError(t, ForceOptions{Force: true, ResourceTimeout: time.Minute}.Validate())
+	assert.EqualError(t, ForceOptions{ResourceTimeout: time.Minute}.Validate(), "the resource timeout requires forcing the destroy")
+	assert.EqualError(t, ForceOptions{Force: true, ResourceTimeout: -time.Minute}.Validate(), "invalid resource timeout -1m0s, must not be negative")
+}
+
+func TestForceOptionsGivesUp(t *testing.T) {
+	now := time.Now()
+	assert.False(t, ForceOptions{}.GivesUp(now.Add(-time.Hour)))
+	assert.True(t, ForceOptions{Force: true}.GivesUp(now.Add(-time.Hour)))
+	assert.False(t, ForceOptions{Force: true}.GivesUp(now.Add(-time.Minute)))
+	assert.True(t, ForceOptions{Force: true, ResourceTimeout: 30 * time.Second}.GivesUp(now.Add(-time.Minute)))
+}
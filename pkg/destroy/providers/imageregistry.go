@@ -0,0 +1,15 @@
+package providers
+
+// ImageRegistryPreserver is implemented by the Destroyers which can keep the
+// storage of the image registry of the cluster, e.g. to retain or migrate its
+// contents.
+type ImageRegistryPreserver interface {
+	PreserveImageRegistry()
+}
+
+// ImageRegistryStoragePrefix returns the prefix of the names of the buckets
+// and containers which the image registry operator creates for the cluster
+// with the infra ID.
+func ImageRegistryStoragePrefix(infraID string) string {
+	return infraID + "-image-registry-"
+}
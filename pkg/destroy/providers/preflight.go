@@ -0,0 +1,13 @@
+package providers
+
+import (
+	"context"
+)
+
+// Preflighter is implemented by the Destroyers which can check, before
+// deleting anything, that the destroy can complete, e.g. that the
+// credentials are allowed to delete every kind of resource of the cluster,
+// so that a failing destroy does not leave the cluster half deleted.
+type Preflighter interface {
+	Preflight(ctx context.Context) error
+}
@@ -0,0 +1,70 @@
+package providers
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// RateLimits controls how fast the resources of the cluster are deleted, to
+// tear down large clusters faster or to stay under the API limits of the
+// account.
+type RateLimits struct {
+	// MaxParallel is the number of resources deleted at the same time, one
+	// at a time when zero.
+	MaxParallel int
+	// RequestsPerSecond is the rate of calls to the cloud APIs, unlimited
+	// when zero.
+	RequestsPerSecond float64
+	// Burst is the number of calls which can exceed RequestsPerSecond at
+	// once, one when zero.
+	Burst int
+}
+
+// RateLimited is implemented by the Destroyers whose parallelism and rate of
+// calls to the cloud APIs can be controlled.
+type RateLimited interface {
+	SetRateLimits(limits RateLimits)
+}
+
+// IsEmpty returns whether the limits are the defaults of the Destroyers.
+func (r RateLimits) IsEmpty() bool {
+	return r.MaxParallel == 0 && r.RequestsPerSecond == 0 && r.Burst == 0
+}
+
+// Validate returns an error when the limits are invalid.
+func (r RateLimits) Validate() error {
+	if r.MaxParallel < 0 {
+		return errors.Errorf("invalid max parallel %d, must not be negative", r.MaxParallel)
+	}
+	if r.RequestsPerSecond < 0 {
+		return errors.Errorf("invalid requests per second %v, must not be negative", r.RequestsPerSecond)
+	}
+	if r.Burst < 0 {
+		return errors.Errorf("invalid burst %d, must not be negative", r.Burst)
+	}
+	if r.Burst > 0 && r.RequestsPerSecond == 0 {
+		return errors.New("burst requires a rate of requests per second")
+	}
+	return nil
+}
+
+// Parallel returns the number of resources deleted at the same time.
+func (r RateLimits) Parallel() int {
+	if r.MaxParallel == 0 {
+		return 1
+	}
+	return r.MaxParallel
+}
+
+// Limiter returns the limiter of the calls to the cloud APIs, or nil when
+// they are unlimited.
+func (r RateLimits) Limiter() flowcontrol.RateLimiter {
+	if r.RequestsPerSecond == 0 {
+		return nil
+	}
+	burst := r.Burst
+	if burst == 0 {
+		burst = 1
+	}
+	return flowcontrol.NewTokenBucketRateLimiter(float32(r.RequestsPerSecond), burst)
+}
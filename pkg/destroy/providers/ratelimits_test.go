@@ -0,0 +1,43 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitsValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		limits RateLimits
+		err    string
+	}{
+		{name: "empty"},
+		{name: "valid", limits: RateLimits{MaxParallel: 8, RequestsPerSecond: 2.5, Burst: 5}},
+		{name: "negative parallel", limits: RateLimits{MaxParallel: -1}, err: "invalid max parallel -1, must not be negative"},
+		{name: "negative rate", limits: RateLimits{RequestsPerSecond: -1}, err: "invalid requests per second -1, must not be negative"},
+		{name: "burst without rate", limits: RateLimits{Burst: 5}, err: "burst requires a rate of requests per second"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.limits.Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRateLimitsDefaults(t *testing.T) {
+	var limits RateLimits
+	assert.True(t, limits.IsEmpty())
+	assert.Equal(t, 1, limits.Parallel())
+	assert.Nil(t, limits.Limiter())
+
+	limits = RateLimits{MaxParallel: 4, RequestsPerSecond: 10}
+	assert.False(t, limits.IsEmpty())
+	assert.Equal(t, 4, limits.Parallel())
+	assert.NotNil(t, limits.Limiter())
+}
@@ -0,0 +1,106 @@
+package providers
+
+import (
+	"sort"
+	"sync"
+)
+
+// ResourceStatus is what happened to a resource while destroying the cluster.
+type ResourceStatus string
+
+const (
+	// ResourceDiscovered is the status of the resources found, which were
+	// neither deleted nor failed to be deleted yet.
+	ResourceDiscovered ResourceStatus = "discovered"
+	// ResourceDeleted is the status of the resources deleted.
+	ResourceDeleted ResourceStatus = "deleted"
+	// ResourceSkipped is the status of the resources the filter excludes.
+	ResourceSkipped ResourceStatus = "skipped"
+	// ResourceFailed is the status of the resources which failed to be
+	// deleted, and were not deleted by a later attempt.
+	ResourceFailed ResourceStatus = "failed"
+)
+
+// TrackedResource is a resource with what happened to it.
+type TrackedResource struct {
+	Resource
+	Status ResourceStatus `json:"status"`
+	// Error is the error of the last attempt to delete the resource, or
+	// the reason why it was skipped.
+	Error string `json:"error,omitempty"`
+}
+
+// ResourceTracker records what happens to the resources while destroying the
+// cluster. A nil tracker records nothing.
+type ResourceTracker struct {
+	mu        sync.Mutex
+	resources map[string]*TrackedResource
+}
+
+// Tracked is implemented by the Destroyers which record what happens to the
+// resources.
+type Tracked interface {
+	SetResourceTracker(tracker *ResourceTracker)
+}
+
+// NewResourceTracker returns an empty tracker.
+func NewResourceTracker() *ResourceTracker {
+	return &ResourceTracker{resources: map[string]*TrackedResource{}}
+}
+
+// Discovered records that the resource was found.
+func (t *ResourceTracker) Discovered(resource Resource) {
+	t.record(resource, ResourceDiscovered, "", false)
+}
+
+// Deleted records that the resource was deleted.
+func (t *ResourceTracker) Deleted(resource Resource) {
+	t.record(resource, ResourceDeleted, "", true)
+}
+
+// Skipped records that the resource was skipped for the reason.
+func (t *ResourceTracker) Skipped(resource Resource, reason string) {
+	t.record(resource, ResourceSkipped, reason, true)
+}
+
+// Failed records that an attempt to delete the resource failed.
+func (t *ResourceTracker) Failed(resource Resource, err error) {
+	t.record(resource, ResourceFailed, err.Error(), true)
+}
+
+func (t *ResourceTracker) record(resource Resource, status ResourceStatus, message string, override bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tracked, ok := t.resources[resource.ID]
+	if !ok {
+		t.resources[resource.ID] = &TrackedResource{Resource: resource, Status: status, Error: message}
+		return
+	}
+	if override && tracked.Status != ResourceDeleted {
+		tracked.Status = status
+		tracked.Error = message
+	}
+}
+
+// Resources returns the tracked resources, sorted by type and ID.
+func (t *ResourceTracker) Resources() []TrackedResource {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	resources := make([]TrackedResource, 0, len(t.resources))
+	for _, resource := range t.resources {
+		resources = append(resources, *resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Type != resources[j].Type {
+			return resources[i].Type < resources[j].Type
+		}
+		return resources[i].ID < resources[j].ID
+	})
+	return resources
+}
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceTracker(t *testing.T) {
+	instance := Resource{Type: "ec2:instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-1", Region: "us-east-1"}
+	vpc := Resource{Type: "ec2:vpc", ID: "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1", Region: "us-east-1"}
+	zone := Resource{Type: "route53:hostedzone", ID: "arn:aws:route53:::hostedzone/Z1"}
+	role := Resource{Type: "iam:role", ID: "arn:aws:iam::123456789012:role/test-master-role"}
+
+	cases := []struct {
+		name     string
+		record   func(tracker *ResourceTracker)
+		expected []TrackedResource
+	}{
+		{
+			name:     "nothing recorded",
+			record:   func(*ResourceTracker) {},
+			expected: []TrackedResource{},
+		},
+		{
+			name: "discovered",
+			record: func(tracker *ResourceTracker) {
+				tracker.Discovered(instance)
+			},
+			expected: []TrackedResource{{Resource: instance, Status: ResourceDiscovered}},
+		},
+		{
+			name: "deleted after a failed attempt",
+			record: func(tracker *ResourceTracker) {
+				tracker.Discovered(instance)
+				tracker.Failed(instance, errors.New("throttled"))
+				tracker.Deleted(instance)
+			},
+			expected: []TrackedResource{{Resource: instance, Status: ResourceDeleted}},
+		},
+		{
+			name: "deleted then discovered again",
+			record: func(tracker *ResourceTracker) {
+				tracker.Deleted(instance)
+				tracker.Discovered(instance)
+				tracker.Failed(instance, errors.New("not found"))
+			},
+			expected: []TrackedResource{{Resource: instance, Status: ResourceDeleted}},
+		},
+		{
+			name: "last failure kept",
+			record: func(tracker *ResourceTracker) {
+				tracker.Discovered(vpc)
+				tracker.Failed(vpc, errors.New("throttled"))
+				tracker.Discovered(vpc)
+				tracker.Failed(vpc, errors.New("DependencyViolation"))
+			},
+			expected: []TrackedResource{{Resource: vpc, Status: ResourceFailed, Error: "DependencyViolation"}},
+		},
+		{
+			name: "skipped",
+			record: func(tracker *ResourceTracker) {
+				tracker.Discovered(zone)
+				tracker.Skipped(zone, "excluded by tag")
+			},
+			expected: []TrackedResource{{Resource: zone, Status: ResourceSkipped, Error: "excluded by tag"}},
+		},
+		{
+			name: "sorted by type and ID",
+			record: func(tracker *ResourceTracker) {
+				tracker.Discovered(zone)
+				tracker.Discovered(vpc)
+				tracker.Discovered(role)
+				tracker.Discovered(instance)
+			},
+			expected: []TrackedResource{
+				{Resource: instance, Status: ResourceDiscovered},
+				{Resource: vpc, Status: ResourceDiscovered},
+				{Resource: role, Status: ResourceDiscovered},
+				{Resource: zone, Status: ResourceDiscovered},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := NewResourceTracker()
+			tc.record(tracker)
+			assert.Equal(t, tc.expected, tracker.Resources())
+		})
+	}
+}
+
+func TestNilResourceTracker(t *testing.T) {
+	var tracker *ResourceTracker
+	tracker.Discovered(Resource{ID: "test"})
+	assert.Empty(t, tracker.Resources())
+}
@@ -1,6 +1,8 @@
 package providers
 
 import (
+	"context"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/installer/pkg/types"
@@ -14,3 +16,19 @@ type Destroyer interface {
 
 // NewFunc is an interface for creating platform-specific destroyers.
 type NewFunc func(logger logrus.FieldLogger, metadata *types.ClusterMetadata) (Destroyer, error)
+
+// Resource is a cloud resource of a cluster.
+type Resource struct {
+	// Type is the type of the resource, e.g. "ec2:instance".
+	Type string `json:"type"`
+	// ID identifies the resource, e.g. its ARN.
+	ID string `json:"id"`
+	// Region is the region of the resource, empty for global resources.
+	Region string `json:"region,omitempty"`
+}
+
+// ResourceLister is implemented by the Destroyers which can list the
+// resources they would delete, without deleting them.
+type ResourceLister interface {
+	ListResources(ctx context.Context) ([]Resource, error)
+}
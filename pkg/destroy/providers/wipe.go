@@ -0,0 +1,12 @@
+package providers
+
+import (
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+// HostWiper is implemented by the Destroyers which can connect to the BMCs
+// of the hosts of the cluster to erase their disks and power them off, so
+// that stale RAID and partition signatures do not break re-provisioning them.
+type HostWiper interface {
+	SetHostsToWipe(hosts []*baremetal.Host)
+}
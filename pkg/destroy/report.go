@@ -0,0 +1,80 @@
+package destroy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/asset/cluster"
+	"github.com/openshift/installer/pkg/destroy/providers"
+)
+
+// ReportFileName is the name of the file, in the asset directory, with the
+// report of the last destroy of the cluster.
+const ReportFileName = "destroy-report.json"
+
+// Report is the auditable record of the destroy of a cluster.
+type Report struct {
+	ClusterName string `json:"clusterName"`
+	InfraID     string `json:"infraID"`
+	Platform    string `json:"platform"`
+	// Result is "success" or "failure".
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Summary counts the resources per status.
+	Summary map[providers.ResourceStatus]int `json:"summary"`
+	// Resources are the resources tracked by the Destroyer of the platform,
+	// empty when it does not track them.
+	Resources []providers.TrackedResource `json:"resources"`
+}
+
+// NewReport returns the report of the destroy of the cluster based on
+// `metadata.json` in `rootDir`, starting now.
+func NewReport(rootDir string) (*Report, error) {
+	metadata, err := cluster.LoadMetadata(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{
+		ClusterName: metadata.ClusterName,
+		InfraID:     metadata.InfraID,
+		Platform:    metadata.Platform(),
+		StartTime:   time.Now().UTC(),
+	}, nil
+}
+
+// Write completes the report with the tracked resources and the error of the
+// destroy, if any, and writes it to `rootDir`.
+func (r *Report) Write(rootDir string, tracker *providers.ResourceTracker, destroyErr error) error {
+	r.EndTime = time.Now().UTC()
+	r.Result = "success"
+	if destroyErr != nil {
+		r.Result = "failure"
+		r.Error = destroyErr.Error()
+	}
+	r.Resources = tracker.Resources()
+	if r.Resources == nil {
+		r.Resources = []providers.TrackedResource{}
+	}
+	r.Summary = map[providers.ResourceStatus]int{}
+	for _, resource := range r.Resources {
+		r.Summary[resource.Status]++
+	}
+
+	path := filepath.Join(rootDir, ReportFileName)
+	logrus.Infof("Writing the destroy report to %s", path)
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the destroy report")
+	}
+	if err := os.WriteFile(path, raw, 0o640); err != nil { //nolint:gosec // no sensitive info
+		return errors.Wrap(err, "failed to write the destroy report")
+	}
+	return nil
+}
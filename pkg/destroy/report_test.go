@@ -0,0 +1,98 @@
+package destroy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/pkg/destroy/providers"
+)
+
+func TestReport(t *testing.T) {
+	instance := providers.Resource{Type: "ec2:instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-1", Region: "us-east-1"}
+	vpc := providers.Resource{Type: "ec2:vpc", ID: "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-1", Region: "us-east-1"}
+
+	cases := []struct {
+		name              string
+		tracker           func() *providers.ResourceTracker
+		destroyErr        error
+		expectedResult    string
+		expectedError     string
+		expectedSummary   map[providers.ResourceStatus]int
+		expectedResources []providers.TrackedResource
+	}{
+		{
+			name: "success",
+			tracker: func() *providers.ResourceTracker {
+				tracker := providers.NewResourceTracker()
+				tracker.Deleted(instance)
+				tracker.Deleted(vpc)
+				return tracker
+			},
+			expectedResult:  "success",
+			expectedSummary: map[providers.ResourceStatus]int{providers.ResourceDeleted: 2},
+			expectedResources: []providers.TrackedResource{
+				{Resource: instance, Status: providers.ResourceDeleted},
+				{Resource: vpc, Status: providers.ResourceDeleted},
+			},
+		},
+		{
+			name: "failure",
+			tracker: func() *providers.ResourceTracker {
+				tracker := providers.NewResourceTracker()
+				tracker.Deleted(instance)
+				tracker.Failed(vpc, errors.New("DependencyViolation"))
+				return tracker
+			},
+			destroyErr:      errors.New("context deadline exceeded"),
+			expectedResult:  "failure",
+			expectedError:   "context deadline exceeded",
+			expectedSummary: map[providers.ResourceStatus]int{providers.ResourceDeleted: 1, providers.ResourceFailed: 1},
+			expectedResources: []providers.TrackedResource{
+				{Resource: instance, Status: providers.ResourceDeleted},
+				{Resource: vpc, Status: providers.ResourceFailed, Error: "DependencyViolation"},
+			},
+		},
+		{
+			name:              "platform not tracking the resources",
+			tracker:           func() *providers.ResourceTracker { return nil },
+			expectedResult:    "success",
+			expectedSummary:   map[providers.ResourceStatus]int{},
+			expectedResources: []providers.TrackedResource{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			metadata := `{"clusterName":"test-cluster","infraID":"test-cluster-x2k4f","aws":{"region":"us-east-1"}}`
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(metadata), 0o600))
+
+			report, err := NewReport(dir)
+			require.NoError(t, err)
+			require.NoError(t, report.Write(dir, tc.tracker(), tc.destroyErr))
+
+			raw, err := os.ReadFile(filepath.Join(dir, ReportFileName))
+			require.NoError(t, err)
+			var written Report
+			require.NoError(t, json.Unmarshal(raw, &written))
+			assert.Equal(t, "test-cluster", written.ClusterName)
+			assert.Equal(t, "test-cluster-x2k4f", written.InfraID)
+			assert.Equal(t, "aws", written.Platform)
+			assert.Equal(t, tc.expectedResult, written.Result)
+			assert.Equal(t, tc.expectedError, written.Error)
+			assert.False(t, written.EndTime.Before(written.StartTime))
+			assert.Equal(t, tc.expectedSummary, written.Summary)
+			assert.Equal(t, tc.expectedResources, written.Resources)
+		})
+	}
+}
+
+func TestNewReportWithoutMetadata(t *testing.T) {
+	_, err := NewReport(t.TempDir())
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package gather
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Compression is the compression of the archives.
+type Compression string
+
+const (
+	// GzipCompression compresses the archives with gzip, into .tar.gz files.
+	GzipCompression Compression = "gzip"
+	// ZstdCompression compresses the archives with zstd, into .tar.zst
+	// files, which is several times faster than gzip for a similar ratio.
+	// It requires the zstd command.
+	ZstdCompression Compression = "zstd"
+)
+
+// Compressions are the supported compressions.
+var Compressions = []Compression{GzipCompression, ZstdCompression}
+
+// Extension returns the extension of the archives with the compression.
+func (c Compression) Extension() string {
+	if c == ZstdCompression {
+		return ".tar.zst"
+	}
+	return ".tar.gz"
+}
+
+// ParseCompression returns the compression with the name.
+func ParseCompression(name string) (Compression, error) {
+	for _, c := range Compressions {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+	return "", errors.Errorf("invalid compression %q, must be gzip or zstd", name)
+}
+
+// CompressionOf returns the compression of the archive, from its extension.
+func CompressionOf(archiveName string) Compression {
+	if strings.HasSuffix(archiveName, ZstdCompression.Extension()) {
+		return ZstdCompression
+	}
+	return GzipCompression
+}
+
+// TrimArchiveExtension returns the archive name without its extension, which
+// is the directory of its files.
+func TrimArchiveExtension(archiveName string) string {
+	return strings.TrimSuffix(archiveName, CompressionOf(archiveName).Extension())
+}
+
+// ArchiveWriter writes a compressed tar file, whose entries are added as they
+// are gathered, from any goroutine.
+type ArchiveWriter struct {
+	mu         sync.Mutex
+	file       *os.File
+	compressor io.WriteCloser
+	tarWriter  *tar.Writer
+}
+
+// NewArchiveWriter creates the archive, compressed as named by its extension.
+func NewArchiveWriter(archiveName string) (*ArchiveWriter, error) {
+	file, err := os.Create(archiveName)
+	if err != nil {
+		return nil, err
+	}
+	compressor, err := newCompressor(CompressionOf(archiveName), file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &ArchiveWriter{
+		file:       file,
+		compressor: compressor,
+		tarWriter:  tar.NewWriter(compressor),
+	}, nil
+}
+
+// AddFile adds the file to the archive, named by its path.
+func (w *ArchiveWriter) AddFile(filename string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return addToArchive(w.tarWriter, filename)
+}
+
+// Add adds an entry with the header and the content of the reader.
+func (w *ArchiveWriter) Add(header *tar.Header, r io.Reader) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.Copy(w.tarWriter, r)
+	return err
+}
+
+// Close flushes the archive and closes it.
+func (w *ArchiveWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []error
+	for _, closer := range []io.Closer{w.tarWriter, w.compressor, w.file} {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func newCompressor(compression Compression, w io.Writer) (io.WriteCloser, error) {
+	if compression == ZstdCompression {
+		return newZstdCommand(w, "-q", "-c", "-T0")
+	}
+	return gzip.NewWriter(w), nil
+}
+
+// NewDecompressor returns a reader decompressing the archive, which must be
+// closed to release the zstd command.
+func NewDecompressor(compression Compression, r io.Reader) (io.ReadCloser, error) {
+	if compression == ZstdCompression {
+		cmd := exec.Command("zstd", "-q", "-d", "-c")
+		cmd.Stdin = r
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, errors.Wrap(err, "failed to run zstd")
+		}
+		return &zstdReader{ReadCloser: stdout, cmd: cmd}, nil
+	}
+	return gzip.NewReader(r)
+}
+
+// zstdCommand compresses what is written to it with the zstd command.
+type zstdCommand struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func newZstdCommand(w io.Writer, args ...string) (*zstdCommand, error) {
+	cmd := exec.Command("zstd", args...)
+	cmd.Stdout = w
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "failed to run zstd")
+	}
+	return &zstdCommand{WriteCloser: stdin, cmd: cmd}, nil
+}
+
+func (z *zstdCommand) Close() error {
+	if err := z.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return errors.Wrap(z.cmd.Wait(), "failed to compress with zstd")
+}
+
+// zstdReader decompresses what is read from it with the zstd command.
+type zstdReader struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (z *zstdReader) Close() error {
+	// Drain the output for zstd to exit when the archive was not read to
+	// its end.
+	io.Copy(io.Discard, z.ReadCloser) //nolint:errcheck
+	return errors.Wrap(z.cmd.Wait(), "failed to decompress with zstd")
+}
@@ -0,0 +1,154 @@
+package gather
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readArchive(t *testing.T, archiveName string) map[string]string {
+	t.Helper()
+	file, err := os.Open(archiveName)
+	require.NoError(t, err)
+	defer file.Close()
+	decompressor, err := NewDecompressor(CompressionOf(archiveName), file)
+	require.NoError(t, err)
+	defer decompressor.Close()
+	entries := map[string]string{}
+	tarReader := tar.NewReader(decompressor)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		entries[header.Name] = string(content)
+	}
+}
+
+func TestArchiveWriterConcurrently(t *testing.T) {
+	directory := t.TempDir()
+	archiveName := filepath.Join(directory, "bundle.tar.gz")
+	archive, err := NewArchiveWriter(archiveName)
+	require.NoError(t, err)
+
+	var files []string
+	for i := 0; i < 10; i++ {
+		filename := filepath.Join(directory, fmt.Sprintf("host-%d.log", i))
+		require.NoError(t, os.WriteFile(filename, []byte(filename), 0600))
+		files = append(files, filename)
+	}
+	var wg sync.WaitGroup
+	for _, filename := range files {
+		wg.Add(1)
+		go func(filename string) {
+			defer wg.Done()
+			assert.NoError(t, archive.AddFile(filename))
+		}(filename)
+	}
+	wg.Wait()
+	require.NoError(t, archive.Close())
+
+	entries := readArchive(t, archiveName)
+	var names []string
+	for name, content := range entries {
+		assert.Equal(t, name, content)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, files, names)
+}
+
+func TestCombineArchives(t *testing.T) {
+	for _, compression := range Compressions {
+		t.Run(string(compression), func(t *testing.T) {
+			if compression == ZstdCompression {
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd is not installed")
+				}
+			}
+			directory := t.TempDir()
+			serial := filepath.Join(directory, "serial-log-bundle-1.tar.gz")
+			serialFile := filepath.Join(TrimArchiveExtension(serial), "master-0-serial.log")
+			require.NoError(t, os.MkdirAll(filepath.Dir(serialFile), 0755))
+			require.NoError(t, os.WriteFile(serialFile, []byte("serial"), 0600))
+			require.NoError(t, CreateArchive([]string{serialFile}, serial))
+
+			selective := filepath.Join(directory, "selective-log-bundle-1"+compression.Extension())
+			selectiveFile := filepath.Join(TrimArchiveExtension(selective), "bootstrap", "journals", "journal.log")
+			require.NoError(t, os.MkdirAll(filepath.Dir(selectiveFile), 0755))
+			require.NoError(t, os.WriteFile(selectiveFile, []byte("journal"), 0600))
+			require.NoError(t, CreateArchive([]string{selectiveFile}, selective))
+
+			combined := filepath.Join(directory, "log-bundle-1"+compression.Extension())
+			require.NoError(t, CombineArchives(combined, map[string]string{serial: "serial", selective: ""}))
+
+			assert.Equal(t, map[string]string{
+				"log-bundle-1/serial/master-0-serial.log":     "serial",
+				"log-bundle-1/bootstrap/journals/journal.log": "journal",
+			}, readArchive(t, combined))
+			assert.NoFileExists(t, serial)
+			assert.NoFileExists(t, selective)
+		})
+	}
+}
+
+func TestTruncateArchive(t *testing.T) {
+	cases := []struct {
+		name     string
+		maxSize  int64
+		expected map[string]string
+	}{
+		{
+			name:    "archive within the maximum size",
+			maxSize: 15,
+			expected: map[string]string{
+				"a.log": "aaaaa",
+				"b.log": "bbbbb",
+				"c.log": "ccccc",
+			},
+		},
+		{
+			name:    "file truncated and files left out",
+			maxSize: 7,
+			expected: map[string]string{
+				"a.log": "aaaaa",
+				"b.log": "bb",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			directory := t.TempDir()
+			var files []string
+			for _, name := range []string{"a.log", "b.log", "c.log"} {
+				filename := filepath.Join(directory, name)
+				require.NoError(t, os.WriteFile(filename, []byte(strings.Repeat(name[:1], 5)), 0600))
+				files = append(files, filename)
+			}
+			archive := filepath.Join(directory, "log-bundle-1.tar.gz")
+			require.NoError(t, CreateArchive(files, archive))
+
+			require.NoError(t, TruncateArchive(archive, tc.maxSize))
+
+			expected := map[string]string{}
+			for name, content := range tc.expected {
+				expected[filepath.Join(directory, name)] = content
+			}
+			assert.Equal(t, expected, readArchive(t, archive))
+			assert.NoFileExists(t, filepath.Join(directory, "log-bundle-1-truncated.tar.gz"))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -120,6 +121,24 @@ func (g *Gather) Run() error {
 		} else {
 			files = append(files, filePath)
 		}
+
+		// Screenshots capture the early boot failures, e.g. of ignition,
+		// which are not on the serial console.
+		screenshot, err := g.downloadConsoleScreenshot(ctx, ec2Client, instance, filePathDir)
+		if err != nil {
+			g.logger.WithField("Instance", aws.StringValue(instance.InstanceId)).Debugf("Failed to download the console screenshot: %v", err)
+		} else {
+			files = append(files, screenshot)
+		}
+
+		// The status checks tell the hardware and hypervisor failures from
+		// the failures of the operating system, e.g. kernel panics.
+		status, err := g.downloadInstanceStatus(ctx, ec2Client, instance, filePathDir)
+		if err != nil {
+			g.logger.WithField("Instance", aws.StringValue(instance.InstanceId)).Debugf("Failed to download the instance status: %v", err)
+		} else {
+			files = append(files, status)
+		}
 	}
 
 	if len(files) > 0 {
@@ -196,15 +215,9 @@ func (g *Gather) downloadConsoleOutput(ctx context.Context, ec2Client *ec2.EC2,
 		return "", err
 	}
 
-	instanceName := aws.StringValue(result.InstanceId)
-	for _, tags := range instance.Tags {
-		if strings.EqualFold(aws.StringValue(tags.Key), "Name") {
-			instanceName = aws.StringValue(tags.Value)
-		}
-	}
-
-	logger.Debugf("Attemping to download console logs for %s", instanceName)
-	filePath, err := g.saveToFile(instanceName, aws.StringValue(result.Output), filePathDir)
+	name := instanceName(instance)
+	logger.Debugf("Attemping to download console logs for %s", name)
+	filePath, err := g.saveToFile(name, aws.StringValue(result.Output), filePathDir)
 	if err != nil {
 		return "", err
 	}
@@ -213,6 +226,55 @@ func (g *Gather) downloadConsoleOutput(ctx context.Context, ec2Client *ec2.EC2,
 	return filePath, nil
 }
 
+func (g *Gather) downloadConsoleScreenshot(ctx context.Context, ec2Client *ec2.EC2, instance *ec2.Instance, filePathDir string) (string, error) {
+	result, err := ec2Client.GetConsoleScreenshotWithContext(ctx, &ec2.GetConsoleScreenshotInput{
+		InstanceId: instance.InstanceId,
+	})
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(aws.StringValue(result.ImageData))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode console screenshot")
+	}
+	filename := filepath.Join(filePathDir, fmt.Sprintf("%s-screenshot.jpg", instanceName(instance)))
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return "", errors.Wrap(err, "failed to write to file")
+	}
+	return filename, nil
+}
+
+// downloadInstanceStatus saves the status checks and scheduled events of the
+// instance.
+func (g *Gather) downloadInstanceStatus(ctx context.Context, ec2Client *ec2.EC2, instance *ec2.Instance, filePathDir string) (string, error) {
+	result, err := ec2Client.DescribeInstanceStatusWithContext(ctx, &ec2.DescribeInstanceStatusInput{
+		InstanceIds:         []*string{instance.InstanceId},
+		IncludeAllInstances: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(result.InstanceStatuses, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(filePathDir, fmt.Sprintf("%s-status.json", instanceName(instance)))
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return "", errors.Wrap(err, "failed to write to file")
+	}
+	return filename, nil
+}
+
+// instanceName returns the Name tag of the instance, or its ID.
+func instanceName(instance *ec2.Instance) string {
+	for _, tag := range instance.Tags {
+		if strings.EqualFold(aws.StringValue(tag.Key), "Name") {
+			return aws.StringValue(tag.Value)
+		}
+	}
+	return aws.StringValue(instance.InstanceId)
+}
+
 func (g *Gather) saveToFile(instanceName, content, filePathDir string) (string, error) {
 	data, err := base64.StdEncoding.DecodeString(content)
 	if err != nil {
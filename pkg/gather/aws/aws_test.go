@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceName(t *testing.T) {
+	cases := []struct {
+		name     string
+		tags     []*ec2.Tag
+		expected string
+	}{
+		{
+			name:     "no tags",
+			expected: "i-0123456789abcdef0",
+		},
+		{
+			name: "name tag",
+			tags: []*ec2.Tag{
+				{Key: aws.String("kubernetes.io/cluster/test-x2k4f"), Value: aws.String("owned")},
+				{Key: aws.String("Name"), Value: aws.String("test-x2k4f-bootstrap")},
+			},
+			expected: "test-x2k4f-bootstrap",
+		},
+		{
+			name:     "lower case name tag",
+			tags:     []*ec2.Tag{{Key: aws.String("name"), Value: aws.String("test-x2k4f-master-0")}},
+			expected: "test-x2k4f-master-0",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			instance := &ec2.Instance{InstanceId: aws.String("i-0123456789abcdef0"), Tags: tc.tags}
+			assert.Equal(t, tc.expected, instanceName(instance))
+		})
+	}
+}
+
+func TestDownloadConsoleScreenshot(t *testing.T) {
+	screenshot := []byte("\xff\xd8\xff\xe0screenshot")
+	cases := []struct {
+		name          string
+		status        int
+		imageData     string
+		expectedError string
+	}{
+		{
+			name:      "screenshot",
+			status:    http.StatusOK,
+			imageData: base64.StdEncoding.EncodeToString(screenshot),
+		},
+		{
+			name:          "unsupported instance type",
+			status:        http.StatusBadRequest,
+			expectedError: "^UnsupportedOperation: screenshots are not supported",
+		},
+		{
+			name:          "invalid image data",
+			status:        http.StatusOK,
+			imageData:     "not base64!",
+			expectedError: "^failed to decode console screenshot: ",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				if tc.status != http.StatusOK {
+					fmt.Fprint(w, `<Response><Errors><Error><Code>UnsupportedOperation</Code><Message>screenshots are not supported</Message></Error></Errors><RequestID>1</RequestID></Response>`)
+					return
+				}
+				fmt.Fprintf(w, `<GetConsoleScreenshotResponse><instanceId>i-0123456789abcdef0</instanceId><imageData>%s</imageData></GetConsoleScreenshotResponse>`, tc.imageData)
+			}))
+			defer server.Close()
+			awsSession, err := session.NewSession(aws.NewConfig().
+				WithRegion("us-east-1").
+				WithEndpoint(server.URL).
+				WithCredentials(credentials.NewStaticCredentials("AKID", "secret", "")).
+				WithMaxRetries(0))
+			require.NoError(t, err)
+
+			g := &Gather{logger: logrus.New()}
+			instance := &ec2.Instance{
+				InstanceId: aws.String("i-0123456789abcdef0"),
+				Tags:       []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String("test-x2k4f-bootstrap")}},
+			}
+			dir := t.TempDir()
+			filename, err := g.downloadConsoleScreenshot(context.Background(), ec2.New(awsSession), instance, dir)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, "test-x2k4f-bootstrap-screenshot.jpg"), filename)
+			data, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			assert.Equal(t, screenshot, data)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package baremetal gathers the console output and logs of bare metal hosts
+// through their BMCs, for when the hosts cannot be reached over SSH.
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/installer/pkg/bmc"
+	"github.com/openshift/installer/pkg/gather"
+	"github.com/openshift/installer/pkg/types/baremetal"
+)
+
+// DefaultConsoleDuration is how long the serial console of each host is
+// captured.
+const DefaultConsoleDuration = time.Minute
+
+// Gather holds the hosts whose BMCs are gathered from.
+type Gather struct {
+	logger          logrus.FieldLogger
+	hosts           []*baremetal.Host
+	directory       string
+	bmcLogBundle    string
+	consoleDuration time.Duration
+}
+
+// New returns a Gather of the hosts, which writes the BMC log bundle.
+func New(logger logrus.FieldLogger, bmcLogBundle string, hosts []*baremetal.Host) *Gather {
+	return &Gather{
+		logger:          logger,
+		hosts:           hosts,
+		directory:       filepath.Dir(bmcLogBundle),
+		bmcLogBundle:    bmcLogBundle,
+		consoleDuration: DefaultConsoleDuration,
+	}
+}
+
+// Run is the entrypoint to start the gather process. It captures the serial
+// console of the hosts with IPMI BMCs, through serial-over-LAN, and the log
+// services, e.g. the system event log, of the hosts with Redfish BMCs, whose
+// serial console is not available over HTTP.
+func (g *Gather) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	bmcLogBundleDir := strings.TrimSuffix(filepath.Base(g.bmcLogBundle), ".tar.gz")
+	filePathDir := filepath.Join(g.directory, bmcLogBundleDir)
+	if err := os.MkdirAll(filePathDir, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	var errs []error
+	var files []string
+	for _, host := range g.hosts {
+		filePath, err := g.gatherHost(ctx, host, filePathDir)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to gather host %s", host.Name))
+		} else {
+			files = append(files, filePath)
+		}
+	}
+
+	if len(files) > 0 {
+		if err := gather.CreateArchive(files, g.bmcLogBundle); err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to create archive"))
+		}
+	}
+
+	if err := gather.DeleteArchiveDirectory(filePathDir); err != nil {
+		// Note: cleanup is best effort, it shouldn't fail the gather
+		g.logger.Debugf("Failed to remove archive directory: %v", err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (g *Gather) gatherHost(ctx context.Context, host *baremetal.Host, filePathDir string) (string, error) {
+	logger := g.logger.WithField("Host", host.Name)
+
+	if details, err := bmc.IPMIDetailsForHost(host); err == nil {
+		logger.Infof("Capturing the serial console for %s", g.consoleDuration)
+		output, err := bmc.CaptureSerialConsole(ctx, details, g.consoleDuration)
+		if err != nil {
+			return "", err
+		}
+		return writeFile(filePathDir, fmt.Sprintf("%s-serial.log", host.Name), output)
+	}
+
+	client, systemID, err := bmc.RedfishClientForHost(host)
+	if err != nil {
+		return "", errors.Wrap(err, "gathering requires an IPMI or Redfish BMC")
+	}
+	logger.Info("Pulling the log services")
+	system, err := client.System(ctx, systemID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the system")
+	}
+	entries, err := client.LogEntries(ctx, system)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the log services")
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return writeFile(filePathDir, fmt.Sprintf("%s-logservices.json", host.Name), data)
+}
+
+func writeFile(directory, name string, data []byte) (string, error) {
+	filePath := filepath.Join(directory, name)
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
@@ -0,0 +1,176 @@
+// Package cluster gathers the state of a cluster which failed to complete its
+// installation through its API, like a minimal must-gather: the cluster
+// version, the cluster operators, the warning events and the pod logs of the
+// failing operators.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/openshift/installer/pkg/gather"
+)
+
+// logTailLines is the number of lines gathered from the end of the logs of
+// each container.
+const logTailLines = 5000
+
+// Gather gathers the state of the cluster into the gzipped tarball at
+// bundlePath. It fails when the API of the cluster is not reachable. The
+// pieces of the state which fail to be gathered once the API is reached are
+// reported in the returned error, while the others are still archived.
+func Gather(ctx context.Context, logger logrus.FieldLogger, config *rest.Config, bundlePath string) error {
+	configClient, err := configclient.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a config client")
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "creating a Kubernetes client")
+	}
+
+	operators, err := configClient.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "the API is not reachable")
+	}
+
+	directory := strings.TrimSuffix(bundlePath, ".tar.gz")
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	defer func() {
+		if err := gather.DeleteArchiveDirectory(directory); err != nil {
+			// Note: cleanup is best effort, it shouldn't fail the gather
+			logger.Debugf("Failed to remove archive directory: %v", err)
+		}
+	}()
+
+	var errs []error
+	var files []string
+	addFile := func(name string, data []byte) {
+		filePath := filepath.Join(directory, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if err := os.WriteFile(filePath, data, 0600); err != nil {
+			errs = append(errs, err)
+			return
+		}
+		files = append(files, filePath)
+	}
+	addYAML := func(name string, obj interface{}) {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to marshal %s", name))
+			return
+		}
+		addFile(name, data)
+	}
+
+	addYAML("cluster-operators.yaml", operators)
+
+	logger.Info("Gathering the cluster version")
+	if versions, err := configClient.ConfigV1().ClusterVersions().List(ctx, metav1.ListOptions{}); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to list the cluster versions"))
+	} else {
+		addYAML("cluster-versions.yaml", versions)
+	}
+
+	logger.Info("Gathering the warning events")
+	if events, err := kubeClient.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "type=Warning"}); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to list the events"))
+	} else {
+		addYAML("events.yaml", events)
+	}
+
+	for _, namespace := range failingOperatorNamespaces(operators.Items) {
+		logger.Infof("Gathering the pod logs of namespace %s", namespace)
+		pods, err := kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to list the pods of namespace %s", namespace))
+			continue
+		}
+		addYAML(filepath.Join("namespaces", namespace, "pods.yaml"), pods)
+		for _, pod := range pods.Items {
+			for _, status := range pod.Status.ContainerStatuses {
+				previous := []bool{false}
+				if status.RestartCount > 0 {
+					previous = append(previous, true)
+				}
+				for _, p := range previous {
+					logs, err := kubeClient.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+						Container: status.Name,
+						Previous:  p,
+						TailLines: pointer.Int64(logTailLines),
+					}).DoRaw(ctx)
+					if err != nil {
+						// The containers which never started have no logs.
+						logger.Debugf("Failed to get the logs of container %s of pod %s/%s: %v", status.Name, namespace, pod.Name, err)
+						continue
+					}
+					name := status.Name
+					if p {
+						name += "-previous"
+					}
+					addFile(filepath.Join("namespaces", namespace, "pods", pod.Name, fmt.Sprintf("%s.log", name)), logs)
+				}
+			}
+		}
+	}
+
+	if len(files) > 0 {
+		if err := gather.CreateArchive(files, bundlePath); err != nil {
+			errs = append(errs, errors.Wrap(err, "failed to create archive"))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// failingOperatorNamespaces returns the namespaces related to the cluster
+// operators which are not available or are degraded.
+func failingOperatorNamespaces(operators []configv1.ClusterOperator) []string {
+	seen := map[string]bool{}
+	var namespaces []string
+	for _, operator := range operators {
+		if !failing(operator) {
+			continue
+		}
+		for _, object := range operator.Status.RelatedObjects {
+			if object.Group != "" || object.Resource != "namespaces" || seen[object.Name] {
+				continue
+			}
+			seen[object.Name] = true
+			namespaces = append(namespaces, object.Name)
+		}
+	}
+	return namespaces
+}
+
+func failing(operator configv1.ClusterOperator) bool {
+	available := false
+	for _, condition := range operator.Status.Conditions {
+		switch {
+		case condition.Type == configv1.OperatorAvailable && condition.Status == configv1.ConditionTrue:
+			available = true
+		case condition.Type == configv1.OperatorDegraded && condition.Status == configv1.ConditionTrue:
+			return true
+		}
+	}
+	return !available
+}
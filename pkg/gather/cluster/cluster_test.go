@@ -0,0 +1,126 @@
+package cluster
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func operator(name, namespace string, conditions ...configv1.ClusterOperatorStatusCondition) configv1.ClusterOperator {
+	return configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: configv1.ClusterOperatorStatus{
+			Conditions: conditions,
+			RelatedObjects: []configv1.ObjectReference{
+				{Resource: "namespaces", Name: namespace},
+				{Group: "operator.openshift.io", Resource: "ingresscontrollers", Name: "default"},
+			},
+		},
+	}
+}
+
+func TestFailingOperatorNamespaces(t *testing.T) {
+	available := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue}
+	degraded := configv1.ClusterOperatorStatusCondition{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue}
+	operators := []configv1.ClusterOperator{
+		operator("dns", "openshift-dns", available),
+		operator("ingress", "openshift-ingress", available, degraded),
+		operator("console", "openshift-console"),
+		operator("console-operator", "openshift-console"),
+	}
+	assert.Equal(t, []string{"openshift-ingress", "openshift-console"}, failingOperatorNamespaces(operators))
+}
+
+func archiveNames(t *testing.T, bundlePath string) []string {
+	t.Helper()
+	file, err := os.Open(bundlePath)
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, filepath.Base(header.Name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestGather(t *testing.T) {
+	responses := map[string]interface{}{
+		"/apis/config.openshift.io/v1/clusteroperators": configv1.ClusterOperatorList{Items: []configv1.ClusterOperator{
+			operator("ingress", "openshift-ingress"),
+		}},
+		"/apis/config.openshift.io/v1/clusterversions": configv1.ClusterVersionList{},
+		"/api/v1/events": corev1.EventList{},
+		"/api/v1/namespaces/openshift-ingress/pods": corev1.PodList{Items: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{Name: "router-1", Namespace: "openshift-ingress"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "router", RestartCount: 2},
+			}},
+		}}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/namespaces/openshift-ingress/pods/router-1/log" {
+			w.Write([]byte("router log\n")) //nolint:errcheck
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	bundlePath := filepath.Join(t.TempDir(), "cluster-log-bundle.tar.gz")
+	err := Gather(context.Background(), logrus.StandardLogger(), &rest.Config{Host: server.URL}, bundlePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"cluster-operators.yaml",
+		"cluster-versions.yaml",
+		"events.yaml",
+		"pods.yaml",
+		"router-previous.log",
+		"router.log",
+	}, archiveNames(t, bundlePath))
+	assert.NoDirExists(t, strings.TrimSuffix(bundlePath, ".tar.gz"))
+}
+
+func TestGatherUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	bundlePath := filepath.Join(t.TempDir(), "cluster-log-bundle.tar.gz")
+	err := Gather(context.Background(), logrus.StandardLogger(), &rest.Config{Host: server.URL}, bundlePath)
+	assert.ErrorContains(t, err, "the API is not reachable")
+	assert.NoFileExists(t, bundlePath)
+}
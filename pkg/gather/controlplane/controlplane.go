@@ -0,0 +1,138 @@
+// Package controlplane gathers the log bundles of the control plane hosts
+// with the installer-masters-gather.sh script of the bootstrap host,
+// concurrently, instead of one host after the other as installer-gather.sh
+// does.
+package controlplane
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/installer/pkg/gather"
+	"github.com/openshift/installer/pkg/gather/ssh"
+)
+
+// mastersGatherScript is the script of the bootstrap host gathering the logs
+// of a control plane host into /tmp/artifacts-<gather ID> on that host.
+const mastersGatherScript = "/usr/local/bin/installer-masters-gather.sh"
+
+// Gather runs the installer-masters-gather.sh script of the bootstrap host,
+// through the client, on the control plane hosts, through the SSH proxy, given
+// as [user@]host[:port], and archives their logs under control-plane/<host>
+// in the tarball at bundlePath, compressed as named by its extension. Up to
+// workers hosts are gathered concurrently. The hosts which fail to be gathered
+// are reported in the returned error, while the others are still archived.
+func Gather(client *gossh.Client, masters []string, proxy string, keys []string, gatherID string, workers int, bundlePath string) error {
+	script := &bytes.Buffer{}
+	if err := ssh.RunWithOutput(client, "cat "+mastersGatherScript, script); err != nil {
+		return errors.Wrap(err, "failed to read the gather script of the control plane hosts")
+	}
+
+	directory := gather.TrimArchiveExtension(bundlePath)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	defer func() {
+		if err := gather.DeleteArchiveDirectory(directory); err != nil {
+			// Note: cleanup is best effort, it shouldn't fail the gather
+			logrus.Debugf("Failed to remove archive directory: %v", err)
+		}
+	}()
+
+	archive, err := gather.NewArchiveWriter(bundlePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create archive")
+	}
+
+	errs := make([]error, len(masters))
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				master := masters[job]
+				logrus.Infof("Pulling debug logs from the control plane host %s", master)
+				errs[job] = errors.Wrapf(gatherMaster(master, proxy, keys, script.Bytes(), gatherID, directory, archive), "failed to gather the control plane host %s", master)
+			}
+		}()
+	}
+	for job := range masters {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := archive.Close(); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to create archive"))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// gatherMaster runs the script on the control plane host and downloads its
+// logs, which are then added to the archive under
+// <directory>/control-plane/<master>.
+func gatherMaster(master, proxy string, keys []string, script []byte, gatherID, directory string, archive *gather.ArchiveWriter) error {
+	client, err := ssh.NewClientThroughProxy("core", net.JoinHostPort(master, "22"), keys, proxy)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := ssh.RunWithInput(client, fmt.Sprintf("sudo bash -s -- --id '%s'", gatherID), bytes.NewReader(script)); err != nil {
+		return errors.Wrap(err, "failed to run the gather script")
+	}
+
+	// The logs are downloaded before they are added to the archive, so that
+	// the hosts do not wait for each other on the archive.
+	hostArchive := filepath.Join(directory, master+".tar")
+	file, err := os.Create(hostArchive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := ssh.RunWithOutput(client, fmt.Sprintf("sudo tar -cf - -C '/tmp/artifacts-%s' .", gatherID), file); err != nil {
+		return errors.Wrap(err, "failed to download the logs")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return addArchive(archive, tar.NewReader(file), filepath.Join(directory, "control-plane", master))
+}
+
+// addArchive adds the entries of the tar reader to the archive, under the
+// directory.
+func addArchive(archive *gather.ArchiveWriter, tarReader *tar.Reader, directory string) error {
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read the logs")
+		}
+		name := filepath.Join(directory, header.Name)
+		if header.Typeflag == tar.TypeDir {
+			name += "/"
+		}
+		header.Name = name
+		if err := archive.Add(header, tarReader); err != nil {
+			return errors.Wrap(err, "failed to archive the logs")
+		}
+	}
+}
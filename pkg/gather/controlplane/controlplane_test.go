@@ -0,0 +1,58 @@
+package controlplane
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/installer/pkg/gather"
+)
+
+func TestAddArchive(t *testing.T) {
+	hostArchive := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(hostArchive)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755}))
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "./journals/", Typeflag: tar.TypeDir, Mode: 0755}))
+	content := []byte("kubelet logs")
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "./journals/kubelet.log", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+	_, err := tarWriter.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+
+	bundlePath := filepath.Join(t.TempDir(), "control-plane-log-bundle.tar.gz")
+	archive, err := gather.NewArchiveWriter(bundlePath)
+	require.NoError(t, err)
+	directory := filepath.Join(gather.TrimArchiveExtension(bundlePath), "control-plane", "10.0.0.5")
+	require.NoError(t, addArchive(archive, tar.NewReader(hostArchive), directory))
+	require.NoError(t, archive.Close())
+
+	file, err := os.Open(bundlePath)
+	require.NoError(t, err)
+	defer file.Close()
+	decompressor, err := gather.NewDecompressor(gather.CompressionOf(bundlePath), file)
+	require.NoError(t, err)
+	defer decompressor.Close()
+	entries := map[string]string{}
+	tarReader := tar.NewReader(decompressor)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		entries[header.Name] = string(data)
+	}
+	assert.Equal(t, map[string]string{
+		directory + "/": "",
+		filepath.Join(directory, "journals") + "/":       "",
+		filepath.Join(directory, "journals/kubelet.log"): "kubelet logs",
+	}, entries)
+}
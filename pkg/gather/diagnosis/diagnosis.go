@@ -0,0 +1,210 @@
+// Package diagnosis diagnoses the probable causes of installation failures
+// from the logs of a gather or agent log bundle, by matching the lines of the logs with
+// known failure signatures.
+package diagnosis
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/ulikunitz/xz"
+
+	"github.com/openshift/installer/pkg/gather"
+)
+
+// maxLineLength is the length of the longest line matched. Longer lines,
+// e.g. of binary files, are skipped.
+const maxLineLength = 1024 * 1024
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is the offset of the magic in the header of a tarball.
+const tarMagicOffset = 257
+
+// skippedExtensions are the extensions of the files which are not logs.
+var skippedExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".bmp":  true,
+}
+
+// Finding is a known failure found in a bundle.
+type Finding struct {
+	Signature Signature
+	// Matches is the number of lines matching the signature.
+	Matches int
+	// Files are the files with lines matching the signature.
+	Files []string
+	// Example is the first line matching the signature.
+	Example string
+}
+
+// DiagnoseBundle diagnoses the bundle at the path, either a gather bundle or
+// an agent log bundle, with the signatures.
+func DiagnoseBundle(bundlePath string, signatures []Signature) ([]Finding, error) {
+	bundle, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open the bundle")
+	}
+	defer bundle.Close()
+	return Diagnose(bundle, signatures)
+}
+
+// Diagnose returns the findings of the signatures in the tarball, which may
+// be gzip or xz compressed, including the tarballs within it, from the most
+// to the least probable cause of the failure.
+func Diagnose(bundle io.Reader, signatures []Signature) ([]Finding, error) {
+	findings := make([]*Finding, len(signatures))
+	for i := range signatures {
+		findings[i] = &Finding{Signature: signatures[i]}
+	}
+	if err := diagnoseArchive(bundle, "", findings); err != nil {
+		return nil, err
+	}
+
+	var found []Finding
+	for _, finding := range findings {
+		if finding.Matches > 0 {
+			found = append(found, *finding)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Signature.Weight != found[j].Signature.Weight {
+			return found[i].Signature.Weight > found[j].Signature.Weight
+		}
+		return found[i].Matches > found[j].Matches
+	})
+	return found, nil
+}
+
+// decompress returns the tarball of the archive, decompressing it when it is
+// gzip or xz compressed.
+func decompress(archive io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(archive)
+	magic, err := buffered.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(buffered)
+	case bytes.HasPrefix(magic, xzMagic):
+		xzReader, err := xz.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xzReader), nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		return gather.NewDecompressor(gather.ZstdCompression, buffered)
+	case len(magic) == tarMagicOffset+len(tarMagic) && bytes.Equal(magic[tarMagicOffset:], tarMagic):
+		return io.NopCloser(buffered), nil
+	default:
+		return nil, errors.New("neither a tarball nor gzip, xz or zstd compressed")
+	}
+}
+
+func diagnoseArchive(archive io.Reader, prefix string, findings []*Finding) error {
+	uncompressed, err := decompress(archive)
+	if err != nil {
+		return errors.Wrap(err, "could not decompress the bundle")
+	}
+	defer uncompressed.Close()
+
+	tarReader := tar.NewReader(uncompressed)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "encountered an error reading from the bundle")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Join(prefix, header.Name)
+		switch {
+		case isArchive(name):
+			// The archives within the bundle which cannot be read, e.g.
+			// truncated by the maximum size of the gather, are diagnosed as
+			// far as they can be read.
+			if err := diagnoseArchive(tarReader, name, findings); err != nil {
+				logrus.Warnf("Diagnosed %s only partially: %v", name, err)
+			}
+		case skippedExtensions[strings.ToLower(path.Ext(name))]:
+		default:
+			diagnoseFile(tarReader, name, findings)
+		}
+	}
+}
+
+func isArchive(name string) bool {
+	for _, suffix := range []string{".tar", ".tar.gz", ".tgz", ".tar.xz", ".txz", ".tar.zst"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func diagnoseFile(file io.Reader, name string, findings []*Finding) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	matched := make([]bool, len(findings))
+	for scanner.Scan() {
+		line := scanner.Text()
+		for i, finding := range findings {
+			if !finding.Signature.Pattern.MatchString(line) {
+				continue
+			}
+			finding.Matches++
+			if finding.Example == "" {
+				finding.Example = strings.TrimSpace(line)
+			}
+			if !matched[i] {
+				matched[i] = true
+				finding.Files = append(finding.Files, name)
+			}
+		}
+	}
+	// The lines after a line which is too long, e.g. in binary files, are
+	// not diagnosed.
+}
+
+// Print prints the findings, from the most to the least probable cause.
+func Print(w io.Writer, findings []Finding) error {
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No known failure was found in the bundle.")
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "Probable causes, from the most to the least likely:"); err != nil {
+		return err
+	}
+	for i, finding := range findings {
+		example := finding.Example
+		if len(example) > 200 {
+			example = example[:200] + "..."
+		}
+		if _, err := fmt.Fprintf(w, "%d. %s (%d matching lines in %d files)\n   e.g. %s: %s\n   Hint: %s\n",
+			i+1, finding.Signature.Cause, finding.Matches, len(finding.Files), finding.Files[0], example, finding.Signature.Remediation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package diagnosis
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func xzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	xw, err := xz.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = xw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, xw.Close())
+	return buf.Bytes()
+}
+
+func codes(findings []Finding) []string {
+	var codes []string
+	for _, finding := range findings {
+		codes = append(codes, finding.Signature.Code)
+	}
+	return codes
+}
+
+func TestDiagnose(t *testing.T) {
+	cases := []struct {
+		name          string
+		bundle        func(t *testing.T) io.Reader
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "no known failure",
+			bundle: func(t *testing.T) io.Reader {
+				return bytes.NewReader(gzipped(t, tarball(t, map[string]string{
+					"log-bundle/bootstrap/journals/bootkube.log": "bootkube.service complete\n",
+				})))
+			},
+		},
+		{
+			name: "ranked by weight then matches",
+			bundle: func(t *testing.T) io.Reader {
+				return bytes.NewReader(gzipped(t, tarball(t, map[string]string{
+					"log-bundle/control-plane/10.0.0.1/journals/kubelet.log": "Back-off pulling image: ImagePullBackOff\nErrImagePull\n",
+					"log-bundle/control-plane/10.0.0.2/journals/etcd.log":    "etcdserver: request timed out\n",
+					"log-bundle/serial/master-0-serial.log":                  "ignition[812]: GET error: Get \"https://api-int.example.com:22623/config/master\": EOF\n",
+					"log-bundle/bootstrap/journals/bootkube.log":             "dial tcp: lookup api.example.com on 10.0.0.2:53: no such host\n",
+					"log-bundle/screenshots/master-0.png":                    "ErrImagePull",
+				})))
+			},
+			expected: []string{"BOOTSTRAP_IGNITION_FETCH", "DNS_RESOLUTION", "IMAGE_PULL", "ETCD_NO_QUORUM"},
+		},
+		{
+			name: "console of a boot loop",
+			bundle: func(t *testing.T) io.Reader {
+				return bytes.NewReader(gzipped(t, tarball(t, map[string]string{
+					"console-log-bundle/master-0-serial.log": "[    3.141592] Kernel panic - not syncing: VFS: Unable to mount root fs on unknown-block(0,0)\n",
+					"console-log-bundle/master-1-serial.log": "Entering emergency mode. Exit the shell to continue.\nErrImagePull\n",
+				})))
+			},
+			expected: []string{"KERNEL_PANIC", "IMAGE_PULL"},
+		},
+		{
+			name: "nested agent log bundle",
+			bundle: func(t *testing.T) io.Reader {
+				nested := gzipped(t, tarball(t, map[string]string{
+					"journal.log": "x509: certificate has expired or is not yet valid\n",
+				}))
+				return bytes.NewReader(xzipped(t, tarball(t, map[string]string{
+					"agent-gather/master-0.tar.gz": string(nested),
+				})))
+			},
+			expected: []string{"TLS_CERTIFICATE"},
+		},
+		{
+			name: "nested cluster logs of the agent rest API",
+			bundle: func(t *testing.T) io.Reader {
+				nested := tarball(t, map[string]string{
+					"controller_logs.tar.gz": string(gzipped(t, tarball(t, map[string]string{
+						"assisted-installer-controller.logs": "no space left on device\n",
+					}))),
+				})
+				return bytes.NewReader(gzipped(t, tarball(t, map[string]string{
+					"agent-log-bundle/cluster-logs.tar": string(nested),
+				})))
+			},
+			expected: []string{"DISK_FULL"},
+		},
+		{
+			name: "not an archive",
+			bundle: func(t *testing.T) io.Reader {
+				return strings.NewReader("no space left on device\n")
+			},
+			expectedError: "could not decompress the bundle: neither a tarball nor gzip, xz or zstd compressed",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings, err := Diagnose(tc.bundle(t), Signatures)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, codes(findings))
+		})
+	}
+}
+
+func TestDiagnoseCounts(t *testing.T) {
+	bundle := gzipped(t, tarball(t, map[string]string{
+		"a/kubelet.log": "ErrImagePull\nImagePullBackOff\n",
+	}))
+	findings, err := Diagnose(bytes.NewReader(bundle), Signatures)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 2, findings[0].Matches)
+	assert.Equal(t, []string{"a/kubelet.log"}, findings[0].Files)
+	assert.Equal(t, "ErrImagePull", findings[0].Example)
+
+	var out bytes.Buffer
+	require.NoError(t, Print(&out, findings))
+	assert.Contains(t, out.String(), "1. Images failed to be pulled (2 matching lines in 1 files)")
+}
+
+func TestWriteReport(t *testing.T) {
+	cases := []struct {
+		name         string
+		files        map[string]string
+		expectedCode string
+		expectedLen  int
+	}{
+		{
+			name:         "no known failure",
+			files:        map[string]string{"a.log": "ok\n"},
+			expectedCode: UnknownCode,
+		},
+		{
+			name: "most probable cause first",
+			files: map[string]string{
+				"a.log": "ErrImagePull\nfailed to pull release image\n",
+			},
+			expectedCode: "RELEASE_IMAGE_PULL",
+			expectedLen:  2,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings, err := Diagnose(bytes.NewReader(gzipped(t, tarball(t, tc.files))), Signatures)
+			require.NoError(t, err)
+
+			directory := t.TempDir()
+			reportPath, err := WriteReport(directory, NewReport("/assets/log-bundle-1.tar.gz", findings))
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(directory, "failure.json"), reportPath)
+
+			data, err := os.ReadFile(reportPath)
+			require.NoError(t, err)
+			var report Report
+			require.NoError(t, json.Unmarshal(data, &report))
+			assert.Equal(t, tc.expectedCode, report.Code)
+			assert.Equal(t, "log-bundle-1.tar.gz", report.Bundle)
+			assert.Len(t, report.Causes, tc.expectedLen)
+		})
+	}
+}
+
+func TestLoadSignatures(t *testing.T) {
+	cases := []struct {
+		name          string
+		data          string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			data: `version: 1
+signatures:
+- code: OUT_OF_MEMORY
+  cause: Processes ran out of memory
+  remediation: Increase the memory of the machines.
+  pattern: 'Out of memory: Killed process'
+  weight: 45
+`,
+			expected: []string{"OUT_OF_MEMORY"},
+		},
+		{
+			name:          "unsupported version",
+			data:          "version: 2\nsignatures: []\n",
+			expectedError: "unsupported signatures version 2, must be 1",
+		},
+		{
+			name:          "unknown field",
+			data:          "version: 1\nsignatures:\n- code: A\n  cause: a\n  pattern: a\n  regex: a\n",
+			expectedError: `failed to unmarshal the signatures: error unmarshaling JSON: while decoding JSON: json: unknown field "regex"`,
+		},
+		{
+			name:          "duplicate code",
+			data:          "version: 1\nsignatures:\n- code: A\n  cause: a\n  pattern: a\n- code: A\n  cause: b\n  pattern: b\n",
+			expectedError: "duplicate signature A",
+		},
+		{
+			name:          "invalid pattern",
+			data:          "version: 1\nsignatures:\n- code: A\n  cause: a\n  pattern: '(a'\n",
+			expectedError: "invalid pattern of signature A: error parsing regexp: missing closing ): `(a`",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			signatures, err := LoadSignatures([]byte(tc.data))
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			var codes []string
+			for _, signature := range signatures {
+				codes = append(codes, signature.Code)
+			}
+			assert.Equal(t, tc.expected, codes)
+
+			findings, err := Diagnose(bytes.NewReader(gzipped(t, tarball(t, map[string]string{
+				"a/journal.log": "kernel: Out of memory: Killed process 1234 (etcd)\n",
+			}))), signatures)
+			require.NoError(t, err)
+			assert.Len(t, findings, 1)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package diagnosis
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReportFileName is the name of the failure report in the assets
+	// directory.
+	ReportFileName = "failure.json"
+
+	// UnknownCode is the code of the failure reports without any known
+	// failure.
+	UnknownCode = "UNKNOWN"
+)
+
+// Report is the machine-readable classification of an installation failure.
+// Its fields and codes are stable, for CI systems to aggregate the failures
+// across installs.
+type Report struct {
+	// Code is the code of the most probable cause, or UnknownCode.
+	Code string `json:"code"`
+	// Bundle is the name of the diagnosed bundle.
+	Bundle string `json:"bundle"`
+	// Causes are the probable causes, from the most to the least likely.
+	Causes []ReportCause `json:"causes"`
+}
+
+// ReportCause is a probable cause of a failure report.
+type ReportCause struct {
+	Code        string   `json:"code"`
+	Cause       string   `json:"cause"`
+	Remediation string   `json:"remediation"`
+	Matches     int      `json:"matches"`
+	Files       []string `json:"files"`
+	Example     string   `json:"example"`
+}
+
+// NewReport returns the failure report of the findings in the bundle.
+func NewReport(bundlePath string, findings []Finding) *Report {
+	report := &Report{
+		Code:   UnknownCode,
+		Bundle: filepath.Base(bundlePath),
+		Causes: []ReportCause{},
+	}
+	for _, finding := range findings {
+		report.Causes = append(report.Causes, ReportCause{
+			Code:        finding.Signature.Code,
+			Cause:       finding.Signature.Cause,
+			Remediation: finding.Signature.Remediation,
+			Matches:     finding.Matches,
+			Files:       finding.Files,
+			Example:     finding.Example,
+		})
+	}
+	if len(report.Causes) > 0 {
+		report.Code = report.Causes[0].Code
+	}
+	return report
+}
+
+// WriteReport writes the failure report to the failure.json file in the
+// directory, and returns its path.
+func WriteReport(directory string, report *Report) (string, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal the failure report")
+	}
+	reportPath := filepath.Join(directory, ReportFileName)
+	if err := os.WriteFile(reportPath, append(data, '\n'), 0640); err != nil {
+		return "", errors.Wrap(err, "failed to write the failure report")
+	}
+	return reportPath, nil
+}
@@ -0,0 +1,109 @@
+package diagnosis
+
+import (
+	_ "embed" // for the embedded signatures
+	"os"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// SignaturesVersion is the version of the format of the signature bundles
+// which is loaded.
+const SignaturesVersion = 1
+
+// defaultSignatures is the signature bundle shipped with the installer.
+//
+//go:embed signatures.yaml
+var defaultSignatures []byte
+
+// Signature is a known failure, recognized by the lines it logs.
+type Signature struct {
+	// Code identifies the failure in the stable taxonomy of the failure
+	// reports, for CI systems to aggregate the failures.
+	Code string
+	// Cause is the probable cause of the failure reported to the user.
+	Cause string
+	// Remediation is a hint to fix the cause.
+	Remediation string
+	// Pattern matches the lines logged by the failure.
+	Pattern *regexp.Regexp
+	// Weight ranks the causes: a cause with a higher weight explains the
+	// failures of the causes with a lower weight, e.g. the machines which
+	// failed to get their Ignition config cannot pull any image.
+	Weight int
+}
+
+// signatureBundle is a versioned YAML bundle of signatures.
+type signatureBundle struct {
+	Version    int `json:"version"`
+	Signatures []struct {
+		Code        string `json:"code"`
+		Cause       string `json:"cause"`
+		Remediation string `json:"remediation"`
+		Pattern     string `json:"pattern"`
+		Weight      int    `json:"weight"`
+	} `json:"signatures"`
+}
+
+// Signatures are the known failures shipped with the installer.
+var Signatures = mustLoadSignatures(defaultSignatures)
+
+func mustLoadSignatures(data []byte) []Signature {
+	signatures, err := LoadSignatures(data)
+	if err != nil {
+		panic(err)
+	}
+	return signatures
+}
+
+// LoadSignatures returns the signatures of the YAML bundle.
+func LoadSignatures(data []byte) ([]Signature, error) {
+	var bundle signatureBundle
+	if err := yaml.UnmarshalStrict(data, &bundle); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal the signatures")
+	}
+	if bundle.Version != SignaturesVersion {
+		return nil, errors.Errorf("unsupported signatures version %d, must be %d", bundle.Version, SignaturesVersion)
+	}
+	if len(bundle.Signatures) == 0 {
+		return nil, errors.New("no signatures")
+	}
+	signatures := make([]Signature, 0, len(bundle.Signatures))
+	codes := map[string]bool{}
+	for i, s := range bundle.Signatures {
+		if s.Code == "" || s.Cause == "" || s.Pattern == "" {
+			return nil, errors.Errorf("signature %d must have a code, a cause and a pattern", i)
+		}
+		if codes[s.Code] {
+			return nil, errors.Errorf("duplicate signature %s", s.Code)
+		}
+		codes[s.Code] = true
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern of signature %s", s.Code)
+		}
+		signatures = append(signatures, Signature{
+			Code:        s.Code,
+			Cause:       s.Cause,
+			Remediation: s.Remediation,
+			Pattern:     pattern,
+			Weight:      s.Weight,
+		})
+	}
+	return signatures, nil
+}
+
+// LoadSignaturesFile returns the signatures of the YAML bundle at the path.
+func LoadSignaturesFile(path string) ([]Signature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the signatures")
+	}
+	signatures, err := LoadSignatures(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid signatures in %s", path)
+	}
+	return signatures, nil
+}
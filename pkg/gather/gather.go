@@ -2,7 +2,6 @@ package gather
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"io"
 	"os"
 	"path/filepath"
@@ -34,28 +33,20 @@ func New(logger logrus.FieldLogger, serialLogBundle string, bootstrap string, ma
 	return creator(logger, serialLogBundle, bootstrap, masters, metadata)
 }
 
-// CreateArchive creates a gzipped tar file.
+// CreateArchive creates a tar file, compressed as named by its extension:
+// gzip for .tar.gz and zstd for .tar.zst.
 func CreateArchive(files []string, archiveName string) error {
-	file, err := os.Create(archiveName)
+	archive, err := NewArchiveWriter(archiveName)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	gzipWriter := gzip.NewWriter(file)
-	defer gzipWriter.Close()
-
-	tarWriter := tar.NewWriter(gzipWriter)
-	defer tarWriter.Close()
-
 	for _, filename := range files {
-		err := addToArchive(tarWriter, filename)
-		if err != nil {
+		if err := archive.AddFile(filename); err != nil {
+			archive.Close()
 			return err
 		}
 	}
-
-	return nil
+	return archive.Close()
 }
 
 func addToArchive(tarWriter *tar.Writer, filename string) error {
@@ -89,27 +80,24 @@ func addToArchive(tarWriter *tar.Writer, filename string) error {
 	return nil
 }
 
-// CombineArchives creates a single gzipped tar file from multiple archives.
-// archiveName is the target gzipped tar file. archives maps the existing
-// gzipped tar files to a subdirectory in the new gzipped tar file.
-func CombineArchives(archiveName string, archives map[string]string) error {
-	suffix := ".tar.gz"
-
-	combinedArchive, err := os.Create(archiveName)
+// CombineArchives creates a single compressed tar file from multiple archives.
+// archiveName is the target compressed tar file. archives maps the existing
+// compressed tar files to a subdirectory in the new compressed tar file. The
+// archives are compressed as named by their extension.
+func CombineArchives(archiveName string, archives map[string]string) (err error) {
+	combinedArchive, err := NewArchiveWriter(archiveName)
 	if err != nil {
 		return err
 	}
-	defer combinedArchive.Close()
-
-	combinedGzipWriter := gzip.NewWriter(combinedArchive)
-	defer combinedGzipWriter.Close()
-
-	combinedTarWriter := tar.NewWriter(combinedGzipWriter)
-	defer combinedTarWriter.Close()
+	defer func() {
+		if closeErr := combinedArchive.Close(); err == nil {
+			err = closeErr
+		}
+	}()
 
-	combinedDirectory := strings.TrimSuffix(archiveName, suffix)
+	combinedDirectory := TrimArchiveExtension(archiveName)
 	if archiveName[0] == '.' || archiveName[0] == '/' {
-		combinedDirectory = strings.TrimSuffix(filepath.Base(archiveName), suffix)
+		combinedDirectory = TrimArchiveExtension(filepath.Base(archiveName))
 	}
 
 	for archive, subDirectory := range archives {
@@ -125,17 +113,17 @@ func CombineArchives(archiveName string, archives map[string]string) error {
 		}
 		defer file.Close()
 
-		directory := strings.TrimSuffix(archive, suffix) + "/"
+		directory := TrimArchiveExtension(archive) + "/"
 		if subDirectory != "" && !strings.HasSuffix(subDirectory, "/") {
 			subDirectory += "/"
 		}
 
-		gzipReader, err := gzip.NewReader(file)
+		decompressor, err := NewDecompressor(CompressionOf(archive), file)
 		if err != nil {
 			return err
 		}
-		defer gzipReader.Close()
-		tarReader := tar.NewReader(gzipReader)
+		defer decompressor.Close()
+		tarReader := tar.NewReader(decompressor)
 
 		for {
 			header, err := tarReader.Next()
@@ -153,12 +141,7 @@ func CombineArchives(archiveName string, archives map[string]string) error {
 			}
 			header.Name = newHeaderName
 
-			err = combinedTarWriter.WriteHeader(header)
-			if err != nil {
-				return err
-			}
-
-			_, err = io.Copy(combinedTarWriter, tarReader)
+			err = combinedArchive.Add(header, tarReader)
 			if err != nil {
 				return err
 			}
@@ -173,6 +156,71 @@ func CombineArchives(archiveName string, archives map[string]string) error {
 	return nil
 }
 
+// TruncateArchive rewrites the archive with at most maxSize bytes of file
+// contents, in the order of its entries. The file reaching the maximum size
+// is truncated and the files after it are left out, while the directories
+// and links are kept.
+func TruncateArchive(archiveName string, maxSize int64) (err error) {
+	file, err := os.Open(archiveName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	decompressor, err := NewDecompressor(CompressionOf(archiveName), file)
+	if err != nil {
+		return err
+	}
+	defer decompressor.Close()
+
+	compression := CompressionOf(archiveName)
+	truncatedName := TrimArchiveExtension(archiveName) + "-truncated" + compression.Extension()
+	truncated, err := NewArchiveWriter(truncatedName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := truncated.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(truncatedName, archiveName)
+		} else if removeErr := os.Remove(truncatedName); removeErr != nil {
+			logrus.Debugf("Failed to remove %s: %v", truncatedName, removeErr)
+		}
+	}()
+
+	remaining := maxSize
+	skipped := 0
+	tarReader := tar.NewReader(decompressor)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg {
+			if remaining <= 0 {
+				skipped++
+				continue
+			}
+			if header.Size > remaining {
+				logrus.Warnf("Truncated %s, the maximum size of the gathered data was reached", header.Name)
+				header.Size = remaining
+			}
+			remaining -= header.Size
+		}
+		if err := truncated.Add(header, io.LimitReader(tarReader, header.Size)); err != nil {
+			return err
+		}
+	}
+	if skipped > 0 {
+		logrus.Warnf("Left out %d files, the maximum size of the gathered data was reached", skipped)
+	}
+	return nil
+}
+
 // DeleteArchiveDirectory deletes an archive directory
 func DeleteArchiveDirectory(archiveDirectory string) error {
 	if archiveDirectory == "" {
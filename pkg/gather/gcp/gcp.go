@@ -2,6 +2,8 @@ package gcp
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -104,6 +106,20 @@ func (g *Gather) Run() error {
 				}
 
 				files = append(files, filename)
+
+				// Screenshots capture the early boot failures, e.g. of ignition,
+				// which are not on the serial console.
+				if screenshot, err := g.saveScreenshot(ctx, isvc, instance, filePathDir); err != nil {
+					g.logger.Debugf("Failed to get the screenshot of %s: %v", instance.Name, err)
+				} else {
+					files = append(files, screenshot)
+				}
+
+				if status, err := saveStatus(instance, filePathDir); err != nil {
+					g.logger.Debugf("Failed to save the status of %s: %v", instance.Name, err)
+				} else {
+					files = append(files, status)
+				}
 			}
 		}
 		return nil
@@ -126,3 +142,47 @@ func (g *Gather) Run() error {
 
 	return utilerrors.NewAggregate(errs)
 }
+
+// saveStatus saves the status of the instance, e.g. whether it was
+// terminated or is being repaired, and when it was last started and stopped.
+func saveStatus(instance *compute.Instance, filePathDir string) (string, error) {
+	data, err := json.MarshalIndent(struct {
+		Name               string `json:"name"`
+		Status             string `json:"status"`
+		StatusMessage      string `json:"statusMessage,omitempty"`
+		LastStartTimestamp string `json:"lastStartTimestamp,omitempty"`
+		LastStopTimestamp  string `json:"lastStopTimestamp,omitempty"`
+	}{
+		Name:               instance.Name,
+		Status:             instance.Status,
+		StatusMessage:      instance.StatusMessage,
+		LastStartTimestamp: instance.LastStartTimestamp,
+		LastStopTimestamp:  instance.LastStopTimestamp,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(filePathDir, fmt.Sprintf("%s-status.json", instance.Name))
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// saveScreenshot saves the screenshot of the instance, which is only
+// available when its display device is enabled.
+func (g *Gather) saveScreenshot(ctx context.Context, isvc *compute.InstancesService, instance *compute.Instance, filePathDir string) (string, error) {
+	screenshot, err := isvc.GetScreenshot(g.credentials.ProjectID, filepath.Base(instance.Zone), instance.Name).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(screenshot.Contents)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode the screenshot")
+	}
+	filename := filepath.Join(filePathDir, fmt.Sprintf("%s-screenshot.png", instance.Name))
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
@@ -0,0 +1,82 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	googleoauth "golang.org/x/oauth2/google"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+func TestSaveScreenshot(t *testing.T) {
+	screenshot := []byte("\x89PNG\r\n\x1a\nscreenshot")
+	cases := []struct {
+		name          string
+		status        int
+		contents      string
+		expectedError string
+	}{
+		{
+			name:     "screenshot",
+			status:   http.StatusOK,
+			contents: base64.StdEncoding.EncodeToString(screenshot),
+		},
+		{
+			name:          "display device disabled",
+			status:        http.StatusBadRequest,
+			expectedError: "Display device needs to be enabled",
+		},
+		{
+			name:          "invalid contents",
+			status:        http.StatusOK,
+			contents:      "not base64!",
+			expectedError: "^failed to decode the screenshot: ",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				if tc.status != http.StatusOK {
+					fmt.Fprint(w, `{"error":{"code":400,"message":"Display device needs to be enabled for the instance"}}`)
+					return
+				}
+				fmt.Fprintf(w, `{"contents":%q}`, tc.contents)
+			}))
+			defer server.Close()
+			svc, err := compute.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+			require.NoError(t, err)
+
+			g := &Gather{credentials: &googleoauth.Credentials{ProjectID: "test-project"}, logger: logrus.New()}
+			instance := &compute.Instance{
+				Name: "test-x2k4f-bootstrap",
+				Zone: "https://www.googleapis.com/compute/v1/projects/test-project/zones/us-central1-a",
+			}
+			dir := t.TempDir()
+			filename, err := g.saveScreenshot(context.Background(), compute.NewInstancesService(svc), instance, dir)
+			assert.Equal(t, "/projects/test-project/zones/us-central1-a/instances/test-x2k4f-bootstrap/screenshot", path)
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, "test-x2k4f-bootstrap-screenshot.png"), filename)
+			data, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			assert.Equal(t, screenshot, data)
+		})
+	}
+}
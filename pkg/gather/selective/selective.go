@@ -0,0 +1,297 @@
+// Package selective gathers only the named systemd units, journal priorities
+// and files from the bootstrap and control plane hosts over SSH, instead of
+// the full log bundle of installer-gather.sh, which is hundreds of megabytes.
+package selective
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/installer/pkg/gather"
+	"github.com/openshift/installer/pkg/gather/ssh"
+)
+
+var (
+	// unitRegex matches the names of systemd units, optionally with a glob.
+	unitRegex = regexp.MustCompile(`^[A-Za-z0-9@:._*?-]+$`)
+	// pathRegex matches the file globs passed to the remote shell, which
+	// must not hold any other shell syntax.
+	pathRegex = regexp.MustCompile(`^/[A-Za-z0-9/._*?\[\]-]*$`)
+
+	// priorities are the journal priorities, from the most to the least
+	// severe.
+	priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+)
+
+// Options selects what is gathered.
+type Options struct {
+	// Units are the systemd units whose journal is gathered.
+	Units []string
+	// Priority is the least severe journal priority gathered, by name or
+	// number as accepted by journalctl -p.
+	Priority string
+	// Paths are the globs of the files gathered.
+	Paths []string
+	// MaxSize is the maximum size in bytes of the gathered data, before it
+	// is compressed, or 0 for no maximum.
+	MaxSize int64
+}
+
+// IsEmpty returns whether no selection was made, in which case the full log
+// bundle is gathered instead, truncated to the maximum size, if any.
+func (o *Options) IsEmpty() bool {
+	return len(o.Units) == 0 && o.Priority == "" && len(o.Paths) == 0
+}
+
+// Validate checks that the options are safe to pass to the remote shell.
+func (o *Options) Validate() error {
+	var errs []error
+	for _, unit := range o.Units {
+		if !unitRegex.MatchString(unit) {
+			errs = append(errs, errors.Errorf("invalid unit %q", unit))
+		}
+	}
+	if o.Priority != "" && !validPriority(o.Priority) {
+		errs = append(errs, errors.Errorf("invalid journal priority %q, must be one of %s or 0-7", o.Priority, strings.Join(priorities, ", ")))
+	}
+	for _, path := range o.Paths {
+		if !pathRegex.MatchString(path) {
+			errs = append(errs, errors.Errorf("invalid path %q, must be an absolute path or glob", path))
+		}
+	}
+	if o.MaxSize < 0 {
+		errs = append(errs, errors.Errorf("invalid maximum size %d", o.MaxSize))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validPriority(priority string) bool {
+	for i, p := range priorities {
+		if priority == p || priority == fmt.Sprint(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// commands returns the remote commands gathering the selection, by the path
+// of the file their output is written to.
+func (o *Options) commands() map[string]string {
+	commands := map[string]string{}
+	priority := ""
+	if o.Priority != "" {
+		priority = " -p " + o.Priority
+	}
+	for _, unit := range o.Units {
+		commands[filepath.Join("journals", unit+".log")] = fmt.Sprintf("sudo journalctl --no-pager -o short-iso%s -u '%s'", priority, unit)
+	}
+	if len(o.Units) == 0 && (o.Priority != "" || len(o.Paths) == 0) {
+		commands[filepath.Join("journals", "journal.log")] = "sudo journalctl --no-pager -o short-iso" + priority
+	}
+	if len(o.Paths) > 0 {
+		// The globs are expanded by the remote shell, and the globs which do
+		// not match any file are skipped.
+		commands["files.tar.gz"] = fmt.Sprintf("sudo sh -c 'tar -czf - --ignore-failed-read -- %s 2>/dev/null; true'", strings.Join(o.Paths, " "))
+	}
+	return commands
+}
+
+// Gather gathers the selection from the bootstrap host, through the client,
+// and from the control plane hosts, through the SSH proxy, given as
+// [user@]host[:port], into the tarball at bundlePath, compressed as named by
+// its extension. Up to workers hosts are gathered concurrently, and their
+// files are added to the tarball as soon as they are gathered. The hosts which
+// fail to be gathered are reported in the returned error, while the others
+// are still archived.
+func Gather(client *gossh.Client, masters []string, proxy string, keys []string, opts Options, workers int, bundlePath string) error {
+	directory := gather.TrimArchiveExtension(bundlePath)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	defer func() {
+		if err := gather.DeleteArchiveDirectory(directory); err != nil {
+			// Note: cleanup is best effort, it shouldn't fail the gather
+			logrus.Debugf("Failed to remove archive directory: %v", err)
+		}
+	}()
+
+	archive, err := gather.NewArchiveWriter(bundlePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to create archive")
+	}
+	a := &archiver{archive: archive}
+
+	hosts := []func(*budget) error{
+		func(budget *budget) error {
+			return errors.Wrap(gatherHost(client, opts, filepath.Join(directory, "bootstrap"), budget, a), "failed to gather the bootstrap host")
+		},
+	}
+	for _, master := range masters {
+		master := master
+		hosts = append(hosts, func(budget *budget) error {
+			return errors.Wrapf(gatherMaster(master, proxy, keys, opts, filepath.Join(directory, "control-plane", master), budget, a), "failed to gather the control plane host %s", master)
+		})
+	}
+
+	budget := &budget{remaining: opts.MaxSize, unlimited: opts.MaxSize == 0}
+	errs := make([]error, len(hosts))
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				errs[job] = hosts[job](budget)
+			}
+		}()
+	}
+	for job := range hosts {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := archive.Close(); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to create archive"))
+	}
+	if a.files == 0 {
+		// Nothing was gathered, so there is no archive to combine.
+		if err := os.Remove(bundlePath); err != nil {
+			logrus.Debugf("Failed to remove the empty archive: %v", err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// archiver adds the gathered files to the archive, counting them.
+type archiver struct {
+	archive *gather.ArchiveWriter
+	mu      sync.Mutex
+	files   int
+}
+
+func (a *archiver) add(filename string) error {
+	if err := a.archive.AddFile(filename); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.files++
+	return nil
+}
+
+func gatherMaster(master, proxy string, keys []string, opts Options, directory string, budget *budget, a *archiver) error {
+	client, err := ssh.NewClientThroughProxy("core", net.JoinHostPort(master, "22"), keys, proxy)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return gatherHost(client, opts, directory, budget, a)
+}
+
+func gatherHost(client *gossh.Client, opts Options, directory string, budget *budget, a *archiver) error {
+	var errs []error
+	commands := opts.commands()
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		command := commands[name]
+		if budget.exhausted() {
+			logrus.Warnf("Skipping %s, the maximum size of the gathered data was reached", filepath.Join(directory, name))
+			continue
+		}
+		filePath := filepath.Join(directory, name)
+		if err := runTo(client, command, filePath, budget); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to gather %s", name))
+		}
+		if _, err := os.Stat(filePath); err == nil {
+			if err := a.add(filePath); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to archive %s", name))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func runTo(client *gossh.Client, command, filePath string, budget *budget) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = ssh.RunWithOutput(client, command, budget.writer(file))
+	if budget.exhausted() {
+		logrus.Warnf("Truncated %s, the maximum size of the gathered data was reached", filePath)
+		// The command fails once its output is not written anymore.
+		return nil
+	}
+	return err
+}
+
+// budget is the size left for the gathered data, shared by the hosts
+// gathered concurrently.
+type budget struct {
+	mu        sync.Mutex
+	remaining int64
+	unlimited bool
+}
+
+func (b *budget) exhausted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.unlimited && b.remaining <= 0
+}
+
+// writer returns a writer which writes to w until the budget is exhausted,
+// and then fails.
+func (b *budget) writer(w io.Writer) io.Writer {
+	if b.unlimited {
+		return w
+	}
+	return &budgetWriter{budget: b, w: w}
+}
+
+type budgetWriter struct {
+	budget *budget
+	w      io.Writer
+}
+
+func (bw *budgetWriter) Write(p []byte) (int, error) {
+	bw.budget.mu.Lock()
+	defer bw.budget.mu.Unlock()
+	if bw.budget.remaining <= 0 {
+		return 0, errors.New("maximum size reached")
+	}
+	truncated := p
+	if int64(len(truncated)) > bw.budget.remaining {
+		truncated = truncated[:bw.budget.remaining]
+	}
+	n, err := bw.w.Write(truncated)
+	bw.budget.remaining -= int64(n)
+	if err == nil && n < len(p) {
+		err = errors.New("maximum size reached")
+	}
+	return n, err
+}
@@ -0,0 +1,108 @@
+package selective
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name          string
+		opts          Options
+		expectedError string
+	}{
+		{
+			name: "valid",
+			opts: Options{Units: []string{"bootkube.service", "kubelet", "crio*"}, Priority: "warning", Paths: []string{"/var/log/pods/*/etcd*/*.log"}, MaxSize: 1024},
+		},
+		{
+			name: "numeric priority",
+			opts: Options{Priority: "3"},
+		},
+		{
+			name:          "invalid",
+			opts:          Options{Units: []string{"kubelet; rm -rf /"}, Priority: "8", Paths: []string{"var/log", "/var/log/$(id)"}, MaxSize: -1},
+			expectedError: `[invalid unit "kubelet; rm -rf /", invalid journal priority "8", must be one of emerg, alert, crit, err, warning, notice, info, debug or 0-7, invalid path "var/log", must be an absolute path or glob, invalid path "/var/log/$(id)", must be an absolute path or glob, invalid maximum size -1]`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     Options
+		expected map[string]string
+	}{
+		{
+			name: "units with priority",
+			opts: Options{Units: []string{"bootkube.service", "kubelet"}, Priority: "err"},
+			expected: map[string]string{
+				"journals/bootkube.service.log": "sudo journalctl --no-pager -o short-iso -p err -u 'bootkube.service'",
+				"journals/kubelet.log":          "sudo journalctl --no-pager -o short-iso -p err -u 'kubelet'",
+			},
+		},
+		{
+			name: "priority only",
+			opts: Options{Priority: "warning"},
+			expected: map[string]string{
+				"journals/journal.log": "sudo journalctl --no-pager -o short-iso -p warning",
+			},
+		},
+		{
+			name: "paths only",
+			opts: Options{Paths: []string{"/var/log/bootkube*", "/etc/kubernetes/manifests"}},
+			expected: map[string]string{
+				"files.tar.gz": "sudo sh -c 'tar -czf - --ignore-failed-read -- /var/log/bootkube* /etc/kubernetes/manifests 2>/dev/null; true'",
+			},
+		},
+		{
+			name: "maximum size only",
+			opts: Options{MaxSize: 1024},
+			expected: map[string]string{
+				"journals/journal.log": "sudo journalctl --no-pager -o short-iso",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.opts.commands())
+		})
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := &budget{remaining: 10}
+	var first, second bytes.Buffer
+
+	n, err := b.writer(&first).Write([]byte("123456"))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.False(t, b.exhausted())
+
+	n, err = b.writer(&second).Write([]byte("123456"))
+	assert.EqualError(t, err, "maximum size reached")
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "1234", second.String())
+	assert.True(t, b.exhausted())
+
+	_, err = b.writer(&second).Write([]byte("7"))
+	assert.EqualError(t, err, "maximum size reached")
+
+	unlimited := &budget{unlimited: true}
+	var out bytes.Buffer
+	_, err = unlimited.writer(&out).Write(bytes.Repeat([]byte("a"), 100))
+	assert.NoError(t, err)
+	assert.False(t, unlimited.exhausted())
+}
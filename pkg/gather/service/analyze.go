@@ -2,7 +2,6 @@ package service
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"encoding/json"
 	"io"
 	"os"
@@ -11,6 +10,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/gather"
 )
 
 // regex matching the path of a service entries file. The captured group is the name of the service.
@@ -30,12 +31,12 @@ func AnalyzeGatherBundle(bundlePath string) error {
 		return errors.Wrap(err, "could not open the gather bundle")
 	}
 	defer bundleFile.Close()
-	return analyzeGatherBundle(bundleFile)
+	return analyzeGatherBundle(bundleFile, gather.CompressionOf(bundlePath))
 }
 
-func analyzeGatherBundle(bundleFile io.Reader) error {
+func analyzeGatherBundle(bundleFile io.Reader, compression gather.Compression) error {
 	// decompress the bundle
-	uncompressedStream, err := gzip.NewReader(bundleFile)
+	uncompressedStream, err := gather.NewDecompressor(compression, bundleFile)
 	if err != nil {
 		return errors.Wrap(err, "could not decompress the gather bundle")
 	}
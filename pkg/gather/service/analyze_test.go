@@ -9,6 +9,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/installer/pkg/gather"
 )
 
 func generateSuccessOutput(stage string) string {
@@ -189,7 +191,7 @@ func TestAnalyzeGatherBundle(t *testing.T) {
 			gzipWriter.Close()
 			hook := test.NewLocal(logrus.StandardLogger())
 			defer hook.Reset()
-			err := analyzeGatherBundle(&gatherBuilder)
+			err := analyzeGatherBundle(&gatherBuilder, gather.GzipCompression)
 			assert.NoError(t, err, "unexpected error from analysis")
 			for i, e := range hook.Entries {
 				hook.Entries[i] = logrus.Entry{
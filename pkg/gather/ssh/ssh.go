@@ -2,7 +2,10 @@
 package ssh
 
 import (
+	"io"
+	"net"
 	"os"
+	osuser "os/user"
 	"path/filepath"
 	"strings"
 
@@ -20,21 +23,27 @@ import (
 //
 // if keys list is empty, it tries to load the keys from the user's environment.
 func NewClient(user, address string, keys []string) (*ssh.Client, error) {
+	return NewClientThroughProxy(user, address, keys, "")
+}
+
+// NewClientThroughProxy creates a new SSH client which can be used to SSH to
+// address using user and the keys, jumping through the proxy, a bastion host
+// given as [user@]host[:port] like the ProxyJump option of OpenSSH, when it
+// is not empty. The same keys authenticate with the proxy and address.
+//
+// if keys list is empty, it tries to load the keys from the user's environment.
+func NewClientThroughProxy(user, address string, keys []string, proxy string) (*ssh.Client, error) {
 	ag, agentType, err := getAgent(keys)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize the SSH agent")
 	}
 
-	client, err := ssh.Dial("tcp", address, &ssh.ClientConfig{
-		User: user,
-		Auth: []ssh.AuthMethod{
-			// Use a callback rather than PublicKeys
-			// so we only consult the agent once the remote server
-			// wants it.
-			ssh.PublicKeysCallback(ag.Signers),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	})
+	var client *ssh.Client
+	if proxy == "" {
+		client, err = ssh.Dial("tcp", address, clientConfig(user, ag))
+	} else {
+		client, err = dialThroughProxy(user, address, proxy, ag)
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "ssh: handshake failed: ssh: unable to authenticate") {
 			if agentType == "agent" {
@@ -50,6 +59,70 @@ func NewClient(user, address string, keys []string) (*ssh.Client, error) {
 	return client, nil
 }
 
+func clientConfig(user string, ag agent.Agent) *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{
+			// Use a callback rather than PublicKeys
+			// so we only consult the agent once the remote server
+			// wants it.
+			ssh.PublicKeysCallback(ag.Signers),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+}
+
+func dialThroughProxy(user, address, proxy string, ag agent.Agent) (*ssh.Client, error) {
+	proxyUser, proxyAddress, err := parseProxy(proxy)
+	if err != nil {
+		return nil, err
+	}
+	proxyClient, err := ssh.Dial("tcp", proxyAddress, clientConfig(proxyUser, ag))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to the SSH proxy %s", proxyAddress)
+	}
+	conn, err := proxyClient.Dial("tcp", address)
+	if err != nil {
+		proxyClient.Close()
+		return nil, errors.Wrapf(err, "failed to connect to %s through the SSH proxy %s", address, proxyAddress)
+	}
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, address, clientConfig(user, ag))
+	if err != nil {
+		conn.Close()
+		proxyClient.Close()
+		return nil, err
+	}
+	client := ssh.NewClient(clientConn, chans, reqs)
+	go func() {
+		client.Wait() //nolint:errcheck
+		proxyClient.Close()
+	}()
+	return client, nil
+}
+
+// parseProxy returns the user and the address of the proxy given as
+// [user@]host[:port]. The user defaults to the local user and the port to 22.
+func parseProxy(proxy string) (string, string, error) {
+	user, host := "", proxy
+	if i := strings.LastIndex(proxy, "@"); i >= 0 {
+		user, host = proxy[:i], proxy[i+1:]
+	}
+	if user == "" {
+		current, err := osuser.Current()
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to get the user of the SSH proxy")
+		}
+		user = current.Username
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), "22")
+	}
+	if strings.HasPrefix(host, ":") {
+		return "", "", errors.Errorf("invalid SSH proxy %q: missing host", proxy)
+	}
+	return user, host, nil
+}
+
 // Run uses an SSH client to execute commands.
 func Run(client *ssh.Client, command string) error {
 	sess, err := client.NewSession()
@@ -68,6 +141,39 @@ func Run(client *ssh.Client, command string) error {
 	return sess.Run(command)
 }
 
+// RunWithOutput uses an SSH client to execute the command, writing its
+// standard output to stdout, e.g. to stream an archive created on the fly.
+func RunWithOutput(client *ssh.Client, command string, stdout io.Writer) error {
+	sess, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	debugW := &lineprinter.LinePrinter{Print: (&lineprinter.Trimmer{WrappedPrint: logrus.Debug}).Print}
+	defer debugW.Close()
+	sess.Stdout = stdout
+	sess.Stderr = debugW
+	return sess.Run(command)
+}
+
+// RunWithInput uses an SSH client to execute the command, reading its
+// standard input from stdin, e.g. to run a script of the local host.
+func RunWithInput(client *ssh.Client, command string, stdin io.Reader) error {
+	sess, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	debugW := &lineprinter.LinePrinter{Print: (&lineprinter.Trimmer{WrappedPrint: logrus.Debug}).Print}
+	defer debugW.Close()
+	sess.Stdin = stdin
+	sess.Stdout = debugW
+	sess.Stderr = debugW
+	return sess.Run(command)
+}
+
 // PullFileTo downloads the file from remote server using SSH connection and writes to localPath.
 func PullFileTo(client *ssh.Client, remotePath, localPath string) error {
 	sc, err := sftp.NewClient(client)
@@ -0,0 +1,35 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProxy(t *testing.T) {
+	cases := []struct {
+		proxy           string
+		expectedUser    string
+		expectedAddress string
+		expectedError   string
+	}{
+		{proxy: "core@bastion.example.com", expectedUser: "core", expectedAddress: "bastion.example.com:22"},
+		{proxy: "core@bastion.example.com:2222", expectedUser: "core", expectedAddress: "bastion.example.com:2222"},
+		{proxy: "core@192.168.1.1", expectedUser: "core", expectedAddress: "192.168.1.1:22"},
+		{proxy: "core@[fd00::1]:2222", expectedUser: "core", expectedAddress: "[fd00::1]:2222"},
+		{proxy: "core@fd00::1", expectedUser: "core", expectedAddress: "[fd00::1]:22"},
+		{proxy: "core@", expectedError: `invalid SSH proxy "core@": missing host`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.proxy, func(t *testing.T) {
+			user, address, err := parseProxy(tc.proxy)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUser, user)
+			assert.Equal(t, tc.expectedAddress, address)
+		})
+	}
+}
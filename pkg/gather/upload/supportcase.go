@@ -0,0 +1,108 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// tokenURL exchanges the offline tokens of the Red Hat API for access
+	// tokens.
+	tokenURL = "https://sso.redhat.com/auth/realms/redhat-external/protocol/openid-connect/token"
+	// supportURL is the base URL of the Red Hat support API.
+	supportURL = "https://api.access.redhat.com/support"
+)
+
+// ToSupportCase attaches the bundle to the Red Hat support case, using the
+// offline token of the Red Hat API, and returns the SHA-256 checksum of the
+// bundle, computed while it is streamed, for the support engineers to check
+// the attachment against.
+func ToSupportCase(ctx context.Context, bundlePath, caseNumber, offlineToken string) (string, error) {
+	if offlineToken == "" {
+		return "", errors.New("an offline token of the Red Hat API is required to upload to a support case")
+	}
+	accessToken, err := exchangeToken(ctx, offlineToken)
+	if err != nil {
+		return "", err
+	}
+
+	bundle, err := os.Open(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	defer bundle.Close()
+
+	hash := sha256.New()
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		part, err := form.CreateFormFile("file", filepath.Base(bundlePath))
+		if err == nil {
+			_, err = io.Copy(part, io.TeeReader(bundle, hash))
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	attachmentsURL := fmt.Sprintf("%s/v1/cases/%s/attachments", supportURL, url.PathEscape(caseNumber))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, attachmentsURL, body)
+	if err != nil {
+		body.Close()
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to attach to support case %s", caseNumber)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", errors.Errorf("failed to attach to support case %s: %s: %s", caseNumber, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// exchangeToken exchanges the offline token for an access token.
+func exchangeToken(ctx context.Context, offlineToken string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {"rhsm-api"},
+		"refresh_token": {offlineToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to exchange the offline token")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to exchange the offline token: %s", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "failed to decode the access token")
+	}
+	return token.AccessToken, nil
+}
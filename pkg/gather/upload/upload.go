@@ -0,0 +1,96 @@
+// Package upload uploads gather bundles to remote storage or to support
+// cases, so that the bundles outlive the ephemeral hosts which gathered them.
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+
+	awsconfig "github.com/openshift/installer/pkg/asset/installconfig/aws"
+)
+
+// ToS3 uploads the bundle to the S3 URL of the form s3://<bucket>/<prefix>,
+// under the prefix, and returns the URL of the uploaded object and the
+// SHA-256 checksum of the bundle. The bundle is streamed in parts whose
+// checksums are verified by S3, and the checksum of the whole bundle is
+// stored in the sha256 metadata of the object.
+func ToS3(ctx context.Context, bundlePath, rawURL string) (string, string, error) {
+	bucket, prefix, err := parseS3URL(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	checksum, err := fileChecksum(bundlePath)
+	if err != nil {
+		return "", "", err
+	}
+
+	ssn, err := awsconfig.GetSession()
+	if err != nil {
+		return "", "", err
+	}
+	region, err := s3manager.GetBucketRegion(ctx, ssn, bucket, "us-east-1")
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to find the region of bucket %s", bucket)
+	}
+
+	bundle, err := os.Open(bundlePath)
+	if err != nil {
+		return "", "", err
+	}
+	defer bundle.Close()
+
+	key := path.Join(prefix, filepath.Base(bundlePath))
+	uploader := s3manager.NewUploaderWithClient(s3.New(ssn, aws.NewConfig().WithRegion(region)))
+	if _, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		Body:              bundle,
+		ChecksumAlgorithm: aws.String(s3.ChecksumAlgorithmSha256),
+		Metadata:          map[string]*string{"sha256": aws.String(checksum)},
+	}); err != nil {
+		return "", "", errors.Wrapf(err, "failed to upload to bucket %s", bucket)
+	}
+	return "s3://" + path.Join(bucket, key), checksum, nil
+}
+
+// parseS3URL returns the bucket and the prefix of the S3 URL.
+func parseS3URL(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid upload URL %q", rawURL)
+	}
+	if u.Scheme != "s3" {
+		return "", "", errors.Errorf("invalid upload URL %q, only s3://<bucket>/<prefix> is supported", rawURL)
+	}
+	if u.Host == "" {
+		return "", "", errors.Errorf("invalid upload URL %q, a bucket is required", rawURL)
+	}
+	return u.Host, strings.Trim(u.Path, "/"), nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of the file.
+func fileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", errors.Wrapf(err, "failed to compute the checksum of %s", filePath)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
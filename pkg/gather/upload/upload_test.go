@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseS3URL(t *testing.T) {
+	cases := []struct {
+		url            string
+		expectedBucket string
+		expectedPrefix string
+		expectedError  string
+	}{
+		{url: "s3://bucket", expectedBucket: "bucket"},
+		{url: "s3://bucket/ci/job-1/", expectedBucket: "bucket", expectedPrefix: "ci/job-1"},
+		{url: "gs://bucket/ci", expectedError: `invalid upload URL "gs://bucket/ci", only s3://<bucket>/<prefix> is supported`},
+		{url: "s3:///ci", expectedError: `invalid upload URL "s3:///ci", a bucket is required`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			bucket, prefix, err := parseS3URL(tc.url)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBucket, bucket)
+			assert.Equal(t, tc.expectedPrefix, prefix)
+		})
+	}
+}
+
+func TestToSupportCase(t *testing.T) {
+	content := []byte("log bundle")
+	sum := sha256.Sum256(content)
+	bundlePath := filepath.Join(t.TempDir(), "log-bundle-1.tar.gz")
+	require.NoError(t, os.WriteFile(bundlePath, content, 0600))
+
+	cases := []struct {
+		name          string
+		uploadStatus  int
+		expectedError string
+	}{
+		{
+			name:         "attached",
+			uploadStatus: http.StatusCreated,
+		},
+		{
+			name:          "rejected",
+			uploadStatus:  http.StatusForbidden,
+			expectedError: "failed to attach to support case 01234567: 403 Forbidden: not your case",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var uploaded []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/token":
+					assert.Equal(t, "offline", r.FormValue("refresh_token"))
+					json.NewEncoder(w).Encode(map[string]string{"access_token": "access"}) //nolint:errcheck
+				case "/support/v1/cases/01234567/attachments":
+					assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+					file, header, err := r.FormFile("file")
+					require.NoError(t, err)
+					assert.Equal(t, "log-bundle-1.tar.gz", header.Filename)
+					uploaded, _ = io.ReadAll(file)
+					w.WriteHeader(tc.uploadStatus)
+					if tc.uploadStatus != http.StatusCreated {
+						w.Write([]byte("not your case")) //nolint:errcheck
+					}
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			tokenURL, supportURL = server.URL+"/token", server.URL+"/support"
+
+			checksum, err := ToSupportCase(context.Background(), bundlePath, "01234567", "offline")
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, hex.EncodeToString(sum[:]), checksum)
+			assert.Equal(t, content, uploaded)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package hooks runs the user-supplied executables found in the hooks
+// directory of the install dir at well-known phases of the install. The hooks
+// of a phase live in <dir>/hooks/<phase>.d and are run in lexical order.
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/lineprinter"
+)
+
+// Phase is a point of the install at which hooks are run.
+type Phase string
+
+const (
+	// PreManifests hooks run before the manifests are generated.
+	PreManifests Phase = "pre-manifests"
+	// PostManifests hooks run after the manifests have been written to the
+	// install dir, and may modify them.
+	PostManifests Phase = "post-manifests"
+)
+
+const (
+	// Dir is the directory in the install dir holding the hooks.
+	Dir = "hooks"
+
+	// AssetDirEnvVar is the environment variable holding the absolute path
+	// of the install dir when running a hook.
+	AssetDirEnvVar = "OPENSHIFT_INSTALL_ASSET_DIR"
+	// PhaseEnvVar is the environment variable holding the phase when running
+	// a hook.
+	PhaseEnvVar = "OPENSHIFT_INSTALL_HOOK_PHASE"
+)
+
+// Find returns the paths of the hooks of the phase in the install dir, in the
+// order they are run. Entries that are not executable files are skipped with
+// a warning.
+func Find(directory string, phase Phase) ([]string, error) {
+	phaseDir := filepath.Join(directory, Dir, string(phase)+".d")
+	entries, err := os.ReadDir(phaseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read %s hooks", phase)
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(phaseDir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s hook", path)
+		}
+		if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+			logrus.Warnf("Skipping %s hook %s, which is not an executable file", phase, path)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Run runs the hooks of the phase in the install dir, stopping at the first
+// one that fails. The hooks are run from the install dir, and their output is
+// logged.
+func Run(directory string, phase Phase) error {
+	paths, err := Find(directory, phase)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	absDir, err := filepath.Abs(directory)
+	if err != nil {
+		return errors.Wrap(err, "failed to get absolute path of the install dir")
+	}
+	for _, path := range paths {
+		logrus.Infof("Running %s hook %s", phase, filepath.Base(path))
+		if err := runHook(absDir, phase, path); err != nil {
+			return errors.Wrapf(err, "%s hook %s failed", phase, filepath.Base(path))
+		}
+	}
+	return nil
+}
+
+func runHook(directory string, phase Phase, path string) error {
+	stdout := &lineprinter.LinePrinter{Print: (&lineprinter.Trimmer{WrappedPrint: logrus.Info}).Print}
+	stderr := &lineprinter.LinePrinter{Print: (&lineprinter.Trimmer{WrappedPrint: logrus.Warn}).Print}
+	defer stdout.Close()
+	defer stderr.Close()
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(absPath)
+	cmd.Dir = directory
+	cmd.Env = append(os.Environ(),
+		AssetDirEnvVar+"="+directory,
+		PhaseEnvVar+"="+string(phase),
+	)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
@@ -0,0 +1,83 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeHook(t *testing.T, directory string, phase Phase, name string, perm os.FileMode, script string) {
+	t.Helper()
+	phaseDir := filepath.Join(directory, Dir, string(phase)+".d")
+	if err := os.MkdirAll(phaseDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(phaseDir, name), []byte(script), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, PreManifests, "20-second", 0o750, "#!/bin/sh\n")
+	writeHook(t, dir, PreManifests, "10-first", 0o750, "#!/bin/sh\n")
+	writeHook(t, dir, PreManifests, "README", 0o640, "not a hook\n")
+
+	paths, err := Find(dir, PreManifests)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, Dir, "pre-manifests.d", "10-first"),
+		filepath.Join(dir, Dir, "pre-manifests.d", "20-second"),
+	}, paths)
+
+	paths, err = Find(dir, PostManifests)
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+}
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		name        string
+		hooks       map[string]string
+		expectedErr string
+		expectedOut string
+	}{
+		{
+			name: "hooks run in order with the environment",
+			hooks: map[string]string{
+				"10-first":  "#!/bin/sh\necho \"first $OPENSHIFT_INSTALL_HOOK_PHASE\" >> \"$OPENSHIFT_INSTALL_ASSET_DIR/out\"\n",
+				"20-second": "#!/bin/sh\necho second >> out\n",
+			},
+			expectedOut: "first post-manifests\nsecond\n",
+		},
+		{
+			name: "failing hook stops the phase",
+			hooks: map[string]string{
+				"10-fail":   "#!/bin/sh\nexit 3\n",
+				"20-second": "#!/bin/sh\necho second >> out\n",
+			},
+			expectedErr: `^post-manifests hook 10-fail failed: exit status 3$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, script := range tc.hooks {
+				writeHook(t, dir, PostManifests, name, 0o750, script)
+			}
+
+			err := Run(dir, PostManifests)
+			if tc.expectedErr != "" {
+				assert.Regexp(t, tc.expectedErr, err)
+				assert.NoFileExists(t, filepath.Join(dir, "out"))
+				return
+			}
+			assert.NoError(t, err)
+			out, err := os.ReadFile(filepath.Join(dir, "out"))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOut, string(out))
+		})
+	}
+}
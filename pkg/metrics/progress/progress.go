@@ -0,0 +1,105 @@
+// Package progress emits machine-readable install progress events so that
+// tools wrapping the installer can follow along without parsing the logs.
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EventType identifies the kind of progress event.
+type EventType string
+
+const (
+	// PhaseStarted is emitted when an install phase begins.
+	PhaseStarted EventType = "PhaseStarted"
+	// PhaseCompleted is emitted when an install phase ends.
+	PhaseCompleted EventType = "PhaseCompleted"
+	// AssetGenerated is emitted every time an asset is generated.
+	AssetGenerated EventType = "AssetGenerated"
+	// InfrastructureProvisioned is emitted once all infrastructure resources have been created.
+	InfrastructureProvisioned EventType = "InfrastructureProvisioned"
+	// BootstrapComplete is emitted once the bootstrap process has finished.
+	BootstrapComplete EventType = "BootstrapComplete"
+	// InstallComplete is emitted once the cluster has finished installing.
+	InstallComplete EventType = "InstallComplete"
+)
+
+// Event is a single progress event. Events are written as newline-delimited JSON.
+type Event struct {
+	Type      EventType `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name,omitempty"`
+}
+
+// Emitter writes progress events to an output.
+type Emitter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+var emitter = &Emitter{}
+
+// NewEmitter returns an emitter writing events to out.
+func NewEmitter(out io.Writer) *Emitter {
+	return &Emitter{out: out}
+}
+
+// Emit writes an event of the given type. It is a no-op if no output is configured.
+func (e *Emitter) Emit(eventType EventType, name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.out == nil {
+		return nil
+	}
+	data, err := json.Marshal(Event{Type: eventType, Timestamp: time.Now().UTC(), Name: name})
+	if err != nil {
+		return err
+	}
+	_, err = e.out.Write(append(data, '\n'))
+	return err
+}
+
+// SetOutput configures the writer used by the package-level emitter.
+func SetOutput(out io.Writer) {
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+	emitter.out = out
+}
+
+// Emit writes an event using the package-level emitter. Failures to write
+// progress must never break the install, so errors are discarded.
+func Emit(eventType EventType, name string) {
+	emitter.Emit(eventType, name) //nolint:errcheck
+}
+
+// Open returns a writer for the given progress destination. The destination
+// is either a numeric file descriptor inherited from the parent process or
+// the path to a unix socket.
+func Open(destination string) (io.WriteCloser, error) {
+	if fd, err := strconv.ParseUint(destination, 10, 0); err == nil {
+		if fd <= 2 {
+			return nil, errors.Errorf("file descriptor %d is reserved for standard streams", fd)
+		}
+		f := os.NewFile(uintptr(fd), "progress")
+		if f == nil {
+			return nil, errors.Errorf("invalid file descriptor %d", fd)
+		}
+		return f, nil
+	}
+
+	conn, err := net.Dial("unix", strings.TrimPrefix(destination, "unix:"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to progress socket %q", destination)
+	}
+	return conn, nil
+}
@@ -0,0 +1,53 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	e := NewEmitter(buf)
+
+	assert.NoError(t, e.Emit(PhaseStarted, "Bootstrap Complete"))
+	assert.NoError(t, e.Emit(AssetGenerated, "Install Config"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		var event Event
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+		assert.Equal(t, AssetGenerated, event.Type)
+		assert.Equal(t, "Install Config", event.Name)
+		assert.False(t, event.Timestamp.IsZero())
+	}
+}
+
+func TestEmitWithoutOutput(t *testing.T) {
+	assert.NoError(t, NewEmitter(nil).Emit(BootstrapComplete, ""))
+}
+
+func TestOpen(t *testing.T) {
+	_, err := Open("1")
+	assert.EqualError(t, err, "file descriptor 1 is reserved for standard streams")
+
+	socket := filepath.Join(t.TempDir(), "progress.sock")
+	listener, err := net.Listen("unix", socket)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	w, err := Open("unix:" + socket)
+	if assert.NoError(t, err) {
+		w.Close()
+	}
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.sock"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+// Package telemetry reports install phase timings for fleet-wide analysis.
+// Reporting is opt-in and nothing is sent or written unless configured.
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift/installer/pkg/metrics/builder"
+	"github.com/openshift/installer/pkg/metrics/pushclient"
+)
+
+const (
+	jobName    = "openshift_install"
+	metricName = "openshift_install_phase_duration_seconds"
+)
+
+var phaseDurationBuckets = []float64{60, 300, 600, 1200, 1800, 2700, 3600, 5400}
+
+// Options configures where phase timings are reported.
+type Options struct {
+	// Endpoint is the URL of a Prometheus push gateway to push timings to.
+	Endpoint string
+	// File is the path of a local file to write timings to as JSON.
+	File string
+}
+
+// Enabled returns true if any reporting destination is configured.
+func (o Options) Enabled() bool {
+	return o.Endpoint != "" || o.File != ""
+}
+
+// Report is the content written to the local telemetry file.
+type Report struct {
+	// Phases maps each install phase to its duration in seconds.
+	Phases map[string]float64 `json:"phases"`
+	// Result is "success" or "failure".
+	Result string `json:"result"`
+	// Timestamp is the time at which the report was created.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Send reports the given phase durations to all the configured destinations.
+func Send(opts Options, durations map[string]time.Duration, success bool) error {
+	if !opts.Enabled() {
+		return nil
+	}
+
+	report := Report{
+		Phases:    make(map[string]float64, len(durations)),
+		Result:    "failure",
+		Timestamp: time.Now().UTC(),
+	}
+	if success {
+		report.Result = "success"
+	}
+	for phase, duration := range durations {
+		report.Phases[phase] = duration.Seconds()
+	}
+
+	if opts.File != "" {
+		if err := writeFile(opts.File, report); err != nil {
+			return err
+		}
+	}
+	if opts.Endpoint != "" {
+		if err := push(opts.Endpoint, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal telemetry report")
+	}
+	if err := os.WriteFile(path, data, 0o640); err != nil { //nolint:gosec // no sensitive info
+		return errors.Wrap(err, "failed to write telemetry report")
+	}
+	return nil
+}
+
+func push(endpoint string, report Report) error {
+	collectors := make([]prometheus.Collector, 0, len(report.Phases))
+	for phase, seconds := range report.Phases {
+		metric, err := builder.NewMetricBuilder(builder.MetricOpts{
+			Labels:     []string{"phase", "result"},
+			Desc:       "Duration of an install phase in seconds",
+			Name:       metricName,
+			Buckets:    phaseDurationBuckets,
+			MetricType: builder.Histogram,
+		}, seconds, map[string]string{"phase": phase, "result": report.Result})
+		if err != nil {
+			return err
+		}
+		collector, err := metric.PromCollector()
+		if err != nil {
+			return err
+		}
+		collectors = append(collectors, collector)
+	}
+
+	client := pushclient.PushClient{
+		URL:     endpoint,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		JobName: jobName,
+	}
+	return client.Push(collectors...)
+}
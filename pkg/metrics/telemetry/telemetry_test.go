@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendDisabled(t *testing.T) {
+	assert.NoError(t, Send(Options{}, map[string]time.Duration{"Total": time.Minute}, true))
+}
+
+func TestSendFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.json")
+	err := Send(Options{File: path}, map[string]time.Duration{
+		"Bootstrap Complete": 10 * time.Minute,
+		"Total":              30 * time.Minute,
+	}, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var report Report
+	assert.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "failure", report.Result)
+	assert.Equal(t, map[string]float64{"Bootstrap Complete": 600, "Total": 1800}, report.Phases)
+}
+
+func TestSendEndpoint(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	err := Send(Options{Endpoint: server.URL}, map[string]time.Duration{"Total": 30 * time.Minute}, true)
+	if assert.NoError(t, err) {
+		assert.True(t, strings.Contains(body, `openshift_install_phase_duration_seconds_sum{phase="Total",result="success"} 1800`), body)
+	}
+}
@@ -0,0 +1,188 @@
+package timer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/cachedir"
+)
+
+const (
+	// historyFileName is the file, in the installer cache directory, with
+	// the durations of the stages of the previous runs, per platform.
+	historyFileName = "stage-durations.json"
+
+	// historySize is the number of durations kept per platform and stage.
+	historySize = 10
+
+	// reminderInterval is how often the remaining time of a running stage
+	// is logged.
+	reminderInterval = 5 * time.Minute
+)
+
+// history maps platforms to stages to their durations, oldest first.
+type history map[string]map[string][]time.Duration
+
+var (
+	historyMu sync.Mutex
+	platform  string
+	past      map[string][]time.Duration
+	running   = map[string]chan struct{}{}
+)
+
+// SetPlatform sets the platform of the cluster, whose durations of the stages
+// of the previous runs are used to estimate the time left in the stages.
+func SetPlatform(name string) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if name == platform {
+		return
+	}
+	platform = name
+	h, err := loadHistory()
+	if err != nil {
+		logrus.Debugf("Failed to load the durations of the previous runs: %v", err)
+	}
+	past = h[name]
+}
+
+// Estimate returns the median duration of the stage in the previous runs on
+// the platform.
+func Estimate(stage string) (time.Duration, bool) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	return median(past[stage])
+}
+
+// SaveHistory adds the durations of the stages stopped so far to the
+// durations of the previous runs on the platform.
+func SaveHistory() error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if platform == "" {
+		return nil
+	}
+	h, err := loadHistory()
+	if err != nil {
+		logrus.Debugf("Discarding the durations of the previous runs: %v", err)
+		h = history{}
+	}
+	if h[platform] == nil {
+		h[platform] = map[string][]time.Duration{}
+	}
+	for stage, duration := range timer.StageDurations() {
+		if stage == TotalTimeElapsed || duration <= 0 {
+			continue
+		}
+		durations := append(h[platform][stage], duration)
+		if len(durations) > historySize {
+			durations = durations[len(durations)-historySize:]
+		}
+		h[platform][stage] = durations
+	}
+
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// historyPath returns the path of the history file in the installer cache
+// directory, in the directory of --cache-dir, set through cachedir.EnvVar,
+// when given.
+func historyPath() (string, error) {
+	dir, err := cachedir.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "openshift-installer", historyFileName), nil
+}
+
+func loadHistory() (history, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history{}, nil
+		}
+		return nil, err
+	}
+	h := history{}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	return h, nil
+}
+
+func median(durations []time.Duration) (time.Duration, bool) {
+	if len(durations) == 0 {
+		return 0, false
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2], true
+}
+
+// estimateStage logs the estimated duration of the stage, when there is one,
+// and then its remaining time every reminderInterval until it is stopped.
+func estimateStage(stage string) {
+	estimate, ok := Estimate(stage)
+	if !ok {
+		return
+	}
+	logrus.Infof("%s took about %s in the previous runs on %s", stage, estimate.Round(time.Minute), platform)
+
+	done := make(chan struct{})
+	historyMu.Lock()
+	if previous, ok := running[stage]; ok {
+		close(previous)
+	}
+	running[stage] = done
+	historyMu.Unlock()
+
+	start := time.Now()
+	go func() {
+		ticker := time.NewTicker(reminderInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if remaining := estimate - time.Since(start); remaining > 0 {
+					logrus.Infof("%s: about %s remaining", stage, remaining.Round(time.Minute))
+				} else {
+					logrus.Infof("%s is taking %s longer than in the previous runs", stage, (-remaining).Round(time.Minute))
+				}
+			}
+		}
+	}()
+}
+
+// stopEstimate stops logging the remaining time of the stage.
+func stopEstimate(stage string) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if done, ok := running[stage]; ok {
+		close(done)
+		delete(running, stage)
+	}
+}
@@ -0,0 +1,102 @@
+package timer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/installer/pkg/cachedir"
+)
+
+func TestMedian(t *testing.T) {
+	cases := []struct {
+		durations []time.Duration
+		want      time.Duration
+		wantOK    bool
+	}{
+		{},
+		{durations: []time.Duration{time.Minute}, want: time.Minute, wantOK: true},
+		{durations: []time.Duration{3 * time.Minute, time.Minute, 2 * time.Minute}, want: 2 * time.Minute, wantOK: true},
+		{durations: []time.Duration{4 * time.Minute, time.Minute, 3 * time.Minute, 2 * time.Minute}, want: 3 * time.Minute, wantOK: true},
+	}
+	for _, tc := range cases {
+		got, ok := median(tc.durations)
+		if got != tc.want || ok != tc.wantOK {
+			t.Fatalf("expected the median of %v to be %s, %t, got %s, %t", tc.durations, tc.want, tc.wantOK, got, ok)
+		}
+	}
+}
+
+func TestSaveHistory(t *testing.T) {
+	t.Setenv(cachedir.EnvVar, t.TempDir())
+	defer func() {
+		timer = NewTimer()
+		platform = ""
+		past = nil
+	}()
+
+	for run := 1; run <= historySize+2; run++ {
+		timer = NewTimer()
+		timer.stageTimes["Infrastructure"] = time.Duration(run) * time.Minute
+		timer.stageTimes[TotalTimeElapsed] = time.Hour
+		platform = ""
+		SetPlatform("aws")
+		if err := SaveHistory(); err != nil {
+			t.Fatalf("failed to save the history of run %d: %v", run, err)
+		}
+	}
+
+	platform = ""
+	SetPlatform("aws")
+	if got := len(past["Infrastructure"]); got != historySize {
+		t.Fatalf("expected %d durations to be kept, got %d", historySize, got)
+	}
+	if _, ok := past[TotalTimeElapsed]; ok {
+		t.Fatalf("expected the total time not to be kept")
+	}
+	// The durations of the last runs, 3m to 12m, are kept.
+	if got, _ := Estimate("Infrastructure"); got != 8*time.Minute {
+		t.Fatalf("expected the estimate to be 8m, got %s", got)
+	}
+
+	SetPlatform("gcp")
+	if _, ok := Estimate("Infrastructure"); ok {
+		t.Fatalf("expected no estimate for another platform")
+	}
+}
+
+func TestHistoryPath(t *testing.T) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Skipf("no user cache directory: %v", err)
+	}
+	cacheDir := t.TempDir()
+	cases := []struct {
+		name     string
+		cacheDir string
+		expected string
+	}{
+		{
+			name:     "user cache directory",
+			expected: filepath.Join(userCacheDir, "openshift-installer", historyFileName),
+		},
+		{
+			name:     "cache-dir",
+			cacheDir: cacheDir,
+			expected: filepath.Join(cacheDir, "openshift-installer", historyFileName),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(cachedir.EnvVar, tc.cacheDir)
+			path, err := historyPath()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if path != tc.expected {
+				t.Fatalf("expected the history at %s, got %s", tc.expected, path)
+			}
+		})
+	}
+}
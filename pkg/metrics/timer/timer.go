@@ -2,13 +2,19 @@ package timer
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/installer/pkg/metrics/progress"
 )
 
 // Timer is the struct that keeps track of each of the sections.
 type Timer struct {
+	// mu guards the stages, which are started and stopped while assets are
+	// generated and read by the log formatter.
+	mu           *sync.Mutex
 	listOfStages []string
 	stageTimes   map[string]time.Duration
 	startTimes   map[string]time.Time
@@ -25,11 +31,27 @@ var timer = NewTimer()
 // StartTimer initiailzes the timer object with the current timestamp information.
 func StartTimer(key string) {
 	timer.StartTimer(key)
+	progress.Emit(progress.PhaseStarted, key)
+	if key != TotalTimeElapsed {
+		estimateStage(key)
+	}
 }
 
 // StopTimer records the duration for the current stage sent as the key parameter and stores the information.
 func StopTimer(key string) {
+	stopEstimate(key)
 	timer.StopTimer(key)
+	progress.Emit(progress.PhaseCompleted, key)
+}
+
+// CurrentStage returns the most recently started stage that has not been stopped yet.
+func CurrentStage() string {
+	return timer.CurrentStage()
+}
+
+// StageDurations returns the durations of all the stages which have been stopped so far.
+func StageDurations() map[string]time.Duration {
+	return timer.StageDurations()
 }
 
 // LogSummary prints the summary of all the times collected so far into the INFO section.
@@ -40,6 +62,7 @@ func LogSummary() {
 // NewTimer returns a new timer that can be used to track sections and
 func NewTimer() Timer {
 	return Timer{
+		mu:           &sync.Mutex{},
 		listOfStages: []string{},
 		stageTimes:   make(map[string]time.Duration),
 		startTimes:   make(map[string]time.Time),
@@ -48,12 +71,16 @@ func NewTimer() Timer {
 
 // StartTimer initializes the timer object with the current timestamp information.
 func (t *Timer) StartTimer(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.listOfStages = append(t.listOfStages, key)
 	t.startTimes[key] = time.Now().Round(time.Second)
 }
 
 // StopTimer records the duration for the current stage sent as the key parameter and stores the information.
 func (t *Timer) StopTimer(key string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if item, found := t.startTimes[key]; found {
 		duration := time.Since(item).Round(time.Second)
 		t.stageTimes[key] = duration
@@ -61,6 +88,34 @@ func (t *Timer) StopTimer(key string) time.Duration {
 	return time.Since(time.Now())
 }
 
+// CurrentStage returns the most recently started stage that has not been stopped yet,
+// ignoring the total time stage. An empty string is returned if no stage is running.
+func (t *Timer) CurrentStage() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.listOfStages) - 1; i >= 0; i-- {
+		key := t.listOfStages[i]
+		if key == TotalTimeElapsed {
+			continue
+		}
+		if _, stopped := t.stageTimes[key]; !stopped {
+			return key
+		}
+	}
+	return ""
+}
+
+// StageDurations returns a copy of the durations of all the stages which have been stopped so far.
+func (t *Timer) StageDurations() map[string]time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	durations := make(map[string]time.Duration, len(t.stageTimes))
+	for key, duration := range t.stageTimes {
+		durations[key] = duration
+	}
+	return durations
+}
+
 // LogSummary prints the summary of all the times collected so far into the INFO section.
 // The format of printing will be the following:
 // If there are no stages except the total time stage, then it only prints the following
@@ -76,25 +131,35 @@ func (t *Timer) StopTimer(key string) time.Duration {
 // Time elapsed: <x>m<yy>s
 // All durations printed are rounded up to the next second value and printed in the format mentioned above.
 func (t *Timer) LogSummary(logger *logrus.Logger) {
+	stages, total := t.summary()
+	if len(stages) > 0 {
+		logger.Debugf("Time elapsed per stage:")
+	}
+	for _, stage := range stages {
+		logger.Debug(stage)
+	}
+	logger.Infof("Time elapsed: %s", total)
+}
+
+// summary returns the aligned durations of the stopped stages and the total
+// time elapsed. It is computed apart from the logging, which reads the
+// current stage through the log formatter.
+func (t *Timer) summary() ([]string, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	maxLen := 0
-	count := 0
 	for _, item := range t.listOfStages {
 		if len(item) > maxLen && item != TotalTimeElapsed {
 			maxLen = len(item)
 		}
-		if t.stageTimes[item] > 0 {
-			count++
-		}
-	}
-
-	if maxLen != 0 && count > 0 {
-		logger.Debugf("Time elapsed per stage:")
 	}
 
+	var stages []string
 	for _, item := range t.listOfStages {
 		if item != TotalTimeElapsed && t.stageTimes[item] > 0 {
-			logger.Debugf(fmt.Sprintf("%*s: %s", maxLen, item, t.stageTimes[item]))
+			stages = append(stages, fmt.Sprintf("%*s: %s", maxLen, item, t.stageTimes[item]))
 		}
 	}
-	logger.Infof("Time elapsed: %s", t.stageTimes[TotalTimeElapsed])
+	return stages, t.stageTimes[TotalTimeElapsed]
 }
@@ -129,3 +129,56 @@ func TestNewTimer(t *testing.T) {
 		t.Fatalf("Expected empty list of startTimes property in the new timer created, got %d", len(timer.stageTimes))
 	}
 }
+
+func TestCurrentStage(t *testing.T) {
+	timer := NewTimer()
+
+	steps := []struct {
+		start string
+		stop  string
+		want  string
+	}{
+		{start: TotalTimeElapsed, want: ""},
+		{start: "testStage1", want: "testStage1"},
+		{start: "testStage2", want: "testStage2"},
+		{stop: "testStage2", want: "testStage1"},
+		{stop: "testStage1", want: ""},
+	}
+	for _, step := range steps {
+		if step.start != "" {
+			timer.StartTimer(step.start)
+		}
+		if step.stop != "" {
+			timer.StopTimer(step.stop)
+		}
+		if got := timer.CurrentStage(); got != step.want {
+			t.Fatalf("expected current stage to be %q, got %q", step.want, got)
+		}
+	}
+}
+
+// TestCurrentStageConcurrently checks, with the race detector, that the
+// current stage can be read, e.g. by the log formatter, while stages are
+// started and stopped.
+func TestCurrentStageConcurrently(t *testing.T) {
+	timer := NewTimer()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			stage := fmt.Sprintf("testStage%d", i)
+			timer.StartTimer(stage)
+			timer.StopTimer(stage)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		timer.CurrentStage()
+		timer.StageDurations()
+	}
+	<-done
+
+	if got := timer.CurrentStage(); got != "" {
+		t.Fatalf("expected no current stage, got %q", got)
+	}
+}
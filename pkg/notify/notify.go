@@ -0,0 +1,119 @@
+// Package notify posts the outcome of the installer commands to a webhook,
+// so that unattended installs can be followed from chat or paging tools.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ResultSuccess is the result of the commands which completed.
+	ResultSuccess = "success"
+	// ResultFailure is the result of the commands which failed.
+	ResultFailure = "failure"
+)
+
+// Event is the JSON document posted to the webhook.
+type Event struct {
+	// Text summarizes the event for the chat webhooks, e.g. Slack's, which
+	// only display this field.
+	Text string `json:"text"`
+	// Command is the command which finished, e.g. "openshift-install create cluster".
+	Command     string `json:"command"`
+	ClusterName string `json:"clusterName,omitempty"`
+	InfraID     string `json:"infraID,omitempty"`
+	// Result is "success" or "failure".
+	Result string `json:"result"`
+	// ErrorClass is the install stage which failed, e.g. "Bootstrap Complete".
+	ErrorClass string `json:"errorClass,omitempty"`
+	// Error is the last error logged by the command.
+	Error           string    `json:"error,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// Send posts the event to the webhook URL.
+func Send(ctx context.Context, url string, event Event) error {
+	if event.Text == "" {
+		event.Text = summary(event)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the notification")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "failed to create the notification request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to post the notification")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("the webhook responded with %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func summary(event Event) string {
+	name := event.ClusterName
+	if name == "" {
+		name = "the cluster"
+	}
+	duration := time.Duration(event.DurationSeconds * float64(time.Second)).Round(time.Second)
+	if event.Result == ResultSuccess {
+		return fmt.Sprintf("%s succeeded for %s after %s", event.Command, name, duration)
+	}
+	text := fmt.Sprintf("%s failed for %s after %s", event.Command, name, duration)
+	if event.ErrorClass != "" {
+		text = fmt.Sprintf("%s in %s", text, event.ErrorClass)
+	}
+	if event.Error != "" {
+		text = fmt.Sprintf("%s: %s", text, event.Error)
+	}
+	return text
+}
+
+// ErrorHook is a logrus hook recording the last error logged, which is the
+// cause of the failure of the commands.
+type ErrorHook struct {
+	mu   sync.Mutex
+	last string
+}
+
+// Levels implements logrus.Hook.
+func (h *ErrorHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel}
+}
+
+// Fire implements logrus.Hook.
+func (h *ErrorHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = entry.Message
+	return nil
+}
+
+// LastError returns the message of the last error logged.
+func (h *ErrorHook) LastError() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	cases := []struct {
+		name          string
+		event         Event
+		status        int
+		expectedText  string
+		expectedError string
+	}{
+		{
+			name: "success",
+			event: Event{
+				Command:         "openshift-install create cluster",
+				ClusterName:     "test-cluster",
+				InfraID:         "test-cluster-x2k4f",
+				Result:          ResultSuccess,
+				DurationSeconds: 2700,
+			},
+			status:       http.StatusOK,
+			expectedText: "openshift-install create cluster succeeded for test-cluster after 45m0s",
+		},
+		{
+			name: "failure",
+			event: Event{
+				Command:         "openshift-install wait-for bootstrap-complete",
+				Result:          ResultFailure,
+				ErrorClass:      "Bootstrap Complete",
+				Error:           "context deadline exceeded",
+				DurationSeconds: 60,
+			},
+			status:       http.StatusNoContent,
+			expectedText: "openshift-install wait-for bootstrap-complete failed for the cluster after 1m0s in Bootstrap Complete: context deadline exceeded",
+		},
+		{
+			name:          "rejected",
+			event:         Event{Command: "openshift-install create cluster", Result: ResultSuccess},
+			status:        http.StatusBadRequest,
+			expectedText:  "openshift-install create cluster succeeded for the cluster after 0s",
+			expectedError: `^the webhook responded with 400 Bad Request: invalid_payload$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received Event
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(tc.status)
+				if tc.status >= http.StatusBadRequest {
+					w.Write([]byte("invalid_payload\n"))
+				}
+			}))
+			defer server.Close()
+
+			tc.event.Timestamp = time.Now().UTC().Truncate(time.Second)
+			err := Send(context.Background(), server.URL, tc.event)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+
+			expected := tc.event
+			expected.Text = tc.expectedText
+			assert.Equal(t, expected, received)
+		})
+	}
+}
+
+func TestErrorHook(t *testing.T) {
+	hook := &ErrorHook{}
+	logger := logrus.New()
+	logger.Out = io.Discard
+	logger.AddHook(hook)
+
+	logger.Info("not an error")
+	assert.Equal(t, "", hook.LastError())
+
+	logger.Error("first error")
+	logger.Error("second error")
+	logger.Warn("a warning")
+	assert.Equal(t, "second error", hook.LastError())
+}
@@ -0,0 +1,126 @@
+// Package plugin discovers external provider plugins, which allow the
+// installer to create and destroy clusters on platforms it does not support
+// natively. A provider plugin is any executable in the PATH named
+// openshift-install-provider-<platform>.
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/installer/pkg/types"
+	"github.com/openshift/installer/pkg/types/libvirt"
+	"github.com/openshift/installer/pkg/types/ovirt"
+)
+
+const (
+	// Prefix is the prefix of provider plugin executable names.
+	Prefix = "openshift-install-provider-"
+
+	installConfigFilename = "install-config.yaml"
+	metadataFilename      = "metadata.json"
+)
+
+// builtinPlatforms are the platforms handled by the installer itself.
+var builtinPlatforms = sets.New[string](append(append([]string{libvirt.Name, ovirt.Name}, types.PlatformNames...), types.HiddenPlatformNames...)...)
+
+// IsBuiltin returns true if the platform is supported natively by the installer.
+func IsBuiltin(platform string) bool {
+	return builtinPlatforms.Has(platform)
+}
+
+// Lookup returns the path of the provider plugin for the platform.
+func Lookup(platform string) (string, error) {
+	return exec.LookPath(Prefix + platform)
+}
+
+// List returns the platforms for which a provider plugin is found in the PATH.
+func List() []string {
+	found := sets.New[string]()
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), Prefix) {
+				continue
+			}
+			if _, err := exec.LookPath(filepath.Join(dir, entry.Name())); err != nil {
+				continue
+			}
+			found.Insert(strings.TrimPrefix(entry.Name(), Prefix))
+		}
+	}
+	platforms := sets.List(found)
+	sort.Strings(platforms)
+	return platforms
+}
+
+// PlatformFromInstallConfig returns the platform named in the install-config.yaml
+// in dir, or an empty string if there is none.
+func PlatformFromInstallConfig(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, installConfigFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	config := struct {
+		Platform map[string]json.RawMessage `json:"platform"`
+	}{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal %s", installConfigFilename)
+	}
+	return singlePlatform(config.Platform), nil
+}
+
+// PlatformFromMetadata returns the platform named in the metadata.json in dir,
+// or an empty string if there is none.
+func PlatformFromMetadata(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metadataFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	metadata := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal %s", metadataFilename)
+	}
+	for _, key := range []string{"clusterName", "clusterID", "infraID"} {
+		delete(metadata, key)
+	}
+	return singlePlatform(metadata), nil
+}
+
+func singlePlatform(fields map[string]json.RawMessage) string {
+	// anything but exactly one platform is left to the regular validation.
+	if len(fields) != 1 {
+		return ""
+	}
+	for name := range fields {
+		return name
+	}
+	return ""
+}
+
+// Run executes the plugin at path with the given arguments, connecting it to
+// the standard streams of the installer.
+func Run(path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBuiltin(t *testing.T) {
+	assert.True(t, IsBuiltin("aws"))
+	assert.True(t, IsBuiltin("none"))
+	assert.False(t, IsBuiltin("acme"))
+}
+
+func TestPlatformFromInstallConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "no install config",
+			expected: "",
+		},
+		{
+			name:     "single platform",
+			config:   "metadata:\n  name: test\nplatform:\n  acme:\n    region: east\n",
+			expected: "acme",
+		},
+		{
+			name:     "no platform",
+			config:   "metadata:\n  name: test\n",
+			expected: "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.config != "" {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, installConfigFilename), []byte(tc.config), 0600))
+			}
+			platform, err := PlatformFromInstallConfig(dir)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, platform)
+		})
+	}
+}
+
+func TestPlatformFromMetadata(t *testing.T) {
+	dir := t.TempDir()
+	metadata := `{"clusterName":"test","clusterID":"1234","infraID":"test-abcde","acme":{"region":"east"}}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, metadataFilename), []byte(metadata), 0600))
+
+	platform, err := PlatformFromMetadata(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", platform)
+}
+
+func TestLookupAndList(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, Prefix+"acme"), []byte("#!/bin/sh\n"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, Prefix+"not-executable"), []byte(""), 0644))
+	t.Setenv("PATH", dir)
+
+	path, err := Lookup("acme")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, Prefix+"acme"), path)
+
+	_, err = Lookup("other")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"acme"}, List())
+}
@@ -0,0 +1,118 @@
+// Package poll implements the policy used by the installer to poll the APIs of
+// the cluster and of the agent installer while waiting for install-time
+// events: how often they are polled, how the polling backs off and how many
+// transient API errors in a row are tolerated.
+package poll
+
+import (
+	"context"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Policy is how an API is polled. Its zero value polls at the default
+// interval of the caller, without backing off, and tolerates any number of
+// transient errors.
+type Policy struct {
+	// Interval replaces the default interval between two polls, when set.
+	Interval time.Duration
+	// MaxInterval caps the interval when it backs off. Zero means no cap.
+	MaxInterval time.Duration
+	// BackoffFactor multiplies the interval after each poll which did not
+	// succeed. Zero or one keep the interval fixed.
+	BackoffFactor float64
+	// ErrorBudget is the number of transient API errors in a row after which
+	// polling fails. Zero means no limit.
+	ErrorBudget int
+}
+
+// Options is the policy used by Until.
+var Options Policy
+
+// Validate returns an error when the policy is invalid.
+func (p Policy) Validate() error {
+	switch {
+	case p.Interval < 0:
+		return errors.Errorf("invalid poll interval %v, must be positive", p.Interval)
+	case p.MaxInterval < 0:
+		return errors.Errorf("invalid maximum poll interval %v, must be positive", p.MaxInterval)
+	case p.BackoffFactor != 0 && p.BackoffFactor < 1:
+		return errors.Errorf("invalid poll backoff factor %v, must be at least 1", p.BackoffFactor)
+	case p.ErrorBudget < 0:
+		return errors.Errorf("invalid API error budget %d, must be positive", p.ErrorBudget)
+	}
+	return nil
+}
+
+// Until calls the condition, following Options, until it returns true, an
+// error which is not transient, more transient errors in a row than the
+// error budget, or the context is done. The condition is first called right
+// away, and then after the interval of Options or, when unset, after the
+// default interval.
+func Until(ctx context.Context, defaultInterval time.Duration, condition func(context.Context) (bool, error)) error {
+	return Options.until(ctx, defaultInterval, condition)
+}
+
+func (p Policy) until(ctx context.Context, defaultInterval time.Duration, condition func(context.Context) (bool, error)) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	interval := defaultInterval
+	if p.Interval > 0 {
+		interval = p.Interval
+	}
+
+	errorsInARow := 0
+	for {
+		done, err := condition(ctx)
+		switch {
+		case err == nil:
+			errorsInARow = 0
+		case !IsTransient(err):
+			return err
+		default:
+			errorsInARow++
+			if p.ErrorBudget > 0 && errorsInARow > p.ErrorBudget {
+				return errors.Wrapf(err, "giving up after %d API errors in a row", errorsInARow)
+			}
+			logrus.Debugf("Transient API error %d in a row: %v", errorsInARow, err)
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		if p.BackoffFactor > 1 {
+			interval = time.Duration(float64(interval) * p.BackoffFactor)
+			if p.MaxInterval > 0 && interval > p.MaxInterval {
+				interval = p.MaxInterval
+			}
+		}
+	}
+}
+
+// IsTransient returns whether the error is an API error which may go away
+// by itself, e.g. a refused connection while the API server restarts or a
+// timeout on a slow link.
+func IsTransient(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err)
+}
@@ -0,0 +1,100 @@
+package poll
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUntil(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	cases := []struct {
+		name          string
+		policy        Policy
+		results       []error
+		expectedCalls int
+		expectedError string
+	}{
+		{
+			name:          "succeeds",
+			results:       []error{nil, nil},
+			expectedCalls: 3,
+		},
+		{
+			name:          "transient errors without a budget",
+			results:       []error{refused, refused, refused},
+			expectedCalls: 4,
+		},
+		{
+			name:          "transient errors within the budget",
+			policy:        Policy{ErrorBudget: 2},
+			results:       []error{refused, refused, nil, refused, refused},
+			expectedCalls: 6,
+		},
+		{
+			name:          "transient errors exceeding the budget",
+			policy:        Policy{ErrorBudget: 2},
+			results:       []error{refused, refused, refused},
+			expectedCalls: 3,
+			expectedError: `^giving up after 3 API errors in a row: dial tcp: connect: connection refused$`,
+		},
+		{
+			name:          "other errors",
+			results:       []error{errors.New("forbidden")},
+			expectedCalls: 1,
+			expectedError: `^forbidden$`,
+		},
+		{
+			name:          "invalid policy",
+			policy:        Policy{BackoffFactor: 0.5},
+			expectedError: `^invalid poll backoff factor 0.5, must be at least 1$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			err := tc.policy.until(context.Background(), time.Millisecond, func(context.Context) (bool, error) {
+				calls++
+				if calls > len(tc.results) {
+					return true, nil
+				}
+				return false, tc.results[calls-1]
+			})
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Regexp(t, tc.expectedError, err)
+			}
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
+
+func TestUntilBackoff(t *testing.T) {
+	policy := Policy{Interval: time.Millisecond, BackoffFactor: 2, MaxInterval: 4 * time.Millisecond}
+	var times []time.Time
+	err := policy.until(context.Background(), time.Hour, func(context.Context) (bool, error) {
+		times = append(times, time.Now())
+		return len(times) == 5, nil
+	})
+	assert.NoError(t, err)
+	// 1ms, 2ms, 4ms and 4ms again.
+	assert.GreaterOrEqual(t, times[4].Sub(times[0]), 11*time.Millisecond)
+}
+
+func TestIsTransient(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	assert.True(t, IsTransient(refused))
+	assert.True(t, IsTransient(fmt.Errorf("get: %w", refused)))
+	assert.True(t, IsTransient(errors.Wrap(syscall.ECONNRESET, "read")))
+	assert.False(t, IsTransient(errors.New("forbidden")))
+}
@@ -0,0 +1,120 @@
+// Package reproducible implements the reproducible mode of the installer, in
+// which identical inputs generate byte-identical manifests and ignition
+// configs, so that what the installer generates can be audited and compared
+// across runs.
+//
+// In reproducible mode, the current time is replaced by the time set in
+// SOURCE_DATE_EPOCH, random identifiers are derived from the inputs and
+// private keys are read from the assets directory instead of being generated.
+package reproducible
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SourceDateEpochEnvVar is the environment variable holding the time, in
+// seconds since the Unix epoch, used as the creation time of the generated
+// assets. See https://reproducible-builds.org/specs/source-date-epoch/.
+const SourceDateEpochEnvVar = "SOURCE_DATE_EPOCH"
+
+// alphanums are the characters of the strings derived by String. They are the
+// ones used by k8s.io/apimachinery/pkg/util/rand.String.
+const alphanums = "bcdfghjklmnpqrstvwxz2456789"
+
+var (
+	enabled bool
+	epoch   time.Time
+	dir     string
+)
+
+// Enable turns the reproducible mode on for the assets directory. The creation
+// time is read from SOURCE_DATE_EPOCH, which must be set.
+func Enable(assetDir string) error {
+	value, ok := os.LookupEnv(SourceDateEpochEnvVar)
+	if !ok || value == "" {
+		return errors.Errorf("%s must be set to the creation time of the assets, in seconds since the Unix epoch", SourceDateEpochEnvVar)
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s", SourceDateEpochEnvVar)
+	}
+
+	enabled = true
+	epoch = time.Unix(seconds, 0).UTC()
+	dir = assetDir
+	return nil
+}
+
+// Disable turns the reproducible mode off.
+func Disable() {
+	enabled = false
+	epoch = time.Time{}
+	dir = ""
+}
+
+// Enabled returns whether the reproducible mode is on.
+func Enabled() bool {
+	return enabled
+}
+
+// Now returns the creation time in reproducible mode, and the current time
+// otherwise.
+func Now() time.Time {
+	if enabled {
+		return epoch
+	}
+	return time.Now()
+}
+
+// File returns the content of the file provided in the assets directory, e.g.
+// tls/root-ca.key, or nil when there is no such file or the reproducible mode
+// is off. Unlike the files loaded by assets, it is left in the assets
+// directory.
+func File(filename string) ([]byte, error) {
+	if !enabled {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, filename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// Sum returns a digest of the seeds, to be used instead of random bytes.
+func Sum(seeds ...[]byte) [sha256.Size]byte {
+	h := sha256.New()
+	for _, seed := range seeds {
+		// Prefix each seed with its length, so that the seeds a, bc and
+		// ab, c have different digests.
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(seed)))
+		h.Write(length[:])
+		h.Write(seed)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// String returns a string of n lowercase alphanumeric characters derived from
+// the seeds, to be used instead of a random string. n must not be greater than
+// sha256.Size.
+func String(n int, seeds ...[]byte) string {
+	sum := Sum(seeds...)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphanums[int(sum[i])%len(alphanums)]
+	}
+	return string(b)
+}
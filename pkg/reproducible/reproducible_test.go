@@ -0,0 +1,86 @@
+package reproducible
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnable(t *testing.T) {
+	cases := []struct {
+		name          string
+		epoch         string
+		expectedTime  time.Time
+		expectedError string
+	}{
+		{
+			name:         "valid epoch",
+			epoch:        "1700000000",
+			expectedTime: time.Date(2023, time.November, 14, 22, 13, 20, 0, time.UTC),
+		},
+		{
+			name:          "missing epoch",
+			expectedError: `^SOURCE_DATE_EPOCH must be set to the creation time of the assets, in seconds since the Unix epoch$`,
+		},
+		{
+			name:          "invalid epoch",
+			epoch:         "yesterday",
+			expectedError: `^invalid SOURCE_DATE_EPOCH: strconv.ParseInt: parsing "yesterday": invalid syntax$`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(SourceDateEpochEnvVar, tc.epoch)
+			defer Disable()
+
+			err := Enable(t.TempDir())
+			if tc.expectedError != "" {
+				assert.Regexp(t, tc.expectedError, err)
+				assert.False(t, Enabled())
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, Enabled())
+			assert.Equal(t, tc.expectedTime, Now())
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tls"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tls", "root-ca.key"), []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := File("tls/root-ca.key")
+	assert.NoError(t, err)
+	assert.Nil(t, data, "files are not read when the mode is off")
+
+	t.Setenv(SourceDateEpochEnvVar, "0")
+	defer Disable()
+	if err := Enable(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = File("tls/root-ca.key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("key"), data)
+
+	data, err = File("tls/missing.key")
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestString(t *testing.T) {
+	s := String(5, []byte("a"), []byte("bc"))
+	assert.Len(t, s, 5)
+	assert.Regexp(t, `^[bcdfghjklmnpqrstvwxz2456789]{5}$`, s)
+	assert.Equal(t, s, String(5, []byte("a"), []byte("bc")))
+	assert.NotEqual(t, s, String(5, []byte("ab"), []byte("c")))
+}
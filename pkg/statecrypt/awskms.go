@@ -0,0 +1,90 @@
+package statecrypt
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/pkg/errors"
+)
+
+// awsKMSProvider encrypts the files with a data key generated by AWS KMS,
+// and stores the data key encrypted by the KMS key in front of them
+// (envelope encryption).
+type awsKMSProvider struct {
+	keyID  string
+	client *kms.KMS
+
+	mu           sync.Mutex
+	encryptedKey []byte
+	keys         map[string][]byte
+}
+
+func newAWSKMSProvider(keyID string) (*awsKMSProvider, error) {
+	if keyID == "" {
+		return nil, errors.New("invalid state encryption key, the KMS key ID is missing")
+	}
+	ssn, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create an AWS session")
+	}
+	config := aws.NewConfig()
+	if parsed, err := arn.Parse(keyID); err == nil {
+		config = config.WithRegion(parsed.Region)
+	}
+	return &awsKMSProvider{
+		keyID:  keyID,
+		client: kms.New(ssn, config),
+		keys:   map[string][]byte{},
+	}, nil
+}
+
+func (p *awsKMSProvider) name() string {
+	return "awskms"
+}
+
+func (p *awsKMSProvider) encryptionKey() ([]byte, []byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.encryptedKey == nil {
+		output, err := p.client.GenerateDataKey(&kms.GenerateDataKeyInput{
+			KeyId:   aws.String(p.keyID),
+			KeySpec: aws.String(kms.DataKeySpecAes256),
+		})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to generate a data key with KMS")
+		}
+		p.encryptedKey = output.CiphertextBlob
+		p.keys[string(output.CiphertextBlob)] = output.Plaintext
+	}
+
+	prefix := make([]byte, 2, 2+len(p.encryptedKey))
+	binary.BigEndian.PutUint16(prefix, uint16(len(p.encryptedKey)))
+	return p.keys[string(p.encryptedKey)], append(prefix, p.encryptedKey...), nil
+}
+
+func (p *awsKMSProvider) decryptionKey(payload []byte) ([]byte, []byte, error) {
+	if len(payload) < 2 || len(payload) < 2+int(binary.BigEndian.Uint16(payload)) {
+		return nil, nil, errors.New("malformed encrypted data")
+	}
+	size := int(binary.BigEndian.Uint16(payload))
+	encryptedKey, rest := payload[2:2+size], payload[2+size:]
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[string(encryptedKey)]; ok {
+		return key, rest, nil
+	}
+	output, err := p.client.Decrypt(&kms.DecryptInput{
+		KeyId:          aws.String(p.keyID),
+		CiphertextBlob: encryptedKey,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decrypt the data key with KMS")
+	}
+	p.keys[string(encryptedKey)] = output.Plaintext
+	return output.Plaintext, rest, nil
+}
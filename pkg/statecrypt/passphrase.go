@@ -0,0 +1,69 @@
+package statecrypt
+
+import (
+	"crypto/rand"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const saltSize = 16
+
+// passphraseProvider derives the data keys from a passphrase with scrypt. A
+// single salt is used for all the files written by a process, so that the
+// costly derivation runs only once.
+type passphraseProvider struct {
+	passphrase []byte
+
+	mu   sync.Mutex
+	salt []byte
+	keys map[string][]byte
+}
+
+func newPassphraseProvider(passphrase string) *passphraseProvider {
+	return &passphraseProvider{passphrase: []byte(passphrase), keys: map[string][]byte{}}
+}
+
+func (p *passphraseProvider) name() string {
+	return "passphrase"
+}
+
+func (p *passphraseProvider) encryptionKey() ([]byte, []byte, error) {
+	p.mu.Lock()
+	if p.salt == nil {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			p.mu.Unlock()
+			return nil, nil, err
+		}
+		p.salt = salt
+	}
+	salt := p.salt
+	p.mu.Unlock()
+
+	key, err := p.key(salt)
+	return key, append([]byte{}, salt...), err
+}
+
+func (p *passphraseProvider) decryptionKey(payload []byte) ([]byte, []byte, error) {
+	if len(payload) < saltSize {
+		return nil, nil, errors.New("malformed encrypted data")
+	}
+	key, err := p.key(payload[:saltSize])
+	return key, payload[saltSize:], err
+}
+
+func (p *passphraseProvider) key(salt []byte) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key(p.passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive the key from the passphrase")
+	}
+	p.keys[string(salt)] = key
+	return key, nil
+}
@@ -0,0 +1,155 @@
+// Package statecrypt encrypts the installer state and the credentials the
+// installer writes to the assets directory.
+package statecrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// header starts every encrypted file, followed by the name of the key
+	// provider and a newline, then the base64-encoded payload.
+	header = "OPENSHIFT-INSTALL-ENCRYPTED v1 "
+
+	// awsKMSPrefix introduces keys naming an AWS KMS key.
+	awsKMSPrefix = "awskms://"
+)
+
+// keyProvider provides the AES-256 data keys encrypting the files.
+type keyProvider interface {
+	// name identifies the provider in the header of the encrypted files.
+	name() string
+	// encryptionKey returns the data key to encrypt with, and the bytes to
+	// store in front of the nonce for decryptionKey to recover the key.
+	encryptionKey() (key []byte, prefix []byte, err error)
+	// decryptionKey returns the data key for the payload of an encrypted
+	// file and the rest of the payload, starting at the nonce.
+	decryptionKey(payload []byte) (key []byte, rest []byte, err error)
+}
+
+var provider keyProvider
+
+// SetKey sets the key encrypting the state. A key of the form
+// awskms://<key-id> names an AWS KMS key, any other non-empty key is a
+// passphrase. An empty key disables the encryption.
+func SetKey(key string) error {
+	switch {
+	case key == "":
+		provider = nil
+	case strings.HasPrefix(key, awsKMSPrefix):
+		p, err := newAWSKMSProvider(strings.TrimPrefix(key, awsKMSPrefix))
+		if err != nil {
+			return err
+		}
+		provider = p
+	default:
+		provider = newPassphraseProvider(key)
+	}
+	return nil
+}
+
+// Enabled returns true if a key has been set.
+func Enabled() bool {
+	return provider != nil
+}
+
+// IsSensitive returns true if the file with the given name relative to the
+// assets directory holds credentials and must be encrypted when enabled.
+func IsSensitive(filename string) bool {
+	return strings.HasPrefix(filepath.ToSlash(filepath.Clean(filename)), "auth/")
+}
+
+// IsEncrypted returns true if data was produced by Encrypt.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(header))
+}
+
+// Encrypt returns data encrypted with the key, or data unchanged if no key
+// has been set.
+func Encrypt(data []byte) ([]byte, error) {
+	if provider == nil {
+		return data, nil
+	}
+	key, prefix, err := provider.encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	payload := append(append(prefix, nonce...), aead.Seal(nil, nonce, data, nil)...)
+	encoded := base64.StdEncoding.EncodeToString(payload)
+	return []byte(header + provider.name() + "\n" + encoded + "\n"), nil
+}
+
+// Decrypt returns the plain text of data if it is encrypted, or data
+// unchanged otherwise.
+func Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if provider == nil {
+		return nil, errors.New("data is encrypted but no state encryption key was given")
+	}
+	firstLine, encoded, found := bytes.Cut(data[len(header):], []byte("\n"))
+	if !found {
+		return nil, errors.New("malformed encrypted data")
+	}
+	if name := string(firstLine); name != provider.name() {
+		return nil, errors.Errorf("data is encrypted with a %s key, but a %s key was given", name, provider.name())
+	}
+	payload, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encoded)))
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed encrypted data")
+	}
+
+	key, rest, err := provider.decryptionKey(payload)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted data")
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt data, the key may be wrong")
+	}
+	return plain, nil
+}
+
+// ReadFile reads the named file, decrypting it if needed.
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := Decrypt(data)
+	return plain, errors.Wrapf(err, "failed to decrypt %s", path)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,53 @@
+package statecrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer SetKey("")
+
+	plain := []byte(`{"kubeadmin-password": "secret"}`)
+
+	assert.NoError(t, SetKey(""))
+	data, err := Encrypt(plain)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, data, "expected data to be unchanged without a key")
+
+	assert.NoError(t, SetKey("correct horse"))
+	encrypted, err := Encrypt(plain)
+	assert.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "secret")
+
+	decrypted, err := Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	decrypted, err = Decrypt(plain)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, decrypted, "expected plain data to be passed through")
+
+	assert.NoError(t, SetKey("battery staple"))
+	_, err = Decrypt(encrypted)
+	assert.EqualError(t, err, "failed to decrypt data, the key may be wrong")
+
+	assert.NoError(t, SetKey(""))
+	_, err = Decrypt(encrypted)
+	assert.EqualError(t, err, "data is encrypted but no state encryption key was given")
+}
+
+func TestIsSensitive(t *testing.T) {
+	cases := map[string]bool{
+		"auth/kubeconfig":         true,
+		"auth/kubeadmin-password": true,
+		"./auth/kubeconfig":       true,
+		"metadata.json":           false,
+		"manifests/auth.yaml":     false,
+	}
+	for filename, expected := range cases {
+		assert.Equal(t, expected, IsSensitive(filename), filename)
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/ulikunitz/xz"
 	"golang.org/x/sys/unix"
+
+	"github.com/openshift/installer/pkg/cachedir"
 )
 
 const (
@@ -32,7 +34,7 @@ func getCacheDir(dataType string) (string, error) {
 		return "", errors.Errorf("data type can't be an empty string")
 	}
 
-	userCacheDir, err := os.UserCacheDir()
+	userCacheDir, err := cachedir.UserCacheDir()
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,13 @@
+package agent
+
+// NodesConfig is the API for specifying the hosts added as workers to an
+// existing cluster by the agent add-nodes image.
+type NodesConfig struct {
+	// Hosts are the hosts added to the cluster, identified by the MAC
+	// addresses of their interfaces.
+	Hosts []Host `json:"hosts"`
+	// SSHKey is the public SSH key of the core user of the hosts while they
+	// boot the add-nodes image.
+	// +optional
+	SSHKey string `json:"sshKey,omitempty"`
+}
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	aiv1beta1 "github.com/openshift/assisted-service/api/v1beta1"
@@ -27,6 +28,35 @@ type Config struct {
 	RendezvousIP         string `json:"rendezvousIP,omitempty"`
 	BootArtifactsBaseURL string `json:"bootArtifactsBaseURL,omitempty"`
 	Hosts                []Host `json:"hosts,omitempty"`
+	// ISOCustomization customizes the discovery ISO and PXE files booted by
+	// all the hosts.
+	// +optional
+	ISOCustomization *ISOCustomization `json:"isoCustomization,omitempty"`
+	// RendezvousDiscovery discovers the rendezvous host on networks where
+	// the hosts get their addresses with DHCP, instead of setting the
+	// rendezvousIP up front. It requires the authentication of the Agent Rest
+	// API, enabled with --rest-api-auth.
+	// +optional
+	RendezvousDiscovery *RendezvousDiscovery `json:"rendezvousDiscovery,omitempty"`
+}
+
+// RendezvousDiscovery defines how the rendezvous host is elected by the hosts
+// and discovered by them and by the installer, when its IP is not known up
+// front. The rendezvous host is found by the MAC address of its interface on
+// the local network of the hosts, and by the installer through the DNS name it
+// registers with the DHCP server, or else on the machine networks of the
+// install-config.
+type RendezvousDiscovery struct {
+	// MACAddress is the MAC address of the interface of the rendezvous host.
+	// Defaults to the lowest MAC address of the interfaces of the control
+	// plane hosts.
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+	// Hostname is the name the rendezvous host sends to the DHCP server of
+	// its interface. The installer resolves it to find the rendezvous host
+	// when the DHCP server registers it in the DNS.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
 }
 
 // Host defines per host configurations
@@ -37,4 +67,116 @@ type Host struct {
 	// list of interfaces and mac addresses
 	Interfaces    []*aiv1beta1.Interface `json:"interfaces,omitempty"`
 	NetworkConfig aiv1beta1.NetConfig    `json:"networkConfig,omitempty"`
+	// IgnitionConfigOverride is an Ignition config, in JSON, merged into the
+	// ignition of the host when it is installed, e.g. to configure host
+	// specific storage.
+	// +optional
+	IgnitionConfigOverride string `json:"ignitionConfigOverride,omitempty"`
+	// ISOCustomization customizes the discovery ISO when it is booted by the
+	// host, which is found by the MAC addresses of its interfaces.
+	// +optional
+	ISOCustomization *HostISOCustomization `json:"isoCustomization,omitempty"`
+	// BMC is the Redfish BMC of the host, booting it on the discovery ISO
+	// with agent boot-hosts. It is not included in the discovery ISO.
+	// +optional
+	BMC *baremetal.BMC `json:"bmc,omitempty"`
+	// Hardware is the hardware of the host, checked against the minimum
+	// requirements of its role in the topology of the cluster before the
+	// image is created.
+	// +optional
+	Hardware *HostHardware `json:"hardware,omitempty"`
+	// NodeLabels are set on the node of the host, which is registered in the
+	// cluster before the host joins it, so that they are set before the
+	// workloads are scheduled. The hostname and role of the host are required.
+	// +optional
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+	// NodeTaints are set on the node of the host, which is registered in the
+	// cluster before the host joins it. The hostname and role of the host are
+	// required.
+	// +optional
+	NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
+}
+
+// HostHardware defines the hardware of a host.
+type HostHardware struct {
+	// CPUCores is the number of CPU cores of the host.
+	// +optional
+	CPUCores int `json:"cpuCores,omitempty"`
+	// MemoryGiB is the memory of the host, in GiB.
+	// +optional
+	MemoryGiB int `json:"memoryGiB,omitempty"`
+	// DiskSizeGB is the size of the installation disk of the host, in GB,
+	// which is the one selected by its rootDeviceHints if any.
+	// +optional
+	DiskSizeGB int `json:"diskSizeGB,omitempty"`
+}
+
+// ISOCustomization defines the customization of the discovery ISO booted by
+// all the hosts.
+type ISOCustomization struct {
+	// KernelArguments are appended to the kernel command line of the
+	// discovery ISO and PXE files.
+	// +optional
+	KernelArguments []string `json:"kernelArguments,omitempty"`
+	// Files are written to the live system of the discovery ISO.
+	// +optional
+	Files []ISOFile `json:"files,omitempty"`
+	// Scripts are run, in order, when the discovery ISO boots and before the
+	// agent starts.
+	// +optional
+	Scripts []ISOScript `json:"scripts,omitempty"`
+	// SerialConsole sets the console kernel arguments and the GRUB serial
+	// terminal of the discovery ISO, and the console kernel arguments of the
+	// installed hosts of the agent config.
+	// +optional
+	SerialConsole *SerialConsole `json:"serialConsole,omitempty"`
+}
+
+// HostISOCustomization defines the customization of the discovery ISO booted
+// by a single host. The kernel arguments are shared by all the hosts booting
+// the same ISO so they can't be set per host.
+type HostISOCustomization struct {
+	// Files are written to the live system of the discovery ISO.
+	// +optional
+	Files []ISOFile `json:"files,omitempty"`
+	// Scripts are run, in order, when the discovery ISO boots and before the
+	// agent starts, after the scripts shared by all the hosts.
+	// +optional
+	Scripts []ISOScript `json:"scripts,omitempty"`
+	// SerialConsole sets the console kernel arguments of the host once
+	// installed, overriding the serial console shared by all the hosts. The
+	// discovery ISO booted by the host keeps the shared serial console.
+	// +optional
+	SerialConsole *SerialConsole `json:"serialConsole,omitempty"`
+}
+
+// SerialConsole defines the serial console of the hosts.
+type SerialConsole struct {
+	// Device is the serial device of the console, e.g. ttyS1. Defaults to
+	// ttyS0.
+	// +optional
+	Device string `json:"device,omitempty"`
+	// BaudRate is the speed of the serial console. Defaults to 115200.
+	// +optional
+	BaudRate int `json:"baudRate,omitempty"`
+}
+
+// ISOFile is a file written to the live system of the discovery ISO.
+type ISOFile struct {
+	// Path is the absolute path of the file.
+	Path string `json:"path"`
+	// Contents are the contents of the file.
+	Contents string `json:"contents"`
+	// Mode is the permission mode of the file, 0644 when unset.
+	// +optional
+	Mode *int `json:"mode,omitempty"`
+}
+
+// ISOScript is a script run when the discovery ISO boots.
+type ISOScript struct {
+	// Name is the file name of the script.
+	Name string `json:"name"`
+	// Contents are the contents of the script, starting with an interpreter
+	// directive, e.g. #!/bin/bash.
+	Contents string `json:"contents"`
 }
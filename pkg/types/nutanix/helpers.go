@@ -13,6 +13,8 @@ import (
 	"github.com/nutanix-cloud-native/prism-go-client/utils"
 	nutanixclientv3 "github.com/nutanix-cloud-native/prism-go-client/v3"
 	"github.com/pkg/errors"
+
+	"github.com/openshift/installer/pkg/cachedir"
 )
 
 const (
@@ -72,7 +74,7 @@ func CreateBootstrapISO(infraID, userData string) (string, error) {
 		return "", errors.Wrap(err, fmt.Sprintf("failed to add file: %s", err))
 	}
 
-	cacheDir, err := os.UserCacheDir()
+	cacheDir, err := cachedir.UserCacheDir()
 	if err != nil {
 		return "", errors.Wrap(err, "unable to fetch user cache dir")
 	}